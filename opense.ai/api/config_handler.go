@@ -157,6 +157,9 @@ func mergeConfig(dst, src *config.Config) {
 	if len(src.API.CORSOrigins) > 0 {
 		dst.API.CORSOrigins = src.API.CORSOrigins
 	}
+	if len(src.API.AllowedOrigins) > 0 {
+		dst.API.AllowedOrigins = src.API.AllowedOrigins
+	}
 
 	// Web
 	if src.Web.URL != "" {
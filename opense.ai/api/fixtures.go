@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/seenimoa/openseai/internal/datasource"
+	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
+)
+
+// Fixtures is an AggregatorLike that serves canned market data, for demos
+// and deterministic integration tests. Map keys are normalized tickers or
+// index names.
+type Fixtures struct {
+	Quotes   map[string]*models.Quote   `json:"quotes"`
+	History  map[string][]models.OHLCV  `json:"history"`
+	Indices  map[string][]string        `json:"indices"`
+	Overview *datasource.MarketOverview `json:"overview"`
+	FIIDII   *models.FIIDIIData         `json:"fii_dii"`
+}
+
+// LoadFixtures reads fixtures from a JSON file.
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: %w", err)
+	}
+	var f Fixtures
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("fixtures %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// GetQuote returns the fixture quote for ticker.
+func (f *Fixtures) GetQuote(_ context.Context, ticker string) (*models.Quote, error) {
+	q, ok := f.Quotes[utils.NormalizeTicker(ticker)]
+	if !ok {
+		return nil, fmt.Errorf("fixtures: no quote for %s", ticker)
+	}
+	return q, nil
+}
+
+// IndexConstituents returns the fixture constituents of index.
+func (f *Fixtures) IndexConstituents(_ context.Context, index string) ([]string, error) {
+	tickers, ok := f.Indices[utils.NormalizeTicker(index)]
+	if !ok {
+		return nil, fmt.Errorf("%w: no fixture constituents for index %q", datasource.ErrNotSupported, index)
+	}
+	return tickers, nil
+}
+
+// FetchHistoricalData returns all fixture bars for ticker. The requested
+// range and timeframe are ignored so that canned data stays usable as it
+// ages.
+func (f *Fixtures) FetchHistoricalData(_ context.Context, ticker string, _, _ time.Time, _ models.Timeframe) ([]models.OHLCV, error) {
+	bars, ok := f.History[utils.NormalizeTicker(ticker)]
+	if !ok {
+		return nil, fmt.Errorf("fixtures: no history for %s", ticker)
+	}
+	return bars, nil
+}
+
+// FetchMarketOverview returns the fixture market overview.
+func (f *Fixtures) FetchMarketOverview(_ context.Context) (*datasource.MarketOverview, error) {
+	if f.Overview == nil {
+		return &datasource.MarketOverview{FetchedAt: utils.NowIST()}, nil
+	}
+	return f.Overview, nil
+}
+
+// FetchFIIDIIActivity returns the fixture FII/DII activity.
+func (f *Fixtures) FetchFIIDIIActivity(_ context.Context) (*models.FIIDIIData, error) {
+	if f.FIIDII == nil {
+		return nil, fmt.Errorf("fixtures: no FII/DII data")
+	}
+	return f.FIIDII, nil
+}
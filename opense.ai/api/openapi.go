@@ -0,0 +1,357 @@
+package api
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/seenimoa/openseai/internal/agent"
+	"github.com/seenimoa/openseai/internal/broker"
+	"github.com/seenimoa/openseai/internal/config"
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// apiPrefix is the mount point of the versioned API, and the server URL
+// of the OpenAPI document; spec paths are relative to it.
+const apiPrefix = "/api/v1"
+
+// apiParam is a query parameter of an API operation.
+type apiParam struct {
+	Name        string
+	Type        string // "string" or "integer"
+	Description string
+}
+
+// apiOperation describes an API route for the OpenAPI document. Request
+// and Response are zero values of the body and of the data field of the
+// APIResponse envelope; nil means none, or an untyped object.
+type apiOperation struct {
+	Summary  string
+	Tag      string
+	Query    []apiParam
+	Request  any
+	Response any
+}
+
+var (
+	paginationParams = []apiParam{
+		{"limit", "integer", fmt.Sprintf("page size (default %d, max %d); without limit or offset every item is returned as a plain array", defaultPageLimit, maxPageLimit)},
+		{"offset", "integer", "items to skip"},
+	}
+	maxPointsParam = apiParam{"max_points", "integer", "downsample each series to at most this many points"}
+)
+
+// apiOperations documents the routes under apiPrefix, keyed by method and
+// path. Routes missing here are still listed in the document, untyped.
+var apiOperations = map[string]apiOperation{
+	"GET /health":       {Summary: "Server health", Tag: "system"},
+	"GET /openapi.json": {Summary: "This OpenAPI document", Tag: "system"},
+
+	"POST /analyze":       {Summary: "Analyze a stock with one agent, or the full team when deep is set", Tag: "analysis", Request: AnalyzeRequest{}, Response: agent.AgentResult{}},
+	"GET /quote/{ticker}": {Summary: "Latest quote", Tag: "market", Response: models.Quote{}},
+
+	"POST /backtest":        {Summary: "Backtest a strategy on a ticker", Tag: "backtest", Request: BacktestRequest{}, Response: models.BacktestResult{}},
+	"POST /backtest/stream": {Summary: "Backtest with server-sent progress events", Tag: "backtest", Request: BacktestRequest{}},
+	"GET /strategies":       {Summary: "Built-in strategies and their parameters", Tag: "backtest", Response: []StrategyInfo{}},
+
+	"GET /portfolio": {Summary: "Margins, positions, holdings and orders", Tag: "portfolio", Query: paginationParams},
+	"POST /chat":     {Summary: "Chat with the agents", Tag: "analysis", Request: ChatRequest{}},
+
+	"POST /query":         {Summary: "Evaluate a FinanceQL expression", Tag: "financeql", Query: []apiParam{maxPointsParam}, Request: QueryRequest{}, Response: QueryResult{}},
+	"POST /query/batch":   {Summary: "Evaluate several FinanceQL expressions", Tag: "financeql", Query: []apiParam{maxPointsParam}, Request: QueryBatchRequest{}, Response: []QueryBatchItem{}},
+	"POST /query/explain": {Summary: "Parse a FinanceQL expression without evaluating it", Tag: "financeql", Request: QueryRequest{}, Response: QueryExplainResponse{}},
+	"POST /query/nl":      {Summary: "Translate a natural-language question to FinanceQL and evaluate it", Tag: "financeql", Query: []apiParam{maxPointsParam}, Request: QueryNLRequest{}},
+
+	"GET /alerts":         {Summary: "Active alerts", Tag: "alerts", Response: []AlertInfo{}},
+	"POST /alerts":        {Summary: "Create an alert", Tag: "alerts", Request: CreateAlertRequest{}, Response: AlertInfo{}},
+	"DELETE /alerts/{id}": {Summary: "Delete an alert", Tag: "alerts"},
+
+	"GET /orders":         {Summary: "Today's orders", Tag: "trading", Query: paginationParams, Response: Page[models.Order]{}},
+	"GET /orders/{id}":    {Summary: "One order", Tag: "trading", Response: models.Order{}},
+	"POST /orders":        {Summary: "Place an order through the risk manager", Tag: "trading", Request: models.OrderRequest{}, Response: models.OrderResponse{}},
+	"PUT /orders/{id}":    {Summary: "Modify an open order", Tag: "trading", Request: models.OrderRequest{}, Response: models.OrderResponse{}},
+	"DELETE /orders/{id}": {Summary: "Cancel an open order", Tag: "trading"},
+	"GET /positions":      {Summary: "Open positions", Tag: "trading", Query: paginationParams, Response: Page[models.Position]{}},
+	"GET /funds":          {Summary: "Account margins", Tag: "trading", Response: models.Margins{}},
+
+	"GET /ohlcv/{ticker}": {Summary: "Historical OHLCV bars", Tag: "market", Query: []apiParam{
+		{"timeframe", "string", "1m, 5m, 15m, 1h, 1d (default) or 1w"},
+		{"days", "integer", "days of history (default 365)"},
+	}, Response: []models.OHLCV{}},
+	"GET /market/indices":  {Summary: "Major index levels", Tag: "market"},
+	"GET /market/movers":   {Summary: "Top gainers or losers", Tag: "market", Query: []apiParam{{"direction", "string", "gainers (default) or losers"}}, Response: []MoverEntry{}},
+	"GET /market/fiidii":   {Summary: "FII and DII activity", Tag: "market", Response: models.FIIDIIData{}},
+	"GET /heatmap/{index}": {Summary: "Daily-change heatmap of an index's constituents", Tag: "market", Response: HeatmapResponse{}},
+
+	"POST /screener":      {Summary: "Screen stocks", Tag: "market", Request: ScreenerRequest{}},
+	"GET /search/tickers": {Summary: "Search tickers by symbol or name", Tag: "market", Query: []apiParam{{"q", "string", "search text"}}},
+
+	"POST /trade/confirm":       {Summary: "Approve, reject or modify a trade proposal", Tag: "trading", Request: TradeConfirmRequest{}},
+	"GET /approvals":            {Summary: "Orders awaiting approval", Tag: "trading", Response: []broker.PendingApproval{}},
+	"POST /approvals/{id}":      {Summary: "Approve or deny a parked order", Tag: "trading", Request: ApprovalDecision{}, Response: models.OrderResponse{}},
+	"POST /webhook/tradingview": {Summary: "Place an order from a signed TradingView alert", Tag: "trading", Query: []apiParam{{"signature", "string", "HMAC-SHA256 of the body, if not sent in " + SignatureHeader}}, Response: models.OrderResponse{}},
+
+	"GET /config":      {Summary: "Running configuration", Tag: "system", Response: ConfigResponse{}},
+	"PUT /config":      {Summary: "Merge and save configuration", Tag: "system", Request: config.Config{}, Response: ConfigResponse{}},
+	"GET /config/keys": {Summary: "Which provider API keys are set", Tag: "system"},
+
+	"GET /ws":        {Summary: "WebSocket for all channels", Tag: "websocket"},
+	"GET /ws/market": {Summary: "WebSocket for market data", Tag: "websocket"},
+	"GET /ws/chat":   {Summary: "WebSocket for chat", Tag: "websocket"},
+	"GET /ws/alerts": {Summary: "WebSocket for alerts", Tag: "websocket"},
+}
+
+// buildOpenAPISpec returns an OpenAPI 3 document of the routes under
+// apiPrefix in r, typed from apiOperations.
+func buildOpenAPISpec(r chi.Routes) ([]byte, error) {
+	gen := &schemaGen{schemas: map[string]any{}, names: map[reflect.Type]string{}}
+	paths := map[string]map[string]any{}
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		p, ok := strings.CutPrefix(route, apiPrefix)
+		if !ok || p == "" {
+			return nil
+		}
+		p = strings.TrimSuffix(p, "/")
+		if paths[p] == nil {
+			paths[p] = map[string]any{}
+		}
+		paths[p][strings.ToLower(method)] = gen.operation(method, p, apiOperations[method+" "+p])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "OpeNSE.ai API",
+			"version":     "v1",
+			"description": "Every response is an APIResponse envelope; the data field holds the payload documented for each operation.",
+		},
+		"servers":    []map[string]string{{"url": apiPrefix}},
+		"paths":      paths,
+		"components": map[string]any{"schemas": gen.schemas},
+	}, "", "  ")
+}
+
+// pathParamRe matches the {name} parameters of a chi route.
+var pathParamRe = regexp.MustCompile(`\{(\w+)\}`)
+
+// operation returns the OpenAPI operation object for one route.
+func (g *schemaGen) operation(method, route string, op apiOperation) map[string]any {
+	summary := op.Summary
+	if summary == "" {
+		summary = method + " " + route
+	}
+	out := map[string]any{"summary": summary}
+	if op.Tag != "" {
+		out["tags"] = []string{op.Tag}
+	}
+
+	var params []map[string]any
+	for _, m := range pathParamRe.FindAllStringSubmatch(route, -1) {
+		params = append(params, map[string]any{
+			"name": m[1], "in": "path", "required": true,
+			"schema": map[string]string{"type": "string"},
+		})
+	}
+	for _, p := range op.Query {
+		params = append(params, map[string]any{
+			"name": p.Name, "in": "query", "description": p.Description,
+			"schema": map[string]string{"type": p.Type},
+		})
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	if op.Request != nil {
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.Request))}},
+		}
+	}
+
+	data := map[string]any{}
+	if op.Response != nil {
+		data = g.schema(reflect.TypeOf(op.Response))
+	}
+	envelope := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"success": map[string]string{"type": "boolean"},
+			"data":    data,
+			"error":   map[string]string{"type": "string"},
+		},
+	}
+	out["responses"] = map[string]any{
+		"200":     map[string]any{"description": "OK", "content": map[string]any{"application/json": map[string]any{"schema": envelope}}},
+		"default": map[string]any{"description": "Error; success is false and error says why"},
+	}
+	return out
+}
+
+// schemaGen builds JSON schemas from Go types, collecting named structs
+// as reusable components.
+type schemaGen struct {
+	schemas map[string]any
+	names   map[reflect.Type]string
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schema returns the JSON schema of t as encoding/json would encode it.
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == durationType:
+		return map[string]any{"type": "integer", "description": "nanoseconds"}
+	case reflect.PointerTo(t).Implements(textMarshalerType) && !reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return map[string]any{"type": "string"}
+	case reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.structRef(t)
+	}
+	return map[string]any{}
+}
+
+// structRef registers t as a component, once, and returns a reference to it.
+func (g *schemaGen) structRef(t reflect.Type) map[string]any {
+	if t.Name() == "" {
+		return g.structSchema(t)
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = g.componentName(t)
+		g.names[t] = name
+		g.schemas[name] = map[string]any{} // placeholder so recursive types terminate
+		g.schemas[name] = g.structSchema(t)
+	}
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// structSchema returns the object schema of a struct's JSON fields,
+// inlining embedded structs as encoding/json does.
+func (g *schemaGen) structSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	g.addFields(t, props)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+func (g *schemaGen) addFields(t reflect.Type, props map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(ft, props)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+	}
+}
+
+// packagePathRe matches the package paths in a generic type's name.
+var packagePathRe = regexp.MustCompile(`[\w./-]+\.`)
+
+// componentName names the component for t: its type name, with type
+// arguments folded in for generics (Page[models.Order] is "PageOrder") and
+// the package prepended if another type already has the name.
+func (g *schemaGen) componentName(t reflect.Type) string {
+	name := strings.NewReplacer("[", "", "]", "", ",", "").Replace(packagePathRe.ReplaceAllString(t.Name(), ""))
+	if _, taken := g.schemas[name]; taken {
+		pkg := path.Base(t.PkgPath())
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	return name
+}
+
+// openAPIHandler serves the OpenAPI document of routes, built on each
+// request so it always matches the registered routes.
+func openAPIHandler(routes chi.Routes) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		spec, err := buildOpenAPISpec(routes)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(spec) //nolint:errcheck
+	}
+}
+
+// swaggerUIPage renders the OpenAPI document with Swagger UI from a CDN.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>OpeNSE.ai API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({url: "` + apiPrefix + `/openapi.json", dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`
+
+func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(swaggerUIPage)) //nolint:errcheck
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Pagination limits for list endpoints.
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// Page is the response wrapper for paginated list endpoints.
+type Page[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"` // number of items before pagination
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// parsePagination reads ?limit= and ?offset= from the request. With
+// neither set it returns a zero limit: the caller lists every item. With
+// only offset set, limit defaults to defaultPageLimit; it is capped at
+// maxPageLimit.
+func parsePagination(r *http.Request) (limit, offset int, err error) {
+	q := r.URL.Query()
+	if q.Get("limit") == "" && q.Get("offset") == "" {
+		return 0, 0, nil
+	}
+	limit = defaultPageLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return 0, 0, fmt.Errorf("invalid limit %q; must be a positive integer", v)
+		}
+		if limit > maxPageLimit {
+			limit = maxPageLimit
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid offset %q; must be a non-negative integer", v)
+		}
+	}
+	return limit, offset, nil
+}
+
+// parseMaxPoints reads ?max_points= from the request: the most points a
+// vector or matrix series in the response may hold. Zero, the default,
+// leaves series at full length.
+func parseMaxPoints(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("max_points")
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid max_points %q; must be a positive integer", v)
+	}
+	return n, nil
+}
+
+// paginate returns the page of items selected by limit and offset.
+func paginate[T any](items []T, limit, offset int) Page[T] {
+	page := Page[T]{Items: []T{}, Total: len(items), Limit: limit, Offset: offset}
+	if offset >= len(items) {
+		return page
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	page.Items = items[offset:end]
+	return page
+}
+
+// listOrPage returns items as they are when no page was requested (limit
+// is zero), so clients written against the plain arrays keep working, and
+// otherwise the Page selected by limit and offset.
+func listOrPage[T any](items []T, limit, offset int) any {
+	if limit == 0 {
+		return items
+	}
+	return paginate(items, limit, offset)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
+)
+
+// defaultRefreshInterval is used when StartQuoteRefresher is given a
+// non-positive interval.
+const defaultRefreshInterval = 30 * time.Second
+
+// quoteCache holds quotes fetched by the refresher. A quote is served
+// while it is younger than ttl, so a missed refresh cycle falls back to a
+// live fetch rather than serving stale prices indefinitely.
+type quoteCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]cachedQuote
+}
+
+type cachedQuote struct {
+	quote     *models.Quote
+	fetchedAt time.Time
+}
+
+func newQuoteCache(ttl time.Duration) *quoteCache {
+	return &quoteCache{ttl: ttl, entries: make(map[string]cachedQuote)}
+}
+
+// get returns a fresh cached quote. It is safe to call on a nil cache.
+func (c *quoteCache) get(ticker string) (*models.Quote, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[ticker]
+	if !ok || time.Since(e.fetchedAt) > c.ttl {
+		return nil, false
+	}
+	return e.quote, true
+}
+
+// put stores q and reports whether it differs from the previous quote for
+// ticker in price or timestamp.
+func (c *quoteCache) put(ticker string, q *models.Quote) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev, ok := c.entries[ticker]
+	c.entries[ticker] = cachedQuote{quote: q, fetchedAt: time.Now()}
+	return !ok || prev.quote.LastPrice != q.LastPrice || !prev.quote.Timestamp.Equal(q.Timestamp)
+}
+
+// StartQuoteRefresher fetches quotes for tickers immediately and then every
+// interval until ctx is cancelled. Fetched quotes are served by the quote
+// endpoint, and each quote that changed since the previous cycle is
+// broadcast to WebSocket clients as a "quote" message. It returns at once;
+// call it before serving requests.
+func (s *Server) StartQuoteRefresher(ctx context.Context, tickers []string, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+	normalized := make([]string, 0, len(tickers))
+	for _, t := range tickers {
+		if t = utils.NormalizeTicker(t); t != "" {
+			normalized = append(normalized, t)
+		}
+	}
+	s.quotes = newQuoteCache(2 * interval)
+
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			s.refreshQuotes(ctx, normalized, interval)
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+// refreshQuotes runs one refresh cycle, fetching all tickers concurrently.
+func (s *Server) refreshQuotes(ctx context.Context, tickers []string, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, ticker := range tickers {
+		wg.Add(1)
+		go func(ticker string) {
+			defer wg.Done()
+			q, err := s.agg.GetQuote(ctx, ticker)
+			if err != nil || q == nil {
+				if ctx.Err() == nil {
+					log.Printf("api: quote refresh for %s failed: %v", ticker, err)
+				}
+				return
+			}
+			if s.quotes.put(ticker, q) {
+				s.wsHub.Broadcast(WSMessage{Type: "quote", Data: q})
+			}
+		}(ticker)
+	}
+	wg.Wait()
+}
@@ -7,6 +7,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
@@ -24,6 +25,7 @@ import (
 	"github.com/go-chi/cors"
 
 	"github.com/seenimoa/openseai/internal/agent"
+	"github.com/seenimoa/openseai/internal/agent/prompts"
 	"github.com/seenimoa/openseai/internal/backtest"
 	"github.com/seenimoa/openseai/internal/broker"
 	"github.com/seenimoa/openseai/internal/config"
@@ -40,16 +42,29 @@ type Server struct {
 	router   chi.Router
 	cfg      *config.Config
 	orch     *agent.Orchestrator
-	agg      *datasource.Aggregator
+	agg      AggregatorLike
+	heatmap  datasource.HeatmapSource
 	broker   broker.Broker
 	riskMgr  *broker.RiskManager
 	wsHub    *WSHub
 	serveUI  bool // when true, serve the embedded web UI at /
+	tls      *TLSConfig // when set, serve HTTPS (see SetTLS)
+	quotes   *quoteCache // filled by StartQuoteRefresher
+
+	webhookSeen seenSignatures // accepted webhook alerts, against replays
 }
 
 // NewServer creates a configured API server with all routes and middleware.
 func NewServer(cfg *config.Config) (*Server, error) {
 	agg := datasource.NewAggregator()
+	err := agg.SelectSources(datasource.SourceSelection{
+		Quotes:       cfg.DataSource.Quotes,
+		Fundamentals: cfg.DataSource.Fundamentals,
+		Options:      cfg.DataSource.Options,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("datasource config: %w", err)
+	}
 
 	router, err := llm.NewRouterFromConfig(cfg)
 	if err != nil {
@@ -63,11 +78,20 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	}
 
 	orch := agent.NewOrchestrator(agent.OrchestratorConfig{
-		Provider:    router,
-		Aggregator:  agg,
-		ChatOptions: opts,
-		DefaultMode: agent.ModeSingle,
-		Capital:     cfg.Trading.InitialCapital,
+		Provider:        router,
+		Aggregator:      agg,
+		ChatOptions:     opts,
+		DefaultMode:     agent.ModeSingle,
+		Capital:         cfg.Trading.InitialCapital,
+		AgentTimeout:    time.Duration(cfg.Analysis.AgentTimeoutSec) * time.Second,
+		MinConfidence:   cfg.Analysis.MinConfidence,
+		DebateThreshold: cfg.Analysis.DebateThreshold,
+		ExecutorLimits: &agent.ExecutorLimits{
+			MaxPositionPct: cfg.Trading.MaxPositionPct,
+			MaxLossPct:     cfg.Trading.DailyLossLimitPct,
+			MinRiskReward:  cfg.Trading.MinRiskReward,
+			MinConfidence:  cfg.Trading.MinTradeConfidence,
+		},
 	})
 
 	b := broker.NewPaperBroker(nil)
@@ -75,12 +99,24 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	riskCfg.MaxPositionPct = cfg.Trading.MaxPositionPct
 	riskCfg.DailyLossLimitPct = cfg.Trading.DailyLossLimitPct
 	riskCfg.MaxOpenPositions = cfg.Trading.MaxOpenPositions
+	if cfg.Trading.ApprovalQueue {
+		riskCfg.RequireApproval = true
+		riskCfg.ParkApprovals = true // approved via /api/v1/approvals
+	}
 	rm := broker.NewRiskManager(b, riskCfg)
 
+	// Journal every order, fill, and approval, as the trade command does
+	journal, err := broker.OpenTradeLogger(config.TradeJournalPath())
+	if err != nil {
+		return nil, err
+	}
+	rm.SetLogger(journal)
+
 	srv := &Server{
 		cfg:     cfg,
 		orch:    orch,
 		agg:     agg,
+		heatmap: agg,
 		broker:  b,
 		riskMgr: rm,
 		wsHub:   NewWSHub(),
@@ -91,6 +127,45 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	return srv, nil
 }
 
+// AggregatorLike is the market data the API handlers depend on.
+// *datasource.Aggregator implements it; tests and demos can substitute
+// a fake with SetAggregator.
+type AggregatorLike interface {
+	datasource.HeatmapSource
+	FetchHistoricalData(ctx context.Context, ticker string, from, to time.Time, tf models.Timeframe) ([]models.OHLCV, error)
+	FetchMarketOverview(ctx context.Context) (*datasource.MarketOverview, error)
+	FetchFIIDIIActivity(ctx context.Context) (*models.FIIDIIData, error)
+}
+
+// SetAggregator replaces the market data source used by the quote,
+// history, market, heatmap, and backtest handlers. The agent orchestrator
+// keeps using live data; FinanceQL queries answer 503 unless agg is a
+// *datasource.Aggregator.
+func (s *Server) SetAggregator(agg AggregatorLike) {
+	s.agg = agg
+	s.heatmap = agg
+}
+
+// errQueryUnavailable is returned for FinanceQL queries while a substitute
+// data source, such as serve --fixtures, is installed.
+var errQueryUnavailable = errors.New("FinanceQL queries need live market data and are unavailable with a substitute data source")
+
+// newEvalContext returns a FinanceQL evaluation context over the server's
+// aggregator, with the configured named universes, or errQueryUnavailable
+// when a substitute data source is installed. Without any data source only
+// expressions that need no market data can be evaluated.
+func (s *Server) newEvalContext(ctx context.Context) (*financeql.EvalContext, error) {
+	agg, ok := s.agg.(*datasource.Aggregator)
+	if !ok && s.agg != nil {
+		return nil, errQueryUnavailable
+	}
+	ec := financeql.NewEvalContext(ctx, agg)
+	if s.cfg != nil {
+		ec.Universes = s.cfg.Universes
+	}
+	return ec, nil
+}
+
 // SetServeUI controls whether the embedded web UI is served.
 // Must be called before ListenAndServe.
 func (s *Server) SetServeUI(enabled bool) {
@@ -116,15 +191,44 @@ func (s *Server) ListenAndServe(addr string) error {
 	// Start WebSocket hub
 	go s.wsHub.Run()
 
+	refreshCtx, stopRefresh := context.WithCancel(context.Background())
+	defer stopRefresh()
+	if len(s.cfg.API.RefreshTickers) > 0 {
+		interval := time.Duration(s.cfg.API.RefreshIntervalSec) * time.Second
+		s.StartQuoteRefresher(refreshCtx, s.cfg.API.RefreshTickers, interval)
+	}
+
 	// Graceful shutdown
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
-	go func() {
-		if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("HTTP server error: %v", err)
+	var redirectSrv *http.Server
+	if s.tls != nil {
+		go func() {
+			if err := httpSrv.ListenAndServeTLS(s.tls.CertFile, s.tls.KeyFile); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("HTTPS server error: %v", err)
+			}
+		}()
+		if s.tls.RedirectAddr != "" {
+			redirectSrv = &http.Server{
+				Addr:         s.tls.RedirectAddr,
+				Handler:      RedirectHandler(addr),
+				ReadTimeout:  10 * time.Second,
+				WriteTimeout: 10 * time.Second,
+			}
+			go func() {
+				if err := redirectSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					log.Fatalf("HTTP redirect server error: %v", err)
+				}
+			}()
 		}
-	}()
+	} else {
+		go func() {
+			if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("HTTP server error: %v", err)
+			}
+		}()
+	}
 
 	<-done
 	log.Println("Shutting down server...")
@@ -132,6 +236,9 @@ func (s *Server) ListenAndServe(addr string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
+	if redirectSrv != nil {
+		redirectSrv.Shutdown(ctx)
+	}
 	return httpSrv.Shutdown(ctx)
 }
 
@@ -176,6 +283,8 @@ func (s *Server) buildRouter() chi.Router {
 
 		// Backtest
 		r.Post("/backtest", s.handleBacktest)
+		r.Post("/backtest/stream", s.handleBacktestStream)
+		r.Get("/strategies", s.handleStrategies)
 
 		// Portfolio
 		r.Get("/portfolio", s.handlePortfolio)
@@ -185,6 +294,7 @@ func (s *Server) buildRouter() chi.Router {
 
 		// FinanceQL
 		r.Post("/query", s.handleQuery)
+		r.Post("/query/batch", s.handleQueryBatch)
 		r.Post("/query/explain", s.handleQueryExplain)
 		r.Post("/query/nl", s.handleQueryNL)
 
@@ -211,6 +321,7 @@ func (s *Server) buildRouter() chi.Router {
 		r.Get("/market/indices", s.handleMarketIndices)
 		r.Get("/market/movers", s.handleTopMovers)
 		r.Get("/market/fiidii", s.handleFIIDII)
+		r.Get("/heatmap/{index}", s.handleHeatmap)
 
 		// Screener
 		r.Post("/screener", s.handleScreener)
@@ -220,6 +331,11 @@ func (s *Server) buildRouter() chi.Router {
 
 		// Trade confirmation (HITL)
 		r.Post("/trade/confirm", s.handleTradeConfirm)
+		r.Get("/approvals", s.handleGetApprovals)
+		r.Post("/approvals/{id}", s.handleResolveApproval)
+
+		// Alert webhooks
+		r.Post("/webhook/tradingview", s.handleTradingViewWebhook)
 
 		// Configuration
 		r.Get("/config", s.handleGetConfig)
@@ -233,6 +349,10 @@ func (s *Server) buildRouter() chi.Router {
 		r.Get("/ws/alerts", s.handleWebSocket)
 	})
 
+	// OpenAPI document of the routes above, and Swagger UI to browse it
+	r.Get(apiPrefix+"/openapi.json", openAPIHandler(r))
+	r.Get("/docs", s.handleDocs)
+
 	// Serve embedded web UI (SPA with fallback to index.html)
 	if s.serveUI {
 		s.mountSPA(r, web.DistFS())
@@ -313,6 +433,15 @@ type BacktestRequest struct {
 	Capital  float64 `json:"capital,omitempty"`
 }
 
+// StrategyInfo describes a built-in strategy for GET /api/v1/strategies.
+// ID is the value to send as BacktestRequest.Strategy.
+type StrategyInfo struct {
+	ID          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description,omitempty"`
+	Params      []backtest.StrategyParam `json:"params"`
+}
+
 // ChatRequest is the body for POST /api/v1/chat.
 type ChatRequest struct {
 	Message string        `json:"message"`
@@ -328,9 +457,31 @@ type ChatMessage struct {
 
 // QueryRequest is the body for POST /api/v1/query.
 type QueryRequest struct {
-	Expression string `json:"expression"`
+	Expression  string  `json:"expression"`
+	Precision   int     `json:"precision,omitempty"`    // decimals for a formatted scalar; 0 picks by magnitude
+	MinVolume   float64 `json:"min_volume,omitempty"`   // screener: minimum average daily volume
+	MinTurnover float64 `json:"min_turnover,omitempty"` // screener: minimum average daily turnover (INR)
+}
+
+// QueryBatchRequest is the body for POST /api/v1/query/batch.
+type QueryBatchRequest struct {
+	Expressions []string `json:"expressions"`
+	Precision   int      `json:"precision,omitempty"`
+	MinVolume   float64  `json:"min_volume,omitempty"`
+	MinTurnover float64  `json:"min_turnover,omitempty"`
+}
+
+// QueryBatchItem is the outcome of one expression in a batch query.
+type QueryBatchItem struct {
+	Expression string       `json:"expression"`
+	Success    bool         `json:"success"`
+	Result     *QueryResult `json:"result,omitempty"`
+	Error      string       `json:"error,omitempty"`
 }
 
+// maxQueryBatch bounds the number of expressions in one batch query.
+const maxQueryBatch = 50
+
 // QueryNLRequest is the body for POST /api/v1/query/nl.
 type QueryNLRequest struct {
 	Query string `json:"query"`
@@ -346,8 +497,9 @@ type QueryExplainResponse struct {
 
 // QueryResult represents a FinanceQL evaluation result.
 type QueryResult struct {
-	Type   string      `json:"type"`
-	Value  interface{} `json:"value"`
+	Type      string      `json:"type"`
+	Value     interface{} `json:"value"`
+	Formatted string      `json:"formatted,omitempty"` // display string for scalars
 }
 
 // MoverEntry represents a top mover stock.
@@ -400,7 +552,7 @@ func (s *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
 	var result *agent.AgentResult
 	var err error
 	if req.Deep {
-		result, err = s.orch.FullAnalysis(ctx, ticker)
+		result, err = s.streamAnalysis(ctx, ticker)
 	} else {
 		result, err = s.orch.QuickQuery(ctx, fmt.Sprintf("Analyze %s stock", ticker))
 	}
@@ -424,6 +576,43 @@ func (s *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// streamAnalysis runs a multi-agent analysis of ticker, streaming the CIO
+// synthesis through agent.ReadAnalysisStream so that its recommendation is
+// broadcast as "analysis_ready" as soon as it is complete, before the
+// report is written.
+func (s *Server) streamAnalysis(ctx context.Context, ticker string) (*agent.AgentResult, error) {
+	events, err := s.orch.AnalyzeStream(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	cio := make(chan llm.StreamChunk, 64)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defaults := models.AnalysisResult{Ticker: ticker, AgentName: prompts.AgentCIO}
+		_, _, _ = agent.ReadAnalysisStream(cio, defaults, s.wsHub.AnalysisReady(ticker))
+	}()
+
+	var last agent.AgentStreamEvent
+	for ev := range events {
+		if ev.Agent == prompts.AgentCIO && ev.Delta != "" {
+			cio <- llm.StreamChunk{Content: ev.Delta}
+		}
+		last = ev
+	}
+	close(cio)
+	<-done
+
+	if last.Result == nil && last.Err == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("analysis ended without a result")
+	}
+	return last.Result, last.Err
+}
+
 func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request) {
 	ticker := chi.URLParam(r, "ticker")
 	if ticker == "" {
@@ -432,10 +621,18 @@ func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ticker = utils.NormalizeTicker(ticker)
+	if quote, ok := s.quotes.get(ticker); ok {
+		writeJSON(w, http.StatusOK, APIResponse{
+			Success: true,
+			Data:    quote,
+		})
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	quote, err := s.agg.YFinance().GetQuote(ctx, ticker)
+	quote, err := s.agg.GetQuote(ctx, ticker)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -447,39 +644,48 @@ func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (s *Server) handleBacktest(w http.ResponseWriter, r *http.Request) {
+// backtestJob is a validated backtest request with its data loaded.
+type backtestJob struct {
+	strategy backtest.Strategy
+	ticker   string
+	bars     []models.OHLCV
+	cfg      backtest.Config
+}
+
+// prepareBacktest decodes and validates a BacktestRequest and fetches its
+// historical data. On failure it returns the HTTP status to report.
+func (s *Server) prepareBacktest(r *http.Request) (*backtestJob, int, error) {
 	var req BacktestRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid request body")
-		return
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid request body")
 	}
 
 	if req.Strategy == "" || req.Ticker == "" {
-		writeError(w, http.StatusBadRequest, "strategy and ticker are required")
-		return
+		return nil, http.StatusBadRequest, fmt.Errorf("strategy and ticker are required")
 	}
 
 	ticker := utils.NormalizeTicker(req.Ticker)
 
 	from, err := time.Parse("2006-01-02", req.From)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid from date; use YYYY-MM-DD")
-		return
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid from date; use YYYY-MM-DD")
 	}
 	to := time.Now()
 	if req.To != "" {
 		to, err = time.Parse("2006-01-02", req.To)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid to date; use YYYY-MM-DD")
-			return
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid to date; use YYYY-MM-DD")
 		}
 	}
+	from, to, err = backtest.TradingRange(from, to)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
 
 	// Find strategy
 	strategy := findStrategy(req.Strategy)
 	if strategy == nil {
-		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown strategy: %s", req.Strategy))
-		return
+		return nil, http.StatusBadRequest, fmt.Errorf("unknown strategy: %s", req.Strategy)
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
@@ -487,13 +693,11 @@ func (s *Server) handleBacktest(w http.ResponseWriter, r *http.Request) {
 
 	bars, err := s.agg.FetchHistoricalData(ctx, ticker, from, to, models.Timeframe1Day)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch data: %v", err))
-		return
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to fetch data: %v", err)
 	}
 
 	if len(bars) < 50 {
-		writeError(w, http.StatusBadRequest, fmt.Sprintf("insufficient data: %d bars", len(bars)))
-		return
+		return nil, http.StatusBadRequest, fmt.Errorf("insufficient data: %d bars", len(bars))
 	}
 
 	btCfg := backtest.DefaultConfig()
@@ -503,8 +707,18 @@ func (s *Server) handleBacktest(w http.ResponseWriter, r *http.Request) {
 		btCfg.InitialCapital = s.cfg.Trading.InitialCapital
 	}
 
-	engine := backtest.NewEngine(btCfg)
-	result, err := engine.Run(strategy, ticker, bars)
+	return &backtestJob{strategy: strategy, ticker: ticker, bars: bars, cfg: btCfg}, http.StatusOK, nil
+}
+
+func (s *Server) handleBacktest(w http.ResponseWriter, r *http.Request) {
+	job, status, err := s.prepareBacktest(r)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+
+	engine := backtest.NewEngine(job.cfg)
+	result, err := engine.Run(job.strategy, job.ticker, job.bars)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -516,7 +730,95 @@ func (s *Server) handleBacktest(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleStrategies lists the built-in strategies with their parameter
+// schemas, so clients can build a backtest form.
+func (s *Server) handleStrategies(w http.ResponseWriter, r *http.Request) {
+	strategies := backtest.BuiltinStrategies()
+	infos := make([]StrategyInfo, 0, len(strategies))
+	for _, st := range strategies {
+		info := StrategyInfo{
+			ID:     strings.ToLower(strings.ReplaceAll(st.Name(), " ", "_")),
+			Name:   st.Name(),
+			Params: st.Params(),
+		}
+		if d, ok := st.(backtest.Describer); ok {
+			info.Description = d.Description()
+		}
+		if info.Params == nil {
+			info.Params = []backtest.StrategyParam{}
+		}
+		infos = append(infos, info)
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    infos,
+	})
+}
+
+// handleBacktestStream runs a backtest and streams it as server-sent events:
+// "progress" events carrying backtest.Progress, then a single "result" event
+// with the BacktestResult, or an "error" event if the run fails.
+func (s *Server) handleBacktestStream(w http.ResponseWriter, r *http.Request) {
+	job, status, err := s.prepareBacktest(r)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	type runOutcome struct {
+		result *models.BacktestResult
+		err    error
+	}
+	progress := make(chan backtest.Progress)
+	done := make(chan runOutcome, 1)
+	go func() {
+		result, err := backtest.NewEngine(job.cfg).RunWithProgress(job.strategy, job.ticker, job.bars, progress)
+		done <- runOutcome{result, err}
+	}()
+
+	for p := range progress {
+		writeSSE(w, "progress", p)
+		flusher.Flush()
+	}
+
+	out := <-done
+	if out.err != nil {
+		writeSSE(w, "error", map[string]string{"error": out.err.Error()})
+	} else {
+		writeSSE(w, "result", out.result)
+	}
+	flusher.Flush()
+}
+
+// writeSSE writes one server-sent event with a JSON-encoded data payload.
+func writeSSE(w http.ResponseWriter, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		payload, _ = json.Marshal(map[string]string{"error": err.Error()})
+		event = "error"
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
+
 func (s *Server) handlePortfolio(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
@@ -550,7 +852,7 @@ func (s *Server) handlePortfolio(w http.ResponseWriter, r *http.Request) {
 			"margins":   margins,
 			"positions": positions,
 			"holdings":  holdings,
-			"orders":    orders,
+			"orders":    listOrPage(orders, limit, offset),
 		},
 	})
 }
@@ -605,6 +907,12 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	maxPoints, err := parseMaxPoints(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	var req QueryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
@@ -619,8 +927,14 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
-	ec := financeql.NewEvalContext(ctx, s.agg)
+	ec, err := s.newEvalContext(ctx)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
 	financeql.RegisterBuiltins(ec)
+	ec.Precision = req.Precision
+	ec.Liquidity = datasource.LiquidityFilter{MinVolume: req.MinVolume, MinTurnover: req.MinTurnover}
 
 	val, err := financeql.EvalQuery(ec, req.Expression)
 	if err != nil {
@@ -628,9 +942,76 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	result := valueToQueryResult(downsampleValue(val, maxPoints))
+	if val.Type == financeql.TypeScalar {
+		result.Formatted = ec.FormatScalar(val.Scalar)
+	}
 	writeJSON(w, http.StatusOK, APIResponse{
 		Success: true,
-		Data:    valueToQueryResult(val),
+		Data:    result,
+	})
+}
+
+// handleQueryBatch evaluates several expressions against one EvalContext,
+// so data fetched for one expression is reused by the others. A failing
+// expression is reported in its own entry and does not fail the batch.
+func (s *Server) handleQueryBatch(w http.ResponseWriter, r *http.Request) {
+	maxPoints, err := parseMaxPoints(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req QueryBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if len(req.Expressions) == 0 {
+		writeError(w, http.StatusBadRequest, "expressions is required")
+		return
+	}
+	if len(req.Expressions) > maxQueryBatch {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d expressions per batch", maxQueryBatch))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	ec, err := s.newEvalContext(ctx)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	financeql.RegisterBuiltins(ec)
+	ec.Precision = req.Precision
+	ec.Liquidity = datasource.LiquidityFilter{MinVolume: req.MinVolume, MinTurnover: req.MinTurnover}
+
+	items := make([]QueryBatchItem, len(req.Expressions))
+	for i, expr := range req.Expressions {
+		items[i].Expression = expr
+		if strings.TrimSpace(expr) == "" {
+			items[i].Error = "expression is required"
+			continue
+		}
+		val, err := financeql.EvalQuery(ec, expr)
+		if err != nil {
+			items[i].Error = err.Error()
+			continue
+		}
+		result := valueToQueryResult(downsampleValue(val, maxPoints))
+		if val.Type == financeql.TypeScalar {
+			result.Formatted = ec.FormatScalar(val.Scalar)
+		}
+		items[i].Success = true
+		items[i].Result = &result
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    items,
 	})
 }
 
@@ -666,6 +1047,12 @@ func (s *Server) handleQueryExplain(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleQueryNL(w http.ResponseWriter, r *http.Request) {
+	maxPoints, err := parseMaxPoints(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	var req QueryNLRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
@@ -692,7 +1079,11 @@ func (s *Server) handleQueryNL(w http.ResponseWriter, r *http.Request) {
 	fqlExpr := strings.TrimSpace(result.Content)
 
 	// Execute the translated expression
-	ec := financeql.NewEvalContext(ctx, s.agg)
+	ec, err := s.newEvalContext(ctx)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
 	financeql.RegisterBuiltins(ec)
 	val, err := financeql.EvalQuery(ec, fqlExpr)
 	if err != nil {
@@ -712,7 +1103,7 @@ func (s *Server) handleQueryNL(w http.ResponseWriter, r *http.Request) {
 		Data: map[string]interface{}{
 			"original_query": req.Query,
 			"translated":     fqlExpr,
-			"result":         valueToQueryResult(val),
+			"result":         valueToQueryResult(downsampleValue(val, maxPoints)),
 		},
 	})
 }
@@ -770,6 +1161,12 @@ func (s *Server) handleDeleteAlert(w http.ResponseWriter, r *http.Request) {
 // ============================================================
 
 func (s *Server) handleGetOrders(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
@@ -781,7 +1178,7 @@ func (s *Server) handleGetOrders(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, http.StatusOK, APIResponse{
 		Success: true,
-		Data:    orders,
+		Data:    listOrPage(orders, limit, offset),
 	})
 }
 
@@ -827,11 +1224,38 @@ func (s *Server) handlePlaceOrder(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	resp, err := s.broker.PlaceOrder(ctx, req)
+	// Orders needing human sign-off go through the risk manager, which
+	// parks them for /approvals.
+	var b broker.Broker = s.broker
+	if s.riskMgr != nil && s.riskMgr.Config().RequireApproval {
+		b = s.riskMgr
+	}
+	resp, err := b.PlaceOrder(ctx, req)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	s.respondOrder(w, req, resp)
+}
+
+// respondOrder broadcasts a placed or parked order over WebSocket and
+// writes it as 201 Created, or 202 Accepted when awaiting approval.
+func (s *Server) respondOrder(w http.ResponseWriter, req models.OrderRequest, resp *models.OrderResponse) {
+	if resp.Status == broker.StatusPendingApproval {
+		s.wsHub.Broadcast(WSMessage{
+			Type: "approval_pending",
+			Data: map[string]interface{}{
+				"approval_id": resp.ApprovalID,
+				"ticker":      req.Ticker,
+				"side":        req.Side,
+			},
+		})
+		writeJSON(w, http.StatusAccepted, APIResponse{
+			Success: true,
+			Data:    resp,
+		})
+		return
+	}
 
 	// Broadcast order event via WebSocket
 	s.wsHub.Broadcast(WSMessage{
@@ -904,6 +1328,12 @@ func (s *Server) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
 // ============================================================
 
 func (s *Server) handleGetPositions(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
@@ -915,7 +1345,7 @@ func (s *Server) handleGetPositions(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, http.StatusOK, APIResponse{
 		Success: true,
-		Data:    positions,
+		Data:    listOrPage(positions, limit, offset),
 	})
 }
 
@@ -1067,7 +1497,7 @@ func (s *Server) handleTopMovers(w http.ResponseWriter, r *http.Request) {
 		wg.Add(1)
 		go func(ticker string) {
 			defer wg.Done()
-			q, err := s.agg.YFinance().GetQuote(ctx, ticker)
+			q, err := s.agg.GetQuote(ctx, ticker)
 			if err != nil || q == nil {
 				return
 			}
@@ -1109,7 +1539,7 @@ func (s *Server) handleFIIDII(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	data, err := s.agg.FIIDII().GetFIIDIIActivity(ctx)
+	data, err := s.agg.FetchFIIDIIActivity(ctx)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -1121,6 +1551,41 @@ func (s *Server) handleFIIDII(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// HeatmapResponse is the payload for GET /api/v1/heatmap/{index}.
+type HeatmapResponse struct {
+	Index        string               `json:"index"`
+	Constituents []models.HeatmapCell `json:"constituents"`
+}
+
+func (s *Server) handleHeatmap(w http.ResponseWriter, r *http.Request) {
+	index := chi.URLParam(r, "index")
+	if index == "" {
+		writeError(w, http.StatusBadRequest, "index is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	cells, err := datasource.FetchHeatmap(ctx, s.heatmap, index)
+	if err != nil {
+		if errors.Is(err, datasource.ErrNotSupported) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data: HeatmapResponse{
+			Index:        utils.NormalizeTicker(index),
+			Constituents: cells,
+		},
+	})
+}
+
 // ============================================================
 // Screener & Search handlers
 // ============================================================
@@ -1244,6 +1709,76 @@ func (s *Server) handleTradeConfirm(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ApprovalDecision is the body for POST /api/v1/approvals/{id}.
+type ApprovalDecision struct {
+	Action string `json:"action"` // "approve" or "deny"
+	Reason string `json:"reason,omitempty"`
+}
+
+func (s *Server) handleGetApprovals(w http.ResponseWriter, r *http.Request) {
+	if s.riskMgr == nil {
+		writeError(w, http.StatusServiceUnavailable, "risk manager not configured")
+		return
+	}
+	writeJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    s.riskMgr.PendingApprovals(),
+	})
+}
+
+func (s *Server) handleResolveApproval(w http.ResponseWriter, r *http.Request) {
+	if s.riskMgr == nil {
+		writeError(w, http.StatusServiceUnavailable, "risk manager not configured")
+		return
+	}
+	id := chi.URLParam(r, "id")
+
+	var req ApprovalDecision
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	var approve bool
+	switch req.Action {
+	case "approve":
+		approve = true
+	case "deny":
+	default:
+		writeError(w, http.StatusBadRequest, `action must be "approve" or "deny"`)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	resp, err := s.riskMgr.ResolveApproval(ctx, id, approve, req.Reason)
+	switch {
+	case errors.Is(err, broker.ErrApprovalNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, broker.ErrApprovalDenied):
+		// Denial is the requested outcome, not a failure.
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if approve {
+		s.wsHub.Broadcast(WSMessage{
+			Type: "order_placed",
+			Data: map[string]interface{}{
+				"order_id":    resp.OrderID,
+				"approval_id": id,
+				"status":      resp.Status,
+			},
+		})
+	}
+	writeJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    resp,
+	})
+}
+
 // ============================================================
 // Helpers
 // ============================================================
@@ -1274,10 +1809,29 @@ func writeError(w http.ResponseWriter, status int, msg string) {
 	})
 }
 
+// downsampleValue caps each series of a vector or matrix value at
+// maxPoints points; zero leaves val unchanged.
+func downsampleValue(val financeql.Value, maxPoints int) financeql.Value {
+	if maxPoints <= 0 {
+		return val
+	}
+	switch val.Type {
+	case financeql.TypeVector:
+		val.Vector = financeql.Downsample(val.Vector, maxPoints)
+	case financeql.TypeMatrix:
+		m := make(map[string][]financeql.TimePoint, len(val.Matrix))
+		for k, pts := range val.Matrix {
+			m[k] = financeql.Downsample(pts, maxPoints)
+		}
+		val.Matrix = m
+	}
+	return val
+}
+
 func valueToQueryResult(val financeql.Value) QueryResult {
 	switch val.Type {
 	case financeql.TypeScalar:
-		return QueryResult{Type: "scalar", Value: val.Scalar}
+		return QueryResult{Type: "scalar", Value: val.Scalar, Formatted: financeql.FormatScalar(val.Scalar, 0)}
 	case financeql.TypeString:
 		return QueryResult{Type: "string", Value: val.Str}
 	case financeql.TypeBool:
@@ -1383,6 +1937,21 @@ func (h *WSHub) Broadcast(msg WSMessage) {
 	}
 }
 
+// AnalysisReady returns an onReady callback for agent.ReadAnalysisStream
+// that broadcasts the structured result for ticker as an "analysis_ready"
+// message, ahead of the prose that is still streaming.
+func (h *WSHub) AnalysisReady(ticker string) func(*models.AnalysisResult) {
+	return func(r *models.AnalysisResult) {
+		h.Broadcast(WSMessage{
+			Type: "analysis_ready",
+			Data: map[string]interface{}{
+				"ticker": ticker,
+				"result": r,
+			},
+		})
+	}
+}
+
 // ClientCount returns the number of connected WebSocket clients.
 func (h *WSHub) ClientCount() int {
 	h.mu.RLock()
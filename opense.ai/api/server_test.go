@@ -2,20 +2,32 @@ package api
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
+
+	"github.com/seenimoa/openseai/internal/agent"
 	"github.com/seenimoa/openseai/internal/backtest"
 	"github.com/seenimoa/openseai/internal/broker"
 	"github.com/seenimoa/openseai/internal/config"
 	"github.com/seenimoa/openseai/internal/datasource"
 	"github.com/seenimoa/openseai/internal/financeql"
+	"github.com/seenimoa/openseai/internal/llm"
 	"github.com/seenimoa/openseai/pkg/models"
 )
 
@@ -372,6 +384,47 @@ func TestHandleBacktest_UnknownStrategy(t *testing.T) {
 // Chat handler tests (validation only)
 // ════════════════════════════════════════════════════════════════════
 
+func TestHandleStrategies(t *testing.T) {
+	srv := testServer(t)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/strategies", nil)
+	srv.handleStrategies(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	resp := decodeResponse(t, rec)
+	list, ok := resp.Data.([]interface{})
+	if !ok {
+		t.Fatalf("data should be an array, got %T", resp.Data)
+	}
+	for _, item := range list {
+		info, _ := item.(map[string]interface{})
+		if info["id"] != "sma_crossover" {
+			continue
+		}
+		if info["name"] == "" || info["name"] == nil {
+			t.Error("sma_crossover: missing name")
+		}
+		if info["description"] == "" || info["description"] == nil {
+			t.Error("sma_crossover: missing description")
+		}
+		params, ok := info["params"].([]interface{})
+		if !ok || len(params) == 0 {
+			t.Fatalf("sma_crossover: params should be a non-empty array, got %v", info["params"])
+		}
+		if p, _ := params[0].(map[string]interface{}); p["name"] != "fast" {
+			t.Errorf("first param: got %v, want fast", p["name"])
+		}
+		if findStrategy(info["id"].(string)) == nil {
+			t.Error("listed id is not accepted by findStrategy")
+		}
+		return
+	}
+	t.Fatalf("sma_crossover not listed: %v", list)
+}
+
 func TestHandleChat_InvalidJSON(t *testing.T) {
 	srv := testServer(t)
 	rec := httptest.NewRecorder()
@@ -432,6 +485,52 @@ func TestHandleQuery_EmptyExpression(t *testing.T) {
 	}
 }
 
+func TestHandleQuery_ConfiguredUniverse(t *testing.T) {
+	srv := testServer(t)
+	srv.cfg.Universes = map[string][]string{"it": {"TCS", "INFY"}}
+	srv.agg = datasource.NewAggregator()
+
+	rec := httptest.NewRecorder()
+	srv.handleQuery(rec, httptest.NewRequest("POST", "/api/v1/query", strings.NewReader(`{"expression":"universe(\"it\")"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d\nbody: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "INFY") {
+		t.Errorf("expected the configured universe in the result: %s", body)
+	}
+}
+
+func TestHandleQuery_InvalidMaxPoints(t *testing.T) {
+	srv := testServer(t)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/v1/query?max_points=0", strings.NewReader(`{"expression":"1"}`))
+	srv.handleQuery(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if resp := decodeResponse(t, rec); !strings.Contains(resp.Error, "max_points") {
+		t.Errorf("error should mention 'max_points': %q", resp.Error)
+	}
+}
+
+func TestDownsampleValue(t *testing.T) {
+	pts := make([]financeql.TimePoint, 100)
+	for i := range pts {
+		pts[i] = financeql.TimePoint{Time: time.Unix(int64(i), 0), Value: float64(i % 7)}
+	}
+	m := downsampleValue(financeql.MatrixValue(map[string][]financeql.TimePoint{"TCS": pts, "INFY": pts[:5]}), 10)
+	if got := len(m.Matrix["TCS"]); got > 10 {
+		t.Errorf("TCS: got %d points, want at most 10", got)
+	}
+	if got := len(m.Matrix["INFY"]); got != 5 {
+		t.Errorf("INFY: got %d points, want 5 unchanged", got)
+	}
+	if got := len(downsampleValue(financeql.VectorValue(pts), 0).Vector); got != 100 {
+		t.Errorf("max_points 0: got %d points, want 100", got)
+	}
+}
+
 func TestHandleQuery_ValidArithmeticExpression(t *testing.T) {
 	srv := testServer(t)
 	// Wire an aggregator so the handler has what it needs
@@ -464,6 +563,95 @@ func TestHandleQuery_ValidArithmeticExpression(t *testing.T) {
 	}
 }
 
+func TestHandleOpenAPI(t *testing.T) {
+	srv := testServer(t)
+	router := srv.buildRouter()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/openapi.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	var spec struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("spec is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("openapi version: got %q", spec.OpenAPI)
+	}
+	for _, p := range []string{"/analyze", "/backtest"} {
+		if _, ok := spec.Paths[p]["post"]; !ok {
+			t.Errorf("spec lacks POST %s", p)
+		}
+	}
+	if _, ok := spec.Paths["/quote/{ticker}"]["get"]["parameters"]; !ok {
+		t.Error("GET /quote/{ticker} lacks its path parameter")
+	}
+	for _, name := range []string{"BacktestRequest", "BacktestResult", "AgentResult", "PageOrder"} {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("spec lacks schema %s", name)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/docs", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "/api/v1/openapi.json") {
+		t.Errorf("/docs: status %d, body %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleQueryBatch(t *testing.T) {
+	srv := testServer(t)
+	rec := httptest.NewRecorder()
+	body := `{"expressions":["1 + 2", "3 * (", "10 / 4"]}`
+	req := httptest.NewRequest("POST", "/api/v1/query/batch", strings.NewReader(body))
+	srv.buildRouter().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp struct {
+		Success bool             `json:"success"`
+		Data    []QueryBatchItem `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Success || len(resp.Data) != 3 {
+		t.Fatalf("expected 3 results, got %+v", resp)
+	}
+
+	if !resp.Data[0].Success || resp.Data[0].Result.Value != 3.0 {
+		t.Errorf("item 0: got %+v", resp.Data[0])
+	}
+	if resp.Data[1].Success || resp.Data[1].Error == "" || resp.Data[1].Result != nil {
+		t.Errorf("item 1 should fail with an error, got %+v", resp.Data[1])
+	}
+	if resp.Data[1].Expression != "3 * (" {
+		t.Errorf("item 1 expression: got %q", resp.Data[1].Expression)
+	}
+	if !resp.Data[2].Success || resp.Data[2].Result.Value != 2.5 {
+		t.Errorf("item 2: got %+v", resp.Data[2])
+	}
+}
+
+func TestHandleQueryBatch_Empty(t *testing.T) {
+	srv := testServer(t)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/v1/query/batch", strings.NewReader(`{"expressions":[]}`))
+	srv.handleQueryBatch(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Query explain handler tests
 // ════════════════════════════════════════════════════════════════════
@@ -882,6 +1070,87 @@ func TestWSHub_Broadcast(t *testing.T) {
 	hub.Unregister(client2)
 }
 
+func TestWSHub_AnalysisReady(t *testing.T) {
+	hub := NewWSHub()
+	go hub.Run()
+	time.Sleep(10 * time.Millisecond)
+
+	client := &WSClient{hub: hub, send: make(chan WSMessage, 256)}
+	hub.Register(client)
+	time.Sleep(10 * time.Millisecond)
+	defer hub.Unregister(client)
+
+	hub.AnalysisReady("TCS")(&models.AnalysisResult{Recommendation: models.ModerateBuy})
+
+	select {
+	case got := <-client.send:
+		data, _ := got.Data.(map[string]interface{})
+		r, _ := data["result"].(*models.AnalysisResult)
+		if got.Type != "analysis_ready" || data["ticker"] != "TCS" || r == nil || r.Recommendation != models.ModerateBuy {
+			t.Errorf("unexpected message: %+v", got)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("client did not receive analysis_ready")
+	}
+}
+
+// recommendationProvider streams a fixed buy recommendation for every call.
+type recommendationProvider struct{}
+
+func (recommendationProvider) Name() string { return "mock" }
+func (recommendationProvider) Chat(context.Context, []llm.Message, []llm.Tool, *llm.ChatOptions) (*llm.Response, error) {
+	return nil, errors.New("expected a streamed call")
+}
+func (recommendationProvider) ChatStream(context.Context, []llm.Message, []llm.Tool, *llm.ChatOptions) (<-chan llm.StreamChunk, error) {
+	ch := make(chan llm.StreamChunk, 3)
+	ch <- llm.StreamChunk{Content: `{"recommendation": "BUY", `}
+	ch <- llm.StreamChunk{Content: `"confidence": 0.8}`}
+	ch <- llm.StreamChunk{Content: "\n\nReasoning follows.", FinishReason: llm.FinishStop, Done: true}
+	close(ch)
+	return ch, nil
+}
+func (recommendationProvider) Models() []string           { return nil }
+func (recommendationProvider) Ping(context.Context) error { return nil }
+
+func TestHandleAnalyze_DeepBroadcastsAnalysisReady(t *testing.T) {
+	srv := testServer(t)
+	srv.orch = agent.NewOrchestrator(agent.OrchestratorConfig{
+		Provider:   recommendationProvider{},
+		Aggregator: datasource.NewAggregator(),
+	})
+	time.Sleep(10 * time.Millisecond)
+	client := &WSClient{hub: srv.wsHub, send: make(chan WSMessage, 256)}
+	srv.wsHub.Register(client)
+	time.Sleep(10 * time.Millisecond)
+	defer srv.wsHub.Unregister(client)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/v1/analyze", strings.NewReader(`{"ticker":"TCS","deep":true}`))
+	srv.handleAnalyze(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var types []string
+	for len(types) < 2 {
+		select {
+		case got := <-client.send:
+			types = append(types, got.Type)
+			if got.Type == "analysis_ready" {
+				data, _ := got.Data.(map[string]interface{})
+				if r, _ := data["result"].(*models.AnalysisResult); r == nil || r.Recommendation != models.ModerateBuy {
+					t.Errorf("unexpected analysis_ready: %+v", got)
+				}
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("got messages %v, want analysis_ready then analysis_complete", types)
+		}
+	}
+	if types[0] != "analysis_ready" || types[1] != "analysis_complete" {
+		t.Errorf("got messages %v, want analysis_ready then analysis_complete", types)
+	}
+}
+
 func TestWSHub_BroadcastDropsWhenBufferFull(t *testing.T) {
 	hub := NewWSHub()
 	go hub.Run()
@@ -1367,7 +1636,9 @@ func TestErrorResponsesAreValidJSON(t *testing.T) {
 
 // mockBroker implements broker.Broker for testing.
 type mockBroker struct {
-	name string
+	name   string
+	orders []models.Order
+	placed []models.OrderRequest
 }
 
 var _ broker.Broker = (*mockBroker)(nil)
@@ -1393,7 +1664,7 @@ func (b *mockBroker) GetHoldings(ctx context.Context) ([]models.Holding, error)
 }
 
 func (b *mockBroker) GetOrders(ctx context.Context) ([]models.Order, error) {
-	return []models.Order{}, nil
+	return append([]models.Order{}, b.orders...), nil
 }
 
 func (b *mockBroker) GetOrderByID(ctx context.Context, orderID string) (*models.Order, error) {
@@ -1401,6 +1672,7 @@ func (b *mockBroker) GetOrderByID(ctx context.Context, orderID string) (*models.
 }
 
 func (b *mockBroker) PlaceOrder(ctx context.Context, req models.OrderRequest) (*models.OrderResponse, error) {
+	b.placed = append(b.placed, req)
 	return &models.OrderResponse{OrderID: "test-001", Status: "placed"}, nil
 }
 
@@ -1576,3 +1848,683 @@ func TestWriteError_VariousStatusCodes(t *testing.T) {
 		})
 	}
 }
+
+// ════════════════════════════════════════════════════════════════════
+// Heatmap handler with fake aggregator
+// ════════════════════════════════════════════════════════════════════
+
+// fakeHeatmapSource implements datasource.HeatmapSource for testing.
+type fakeHeatmapSource struct {
+	constituents []string
+	quotes       map[string]*models.Quote
+}
+
+var _ datasource.HeatmapSource = (*fakeHeatmapSource)(nil)
+
+func (f *fakeHeatmapSource) IndexConstituents(_ context.Context, index string) ([]string, error) {
+	return f.constituents, nil
+}
+
+func (f *fakeHeatmapSource) GetQuote(_ context.Context, ticker string) (*models.Quote, error) {
+	q, ok := f.quotes[ticker]
+	if !ok {
+		return nil, fmt.Errorf("no quote for %s", ticker)
+	}
+	return q, nil
+}
+
+func TestHandleHeatmap_FakeAggregator(t *testing.T) {
+	srv := testServer(t)
+	srv.heatmap = &fakeHeatmapSource{
+		constituents: []string{"RELIANCE", "TCS", "INFY"},
+		quotes: map[string]*models.Quote{
+			"RELIANCE": {Ticker: "RELIANCE", LastPrice: 2900, ChangePct: 1.5, MarketCap: 200},
+			"TCS":      {Ticker: "TCS", LastPrice: 3800, ChangePct: -0.8, MarketCap: 150},
+			"INFY":     {Ticker: "INFY", LastPrice: 1500, ChangePct: 0.25, MarketCap: 50},
+		},
+	}
+	router := srv.buildRouter()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/heatmap/NIFTY50", nil)
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d\nbody: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var resp struct {
+		Success bool            `json:"success"`
+		Data    HeatmapResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Data.Index != "NIFTY 50" {
+		t.Errorf("index: got %q, want %q", resp.Data.Index, "NIFTY 50")
+	}
+
+	want := map[string]struct{ change, weight float64 }{
+		"RELIANCE": {1.5, 50},
+		"TCS":      {-0.8, 37.5},
+		"INFY":     {0.25, 12.5},
+	}
+	if len(resp.Data.Constituents) != len(want) {
+		t.Fatalf("constituents: got %d, want %d", len(resp.Data.Constituents), len(want))
+	}
+	for _, c := range resp.Data.Constituents {
+		w, ok := want[c.Symbol]
+		if !ok {
+			t.Errorf("unexpected constituent %q", c.Symbol)
+			continue
+		}
+		if c.ChangePct != w.change {
+			t.Errorf("%s change_pct: got %v, want %v", c.Symbol, c.ChangePct, w.change)
+		}
+		if c.Weight != w.weight {
+			t.Errorf("%s weight: got %v, want %v", c.Symbol, c.Weight, w.weight)
+		}
+	}
+	if resp.Data.Constituents[0].Symbol != "RELIANCE" {
+		t.Errorf("expected heaviest constituent first, got %q", resp.Data.Constituents[0].Symbol)
+	}
+}
+
+func TestSetTLS_RedirectHandler(t *testing.T) {
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "cert.pem")
+	key := filepath.Join(dir, "key.pem")
+	for _, f := range []string{cert, key} {
+		if err := os.WriteFile(f, []byte("test"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := testServer(t)
+	if err := srv.SetTLS(TLSConfig{CertFile: cert, KeyFile: filepath.Join(dir, "missing.pem")}); err == nil {
+		t.Error("expected error for missing key file")
+	}
+	if err := srv.SetTLS(TLSConfig{CertFile: cert, KeyFile: key, RedirectAddr: ":8080"}); err != nil {
+		t.Fatalf("SetTLS: %v", err)
+	}
+	if srv.tls == nil || srv.tls.RedirectAddr != ":8080" {
+		t.Fatalf("expected TLS config to be stored, got %+v", srv.tls)
+	}
+
+	req := httptest.NewRequest("GET", "http://example.com:8080/api/v1/quote/TCS?x=1", nil)
+	rec := httptest.NewRecorder()
+	RedirectHandler(":8443").ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMovedPermanently {
+		t.Fatalf("expected 301, got %d", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "https://example.com:8443/api/v1/quote/TCS?x=1" {
+		t.Errorf("unexpected Location: %s", loc)
+	}
+
+	rec = httptest.NewRecorder()
+	RedirectHandler("0.0.0.0:443").ServeHTTP(rec, httptest.NewRequest("GET", "http://example.com/", nil))
+	if loc := rec.Header().Get("Location"); loc != "https://example.com/" {
+		t.Errorf("expected default port to be omitted, got %s", loc)
+	}
+}
+
+func TestHandleGetOrders_Pagination(t *testing.T) {
+	srv := testServer(t)
+	mb := newTestBroker()
+	mb.orders = []models.Order{{OrderID: "o1"}, {OrderID: "o2"}, {OrderID: "o3"}}
+	srv.broker = mb
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/orders?limit=1&offset=1", nil)
+	srv.handleGetOrders(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Success bool               `json:"success"`
+		Data    Page[models.Order] `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data.Items) != 1 || resp.Data.Items[0].OrderID != "o2" {
+		t.Errorf("expected only order o2, got %+v", resp.Data.Items)
+	}
+	if resp.Data.Total != 3 || resp.Data.Limit != 1 || resp.Data.Offset != 1 {
+		t.Errorf("unexpected page metadata: total=%d limit=%d offset=%d", resp.Data.Total, resp.Data.Limit, resp.Data.Offset)
+	}
+}
+
+func TestHandleGetOrders_UnpaginatedReturnsArray(t *testing.T) {
+	srv := testServer(t)
+	mb := newTestBroker()
+	for i := 0; i < defaultPageLimit+5; i++ {
+		mb.orders = append(mb.orders, models.Order{OrderID: fmt.Sprintf("o%d", i)})
+	}
+	srv.broker = mb
+
+	rec := httptest.NewRecorder()
+	srv.handleGetOrders(rec, httptest.NewRequest("GET", "/api/v1/orders", nil))
+
+	var resp struct {
+		Success bool           `json:"success"`
+		Data    []models.Order `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("expected a plain array: %v", err)
+	}
+	if len(resp.Data) != len(mb.orders) {
+		t.Errorf("expected all %d orders, got %d", len(mb.orders), len(resp.Data))
+	}
+}
+
+func TestHandleGetOrders_InvalidPagination(t *testing.T) {
+	srv := testServer(t)
+	srv.broker = newTestBroker()
+
+	for _, q := range []string{"limit=0", "limit=abc", "offset=-1"} {
+		rec := httptest.NewRecorder()
+		srv.handleGetOrders(rec, httptest.NewRequest("GET", "/api/v1/orders?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status got %d, want %d", q, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+// approvalServer returns a server whose risk manager parks every order
+// for approval, backed by a mock broker.
+func approvalServer(t *testing.T) (*Server, *mockBroker) {
+	t.Helper()
+	srv := testServer(t)
+	mb := newTestBroker()
+	cfg := broker.DefaultRiskConfig()
+	cfg.RequireApproval = true
+	cfg.ParkApprovals = true
+	srv.broker = mb
+	srv.riskMgr = broker.NewRiskManager(mb, cfg)
+	return srv, mb
+}
+
+// placeParkedOrder places an order through the router and returns its
+// approval ID.
+func placeParkedOrder(t *testing.T, router http.Handler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	body := `{"ticker":"TCS","exchange":"NSE","side":"BUY","order_type":"LIMIT","product":"CNC","quantity":5,"price":3500}`
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/orders", strings.NewReader(body)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("place order: got %d, want %d\nbody: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+	var resp struct {
+		Data models.OrderResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.Status != broker.StatusPendingApproval || resp.Data.ApprovalID == "" {
+		t.Fatalf("expected pending status with approval id, got %+v", resp.Data)
+	}
+	return resp.Data.ApprovalID
+}
+
+func TestHandleApprovals_List(t *testing.T) {
+	srv, mb := approvalServer(t)
+	router := srv.buildRouter()
+	id := placeParkedOrder(t, router)
+
+	if len(mb.placed) != 0 {
+		t.Fatal("parked order must not reach the broker")
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/approvals", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp struct {
+		Data []broker.PendingApproval `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != id || resp.Data[0].Order.Ticker != "TCS" {
+		t.Errorf("unexpected pending approvals: %+v", resp.Data)
+	}
+}
+
+func TestHandleApprovals_Approve(t *testing.T) {
+	srv, mb := approvalServer(t)
+	router := srv.buildRouter()
+	id := placeParkedOrder(t, router)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/approvals/"+id, strings.NewReader(`{"action":"approve"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d\nbody: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp struct {
+		Data models.OrderResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.OrderID != "test-001" {
+		t.Errorf("expected broker order id, got %+v", resp.Data)
+	}
+	if len(mb.placed) != 1 || mb.placed[0].Ticker != "TCS" {
+		t.Errorf("approved order should reach the broker, got %+v", mb.placed)
+	}
+	if n := len(srv.riskMgr.PendingApprovals()); n != 0 {
+		t.Errorf("expected no pending approvals, got %d", n)
+	}
+
+	// Resolving the same approval again is a 404.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/approvals/"+id, strings.NewReader(`{"action":"approve"}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("second approve: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleApprovals_Deny(t *testing.T) {
+	srv, mb := approvalServer(t)
+	router := srv.buildRouter()
+	id := placeParkedOrder(t, router)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/approvals/"+id,
+		strings.NewReader(`{"action":"deny","reason":"too large"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d\nbody: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp struct {
+		Data models.OrderResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.Status != "REJECTED" || !strings.Contains(resp.Data.Message, "too large") {
+		t.Errorf("expected rejected response with reason, got %+v", resp.Data)
+	}
+	if len(mb.placed) != 0 {
+		t.Error("denied order must not reach the broker")
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/approvals/"+id, strings.NewReader(`{"action":"maybe"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid action: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+// fakeAggregator is an AggregatorLike serving fixed quotes.
+type fakeAggregator struct {
+	fakeHeatmapSource
+}
+
+func (f *fakeAggregator) FetchHistoricalData(_ context.Context, ticker string, _, _ time.Time, _ models.Timeframe) ([]models.OHLCV, error) {
+	return nil, fmt.Errorf("no history for %s", ticker)
+}
+
+func (f *fakeAggregator) FetchMarketOverview(_ context.Context) (*datasource.MarketOverview, error) {
+	return &datasource.MarketOverview{}, nil
+}
+
+func (f *fakeAggregator) FetchFIIDIIActivity(_ context.Context) (*models.FIIDIIData, error) {
+	return &models.FIIDIIData{}, nil
+}
+
+func TestSetAggregator_QueryUnavailable(t *testing.T) {
+	srv := testServer(t)
+	srv.SetAggregator(&fakeAggregator{fakeHeatmapSource{}})
+	router := srv.buildRouter()
+
+	for _, path := range []string{"/api/v1/query", "/api/v1/query/batch"} {
+		body := `{"expression":"price(\"TCS\")","expressions":["price(\"TCS\")"]}`
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: status got %d, want %d\nbody: %s", path, rec.Code, http.StatusServiceUnavailable, rec.Body.String())
+		}
+	}
+}
+
+func TestSetAggregator_Quote(t *testing.T) {
+	srv := testServer(t)
+	srv.SetAggregator(&fakeAggregator{fakeHeatmapSource{
+		quotes: map[string]*models.Quote{
+			"RELIANCE": {Ticker: "RELIANCE", LastPrice: 2912.5, ChangePct: 1.25},
+		},
+	}})
+	router := srv.buildRouter()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/quote/RELIANCE", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d\nbody: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var resp struct {
+		Success bool         `json:"success"`
+		Data    models.Quote `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Success || resp.Data.Ticker != "RELIANCE" || resp.Data.LastPrice != 2912.5 {
+		t.Errorf("quote: got %+v, want the fake RELIANCE quote", resp.Data)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/quote/TCS", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("unknown ticker status: got %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	data := `{"quotes": {"INFY": {"ticker": "INFY", "last_price": 1500}}, "indices": {"NIFTY IT": ["INFY"]}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fx, err := LoadFixtures(path)
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+
+	q, err := fx.GetQuote(context.Background(), "infy")
+	if err != nil || q.LastPrice != 1500 {
+		t.Errorf("GetQuote: got %+v, %v", q, err)
+	}
+	if _, err := fx.IndexConstituents(context.Background(), "NIFTY BANK"); !errors.Is(err, datasource.ErrNotSupported) {
+		t.Errorf("IndexConstituents: got %v, want ErrNotSupported", err)
+	}
+	if _, err := LoadFixtures(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for a missing fixtures file")
+	}
+}
+
+// ════════════════════════════════════════════════════════════════════
+// TradingView webhook tests
+// ════════════════════════════════════════════════════════════════════
+
+const testWebhookSecret = "tv-secret"
+
+// webhookServer returns a server with a webhook secret and a risk manager
+// that places orders straight through to a mock broker.
+func webhookServer(t *testing.T) (*Server, *mockBroker) {
+	t.Helper()
+	srv := testServer(t)
+	srv.cfg.API.WebhookSecret = testWebhookSecret
+	srv.SetAggregator(&fakeAggregator{fakeHeatmapSource{quotes: map[string]*models.Quote{
+		"RELIANCE": {Ticker: "RELIANCE", LastPrice: 2900},
+		"TCS":      {Ticker: "TCS", LastPrice: 3800},
+	}}})
+	mb := newTestBroker()
+	srv.broker = mb
+	srv.riskMgr = broker.NewRiskManager(mb, broker.DefaultRiskConfig())
+	return srv, mb
+}
+
+func signPayload(secret, ts, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "." + body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signedWebhook returns an alert request for body signed now.
+func signedWebhook(body string) *http.Request {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req := httptest.NewRequest("POST", "/api/v1/webhook/tradingview", strings.NewReader(body))
+	req.Header.Set(SignatureHeader, signPayload(testWebhookSecret, ts, body))
+	req.Header.Set(TimestampHeader, ts)
+	return req
+}
+
+func TestHandleTradingViewWebhook_Valid(t *testing.T) {
+	srv, mb := webhookServer(t)
+	srv.cfg.API.TradingView.QuantityField = "strategy.order.contracts"
+	router := srv.buildRouter()
+
+	body := `{"ticker":"NSE:RELIANCE","action":"BUY","strategy":{"order":{"contracts":"10"}}}`
+	req := signedWebhook(body)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d\nbody: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if len(mb.placed) != 1 {
+		t.Fatalf("expected 1 order placed, got %d", len(mb.placed))
+	}
+	got := mb.placed[0]
+	if got.Ticker != "RELIANCE" || got.Side != models.Buy || got.Quantity != 10 ||
+		got.OrderType != models.Market || got.Exchange != "NSE" || got.Product != models.CNC || got.TriggerPrice != 2900 {
+		t.Errorf("order: got %+v", got)
+	}
+}
+
+func TestHandleTradingViewWebhook_FillsAtQuote(t *testing.T) {
+	srv, _ := webhookServer(t)
+	pb := broker.NewPaperBroker(nil)
+	srv.broker = pb
+	srv.riskMgr = broker.NewRiskManager(pb, broker.DefaultRiskConfig())
+	router := srv.buildRouter()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, signedWebhook(`{"ticker":"TCS","action":"buy","qty":5}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status: got %d\nbody: %s", rec.Code, rec.Body.String())
+	}
+	orders, _ := pb.GetOrders(context.Background())
+	if len(orders) != 1 || math.Abs(orders[0].AvgPrice-3800) > 3800*0.01 {
+		t.Errorf("expected a fill near the ₹3800 quote, got %+v", orders)
+	}
+
+	// Without a quote the alert is rejected rather than priced blindly.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, signedWebhook(`{"ticker":"INFY","action":"buy","qty":5}`))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("unquoted ticker: got %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestHandleTradingViewWebhook_Replay(t *testing.T) {
+	srv, mb := webhookServer(t)
+	router := srv.buildRouter()
+	body := `{"ticker":"TCS","action":"sell","qty":5}`
+
+	first := signedWebhook(body)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, first)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("first alert: got %d\nbody: %s", rec.Code, rec.Body.String())
+	}
+
+	// The same signed alert again is a replay.
+	again := httptest.NewRequest("POST", "/api/v1/webhook/tradingview", strings.NewReader(body))
+	again.Header = first.Header.Clone()
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, again)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("replayed alert: got %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	// So is one correctly signed long ago, and one with no timestamp.
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	stale := httptest.NewRequest("POST", "/api/v1/webhook/tradingview?timestamp="+old, strings.NewReader(body))
+	stale.Header.Set(SignatureHeader, signPayload(testWebhookSecret, old, body))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, stale)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("stale alert: got %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	bare := httptest.NewRequest("POST", "/api/v1/webhook/tradingview", strings.NewReader(body))
+	bare.Header.Set(SignatureHeader, signPayload(testWebhookSecret, "", body))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, bare)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("unstamped alert: got %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	if len(mb.placed) != 1 {
+		t.Errorf("expected only the first alert placed, got %d", len(mb.placed))
+	}
+}
+
+func TestHandleTradingViewWebhook_InvalidSignature(t *testing.T) {
+	srv, mb := webhookServer(t)
+	router := srv.buildRouter()
+
+	body := `{"ticker":"TCS","action":"sell","qty":5}`
+	for name, sig := range map[string]string{
+		"wrong secret": signPayload("other-secret", "", body),
+		"missing":      "",
+		"not hex":      "sha256=zzzz",
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/webhook/tradingview", strings.NewReader(body))
+			if sig != "" {
+				req.Header.Set(SignatureHeader, sig)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status: got %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+	if len(mb.placed) != 0 {
+		t.Errorf("unsigned alerts must not place orders, got %d", len(mb.placed))
+	}
+}
+
+func TestHandleTradingViewWebhook_BadPayload(t *testing.T) {
+	srv, _ := webhookServer(t)
+	router := srv.buildRouter()
+
+	for _, body := range []string{
+		`{"ticker":"TCS","action":"hold","qty":5}`,
+		`{"ticker":"TCS","action":"buy","qty":2.5}`,
+		`{"action":"buy","qty":5}`,
+	} {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req := httptest.NewRequest("POST", "/api/v1/webhook/tradingview?timestamp="+ts+"&signature="+
+			strings.TrimPrefix(signPayload(testWebhookSecret, ts, body), "sha256="), strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status got %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	// Without a secret the webhook is disabled.
+	srv.cfg.API.WebhookSecret = ""
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/webhook/tradingview", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("disabled webhook: got %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestStartQuoteRefresher(t *testing.T) {
+	srv := testServer(t)
+	srv.SetAggregator(&fakeAggregator{fakeHeatmapSource{
+		quotes: map[string]*models.Quote{
+			"TCS": {Ticker: "TCS", LastPrice: 3800},
+		},
+	}})
+
+	client := &WSClient{hub: srv.wsHub, send: make(chan WSMessage, 16)}
+	srv.wsHub.Register(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv.StartQuoteRefresher(ctx, []string{"tcs"}, 20*time.Millisecond)
+
+	select {
+	case msg := <-client.send:
+		q, ok := msg.Data.(*models.Quote)
+		if msg.Type != "quote" || !ok || q.Ticker != "TCS" {
+			t.Fatalf("got %s message %+v, want TCS quote", msg.Type, msg.Data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no quote broadcast from the refresher")
+	}
+
+	// The quote endpoint serves the refreshed quote from the cache.
+	if q, ok := srv.quotes.get("TCS"); !ok || q.LastPrice != 3800 {
+		t.Errorf("cached quote: got %+v, %v", q, ok)
+	}
+}
+
+// ════════════════════════════════════════════════════════════════════
+// WebSocket origin and token tests
+// ════════════════════════════════════════════════════════════════════
+
+// dialWS opens /api/v1/ws on ts with the given Origin header, returning the
+// handshake response status.
+func dialWS(t *testing.T, ts *httptest.Server, query, origin string) int {
+	t.Helper()
+	header := http.Header{}
+	if origin != "" {
+		header.Set("Origin", origin)
+	}
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/ws" + query
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if err == nil {
+		conn.Close()
+	}
+	if resp == nil {
+		t.Fatalf("dial %s: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+func TestWebSocketOriginCheck(t *testing.T) {
+	srv := testServer(t)
+	srv.cfg.API.AllowedOrigins = []string{"https://app.example.com"}
+	ts := httptest.NewServer(srv.buildRouter())
+	defer ts.Close()
+
+	if code := dialWS(t, ts, "", "https://evil.example.com"); code != http.StatusForbidden {
+		t.Errorf("disallowed origin: status %d, want 403", code)
+	}
+	if code := dialWS(t, ts, "", "https://app.example.com"); code != http.StatusSwitchingProtocols {
+		t.Errorf("allowed origin: status %d, want 101", code)
+	}
+	if code := dialWS(t, ts, "", ts.URL); code != http.StatusSwitchingProtocols {
+		t.Errorf("same origin: status %d, want 101", code)
+	}
+}
+
+func TestWebSocketToken(t *testing.T) {
+	srv := testServer(t)
+	srv.cfg.API.WSToken = "s3cret"
+	ts := httptest.NewServer(srv.buildRouter())
+	defer ts.Close()
+
+	if code := dialWS(t, ts, "", ""); code != http.StatusUnauthorized {
+		t.Errorf("missing token: status %d, want 401", code)
+	}
+	if code := dialWS(t, ts, "?token=wrong", ""); code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status %d, want 401", code)
+	}
+	if code := dialWS(t, ts, "?token=s3cret", ""); code != http.StatusSwitchingProtocols {
+		t.Errorf("query token: status %d, want 101", code)
+	}
+
+	dialer := websocket.Dialer{Subprotocols: []string{"token.s3cret"}}
+	conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/api/v1/ws", nil)
+	if err != nil {
+		t.Fatalf("subprotocol token: %v", err)
+	}
+	defer conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols || conn.Subprotocol() != "token.s3cret" {
+		t.Errorf("subprotocol token: status %d, protocol %q", resp.StatusCode, conn.Subprotocol())
+	}
+}
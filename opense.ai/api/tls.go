@@ -0,0 +1,50 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+)
+
+// TLSConfig enables HTTPS for the API server.
+type TLSConfig struct {
+	CertFile     string // PEM certificate path
+	KeyFile      string // PEM private key path
+	RedirectAddr string // optional plain-HTTP listen address that redirects to HTTPS, e.g. ":80"
+}
+
+// SetTLS makes ListenAndServe serve HTTPS with the given certificate and key.
+// Both files must exist. Must be called before ListenAndServe.
+func (s *Server) SetTLS(tc TLSConfig) error {
+	if tc.CertFile == "" || tc.KeyFile == "" {
+		return fmt.Errorf("TLS requires both a certificate and a key file")
+	}
+	for _, f := range []string{tc.CertFile, tc.KeyFile} {
+		info, err := os.Stat(f)
+		if err != nil {
+			return fmt.Errorf("TLS file: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("TLS file %s is a directory", f)
+		}
+	}
+	s.tls = &tc
+	return nil
+}
+
+// RedirectHandler returns a handler that permanently redirects every request
+// to the same host and path over HTTPS on the port of httpsAddr.
+func RedirectHandler(httpsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,257 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seenimoa/openseai/internal/broker"
+	"github.com/seenimoa/openseai/internal/config"
+	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
+)
+
+// maxWebhookBody bounds the size of an alert payload.
+const maxWebhookBody = 64 << 10
+
+// SignatureHeader carries the hex HMAC-SHA256 of the timestamp, a ".",
+// and the request body, keyed by cfg.API.WebhookSecret, optionally
+// prefixed with "sha256=". Senders that cannot set headers may pass it as
+// the "signature" query parameter.
+const SignatureHeader = "X-Signature"
+
+// TimestampHeader carries the Unix time in seconds at which the alert was
+// signed, or the "timestamp" query parameter does. Alerts signed more than
+// webhookMaxSkew away from now are rejected, as are repeats of one seen
+// within that window, so a captured alert cannot be replayed.
+const TimestampHeader = "X-Signature-Timestamp"
+
+// webhookMaxSkew bounds how old (or how far ahead) an alert's signed
+// timestamp may be.
+const webhookMaxSkew = 5 * time.Minute
+
+// handleTradingViewWebhook turns a signed TradingView alert into a market
+// order priced off the current quote and routed through the risk manager.
+func (s *Server) handleTradingViewWebhook(w http.ResponseWriter, r *http.Request) {
+	secret := s.cfg.API.WebhookSecret
+	if secret == "" {
+		writeError(w, http.StatusServiceUnavailable, "webhook is disabled: api.webhook_secret is not set")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sig := r.Header.Get(SignatureHeader)
+	if sig == "" {
+		sig = r.URL.Query().Get("signature")
+	}
+	ts := r.Header.Get(TimestampHeader)
+	if ts == "" {
+		ts = r.URL.Query().Get("timestamp")
+	}
+	if !validSignature(secret, ts, body, sig) {
+		writeError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+	signedAt, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || absDuration(time.Since(time.Unix(signedAt, 0))) > webhookMaxSkew {
+		writeError(w, http.StatusUnauthorized, "signature timestamp is missing or outside the allowed window")
+		return
+	}
+	if !s.webhookSeen.first(sig, time.Now()) {
+		writeError(w, http.StatusUnauthorized, "alert already received")
+		return
+	}
+
+	req, err := tradingViewOrder(s.cfg.API.TradingView, body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if s.riskMgr == nil {
+		writeError(w, http.StatusServiceUnavailable, "risk manager not configured")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	// Market orders carry the last price as their reference, so the
+	// risk checks and a paper fill use the real price.
+	quote, err := s.agg.GetQuote(ctx, req.Ticker)
+	if err != nil || quote == nil || quote.LastPrice <= 0 {
+		writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("no quote for %s to price the order", req.Ticker))
+		return
+	}
+	req.TriggerPrice = quote.LastPrice
+
+	resp, err := s.riskMgr.PlaceOrder(ctx, req)
+	if err != nil {
+		if errors.Is(err, broker.ErrTradeBlocked) {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondOrder(w, req, resp)
+}
+
+// validSignature reports whether sig is the hex HMAC-SHA256 of ts, ".",
+// and body.
+func validSignature(secret, ts string, body []byte, sig string) bool {
+	got, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(sig), "sha256="))
+	if err != nil || len(got) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// seenSignatures remembers the signatures of accepted alerts for
+// webhookMaxSkew, past which their timestamps are rejected anyway.
+type seenSignatures struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// first records sig and reports whether it was not seen in the window.
+func (s *seenSignatures) first(sig string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen == nil {
+		s.seen = make(map[string]time.Time)
+	}
+	for k, at := range s.seen {
+		if now.Sub(at) > 2*webhookMaxSkew {
+			delete(s.seen, k)
+		}
+	}
+	key := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(sig)), "sha256=")
+	if _, ok := s.seen[key]; ok {
+		return false
+	}
+	s.seen[key] = now
+	return true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// tradingViewOrder maps an alert payload to a market order using the
+// configured field names, falling back to the defaults for empty ones.
+func tradingViewOrder(tv config.TradingViewConfig, body []byte) (models.OrderRequest, error) {
+	var payload map[string]any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err != nil {
+		return models.OrderRequest{}, fmt.Errorf("invalid JSON payload: %v", err)
+	}
+
+	tickerField := orDefault(tv.TickerField, "ticker")
+	actionField := orDefault(tv.ActionField, "action")
+	qtyField := orDefault(tv.QuantityField, "qty")
+
+	ticker, _ := lookupField(payload, tickerField).(string)
+	if strings.TrimSpace(ticker) == "" {
+		return models.OrderRequest{}, fmt.Errorf("payload field %q (ticker) is required", tickerField)
+	}
+	// TradingView tickers may carry an exchange prefix, e.g. "NSE:TCS".
+	if i := strings.LastIndex(ticker, ":"); i >= 0 {
+		ticker = ticker[i+1:]
+	}
+
+	action, _ := lookupField(payload, actionField).(string)
+	var side models.OrderSide
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case "buy":
+		side = models.Buy
+	case "sell":
+		side = models.Sell
+	default:
+		return models.OrderRequest{}, fmt.Errorf("payload field %q (action) must be \"buy\" or \"sell\", got %q", actionField, action)
+	}
+
+	qty, err := quantityValue(lookupField(payload, qtyField))
+	if err != nil {
+		return models.OrderRequest{}, fmt.Errorf("payload field %q (quantity): %v", qtyField, err)
+	}
+
+	return models.OrderRequest{
+		Ticker:    utils.NormalizeTicker(ticker),
+		Exchange:  strings.ToUpper(orDefault(tv.Exchange, "NSE")),
+		Side:      side,
+		OrderType: models.Market,
+		Product:   models.OrderProduct(strings.ToUpper(orDefault(tv.Product, string(models.CNC)))),
+		Quantity:  qty,
+		Tag:       "tradingview",
+	}, nil
+}
+
+// lookupField resolves a dotted path such as "strategy.order.action" in a
+// decoded JSON object. It returns nil when any segment is missing.
+func lookupField(payload map[string]any, path string) any {
+	var cur any = payload
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[key]
+	}
+	return cur
+}
+
+// quantityValue parses a positive whole quantity from a JSON number or a
+// numeric string (TradingView placeholders are substituted as text).
+func quantityValue(v any) (int, error) {
+	var s string
+	switch q := v.(type) {
+	case json.Number:
+		s = q.String()
+	case string:
+		s = strings.TrimSpace(q)
+	case nil:
+		return 0, fmt.Errorf("missing")
+	default:
+		return 0, fmt.Errorf("expected a number, got %T", v)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	if f <= 0 || f != math.Trunc(f) || f > math.MaxInt32 {
+		return 0, fmt.Errorf("must be a positive whole number, got %s", s)
+	}
+	return int(f), nil
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -13,10 +16,14 @@ var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
 	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins; restrict in production
+		return true // checked by wsOriginAllowed before upgrading
 	},
 }
 
+// wsTokenProtocol prefixes the Sec-WebSocket-Protocol value that carries
+// the /ws token for clients that cannot add a query parameter.
+const wsTokenProtocol = "token."
+
 const (
 	// Time allowed to write a message to the peer.
 	writeWait = 10 * time.Second
@@ -33,8 +40,28 @@ const (
 
 // handleWebSocket upgrades HTTP connections to WebSocket and manages
 // bidirectional communication for streaming analysis updates.
+// Browser origins must be the server's own or listed in
+// cfg.API.AllowedOrigins, and when cfg.API.WSToken is set the client must
+// present it as the "token" query parameter or a "token.<value>"
+// subprotocol.
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	if !wsOriginAllowed(r, s.cfg.API.AllowedOrigins) {
+		writeError(w, http.StatusForbidden, "origin not allowed")
+		return
+	}
+	var header http.Header
+	if token := s.cfg.API.WSToken; token != "" {
+		protocol, ok := wsAuthorized(r, token)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "invalid or missing websocket token")
+			return
+		}
+		if protocol != "" {
+			header = http.Header{"Sec-Websocket-Protocol": {protocol}}
+		}
+	}
+
+	conn, err := upgrader.Upgrade(w, r, header)
 	if err != nil {
 		log.Printf("WebSocket upgrade error: %v", err)
 		return
@@ -52,6 +79,44 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	go wsReadPump(conn, client, s)
 }
 
+// wsOriginAllowed reports whether r may open a websocket. Requests without
+// an Origin header come from non-browser clients and are allowed; browser
+// requests must be same-origin or match an entry of allowed.
+func wsOriginAllowed(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, a := range allowed {
+		if a == "*" || strings.EqualFold(strings.TrimSuffix(a, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// wsAuthorized reports whether r carries token, and returns the
+// subprotocol to echo when it was sent as one.
+func wsAuthorized(r *http.Request, token string) (string, bool) {
+	if q := r.URL.Query().Get("token"); q != "" {
+		return "", subtle.ConstantTimeCompare([]byte(q), []byte(token)) == 1
+	}
+	for _, p := range websocket.Subprotocols(r) {
+		if v, ok := strings.CutPrefix(p, wsTokenProtocol); ok &&
+			subtle.ConstantTimeCompare([]byte(v), []byte(token)) == 1 {
+			return p, true
+		}
+	}
+	return "", false
+}
+
 // wsReadPump pumps messages from the WebSocket connection to the hub.
 func wsReadPump(conn *websocket.Conn, client *WSClient, s *Server) {
 	defer func() {
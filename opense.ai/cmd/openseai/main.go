@@ -5,25 +5,36 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"text/template"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/seenimoa/openseai/api"
 	"github.com/seenimoa/openseai/internal/agent"
+	"github.com/seenimoa/openseai/internal/agent/prompts"
 	"github.com/seenimoa/openseai/internal/backtest"
 	"github.com/seenimoa/openseai/internal/broker"
 	"github.com/seenimoa/openseai/internal/config"
 	"github.com/seenimoa/openseai/internal/datasource"
 	"github.com/seenimoa/openseai/internal/financeql"
 	"github.com/seenimoa/openseai/internal/llm"
+	"github.com/seenimoa/openseai/internal/mcp"
 	"github.com/seenimoa/openseai/internal/report"
 	"github.com/seenimoa/openseai/pkg/models"
 	"github.com/seenimoa/openseai/pkg/utils"
@@ -54,6 +65,19 @@ A Go-based multi-agent AI system for comprehensive NSE stock analysis,
 covering fundamental, technical, derivatives, sentiment analysis, and
 automated trading.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		switch {
+		case verbose && quiet:
+			return fmt.Errorf("--verbose and --quiet are mutually exclusive")
+		case verbose:
+			outputVerbosity = verbosityVerbose
+		case quiet:
+			outputVerbosity = verbosityQuiet
+		default:
+			outputVerbosity = verbosityNormal
+		}
+
 		var err error
 		configFile, _ := cmd.Flags().GetString("config")
 		if configFile != "" {
@@ -64,6 +88,7 @@ automated trading.`,
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
+		broker.SetLotSizes(cfg.LotSizes)
 		return nil
 	},
 }
@@ -71,6 +96,9 @@ automated trading.`,
 func init() {
 	rootCmd.PersistentFlags().String("config", "", "config file path (default: ./config/config.yaml)")
 	rootCmd.PersistentFlags().String("log-level", "", "log level override (debug, info, warn, error)")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "override the timeout of long-running commands (e.g. 10m; default: per-command)")
+	rootCmd.PersistentFlags().Bool("verbose", false, "show agent tool traces")
+	rootCmd.PersistentFlags().Bool("quiet", false, "show only the recommendation and confidence of agent results")
 
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(analyzeCmd)
@@ -80,18 +108,42 @@ func init() {
 	rootCmd.AddCommand(reportCmd)
 	rootCmd.AddCommand(backtestCmd)
 	rootCmd.AddCommand(tradeCmd)
+	rootCmd.AddCommand(tradesCmd)
+	rootCmd.AddCommand(downloadCmd)
 	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(heatmapCmd)
 	rootCmd.AddCommand(portfolioCmd)
+	rootCmd.AddCommand(riskCmd)
 	rootCmd.AddCommand(queryCmd)
 	rootCmd.AddCommand(chatCmd)
 	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(mcpCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(configCmd)
 }
 
 // --- Helper: create orchestrator ---
 
-func newOrchestrator() (*agent.Orchestrator, error) {
+// newAggregator returns a data aggregator routed to the sources chosen in
+// the datasource config section.
+func newAggregator() (*datasource.Aggregator, error) {
 	agg := datasource.NewAggregator()
+	err := agg.SelectSources(datasource.SourceSelection{
+		Quotes:       cfg.DataSource.Quotes,
+		Fundamentals: cfg.DataSource.Fundamentals,
+		Options:      cfg.DataSource.Options,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("datasource config: %w", err)
+	}
+	return agg, nil
+}
+
+func newOrchestrator() (*agent.Orchestrator, error) {
+	agg, err := newAggregator()
+	if err != nil {
+		return nil, err
+	}
 	router, err := llm.NewRouterFromConfig(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("LLM setup failed: %w", err)
@@ -102,15 +154,48 @@ func newOrchestrator() (*agent.Orchestrator, error) {
 		MaxTokens:   cfg.LLM.MaxTokens,
 	}
 	orch := agent.NewOrchestrator(agent.OrchestratorConfig{
-		Provider:    router,
-		Aggregator:  agg,
-		ChatOptions: opts,
-		DefaultMode: agent.ModeSingle,
-		Capital:     cfg.Trading.InitialCapital,
+		Provider:        router,
+		Aggregator:      agg,
+		ChatOptions:     opts,
+		DefaultMode:     agent.ModeSingle,
+		Capital:         cfg.Trading.InitialCapital,
+		AgentTimeout:    time.Duration(cfg.Analysis.AgentTimeoutSec) * time.Second,
+		MinConfidence:   cfg.Analysis.MinConfidence,
+		DebateThreshold: cfg.Analysis.DebateThreshold,
+		ExecutorLimits: &agent.ExecutorLimits{
+			MaxPositionPct: cfg.Trading.MaxPositionPct,
+			MaxLossPct:     cfg.Trading.DailyLossLimitPct,
+			MinRiskReward:  cfg.Trading.MinRiskReward,
+			MinConfidence:  cfg.Trading.MinTradeConfidence,
+		},
 	})
 	return orch, nil
 }
 
+// --- Helper: command timeouts ---
+
+// commandTimeout returns the --timeout override if one was given, otherwise def.
+func commandTimeout(cmd *cobra.Command, def time.Duration) time.Duration {
+	if d, err := cmd.Flags().GetDuration("timeout"); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
+
+// commandContext returns a background context bounded by commandTimeout.
+func commandContext(cmd *cobra.Command, def time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), commandTimeout(cmd, def))
+}
+
+// runAnalysis dispatches the analyze command to the orchestrator.
+// It is a variable so tests can observe the context it receives.
+var runAnalysis = func(ctx context.Context, orch *agent.Orchestrator, ticker string, deep bool) (*agent.AgentResult, error) {
+	if deep {
+		return orch.FullAnalysis(ctx, ticker)
+	}
+	return orch.QuickQuery(ctx, fmt.Sprintf("Analyze %s stock", ticker))
+}
+
 // --- Version Command ---
 
 var versionCmd = &cobra.Command{
@@ -128,19 +213,99 @@ var versionCmd = &cobra.Command{
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze [ticker]",
 	Short: "Run analysis on a stock",
-	Long:  "Run single-agent quick analysis or multi-agent deep analysis on a stock.",
-	Args:  cobra.ExactArgs(1),
+	Long: `Run single-agent quick analysis or multi-agent deep analysis on a stock.
+
+With --input-file, analyze every ticker listed in the file (one per line;
+blank lines and # comments are ignored), write an HTML report per ticker
+to --output-dir, and print a summary table. --universe does the same for a
+universe named in the config, or a ticker file.
+
+With --focus, weight the analysis toward one dimension: valuation,
+momentum, sentiment, or risk.
+
+With --output-template, render the result with a Go text/template file
+instead of the default layout. The template sees the result's fields
+({{.AgentName}}, {{.Content}}, {{.Analysis.Recommendation}},
+{{.AgentResults}}) plus {{.Ticker}} and {{.Metadata}}, and the helpers
+pct, inr, upper, lower, join, and round.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ticker := utils.NormalizeTicker(args[0])
 		deep, _ := cmd.Flags().GetBool("deep")
 		outputJSON, _ := cmd.Flags().GetBool("json")
+		yes, _ := cmd.Flags().GetBool("yes")
+		inputFile, _ := cmd.Flags().GetString("input-file")
+		universe, _ := cmd.Flags().GetString("universe")
+		bundlePath, _ := cmd.Flags().GetString("bundle")
+		focus, _ := cmd.Flags().GetString("focus")
+		templatePath, _ := cmd.Flags().GetString("output-template")
+
+		sources := len(args)
+		for _, s := range []string{inputFile, universe} {
+			if s != "" {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return fmt.Errorf("specify exactly one of a ticker, --input-file, or --universe")
+		}
+		batch := inputFile != "" || universe != ""
+		var focusPrompt string
+		if focus != "" {
+			var err error
+			if focusPrompt, err = prompts.Focus(focus); err != nil {
+				return err
+			}
+		}
+		if bundlePath != "" && batch {
+			return fmt.Errorf("--bundle exports a single ticker and cannot be used with --input-file or --universe")
+		}
+		var outputTemplate *template.Template
+		if templatePath != "" {
+			if batch || outputJSON {
+				return fmt.Errorf("--output-template renders a single ticker and cannot be used with --json, --input-file, or --universe")
+			}
+			var err error
+			if outputTemplate, err = loadOutputTemplate(templatePath); err != nil {
+				return err
+			}
+		}
+
+		var tickers []string
+		if universe != "" {
+			var err error
+			if tickers, err = loadUniverse(universe, configUniverses()); err != nil {
+				return err
+			}
+		} else if inputFile != "" {
+			f, err := os.Open(inputFile)
+			if err != nil {
+				return err
+			}
+			tickers, err = readTickerFile(f)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("read %s: %w", inputFile, err)
+			}
+			if len(tickers) == 0 {
+				return fmt.Errorf("no tickers in %s", inputFile)
+			}
+		} else {
+			tickers = []string{utils.NormalizeTicker(args[0])}
+		}
 
 		mode := "quick (single-agent)"
 		if deep {
 			mode = "deep (multi-agent)"
 		}
+		if focus != "" {
+			mode += ", " + strings.ToLower(focus) + " focus"
+		}
 
-		fmt.Printf("🔍 Analyzing %s — %s mode\n", ticker, mode)
+		if !batch {
+			fmt.Printf("🔍 Analyzing %s — %s mode\n", tickers[0], mode)
+		} else {
+			fmt.Printf("🔍 Analyzing %d stocks — %s mode\n", len(tickers), mode)
+		}
 		fmt.Printf("   Market Status: %s\n", utils.MarketStatus())
 		fmt.Println()
 
@@ -148,24 +313,82 @@ var analyzeCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		orch.SetInstructions(focusPrompt)
+
+		if deep && !yes {
+			est := orch.EstimateCost(agent.ModeMulti)
+			n := len(tickers)
+			if maxCost := est.MaxCostUSD * float64(n); maxCost > deepCostConfirmThreshold {
+				fmt.Printf("   Estimated usage: %d–%d tokens, $%.2f–$%.2f (%s)\n",
+					est.MinTokens*n, est.MaxTokens*n, est.MinCostUSD*float64(n), maxCost, est.Model)
+				if !confirm(fmt.Sprintf("This may cost ~$%.2f, continue? [y/N] ", maxCost)) {
+					fmt.Println("Aborted.")
+					return nil
+				}
+				fmt.Println()
+			}
+		}
+
+		if batch {
+			outputDir, _ := cmd.Flags().GetString("output-dir")
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+			ctx, cancel := commandContext(cmd, time.Duration(len(tickers))*5*time.Minute)
+			defer cancel()
+
+			results, err := runBatchAnalysis(ctx, orch, tickers, deep, outputDir, concurrency)
+			if err != nil {
+				return err
+			}
+			if outputJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(results)
+			}
+			printBatchSummary(os.Stdout, results)
+			return nil
+		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		ticker := tickers[0]
+		ctx, cancel := commandContext(cmd, 5*time.Minute)
 		defer cancel()
 
-		var result *agent.AgentResult
-		if deep {
-			result, err = orch.FullAnalysis(ctx, ticker)
-		} else {
-			result, err = orch.QuickQuery(ctx, fmt.Sprintf("Analyze %s stock", ticker))
+		// The bundle's data snapshot is taken through the agents' own
+		// aggregator before the run, so it matches what they analysed.
+		var snap *datasource.StockBundle
+		if bundlePath != "" {
+			if snap, err = orch.Aggregator().FetchBundle(ctx, ticker); err != nil {
+				return fmt.Errorf("bundle snapshot: %w", err)
+			}
 		}
+
+		result, err := runAnalysis(ctx, orch, ticker, deep)
 		if err != nil {
 			return fmt.Errorf("analysis failed: %w", err)
 		}
 
+		if bundlePath != "" {
+			if err := writeAnalysisBundle(bundlePath, newAnalysisBundle(ticker, result, orch.LastRunMetadata(), snap)); err != nil {
+				return fmt.Errorf("write bundle: %w", err)
+			}
+			fmt.Printf("📦 Analysis bundle saved: %s\n", bundlePath)
+		}
+
 		if outputJSON {
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
-			return enc.Encode(result)
+			return enc.Encode(struct {
+				*agent.AgentResult
+				Metadata agent.RunMetadata `json:"metadata"`
+			}{result, orch.LastRunMetadata()})
+		}
+
+		if outputTemplate != nil {
+			return renderAgentTemplate(os.Stdout, outputTemplate, agentTemplateData{
+				AgentResult: result,
+				Ticker:      ticker,
+				Metadata:    orch.LastRunMetadata(),
+			})
 		}
 
 		printAgentResult(result)
@@ -177,6 +400,179 @@ func init() {
 	analyzeCmd.Flags().Bool("deep", false, "run multi-agent deep analysis")
 	analyzeCmd.Flags().Bool("json", false, "output result as JSON")
 	analyzeCmd.Flags().Bool("pdf", false, "generate PDF report after analysis")
+	analyzeCmd.Flags().BoolP("yes", "y", false, "skip the cost confirmation prompt for --deep")
+	analyzeCmd.Flags().String("input-file", "", "file of tickers to analyze, one per line")
+	analyzeCmd.Flags().String("universe", "", "analyze every ticker of a universe: a name from the config, or a ticker file")
+	analyzeCmd.Flags().String("output-dir", "reports", "directory for per-ticker reports with --input-file or --universe")
+	analyzeCmd.Flags().Int("concurrency", 4, "number of tickers analyzed at once with --input-file or --universe")
+	analyzeCmd.Flags().String("bundle", "", "also write a self-contained JSON bundle of the analysis to this file")
+	analyzeCmd.Flags().String("focus", "", "dimension to emphasize: "+strings.Join(prompts.FocusNames(), ", "))
+	analyzeCmd.Flags().String("output-template", "", "render the result with this Go template file instead of the default layout")
+}
+
+// readTickerFile reads one ticker per line from r. Blank lines and
+// everything after a '#' are ignored; tickers are normalised and
+// duplicates dropped, preserving first-seen order.
+func readTickerFile(r io.Reader) ([]string, error) {
+	var tickers []string
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		t := utils.NormalizeTicker(line)
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		tickers = append(tickers, t)
+	}
+	return tickers, sc.Err()
+}
+
+// configUniverses returns the named universes from the loaded config.
+func configUniverses() map[string][]string {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.Universes
+}
+
+// loadUniverse resolves a --universe value to its tickers: the universe of
+// that name in named if there is one (names are case-insensitive), else
+// the ticker file at that path, read as by readTickerFile.
+func loadUniverse(spec string, named map[string][]string) ([]string, error) {
+	if list, ok := named[strings.ToLower(spec)]; ok {
+		tickers, err := readTickerFile(strings.NewReader(strings.Join(list, "\n")))
+		if err == nil && len(tickers) == 0 {
+			err = fmt.Errorf("universe %q is empty", spec)
+		}
+		return tickers, err
+	}
+	f, err := os.Open(spec)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("unknown universe %q: not a configured universe or a ticker file", spec)
+		}
+		return nil, err
+	}
+	defer f.Close()
+	tickers, err := readTickerFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", spec, err)
+	}
+	if len(tickers) == 0 {
+		return nil, fmt.Errorf("no tickers in %s", spec)
+	}
+	return tickers, nil
+}
+
+// batchResult is the outcome of analyzing one ticker in batch mode.
+type batchResult struct {
+	Ticker         string        `json:"ticker"`
+	Recommendation string        `json:"recommendation,omitempty"`
+	Confidence     float64       `json:"confidence,omitempty"`
+	Report         string        `json:"report,omitempty"`
+	Duration       time.Duration `json:"duration"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// runBatchAnalysis analyzes tickers with at most concurrency in flight and
+// writes an HTML report for each to outDir. A failure for one ticker is
+// recorded in its result and does not stop the others. Results are in
+// input order.
+func runBatchAnalysis(ctx context.Context, orch *agent.Orchestrator, tickers []string, deep bool, outDir string, concurrency int) ([]batchResult, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create output dir: %w", err)
+	}
+
+	results := make([]batchResult, len(tickers))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, ticker := range tickers {
+		wg.Add(1)
+		go func(i int, ticker string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			start := time.Now()
+			results[i] = analyzeAndReport(ctx, orch, ticker, deep, outDir)
+			results[i].Duration = time.Since(start)
+		}(i, ticker)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// analyzeAndReport runs one ticker's analysis and writes its report.
+func analyzeAndReport(ctx context.Context, orch *agent.Orchestrator, ticker string, deep bool, outDir string) batchResult {
+	res := batchResult{Ticker: ticker}
+	result, err := runAnalysis(ctx, orch, ticker, deep)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if result.Analysis != nil {
+		res.Recommendation = string(result.Analysis.Recommendation)
+		res.Confidence = float64(result.Analysis.Confidence)
+	}
+
+	reportCfg := report.DefaultReportConfig()
+	reportCfg.Title = fmt.Sprintf("OpeNSE.ai Research Report — %s", ticker)
+	reportCfg.Author = "OpeNSE.ai"
+	html, err := report.GenerateHTML(buildCompositeAnalysis(ticker, result), reportCfg)
+	if err != nil {
+		res.Error = fmt.Sprintf("report generation failed: %v", err)
+		return res
+	}
+	path := filepath.Join(outDir, ticker+"_report.html")
+	if err := os.WriteFile(path, []byte(html), 0o644); err != nil {
+		res.Error = fmt.Sprintf("write report: %v", err)
+		return res
+	}
+	res.Report = path
+	return res
+}
+
+// printBatchSummary writes one row per ticker and a final tally.
+func printBatchSummary(w io.Writer, results []batchResult) {
+	failed := 0
+	fmt.Fprintf(w, "  %-12s %-6s %-12s %-6s %s\n", "TICKER", "STATUS", "RECOMMEND", "CONF", "REPORT / ERROR")
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			fmt.Fprintf(w, "  %-12s %-6s %-12s %-6s %s\n", r.Ticker, "FAIL", "-", "-", r.Error)
+			continue
+		}
+		rec, conf := "-", "-"
+		if r.Recommendation != "" {
+			rec, conf = r.Recommendation, fmt.Sprintf("%.0f%%", r.Confidence*100)
+		}
+		fmt.Fprintf(w, "  %-12s %-6s %-12s %-6s %s\n", r.Ticker, "OK", rec, conf, r.Report)
+	}
+	fmt.Fprintf(w, "\n  %d analyzed, %d failed\n", len(results)-failed, failed)
+}
+
+// deepCostConfirmThreshold is the estimated USD cost above which
+// "analyze --deep" asks for confirmation before running.
+const deepCostConfirmThreshold = 0.25
+
+// confirm prints prompt and reports whether the user answered yes.
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
 
 // --- Technical Command ---
@@ -197,7 +593,7 @@ var technicalCmd = &cobra.Command{
 			return err
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		ctx, cancel := commandContext(cmd, 2*time.Minute)
 		defer cancel()
 
 		result, err := orch.QuickQuery(ctx, fmt.Sprintf("Run technical analysis on %s", ticker))
@@ -238,7 +634,7 @@ var fundamentalCmd = &cobra.Command{
 			return err
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		ctx, cancel := commandContext(cmd, 2*time.Minute)
 		defer cancel()
 
 		result, err := orch.QuickQuery(ctx, fmt.Sprintf("Run fundamental analysis on %s", ticker))
@@ -279,7 +675,7 @@ var fnoCmd = &cobra.Command{
 			return err
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		ctx, cancel := commandContext(cmd, 2*time.Minute)
 		defer cancel()
 
 		result, err := orch.QuickQuery(ctx, fmt.Sprintf("Run F&O derivatives analysis on %s", ticker))
@@ -307,42 +703,118 @@ func init() {
 var reportCmd = &cobra.Command{
 	Use:   "report [ticker]",
 	Short: "Generate a research report for a stock",
-	Long:  "Run multi-agent deep analysis and generate an HTML or PDF research report.",
-	Args:  cobra.ExactArgs(1),
+	Long: `Run multi-agent deep analysis and generate an HTML or PDF research report.
+
+With --from-bundle, render the report from a bundle written by
+"analyze --bundle" instead of running the analysis again.
+
+With --comparison, analyze every stock in --tickers and render them into a
+single report: a comparison matrix followed by a section per stock.
+
+Examples:
+  openseai report RELIANCE --pdf
+  openseai report --from-bundle tcs.json
+  openseai report --tickers TCS,INFY,WIPRO --comparison`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ticker := utils.NormalizeTicker(args[0])
 		pdfFlag, _ := cmd.Flags().GetBool("pdf")
 		output, _ := cmd.Flags().GetString("output")
-
-		fmt.Printf("📝 Generating report for %s\n", ticker)
-		fmt.Println()
-
-		orch, err := newOrchestrator()
+		sectionList, _ := cmd.Flags().GetString("sections")
+		bundlePath, _ := cmd.Flags().GetString("from-bundle")
+		tickers, _ := cmd.Flags().GetStringSlice("tickers")
+		comparison, _ := cmd.Flags().GetBool("comparison")
+
+		switch {
+		case comparison && (len(args) > 0 || bundlePath != ""):
+			return fmt.Errorf("--comparison takes its stocks from --tickers, not a ticker argument or --from-bundle")
+		case comparison && len(tickers) < 2:
+			return fmt.Errorf("--comparison needs at least two --tickers")
+		case !comparison && len(tickers) > 0:
+			return fmt.Errorf("--tickers requires --comparison")
+		case !comparison && (bundlePath == "") == (len(args) == 0):
+			return fmt.Errorf("specify either a ticker or --from-bundle")
+		}
+
+		sections, err := report.ParseSections(sectionList)
 		if err != nil {
 			return err
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-		defer cancel()
+		reportCfg := report.DefaultReportConfig()
+		reportCfg.Author = "OpeNSE.ai"
+		reportCfg.Sections = sections
 
-		// Run deep analysis
-		result, err := orch.FullAnalysis(ctx, ticker)
-		if err != nil {
-			return fmt.Errorf("analysis failed: %w", err)
-		}
+		var ticker, html string
+		var composite *models.CompositeAnalysis
+		if comparison {
+			for i, t := range tickers {
+				tickers[i] = utils.NormalizeTicker(t)
+			}
+			fmt.Printf("📝 Generating comparison report for %s\n", strings.Join(tickers, ", "))
+			fmt.Println()
 
-		// Build composite analysis from result
-		composite := buildCompositeAnalysis(ticker, result)
+			orch, err := newOrchestrator()
+			if err != nil {
+				return err
+			}
 
-		// Generate HTML report
-		reportCfg := report.DefaultReportConfig()
-		reportCfg.Title = fmt.Sprintf("OpeNSE.ai Research Report — %s", ticker)
-		reportCfg.Author = "OpeNSE.ai"
-		reportCfg.Sections = report.AllSections()
+			ctx, cancel := commandContext(cmd, time.Duration(len(tickers))*5*time.Minute)
+			defer cancel()
 
-		html, err := report.GenerateHTML(composite, reportCfg)
-		if err != nil {
-			return fmt.Errorf("report generation failed: %w", err)
+			analyses := make([]*models.CompositeAnalysis, 0, len(tickers))
+			for _, t := range tickers {
+				fmt.Printf("   Analyzing %s...\n", t)
+				result, err := orch.FullAnalysis(ctx, t)
+				if err != nil {
+					return fmt.Errorf("analysis of %s failed: %w", t, err)
+				}
+				analyses = append(analyses, buildCompositeAnalysis(t, result))
+			}
+
+			ticker = "comparison"
+			reportCfg.Title = fmt.Sprintf("OpeNSE.ai Comparison Report — %s", strings.Join(tickers, ", "))
+			html, err = report.GenerateComparisonHTML(analyses, reportCfg)
+			if err != nil {
+				return fmt.Errorf("report generation failed: %w", err)
+			}
+		} else if bundlePath != "" {
+			b, err := readAnalysisBundle(bundlePath)
+			if err != nil {
+				return err
+			}
+			ticker, composite = b.Ticker, b.Composite
+			fmt.Printf("📝 Generating report for %s from %s\n", ticker, bundlePath)
+			fmt.Println()
+		} else {
+			ticker = utils.NormalizeTicker(args[0])
+			fmt.Printf("📝 Generating report for %s\n", ticker)
+			fmt.Println()
+
+			orch, err := newOrchestrator()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := commandContext(cmd, 5*time.Minute)
+			defer cancel()
+
+			// Run deep analysis
+			result, err := orch.FullAnalysis(ctx, ticker)
+			if err != nil {
+				return fmt.Errorf("analysis failed: %w", err)
+			}
+
+			// Build composite analysis from result
+			composite = buildCompositeAnalysis(ticker, result)
+		}
+
+		// Generate HTML report
+		if composite != nil {
+			reportCfg.Title = fmt.Sprintf("OpeNSE.ai Research Report — %s", ticker)
+			html, err = report.GenerateHTML(composite, reportCfg)
+			if err != nil {
+				return fmt.Errorf("report generation failed: %w", err)
+			}
 		}
 
 		if pdfFlag {
@@ -380,6 +852,10 @@ var reportCmd = &cobra.Command{
 func init() {
 	reportCmd.Flags().Bool("pdf", false, "generate PDF report (requires wkhtmltopdf or chromium)")
 	reportCmd.Flags().StringP("output", "o", "", "output file path")
+	reportCmd.Flags().String("from-bundle", "", "render from an analysis bundle instead of running the analysis")
+	reportCmd.Flags().StringSlice("tickers", nil, "stocks to compare with --comparison (e.g. TCS,INFY,WIPRO)")
+	reportCmd.Flags().Bool("comparison", false, "generate one comparison report for every stock in --tickers")
+	reportCmd.Flags().String("sections", "", "comma-separated sections to include, in order (summary, recommendation, fundamental, technical, derivatives, sentiment, risk; default: all)")
 }
 
 // --- Backtest Command ---
@@ -391,20 +867,53 @@ var backtestCmd = &cobra.Command{
 
 Available strategies: sma_crossover, rsi_mean_reversion, supertrend, vwap_breakout, macd_crossover
 
+Historical bars are cached under ~/.openseai/cache/history, so repeated runs
+over the same range do not refetch them. Use --refresh to refetch and update
+the cache, or --no-cache to bypass it.
+
 Examples:
   openseai backtest --strategy sma_crossover --ticker RELIANCE --from 2023-01-01
-  openseai backtest --strategy rsi_mean_reversion --ticker TCS --from 2024-01-01 --capital 500000`,
+  openseai backtest --strategy rsi_mean_reversion --ticker TCS --from 2024-01-01 --capital 500000
+  openseai backtest --strategy sma_crossover --ticker INFY --param fast=10 --param slow=30
+  openseai backtest --strategy supertrend --ticker HDFCBANK --split 0.7
+  openseai backtest --list-strategies --json
+  openseai backtest --compare-runs before.json after.json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if list, _ := cmd.Flags().GetBool("list-strategies"); list {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return printStrategyList(asJSON)
+		}
+		if compare, _ := cmd.Flags().GetBool("compare-runs"); compare {
+			if len(args) != 2 {
+				return fmt.Errorf("--compare-runs takes two saved results: A.json B.json")
+			}
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return compareBacktestRuns(args[0], args[1], asJSON)
+		}
+
 		strategyName, _ := cmd.Flags().GetString("strategy")
 		ticker, _ := cmd.Flags().GetString("ticker")
 		fromStr, _ := cmd.Flags().GetString("from")
 		toStr, _ := cmd.Flags().GetString("to")
 		capital, _ := cmd.Flags().GetFloat64("capital")
 		outputJSON, _ := cmd.Flags().GetBool("json")
+		paramPairs, _ := cmd.Flags().GetStringArray("param")
+		splitRatio, _ := cmd.Flags().GetFloat64("split")
+		noCache, _ := cmd.Flags().GetBool("no-cache")
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		tfStr, _ := cmd.Flags().GetString("timeframe")
+		showChart, _ := cmd.Flags().GetBool("chart")
 
 		if strategyName == "" || ticker == "" {
 			return fmt.Errorf("--strategy and --ticker are required")
 		}
+		tf, err := models.ParseTimeframe(tfStr)
+		if err != nil {
+			return fmt.Errorf("invalid --timeframe: %w", err)
+		}
+		if splitRatio < 0 || splitRatio >= 1 {
+			return fmt.Errorf("--split must be between 0 and 1, got %g", splitRatio)
+		}
 
 		ticker = utils.NormalizeTicker(ticker)
 
@@ -420,9 +929,13 @@ Examples:
 				return fmt.Errorf("invalid --to date: %w", err)
 			}
 		}
+		from, to, err = backtest.TradingRange(from, to)
+		if err != nil {
+			return err
+		}
 
-		fmt.Printf("📉 Backtesting %s on %s (%s to %s)\n", strategyName, ticker,
-			from.Format("2006-01-02"), to.Format("2006-01-02"))
+		fmt.Printf("📉 Backtesting %s on %s (%s to %s, %s bars)\n", strategyName, ticker,
+			from.Format("2006-01-02"), to.Format("2006-01-02"), tf)
 		fmt.Println()
 
 		// Find strategy
@@ -431,33 +944,35 @@ Examples:
 			available := listStrategyNames()
 			return fmt.Errorf("unknown strategy %q; available: %s", strategyName, strings.Join(available, ", "))
 		}
-
-		// Fetch historical data
-		agg := datasource.NewAggregator()
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-		defer cancel()
-
-		bars, err := agg.FetchHistoricalData(ctx, ticker, from, to, models.Timeframe1Day)
+		overrides, err := backtest.ParseParams(paramPairs)
 		if err != nil {
-			return fmt.Errorf("failed to fetch data: %w", err)
+			return err
 		}
-
-		if len(bars) < 50 {
-			return fmt.Errorf("insufficient data: got %d bars, need at least 50", len(bars))
+		if err := backtest.ApplyParams(strategy, overrides); err != nil {
+			return err
 		}
 
-		// Configure and run
-		btCfg := backtest.DefaultConfig()
-		if capital > 0 {
-			btCfg.InitialCapital = capital
-		} else if cfg.Trading.InitialCapital > 0 {
-			btCfg.InitialCapital = cfg.Trading.InitialCapital
+		ctx, cancel := commandContext(cmd, 2*time.Minute)
+		defer cancel()
+		agg := newHistoryAggregator(noCache, refresh)
+
+		if splitRatio > 0 {
+			split, err := runBacktestSplit(ctx, agg, strategy, ticker, from, to, tf, capital, splitRatio)
+			if err != nil {
+				return err
+			}
+			if outputJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(split)
+			}
+			printBacktestSplit(split)
+			return nil
 		}
 
-		engine := backtest.NewEngine(btCfg)
-		result, err := engine.Run(strategy, ticker, bars)
+		result, err := runBacktest(ctx, agg, strategy, ticker, from, to, tf, capital)
 		if err != nil {
-			return fmt.Errorf("backtest failed: %w", err)
+			return err
 		}
 
 		if outputJSON {
@@ -466,18 +981,97 @@ Examples:
 			return enc.Encode(result)
 		}
 
-		printBacktestResult(result)
+		printBacktestResult(result, showChart)
 		return nil
 	},
 }
 
+// defaultBacktestFrom is the default backtest start date.
+const defaultBacktestFrom = "2023-01-01"
+
+// runBacktest fetches tf bars for ticker from agg and runs strategy over
+// them. A zero capital uses the configured initial capital.
+func runBacktest(ctx context.Context, agg *datasource.Aggregator, strategy backtest.Strategy, ticker string, from, to time.Time, tf models.Timeframe, capital float64) (*models.BacktestResult, error) {
+	bars, err := fetchBacktestBars(ctx, agg, ticker, from, to, tf)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := newBacktestEngine(capital).Run(strategy, ticker, bars)
+	if err != nil {
+		return nil, fmt.Errorf("backtest failed: %w", err)
+	}
+	return result, nil
+}
+
+// runBacktestSplit is like runBacktest but runs the strategy separately on
+// the first ratio of the bars and on the remainder.
+func runBacktestSplit(ctx context.Context, agg *datasource.Aggregator, strategy backtest.Strategy, ticker string, from, to time.Time, tf models.Timeframe, capital, ratio float64) (*backtest.SplitResult, error) {
+	bars, err := fetchBacktestBars(ctx, agg, ticker, from, to, tf)
+	if err != nil {
+		return nil, err
+	}
+
+	split, err := newBacktestEngine(capital).RunSplit(strategy, ticker, bars, ratio)
+	if err != nil {
+		return nil, fmt.Errorf("backtest failed: %w", err)
+	}
+	return split, nil
+}
+
+// fetchBacktestBars fetches tf bars for ticker, requiring at least 50.
+func fetchBacktestBars(ctx context.Context, agg *datasource.Aggregator, ticker string, from, to time.Time, tf models.Timeframe) ([]models.OHLCV, error) {
+	bars, err := agg.FetchHistoricalData(ctx, ticker, from, to, tf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch data: %w", err)
+	}
+
+	if len(bars) < 50 {
+		return nil, fmt.Errorf("insufficient data: got %d bars, need at least 50", len(bars))
+	}
+	return bars, nil
+}
+
+// newHistoryAggregator returns an aggregator whose historical bars are
+// cached on disk under ~/.openseai/cache/history, unless noCache is set.
+// With refresh, cached ranges are refetched and overwritten.
+func newHistoryAggregator(noCache, refresh bool) *datasource.Aggregator {
+	agg := datasource.NewAggregator()
+	if !noCache {
+		cache := datasource.NewHistoryCache(filepath.Join(config.Dir(), "cache", "history"))
+		cache.SetRefresh(refresh)
+		agg.SetHistoryCache(cache)
+	}
+	return agg
+}
+
+// newBacktestEngine returns an engine with the given capital, or the
+// configured initial capital when capital is zero.
+func newBacktestEngine(capital float64) *backtest.Engine {
+	btCfg := backtest.DefaultConfig()
+	if capital > 0 {
+		btCfg.InitialCapital = capital
+	} else if cfg.Trading.InitialCapital > 0 {
+		btCfg.InitialCapital = cfg.Trading.InitialCapital
+	}
+	return backtest.NewEngine(btCfg)
+}
+
 func init() {
 	backtestCmd.Flags().StringP("strategy", "s", "", "strategy name (required)")
 	backtestCmd.Flags().StringP("ticker", "t", "", "ticker symbol (required)")
-	backtestCmd.Flags().String("from", "2023-01-01", "start date (YYYY-MM-DD)")
+	backtestCmd.Flags().String("from", defaultBacktestFrom, "start date (YYYY-MM-DD)")
 	backtestCmd.Flags().String("to", "", "end date (YYYY-MM-DD, default: today)")
 	backtestCmd.Flags().Float64("capital", 0, "initial capital (default from config)")
 	backtestCmd.Flags().Bool("json", false, "output result as JSON")
+	backtestCmd.Flags().StringArray("param", nil, "override a strategy parameter as name=value (repeatable)")
+	backtestCmd.Flags().Bool("list-strategies", false, "list strategies and their parameters")
+	backtestCmd.Flags().Float64("split", 0, "in-sample fraction for an out-of-sample check, e.g. 0.7 (0 disables)")
+	backtestCmd.Flags().Bool("compare-runs", false, "compare two results saved with --json: backtest --compare-runs A.json B.json")
+	backtestCmd.Flags().Bool("no-cache", false, "fetch historical data without the on-disk cache")
+	backtestCmd.Flags().Bool("refresh", false, "refetch historical data and update the on-disk cache")
+	backtestCmd.Flags().String("timeframe", "1d", "bar timeframe: 1m, 5m, 15m, 1h, 1d, 1w")
+	backtestCmd.Flags().Bool("chart", false, "draw the equity curve with drawdown markers")
 }
 
 // --- Trade Command ---
@@ -488,11 +1082,20 @@ var tradeCmd = &cobra.Command{
 	Long: `Enter interactive trading mode with paper or live broker.
 
 The trade command provides a REPL-style interface for placing and managing orders
-with built-in risk management and human-in-the-loop confirmation.`,
+with built-in risk management and human-in-the-loop confirmation.
+
+Every order is recorded in the trade journal (~/.openseai/trades.jsonl by
+default), which "openseai trades export" writes out as CSV or JSON.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		journal, err := broker.OpenTradeLogger(tradeJournalPath(cmd))
+		if err != nil {
+			return err
+		}
+
 		fmt.Println("🔔 OpeNSE.ai — Interactive Trading Mode")
 		fmt.Printf("   Broker: %s\n", cfg.Broker.Provider)
 		fmt.Printf("   Mode:   %s\n", cfg.Trading.Mode)
+		fmt.Printf("   Journal: %s\n", tradeJournalPath(cmd))
 		fmt.Println()
 
 		b := broker.NewPaperBroker(nil)
@@ -502,6 +1105,7 @@ with built-in risk management and human-in-the-loop confirmation.`,
 		riskCfg.MaxOpenPositions = cfg.Trading.MaxOpenPositions
 		riskCfg.RequireApproval = cfg.Trading.RequireConfirmation
 		rm := broker.NewRiskManager(b, riskCfg)
+		rm.SetLogger(journal)
 
 		// Show current portfolio
 		ctx := context.Background()
@@ -511,7 +1115,7 @@ with built-in risk management and human-in-the-loop confirmation.`,
 			fmt.Println()
 		}
 
-		fmt.Println("Commands: buy, sell, positions, orders, margins, cancel, quit")
+		fmt.Println("Commands: buy, sell, positions, orders, margins, cancel, autoconfirm, quit")
 		fmt.Println("Example: buy RELIANCE 10 2850.00")
 		fmt.Println()
 
@@ -519,37 +1123,258 @@ with built-in risk management and human-in-the-loop confirmation.`,
 	},
 }
 
-// --- Watch Command ---
+func init() {
+	tradeCmd.Flags().String("journal", "", "trade journal file (default ~/.openseai/trades.jsonl)")
+}
 
-var watchCmd = &cobra.Command{
-	Use:   "watch [tickers...]",
-	Short: "Real-time watchlist with alerts",
-	Long:  "Monitor stocks in real-time with price updates and alert triggers.",
-	Args:  cobra.MinimumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		interval, _ := cmd.Flags().GetInt("interval")
+// tradeJournalPath returns the --journal flag, or the default trade
+// journal under the OpeNSE.ai directory.
+func tradeJournalPath(cmd *cobra.Command) string {
+	if p, _ := cmd.Flags().GetString("journal"); p != "" {
+		return p
+	}
+	return config.TradeJournalPath()
+}
 
-		tickers := make([]string, len(args))
-		for i, t := range args {
-			tickers[i] = utils.NormalizeTicker(t)
-		}
+// --- Trades Command ---
 
-		fmt.Printf("👀 Watching: %s (refresh every %ds)\n", strings.Join(tickers, ", "), interval)
-		fmt.Println("   Press Ctrl+C to stop")
-		fmt.Println()
+var tradesCmd = &cobra.Command{
+	Use:   "trades",
+	Short: "Work with the trade journal",
+}
 
-		agg := datasource.NewAggregator()
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+var tradesExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the trade journal as CSV or JSON",
+	Long: `Export every event in the trade journal, for tax filing or reconciliation
+with broker contract notes.
 
-		// Handle interrupt
-		sigCh := make(chan os.Signal, 1)
+Each row holds the order details, the fill quantity and average price, the
+charges on the fill, and the agent that placed it.
+
+Examples:
+  openseai trades export --format csv -o trades.csv
+  openseai trades export --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		if format != "csv" && format != "json" {
+			return fmt.Errorf("unknown format %q; use csv or json", format)
+		}
+
+		journal, err := broker.OpenTradeLogger(tradeJournalPath(cmd))
+		if err != nil {
+			return err
+		}
+
+		if output == "" {
+			return exportTrades(journal, format, os.Stdout)
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("cannot create %s: %w", output, err)
+		}
+		if err := exportTrades(journal, format, f); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d trade events to %s\n", journal.Count(), output)
+		return nil
+	},
+}
+
+func init() {
+	tradesCmd.AddCommand(tradesExportCmd)
+	tradesExportCmd.Flags().String("format", "csv", "output format: csv or json")
+	tradesExportCmd.Flags().StringP("output", "o", "", "output file (default stdout)")
+	tradesExportCmd.Flags().String("journal", "", "trade journal file (default ~/.openseai/trades.jsonl)")
+}
+
+// exportTrades writes the journal to w in the given format, csv or json.
+func exportTrades(journal *broker.TradeLogger, format string, w io.Writer) error {
+	if format == "json" {
+		return journal.ExportJSON(w)
+	}
+	return journal.ExportCSV(w)
+}
+
+// --- Download Command ---
+
+var downloadCmd = &cobra.Command{
+	Use:   "download",
+	Short: "Download and cache historical bars for a universe",
+	Long: `Fetch the historical bars of every ticker in a universe into the local
+history cache (~/.openseai/cache/history), so later backtests and queries
+over the same range run offline.
+
+Ranges already in the cache are skipped, so an interrupted download can be
+rerun to fetch only what is missing. Use --refresh to fetch everything again.
+
+The universe is a name from the config, a ticker file, or an index:
+NIFTY50, BANKNIFTY, NIFTYIT.
+
+Examples:
+  openseai download --universe nifty50 --from 2015-01-01
+  openseai download --universe watchlist.txt --from 2020-01-01 --timeframe 1h`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		universe, _ := cmd.Flags().GetString("universe")
+		fromStr, _ := cmd.Flags().GetString("from")
+		toStr, _ := cmd.Flags().GetString("to")
+		tfStr, _ := cmd.Flags().GetString("timeframe")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		retries, _ := cmd.Flags().GetInt("retries")
+		refresh, _ := cmd.Flags().GetBool("refresh")
+
+		if universe == "" {
+			return fmt.Errorf("--universe is required")
+		}
+		tf, err := models.ParseTimeframe(tfStr)
+		if err != nil {
+			return fmt.Errorf("invalid --timeframe: %w", err)
+		}
+		from, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return fmt.Errorf("invalid --from date: %w", err)
+		}
+		to := time.Now()
+		if toStr != "" {
+			to, err = time.Parse("2006-01-02", toStr)
+			if err != nil {
+				return fmt.Errorf("invalid --to date: %w", err)
+			}
+		}
+		if !from.Before(to) {
+			return fmt.Errorf("--from must be before --to")
+		}
+		if retries == 0 {
+			retries = -1 // DownloadOptions reads zero as the default
+		}
+
+		ctx, cancel := commandContext(cmd, time.Hour)
+		defer cancel()
+		agg := newHistoryAggregator(false, refresh)
+
+		tickers, err := downloadUniverse(ctx, agg, universe)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "📥 Downloading %s bars for %d tickers (%s to %s)\n", tf, len(tickers),
+			from.Format("2006-01-02"), to.Format("2006-01-02"))
+		results, err := agg.DownloadHistory(ctx, tickers, from, to, tf, datasource.DownloadOptions{
+			Concurrency: concurrency,
+			Retries:     retries,
+			Progress: func(done, total int, r datasource.DownloadResult) {
+				fmt.Fprintf(os.Stderr, "\r%s %d/%d %-12s", progressBar(done, total, 30), done, total, r.Ticker)
+			},
+		})
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return err
+		}
+
+		var fetched, cached int
+		var failed []string
+		for _, r := range results {
+			switch {
+			case r.Err != nil:
+				failed = append(failed, r.Ticker)
+				fmt.Fprintf(os.Stderr, "   ✗ %s: %v\n", r.Ticker, r.Err)
+			case r.Cached:
+				cached++
+			default:
+				fetched++
+			}
+		}
+		fmt.Printf("Fetched %d, already cached %d, failed %d\n", fetched, cached, len(failed))
+		if len(failed) > 0 {
+			return fmt.Errorf("%d tickers failed to download; rerun to retry them", len(failed))
+		}
+		return nil
+	},
+}
+
+func init() {
+	downloadCmd.Flags().String("universe", "", "tickers to download: a universe from the config, a ticker file, or an index (required)")
+	downloadCmd.Flags().String("from", defaultBacktestFrom, "start date (YYYY-MM-DD)")
+	downloadCmd.Flags().String("to", "", "end date (YYYY-MM-DD, default: today)")
+	downloadCmd.Flags().String("timeframe", "1d", "bar timeframe: 1m, 5m, 15m, 1h, 1d, 1w")
+	downloadCmd.Flags().Int("concurrency", 4, "tickers fetched at once")
+	downloadCmd.Flags().Int("retries", 2, "further attempts for a ticker whose fetch fails")
+	downloadCmd.Flags().Bool("refresh", false, "refetch ranges that are already cached")
+}
+
+// downloadUniverse resolves the download --universe value: a configured
+// universe or ticker file as by loadUniverse, else an index with known
+// constituents.
+func downloadUniverse(ctx context.Context, agg *datasource.Aggregator, spec string) ([]string, error) {
+	tickers, err := loadUniverse(spec, configUniverses())
+	if err == nil {
+		return tickers, nil
+	}
+	if constituents, idxErr := agg.IndexConstituents(ctx, spec); idxErr == nil {
+		return constituents, nil
+	}
+	return nil, err
+}
+
+// progressBar renders done out of total as a bar width cells wide.
+func progressBar(done, total, width int) string {
+	filled := width
+	if total > 0 {
+		filled = done * width / total
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
+
+// --- Watch Command ---
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [tickers...]",
+	Short: "Real-time watchlist with alerts",
+	Long:  "Monitor stocks in real-time with price updates and alert triggers.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetInt("interval")
+
+		tickers := make([]string, len(args))
+		for i, t := range args {
+			tickers[i] = utils.NormalizeTicker(t)
+		}
+
+		fmt.Printf("👀 Watching: %s (refresh every %ds)\n", strings.Join(tickers, ", "), interval)
+		fmt.Println("   Press Ctrl+C to stop")
+		fmt.Println()
+
+		agg, err := newAggregator()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// Handle interrupt
+		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 		go func() {
 			<-sigCh
 			cancel()
 		}()
 
+		if liquidity := liquidityFlags(cmd); liquidity.Enabled() {
+			var dropped []string
+			tickers, dropped = filterLiquid(ctx, agg, tickers, liquidity)
+			if len(dropped) > 0 {
+				fmt.Printf("   Skipping illiquid: %s\n", strings.Join(dropped, ", "))
+			}
+			if len(tickers) == 0 {
+				return fmt.Errorf("no tickers meet the liquidity threshold")
+			}
+		}
+
 		tickerTimer := time.NewTicker(time.Duration(interval) * time.Second)
 		defer tickerTimer.Stop()
 
@@ -570,6 +1395,100 @@ var watchCmd = &cobra.Command{
 
 func init() {
 	watchCmd.Flags().Int("interval", 30, "refresh interval in seconds")
+	addLiquidityFlags(watchCmd)
+}
+
+// addLiquidityFlags registers the --min-volume and --min-turnover flags
+// read by liquidityFlags.
+func addLiquidityFlags(cmd *cobra.Command) {
+	cmd.Flags().Float64("min-volume", 0, "skip stocks whose average daily volume is below this")
+	cmd.Flags().Float64("min-turnover", 0, "skip stocks whose average daily turnover (INR) is below this")
+}
+
+func liquidityFlags(cmd *cobra.Command) datasource.LiquidityFilter {
+	minVolume, _ := cmd.Flags().GetFloat64("min-volume")
+	minTurnover, _ := cmd.Flags().GetFloat64("min-turnover")
+	return datasource.LiquidityFilter{MinVolume: minVolume, MinTurnover: minTurnover}
+}
+
+// filterLiquid returns the tickers whose average daily volume and turnover
+// over the last 30 days clear f. Tickers without history are dropped.
+func filterLiquid(ctx context.Context, agg *datasource.Aggregator, tickers []string, f datasource.LiquidityFilter) (kept, dropped []string) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -30)
+	for _, t := range tickers {
+		bars, err := agg.FetchHistoricalData(ctx, t, from, to, models.Timeframe1Day)
+		if err != nil || len(bars) == 0 || !f.Allows(datasource.MeasureLiquidity(bars)) {
+			dropped = append(dropped, t)
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept, dropped
+}
+
+// --- Heatmap Command ---
+
+var heatmapCmd = &cobra.Command{
+	Use:   "heatmap [index]",
+	Short: "Show a daily-change heatmap of an index's constituents",
+	Long: `Fetch every constituent of an index and print a colored grid of their
+daily change, heaviest market-cap weight first.
+
+Supported indices: NIFTY50, BANKNIFTY, NIFTYIT. Use --universe instead of
+an index to map a universe named in the config, or a ticker file.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputJSON, _ := cmd.Flags().GetBool("json")
+		cols, _ := cmd.Flags().GetInt("columns")
+		universe, _ := cmd.Flags().GetString("universe")
+		if (universe == "") == (len(args) == 0) {
+			return fmt.Errorf("specify either an index or --universe")
+		}
+
+		ctx, cancel := commandContext(cmd, time.Minute)
+		defer cancel()
+
+		agg, err := newAggregator()
+		if err != nil {
+			return err
+		}
+		var (
+			index string
+			cells []models.HeatmapCell
+		)
+		if universe != "" {
+			var tickers []string
+			if tickers, err = loadUniverse(universe, configUniverses()); err != nil {
+				return err
+			}
+			index = universe
+			cells, err = datasource.FetchHeatmapTickers(ctx, agg, universe, tickers)
+		} else {
+			index = utils.NormalizeTicker(args[0])
+			cells, err = datasource.FetchHeatmap(ctx, agg, index)
+		}
+		if err != nil {
+			return fmt.Errorf("heatmap failed: %w", err)
+		}
+
+		if outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(cells)
+		}
+
+		fmt.Printf("🗺️  %s Heatmap (%d constituents)\n", index, len(cells))
+		fmt.Println()
+		printHeatmap(cells, cols)
+		return nil
+	},
+}
+
+func init() {
+	heatmapCmd.Flags().Bool("json", false, "output result as JSON")
+	heatmapCmd.Flags().Int("columns", 5, "number of cells per row")
+	heatmapCmd.Flags().String("universe", "", "map a universe instead of an index: a name from the config, or a ticker file")
 }
 
 // --- Portfolio Command ---
@@ -579,6 +1498,7 @@ var portfolioCmd = &cobra.Command{
 	Short: "Portfolio analysis from broker",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		outputJSON, _ := cmd.Flags().GetBool("json")
+		showAttribution, _ := cmd.Flags().GetBool("attribution")
 
 		fmt.Println("💼 Portfolio Summary")
 		fmt.Println()
@@ -606,6 +1526,15 @@ var portfolioCmd = &cobra.Command{
 			return fmt.Errorf("failed to get orders: %w", err)
 		}
 
+		var attribution []broker.TickerAttribution
+		if showAttribution {
+			journal, err := broker.OpenTradeLogger(tradeJournalPath(cmd))
+			if err != nil {
+				return err
+			}
+			attribution = broker.JournalAttribution(journal.Logs())
+		}
+
 		if outputJSON {
 			data := map[string]any{
 				"margins":   margins,
@@ -613,6 +1542,9 @@ var portfolioCmd = &cobra.Command{
 				"holdings":  holdings,
 				"orders":    orders,
 			}
+			if showAttribution {
+				data["attribution"] = attribution
+			}
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
 			return enc.Encode(data)
@@ -644,9 +1576,10 @@ var portfolioCmd = &cobra.Command{
 		// Print holdings
 		fmt.Printf("═══ Holdings (%d) ═══\n", len(holdings))
 		for _, h := range holdings {
-			fmt.Printf("  %-15s %5d @ %s  CMP: %s  PnL: %s\n",
+			pct, trend := utils.FormatPctTrend(h.PnLPct, true)
+			fmt.Printf("  %-15s %5d @ %s  CMP: %s  PnL: %s (%s)\n",
 				h.Ticker, h.Quantity, utils.FormatINR(h.AvgPrice),
-				utils.FormatINR(h.LTP), utils.FormatINR(h.PnL))
+				utils.FormatINR(h.LTP), utils.FormatINR(h.PnL), colorize(pct, trend))
 		}
 		if len(holdings) == 0 {
 			fmt.Println("  No holdings")
@@ -663,12 +1596,118 @@ var portfolioCmd = &cobra.Command{
 			fmt.Println("  No orders")
 		}
 
+		if showAttribution {
+			fmt.Println()
+			printAttribution(attribution)
+		}
+
 		return nil
 	},
 }
 
 func init() {
 	portfolioCmd.Flags().Bool("json", false, "output result as JSON")
+	portfolioCmd.Flags().Bool("attribution", false, "show realized and unrealized P&L, trades, and win rate per ticker from the trade journal")
+	portfolioCmd.Flags().String("journal", "", "trade journal file (default ~/.openseai/trades.jsonl)")
+}
+
+// printAttribution prints per-ticker P&L attribution, largest contributor first.
+func printAttribution(rows []broker.TickerAttribution) {
+	fmt.Printf("═══ P&L Attribution (%d) ═══\n", len(rows))
+	if len(rows) == 0 {
+		fmt.Println("  No trades")
+		return
+	}
+	fmt.Printf("  %-15s %14s %14s %14s %7s %7s\n", "TICKER", "REALIZED", "UNREALIZED", "TOTAL", "TRADES", "WIN%")
+	for _, a := range rows {
+		winRate := "-"
+		if a.Trades > 0 {
+			winRate = fmt.Sprintf("%.0f%%", a.WinRatePct)
+		}
+		fmt.Printf("  %-15s %14s %14s %14s %7d %7s\n", a.Ticker,
+			utils.FormatINR(a.RealizedPnL), utils.FormatINR(a.UnrealizedPnL), utils.FormatINR(a.TotalPnL),
+			a.Trades, winRate)
+	}
+}
+
+// --- Risk Command ---
+
+var riskCmd = &cobra.Command{
+	Use:   "risk",
+	Short: "Risk tools for your portfolio",
+}
+
+var riskStopsCmd = &cobra.Command{
+	Use:     "stops",
+	Aliases: []string{"stop-loss"},
+	Short:   "Suggest stop-losses for current holdings",
+	Long: `Suggest ATR-based stop-losses for every delivery holding left open by
+the fills in the trade journal.
+
+Each holding gets a tight (1.5x ATR), moderate (2x ATR), and wide (3x ATR)
+stop below its last traded price, using the 14-day ATR, along with the
+rupee loss on the whole position if that stop is hit.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputJSON, _ := cmd.Flags().GetBool("json")
+
+		ctx, cancel := commandContext(cmd, 2*time.Minute)
+		defer cancel()
+
+		journal, err := broker.OpenTradeLogger(tradeJournalPath(cmd))
+		if err != nil {
+			return err
+		}
+		holdings := broker.JournalHoldings(journal.Logs())
+
+		agg, err := newAggregator()
+		if err != nil {
+			return err
+		}
+		// Stops go below the live price; keep the last fill if no quote.
+		for i := range holdings {
+			if q, err := agg.GetQuote(ctx, holdings[i].Ticker); err == nil && q.LastPrice > 0 {
+				holdings[i].LTP = q.LastPrice
+			}
+		}
+		stops := agent.SuggestHoldingStops(ctx, holdings, agent.HistoryATR(agg.FetchHistoricalData))
+
+		if outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(stops)
+		}
+
+		fmt.Printf("🛑 Stop-Loss Suggestions (%d holdings)\n", len(stops))
+		fmt.Println()
+		printHoldingStops(stops)
+		return nil
+	},
+}
+
+func init() {
+	riskCmd.AddCommand(riskStopsCmd)
+	riskStopsCmd.Flags().Bool("json", false, "output result as JSON")
+	riskStopsCmd.Flags().String("journal", "", "trade journal file (default ~/.openseai/trades.jsonl)")
+}
+
+// printHoldingStops prints each holding's stop levels and rupee risk.
+func printHoldingStops(stops []agent.HoldingStop) {
+	if len(stops) == 0 {
+		fmt.Println("  No holdings")
+		return
+	}
+	for _, hs := range stops {
+		fmt.Printf("  %-15s %5d @ %s", hs.Ticker, hs.Quantity, utils.FormatINR(hs.Price))
+		if hs.Error != "" {
+			fmt.Printf("  ⚠️  %s\n", hs.Error)
+			continue
+		}
+		fmt.Printf("  ATR(%d): %.2f\n", agent.StopATRPeriod, hs.ATR)
+		for _, l := range hs.Levels {
+			fmt.Printf("    %-9s %s  (-%.2f%%, %.1fx ATR)  Risk: %s\n",
+				l.Name, utils.FormatINR(l.Price), l.RiskPct, l.Multiplier, utils.FormatINR(l.Risk))
+		}
+	}
 }
 
 // --- Query Command (FinanceQL) ---
@@ -682,12 +1721,33 @@ Examples:
   openseai query 'rsi(RELIANCE, 14)'
   openseai query 'price(TCS)[30d] | sma(20) | trend()'
   openseai query 'screener(pe < 15 AND roe > 20)'
+  openseai query --universe watchlist.txt 'screener(rsi(*, 14) < 30)'
+  openseai query --explain 'rsi(RELIANCE, 14)'
   openseai query --repl
   openseai query --nl "oversold IT stocks"`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		replFlag, _ := cmd.Flags().GetBool("repl")
 		nl, _ := cmd.Flags().GetString("nl")
 		outputJSON, _ := cmd.Flags().GetBool("json")
+		precision, _ := cmd.Flags().GetInt("precision")
+		universe, _ := cmd.Flags().GetString("universe")
+		explain, _ := cmd.Flags().GetBool("explain")
+		if describe, _ := cmd.Flags().GetBool("describe"); describe {
+			explain = true
+		}
+		liquidity := liquidityFlags(cmd)
+
+		var tickers []string
+		if universe != "" {
+			var err error
+			if tickers, err = loadUniverse(universe, configUniverses()); err != nil {
+				return err
+			}
+		}
+		setUniverse := func(ec *financeql.EvalContext) {
+			ec.Universe = tickers
+			ec.Universes = configUniverses()
+		}
 
 		agg := datasource.NewAggregator()
 
@@ -696,6 +1756,7 @@ Examples:
 			fmt.Println("   Type .help for commands, .quit to exit")
 			fmt.Println()
 			repl := financeql.NewREPL(agg)
+			setUniverse(repl.EvalContext())
 			repl.Run()
 			return nil
 		}
@@ -710,7 +1771,7 @@ Examples:
 				return err
 			}
 
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			ctx, cancel := commandContext(cmd, 30*time.Second)
 			defer cancel()
 
 			prompt := fmt.Sprintf("Translate this natural language query to a FinanceQL expression. "+
@@ -727,12 +1788,14 @@ Examples:
 			// Execute the translated expression
 			ec := financeql.NewEvalContext(ctx, agg)
 			financeql.RegisterBuiltins(ec)
+			ec.Liquidity = liquidity
+			setUniverse(ec)
 			val, err := financeql.EvalQuery(ec, fqlExpr)
 			if err != nil {
 				return fmt.Errorf("FinanceQL execution failed: %w", err)
 			}
 
-			printFinanceQLResult(val, outputJSON)
+			printFinanceQLResult(val, precision, outputJSON)
 			return nil
 		}
 
@@ -744,17 +1807,30 @@ Examples:
 		fmt.Printf("📟 FinanceQL: %s\n", expr)
 		fmt.Println()
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := commandContext(cmd, 30*time.Second)
 		defer cancel()
 
 		ec := financeql.NewEvalContext(ctx, agg)
 		financeql.RegisterBuiltins(ec)
+		ec.Liquidity = liquidity
+		setUniverse(ec)
+		if explain {
+			val, explanations, err := financeql.EvalQueryExplain(ec, expr)
+			if err != nil {
+				return fmt.Errorf("FinanceQL error: %w", err)
+			}
+			printFinanceQLResult(val, precision, outputJSON)
+			if !outputJSON {
+				printExplanations(explanations)
+			}
+			return nil
+		}
 		val, err := financeql.EvalQuery(ec, expr)
 		if err != nil {
 			return fmt.Errorf("FinanceQL error: %w", err)
 		}
 
-		printFinanceQLResult(val, outputJSON)
+		printFinanceQLResult(val, precision, outputJSON)
 		return nil
 	},
 }
@@ -763,6 +1839,12 @@ func init() {
 	queryCmd.Flags().Bool("repl", false, "start interactive FinanceQL REPL")
 	queryCmd.Flags().String("nl", "", "natural language query to translate to FinanceQL")
 	queryCmd.Flags().Bool("json", false, "output result as JSON")
+	queryCmd.Flags().Int("precision", 0, "decimal places for numbers (0 = by magnitude)")
+	queryCmd.Flags().String("universe", "", "tickers scanned by screener(): a universe name from the config, or a ticker file")
+	queryCmd.Flags().Bool("explain", false, "describe in plain English what each function computed and the data it used")
+	queryCmd.Flags().Bool("describe", false, "alias for --explain")
+	_ = queryCmd.Flags().MarkHidden("describe")
+	addLiquidityFlags(queryCmd)
 }
 
 // --- Chat Command ---
@@ -770,9 +1852,39 @@ func init() {
 var chatCmd = &cobra.Command{
 	Use:   "chat",
 	Short: "Start interactive chat mode",
-	Long:  "Start a conversational interface with the AI agent for free-form analysis queries.",
+	Long: `Start a conversational interface with the AI agent for free-form analysis queries.
+
+With --deep, a message naming a ticker runs the multi-agent analysis,
+showing each agent as it starts; follow-ups without a ticker are answered
+by the single agent with the conversation as context.
+
+Use --persona to set the assistant's investing style from a bundled preset
+(conservative, aggressive, quant), and --system to add your own
+instructions. Both are layered over the default system prompt.
+
+Use --save to write the conversation to a JSON transcript when the chat
+ends, and --load to resume from one. The transcript keeps your messages and
+the assistant's replies; tool calls made while answering are not recorded.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		deep, _ := cmd.Flags().GetBool("deep")
+		persona, _ := cmd.Flags().GetString("persona")
+		system, _ := cmd.Flags().GetString("system")
+		savePath, _ := cmd.Flags().GetString("save")
+		loadPath, _ := cmd.Flags().GetString("load")
+
+		instructions, err := chatInstructions(persona, system)
+		if err != nil {
+			return err
+		}
+
+		var history []llm.Message
+		if loadPath != "" {
+			t, err := readChatTranscript(loadPath)
+			if err != nil {
+				return err
+			}
+			history = t.Messages
+		}
 
 		fmt.Println("💬 OpeNSE.ai Chat Mode")
 		if deep {
@@ -780,7 +1892,13 @@ var chatCmd = &cobra.Command{
 		} else {
 			fmt.Println("   Mode: Quick (single-agent)")
 		}
-		fmt.Println("   Type 'quit' or 'exit' to leave")
+		if persona != "" {
+			fmt.Printf("   Persona: %s\n", persona)
+		}
+		if loadPath != "" {
+			fmt.Printf("   Resumed: %s (%d messages)\n", loadPath, len(history))
+		}
+		fmt.Println("   Type '/help' for commands, 'quit' or 'exit' to leave")
 		fmt.Println()
 
 		orch, err := newOrchestrator()
@@ -791,13 +1909,45 @@ var chatCmd = &cobra.Command{
 		if deep {
 			orch.SetMode(agent.ModeMulti)
 		}
+		orch.SetInstructions(instructions)
 
-		return runChatREPL(orch)
+		history, err = runChatREPL(orch, commandTimeout(cmd, 2*time.Minute), history)
+		if err != nil {
+			return err
+		}
+		if savePath != "" {
+			if err := writeChatTranscript(savePath, history); err != nil {
+				return fmt.Errorf("save transcript: %w", err)
+			}
+			fmt.Printf("💾 Transcript saved to %s\n", savePath)
+		}
+		return nil
 	},
 }
 
 func init() {
 	chatCmd.Flags().Bool("deep", false, "use multi-agent deep analysis mode")
+	chatCmd.Flags().String("persona", "", "assistant persona: "+strings.Join(prompts.PersonaNames(), ", "))
+	chatCmd.Flags().String("system", "", "extra system instructions for the assistant")
+	chatCmd.Flags().String("save", "", "write the conversation to this JSON transcript on exit")
+	chatCmd.Flags().String("load", "", "resume the conversation from a JSON transcript")
+}
+
+// chatInstructions combines the named persona's prompt and a custom system
+// message into the instructions layered over the default system prompt.
+func chatInstructions(persona, system string) (string, error) {
+	var parts []string
+	if persona != "" {
+		p, err := prompts.Persona(persona)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, p)
+	}
+	if s := strings.TrimSpace(system); s != "" {
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, "\n\n"), nil
 }
 
 // --- Serve Command (API Server) ---
@@ -811,7 +1961,16 @@ The server exposes endpoints for analysis, quotes, backtesting, portfolio,
 chat, FinanceQL queries, and WebSocket streaming.
 
 By default, the embedded web UI is served at / and the API at /api/v1.
-Use --no-ui to disable the web UI and serve only the API.`,
+Use --no-ui to disable the web UI and serve only the API.
+
+Use --tls-cert and --tls-key to serve HTTPS. Add --http-redirect to also
+listen on a plain-HTTP address that redirects to HTTPS.
+
+Use --fixtures to serve quotes, history, heatmaps, and market data from a
+JSON fixtures file instead of live sources, for demos.
+
+Examples:
+  openseai serve --port 8443 --tls-cert cert.pem --tls-key key.pem --http-redirect :8080`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		port, _ := cmd.Flags().GetInt("port")
 		if port == 0 {
@@ -822,6 +1981,14 @@ Use --no-ui to disable the web UI and serve only the API.`,
 			host = cfg.API.Host
 		}
 		noUI, _ := cmd.Flags().GetBool("no-ui")
+		certFile, _ := cmd.Flags().GetString("tls-cert")
+		keyFile, _ := cmd.Flags().GetString("tls-key")
+		redirectAddr, _ := cmd.Flags().GetString("http-redirect")
+		fixturesFile, _ := cmd.Flags().GetString("fixtures")
+		useTLS := certFile != "" || keyFile != ""
+		if redirectAddr != "" && !useTLS {
+			return fmt.Errorf("--http-redirect requires --tls-cert and --tls-key")
+		}
 
 		srv, err := api.NewServer(cfg)
 		if err != nil {
@@ -832,23 +1999,54 @@ Use --no-ui to disable the web UI and serve only the API.`,
 			srv.SetServeUI(false)
 		}
 
-		addr := fmt.Sprintf("%s:%d", host, port)
-		fmt.Printf("🌐 Starting OpeNSE.ai server on %s\n", addr)
-		if !noUI {
-			fmt.Printf("   Web UI:  http://%s/\n", resolveDisplayAddr(host, port))
+		if fixturesFile != "" {
+			fx, err := api.LoadFixtures(fixturesFile)
+			if err != nil {
+				return err
+			}
+			srv.SetAggregator(fx)
 		}
-		fmt.Printf("   API:     http://%s/api/v1\n", resolveDisplayAddr(host, port))
-		fmt.Println()
-		fmt.Println("   Endpoints:")
+
+		scheme := "http"
+		if useTLS {
+			if err := srv.SetTLS(api.TLSConfig{
+				CertFile:     certFile,
+				KeyFile:      keyFile,
+				RedirectAddr: redirectAddr,
+			}); err != nil {
+				return err
+			}
+			scheme = "https"
+		}
+
+		addr := fmt.Sprintf("%s:%d", host, port)
+		fmt.Printf("🌐 Starting OpeNSE.ai server on %s\n", addr)
+		if !noUI {
+			fmt.Printf("   Web UI:  %s://%s/\n", scheme, resolveDisplayAddr(host, port))
+		}
+		fmt.Printf("   API:     %s://%s/api/v1\n", scheme, resolveDisplayAddr(host, port))
+		if redirectAddr != "" {
+			fmt.Printf("   HTTP %s redirects to HTTPS\n", redirectAddr)
+		}
+		if fixturesFile != "" {
+			fmt.Printf("   Market data from fixtures %s\n", fixturesFile)
+		}
+		fmt.Println()
+		fmt.Println("   Endpoints:")
 		fmt.Println("     POST /api/v1/analyze    — run analysis")
 		fmt.Println("     GET  /api/v1/quote/:t   — live quote")
 		fmt.Println("     POST /api/v1/backtest   — run backtest")
+		fmt.Println("     POST /api/v1/backtest/stream — backtest with SSE progress")
 		fmt.Println("     GET  /api/v1/portfolio   — portfolio summary")
+		fmt.Println("     GET  /api/v1/heatmap/:i  — index heatmap")
 		fmt.Println("     POST /api/v1/chat        — chat")
 		fmt.Println("     POST /api/v1/query       — FinanceQL query")
 		fmt.Println("     POST /api/v1/query/explain — explain FinanceQL")
 		fmt.Println("     POST /api/v1/query/nl    — natural language query")
 		fmt.Println("     GET  /api/v1/alerts      — active alerts")
+		if cfg.API.WebhookSecret != "" {
+			fmt.Println("     POST /api/v1/webhook/tradingview — signed TradingView alerts")
+		}
 		fmt.Println("     WS   /api/v1/ws          — WebSocket streaming")
 		fmt.Println()
 		fmt.Println("   Press Ctrl+C to stop")
@@ -869,6 +2067,87 @@ func init() {
 	serveCmd.Flags().IntP("port", "p", 0, "server port (default from config)")
 	serveCmd.Flags().String("host", "", "server host (default from config)")
 	serveCmd.Flags().Bool("no-ui", false, "disable embedded web UI (API only)")
+	serveCmd.Flags().String("tls-cert", "", "TLS certificate file (PEM); enables HTTPS")
+	serveCmd.Flags().String("tls-key", "", "TLS private key file (PEM)")
+	serveCmd.Flags().String("http-redirect", "", "also listen on this HTTP address and redirect to HTTPS (e.g. :80)")
+	serveCmd.Flags().String("fixtures", "", "serve market data from this JSON fixtures file (demo mode)")
+}
+
+// --- MCP Command ---
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve OpeNSE tools to external agents over MCP (stdio)",
+	Long: `Serve the stock analysis tools (get_quote, compute_indicators,
+get_option_chain, ...) over the Model Context Protocol on stdin/stdout, so
+an external LLM agent can list and call them.
+
+Tools run against the configured data sources; no LLM key is needed.
+Diagnostics are written to stderr; stdout carries only JSON-RPC messages.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		disabled, _ := cmd.Flags().GetStringSlice("disable-tool")
+
+		orch := agent.NewOrchestrator(agent.OrchestratorConfig{
+			Aggregator:    datasource.NewAggregator(),
+			Capital:       cfg.Trading.InitialCapital,
+			DisabledTools: disabled,
+		})
+		srv := mcp.NewServer("openseai", version, orch.Tools())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-sigCh
+			cancel()
+		}()
+
+		fmt.Fprintf(os.Stderr, "openseai MCP server: %d tools on stdio\n", len(orch.Tools()))
+		return srv.Serve(ctx, os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	mcpCmd.Flags().StringSlice("disable-tool", nil, "tool names to hide from MCP clients (repeatable)")
+}
+
+// --- Config Command ---
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration file",
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a commented default config file",
+	Long: `Write a config file holding every setting at its default value, with a
+comment explaining each one: LLM providers, broker, trading limits, the API
+server, and more. Edit it, then point --config at it or leave it at
+./config/config.yaml where it is found automatically.
+
+An existing file is not overwritten unless --force is given.
+
+Examples:
+  openseai config init
+  openseai config init --path ~/.openseai/config.yaml --force`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("path")
+		force, _ := cmd.Flags().GetBool("force")
+		if err := config.WriteDefault(path, force); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Config written: %s\n", path)
+		return nil
+	},
+}
+
+func init() {
+	configInitCmd.Flags().String("path", filepath.Join("config", "config.yaml"), "where to write the config file")
+	configInitCmd.Flags().Bool("force", false, "overwrite an existing file")
+	configCmd.AddCommand(configInitCmd)
 }
 
 // --- Status Command ---
@@ -876,73 +2155,237 @@ func init() {
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show system status and configuration",
+	Long: `Show version, market status, configuration, API key status, and the
+health of the market data sources.
+
+Use --json for a machine-readable report suitable for scripts and uptime checks.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		outputJSON, _ := cmd.Flags().GetBool("json")
+
+		ctx, cancel := commandContext(cmd, 10*time.Second)
+		defer cancel()
+		report := buildStatusReport(datasource.NewAggregator().CheckHealth(ctx))
+
+		if outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(report)
+		}
+
 		fmt.Println("═══════════════════════════════════════")
 		fmt.Println("  OpeNSE.ai — System Status")
 		fmt.Println("═══════════════════════════════════════")
-		fmt.Printf("  Version:       %s (%s)\n", version, commit)
-		fmt.Printf("  Market Status: %s\n", utils.MarketStatus())
-		fmt.Printf("  Time (IST):    %s\n", utils.FormatDateTimeIST(utils.NowIST()))
+		fmt.Printf("  Version:       %s (%s)\n", report.Version, report.Commit)
+		fmt.Printf("  Market Status: %s\n", report.MarketStatus)
+		fmt.Printf("  Time (IST):    %s\n", utils.FormatDateTimeIST(report.Time))
 		fmt.Println()
 
 		// Config summary
 		fmt.Println("  Configuration:")
-		fmt.Printf("    LLM Provider:  %s (model: %s)\n", cfg.LLM.Primary, cfg.LLM.Model)
-		fmt.Printf("    Broker:        %s\n", cfg.Broker.Provider)
-		fmt.Printf("    Trading Mode:  %s\n", cfg.Trading.Mode)
-		fmt.Printf("    API Server:    %s:%d\n", cfg.API.Host, cfg.API.Port)
+		fmt.Printf("    LLM Provider:  %s (model: %s)\n", report.Config.LLMProvider, report.Config.LLMModel)
+		fmt.Printf("    Broker:        %s\n", report.Config.Broker)
+		fmt.Printf("    Trading Mode:  %s\n", report.Config.TradingMode)
+		fmt.Printf("    API Server:    %s\n", report.Config.APIAddr)
 		fmt.Println()
 
 		// API keys status
 		fmt.Println("  API Keys:")
-		keys := config.CheckAPIKeys(cfg)
-		for _, k := range keys {
+		for _, k := range report.APIKeys {
 			status := "❌ not set"
 			if k.IsSet {
 				status = fmt.Sprintf("✅ set (%s: %s)", k.Source, k.Masked)
 			}
 			fmt.Printf("    %-25s %s\n", k.Name+":", status)
 		}
+		fmt.Println()
+
+		// Data source health
+		fmt.Println("  Data Sources:")
+		for _, h := range report.Sources {
+			status := fmt.Sprintf("✅ ok (%d ms)", h.LatencyMS)
+			if !h.OK {
+				status = "❌ " + h.Error
+			}
+			fmt.Printf("    %-25s %s\n", h.Name+":", status)
+		}
 
 		fmt.Println("═══════════════════════════════════════")
 		return nil
 	},
 }
 
+func init() {
+	statusCmd.Flags().Bool("json", false, "output status as JSON")
+}
+
+// statusReport is the structured output of the status command.
+type statusReport struct {
+	Version      string                    `json:"version"`
+	Commit       string                    `json:"commit"`
+	MarketStatus string                    `json:"market_status"`
+	Time         time.Time                 `json:"time"`
+	Config       statusConfig              `json:"config"`
+	APIKeys      []config.KeyStatus        `json:"api_keys"`
+	Sources      []datasource.SourceHealth `json:"sources"`
+}
+
+// statusConfig summarizes the active configuration.
+type statusConfig struct {
+	LLMProvider string `json:"llm_provider"`
+	LLMModel    string `json:"llm_model"`
+	Broker      string `json:"broker"`
+	TradingMode string `json:"trading_mode"`
+	APIAddr     string `json:"api_addr"`
+}
+
+// buildStatusReport assembles the status report from the loaded config
+// and the given data source health results.
+func buildStatusReport(sources []datasource.SourceHealth) statusReport {
+	if sources == nil {
+		sources = []datasource.SourceHealth{}
+	}
+	return statusReport{
+		Version:      version,
+		Commit:       commit,
+		MarketStatus: utils.MarketStatus(),
+		Time:         utils.NowIST(),
+		Config: statusConfig{
+			LLMProvider: cfg.LLM.Primary,
+			LLMModel:    cfg.LLM.Model,
+			Broker:      cfg.Broker.Provider,
+			TradingMode: cfg.Trading.Mode,
+			APIAddr:     fmt.Sprintf("%s:%d", cfg.API.Host, cfg.API.Port),
+		},
+		APIKeys: config.CheckAPIKeys(cfg),
+		Sources: sources,
+	}
+}
+
 // ============================================================
 // Helper functions
 // ============================================================
 
+// verbosity controls how much of an agent result is printed.
+type verbosity int
+
+const (
+	verbosityQuiet   verbosity = iota // recommendation and confidence only
+	verbosityNormal                   // content, analysis, tool-call count, and token usage
+	verbosityVerbose                  // normal plus tool trace
+)
+
+// outputVerbosity is set from the --quiet/--verbose flags.
+var outputVerbosity = verbosityNormal
+
 func printAgentResult(r *agent.AgentResult) {
-	fmt.Println("═══════════════════════════════════════")
-	fmt.Printf("  Agent: %s (%s)\n", r.AgentName, r.Role)
-	fmt.Printf("  Duration: %s\n", r.Duration.Round(time.Millisecond))
-	fmt.Println("═══════════════════════════════════════")
-	fmt.Println()
-	fmt.Println(r.Content)
-	fmt.Println()
+	renderAgentResult(os.Stdout, r, outputVerbosity)
+}
+
+// renderAgentResult writes r to w at the given verbosity. Quiet output falls
+// back to the content when the result has no structured analysis.
+func renderAgentResult(w io.Writer, r *agent.AgentResult, v verbosity) {
+	if v == verbosityQuiet {
+		if r.Analysis == nil {
+			fmt.Fprintln(w, r.Content)
+			return
+		}
+		fmt.Fprintf(w, "Recommendation: %s\n", r.Analysis.Recommendation)
+		fmt.Fprintf(w, "Confidence:     %.0f%%\n", float64(r.Analysis.Confidence)*100)
+		return
+	}
+
+	fmt.Fprintln(w, "═══════════════════════════════════════")
+	fmt.Fprintf(w, "  Agent: %s (%s)\n", r.AgentName, r.Role)
+	fmt.Fprintf(w, "  Duration: %s\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintln(w, "═══════════════════════════════════════")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, r.Content)
+	fmt.Fprintln(w)
 
 	if r.Analysis != nil {
-		fmt.Printf("  Recommendation: %s\n", r.Analysis.Recommendation)
-		fmt.Printf("  Confidence:     %.0f%%\n", float64(r.Analysis.Confidence)*100)
+		fmt.Fprintf(w, "  Recommendation: %s\n", r.Analysis.Recommendation)
+		fmt.Fprintf(w, "  Confidence:     %.0f%%\n", float64(r.Analysis.Confidence)*100)
+		if sc := r.Analysis.Scenarios; sc != nil {
+			fmt.Fprintf(w, "  Scenarios:      Bear %s · Base %s · Bull %s (band %s – %s)\n",
+				utils.FormatINR(sc.Bear), utils.FormatINR(sc.Base), utils.FormatINR(sc.Bull),
+				utils.FormatINR(sc.BandLow), utils.FormatINR(sc.BandHigh))
+		}
 		if len(r.Analysis.Signals) > 0 {
-			fmt.Println("  Signals:")
+			fmt.Fprintln(w, "  Signals:")
 			for _, sig := range r.Analysis.Signals {
-				fmt.Printf("    [%s] %s — %s (%.0f%%)\n",
+				fmt.Fprintf(w, "    [%s] %s — %s (%.0f%%)\n",
 					sig.Source, sig.Type, sig.Reason, float64(sig.Confidence)*100)
 			}
 		}
 	}
 
 	if r.ToolCalls > 0 {
-		fmt.Printf("\n  Tool Calls: %d\n", r.ToolCalls)
+		fmt.Fprintf(w, "\n  Tool Calls: %d\n", r.ToolCalls)
 	}
 	if r.Tokens > 0 {
-		fmt.Printf("  Tokens:     %d\n", r.Tokens)
+		fmt.Fprintf(w, "  Tokens:     %d\n", r.Tokens)
+	}
+	if v < verbosityVerbose {
+		return
+	}
+
+	var trace []llm.ToolCall
+	for _, m := range r.Messages {
+		trace = append(trace, m.ToolCalls...)
+	}
+	if len(trace) > 0 {
+		fmt.Fprintln(w, "  Tool Trace:")
+		for i, tc := range trace {
+			args := string(tc.Arguments)
+			if len(args) > 80 {
+				args = args[:77] + "..."
+			}
+			fmt.Fprintf(w, "    %2d. %s %s\n", i+1, tc.Name, args)
+		}
 	}
 }
 
-func printBacktestResult(r *models.BacktestResult) {
+// agentTemplateData is what an --output-template renders. The result's
+// fields are promoted, so a template reads {{.AgentName}}, {{.Content}},
+// {{.Analysis.Recommendation}}, or ranges over {{.AgentResults}}; the
+// analyzed ticker and the run's metadata ({{.Metadata.TotalTokens}}) sit
+// alongside them.
+type agentTemplateData struct {
+	*agent.AgentResult
+	Ticker   string
+	Metadata agent.RunMetadata
+}
+
+// outputTemplateFuncs are the helpers available to output templates.
+var outputTemplateFuncs = template.FuncMap{
+	"pct":   func(c models.Confidence) string { return fmt.Sprintf("%.0f%%", float64(c)*100) },
+	"inr":   utils.FormatINR,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+	"round": func(d time.Duration) time.Duration { return d.Round(time.Millisecond) },
+}
+
+// loadOutputTemplate parses the Go template file at path.
+func loadOutputTemplate(path string) (*template.Template, error) {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(outputTemplateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("output template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderAgentTemplate executes tmpl with data and writes the result to w.
+func renderAgentTemplate(w io.Writer, tmpl *template.Template, data agentTemplateData) error {
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("output template: %w", err)
+	}
+	return nil
+}
+
+// printBacktestResult prints the metrics of r and, with chart, its equity
+// curve sized to the terminal.
+func printBacktestResult(r *models.BacktestResult, chart bool) {
 	fmt.Println("═══════════════════════════════════════")
 	fmt.Println("  Backtest Results")
 	fmt.Println("═══════════════════════════════════════")
@@ -950,6 +2393,7 @@ func printBacktestResult(r *models.BacktestResult) {
 	fmt.Printf("  Ticker:         %s\n", r.Ticker)
 	fmt.Printf("  Period:         %s to %s\n",
 		r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
+	fmt.Printf("  Warm-up Bars:   %d\n", r.WarmupBars)
 	fmt.Printf("  Initial:        %s\n", utils.FormatINR(r.InitialCapital))
 	fmt.Printf("  Final:          %s\n", utils.FormatINR(r.FinalCapital))
 	fmt.Println()
@@ -962,10 +2406,166 @@ func printBacktestResult(r *models.BacktestResult) {
 	fmt.Printf("  Total Trades:   %d\n", r.TotalTrades)
 	fmt.Printf("  Win Rate:       %s\n", utils.FormatPct(r.WinRate))
 	fmt.Printf("  Profit Factor:  %.2f\n", r.ProfitFactor)
+	if chart && len(r.EquityCurve) > 0 {
+		fmt.Println()
+		fmt.Println("  Equity Curve (░ ≥5% ▒ ≥10% ▓ ≥20% drawdown)")
+		for _, line := range strings.Split(equityChart(r.EquityCurve, terminalWidth()-4), "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
 	fmt.Println("═══════════════════════════════════════")
 }
 
-func printFinanceQLResult(val financeql.Value, asJSON bool) {
+// terminalWidth returns the width of the terminal on stdout, else the
+// width in $COLUMNS, else 80.
+func terminalWidth() int {
+	if n := stdoutWidth(); n > 0 {
+		return n
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return 80
+}
+
+// equityChart renders curve as a width-column sparkline over a row of
+// drawdown markers. Each column covers an equal slice of the curve and
+// shows its last value and the deepest drawdown from the running peak.
+func equityChart(curve []models.EquityPoint, width int) string {
+	if len(curve) == 0 || width <= 0 {
+		return ""
+	}
+	drawdown := make([]float64, len(curve))
+	peak := curve[0].Value
+	for i, p := range curve {
+		peak = math.Max(peak, p.Value)
+		if peak > 0 {
+			drawdown[i] = (peak - p.Value) / peak * 100
+		}
+	}
+
+	values := make([]float64, width)
+	shade := make([]rune, width)
+	for col := 0; col < width; col++ {
+		lo, hi := col*len(curve)/width, (col+1)*len(curve)/width
+		if hi <= lo {
+			hi = lo + 1 // more columns than points: repeat the point
+		}
+		worst := 0.0
+		for _, d := range drawdown[lo:hi] {
+			worst = math.Max(worst, d)
+		}
+		values[col] = curve[hi-1].Value
+		shade[col] = drawdownMarker(worst)
+	}
+	return sparkline(values) + "\n" + string(shade)
+}
+
+// drawdownMarker shades a drawdown percentage.
+func drawdownMarker(pct float64) rune {
+	switch {
+	case pct >= 20:
+		return '▓'
+	case pct >= 10:
+		return '▒'
+	case pct >= 5:
+		return '░'
+	default:
+		return ' '
+	}
+}
+
+// compareBacktestRuns loads two results saved with backtest --json and
+// prints the change in each metric from the first to the second.
+func compareBacktestRuns(pathA, pathB string, asJSON bool) error {
+	a, err := loadBacktestResult(pathA)
+	if err != nil {
+		return err
+	}
+	b, err := loadBacktestResult(pathB)
+	if err != nil {
+		return err
+	}
+
+	diff := backtest.DiffResults(*a, *b)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diff)
+	}
+	printBacktestDiff(os.Stdout, diff, pathA, pathB)
+	return nil
+}
+
+func loadBacktestResult(path string) (*models.BacktestResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read backtest result: %w", err)
+	}
+	var r models.BacktestResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("invalid backtest result %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// printBacktestDiff prints a metric-by-metric comparison table.
+func printBacktestDiff(w io.Writer, diff backtest.ResultComparison, nameA, nameB string) {
+	markers := map[backtest.Change]string{
+		backtest.Improved:  "▲ improved",
+		backtest.Regressed: "▼ regressed",
+		backtest.Changed:   "• changed",
+		backtest.Unchanged: "",
+	}
+	fmt.Fprintln(w, "═══════════════════════════════════════════════════════════════════")
+	fmt.Fprintf(w, "  A: %s (%s)\n", filepath.Base(nameA), diff.A)
+	fmt.Fprintf(w, "  B: %s (%s)\n", filepath.Base(nameB), diff.B)
+	fmt.Fprintln(w, "═══════════════════════════════════════════════════════════════════")
+	fmt.Fprintf(w, "  %-16s %12s %12s %12s  %s\n", "Metric", "A", "B", "Δ", "")
+	for _, m := range diff.Metrics {
+		fmt.Fprintf(w, "  %-16s %12.2f %12.2f %+12.2f  %s\n", m.Metric, m.A, m.B, m.Delta, markers[m.Change])
+	}
+	fmt.Fprintln(w, "═══════════════════════════════════════════════════════════════════")
+	fmt.Fprintf(w, "  %d improved, %d regressed\n", len(diff.Improved()), len(diff.Regressed()))
+}
+
+// printBacktestSplit prints in-sample and out-of-sample metrics side by side.
+func printBacktestSplit(s *backtest.SplitResult) {
+	is, oos := s.InSample, s.OutOfSample
+	fmt.Println("═══════════════════════════════════════════════════════")
+	fmt.Printf("  Backtest Results — %s on %s\n", is.StrategyName, is.Ticker)
+	fmt.Println("═══════════════════════════════════════════════════════")
+	fmt.Printf("  %-16s %-18s %-18s\n", "", fmt.Sprintf("In-Sample (%.0f%%)", s.Ratio*100),
+		fmt.Sprintf("Out-of-Sample (%.0f%%)", (1-s.Ratio)*100))
+	fmt.Printf("  %-16s %-18s %-18s\n", "From", is.From.Format("2006-01-02"), oos.From.Format("2006-01-02"))
+	fmt.Printf("  %-16s %-18s %-18s\n", "To", is.To.Format("2006-01-02"), oos.To.Format("2006-01-02"))
+	fmt.Printf("  %-16s %-18s %-18s\n", "Total Return", utils.FormatPct(is.TotalReturnPct), utils.FormatPct(oos.TotalReturnPct))
+	fmt.Printf("  %-16s %-18s %-18s\n", "CAGR", utils.FormatPct(is.CAGR), utils.FormatPct(oos.CAGR))
+	fmt.Printf("  %-16s %-18.2f %-18.2f\n", "Sharpe Ratio", is.SharpeRatio, oos.SharpeRatio)
+	fmt.Printf("  %-16s %-18.2f %-18.2f\n", "Sortino Ratio", is.SortinoRatio, oos.SortinoRatio)
+	fmt.Printf("  %-16s %-18s %-18s\n", "Max Drawdown", utils.FormatPct(is.MaxDrawdownPct), utils.FormatPct(oos.MaxDrawdownPct))
+	fmt.Printf("  %-16s %-18d %-18d\n", "Total Trades", is.TotalTrades, oos.TotalTrades)
+	fmt.Printf("  %-16s %-18s %-18s\n", "Win Rate", utils.FormatPct(is.WinRate), utils.FormatPct(oos.WinRate))
+	fmt.Println("═══════════════════════════════════════════════════════")
+	if s.Degraded {
+		fmt.Printf("  ⚠️  Out-of-sample Sharpe (%.2f) is well below in-sample (%.2f) — the strategy may be overfit.\n",
+			oos.SharpeRatio, is.SharpeRatio)
+	}
+}
+
+// printExplanations lists what each function of an explained query computed.
+func printExplanations(explanations []financeql.Explanation) {
+	if len(explanations) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("  Explanation:")
+	for i, e := range explanations {
+		fmt.Printf("    %d. %s\n", i+1, e.Description)
+	}
+}
+
+func printFinanceQLResult(val financeql.Value, precision int, asJSON bool) {
 	if asJSON {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -990,7 +2590,7 @@ func printFinanceQLResult(val financeql.Value, asJSON bool) {
 
 	switch val.Type {
 	case financeql.TypeScalar:
-		fmt.Printf("  Result: %.4f\n", val.Scalar)
+		fmt.Printf("  Result: %s\n", financeql.FormatScalar(val.Scalar, precision))
 	case financeql.TypeString:
 		fmt.Printf("  Result: %s\n", val.Str)
 	case financeql.TypeBool:
@@ -1003,7 +2603,7 @@ func printFinanceQLResult(val financeql.Value, asJSON bool) {
 			fmt.Printf("  ... showing last 10 of %d\n", len(val.Vector))
 		}
 		for _, pt := range val.Vector[start:] {
-			fmt.Printf("    %s  %.4f\n", pt.Time.Format("2006-01-02"), pt.Value)
+			fmt.Printf("    %s  %s\n", pt.Time.Format("2006-01-02"), financeql.FormatScalar(pt.Value, precision))
 		}
 	case financeql.TypeMatrix:
 		for name, pts := range val.Matrix {
@@ -1013,7 +2613,7 @@ func printFinanceQLResult(val financeql.Value, asJSON bool) {
 				start = len(pts) - 5
 			}
 			for _, pt := range pts[start:] {
-				fmt.Printf("    %s  %.4f\n", pt.Time.Format("2006-01-02"), pt.Value)
+				fmt.Printf("    %s  %s\n", pt.Time.Format("2006-01-02"), financeql.FormatScalar(pt.Value, precision))
 			}
 		}
 	case financeql.TypeTable:
@@ -1056,15 +2656,90 @@ func buildCompositeAnalysis(ticker string, result *agent.AgentResult) *models.Co
 		Ticker:    ticker,
 		Summary:   result.Content,
 		Timestamp: time.Now(),
-		Timeframe: "medium-term",
+		Timeframe: agent.CompositeTimeframe,
 	}
 	if result.Analysis != nil {
 		ca.Recommendation = result.Analysis.Recommendation
 		ca.Confidence = result.Analysis.Confidence
+		ca.Scenarios = result.Analysis.Scenarios
 	}
 	return ca
 }
 
+// analysisBundleVersion is the format version written to analysis bundles.
+const analysisBundleVersion = 1
+
+// analysisBundle is a self-contained export of one analysis: the composite
+// view the report renders, every agent result with its conversation and
+// tool calls, and the market data snapshot taken alongside it.
+type analysisBundle struct {
+	Version    int                       `json:"version"`
+	Ticker     string                    `json:"ticker"`
+	CreatedAt  time.Time                 `json:"created_at"`
+	Composite  *models.CompositeAnalysis `json:"composite"`
+	Result     *agent.AgentResult        `json:"result"`
+	Metadata   agent.RunMetadata         `json:"metadata"`
+	Data       *datasource.StockBundle   `json:"data,omitempty"`
+	DataErrors map[string]string         `json:"data_errors,omitempty"` // snapshot fields that could not be fetched
+}
+
+// newAnalysisBundle assembles a bundle from a finished analysis and the
+// data snapshot taken for it, which may be nil.
+func newAnalysisBundle(ticker string, result *agent.AgentResult, md agent.RunMetadata, snap *datasource.StockBundle) *analysisBundle {
+	b := &analysisBundle{
+		Version:   analysisBundleVersion,
+		Ticker:    ticker,
+		CreatedAt: utils.NowIST(),
+		Composite: buildCompositeAnalysis(ticker, result),
+		Result:    result,
+		Metadata:  md,
+		Data:      snap,
+	}
+	if snap != nil {
+		if snap.Profile != nil {
+			b.Composite.StockProfile = *snap.Profile
+		}
+		if b.Composite.StockProfile.Quote == nil {
+			b.Composite.StockProfile.Quote = snap.Quote
+		}
+		for field, err := range snap.Errors {
+			if b.DataErrors == nil {
+				b.DataErrors = make(map[string]string)
+			}
+			b.DataErrors[field] = err.Error()
+		}
+	}
+	return b
+}
+
+// writeAnalysisBundle writes b to path as indented JSON.
+func writeAnalysisBundle(path string, b *analysisBundle) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// readAnalysisBundle reads a bundle written by writeAnalysisBundle.
+func readAnalysisBundle(path string) (*analysisBundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b analysisBundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse bundle %s: %w", path, err)
+	}
+	if b.Version < 1 || b.Version > analysisBundleVersion {
+		return nil, fmt.Errorf("bundle %s: unsupported version %d", path, b.Version)
+	}
+	if b.Composite == nil {
+		return nil, fmt.Errorf("bundle %s: no composite analysis", path)
+	}
+	return &b, nil
+}
+
 func findStrategy(name string) backtest.Strategy {
 	name = strings.ToLower(strings.ReplaceAll(name, "-", "_"))
 	for _, s := range backtest.BuiltinStrategies() {
@@ -1084,13 +2759,47 @@ func listStrategyNames() []string {
 	return names
 }
 
+// strategyInfo describes a built-in strategy and its parameter schema.
+type strategyInfo struct {
+	ID     string                   `json:"id"`
+	Name   string                   `json:"name"`
+	Params []backtest.StrategyParam `json:"params"`
+}
+
+// printStrategyList prints every built-in strategy with its parameters.
+func printStrategyList(asJSON bool) error {
+	var infos []strategyInfo
+	for _, s := range backtest.BuiltinStrategies() {
+		infos = append(infos, strategyInfo{
+			ID:     strings.ToLower(strings.ReplaceAll(s.Name(), " ", "_")),
+			Name:   s.Name(),
+			Params: s.Params(),
+		})
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(infos)
+	}
+
+	for _, info := range infos {
+		fmt.Printf("  %s (%s)\n", info.ID, info.Name)
+		for _, p := range info.Params {
+			fmt.Printf("    --param %-12s %-5s default %-6g range [%g, %g]  %s\n",
+				p.Name+"=", p.Type, p.Default, p.Min, p.Max, p.Description)
+		}
+	}
+	return nil
+}
+
 func printWatchlist(ctx context.Context, agg *datasource.Aggregator, tickers []string) {
 	fmt.Printf("\033[2J\033[H") // clear screen
-	fmt.Printf("  %-15s %12s %10s %10s   %s\n", "TICKER", "PRICE", "CHANGE", "CHANGE%", "TIME")
-	fmt.Println("  " + strings.Repeat("─", 65))
+	fmt.Printf("  %-15s %12s %10s %10s %7s %7s %7s %8s   %s\n", "TICKER", "PRICE", "CHANGE", "CHANGE%", "GAP%", "RANGE%", "VWAP%", "VOLUME", "TIME")
+	fmt.Println("  " + strings.Repeat("─", 98))
 
 	for _, t := range tickers {
-		quote, err := agg.YFinance().GetQuote(ctx, t)
+		quote, err := agg.GetQuote(ctx, t)
 		if err != nil {
 			fmt.Printf("  %-15s  ⚠ error: %s\n", t, err)
 			continue
@@ -1099,19 +2808,108 @@ func printWatchlist(ctx context.Context, agg *datasource.Aggregator, tickers []s
 		if quote.Change >= 0 {
 			changeStr = "+" + changeStr
 		}
-		fmt.Printf("  %-15s %12s %10s %10s   %s\n",
+		pct, trend := utils.FormatPctTrend(quote.ChangePct, true)
+		rangePct, _ := utils.FormatPctTrend(quote.DayRangePct(), false)
+		vwapDist := "—"
+		if vwap := sessionVWAP(ctx, agg, t); vwap > 0 {
+			vwapDist = utils.FormatPct(quote.DistanceFromVWAP(vwap))
+		}
+		fmt.Printf("  %-15s %12s %10s %s %7s %7s %7s %8s   %s\n",
 			t,
 			utils.FormatINR(quote.LastPrice),
 			changeStr,
-			utils.FormatPct(quote.ChangePct),
+			colorize(fmt.Sprintf("%10s", pct), trend),
+			utils.FormatPct(quote.GapPct()),
+			rangePct,
+			vwapDist,
+			utils.FormatVolume(float64(quote.Volume)),
 			quote.Timestamp.Format("15:04:05"),
 		)
 	}
 	fmt.Printf("\n  Last updated: %s\n", utils.FormatDateTimeIST(utils.NowIST()))
 }
 
-func runChatREPL(orch *agent.Orchestrator) error {
-	var history []llm.Message
+// sessionVWAP returns the VWAP of ticker's 5-minute candles in today's
+// session, or 0 before the open or when they cannot be fetched.
+func sessionVWAP(ctx context.Context, agg *datasource.Aggregator, ticker string) float64 {
+	now := utils.NowIST()
+	open := utils.MarketOpenTime(now)
+	if now.Before(open) {
+		return 0
+	}
+	candles, err := agg.FetchHistoricalData(ctx, ticker, open, now, models.Timeframe5Min)
+	if err != nil {
+		return 0
+	}
+	return vwapSince(candles, open)
+}
+
+// vwapSince returns the volume-weighted average typical price of the
+// candles at or after from, or 0 when they carry no volume.
+func vwapSince(candles []models.OHLCV, from time.Time) float64 {
+	var value, volume float64
+	for _, c := range candles {
+		if c.Timestamp.Before(from) {
+			continue
+		}
+		value += (c.High + c.Low + c.Close) / 3 * float64(c.Volume)
+		volume += float64(c.Volume)
+	}
+	if volume <= 0 {
+		return 0
+	}
+	return value / volume
+}
+
+// printHeatmap prints cells as a grid with ANSI background colors
+// scaled by the sign and size of each cell's daily change.
+func printHeatmap(cells []models.HeatmapCell, cols int) {
+	if cols < 1 {
+		cols = 5
+	}
+	for i, c := range cells {
+		label := fmt.Sprintf(" %-11s%7s ", c.Symbol, utils.FormatPct(c.ChangePct))
+		fmt.Printf("%s%s\033[0m ", heatmapColor(c.ChangePct), label)
+		if (i+1)%cols == 0 || i == len(cells)-1 {
+			fmt.Println()
+		}
+	}
+	fmt.Printf("\n  Last updated: %s\n", utils.FormatDateTimeIST(utils.NowIST()))
+}
+
+// heatmapColor returns the ANSI color escape for a daily change percentage.
+func heatmapColor(changePct float64) string {
+	switch {
+	case changePct >= 2:
+		return "\033[30;102m" // bright green
+	case changePct > 0:
+		return "\033[30;42m" // green
+	case changePct <= -2:
+		return "\033[97;101m" // bright red
+	case changePct < 0:
+		return "\033[97;41m" // red
+	default:
+		return "\033[30;47m" // unchanged
+	}
+}
+
+// colorize wraps s in the ANSI foreground color for trend: green for up,
+// red for down, and no color for flat.
+func colorize(s string, trend utils.Trend) string {
+	switch trend {
+	case utils.TrendUp:
+		return "\033[32m" + s + "\033[0m"
+	case utils.TrendDown:
+		return "\033[31m" + s + "\033[0m"
+	default:
+		return s
+	}
+}
+
+// runChatREPL reads chat input from stdin until quit or EOF, seeding the
+// conversation with history, and returns the history at exit.
+func runChatREPL(orch *agent.Orchestrator, timeout time.Duration, history []llm.Message) ([]llm.Message, error) {
+	history = trimChatHistory(history)
 	scanner := bufio.NewScanner(os.Stdin)
 
 	for {
@@ -1125,33 +2923,422 @@ func runChatREPL(orch *agent.Orchestrator) error {
 		}
 		if input == "quit" || input == "exit" {
 			fmt.Println("👋 Goodbye!")
-			return nil
+			break
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-		result, err := orch.Chat(ctx, input, history)
+		if strings.HasPrefix(input, "/") {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			err := dispatchChatCommand(ctx, orch, input)
+			cancel()
+			if err != nil {
+				fmt.Printf("❌ Error: %s\n", err)
+			}
+			fmt.Println()
+			continue
+		}
+
+		// Ctrl+C stops the reply instead of the REPL
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		fmt.Print("\n🤖 ")
+		reply, err := streamChatReply(ctx, orch, input, history, os.Stdout)
+		stop()
 		cancel()
+		if errors.Is(err, context.Canceled) {
+			fmt.Print("\n⏹ Interrupted\n\n")
+			continue
+		}
 		if err != nil {
-			fmt.Printf("❌ Error: %s\n\n", err)
+			fmt.Printf("\n❌ Error: %s\n\n", err)
 			continue
 		}
-
-		fmt.Printf("\n🤖 %s:\n%s\n\n", result.AgentName, result.Content)
+		fmt.Print("\n\n")
 
 		// Append to history
 		history = append(history, llm.UserMessage(input))
-		history = append(history, llm.AssistantMessage(result.Content))
+		history = append(history, llm.AssistantMessage(reply))
+
+		history = trimChatHistory(history)
+	}
+	return history, nil
+}
+
+// streamChatReply sends input to orch and writes the reply to w as it
+// streams, returning the reply for the chat history. Text written before
+// a tool call is shown but left out of the reply.
+func streamChatReply(ctx context.Context, orch *agent.Orchestrator, input string, history []llm.Message, w io.Writer) (string, error) {
+	chunks, err := orch.ChatStream(ctx, input, history)
+	if err != nil {
+		return "", err
+	}
+
+	var reply strings.Builder
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			return "", chunk.Err
+		}
+		if chunk.FinishReason == llm.FinishToolCalls && !chunk.Done {
+			if reply.Len() > 0 {
+				fmt.Fprint(w, "\n")
+			}
+			reply.Reset()
+			continue
+		}
+		fmt.Fprint(w, chunk.Content)
+		reply.WriteString(chunk.Content)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return reply.String(), nil
+}
+
+// maxChatHistory is how many messages of chat history are kept as context.
+const maxChatHistory = 20
+
+// trimChatHistory keeps the last maxChatHistory messages of history, also
+// dropping tool results at the front whose tool call was cut off.
+func trimChatHistory(history []llm.Message) []llm.Message {
+	if len(history) > maxChatHistory {
+		history = history[len(history)-maxChatHistory:]
+	}
+	for len(history) > 0 && history[0].Role == llm.RoleTool {
+		history = history[1:]
+	}
+	return history
+}
+
+// chatTranscriptVersion is the format version written to chat transcripts.
+const chatTranscriptVersion = 1
+
+// chatTranscript is a saved chat conversation, as written by chat --save.
+type chatTranscript struct {
+	Version  int           `json:"version"`
+	SavedAt  time.Time     `json:"saved_at"`
+	Messages []llm.Message `json:"messages"`
+}
+
+// writeChatTranscript writes messages to path as an indented JSON transcript.
+func writeChatTranscript(path string, messages []llm.Message) error {
+	if messages == nil {
+		messages = []llm.Message{}
+	}
+	data, err := json.MarshalIndent(chatTranscript{
+		Version:  chatTranscriptVersion,
+		SavedAt:  utils.NowIST(),
+		Messages: messages,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// readChatTranscript reads a transcript written by writeChatTranscript.
+func readChatTranscript(path string) (*chatTranscript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t chatTranscript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("parse transcript %s: %w", path, err)
+	}
+	if t.Version < 1 || t.Version > chatTranscriptVersion {
+		return nil, fmt.Errorf("transcript %s: unsupported version %d", path, t.Version)
+	}
+	for i, m := range t.Messages {
+		switch m.Role {
+		case llm.RoleSystem, llm.RoleUser, llm.RoleAssistant, llm.RoleTool:
+		default:
+			return nil, fmt.Errorf("transcript %s: message %d has unknown role %q", path, i, m.Role)
+		}
+		// MarshalIndent re-indents tool arguments; restore their compact form.
+		for j, tc := range m.ToolCalls {
+			var buf bytes.Buffer
+			if json.Compact(&buf, tc.Arguments) == nil {
+				t.Messages[i].ToolCalls[j].Arguments = buf.Bytes()
+			}
+		}
+	}
+	return &t, nil
+}
+
+// chatCommand is a slash command available in the chat REPL.
+type chatCommand struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, orch *agent.Orchestrator, args []string) error
+}
+
+// chatCommands maps a slash-command name (without "/") to its handler.
+// Populated in init because /help refers back to the table.
+var chatCommands map[string]chatCommand
+
+func init() {
+	chatCommands = map[string]chatCommand{
+		"analyze": {
+			usage: "/analyze TICKER",
+			help:  "run a full analysis",
+			run:   chatAnalyze,
+		},
+		"backtest": {
+			usage: "/backtest STRATEGY TICKER [FROM]",
+			help:  "backtest a built-in strategy (FROM is YYYY-MM-DD, default " + defaultBacktestFrom + ")",
+			run:   chatBacktest,
+		},
+		"chart": {
+			usage: "/chart TICKER [DAYS]",
+			help:  "plot recent closing prices (default 90 days)",
+			run:   chatChart,
+		},
+		"help": {
+			usage: "/help",
+			help:  "list chat commands",
+			run:   chatHelp,
+		},
+	}
+}
+
+// dispatchChatCommand runs a slash command such as "/analyze TCS".
+func dispatchChatCommand(ctx context.Context, orch *agent.Orchestrator, input string) error {
+	fields := strings.Fields(strings.TrimPrefix(input, "/"))
+	if len(fields) == 0 {
+		return chatHelp(ctx, orch, nil)
+	}
+	c, ok := chatCommands[strings.ToLower(fields[0])]
+	if !ok {
+		return fmt.Errorf("unknown command /%s; type /help for a list", fields[0])
+	}
+	return c.run(ctx, orch, fields[1:])
+}
+
+func chatAnalyze(ctx context.Context, orch *agent.Orchestrator, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s", chatCommands["analyze"].usage)
+	}
+	result, err := runAnalysis(ctx, orch, utils.NormalizeTicker(args[0]), true)
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+	printAgentResult(result)
+	return nil
+}
+
+func chatBacktest(ctx context.Context, _ *agent.Orchestrator, args []string) error {
+	if len(args) < 2 || len(args) > 3 {
+		return fmt.Errorf("usage: %s", chatCommands["backtest"].usage)
+	}
+	strategy := findStrategy(args[0])
+	if strategy == nil {
+		return fmt.Errorf("unknown strategy %q; available: %s", args[0], strings.Join(listStrategyNames(), ", "))
+	}
+	fromStr := defaultBacktestFrom
+	if len(args) == 3 {
+		fromStr = args[2]
+	}
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		return fmt.Errorf("invalid from date: %w", err)
+	}
+	from, to, err := backtest.TradingRange(from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	result, err := runBacktest(ctx, newHistoryAggregator(false, false), strategy, utils.NormalizeTicker(args[1]), from, to, models.Timeframe1Day, 0)
+	if err != nil {
+		return err
+	}
+	printBacktestResult(result, false)
+	return nil
+}
 
-		// Keep history manageable
-		if len(history) > 20 {
-			history = history[len(history)-20:]
+func chatChart(ctx context.Context, _ *agent.Orchestrator, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: %s", chatCommands["chart"].usage)
+	}
+	days := 90
+	if len(args) == 2 {
+		d, err := strconv.Atoi(args[1])
+		if err != nil || d < 2 {
+			return fmt.Errorf("invalid days %q", args[1])
 		}
+		days = d
+	}
+
+	ticker := utils.NormalizeTicker(args[0])
+	to := time.Now()
+	bars, err := datasource.NewAggregator().FetchHistoricalData(ctx, ticker, to.AddDate(0, 0, -days), to, models.Timeframe1Day)
+	if err != nil {
+		return fmt.Errorf("failed to fetch data: %w", err)
+	}
+	if len(bars) == 0 {
+		return fmt.Errorf("no price data for %s", ticker)
+	}
+
+	closes := make([]float64, len(bars))
+	low, high := bars[0].Close, bars[0].Close
+	for i, b := range bars {
+		closes[i] = b.Close
+		low = math.Min(low, b.Close)
+		high = math.Max(high, b.Close)
+	}
+	first, last := closes[0], closes[len(closes)-1]
+	change := "n/a" // no base to measure from
+	if first != 0 {
+		change = utils.FormatPct((last - first) / first * 100)
+	}
+	fmt.Printf("📈 %s — last %d days\n", ticker, days)
+	fmt.Printf("   %s\n", sparkline(closes))
+	fmt.Printf("   Low %s  High %s  Last %s (%s)\n",
+		utils.FormatINR(low), utils.FormatINR(high), utils.FormatINR(last), change)
+	return nil
+}
+
+func chatHelp(_ context.Context, _ *agent.Orchestrator, _ []string) error {
+	names := make([]string, 0, len(chatCommands))
+	for name := range chatCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Commands:")
+	for _, name := range names {
+		c := chatCommands[name]
+		fmt.Printf("  %-34s %s\n", c.usage, c.help)
 	}
+	fmt.Println("Anything else is sent to the AI agent.")
 	return nil
 }
 
+// sparkline renders values as a single line of block characters.
+func sparkline(values []float64) string {
+	ticks := []rune("▁▂▃▄▅▆▇█")
+	if len(values) == 0 {
+		return ""
+	}
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low = math.Min(low, v)
+		high = math.Max(high, v)
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		idx := 0
+		if high > low {
+			idx = int((v - low) / (high - low) * float64(len(ticks)-1))
+		}
+		sb.WriteRune(ticks[idx])
+	}
+	return sb.String()
+}
+
+// orderPreview is what the trade REPL shows before placing an order.
+type orderPreview struct {
+	Order          models.OrderRequest
+	Value          float64 // order value in ₹
+	Charges        broker.BrokerageCharges
+	Margin         float64 // margin blocked by the order
+	ExposureBefore float64 // gross portfolio exposure in ₹
+	ExposureAfter  float64
+	ExposurePct    float64 // ExposureAfter as % of capital
+	Risk           *broker.RiskReport
+}
+
+// buildOrderPreview estimates the charges, margin, and post-trade exposure
+// of req, and runs the risk manager's pre-trade checks without placing it.
+func buildOrderPreview(ctx context.Context, rm *broker.RiskManager, req models.OrderRequest) (*orderPreview, error) {
+	p := &orderPreview{Order: req, Value: req.Price * float64(req.Quantity)}
+
+	buyPrice, sellPrice := req.Price, 0.0
+	if req.Side == models.Sell {
+		buyPrice, sellPrice = 0, req.Price
+	}
+	p.Charges = broker.CalculateBrokerage(buyPrice, sellPrice, req.Quantity, req.Product)
+	p.Charges.NetPnL = 0 // single leg, no round-trip P&L
+
+	margin, err := broker.RequiredMargin(req, req.Price)
+	if err != nil {
+		return nil, err
+	}
+	p.Margin = margin
+
+	// Gross exposure, and the signed quantity already held in this ticker.
+	held := 0
+	positions, err := rm.GetPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pos := range positions {
+		p.ExposureBefore += math.Abs(float64(pos.Quantity)) * markPrice(pos.LTP, pos.AvgPrice)
+		if pos.Ticker == req.Ticker {
+			held += pos.Quantity
+		}
+	}
+	holdings, err := rm.GetHoldings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range holdings {
+		p.ExposureBefore += float64(h.Quantity) * markPrice(h.LTP, h.AvgPrice)
+		if h.Ticker == req.Ticker {
+			held += h.Quantity
+		}
+	}
+	after := held + req.Quantity
+	if req.Side == models.Sell {
+		after = held - req.Quantity
+	}
+	p.ExposureAfter = p.ExposureBefore + (math.Abs(float64(after))-math.Abs(float64(held)))*req.Price
+	if capital := rm.Config().InitialCapital; capital > 0 {
+		p.ExposurePct = p.ExposureAfter / capital * 100
+	}
+
+	p.Risk, err = rm.Assess(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// markPrice returns ltp, or avg when no price has been set yet.
+func markPrice(ltp, avg float64) float64 {
+	if ltp > 0 {
+		return ltp
+	}
+	return avg
+}
+
+func printOrderPreview(w io.Writer, p *orderPreview) {
+	o := p.Order
+	fmt.Fprintf(w, "📋 %s %d %s @ %s (%s %s)\n", o.Side, o.Quantity, o.Ticker, utils.FormatINR(o.Price), o.OrderType, o.Product)
+	fmt.Fprintf(w, "   Order value:     %s\n", utils.FormatINR(p.Value))
+	fmt.Fprintf(w, "   Est. charges:    %s (STT %s, stamp %s, exch %s, GST %s)\n",
+		utils.FormatINR(p.Charges.Total), utils.FormatINR(p.Charges.STT), utils.FormatINR(p.Charges.StampDuty),
+		utils.FormatINR(p.Charges.ExchangeTxn), utils.FormatINR(p.Charges.GST))
+	fmt.Fprintf(w, "   Margin required: %s\n", utils.FormatINR(p.Margin))
+	fmt.Fprintf(w, "   Exposure:        %s → %s (%.2f%% of capital)\n",
+		utils.FormatINR(p.ExposureBefore), utils.FormatINR(p.ExposureAfter), p.ExposurePct)
+	if p.Risk == nil {
+		return
+	}
+	if p.Risk.Passed {
+		fmt.Fprintf(w, "   Risk checks:     ✅ passed (order is %.2f%% of capital)\n", p.Risk.OrderValuePct)
+	} else {
+		fmt.Fprintln(w, "   Risk checks:     ⛔ failed — the order will be blocked")
+	}
+	for _, v := range p.Risk.Violations {
+		fmt.Fprintf(w, "     ⛔ %s\n", v)
+	}
+	for _, warn := range p.Risk.Warnings {
+		fmt.Fprintf(w, "     ⚠️  %s\n", warn)
+	}
+}
+
 func runTradeREPL(ctx context.Context, rm *broker.RiskManager) error {
 	scanner := bufio.NewScanner(os.Stdin)
+	autoConfirm := false
 
 	for {
 		fmt.Print("trade> ")
@@ -1229,6 +3416,24 @@ func runTradeREPL(ctx context.Context, rm *broker.RiskManager) error {
 				Product:   models.CNC,
 			}
 
+			if !autoConfirm {
+				preview, err := buildOrderPreview(ctx, rm, req)
+				if err != nil {
+					fmt.Printf("❌ Preview failed: %v\n", err)
+					continue
+				}
+				printOrderPreview(os.Stdout, preview)
+				fmt.Print("Type 'yes' to place the order: ")
+				if !scanner.Scan() {
+					return nil
+				}
+				if strings.ToLower(strings.TrimSpace(scanner.Text())) != "yes" {
+					fmt.Println("Order not placed.")
+					fmt.Println()
+					continue
+				}
+			}
+
 			resp, err := rm.PlaceOrder(ctx, req)
 			if err != nil {
 				fmt.Printf("❌ Order failed: %v\n", err)
@@ -1236,6 +3441,18 @@ func runTradeREPL(ctx context.Context, rm *broker.RiskManager) error {
 			}
 			fmt.Printf("✅ Order placed: %s (%s)\n", resp.OrderID, resp.Status)
 
+		case "autoconfirm":
+			if len(parts) < 2 || (parts[1] != "on" && parts[1] != "off") {
+				fmt.Println("Usage: autoconfirm on|off")
+				continue
+			}
+			autoConfirm = parts[1] == "on"
+			if autoConfirm {
+				fmt.Println("Orders will be placed without a preview.")
+			} else {
+				fmt.Println("Orders will be previewed and need confirmation.")
+			}
+
 		case "cancel":
 			if len(parts) < 2 {
 				fmt.Println("Usage: cancel ORDER_ID")
@@ -1248,7 +3465,7 @@ func runTradeREPL(ctx context.Context, rm *broker.RiskManager) error {
 			fmt.Println("✅ Order cancelled")
 
 		default:
-			fmt.Println("Unknown command. Available: buy, sell, positions, orders, margins, cancel, quit")
+			fmt.Println("Unknown command. Available: buy, sell, positions, orders, margins, cancel, autoconfirm, quit")
 		}
 		fmt.Println()
 	}
@@ -0,0 +1,630 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/seenimoa/openseai/internal/agent"
+	"github.com/seenimoa/openseai/internal/broker"
+	"github.com/seenimoa/openseai/internal/config"
+	"github.com/seenimoa/openseai/internal/datasource"
+	"github.com/seenimoa/openseai/internal/financeql"
+	"github.com/seenimoa/openseai/internal/llm"
+	"github.com/seenimoa/openseai/internal/report"
+	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
+)
+
+func TestTimeoutFlagAppliedToAnalyzeContext(t *testing.T) {
+	orig := runAnalysis
+	defer func() { runAnalysis = orig }()
+	defer rootCmd.PersistentFlags().Set("timeout", "0")
+
+	var deadline time.Time
+	var hasDeadline bool
+	runAnalysis = func(ctx context.Context, _ *agent.Orchestrator, ticker string, _ bool) (*agent.AgentResult, error) {
+		deadline, hasDeadline = ctx.Deadline()
+		return &agent.AgentResult{AgentName: "test", Content: ticker}, nil
+	}
+
+	start := time.Now()
+	rootCmd.SetArgs([]string{"analyze", "RELIANCE", "--json", "--timeout", "7m"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze failed: %v", err)
+	}
+
+	if !hasDeadline {
+		t.Fatal("expected orchestrator context to carry a deadline")
+	}
+	got := deadline.Sub(start)
+	if got < 7*time.Minute-5*time.Second || got > 7*time.Minute+5*time.Second {
+		t.Errorf("expected ~7m deadline, got %s", got)
+	}
+}
+
+func TestConfigInitWritesLoadableConfig(t *testing.T) {
+	defer configInitCmd.Flags().Set("force", "false")
+	path := filepath.Join(t.TempDir(), "config", "config.yaml")
+
+	rootCmd.SetArgs([]string{"config", "init", "--path", path})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("config init failed: %v", err)
+	}
+	loaded, err := config.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("generated config does not load: %v", err)
+	}
+	defaults, err := config.Defaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Trading != defaults.Trading || loaded.Broker.Provider != defaults.Broker.Provider {
+		t.Errorf("loaded config %+v differs from defaults %+v", loaded.Trading, defaults.Trading)
+	}
+
+	// An existing file is kept unless --force is given.
+	if err := os.WriteFile(path, []byte("llm:\n  model: custom\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rootCmd.SetArgs([]string{"config", "init", "--path", path})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected config init to refuse to overwrite an existing file")
+	}
+	rootCmd.SetArgs([]string{"config", "init", "--path", path, "--force"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("config init --force failed: %v", err)
+	}
+	if loaded, err = config.LoadFromFile(path); err != nil || loaded.LLM.Model != defaults.LLM.Model {
+		t.Errorf("expected --force to restore the default model, got %v (err %v)", loaded, err)
+	}
+}
+
+func TestReadTickerFile(t *testing.T) {
+	input := `# Nifty heavyweights
+reliance
+  tcs  
+
+INFY   # IT
+# HDFCBANK
+RELIANCE
+`
+	got, err := readTickerFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readTickerFile: %v", err)
+	}
+	want := []string{"RELIANCE", "TCS", "INFY"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+// recordingQuotes serves a fixed quote for any ticker and records which
+// tickers were asked for.
+type recordingQuotes struct {
+	seen []string
+}
+
+func (r *recordingQuotes) GetQuote(_ context.Context, ticker string) (*models.Quote, error) {
+	r.seen = append(r.seen, ticker)
+	return &models.Quote{Ticker: ticker, LastPrice: 100}, nil
+}
+
+func (r *recordingQuotes) GetStockProfile(_ context.Context, ticker string) (*models.StockProfile, error) {
+	return &models.StockProfile{}, nil
+}
+
+func TestUniverseFileDrivesScreener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchlist.txt")
+	if err := os.WriteFile(path, []byte("# my picks\nTCS\ninfy.ns\n\nITC\nTCS\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tickers, err := loadUniverse(path, nil)
+	if err != nil {
+		t.Fatalf("loadUniverse: %v", err)
+	}
+
+	quotes := &recordingQuotes{}
+	ec := financeql.NewEvalContext(context.Background(), nil)
+	ec.Fundamentals = quotes
+	ec.Universe = tickers
+	v, err := financeql.EvalQuery(ec, `screener(price(*) > 0)`)
+	if err != nil {
+		t.Fatalf("screener: %v", err)
+	}
+
+	want := []string{"TCS", "INFY", "ITC"}
+	if !reflect.DeepEqual(quotes.seen, want) {
+		t.Errorf("screened %v, want %v", quotes.seen, want)
+	}
+	if len(v.Table) != len(want) {
+		t.Errorf("screener returned %d rows, want %d", len(v.Table), len(want))
+	}
+}
+
+func TestLoadUniverseNamed(t *testing.T) {
+	named := map[string][]string{"it": {"tcs", "WIPRO"}, "empty": {}}
+	got, err := loadUniverse("IT", named)
+	if err != nil || !reflect.DeepEqual(got, []string{"TCS", "WIPRO"}) {
+		t.Errorf("named universe: got %v, %v", got, err)
+	}
+	if _, err := loadUniverse("empty", named); err == nil {
+		t.Error("expected an error for an empty universe")
+	}
+	if _, err := loadUniverse(filepath.Join(t.TempDir(), "missing"), named); err == nil || !strings.Contains(err.Error(), "unknown universe") {
+		t.Errorf("missing universe: got %v", err)
+	}
+}
+
+func TestDownloadUniverseIndex(t *testing.T) {
+	agg := datasource.NewAggregator()
+	got, err := downloadUniverse(context.Background(), agg, "nifty50")
+	if err != nil || len(got) != 50 {
+		t.Errorf("nifty50: got %d tickers, %v", len(got), err)
+	}
+	if _, err := downloadUniverse(context.Background(), agg, "no-such-universe"); err == nil || !strings.Contains(err.Error(), "unknown universe") {
+		t.Errorf("unknown universe: got %v", err)
+	}
+	if bar := progressBar(1, 4, 8); bar != "[██░░░░░░]" {
+		t.Errorf("progressBar = %q", bar)
+	}
+}
+
+func TestRunBatchAnalysisContinuesOnFailure(t *testing.T) {
+	orig := runAnalysis
+	defer func() { runAnalysis = orig }()
+
+	runAnalysis = func(_ context.Context, _ *agent.Orchestrator, ticker string, deep bool) (*agent.AgentResult, error) {
+		if ticker == "INFY" {
+			return nil, errors.New("provider unavailable")
+		}
+		return &agent.AgentResult{
+			AgentName: "test",
+			Content:   ticker + " looks fine",
+			Analysis:  &models.AnalysisResult{Recommendation: models.Hold, Confidence: 0.6},
+		}, nil
+	}
+
+	dir := t.TempDir()
+	results, err := runBatchAnalysis(context.Background(), nil, []string{"TCS", "INFY", "RELIANCE"}, true, dir, 2)
+	if err != nil {
+		t.Fatalf("runBatchAnalysis: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for _, r := range results {
+		switch r.Ticker {
+		case "INFY":
+			if r.Error == "" || r.Report != "" {
+				t.Errorf("expected INFY to fail without a report, got %+v", r)
+			}
+		default:
+			if r.Error != "" {
+				t.Errorf("%s: unexpected error %q", r.Ticker, r.Error)
+			}
+			if _, err := os.Stat(filepath.Join(dir, r.Ticker+"_report.html")); err != nil {
+				t.Errorf("%s: report not written: %v", r.Ticker, err)
+			}
+		}
+	}
+	if results[0].Ticker != "TCS" || results[2].Ticker != "RELIANCE" {
+		t.Errorf("expected results in input order, got %v, %v", results[0].Ticker, results[2].Ticker)
+	}
+
+	var buf bytes.Buffer
+	printBatchSummary(&buf, results)
+	out := buf.String()
+	if !strings.Contains(out, "2 analyzed, 1 failed") || !strings.Contains(out, "provider unavailable") {
+		t.Errorf("unexpected summary:\n%s", out)
+	}
+}
+
+func TestCommandTimeoutDefault(t *testing.T) {
+	if got := commandTimeout(analyzeCmd, 5*time.Minute); got != 5*time.Minute {
+		t.Errorf("expected default 5m when --timeout unset, got %s", got)
+	}
+}
+
+func TestDispatchChatCommandAnalyze(t *testing.T) {
+	orig := runAnalysis
+	defer func() { runAnalysis = orig }()
+
+	var gotTicker string
+	var gotDeep bool
+	runAnalysis = func(_ context.Context, _ *agent.Orchestrator, ticker string, deep bool) (*agent.AgentResult, error) {
+		gotTicker, gotDeep = ticker, deep
+		return &agent.AgentResult{AgentName: "test", Content: ticker}, nil
+	}
+
+	if err := dispatchChatCommand(context.Background(), nil, "/analyze tcs"); err != nil {
+		t.Fatalf("dispatch failed: %v", err)
+	}
+	if gotTicker != "TCS" || !gotDeep {
+		t.Errorf("expected full analysis of TCS, got ticker=%q deep=%v", gotTicker, gotDeep)
+	}
+}
+
+func TestDispatchChatCommandErrors(t *testing.T) {
+	if err := dispatchChatCommand(context.Background(), nil, "/unknown"); err == nil {
+		t.Error("expected error for unknown command")
+	}
+	if err := dispatchChatCommand(context.Background(), nil, "/analyze"); err == nil {
+		t.Error("expected usage error for /analyze without ticker")
+	}
+	if err := dispatchChatCommand(context.Background(), nil, "/backtest no_such_strategy TCS"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{1, 2, 3, 4, 5, 6, 7, 8}); got != "▁▂▃▄▅▆▇█" {
+		t.Errorf("sparkline = %q", got)
+	}
+	if got := sparkline([]float64{5, 5}); got != "▁▁" {
+		t.Errorf("flat sparkline = %q", got)
+	}
+}
+
+func TestEquityChart(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var curve []models.EquityPoint
+	for i, v := range []float64{100, 110, 120, 100, 90, 105, 130, 125, 140, 150} {
+		curve = append(curve, models.EquityPoint{Date: start.AddDate(0, 0, i), Value: v})
+	}
+
+	for _, width := range []int{5, 10, 40} {
+		lines := strings.Split(equityChart(curve, width), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("width %d: expected 2 lines, got %d", width, len(lines))
+		}
+		for _, line := range lines {
+			if n := utf8.RuneCountInString(line); n != width {
+				t.Errorf("width %d: line %q has %d columns", width, line, n)
+			}
+		}
+		if !strings.ContainsRune(lines[1], '▒') {
+			t.Errorf("width %d: expected a ≥10%% drawdown marker, got %q", width, lines[1])
+		}
+	}
+	if got := equityChart(nil, 40); got != "" {
+		t.Errorf("empty curve chart = %q", got)
+	}
+}
+
+func testAgentResult() *agent.AgentResult {
+	return &agent.AgentResult{
+		AgentName: "technical",
+		Role:      "Technical Analyst",
+		Content:   "TCS is in an uptrend.",
+		ToolCalls: 1,
+		Tokens:    1234,
+		Analysis: &models.AnalysisResult{
+			Recommendation: models.ModerateBuy,
+			Confidence:     0.8,
+			Signals: []models.Signal{
+				{Source: "RSI", Type: models.SignalBuy, Reason: "oversold bounce", Confidence: 0.7},
+			},
+		},
+		Messages: []llm.Message{
+			{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{
+				{ID: "1", Name: "get_technicals", Arguments: json.RawMessage(`{"ticker":"TCS"}`)},
+			}},
+		},
+	}
+}
+
+func TestRenderAgentResultQuiet(t *testing.T) {
+	var buf bytes.Buffer
+	renderAgentResult(&buf, testAgentResult(), verbosityQuiet)
+	out := buf.String()
+
+	if !strings.Contains(out, "Recommendation: BUY") || !strings.Contains(out, "80%") {
+		t.Errorf("quiet output missing recommendation/confidence:\n%s", out)
+	}
+	for _, unwanted := range []string{"Signals", "oversold bounce", "uptrend", "Tool"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("quiet output should omit %q:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestRenderAgentResultVerbose(t *testing.T) {
+	var buf bytes.Buffer
+	renderAgentResult(&buf, testAgentResult(), verbosityVerbose)
+	out := buf.String()
+
+	for _, want := range []string{"Tool Trace", `get_technicals {"ticker":"TCS"}`, "Tokens:     1234", "oversold bounce"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("verbose output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	renderAgentResult(&buf, testAgentResult(), verbosityNormal)
+	if strings.Contains(buf.String(), "Tool Trace") {
+		t.Error("normal output should not include the tool trace")
+	}
+	if !strings.Contains(buf.String(), "Tokens:     1234") {
+		t.Errorf("normal output missing the token count:\n%s", buf.String())
+	}
+}
+
+func TestRenderAgentTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "brief.tmpl")
+	tmpl := "{{.AgentName}}: {{.Analysis.Recommendation}}{{with .Analysis}} ({{pct .Confidence}}){{end}} for {{.Ticker}}\n"
+	if err := os.WriteFile(path, []byte(tmpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := loadOutputTemplate(path)
+	if err != nil {
+		t.Fatalf("loadOutputTemplate: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := renderAgentTemplate(&buf, parsed, agentTemplateData{AgentResult: testAgentResult(), Ticker: "TCS"}); err != nil {
+		t.Fatalf("renderAgentTemplate: %v", err)
+	}
+	if got, want := buf.String(), "technical: BUY (80%) for TCS\n"; got != want {
+		t.Errorf("rendered %q, want %q", got, want)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.tmpl")
+	if err := os.WriteFile(bad, []byte("{{.AgentName"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadOutputTemplate(bad); err == nil {
+		t.Error("expected a parse error for a malformed template")
+	}
+}
+
+func TestStatusReportJSON(t *testing.T) {
+	origCfg, origVersion := cfg, version
+	defer func() { cfg, version = origCfg, origVersion }()
+	cfg = &config.Config{}
+	cfg.LLM.OpenAIKey = "sk-test-1234567890"
+	version = "1.2.3"
+
+	report := buildStatusReport([]datasource.SourceHealth{{Name: "Yahoo Finance", OK: true}})
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		Version string `json:"version"`
+		APIKeys []struct {
+			Name  string `json:"name"`
+			IsSet *bool  `json:"is_set"`
+		} `json:"api_keys"`
+		Sources []datasource.SourceHealth `json:"sources"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Version != "1.2.3" {
+		t.Errorf("version = %q, want 1.2.3", out.Version)
+	}
+	if len(out.APIKeys) == 0 {
+		t.Fatal("expected api_keys entries")
+	}
+	var set int
+	for _, k := range out.APIKeys {
+		if k.IsSet == nil {
+			t.Fatalf("key %q missing is_set", k.Name)
+		}
+		if *k.IsSet {
+			set++
+		}
+	}
+	if set != 1 {
+		t.Errorf("expected exactly 1 key set, got %d", set)
+	}
+	if len(out.Sources) != 1 || !out.Sources[0].OK {
+		t.Errorf("unexpected sources: %+v", out.Sources)
+	}
+}
+
+func TestBuildOrderPreview(t *testing.T) {
+	ctx := context.Background()
+	pb := broker.NewPaperBroker(&broker.PaperBrokerConfig{InitialCapital: 1_000_000, SlippagePct: 0.0001})
+	if _, err := pb.PlaceOrder(ctx, models.OrderRequest{
+		Ticker: "INFY", Exchange: "NSE", Side: models.Buy, OrderType: models.Limit,
+		Product: models.CNC, Quantity: 10, Price: 1500,
+	}); err != nil {
+		t.Fatalf("seed holding: %v", err)
+	}
+	pb.SetPrice("INFY", 1500)
+	rm := broker.NewRiskManager(pb, broker.DefaultRiskConfig())
+
+	req := models.OrderRequest{
+		Ticker: "RELIANCE", Exchange: "NSE", Side: models.Buy, OrderType: models.Limit,
+		Product: models.CNC, Quantity: 10, Price: 2850,
+	}
+	p, err := buildOrderPreview(ctx, rm, req)
+	if err != nil {
+		t.Fatalf("buildOrderPreview: %v", err)
+	}
+
+	if p.Value != 28500 {
+		t.Errorf("value = %.2f, want 28500", p.Value)
+	}
+	want := broker.CalculateBrokerage(2850, 0, 10, models.CNC)
+	if math.Abs(p.Charges.Total-want.Total) > 1e-9 || p.Charges.STT != 28.5 {
+		t.Errorf("charges = %+v, want total %.4f with STT 28.50", p.Charges, want.Total)
+	}
+	if p.Margin != 28500 {
+		t.Errorf("margin = %.2f, want 28500", p.Margin)
+	}
+	if p.ExposureBefore != 15000 || p.ExposureAfter != 43500 {
+		t.Errorf("exposure = %.2f → %.2f, want 15000 → 43500", p.ExposureBefore, p.ExposureAfter)
+	}
+	if math.Abs(p.ExposurePct-4.35) > 1e-9 {
+		t.Errorf("exposure pct = %.4f, want 4.35", p.ExposurePct)
+	}
+	if p.Risk == nil || !p.Risk.Passed {
+		t.Errorf("expected passing risk report, got %+v", p.Risk)
+	}
+
+	// Selling the held INFY shares reduces exposure.
+	p, err = buildOrderPreview(ctx, rm, models.OrderRequest{
+		Ticker: "INFY", Exchange: "NSE", Side: models.Sell, OrderType: models.Limit,
+		Product: models.CNC, Quantity: 10, Price: 1500,
+	})
+	if err != nil {
+		t.Fatalf("buildOrderPreview sell: %v", err)
+	}
+	if p.ExposureAfter != 0 || p.Margin != 0 {
+		t.Errorf("sell: exposure after %.2f, margin %.2f; want 0, 0", p.ExposureAfter, p.Margin)
+	}
+}
+
+func TestAnalysisBundleRoundTrip(t *testing.T) {
+	result := &agent.AgentResult{
+		ID:        "TCS/orchestrator",
+		AgentName: "orchestrator",
+		Content:   "TCS looks fairly valued; accumulate on dips.",
+		Analysis:  &models.AnalysisResult{Recommendation: models.ModerateBuy, Confidence: 0.7},
+		AgentResults: []*agent.AgentResult{{
+			ID:        "TCS/fundamental",
+			AgentName: "fundamental_analyst",
+			Content:   "PE 28, ROE 45%",
+			ToolCalls: 1,
+			Messages: []llm.Message{
+				{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{ID: "c1", Name: "get_financials", Arguments: json.RawMessage(`{"ticker":"TCS"}`)}}},
+				{Role: llm.RoleTool, ToolCallID: "c1", Name: "get_financials", Content: `{"pe":28}`},
+			},
+		}},
+	}
+	snap := &datasource.StockBundle{
+		Ticker: "TCS",
+		Quote:  &models.Quote{Ticker: "TCS", LastPrice: 3850.5},
+		Errors: map[string]error{datasource.BundleFinancials: errors.New("screener down")},
+	}
+	md := agent.RunMetadata{Mode: agent.ModeMulti, TotalTokens: 1234}
+
+	path := filepath.Join(t.TempDir(), "tcs.json")
+	if err := writeAnalysisBundle(path, newAnalysisBundle("TCS", result, md, snap)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	b, err := readAnalysisBundle(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if b.Ticker != "TCS" || b.Version != analysisBundleVersion {
+		t.Errorf("ticker/version = %q/%d", b.Ticker, b.Version)
+	}
+	if b.Composite.Recommendation != models.ModerateBuy || b.Composite.Summary != result.Content {
+		t.Errorf("composite = %+v", b.Composite)
+	}
+	if q := b.Composite.StockProfile.Quote; q == nil || q.LastPrice != 3850.5 {
+		t.Errorf("composite quote = %+v", q)
+	}
+	if len(b.Result.AgentResults) != 1 {
+		t.Fatalf("agent results = %d, want 1", len(b.Result.AgentResults))
+	}
+	msgs := b.Result.AgentResults[0].Messages
+	if len(msgs) != 2 || len(msgs[0].ToolCalls) != 1 || msgs[0].ToolCalls[0].Name != "get_financials" || msgs[1].Content != `{"pe":28}` {
+		t.Errorf("tool trace not preserved: %+v", msgs)
+	}
+	if b.Data == nil || b.Data.Quote.LastPrice != 3850.5 {
+		t.Errorf("data snapshot = %+v", b.Data)
+	}
+	if b.DataErrors[datasource.BundleFinancials] != "screener down" {
+		t.Errorf("data errors = %v", b.DataErrors)
+	}
+	if b.Metadata.TotalTokens != 1234 {
+		t.Errorf("metadata = %+v", b.Metadata)
+	}
+
+	html, err := report.GenerateHTML(b.Composite, report.DefaultReportConfig())
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(html, "TCS") || !strings.Contains(html, "accumulate on dips") {
+		t.Error("report rendered from bundle is missing the ticker or summary")
+	}
+}
+
+func TestChatTranscriptRoundTrip(t *testing.T) {
+	history := []llm.Message{
+		llm.UserMessage("What is RELIANCE trading at?"),
+		llm.AssistantToolCallMessage([]llm.ToolCall{{ID: "c1", Name: "get_quote", Arguments: json.RawMessage(`{"ticker":"RELIANCE"}`)}}),
+		llm.ToolResultMessage("c1", "get_quote", `{"ltp":2847.5}`),
+		llm.AssistantMessage("RELIANCE is at ₹2847.50."),
+	}
+
+	path := filepath.Join(t.TempDir(), "chat.json")
+	if err := writeChatTranscript(path, history); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	tr, err := readChatTranscript(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if tr.Version != chatTranscriptVersion {
+		t.Errorf("version = %d", tr.Version)
+	}
+	if !reflect.DeepEqual(tr.Messages, history) {
+		t.Errorf("messages not preserved:\ngot  %+v\nwant %+v", tr.Messages, history)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"version":1,"messages":[{"role":"robot","content":"hi"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readChatTranscript(path); err == nil {
+		t.Error("expected an error for an unknown role")
+	}
+}
+
+func TestTrimChatHistoryDropsOrphanedToolResults(t *testing.T) {
+	var history []llm.Message
+	for i := 0; i < maxChatHistory-1; i++ {
+		history = append(history, llm.UserMessage(fmt.Sprint(i)))
+	}
+	history = append([]llm.Message{
+		llm.AssistantToolCallMessage([]llm.ToolCall{{ID: "c1", Name: "get_quote"}}),
+		llm.ToolResultMessage("c1", "get_quote", "{}"),
+	}, history...)
+
+	got := trimChatHistory(history)
+	if len(got) != maxChatHistory-1 || got[0].Role != llm.RoleUser {
+		t.Errorf("trimmed to %d messages starting with %q", len(got), got[0].Role)
+	}
+}
+
+func TestReadAnalysisBundleRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "ticker": "TCS", "composite": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readAnalysisBundle(path); err == nil {
+		t.Error("expected an error for an unsupported bundle version")
+	}
+}
+
+func TestVWAPSince(t *testing.T) {
+	open := time.Date(2026, 1, 5, 9, 15, 0, 0, utils.IST)
+	candles := []models.OHLCV{
+		{Timestamp: open.Add(-18 * time.Hour), High: 900, Low: 900, Close: 900, Volume: 1_000}, // yesterday
+		{Timestamp: open, High: 102, Low: 98, Close: 100, Volume: 100},
+		{Timestamp: open.Add(5 * time.Minute), High: 112, Low: 108, Close: 110, Volume: 300},
+	}
+	if got := vwapSince(candles, open); math.Abs(got-107.5) > 1e-9 {
+		t.Errorf("vwapSince = %v, want 107.5", got)
+	}
+	if got := vwapSince(candles[:1], open); got != 0 {
+		t.Errorf("vwapSince with no session candles = %v, want 0", got)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !unix
+
+package main
+
+// stdoutWidth reports 0 where the terminal size cannot be queried.
+func stdoutWidth() int { return 0 }
@@ -42,8 +42,11 @@ type Agent interface {
 
 // AgentResult holds the output from an agent's processing.
 type AgentResult struct {
+	ID         string         `json:"id,omitempty"` // stable per ticker and agent, e.g. "TCS/fundamental"
 	AgentName  string         `json:"agent_name"`
 	Role       string         `json:"role"`
+	Model      string         `json:"model,omitempty"`    // model that produced Content
+	Provider   string         `json:"provider,omitempty"` // LLM provider used
 	Content    string         `json:"content"`     // LLM-generated analysis text
 	Analysis   *models.AnalysisResult `json:"analysis,omitempty"`
 	ToolCalls  int            `json:"tool_calls"`  // number of tool calls made
@@ -51,6 +54,14 @@ type AgentResult struct {
 	Duration   time.Duration  `json:"duration"`
 	Messages   []llm.Message  `json:"messages"`    // full conversation history
 	Error      string         `json:"error,omitempty"`
+	Degraded   bool           `json:"degraded,omitempty"` // best-effort result after a multi-agent failure
+	Retried    bool           `json:"retried,omitempty"`  // re-prompted after an empty or low-confidence answer
+	Debate     *DebateRound   `json:"debate,omitempty"`   // CIO adjudication of conflicting agents
+	Usage      *UsageTotals   `json:"usage,omitempty"`    // whole-run usage, set on orchestrator results
+
+	// AgentResults holds the specialist results of a multi-agent run, in
+	// canonical order: fundamental, technical, sentiment, fno, risk.
+	AgentResults []*AgentResult `json:"agent_results,omitempty"`
 }
 
 // ── Memory ──
@@ -175,6 +186,7 @@ type BaseAgent struct {
 	memory       *Memory
 	opts         *llm.ChatOptions
 	maxToolIter  int // max tool-call loop iterations
+	maxParallel  int // max concurrent tool calls; 0 is unlimited
 }
 
 // BaseAgentConfig configures a BaseAgent.
@@ -187,6 +199,13 @@ type BaseAgentConfig struct {
 	ChatOptions  *llm.ChatOptions
 	MemorySize   int
 	MaxToolIter  int
+
+	// MaxParallelTools bounds how many tool calls from one LLM turn run
+	// concurrently. Zero means no limit.
+	MaxParallelTools int
+
+	// DisabledTools lists tool names that are never registered with the agent.
+	DisabledTools []string
 }
 
 // NewBaseAgent creates a new BaseAgent from the given configuration.
@@ -198,22 +217,54 @@ func NewBaseAgent(cfg BaseAgentConfig) *BaseAgent {
 		cfg.MemorySize = 50
 	}
 
-	reg := llm.NewToolRegistry()
-	for _, t := range cfg.Tools {
-		reg.Register(t)
-	}
-
-	return &BaseAgent{
+	a := &BaseAgent{
 		name:         cfg.Name,
 		role:         cfg.Role,
 		systemPrompt: cfg.SystemPrompt,
-		tools:        cfg.Tools,
-		registry:     reg,
 		provider:     cfg.Provider,
 		memory:       NewMemory(cfg.MemorySize),
 		opts:         cfg.ChatOptions,
 		maxToolIter:  cfg.MaxToolIter,
+		maxParallel:  cfg.MaxParallelTools,
 	}
+	a.setTools(cfg.Tools, cfg.DisabledTools)
+	return a
+}
+
+// setTools installs tools on the agent, dropping any whose name is in disabled.
+// The registry is rebuilt so a disabled tool can never be dispatched.
+func (a *BaseAgent) setTools(tools []llm.Tool, disabled []string) {
+	skip := make(map[string]bool, len(disabled))
+	for _, name := range disabled {
+		skip[name] = true
+	}
+
+	reg := llm.NewToolRegistry()
+	kept := make([]llm.Tool, 0, len(tools))
+	for _, t := range tools {
+		if skip[t.Name] {
+			continue
+		}
+		reg.Register(t)
+		kept = append(kept, t)
+	}
+
+	a.tools = kept
+	a.registry = reg
+}
+
+// DisableTools removes the named tools from the agent's tool list and registry.
+func (a *BaseAgent) DisableTools(names ...string) {
+	if len(names) == 0 {
+		return
+	}
+	a.setTools(a.tools, names)
+}
+
+// SetMaxParallelTools bounds how many tool calls run concurrently. Zero
+// means no limit.
+func (a *BaseAgent) SetMaxParallelTools(n int) {
+	a.maxParallel = n
 }
 
 // Name returns the agent's identifier.
@@ -251,12 +302,24 @@ func (a *BaseAgent) ProcessWithMessages(ctx context.Context, task string, histor
 	}
 	messages = append(messages, llm.UserMessage(task))
 
-	// Run tool-calling loop
-	resp, finalMsgs, err := llm.RunToolLoop(ctx, a.provider, a.registry, messages, a.tools, a.opts, a.maxToolIter)
+	// Run tool-calling loop, streaming text when the run has a sink and
+	// metering every round trip when it tracks usage
+	var provider llm.LLMProvider = a.provider
+	if sink := streamSinkFrom(ctx); sink != nil {
+		sink(ctx, a.name, AgentStreamEvent{})
+		provider = &streamingProvider{LLMProvider: provider, emit: func(ctx context.Context, ev AgentStreamEvent) {
+			sink(ctx, a.name, ev)
+		}}
+	}
+	if u := usageFrom(ctx); u != nil {
+		provider = &meteredProvider{LLMProvider: provider, usage: u}
+	}
+	resp, finalMsgs, err := llm.RunToolLoop(ctx, provider, a.registry, messages, a.tools, a.opts, a.maxToolIter, a.maxParallel)
 	if err != nil {
 		return &AgentResult{
 			AgentName: a.name,
 			Role:      a.role,
+			Provider:  a.provider.Name(),
 			Error:     err.Error(),
 			Duration:  time.Since(start),
 			Messages:  finalMsgs,
@@ -272,9 +335,15 @@ func (a *BaseAgent) ProcessWithMessages(ctx context.Context, task string, histor
 	// Store in memory
 	a.memory.AddAll(finalMsgs[1:]) // skip system prompt from memory
 
+	providerName := resp.Provider
+	if providerName == "" {
+		providerName = a.provider.Name()
+	}
 	result := &AgentResult{
 		AgentName: a.name,
 		Role:      a.role,
+		Model:     resp.Model,
+		Provider:  providerName,
 		Content:   resp.Content,
 		ToolCalls: toolCallCount,
 		Tokens:    resp.Usage.TotalTokens,
@@ -326,6 +395,7 @@ func ParseAnalysisResult(content string, defaults models.AnalysisResult) *models
 		result.Summary = content
 	}
 
+	result.Score = models.ScoreSignals(result.Signals)
 	result.Timestamp = time.Now()
 	return &result
 }
@@ -3,11 +3,15 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/seenimoa/openseai/internal/agent/prompts"
 	"github.com/seenimoa/openseai/internal/datasource"
@@ -364,6 +368,28 @@ func TestBaseAgentProcessWithTools(t *testing.T) {
 	}
 }
 
+func TestBaseAgentDisabledTools(t *testing.T) {
+	noop := func(ctx context.Context, args json.RawMessage) (string, error) { return "", nil }
+	agent := NewBaseAgent(BaseAgentConfig{
+		Name:     "test-agent",
+		Role:     "Test",
+		Provider: simpleProvider("ok"),
+		Tools: []llm.Tool{
+			{Name: "get_price", Handler: noop},
+			{Name: "place_order", Handler: noop},
+		},
+		DisabledTools: []string{"place_order"},
+	})
+
+	tools := agent.Tools()
+	if len(tools) != 1 || tools[0].Name != "get_price" {
+		t.Fatalf("expected only get_price, got %+v", tools)
+	}
+	if _, ok := agent.registry.Get("place_order"); ok {
+		t.Fatal("disabled tool must not be registered")
+	}
+}
+
 func TestBaseAgentProcessError(t *testing.T) {
 	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
 		return nil, fmt.Errorf("provider error")
@@ -413,6 +439,68 @@ func TestBaseAgentMemoryGrowth(t *testing.T) {
 // ParseAnalysisResult Tests
 // ════════════════════════════════════════════════════════════════════
 
+func TestReadAnalysisStreamEarlyParse(t *testing.T) {
+	chunks := []string{
+		"Summary first. ",
+		`{"ticker": "TCS", "recommendation": "BUY", `,
+		`"confidence": 0.8, "summary": "Strong {margins}, \"cheap\""}`,
+		"\n\nDetailed reasoning follows: revenue grew ",
+		"12% with a {healthy} order book.",
+	}
+	ch := make(chan llm.StreamChunk) // unbuffered: each send waits for the reader
+	ready := make(chan *models.AnalysisResult, 1)
+	go func() {
+		defer close(ch)
+		for i, c := range chunks {
+			ch <- llm.StreamChunk{Content: c}
+			if i == 2 {
+				// The prose must not be sent until the early parse fired.
+				select {
+				case r := <-ready:
+					ready <- r
+				case <-time.After(time.Second):
+					return
+				}
+			}
+		}
+		ch <- llm.StreamChunk{Done: true}
+	}()
+
+	var calls int
+	content, final, err := ReadAnalysisStream(ch, models.AnalysisResult{AgentName: "technical"}, func(r *models.AnalysisResult) {
+		calls++
+		ready <- r
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected onReady once, got %d", calls)
+	}
+	early := <-ready
+	if early.Recommendation != "BUY" || early.Confidence != 0.8 || early.AgentName != "technical" {
+		t.Errorf("unexpected early result: %+v", early)
+	}
+	if early.Summary != `Strong {margins}, "cheap"` {
+		t.Errorf("braces and quotes inside strings should not end the object, got summary %q", early.Summary)
+	}
+	if content != strings.Join(chunks, "") {
+		t.Errorf("content not fully collected: %q", content)
+	}
+	if final == nil || final.Recommendation != "BUY" {
+		t.Errorf("unexpected final result: %+v", final)
+	}
+
+	// Objects without a recommendation are skipped.
+	s := NewAnalysisStream(models.AnalysisResult{})
+	if r := s.Write(`{"note": "x"} then `); r != nil {
+		t.Errorf("expected no result for an object without a recommendation, got %+v", r)
+	}
+	if r := s.Write(`{"recommendation": "SELL"}`); r == nil || r.Recommendation != "SELL" {
+		t.Errorf("expected SELL result, got %+v", r)
+	}
+}
+
 func TestParseAnalysisResultJSON(t *testing.T) {
 	content := `Based on the analysis, here's my recommendation:
 {"ticker": "RELIANCE", "recommendation": "BUY", "confidence": 0.85, "summary": "Strong fundamentals"}
@@ -589,7 +677,7 @@ func TestTechnicalAgentCreation(t *testing.T) {
 	}
 
 	toolNames := toolNameSet(agent.Tools())
-	for _, name := range []string{"get_historical_data", "compute_indicators", "generate_signals", "full_technical_analysis", "get_quote"} {
+	for _, name := range []string{"get_historical_data", "compute_indicators", "generate_signals", "full_technical_analysis", "rsi_divergence", "get_quote"} {
 		if !toolNames[name] {
 			t.Fatalf("missing tool: %s", name)
 		}
@@ -642,7 +730,7 @@ func TestRiskAgentCreation(t *testing.T) {
 }
 
 func TestExecutorAgentCreation(t *testing.T) {
-	agent := NewExecutorAgent(simpleProvider(""), nil)
+	agent := NewExecutorAgent(simpleProvider(""), nil, nil)
 
 	if agent.Name() != prompts.AgentExecutor {
 		t.Fatalf("Name: got %q", agent.Name())
@@ -767,6 +855,19 @@ func TestTechnicalHandleComputeIndicators(t *testing.T) {
 	}
 }
 
+func TestTechnicalHandleRSIDivergence(t *testing.T) {
+	agent := NewTechnicalAgent(simpleProvider(""), newMockSources(), nil)
+
+	args := json.RawMessage(`{"ticker": "TCS", "days": 100}`)
+	result, err := agent.handleRSIDivergence(context.Background(), args)
+	if err != nil {
+		t.Fatalf("handleRSIDivergence: %v", err)
+	}
+	if !strings.Contains(result, "TCS") || !strings.Contains(result, `"divergences"`) {
+		t.Fatalf("unexpected result: %s", result)
+	}
+}
+
 func TestTechnicalHandleGenerateSignals(t *testing.T) {
 	agent := NewTechnicalAgent(simpleProvider(""), newMockSources(), nil)
 
@@ -941,7 +1042,7 @@ func TestRiskHandlePortfolioExposureWarnings(t *testing.T) {
 // ════════════════════════════════════════════════════════════════════
 
 func TestExecutorHandleCreateProposal(t *testing.T) {
-	agent := NewExecutorAgent(simpleProvider(""), nil)
+	agent := NewExecutorAgent(simpleProvider(""), nil, nil)
 
 	args := json.RawMessage(`{
 		"ticker": "TCS",
@@ -968,7 +1069,7 @@ func TestExecutorHandleCreateProposal(t *testing.T) {
 }
 
 func TestExecutorHandleCreateProposalDefaultOrderType(t *testing.T) {
-	agent := NewExecutorAgent(simpleProvider(""), nil)
+	agent := NewExecutorAgent(simpleProvider(""), nil, nil)
 
 	args := json.RawMessage(`{"ticker": "TCS", "action": "BUY", "price": 3500}`)
 	result, err := agent.handleCreateProposal(context.Background(), args)
@@ -981,7 +1082,7 @@ func TestExecutorHandleCreateProposalDefaultOrderType(t *testing.T) {
 }
 
 func TestExecutorHandleEstimateBrokerage(t *testing.T) {
-	agent := NewExecutorAgent(simpleProvider(""), nil)
+	agent := NewExecutorAgent(simpleProvider(""), nil, nil)
 
 	args := json.RawMessage(`{"buy_price": 3500, "sell_price": 3800, "quantity": 100, "is_delivery": true}`)
 	result, err := agent.handleEstimateBrokerage(context.Background(), args)
@@ -994,7 +1095,7 @@ func TestExecutorHandleEstimateBrokerage(t *testing.T) {
 }
 
 func TestExecutorHandleValidateTrade(t *testing.T) {
-	agent := NewExecutorAgent(simpleProvider(""), nil)
+	agent := NewExecutorAgent(simpleProvider(""), nil, nil)
 
 	// Valid trade: position = ₹3,50,000 which is 3.5% of ₹1Cr
 	args := json.RawMessage(`{
@@ -1015,7 +1116,7 @@ func TestExecutorHandleValidateTrade(t *testing.T) {
 }
 
 func TestExecutorHandleValidateTradeReject(t *testing.T) {
-	agent := NewExecutorAgent(simpleProvider(""), nil)
+	agent := NewExecutorAgent(simpleProvider(""), nil, nil)
 
 	// Position = ₹3,50,000 which is 35% of ₹10L → exceeds 5%
 	args := json.RawMessage(`{
@@ -1038,6 +1139,61 @@ func TestExecutorHandleValidateTradeReject(t *testing.T) {
 	}
 }
 
+func TestExecutorValidateTradeLimits(t *testing.T) {
+	// Position = ₹3,50,000 which is 35% of ₹10L; the stop risks 1% of capital.
+	oversized := json.RawMessage(`{"ticker": "TCS", "action": "BUY", "price": 3500, "quantity": 100, "capital": 1000000, "stop_loss": 3400}`)
+	loose := NewExecutorAgent(simpleProvider(""), nil, &ExecutorLimits{MaxPositionPct: 40})
+	result, err := loose.handleValidateTrade(context.Background(), oversized)
+	if err != nil {
+		t.Fatalf("handleValidateTrade: %v", err)
+	}
+	if !strings.Contains(result, `"valid": true`) {
+		t.Fatalf("a 40%% position cap should allow the trade: %s", result)
+	}
+
+	// Position = 3.5% of ₹1Cr with a 1:3 reward:risk.
+	sized := json.RawMessage(`{"ticker": "TCS", "action": "BUY", "price": 3500, "quantity": 100, "capital": 10000000, "stop_loss": 3400, "target": 3800}`)
+	result, err = NewExecutorAgent(simpleProvider(""), nil, nil).handleValidateTrade(context.Background(), sized)
+	if err != nil || !strings.Contains(result, `"valid": true`) {
+		t.Fatalf("default limits should allow the trade: %s, %v", result, err)
+	}
+	strict := NewExecutorAgent(simpleProvider(""), nil, &ExecutorLimits{MinRiskReward: 4})
+	result, err = strict.handleValidateTrade(context.Background(), sized)
+	if err != nil {
+		t.Fatalf("handleValidateTrade: %v", err)
+	}
+	if !strings.Contains(result, `"valid": false`) || !strings.Contains(result, "RISK_REWARD") {
+		t.Fatalf("a 1:4 minimum should reject a 1:3 trade: %s", result)
+	}
+
+	// A target on the losing side is rejected, however far away it is.
+	wrongSide := map[string]string{
+		"BUY":  `{"ticker": "TCS", "action": "BUY", "price": 3500, "quantity": 100, "capital": 10000000, "stop_loss": 3400, "target": 3000}`,
+		"SELL": `{"ticker": "TCS", "action": "SELL", "price": 3500, "quantity": 100, "capital": 10000000, "stop_loss": 3600, "target": 4000}`,
+	}
+	for action, args := range wrongSide {
+		result, err = NewExecutorAgent(simpleProvider(""), nil, &ExecutorLimits{MinRiskReward: 2}).handleValidateTrade(context.Background(), json.RawMessage(args))
+		if err != nil {
+			t.Fatalf("handleValidateTrade: %v", err)
+		}
+		if !strings.Contains(result, `"valid": false`) || !strings.Contains(result, "TARGET") {
+			t.Errorf("a %s with its target on the losing side should be rejected: %s", action, result)
+		}
+	}
+	short := json.RawMessage(`{"ticker": "TCS", "action": "SELL", "price": 3500, "quantity": 100, "capital": 10000000, "stop_loss": 3600, "target": 3200}`)
+	result, err = NewExecutorAgent(simpleProvider(""), nil, &ExecutorLimits{MinRiskReward: 2}).handleValidateTrade(context.Background(), short)
+	if err != nil || !strings.Contains(result, `"valid": true`) {
+		t.Fatalf("a 1:3 SELL should pass a 1:2 minimum: %s, %v", result, err)
+	}
+
+	confident := NewExecutorAgent(simpleProvider(""), nil, &ExecutorLimits{MinConfidence: 0.7})
+	lowConf := json.RawMessage(`{"ticker": "TCS", "action": "BUY", "price": 3500, "quantity": 100, "capital": 10000000, "confidence": 0.5}`)
+	result, err = confident.handleValidateTrade(context.Background(), lowConf)
+	if err != nil || !strings.Contains(result, "CONFIDENCE") {
+		t.Fatalf("low confidence should be rejected: %s, %v", result, err)
+	}
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Reporter Agent Tool Handler Tests
 // ════════════════════════════════════════════════════════════════════
@@ -1125,6 +1281,473 @@ func TestExtractTicker(t *testing.T) {
 	}
 }
 
+func TestOrchestratorEstimateCost(t *testing.T) {
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:    simpleProvider("ok"),
+		Aggregator:  datasource.NewAggregator(),
+		ChatOptions: &llm.ChatOptions{Model: "gpt-4o"},
+	})
+
+	est := orch.EstimateCost(ModeMulti)
+	if !est.PricingKnown {
+		t.Fatal("expected gpt-4o pricing to be known")
+	}
+	if est.AgentRuns != 7 {
+		t.Errorf("expected 7 agent runs (5 specialists + CIO + reporter), got %d", est.AgentRuns)
+	}
+	// Min: 31000 prompt + 6300 completion; Max: 122000 prompt + 18500 completion.
+	if est.MinTokens != 37300 || est.MaxTokens != 140500 {
+		t.Errorf("unexpected token range %d–%d", est.MinTokens, est.MaxTokens)
+	}
+	// gpt-4o: $2.50 / $10.00 per million tokens.
+	if diff := est.MinCostUSD - 0.1405; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("expected min cost $0.1405, got $%.4f", est.MinCostUSD)
+	}
+	if diff := est.MaxCostUSD - 0.49; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("expected max cost $0.49, got $%.4f", est.MaxCostUSD)
+	}
+
+	single := orch.EstimateCost(ModeSingle)
+	if single.AgentRuns != 1 || single.MaxCostUSD >= est.MaxCostUSD {
+		t.Errorf("single-agent estimate should be one run and cheaper, got %+v", single)
+	}
+}
+
+func TestOrchestratorAgentsFollowSourceRoutes(t *testing.T) {
+	agg := datasource.NewAggregator()
+	agg.RegisterSource("mock", &mockDS{dsName: "mock"})
+	for _, c := range []datasource.DataCategory{datasource.CategoryQuotes, datasource.CategoryFundamentals} {
+		if err := agg.UseSource(c, "mock"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orch := NewOrchestrator(OrchestratorConfig{Provider: simpleProvider("ok"), Aggregator: agg})
+	ctx := context.Background()
+	args := json.RawMessage(`{"ticker":"TCS"}`)
+
+	quote, err := orch.technical.handleGetQuote(ctx, args)
+	if err != nil || !strings.Contains(quote, "3500") {
+		t.Errorf("expected the routed mock quote, got %q (err %v)", quote, err)
+	}
+	fin, err := orch.fundamental.handleGetFinancials(ctx, args)
+	if err != nil || !strings.Contains(fin, "Q1 2024") {
+		t.Errorf("expected the routed mock financials, got %q (err %v)", fin, err)
+	}
+}
+
+func TestOrchestratorDisabledTools(t *testing.T) {
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:      simpleProvider("ok"),
+		Aggregator:    datasource.NewAggregator(),
+		DisabledTools: []string{"create_trade_proposal"},
+	})
+
+	for _, a := range []Agent{orch.ExecutorAgent(), orch.singleAgent} {
+		for _, tool := range a.Tools() {
+			if tool.Name == "create_trade_proposal" {
+				t.Errorf("%s: disabled tool create_trade_proposal still present", a.Name())
+			}
+		}
+	}
+	if len(orch.ExecutorAgent().Tools()) == 0 {
+		t.Error("executor should keep its other tools")
+	}
+}
+
+func TestOrchestratorDefaultGuardrail(t *testing.T) {
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:   simpleProvider("TCS is a BUY with Guaranteed Returns of 20% this year."),
+		Aggregator: datasource.NewAggregator(),
+	})
+
+	result, err := orch.QuickQuery(context.Background(), "Analyze TCS")
+	if err != nil {
+		t.Fatalf("QuickQuery: %v", err)
+	}
+	if strings.Contains(strings.ToLower(result.Content), "guaranteed returns") {
+		t.Errorf("banned phrase not redacted: %q", result.Content)
+	}
+	if !strings.Contains(result.Content, "[redacted]") {
+		t.Errorf("expected redaction marker, got %q", result.Content)
+	}
+	if !strings.HasSuffix(result.Content, GuardrailDisclaimer) {
+		t.Errorf("expected disclaimer appended, got %q", result.Content)
+	}
+
+	// Content that already carries a disclaimer is left alone.
+	withDisclaimer := "HOLD.\n\n*Disclaimer: not advice.*"
+	if got := DefaultGuardrail(withDisclaimer); got != withDisclaimer {
+		t.Errorf("expected content unchanged, got %q", got)
+	}
+}
+
+func TestOrchestratorGuardrailCoversSpecialists(t *testing.T) {
+	orch := NewOrchestrator(OrchestratorConfig{Aggregator: datasource.NewAggregator()})
+	technical := orch.technical.SystemPrompt()
+	orch = NewOrchestrator(OrchestratorConfig{
+		Aggregator: datasource.NewAggregator(),
+		Provider: newMockProvider(func(_ context.Context, msgs []llm.Message, _ []llm.Tool, _ *llm.ChatOptions) (*llm.Response, error) {
+			content := `{"recommendation": "HOLD", "confidence": 0.6}`
+			if msgs[0].Content == technical {
+				content = `{"recommendation": "BUY", "confidence": 0.7, "summary": "A sure-shot breakout"}`
+			}
+			return &llm.Response{Content: content, FinishReason: llm.FinishStop}, nil
+		}),
+	})
+
+	result, err := orch.FullAnalysis(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+	found := false
+	for _, r := range result.AgentResults {
+		if bannedPhrases.MatchString(r.Content) {
+			t.Errorf("%s content not redacted: %q", r.AgentName, r.Content)
+		}
+		if !strings.HasSuffix(r.Content, GuardrailDisclaimer) {
+			t.Errorf("%s content lacks the disclaimer: %q", r.AgentName, r.Content)
+		}
+		found = found || strings.Contains(r.Content, "A [redacted] breakout")
+	}
+	if !found {
+		t.Error("expected the technical analyst's content among the results")
+	}
+}
+
+func TestOrchestratorCustomPostProcess(t *testing.T) {
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:    simpleProvider("ok"),
+		Aggregator:  datasource.NewAggregator(),
+		PostProcess: strings.ToUpper,
+	})
+
+	result, err := orch.Process(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if result.Content != "OK" {
+		t.Errorf("expected custom post-processor output %q, got %q", "OK", result.Content)
+	}
+}
+
+func TestOrchestratorFallbackToQuick(t *testing.T) {
+	// Only the single agent succeeds; every specialist and the CIO fail.
+	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+		if len(msgs) > 0 && strings.HasPrefix(msgs[0].Content, "You are OpeNSE.ai") {
+			return &llm.Response{Content: "TCS: HOLD (quick view)", FinishReason: llm.FinishStop}, nil
+		}
+		return nil, fmt.Errorf("%w: agent down", llm.ErrProviderDown)
+	})
+
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:        provider,
+		Aggregator:      datasource.NewAggregator(),
+		PostProcess:     func(s string) string { return s },
+		FallbackToQuick: true,
+	})
+	result, err := orch.FullAnalysis(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+	if !result.Degraded {
+		t.Error("expected result flagged as degraded")
+	}
+	if !strings.Contains(result.Content, "TCS: HOLD (quick view)") {
+		t.Errorf("expected quick query content, got %q", result.Content)
+	}
+	if !strings.Contains(result.Content, "Multi-agent analysis failed") {
+		t.Errorf("expected degradation note, got %q", result.Content)
+	}
+
+	// Without the option the multi-agent fallback summary is returned.
+	orch = NewOrchestrator(OrchestratorConfig{
+		Provider:    provider,
+		Aggregator:  datasource.NewAggregator(),
+		PostProcess: func(s string) string { return s },
+	})
+	result, err = orch.FullAnalysis(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+	if strings.Contains(result.Content, "quick view") {
+		t.Errorf("did not expect a quick query fallback, got %q", result.Content)
+	}
+	if !result.Degraded {
+		t.Error("expected CIO fallback summary flagged as degraded")
+	}
+}
+
+func TestOrchestratorDebateRound(t *testing.T) {
+	var debated atomic.Int32
+	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+		system, task := msgs[0].Content, msgs[len(msgs)-1].Content
+		var content string
+		switch {
+		case strings.Contains(system, "Chief Investment Officer") && strings.Contains(task, "adjudicate"):
+			debated.Add(1)
+			content = "VERDICT: fundamentals outweigh the short-term technical weakness."
+		case strings.Contains(system, "Chief Investment Officer"):
+			content = "Synthesis: HOLD"
+		case strings.Contains(system, "Fundamental Analyst"):
+			content = `{"recommendation": "BUY", "signals": [{"source": "Valuation", "type": "BUY", "confidence": 0.8}]}`
+		case strings.Contains(system, "Technical Analyst"):
+			content = `{"recommendation": "SELL", "signals": [{"source": "MACD", "type": "SELL", "confidence": 0.7}]}`
+		default:
+			content = "No strong view."
+		}
+		return &llm.Response{Content: content, FinishReason: llm.FinishStop}, nil
+	})
+
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:        provider,
+		Aggregator:      datasource.NewAggregator(),
+		PostProcess:     func(s string) string { return s },
+		DebateThreshold: 0.5,
+	})
+	result, err := orch.FullAnalysis(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+	if n := debated.Load(); n != 1 {
+		t.Fatalf("expected one CIO debate turn, got %d", n)
+	}
+	if result.Debate == nil {
+		t.Fatal("expected debate recorded in result")
+	}
+	if result.Debate.Bullish != "fundamental" || result.Debate.Bearish != "technical" {
+		t.Errorf("debate sides = %s vs %s, want fundamental vs technical", result.Debate.Bullish, result.Debate.Bearish)
+	}
+	if !strings.Contains(result.Debate.Verdict, "VERDICT") {
+		t.Errorf("expected CIO verdict captured, got %q", result.Debate.Verdict)
+	}
+
+	// Without a threshold no debate turn runs.
+	debated.Store(0)
+	orch = NewOrchestrator(OrchestratorConfig{
+		Provider:    provider,
+		Aggregator:  datasource.NewAggregator(),
+		PostProcess: func(s string) string { return s },
+	})
+	result, err = orch.FullAnalysis(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+	if debated.Load() != 0 || result.Debate != nil {
+		t.Error("did not expect a debate round when DebateThreshold is zero")
+	}
+}
+
+func TestOrchestratorFollowUp(t *testing.T) {
+	var followUp []llm.Message
+	var followUpTools []llm.Tool
+	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+		system := msgs[0].Content
+		last := msgs[len(msgs)-1].Content
+		switch {
+		case last == "Why SELL?":
+			followUp, followUpTools = msgs, tools
+			return &llm.Response{Content: "Because MACD turned bearish.", FinishReason: llm.FinishStop}, nil
+		case strings.Contains(system, "Technical Analyst"):
+			return &llm.Response{Content: "MACD bearish crossover; SELL.", FinishReason: llm.FinishStop}, nil
+		case strings.Contains(system, "Chief Investment Officer"), strings.Contains(system, "Report Generator"):
+			return &llm.Response{Content: "Final call: SELL", FinishReason: llm.FinishStop}, nil
+		}
+		return &llm.Response{Content: "analysis", FinishReason: llm.FinishStop}, nil
+	})
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:    provider,
+		Aggregator:  datasource.NewAggregator(),
+		PostProcess: func(s string) string { return s },
+	})
+
+	if _, err := orch.FollowUp(context.Background(), "Why SELL?"); err == nil {
+		t.Fatal("expected an error before any analysis")
+	}
+
+	if _, err := orch.FullAnalysis(context.Background(), "TCS"); err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+	analysisCalls := provider.callCount()
+
+	result, err := orch.FollowUp(context.Background(), "Why SELL?")
+	if err != nil {
+		t.Fatalf("FollowUp: %v", err)
+	}
+	followUpCalls := provider.callCount() - analysisCalls
+	if followUpCalls != 1 || analysisCalls < 5*followUpCalls {
+		t.Errorf("follow-up made %d provider calls against %d for the analysis", followUpCalls, analysisCalls)
+	}
+	if result.Content != "Because MACD turned bearish." {
+		t.Errorf("content = %q", result.Content)
+	}
+	if len(followUpTools) != 0 {
+		t.Errorf("follow-up offered %d tools, want none", len(followUpTools))
+	}
+	var sent strings.Builder
+	for _, m := range followUp {
+		sent.WriteString(m.Content)
+	}
+	for _, want := range []string{"MACD bearish crossover", "Final call: SELL"} {
+		if !strings.Contains(sent.String(), want) {
+			t.Errorf("follow-up context is missing %q", want)
+		}
+	}
+
+	// Another conversation's analysis is not the context for this one.
+	other := WithSession(context.Background(), "other")
+	if _, err := orch.FollowUp(other, "Why SELL?"); err == nil {
+		t.Error("expected an error following up in a session without an analysis")
+	}
+	if _, err := orch.QuickQuery(other, "Compare TCS and INFY"); err != nil {
+		t.Fatalf("QuickQuery: %v", err)
+	}
+	if _, err := orch.FollowUp(other, "Why SELL?"); err != nil {
+		t.Errorf("FollowUp in its own session: %v", err)
+	}
+	sent.Reset()
+	for _, m := range followUp {
+		sent.WriteString(m.Content)
+	}
+	if !strings.Contains(sent.String(), "Compare TCS and INFY") || strings.Contains(sent.String(), "MACD bearish crossover") {
+		t.Errorf("follow-up answered from another session: %q", sent.String())
+	}
+}
+
+func TestBuildFollowUpContextTruncatesOnRune(t *testing.T) {
+	out := strings.Repeat("a", maxFollowUpToolOutput-1) + strings.Repeat("₹", 10)
+	last := &AgentResult{Messages: []llm.Message{llm.ToolResultMessage("call_1", "get_quote", out)}}
+	got := buildFollowUpContext("TCS price", last)
+	if !utf8.ValidString(got) {
+		t.Fatal("follow-up context is not valid UTF-8")
+	}
+	if !strings.Contains(got, strings.Repeat("a", maxFollowUpToolOutput-1)+"…") {
+		t.Errorf("expected the tool output cut before the split rune, got %q", got[len(got)-40:])
+	}
+}
+
+func TestOrchestratorAgentTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var synthesis string
+	var mu sync.Mutex
+	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+		system := msgs[0].Content
+		switch {
+		case strings.Contains(system, "Chief Investment Officer"):
+			mu.Lock()
+			synthesis = msgs[len(msgs)-1].Content
+			mu.Unlock()
+			return &llm.Response{Content: "Synthesis: HOLD", FinishReason: llm.FinishStop}, nil
+		case strings.Contains(system, "Report Generator"):
+			return &llm.Response{Content: "Report: HOLD", FinishReason: llm.FinishStop}, nil
+		case strings.Contains(system, "**Sentiment Analyst**"):
+			// Hangs on a slow tool and ignores cancellation.
+			<-release
+			return &llm.Response{Content: "late sentiment", FinishReason: llm.FinishStop}, nil
+		}
+		return &llm.Response{Content: "on-time analysis", FinishReason: llm.FinishStop}, nil
+	})
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:     provider,
+		Aggregator:   datasource.NewAggregator(),
+		PostProcess:  func(s string) string { return s },
+		AgentTimeout: 50 * time.Millisecond,
+	})
+
+	done := make(chan struct{})
+	var result *AgentResult
+	var err error
+	go func() {
+		defer close(done)
+		result, err = orch.FullAnalysis(context.Background(), "TCS")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("FullAnalysis stalled on the slow agent")
+	}
+	if err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+
+	if len(result.AgentResults) != 5 {
+		t.Fatalf("got %d agent results, want 5", len(result.AgentResults))
+	}
+	for _, r := range result.AgentResults {
+		if r.AgentName == prompts.AgentSentiment {
+			if !strings.Contains(r.Content, "[timed out]") {
+				t.Errorf("sentiment content = %q, want timed-out marker", r.Content)
+			}
+		} else if r.Content != "on-time analysis" {
+			t.Errorf("%s content = %q", r.AgentName, r.Content)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(synthesis, "[timed out]") || !strings.Contains(synthesis, "on-time analysis") {
+		t.Errorf("CIO synthesis prompt should include the timeout and the other agents' output:\n%s", synthesis)
+	}
+	if result.Content != "Report: HOLD" {
+		t.Errorf("content = %q", result.Content)
+	}
+}
+
+func TestOrchestratorCanonicalResultOrder(t *testing.T) {
+	var mu sync.Mutex
+	var finished []string
+	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+		system := msgs[0].Content
+		switch {
+		case strings.Contains(system, "Chief Investment Officer"), strings.Contains(system, "Report Generator"):
+			return &llm.Response{Content: "Synthesis: HOLD", FinishReason: llm.FinishStop}, nil
+		case strings.Contains(system, "Fundamental Analyst"):
+			// The first agent in canonical order finishes last.
+			time.Sleep(50 * time.Millisecond)
+		}
+		mu.Lock()
+		finished = append(finished, system[:40])
+		mu.Unlock()
+		return &llm.Response{Content: "analysis", FinishReason: llm.FinishStop}, nil
+	})
+
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:    provider,
+		Aggregator:  datasource.NewAggregator(),
+		PostProcess: func(s string) string { return s },
+	})
+	result, err := orch.FullAnalysis(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+	if len(finished) == 0 || !strings.Contains(finished[len(finished)-1], "Fundamental") {
+		t.Fatalf("expected the fundamental agent to finish last, got %q", finished)
+	}
+
+	want := []string{"fundamental", "technical", "sentiment", "fno", "risk"}
+	if len(result.AgentResults) != len(want) {
+		t.Fatalf("expected %d agent results, got %d", len(want), len(result.AgentResults))
+	}
+	for i, r := range result.AgentResults {
+		if id := "TCS/" + want[i]; r.ID != id {
+			t.Errorf("result %d: ID = %q, want %q", i, r.ID, id)
+		}
+	}
+	if result.ID != "TCS/orchestrator" {
+		t.Errorf("orchestrator ID = %q, want TCS/orchestrator", result.ID)
+	}
+}
+
+func TestOrchestratorEstimateCostUnknownModel(t *testing.T) {
+	orch := &Orchestrator{model: "some-private-model"}
+	est := orch.EstimateCost(ModeMulti)
+	if est.PricingKnown || est.MaxCostUSD != 0 {
+		t.Errorf("expected unknown pricing with zero cost, got %+v", est)
+	}
+	if est.MaxTokens == 0 {
+		t.Error("token range should still be estimated for unknown models")
+	}
+}
+
 func TestIsAlpha(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -1448,7 +2071,7 @@ func TestTechnicalAgentAnalyze(t *testing.T) {
 
 func TestExecutorCreateTradeProposal(t *testing.T) {
 	provider := simpleProvider("Trade proposal: BUY TCS at 3500, SL 3400, Target 3800.")
-	agent := NewExecutorAgent(provider, nil)
+	agent := NewExecutorAgent(provider, nil, nil)
 
 	analyses := []*AgentResult{
 		{AgentName: "fundamental", Role: "Fundamental", Content: "Strong buy signal"},
@@ -1550,3 +2173,768 @@ func toolNameSet(tools []llm.Tool) map[string]bool {
 	}
 	return m
 }
+
+func TestMergeSignalsCollapsesDuplicates(t *testing.T) {
+	signals := []models.Signal{
+		{Source: "RSI", Type: models.SignalBuy, Confidence: 0.6, Reason: "oversold"},
+		{Source: "MACD", Type: models.SignalBuy, Confidence: 0.7},
+		{Source: "rsi", Type: models.SignalBuy, Confidence: 0.8, Reason: "deeply oversold"},
+	}
+
+	merged, conflicts := MergeSignals(signals)
+	if len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %d", len(conflicts))
+	}
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 merged signals, got %d", len(merged))
+	}
+	if merged[0].Source != "rsi" || merged[0].Confidence != 0.8 {
+		t.Errorf("expected higher-confidence RSI signal first, got %+v", merged[0])
+	}
+	if merged[1].Source != "MACD" {
+		t.Errorf("expected MACD second, got %s", merged[1].Source)
+	}
+}
+
+func TestMergeSignalsFlagsConflict(t *testing.T) {
+	signals := []models.Signal{
+		{Source: "RSI", Type: models.SignalBuy, Confidence: 0.6},
+		{Source: "RSI", Type: models.SignalSell, Confidence: 0.5},
+		{Source: "PCR", Type: models.SignalNeutral, Confidence: 0.4},
+	}
+
+	merged, conflicts := MergeSignals(signals)
+	if len(merged) != 3 {
+		t.Errorf("expected both RSI signals kept, got %d merged", len(merged))
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %d", len(conflicts))
+	}
+	c := conflicts[0]
+	if c.Source != "RSI" || c.Bullish.Type != models.SignalBuy || c.Bearish.Type != models.SignalSell {
+		t.Errorf("unexpected conflict: %+v", c)
+	}
+}
+
+func TestBuildCompositeAnalysisMergesSignals(t *testing.T) {
+	results := map[string]*AgentResult{
+		"technical": {Analysis: &models.AnalysisResult{Signals: []models.Signal{
+			{Source: "RSI", Type: models.SignalBuy, Confidence: 0.7},
+		}}},
+		"sentiment": {Analysis: &models.AnalysisResult{Signals: []models.Signal{
+			{Source: "RSI", Type: models.SignalBuy, Confidence: 0.5},
+			{Source: "RSI", Type: models.SignalSell, Confidence: 0.6},
+		}}},
+		"risk": {Content: "no structured analysis"},
+	}
+
+	composite := buildCompositeAnalysis("TCS", results)
+	if composite.Type != models.AnalysisComposite || composite.Ticker != "TCS" {
+		t.Errorf("unexpected composite header: %+v", composite)
+	}
+	if len(composite.Signals) != 2 {
+		t.Errorf("expected 2 merged signals, got %d", len(composite.Signals))
+	}
+	conflicts, ok := composite.Details["signal_conflicts"].([]SignalConflict)
+	if !ok || len(conflicts) != 1 {
+		t.Errorf("expected 1 signal conflict in details, got %v", composite.Details)
+	}
+}
+
+func TestBuildScenariosFromComputedATR(t *testing.T) {
+	// The model reports a bogus ATR; the mock bars have a true range of 80.
+	provider := simpleProvider(`{"recommendation": "BUY", "details": {"target_price": 4200, "atr": 5}}`)
+	technical, err := NewTechnicalAgent(provider, newMockSources(), nil).AnalyzeWithTimestamp(context.Background(), "TCS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atr := detailFloat(technical.Analysis.Details, "atr"); atr != 80 {
+		t.Fatalf("atr = %v, want the computed 80", atr)
+	}
+
+	results := []*AgentResult{technical, {Content: "no structured analysis"}}
+	sc := BuildScenarios(results, 16) // ATR scaled by √16
+	if sc == nil {
+		t.Fatal("expected scenarios")
+	}
+	want := models.PriceScenarios{Bear: 3560, Base: 4200, Bull: 4840, BandLow: 3880, BandHigh: 4520}
+	if *sc != want {
+		t.Errorf("scenarios = %+v, want %+v", *sc, want)
+	}
+	if short := BuildScenarios(results, HorizonDays("short-term")); short.BandHigh-short.BandLow >= sc.BandHigh-sc.BandLow {
+		t.Errorf("a shorter horizon should narrow the band: %+v vs %+v", short, sc)
+	}
+	if BuildScenarios(results[1:], 16) != nil {
+		t.Error("expected no scenarios without a target price")
+	}
+
+	composite := buildCompositeAnalysis("TCS", map[string]*AgentResult{"technical": technical})
+	if composite.Scenarios == nil || composite.Scenarios.Base != 4200 {
+		t.Errorf("composite scenarios = %+v, want a base of 4200", composite.Scenarios)
+	}
+}
+
+func TestOrchestratorLastRunMetadata(t *testing.T) {
+	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+		time.Sleep(time.Millisecond)
+		return &llm.Response{Content: "analysis", Model: "mock-model", FinishReason: llm.FinishStop}, nil
+	})
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:    provider,
+		Aggregator:  datasource.NewAggregator(),
+		PostProcess: func(s string) string { return s },
+	})
+	if md := orch.LastRunMetadata(); len(md.Agents) != 0 {
+		t.Fatalf("expected no metadata before a run, got %+v", md)
+	}
+
+	if _, err := orch.FullAnalysis(context.Background(), "TCS"); err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+
+	md := orch.LastRunMetadata()
+	if md.Mode != ModeMulti {
+		t.Errorf("mode = %v, want multi", md.Mode)
+	}
+	seen := make(map[string]bool)
+	for _, a := range md.Agents {
+		seen[a.Agent] = true
+		if a.Latency <= 0 {
+			t.Errorf("%s: latency = %v, want > 0", a.Agent, a.Latency)
+		}
+		if a.Model != "mock-model" {
+			t.Errorf("%s: model = %q, want mock-model", a.Agent, a.Model)
+		}
+		if a.Provider != "mock" {
+			t.Errorf("%s: provider = %q, want mock", a.Agent, a.Provider)
+		}
+	}
+	for _, name := range []string{
+		prompts.AgentFundamental, prompts.AgentTechnical, prompts.AgentSentiment,
+		prompts.AgentFnO, prompts.AgentRisk, prompts.AgentCIO,
+	} {
+		if !seen[name] {
+			t.Errorf("metadata missing agent %q (have %+v)", name, md.Agents)
+		}
+	}
+}
+
+func TestOrchestratorPersonaInstructions(t *testing.T) {
+	var first llm.Message
+	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+		first = msgs[0]
+		return &llm.Response{Content: "view", FinishReason: llm.FinishStop}, nil
+	})
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:    provider,
+		Aggregator:  datasource.NewAggregator(),
+		PostProcess: func(s string) string { return s },
+	})
+
+	persona, err := prompts.Persona("Conservative")
+	if err != nil {
+		t.Fatalf("Persona: %v", err)
+	}
+	orch.SetInstructions(persona)
+	if _, err := orch.Chat(context.Background(), "What is your view on TCS?", nil); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if first.Role != llm.RoleSystem {
+		t.Fatalf("first message role = %q, want system", first.Role)
+	}
+	if !strings.Contains(first.Content, persona) {
+		t.Errorf("system prompt does not include the persona:\n%s", first.Content)
+	}
+	if !strings.Contains(first.Content, "OpeNSE.ai") {
+		t.Errorf("persona replaced the default system prompt:\n%s", first.Content)
+	}
+
+	if _, err := prompts.Persona("reckless"); err == nil {
+		t.Error("expected an error for an unknown persona")
+	}
+}
+
+func TestOrchestratorFocusInstructions(t *testing.T) {
+	var system string
+	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+		system = msgs[0].Content
+		return &llm.Response{Content: "view", FinishReason: llm.FinishStop}, nil
+	})
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:    provider,
+		Aggregator:  datasource.NewAggregator(),
+		PostProcess: func(s string) string { return s },
+	})
+
+	focus, err := prompts.Focus(" Valuation ")
+	if err != nil {
+		t.Fatalf("Focus: %v", err)
+	}
+	orch.SetInstructions(focus)
+	if _, err := orch.QuickQuery(context.Background(), "Analyze TCS stock"); err != nil {
+		t.Fatalf("QuickQuery: %v", err)
+	}
+	if !strings.Contains(system, "## Analysis Focus: Valuation") {
+		t.Errorf("system prompt does not carry the valuation focus:\n%s", system)
+	}
+
+	for _, name := range prompts.FocusNames() {
+		if _, err := prompts.Focus(name); err != nil {
+			t.Errorf("Focus(%q): %v", name, err)
+		}
+	}
+	if _, err := prompts.Focus("dividends"); err == nil {
+		t.Error("expected an error for an unknown focus")
+	}
+}
+
+func TestSuggestHoldingStops(t *testing.T) {
+	holdings := []models.Holding{
+		{Ticker: "TCS", Quantity: 10, AvgPrice: 3500, LTP: 4000},
+		{Ticker: "INFY", Quantity: 20, AvgPrice: 1500},
+	}
+	atrs := map[string]float64{"TCS": 40, "INFY": 10}
+	fakeATR := func(_ context.Context, ticker string) (float64, error) {
+		return atrs[ticker], nil
+	}
+
+	stops := SuggestHoldingStops(context.Background(), holdings, fakeATR)
+	if len(stops) != 2 {
+		t.Fatalf("expected 2 holdings, got %d", len(stops))
+	}
+	want := map[string][]StopLevel{
+		// TCS: 4000 − {1.5, 2, 3} × 40, risk × 10 shares.
+		"TCS": {{Name: "tight", Price: 3940, Risk: 600}, {Name: "moderate", Price: 3920, Risk: 800}, {Name: "wide", Price: 3880, Risk: 1200}},
+		// INFY has no LTP, so stops hang off the 1500 average price.
+		"INFY": {{Name: "tight", Price: 1485, Risk: 300}, {Name: "moderate", Price: 1480, Risk: 400}, {Name: "wide", Price: 1470, Risk: 600}},
+	}
+	for _, hs := range stops {
+		if hs.Error != "" {
+			t.Fatalf("%s: unexpected error %s", hs.Ticker, hs.Error)
+		}
+		if len(hs.Levels) != 3 {
+			t.Fatalf("%s: expected 3 stop levels, got %d", hs.Ticker, len(hs.Levels))
+		}
+		for i, l := range hs.Levels {
+			w := want[hs.Ticker][i]
+			if l.Name != w.Name || math.Abs(l.Price-w.Price) > 0.01 || math.Abs(l.Risk-w.Risk) > 0.01 {
+				t.Errorf("%s level %d = %+v, want %s at %.2f risking %.2f", hs.Ticker, i, l, w.Name, w.Price, w.Risk)
+			}
+		}
+	}
+
+	failing := func(context.Context, string) (float64, error) { return 0, errors.New("no data") }
+	stops = SuggestHoldingStops(context.Background(), holdings[:1], failing)
+	if len(stops) != 1 || stops[0].Error == "" || len(stops[0].Levels) != 0 {
+		t.Errorf("expected an error entry without levels, got %+v", stops)
+	}
+}
+
+func TestUsageAccumulatorConcurrent(t *testing.T) {
+	u := NewUsageAccumulator("gpt-4o")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				u.Add("", llm.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5})
+			}
+		}()
+	}
+	wg.Wait()
+
+	got := u.Totals()
+	if got.Calls != 1000 || got.PromptTokens != 3000 || got.CompletionTokens != 2000 || got.TotalTokens != 5000 {
+		t.Errorf("unexpected totals %+v", got)
+	}
+	pricing, _ := llm.LookupPricing("gpt-4o")
+	if want := pricing.Cost(3000, 2000); math.Abs(got.CostUSD-want) > 1e-9 || !got.PricingKnown {
+		t.Errorf("cost = %v (known %v), want %v", got.CostUSD, got.PricingKnown, want)
+	}
+
+	u.Add("no-such-model", llm.Usage{PromptTokens: 1})
+	if u.Totals().PricingKnown {
+		t.Error("pricing should be unknown after a call to an unpriced model")
+	}
+}
+
+func TestOrchestratorRunUsage(t *testing.T) {
+	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+		return &llm.Response{
+			Content:      "analysis",
+			FinishReason: llm.FinishStop,
+			Usage:        llm.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+			Model:        "mock-model",
+		}, nil
+	})
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:    provider,
+		Aggregator:  datasource.NewAggregator(),
+		PostProcess: func(s string) string { return s },
+	})
+
+	result, err := orch.FullAnalysis(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+
+	calls := provider.callCount()
+	if calls < multiAgentSpecialists+1 {
+		t.Fatalf("expected the specialists and CIO to call the provider, got %d calls", calls)
+	}
+	want := UsageTotals{Calls: calls, PromptTokens: 10 * calls, CompletionTokens: 5 * calls, TotalTokens: 15 * calls}
+	if result.Usage == nil || *result.Usage != want {
+		t.Errorf("result usage = %+v, want %+v", result.Usage, want)
+	}
+	if md := orch.LastRunMetadata(); md.Usage != want {
+		t.Errorf("metadata usage = %+v, want %+v", md.Usage, want)
+	}
+
+	// A second run starts a fresh count.
+	if _, err := orch.QuickQuery(context.Background(), "TCS price?"); err != nil {
+		t.Fatalf("QuickQuery: %v", err)
+	}
+	if md := orch.LastRunMetadata(); md.Usage.Calls != provider.callCount()-calls {
+		t.Errorf("second run calls = %d, want %d", md.Usage.Calls, provider.callCount()-calls)
+	}
+}
+
+func TestOrchestratorRetriesLowConfidenceSpecialist(t *testing.T) {
+	var retries atomic.Int32
+	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+		last := msgs[len(msgs)-1].Content
+		content := `{"recommendation": "HOLD", "confidence": 0.2, "summary": "unsure"}`
+		if strings.Contains(last, "not confident enough") {
+			retries.Add(1)
+			content = `{"recommendation": "BUY", "confidence": 0.8, "summary": "decisive"}`
+		}
+		return &llm.Response{Content: content, FinishReason: llm.FinishStop, Usage: llm.Usage{TotalTokens: 10}}, nil
+	})
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:      provider,
+		Aggregator:    datasource.NewAggregator(),
+		PostProcess:   func(s string) string { return s },
+		MinConfidence: 0.5,
+	})
+
+	result, err := orch.FullAnalysis(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+	if got := retries.Load(); got != multiAgentSpecialists {
+		t.Errorf("retries = %d, want one per specialist (%d)", got, multiAgentSpecialists)
+	}
+	if len(result.AgentResults) != multiAgentSpecialists {
+		t.Fatalf("expected %d specialist results, got %d", multiAgentSpecialists, len(result.AgentResults))
+	}
+	for _, r := range result.AgentResults {
+		if !r.Retried || r.Analysis == nil || r.Analysis.Confidence != 0.8 || r.Analysis.Recommendation != "BUY" {
+			t.Errorf("%s: expected the confident retry to be used, got retried=%v analysis=%+v", r.AgentName, r.Retried, r.Analysis)
+		}
+		if r.Tokens != 20 {
+			t.Errorf("%s: tokens = %d, want both attempts counted (20)", r.AgentName, r.Tokens)
+		}
+	}
+}
+
+func TestOrchestratorRetryBoundedByAgentTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	provider := newMockProvider(func(ctx context.Context, msgs []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+		if strings.Contains(msgs[len(msgs)-1].Content, "not confident enough") {
+			<-release // the re-prompt hangs and ignores cancellation
+		}
+		return &llm.Response{Content: `{"recommendation": "HOLD", "confidence": 0.2}`, FinishReason: llm.FinishStop}, nil
+	})
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:      provider,
+		Aggregator:    datasource.NewAggregator(),
+		PostProcess:   func(s string) string { return s },
+		MinConfidence: 0.5,
+		AgentTimeout:  50 * time.Millisecond,
+	})
+
+	done := make(chan struct{})
+	var result *AgentResult
+	var err error
+	go func() {
+		defer close(done)
+		result, err = orch.FullAnalysis(context.Background(), "TCS")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("FullAnalysis stalled on the re-prompt")
+	}
+	if err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+	for _, r := range result.AgentResults {
+		if r.Retried || r.Error != "" || r.Analysis == nil || r.Analysis.Confidence != 0.2 {
+			t.Errorf("%s: expected the first answer, got retried=%v error=%q analysis=%+v", r.AgentName, r.Retried, r.Error, r.Analysis)
+		}
+	}
+}
+
+func TestOrchestratorNoRetryWithoutMinConfidence(t *testing.T) {
+	provider := simpleProvider(`{"recommendation": "HOLD", "confidence": 0.1}`)
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:    provider,
+		Aggregator:  datasource.NewAggregator(),
+		PostProcess: func(s string) string { return s },
+	})
+
+	result, err := orch.FullAnalysis(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("FullAnalysis: %v", err)
+	}
+	for _, r := range result.AgentResults {
+		if r.Retried {
+			t.Errorf("%s: retried with MinConfidence unset", r.AgentName)
+		}
+	}
+}
+
+// promptStreamProvider streams, for each call, the name its system prompt
+// is registered under, split across three chunks.
+type promptStreamProvider struct {
+	*mockProvider
+	names sync.Map // system prompt → agent name
+}
+
+func (p *promptStreamProvider) ChatStream(_ context.Context, msgs []llm.Message, _ []llm.Tool, _ *llm.ChatOptions) (<-chan llm.StreamChunk, error) {
+	name := "unknown"
+	if v, ok := p.names.Load(msgs[0].Content); ok {
+		name = v.(string)
+	}
+	ch := make(chan llm.StreamChunk, 3)
+	ch <- llm.StreamChunk{Content: name}
+	ch <- llm.StreamChunk{Content: "|"}
+	ch <- llm.StreamChunk{Content: name, FinishReason: llm.FinishStop, Done: true}
+	close(ch)
+	return ch, nil
+}
+
+func TestOrchestratorAnalyzeStream(t *testing.T) {
+	provider := &promptStreamProvider{mockProvider: newMockProvider(nil)}
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:   provider,
+		Aggregator: datasource.NewAggregator(),
+	})
+	for _, a := range []*BaseAgent{
+		orch.fundamental.BaseAgent, orch.technical.BaseAgent, orch.sentiment.BaseAgent,
+		orch.fno.BaseAgent, orch.risk.BaseAgent, orch.reporter.BaseAgent, orch.cio,
+	} {
+		provider.names.Store(a.SystemPrompt(), a.Name())
+	}
+
+	if _, err := orch.AnalyzeStream(context.Background(), " "); err == nil {
+		t.Fatal("expected error for empty ticker")
+	}
+	events, err := orch.AnalyzeStream(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("AnalyzeStream: %v", err)
+	}
+
+	streamed := make(map[string]string)
+	var last AgentStreamEvent
+	for ev := range events {
+		if last.Result != nil {
+			t.Fatalf("event after the final result: %+v", ev)
+		}
+		streamed[ev.Agent] += ev.Delta
+		last = ev
+	}
+
+	if last.Agent != "orchestrator" || last.Err != nil || last.Result == nil {
+		t.Fatalf("unexpected final event: %+v", last)
+	}
+	if provider.callCount() != 0 {
+		t.Errorf("expected every call to stream, got %d Chat calls", provider.callCount())
+	}
+	for _, name := range []string{prompts.AgentFundamental, prompts.AgentTechnical, prompts.AgentRisk, prompts.AgentCIO} {
+		if got, want := streamed[name], name+"|"+name; got != want {
+			t.Errorf("%s streamed %q, want %q", name, got, want)
+		}
+	}
+	if _, ok := streamed["unknown"]; ok {
+		t.Error("received chunks from an unregistered prompt")
+	}
+	if r := last.Result.AgentResults; len(r) != multiAgentSpecialists || !strings.HasPrefix(r[0].Content, prompts.AgentFundamental+"|"+prompts.AgentFundamental) {
+		t.Errorf("unexpected specialist results: %+v", r)
+	}
+}
+
+func TestOrchestratorAnalyzeStreamRedacts(t *testing.T) {
+	deltas := []string{"TCS talk of guaranteed ", "returns is a red flag.\n", "Hold, risk-free ", "profit is a myth"}
+	provider := &deltaStreamProvider{mockProvider: newMockProvider(nil), deltas: deltas}
+	orch := NewOrchestrator(OrchestratorConfig{Provider: provider, Aggregator: datasource.NewAggregator()})
+
+	events, err := orch.AnalyzeStream(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("AnalyzeStream: %v", err)
+	}
+	streamed := make(map[string]string)
+	for ev := range events {
+		if bannedPhrases.MatchString(ev.Delta) {
+			t.Errorf("%s streamed a banned phrase: %q", ev.Agent, ev.Delta)
+		}
+		streamed[ev.Agent] += ev.Delta
+	}
+	want := "TCS talk of [redacted] is a red flag.\nHold, [redacted] is a myth"
+	for _, name := range []string{prompts.AgentFundamental, prompts.AgentCIO} {
+		if !strings.Contains(streamed[name], want) {
+			t.Errorf("%s streamed %q, want it to contain %q", name, streamed[name], want)
+		}
+	}
+
+	// A custom post-processor may rewrite anything, so nothing streams.
+	orch = NewOrchestrator(OrchestratorConfig{Provider: provider, Aggregator: datasource.NewAggregator(), PostProcess: strings.ToUpper})
+	events, err = orch.AnalyzeStream(context.Background(), "TCS")
+	if err != nil {
+		t.Fatalf("AnalyzeStream: %v", err)
+	}
+	var last AgentStreamEvent
+	for ev := range events {
+		if ev.Delta != "" {
+			t.Fatalf("%s streamed %q under a custom post-processor", ev.Agent, ev.Delta)
+		}
+		last = ev
+	}
+	if last.Result == nil || !strings.Contains(last.Result.Content, "HOLD") {
+		t.Errorf("expected the post-processed result, got %+v", last.Result)
+	}
+}
+
+// deltaStreamProvider streams a fixed list of content deltas, then usage
+// if set.
+type deltaStreamProvider struct {
+	*mockProvider
+	deltas []string
+	usage  *llm.Usage
+}
+
+func (p *deltaStreamProvider) ChatStream(_ context.Context, _ []llm.Message, _ []llm.Tool, _ *llm.ChatOptions) (<-chan llm.StreamChunk, error) {
+	ch := make(chan llm.StreamChunk, len(p.deltas)+1)
+	for _, d := range p.deltas {
+		ch <- llm.StreamChunk{Content: d}
+	}
+	ch <- llm.StreamChunk{FinishReason: llm.FinishStop, Done: true, Usage: p.usage}
+	close(ch)
+	return ch, nil
+}
+
+func TestOrchestratorAnalyzeStreamUsage(t *testing.T) {
+	run := func(usage *llm.Usage) UsageTotals {
+		t.Helper()
+		provider := &deltaStreamProvider{mockProvider: newMockProvider(nil), deltas: []string{"Hold\n"}, usage: usage}
+		orch := NewOrchestrator(OrchestratorConfig{Provider: provider, Aggregator: datasource.NewAggregator()})
+		events, err := orch.AnalyzeStream(context.Background(), "TCS")
+		if err != nil {
+			t.Fatalf("AnalyzeStream: %v", err)
+		}
+		var last AgentStreamEvent
+		for ev := range events {
+			last = ev
+		}
+		if last.Result == nil || last.Result.Usage == nil {
+			t.Fatalf("expected a result with usage, got %+v", last)
+		}
+		return *last.Result.Usage
+	}
+
+	got := run(&llm.Usage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120})
+	if got.Calls == 0 || got.TotalTokens != 120*got.Calls || got.PromptTokens != 100*got.Calls || got.Incomplete {
+		t.Errorf("streamed usage = %+v", got)
+	}
+	if got := run(nil); got.Calls == 0 || got.TotalTokens != 0 || !got.Incomplete {
+		t.Errorf("unreported usage = %+v, want Incomplete", got)
+	}
+}
+
+func TestOrchestratorChatStream(t *testing.T) {
+	deltas := []string{"TCS looks ", "strong.\nGuaranteed ", "returns ahead.\n", "Hold"}
+	provider := &deltaStreamProvider{mockProvider: newMockProvider(nil), deltas: deltas}
+	orch := NewOrchestrator(OrchestratorConfig{Provider: provider, Aggregator: datasource.NewAggregator()})
+
+	if _, err := orch.ChatStream(context.Background(), " ", nil); err == nil {
+		t.Fatal("expected error for empty message")
+	}
+	chunks, err := orch.ChatStream(context.Background(), "How is TCS?", nil)
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+
+	var got []string
+	var last llm.StreamChunk
+	for c := range chunks {
+		if last.Done {
+			t.Fatalf("chunk after the last one: %+v", c)
+		}
+		got = append(got, c.Content)
+		last = c
+	}
+	if !last.Done || last.Err != nil {
+		t.Fatalf("unexpected last chunk: %+v", last)
+	}
+	want := []string{"TCS looks strong.\n", "[redacted] ahead.\n", "Hold", "\n\n---\n\n" + GuardrailDisclaimer}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("chunks = %q, want %q", got, want)
+	}
+	if full := strings.Join(got, ""); full != DefaultGuardrail(strings.Join(deltas, "")) {
+		t.Errorf("streamed reply %q does not match the post-processed reply", full)
+	}
+	if provider.callCount() != 0 {
+		t.Errorf("expected the reply to stream, got %d Chat calls", provider.callCount())
+	}
+}
+
+func TestOrchestratorChatStreamCustomPostProcess(t *testing.T) {
+	deltas := []string{"TCS looks ", "strong.\n", "Hold"}
+	provider := &deltaStreamProvider{mockProvider: newMockProvider(nil), deltas: deltas}
+	orch := NewOrchestrator(OrchestratorConfig{
+		Provider:    provider,
+		Aggregator:  datasource.NewAggregator(),
+		PostProcess: strings.ToUpper,
+	})
+
+	chunks, err := orch.ChatStream(context.Background(), "How is TCS?", nil)
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	var got []string
+	for c := range chunks {
+		got = append(got, c.Content)
+	}
+	if want := []string{"TCS LOOKS STRONG.\nHOLD"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("chunks = %q, want %q", got, want)
+	}
+}
+
+// roundStreamProvider streams one response per call from rounds, recording
+// the messages of each call.
+type roundStreamProvider struct {
+	*mockProvider
+	rounds [][]llm.StreamChunk
+	mu     sync.Mutex
+	seen   [][]llm.Message
+}
+
+func (p *roundStreamProvider) ChatStream(_ context.Context, msgs []llm.Message, _ []llm.Tool, _ *llm.ChatOptions) (<-chan llm.StreamChunk, error) {
+	p.mu.Lock()
+	round := p.rounds[len(p.seen)%len(p.rounds)]
+	p.seen = append(p.seen, msgs)
+	p.mu.Unlock()
+	ch := make(chan llm.StreamChunk, len(round))
+	for _, c := range round {
+		ch <- c
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestOrchestratorChatStreamToolRound(t *testing.T) {
+	provider := &roundStreamProvider{mockProvider: newMockProvider(nil), rounds: [][]llm.StreamChunk{
+		{
+			{Content: "Let me check the quote.\n"},
+			{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "no_such_tool", Arguments: json.RawMessage(`{}`)}}},
+			{FinishReason: llm.FinishToolCalls, Done: true},
+		},
+		{
+			{Content: "TCS is at ₹3,500."},
+			{FinishReason: llm.FinishStop, Done: true},
+		},
+	}}
+	orch := NewOrchestrator(OrchestratorConfig{Provider: provider, Aggregator: datasource.NewAggregator()})
+
+	chunks, err := orch.ChatStream(context.Background(), "How is TCS?", nil)
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	var reply strings.Builder
+	var last llm.StreamChunk
+	rounds := 0
+	for c := range chunks {
+		if c.FinishReason == llm.FinishToolCalls && !c.Done {
+			rounds++
+			reply.Reset()
+			continue
+		}
+		reply.WriteString(c.Content)
+		last = c
+	}
+	if !last.Done || last.Err != nil {
+		t.Fatalf("unexpected last chunk: %+v", last)
+	}
+	if rounds != 1 {
+		t.Errorf("expected 1 tool round marker, got %d", rounds)
+	}
+	if got, want := reply.String(), DefaultGuardrail("TCS is at ₹3,500."); got != want {
+		t.Errorf("reply = %q, want %q", got, want)
+	}
+}
+
+func TestOrchestratorChatStreamMultiProgress(t *testing.T) {
+	provider := &promptStreamProvider{mockProvider: newMockProvider(nil)}
+	orch := NewOrchestrator(OrchestratorConfig{Provider: provider, Aggregator: datasource.NewAggregator()})
+	orch.SetMode(ModeMulti)
+
+	chunks, err := orch.ChatStream(context.Background(), "Deep dive on TCS", nil)
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	var progress strings.Builder
+	var last llm.StreamChunk
+	toolRound := false
+	for c := range chunks {
+		if c.FinishReason == llm.FinishToolCalls && !c.Done {
+			toolRound = true
+			continue
+		}
+		if !c.Done {
+			progress.WriteString(c.Content)
+		}
+		last = c
+	}
+
+	for _, name := range []string{prompts.AgentFundamental, prompts.AgentTechnical, prompts.AgentRisk, prompts.AgentCIO} {
+		if !strings.Contains(progress.String(), "→ "+name+" running\n") {
+			t.Errorf("no progress line for %s in %q", name, progress.String())
+		}
+	}
+	if !toolRound {
+		t.Error("expected a tool-calls chunk separating progress from the answer")
+	}
+	if !last.Done || last.Err != nil || !strings.Contains(last.Content, GuardrailDisclaimer) {
+		t.Errorf("expected the whole answer in the last chunk, got %+v", last)
+	}
+}
+
+func TestOrchestratorChatStreamMultiKeepsHistory(t *testing.T) {
+	provider := &roundStreamProvider{mockProvider: newMockProvider(nil), rounds: [][]llm.StreamChunk{
+		{{Content: "Still a hold."}, {FinishReason: llm.FinishStop, Done: true}},
+	}}
+	orch := NewOrchestrator(OrchestratorConfig{Provider: provider, Aggregator: datasource.NewAggregator()})
+	orch.SetMode(ModeMulti)
+
+	history := []llm.Message{llm.UserMessage("How is TCS?"), llm.AssistantMessage("TCS is a hold.")}
+	chunks, err := orch.ChatStream(context.Background(), "And now?", history)
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	for range chunks {
+	}
+
+	if len(provider.seen) != 1 {
+		t.Fatalf("expected 1 call, got %d", len(provider.seen))
+	}
+	found := false
+	for _, m := range provider.seen[0] {
+		found = found || m.Content == "TCS is a hold."
+	}
+	if !found {
+		t.Errorf("history missing from the request: %+v", provider.seen[0])
+	}
+}
@@ -0,0 +1,80 @@
+package agent
+
+import "github.com/seenimoa/openseai/internal/llm"
+
+// tokenProfile is the typical token usage of a single agent run,
+// including its tool-calling round trips.
+type tokenProfile struct {
+	Prompt     int
+	Completion int
+}
+
+// agentTokenRange is the low/high token usage observed for one agent run.
+type agentTokenRange struct {
+	Min tokenProfile
+	Max tokenProfile
+}
+
+// Typical per-agent token usage. Specialists run several tool round trips
+// whose results are fed back as prompt tokens, so their upper bound is wide.
+var (
+	specialistTokens = agentTokenRange{Min: tokenProfile{4000, 800}, Max: tokenProfile{20000, 2500}}
+	cioTokens        = agentTokenRange{Min: tokenProfile{6000, 800}, Max: tokenProfile{12000, 2000}}
+	reporterTokens   = agentTokenRange{Min: tokenProfile{5000, 1500}, Max: tokenProfile{10000, 4000}}
+	singleTokens     = agentTokenRange{Min: tokenProfile{3000, 500}, Max: tokenProfile{25000, 3000}}
+)
+
+// multiAgentSpecialists is the number of specialist agents run in ModeMulti.
+const multiAgentSpecialists = 5
+
+// CostEstimate is an approximate token and USD range for one orchestrator run.
+type CostEstimate struct {
+	Mode         OrchestratorMode `json:"mode"`
+	Model        string           `json:"model"`
+	AgentRuns    int              `json:"agent_runs"`
+	MinTokens    int              `json:"min_tokens"`
+	MaxTokens    int              `json:"max_tokens"`
+	MinCostUSD   float64          `json:"min_cost_usd"`
+	MaxCostUSD   float64          `json:"max_cost_usd"`
+	PricingKnown bool             `json:"pricing_known"` // false if the model is not in the pricing table
+}
+
+// EstimateCost returns the expected token and cost range for running the
+// given mode with the orchestrator's configured model. It makes no LLM calls.
+func (o *Orchestrator) EstimateCost(mode OrchestratorMode) CostEstimate {
+	var runs []agentTokenRange
+	switch mode {
+	case ModeMulti:
+		for i := 0; i < multiAgentSpecialists; i++ {
+			runs = append(runs, specialistTokens)
+		}
+		runs = append(runs, cioTokens, reporterTokens)
+	default:
+		mode = ModeSingle
+		runs = append(runs, singleTokens)
+	}
+
+	est := CostEstimate{
+		Mode:      mode,
+		Model:     o.model,
+		AgentRuns: len(runs),
+	}
+
+	var minP, minC, maxP, maxC int
+	for _, r := range runs {
+		minP += r.Min.Prompt
+		minC += r.Min.Completion
+		maxP += r.Max.Prompt
+		maxC += r.Max.Completion
+	}
+	est.MinTokens = minP + minC
+	est.MaxTokens = maxP + maxC
+
+	pricing, ok := llm.LookupPricing(o.model)
+	est.PricingKnown = ok
+	if ok {
+		est.MinCostUSD = pricing.Cost(minP, minC)
+		est.MaxCostUSD = pricing.Cost(maxP, maxC)
+	}
+	return est
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/seenimoa/openseai/internal/agent/prompts"
@@ -16,6 +17,22 @@ import (
 // human-in-the-loop confirmation. NEVER executes trades without explicit approval.
 type ExecutorAgent struct {
 	*BaseAgent
+	limits ExecutorLimits
+}
+
+// ExecutorLimits holds the thresholds the validate_trade tool enforces, so
+// different risk profiles can be applied. Percentages are of capital.
+type ExecutorLimits struct {
+	MaxPositionPct float64 // largest position allowed; a position above 60% of this draws a warning
+	MaxLossPct     float64 // largest loss allowed if the stop-loss is hit
+	MinRiskReward  float64 // smallest reward:risk ratio allowed, e.g. 2 for 1:2; zero skips the check
+	MinConfidence  float64 // smallest analysis confidence (0–1) allowed; zero skips the check
+}
+
+// DefaultExecutorLimits returns the limits used when none are given: a 5%
+// position cap and a 2% loss cap, with no reward:risk or confidence floor.
+func DefaultExecutorLimits() ExecutorLimits {
+	return ExecutorLimits{MaxPositionPct: 5, MaxLossPct: 2}
 }
 
 // TradeProposal represents a structured trade proposal requiring human approval.
@@ -34,9 +51,20 @@ type TradeProposal struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
-// NewExecutorAgent creates a Trade Executor agent.
-func NewExecutorAgent(provider llm.LLMProvider, opts *llm.ChatOptions) *ExecutorAgent {
-	agent := &ExecutorAgent{}
+// NewExecutorAgent creates a Trade Executor agent. A nil limits uses
+// DefaultExecutorLimits, as do its zero MaxPositionPct and MaxLossPct.
+func NewExecutorAgent(provider llm.LLMProvider, opts *llm.ChatOptions, limits *ExecutorLimits) *ExecutorAgent {
+	def := DefaultExecutorLimits()
+	agent := &ExecutorAgent{limits: def}
+	if limits != nil {
+		agent.limits = *limits
+		if agent.limits.MaxPositionPct <= 0 {
+			agent.limits.MaxPositionPct = def.MaxPositionPct
+		}
+		if agent.limits.MaxLossPct <= 0 {
+			agent.limits.MaxLossPct = def.MaxLossPct
+		}
+	}
 
 	tools := agent.buildTools()
 
@@ -92,15 +120,17 @@ func (a *ExecutorAgent) buildTools() []llm.Tool {
 		},
 		{
 			Name:        "validate_trade",
-			Description: "Validate a trade proposal against risk rules: max position size 5%, max daily loss 2%, circuit limits, market hours",
+			Description: a.validateTradeDescription(),
 			Parameters: llm.ObjectSchema("Trade validation parameters",
 				map[string]*llm.JSONSchema{
-					"ticker":    llm.StringProp("NSE ticker symbol"),
-					"action":    llm.StringProp("BUY or SELL"),
-					"price":     llm.NumberProp("Trade price in ₹"),
-					"quantity":  llm.IntProp("Number of shares"),
-					"capital":   llm.NumberProp("Total trading capital in ₹"),
-					"stop_loss": llm.NumberProp("Stop-loss price in ₹"),
+					"ticker":     llm.StringProp("NSE ticker symbol"),
+					"action":     llm.StringProp("BUY or SELL"),
+					"price":      llm.NumberProp("Trade price in ₹"),
+					"quantity":   llm.IntProp("Number of shares"),
+					"capital":    llm.NumberProp("Total trading capital in ₹"),
+					"stop_loss":  llm.NumberProp("Stop-loss price in ₹"),
+					"target":     llm.NumberProp("Target price in ₹"),
+					"confidence": llm.NumberProp("Confidence of the analysis behind the trade, 0 to 1"),
 				},
 				"ticker", "action", "price", "quantity",
 			),
@@ -109,6 +139,18 @@ func (a *ExecutorAgent) buildTools() []llm.Tool {
 	}
 }
 
+// validateTradeDescription describes validate_trade with the agent's limits.
+func (a *ExecutorAgent) validateTradeDescription() string {
+	desc := fmt.Sprintf("Validate a trade proposal against risk rules: max position size %g%%, max daily loss %g%%", a.limits.MaxPositionPct, a.limits.MaxLossPct)
+	if a.limits.MinRiskReward > 0 {
+		desc += fmt.Sprintf(", min risk:reward 1:%g", a.limits.MinRiskReward)
+	}
+	if a.limits.MinConfidence > 0 {
+		desc += fmt.Sprintf(", min confidence %.0f%%", a.limits.MinConfidence*100)
+	}
+	return desc + ", circuit limits, market hours"
+}
+
 // ── Tool Handlers ──
 
 func (a *ExecutorAgent) handleCreateProposal(_ context.Context, args json.RawMessage) (string, error) {
@@ -181,38 +223,69 @@ func (a *ExecutorAgent) handleEstimateBrokerage(_ context.Context, args json.Raw
 
 func (a *ExecutorAgent) handleValidateTrade(_ context.Context, args json.RawMessage) (string, error) {
 	var params struct {
-		Ticker   string  `json:"ticker"`
-		Action   string  `json:"action"`
-		Price    float64 `json:"price"`
-		Quantity int     `json:"quantity"`
-		Capital  float64 `json:"capital"`
-		StopLoss float64 `json:"stop_loss"`
+		Ticker     string  `json:"ticker"`
+		Action     string  `json:"action"`
+		Price      float64 `json:"price"`
+		Quantity   int     `json:"quantity"`
+		Capital    float64 `json:"capital"`
+		StopLoss   float64 `json:"stop_loss"`
+		Target     float64 `json:"target"`
+		Confidence float64 `json:"confidence"`
 	}
 	if err := json.Unmarshal(args, &params); err != nil {
 		return "", fmt.Errorf("parse args: %w", err)
 	}
 
+	limits := a.limits
 	posValue := params.Price * float64(params.Quantity)
 	issues := []string{}
 	warnings := []string{}
 
-	// Max position size check (5% of capital)
+	// Max position size check
 	if params.Capital > 0 {
 		posPct := (posValue / params.Capital) * 100
-		if posPct > 5.0 {
-			issues = append(issues, fmt.Sprintf("POSITION_SIZE: %.1f%% exceeds 5%% max (₹%.0f of ₹%.0f)", posPct, posValue, params.Capital))
-		} else if posPct > 3.0 {
+		if posPct > limits.MaxPositionPct {
+			issues = append(issues, fmt.Sprintf("POSITION_SIZE: %.1f%% exceeds %g%% max (₹%.0f of ₹%.0f)", posPct, limits.MaxPositionPct, posValue, params.Capital))
+		} else if posPct > limits.MaxPositionPct*0.6 {
 			warnings = append(warnings, fmt.Sprintf("Position is %.1f%% of capital — on the higher side", posPct))
 		}
 	}
 
-	// Max loss check (2% of capital)
+	// Max loss check
 	if params.Capital > 0 && params.StopLoss > 0 {
 		maxLoss := abs(params.Price-params.StopLoss) * float64(params.Quantity)
 		lossPct := (maxLoss / params.Capital) * 100
-		if lossPct > 2.0 {
-			issues = append(issues, fmt.Sprintf("MAX_LOSS: %.1f%% exceeds 2%% daily loss limit (₹%.0f)", lossPct, maxLoss))
+		if lossPct > limits.MaxLossPct {
+			issues = append(issues, fmt.Sprintf("MAX_LOSS: %.1f%% exceeds %g%% daily loss limit (₹%.0f)", lossPct, limits.MaxLossPct, maxLoss))
+		}
+	}
+
+	// The target must be on the profitable side: above the entry for a
+	// BUY, below it for a SELL
+	reward := params.Target - params.Price
+	if strings.EqualFold(params.Action, "SELL") {
+		reward = -reward
+	}
+	if params.Target > 0 && params.Price > 0 && reward <= 0 {
+		side := "above"
+		if strings.EqualFold(params.Action, "SELL") {
+			side = "below"
 		}
+		issues = append(issues, fmt.Sprintf("TARGET: ₹%.2f must be %s the ₹%.2f entry for a %s", params.Target, side, params.Price, strings.ToUpper(params.Action)))
+	}
+
+	// Reward:risk check, when both exits are known
+	if limits.MinRiskReward > 0 && params.StopLoss > 0 && params.Target > 0 && reward > 0 {
+		if risk := abs(params.Price - params.StopLoss); risk > 0 {
+			if rr := reward / risk; rr < limits.MinRiskReward {
+				issues = append(issues, fmt.Sprintf("RISK_REWARD: 1:%.2f is below the 1:%g minimum", rr, limits.MinRiskReward))
+			}
+		}
+	}
+
+	// Confidence check, when the analysis confidence is given
+	if limits.MinConfidence > 0 && params.Confidence > 0 && params.Confidence < limits.MinConfidence {
+		issues = append(issues, fmt.Sprintf("CONFIDENCE: %.0f%% is below the %.0f%% minimum", params.Confidence*100, limits.MinConfidence*100))
 	}
 
 	// Basic sanity
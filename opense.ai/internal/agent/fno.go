@@ -62,7 +62,7 @@ func (a *FnOAgent) buildTools() []llm.Tool {
 		},
 		{
 			Name:        "analyze_option_chain",
-			Description: "Analyze option chain: compute max pain, IV skew, ATM IV, OI-based support/resistance, PCR sentiment",
+			Description: "Analyze option chain: compute max pain, IV skew, ATM IV, OI-based support/resistance, option-implied key support/resistance levels, PCR sentiment",
 			Parameters: llm.ObjectSchema("Analysis parameters",
 				map[string]*llm.JSONSchema{
 					"ticker": llm.StringProp("NSE ticker or index"),
@@ -132,7 +132,17 @@ func (a *FnOAgent) buildTools() []llm.Tool {
 
 // ── Tool Handlers ──
 
+// fetchOptionChain reads the chain from the agent's sources, which carry the
+// configured options route, falling back to NSE derivatives.
 func (a *FnOAgent) fetchOptionChain(ctx context.Context, ticker, expiry string) (*models.OptionChain, error) {
+	for _, src := range a.sources {
+		if chain, err := src.GetOptionChain(ctx, ticker, expiry); err == nil {
+			return chain, nil
+		}
+	}
+	if a.derivSrc == nil {
+		return nil, fmt.Errorf("no option chain available for %s", ticker)
+	}
 	return a.derivSrc.GetOptionChain(ctx, ticker, expiry)
 }
 
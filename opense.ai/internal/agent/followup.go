@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/seenimoa/openseai/internal/llm"
+)
+
+// maxFollowUpToolOutput caps each tool result quoted into follow-up context
+// from a single-agent run, in bytes.
+const maxFollowUpToolOutput = 2000
+
+// maxFollowUpSessions bounds how many sessions keep an analysis for
+// FollowUp; the session analyzed least recently is forgotten first.
+const maxFollowUpSessions = 64
+
+// followUpContext is a session's most recent successful analysis.
+type followUpContext struct {
+	query  string
+	result *AgentResult
+}
+
+type sessionKey struct{}
+
+// WithSession returns a context whose runs belong to the conversation id,
+// so that FollowUp answers from that conversation's last analysis rather
+// than another caller's. Runs without a session share one conversation.
+func WithSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, id)
+}
+
+// sessionFrom returns the conversation id carried by ctx, or "".
+func sessionFrom(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}
+
+// remember keeps a successful result as the context for FollowUp in the
+// session of ctx.
+func (o *Orchestrator) remember(ctx context.Context, query string, result *AgentResult, err error) {
+	if err != nil || result == nil {
+		return
+	}
+	id := sessionFrom(ctx)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.followUps == nil {
+		o.followUps = make(map[string]followUpContext)
+	}
+	if _, ok := o.followUps[id]; ok {
+		for i, s := range o.followUpOrder {
+			if s == id {
+				o.followUpOrder = append(o.followUpOrder[:i], o.followUpOrder[i+1:]...)
+				break
+			}
+		}
+	}
+	o.followUps[id] = followUpContext{query: query, result: result}
+	o.followUpOrder = append(o.followUpOrder, id)
+	if len(o.followUpOrder) > maxFollowUpSessions {
+		delete(o.followUps, o.followUpOrder[0])
+		o.followUpOrder = o.followUpOrder[1:]
+	}
+}
+
+// FollowUp answers a question about the most recent analysis run through
+// Process, QuickQuery, or FullAnalysis in the same session (see
+// WithSession). The CIO answers in one turn from the stored specialist
+// reports and final answer, so no agent or data tool runs again. It fails
+// if no analysis has completed in the session yet.
+func (o *Orchestrator) FollowUp(ctx context.Context, question string) (*AgentResult, error) {
+	o.mu.RLock()
+	prev, ok := o.followUps[sessionFrom(ctx)]
+	o.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no previous analysis to follow up on")
+	}
+	query, last := prev.query, prev.result
+
+	history := []llm.Message{
+		llm.UserMessage(buildFollowUpContext(query, last)),
+		llm.AssistantMessage(last.Content),
+	}
+	ctx, usage := o.trackUsage(ctx)
+	start := time.Now()
+	result, err := o.cio.ProcessWithMessages(ctx, question, history)
+	o.recordRun(ctx, ModeSingle, start, result)
+	attachUsage(result, usage)
+	return o.finalize(result, err)
+}
+
+// buildFollowUpContext restates the analysis behind last as the user turn
+// that the stored answer replies to: the original request, the
+// specialists' reports, and for a single-agent run its tool outputs.
+func buildFollowUpContext(query string, last *AgentResult) string {
+	var sb strings.Builder
+	sb.WriteString(query)
+	sb.WriteString("\n\nAnswer follow-up questions from the findings below and your answer; do not fetch new data.\n\n")
+
+	for _, r := range last.AgentResults {
+		if r == nil || r.Content == "" {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("### %s\n%s\n\n", r.Role, r.Content))
+	}
+	if len(last.AgentResults) == 0 {
+		for _, m := range last.Messages {
+			if m.Role != llm.RoleTool {
+				continue
+			}
+			out := m.Content
+			if len(out) > maxFollowUpToolOutput {
+				cut := maxFollowUpToolOutput
+				for cut > 0 && !utf8.RuneStart(out[cut]) {
+					cut--
+				}
+				out = out[:cut] + "…"
+			}
+			sb.WriteString(fmt.Sprintf("### Tool %s\n%s\n\n", m.Name, out))
+		}
+	}
+	if a := last.Analysis; a != nil && a.Recommendation != "" {
+		sb.WriteString(fmt.Sprintf("Composite recommendation: %s (confidence %.0f%%)\n", a.Recommendation, float64(a.Confidence)*100))
+	}
+	return sb.String()
+}
@@ -69,7 +69,7 @@ func (a *FundamentalAgent) buildTools() []llm.Tool {
 		},
 		{
 			Name:        "compute_ratios",
-			Description: "Compute financial ratios (PE, PB, ROE, ROCE, D/E, Current Ratio) from financial data",
+			Description: "Compute financial ratios (PE, PB, ROE, ROCE, D/E, Current Ratio, dividend yield, payout ratio) from financial data",
 			Parameters: llm.ObjectSchema("Ratio computation parameters",
 				map[string]*llm.JSONSchema{
 					"ticker":             llm.StringProp("NSE ticker symbol"),
@@ -116,6 +116,17 @@ func (a *FundamentalAgent) buildTools() []llm.Tool {
 			),
 			Handler: a.handleGetProfile,
 		},
+		{
+			Name:        "get_corporate_events",
+			Description: "Get upcoming and recent corporate events (results dates, dividends, splits, bonuses); flag earnings due soon",
+			Parameters: llm.ObjectSchema("Corporate events parameters",
+				map[string]*llm.JSONSchema{
+					"ticker": llm.StringProp("NSE ticker symbol"),
+				},
+				"ticker",
+			),
+			Handler: a.handleGetCorporateEvents,
+		},
 	}
 }
 
@@ -159,6 +170,36 @@ func (a *FundamentalAgent) handleGetQuote(ctx context.Context, args json.RawMess
 	return fmt.Sprintf("Could not fetch quote for %s", params.Ticker), nil
 }
 
+func (a *FundamentalAgent) handleGetCorporateEvents(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Ticker string `json:"ticker"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("parse args: %w", err)
+	}
+
+	for _, src := range a.dataSources {
+		es, ok := src.(datasource.CorporateEventSource)
+		if !ok {
+			continue
+		}
+		events, err := es.GetCorporateEvents(ctx, params.Ticker)
+		if err != nil {
+			continue
+		}
+		result := map[string]any{
+			"ticker": params.Ticker,
+			"events": events,
+		}
+		if next, ok := datasource.NextEvent(events, models.EventEarnings, time.Now()); ok {
+			result["next_earnings"] = next.Date.Format("2006-01-02")
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		return string(data), nil
+	}
+	return fmt.Sprintf("Could not fetch corporate events for %s", params.Ticker), nil
+}
+
 func (a *FundamentalAgent) handleComputeRatios(ctx context.Context, args json.RawMessage) (string, error) {
 	var params struct {
 		Ticker            string  `json:"ticker"`
@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GuardrailDisclaimer is appended to agent content that lacks a disclaimer.
+const GuardrailDisclaimer = "*Disclaimer: This analysis is generated by OpeNSE.ai for educational purposes only. " +
+	"It does not constitute financial advice. Please consult a SEBI-registered advisor before making investment decisions.*"
+
+// redactedPhrase replaces banned language in agent content.
+const redactedPhrase = "[redacted]"
+
+// bannedPhrases matches promissory language that must not appear in a
+// recommendation, such as "guaranteed returns" or "risk-free profit".
+var bannedPhrases = regexp.MustCompile(`(?i)\b(?:` +
+	`guaranteed\s+(?:returns?|profits?|gains?)|` +
+	`assured\s+(?:returns?|profits?|gains?)|` +
+	`risk[\s-]free\s+(?:returns?|profits?|gains?)|` +
+	`sure[\s-]shot|` +
+	`can(?:not|'t)\s+lose)\b`)
+
+// DefaultGuardrail redacts banned phrases from content and appends
+// GuardrailDisclaimer when no disclaimer is present.
+func DefaultGuardrail(content string) string {
+	content = bannedPhrases.ReplaceAllString(content, redactedPhrase)
+	if strings.Contains(strings.ToLower(content), "disclaimer") {
+		return content
+	}
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return GuardrailDisclaimer
+	}
+	return content + "\n\n---\n\n" + GuardrailDisclaimer
+}
@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"context"
+	"time"
+)
+
+// AgentRunInfo describes one agent's part in an orchestrator run.
+type AgentRunInfo struct {
+	Agent     string        `json:"agent"`
+	Model     string        `json:"model,omitempty"`
+	Provider  string        `json:"provider,omitempty"`
+	Tokens    int           `json:"tokens"`
+	Latency   time.Duration `json:"latency"`
+	ToolCalls int           `json:"tool_calls"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// RunMetadata records which agents ran in an orchestrator run and what
+// each of them used, for debugging and cost attribution.
+type RunMetadata struct {
+	Mode        OrchestratorMode `json:"mode"`
+	Agents      []AgentRunInfo   `json:"agents"`
+	TotalTokens int              `json:"total_tokens"`
+	Usage       UsageTotals      `json:"usage"` // every LLM call of the run, tool-loop round trips included
+	Duration    time.Duration    `json:"duration"`
+	FinishedAt  time.Time        `json:"finished_at"`
+}
+
+// LastRunMetadata returns the metadata of the most recently completed run.
+// When runs overlap, the last one to finish wins. It is the zero value
+// before the first run.
+func (o *Orchestrator) LastRunMetadata() RunMetadata {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	md := o.lastRun
+	md.Agents = append([]AgentRunInfo(nil), o.lastRun.Agents...)
+	return md
+}
+
+// recordRun stores the metadata of a finished run. Nil results, such as
+// a phase that did not run, are skipped. Usage is read from the
+// accumulator carried by ctx, if any.
+func (o *Orchestrator) recordRun(ctx context.Context, mode OrchestratorMode, start time.Time, results ...*AgentResult) {
+	md := RunMetadata{Mode: mode, Duration: time.Since(start), FinishedAt: time.Now()}
+	if u := usageFrom(ctx); u != nil {
+		md.Usage = u.Totals()
+	}
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		md.Agents = append(md.Agents, AgentRunInfo{
+			Agent:     r.AgentName,
+			Model:     r.Model,
+			Provider:  r.Provider,
+			Tokens:    r.Tokens,
+			Latency:   r.Duration,
+			ToolCalls: r.ToolCalls,
+			Error:     r.Error,
+		})
+		md.TotalTokens += r.Tokens
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.lastRun = md
+}
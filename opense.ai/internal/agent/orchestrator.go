@@ -44,10 +44,28 @@ type Orchestrator struct {
 
 	// LLM provider
 	provider llm.LLMProvider
+	model    string // configured model, used for cost estimates
+
+	// Market data the agents read
+	aggregator *datasource.Aggregator
 
 	// Config
 	defaultMode   OrchestratorMode
 	defaultCapital float64 // default trading capital in ₹
+	postProcess   func(string) string
+	customPost    bool // postProcess was configured rather than DefaultGuardrail
+	fallbackToQuick bool
+	debateThreshold float64
+	agentTimeout    time.Duration
+	minConfidence   float64
+
+	lastRun RunMetadata // guarded by mu
+
+	// Each session's most recent successful analysis, answered from by
+	// FollowUp, and the sessions from least to most recently analyzed;
+	// guarded by mu.
+	followUps     map[string]followUpContext
+	followUpOrder []string
 }
 
 // OrchestratorConfig holds configuration for creating an Orchestrator.
@@ -57,16 +75,71 @@ type OrchestratorConfig struct {
 	ChatOptions *llm.ChatOptions
 	DefaultMode OrchestratorMode
 	Capital     float64 // default trading capital in ₹
+
+	// DisabledTools lists tool names (e.g. "create_trade_proposal") that are
+	// removed from every agent, so the LLM can never invoke them.
+	DisabledTools []string
+
+	// PostProcess is applied to the content of every result returned by the
+	// orchestrator's public methods. Defaults to DefaultGuardrail.
+	PostProcess func(string) string
+
+	// FallbackToQuick makes multi-agent analysis fall back to a single-agent
+	// QuickQuery when it errors or CIO synthesis fails. The quick result is
+	// returned with Degraded set.
+	FallbackToQuick bool
+
+	// DebateThreshold enables a CIO debate round in multi-agent analysis.
+	// When one specialist's signal score is bullish, another's is bearish,
+	// and the two are at least this far apart (scores run −1 to +1), the
+	// CIO is asked to adjudicate and the verdict is recorded in
+	// AgentResult.Debate. Zero disables the round.
+	DebateThreshold float64
+
+	// MaxParallelTools bounds how many tool calls from a single LLM turn
+	// an agent runs concurrently, so a burst of calls cannot overwhelm the
+	// data sources. Zero uses DefaultMaxParallelTools; a negative value
+	// removes the limit.
+	MaxParallelTools int
+
+	// AgentTimeout bounds each specialist in multi-agent analysis,
+	// including its MinConfidence re-prompt. An agent still running when
+	// it expires is cancelled and contributes a "timed out" placeholder,
+	// and the CIO synthesizes from the others; a re-prompt still running
+	// is abandoned and the first answer kept. Zero leaves agents bounded
+	// only by the caller's context.
+	AgentTimeout time.Duration
+
+	// MinConfidence is the confidence floor (0–1) for specialist analyses
+	// in multi-agent mode. A specialist whose output is empty or whose
+	// parsed confidence is below it is re-prompted once to be more
+	// decisive, and the second answer is used. Zero disables the retry.
+	MinConfidence float64
+
+	// ExecutorLimits sets the thresholds the executor's validate_trade tool
+	// enforces. Nil uses DefaultExecutorLimits.
+	ExecutorLimits *ExecutorLimits
 }
 
+// DefaultMaxParallelTools is the tool-call concurrency used when
+// OrchestratorConfig.MaxParallelTools is zero.
+const DefaultMaxParallelTools = 4
+
 // NewOrchestrator creates a fully configured Orchestrator with all specialized agents.
 func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
-	sources := cfg.Aggregator.Sources()
+	// Agents read through the aggregator so configured routes apply.
+	sources := []datasource.DataSource{cfg.Aggregator}
 
 	o := &Orchestrator{
 		provider:       cfg.Provider,
+		aggregator:     cfg.Aggregator,
 		defaultMode:    cfg.DefaultMode,
 		defaultCapital: cfg.Capital,
+		postProcess:    cfg.PostProcess,
+		fallbackToQuick: cfg.FallbackToQuick,
+		debateThreshold: cfg.DebateThreshold,
+		agentTimeout:    cfg.AgentTimeout,
+		minConfidence:   cfg.MinConfidence,
 	}
 
 	if o.defaultMode == "" {
@@ -75,8 +148,16 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 	if o.defaultCapital <= 0 {
 		o.defaultCapital = 1_000_000 // ₹10 Lakh default
 	}
+	if o.postProcess == nil {
+		o.postProcess = DefaultGuardrail
+	} else {
+		o.customPost = true
+	}
 
 	opts := cfg.ChatOptions
+	if opts != nil {
+		o.model = opts.Model
+	}
 
 	// Create specialized agents
 	o.fundamental = NewFundamentalAgent(cfg.Provider, sources, opts)
@@ -84,9 +165,16 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 	o.sentiment = NewSentimentAgent(cfg.Provider, cfg.Aggregator.NewsSource(), opts)
 	o.fno = NewFnOAgent(cfg.Provider, cfg.Aggregator.Derivatives(), sources, opts)
 	o.risk = NewRiskAgent(cfg.Provider, sources, opts)
-	o.executor = NewExecutorAgent(cfg.Provider, opts)
+	o.executor = NewExecutorAgent(cfg.Provider, opts, cfg.ExecutorLimits)
 	o.reporter = NewReporterAgent(cfg.Provider, opts)
 
+	for _, a := range []*BaseAgent{
+		o.fundamental.BaseAgent, o.technical.BaseAgent, o.sentiment.BaseAgent,
+		o.fno.BaseAgent, o.risk.BaseAgent, o.executor.BaseAgent, o.reporter.BaseAgent,
+	} {
+		a.DisableTools(cfg.DisabledTools...)
+	}
+
 	// Create CIO agent for multi-agent coordination
 	o.cio = NewBaseAgent(BaseAgentConfig{
 		Name:         prompts.AgentCIO,
@@ -101,6 +189,20 @@ func NewOrchestrator(cfg OrchestratorConfig) *Orchestrator {
 	// Create single-agent with all tools combined
 	o.buildSingleAgent(cfg.Provider, opts)
 
+	maxParallel := cfg.MaxParallelTools
+	if maxParallel == 0 {
+		maxParallel = DefaultMaxParallelTools
+	} else if maxParallel < 0 {
+		maxParallel = 0
+	}
+	for _, a := range []*BaseAgent{
+		o.fundamental.BaseAgent, o.technical.BaseAgent, o.sentiment.BaseAgent,
+		o.fno.BaseAgent, o.risk.BaseAgent, o.executor.BaseAgent, o.reporter.BaseAgent,
+		o.cio, o.singleAgent,
+	} {
+		a.SetMaxParallelTools(maxParallel)
+	}
+
 	return o
 }
 
@@ -164,37 +266,106 @@ func (o *Orchestrator) Process(ctx context.Context, query string) (*AgentResult,
 
 // ProcessWithMode handles a query with an explicit mode selection.
 func (o *Orchestrator) ProcessWithMode(ctx context.Context, query string, mode OrchestratorMode) (*AgentResult, error) {
+	ctx, usage := o.trackUsage(ctx)
+	var result *AgentResult
+	var err error
 	switch mode {
-	case ModeSingle:
-		return o.processSingle(ctx, query)
 	case ModeMulti:
-		return o.processMulti(ctx, query)
+		result, err = o.finalize(o.processMultiWithFallback(ctx, query))
 	default:
-		return o.processSingle(ctx, query)
+		result, err = o.finalize(o.processSingle(ctx, query))
 	}
+	attachUsage(result, usage)
+	o.remember(ctx, query, result, err)
+	return result, err
 }
 
 // QuickQuery runs a single-agent query (convenience method).
 func (o *Orchestrator) QuickQuery(ctx context.Context, query string) (*AgentResult, error) {
-	return o.processSingle(ctx, query)
+	return o.ProcessWithMode(ctx, query, ModeSingle)
 }
 
 // FullAnalysis runs a multi-agent analysis for a ticker (convenience method).
 func (o *Orchestrator) FullAnalysis(ctx context.Context, ticker string) (*AgentResult, error) {
 	query := fmt.Sprintf("Perform a comprehensive investment analysis of %s for the Indian market.", ticker)
-	return o.processMulti(ctx, query)
+	return o.ProcessWithMode(ctx, query, ModeMulti)
 }
 
 // Chat handles an interactive chat message with conversation history.
 func (o *Orchestrator) Chat(ctx context.Context, message string, history []llm.Message) (*AgentResult, error) {
-	return o.singleAgent.ProcessWithMessages(ctx, message, history)
+	ctx, usage := o.trackUsage(ctx)
+	start := time.Now()
+	result, err := o.singleAgent.ProcessWithMessages(ctx, message, history)
+	o.recordRun(ctx, ModeSingle, start, result)
+	attachUsage(result, usage)
+	return o.finalize(result, err)
+}
+
+// trackUsage returns a context that meters every LLM call of one run into
+// a fresh accumulator, which is also returned.
+func (o *Orchestrator) trackUsage(ctx context.Context) (context.Context, *UsageAccumulator) {
+	u := NewUsageAccumulator(o.model)
+	return withUsage(ctx, u), u
+}
+
+// attachUsage sets the run's usage totals on its result.
+func attachUsage(result *AgentResult, u *UsageAccumulator) {
+	if result == nil {
+		return
+	}
+	totals := u.Totals()
+	result.Usage = &totals
+}
+
+// finalize applies the configured post-processor to a successful result
+// and to the content of each agent result nested in it.
+func (o *Orchestrator) finalize(result *AgentResult, err error) (*AgentResult, error) {
+	if err != nil || result == nil || o.postProcess == nil {
+		return result, err
+	}
+	result.Content = o.postProcess(result.Content)
+	for _, r := range result.AgentResults {
+		if r != nil && r.Content != "" {
+			r.Content = o.postProcess(r.Content)
+		}
+	}
+	return result, nil
 }
 
 // ── Internal modes ──
 
 // processSingle routes the query to the single all-tools agent.
 func (o *Orchestrator) processSingle(ctx context.Context, query string) (*AgentResult, error) {
-	return o.singleAgent.Process(ctx, query)
+	start := time.Now()
+	result, err := o.singleAgent.Process(ctx, query)
+	o.recordRun(ctx, ModeSingle, start, result)
+	return result, err
+}
+
+// processMultiWithFallback runs processMulti and, when FallbackToQuick is
+// set and the multi-agent run errored or degraded, answers the query with
+// the single agent instead. If the quick query also fails, the multi-agent
+// outcome is returned unchanged.
+func (o *Orchestrator) processMultiWithFallback(ctx context.Context, query string) (*AgentResult, error) {
+	result, err := o.processMulti(ctx, query)
+	if !o.fallbackToQuick || ctx.Err() != nil {
+		return result, err
+	}
+	if err == nil && result != nil && !result.Degraded {
+		return result, nil
+	}
+
+	quick, qerr := o.processSingle(ctx, query)
+	if qerr != nil {
+		return result, err
+	}
+	quick.Content = "*Note: Multi-agent analysis failed. Presenting a quick single-agent analysis.*\n\n" + quick.Content
+	quick.Degraded = true
+	if result != nil {
+		quick.Duration += result.Duration
+		quick.ToolCalls += result.ToolCalls
+	}
+	return quick, nil
 }
 
 // processMulti runs the CIO-led multi-agent workflow.
@@ -209,86 +380,103 @@ func (o *Orchestrator) processMulti(ctx context.Context, query string) (*AgentRe
 
 	// Phase 1: Run specialized agents concurrently
 	type agentResult struct {
-		name   string
 		result *AgentResult
 		err    error
 	}
 
-	ch := make(chan agentResult, 5)
 	var wg sync.WaitGroup
 
-	// Launch agents concurrently
+	// Launch agents concurrently, in canonical order (see specialistOrder)
 	agents := []struct {
-		name string
-		fn   func(context.Context, string) (*AgentResult, error)
+		name  string
+		agent *BaseAgent
+		fn    func(context.Context, string) (*AgentResult, error)
 	}{
-		{"fundamental", func(ctx context.Context, t string) (*AgentResult, error) {
+		{"fundamental", o.fundamental.BaseAgent, func(ctx context.Context, t string) (*AgentResult, error) {
 			return o.fundamental.AnalyzeWithTimestamp(ctx, t)
 		}},
-		{"technical", func(ctx context.Context, t string) (*AgentResult, error) {
+		{"technical", o.technical.BaseAgent, func(ctx context.Context, t string) (*AgentResult, error) {
 			return o.technical.AnalyzeWithTimestamp(ctx, t)
 		}},
-		{"sentiment", func(ctx context.Context, t string) (*AgentResult, error) {
+		{"sentiment", o.sentiment.BaseAgent, func(ctx context.Context, t string) (*AgentResult, error) {
 			return o.sentiment.AnalyzeWithTimestamp(ctx, t)
 		}},
-		{"fno", func(ctx context.Context, t string) (*AgentResult, error) {
+		{"fno", o.fno.BaseAgent, func(ctx context.Context, t string) (*AgentResult, error) {
 			return o.fno.AnalyzeWithTimestamp(ctx, t)
 		}},
-		{"risk", func(ctx context.Context, t string) (*AgentResult, error) {
+		{"risk", o.risk.BaseAgent, func(ctx context.Context, t string) (*AgentResult, error) {
 			return o.risk.AnalyzeWithTimestamp(ctx, t, o.defaultCapital)
 		}},
 	}
 
-	for _, a := range agents {
+	collected := make([]agentResult, len(agents))
+	for i, a := range agents {
 		wg.Add(1)
-		go func(name string, fn func(context.Context, string) (*AgentResult, error)) {
+		go func(i int, agent *BaseAgent, fn func(context.Context, string) (*AgentResult, error)) {
 			defer wg.Done()
-			result, err := fn(ctx, ticker)
-			ch <- agentResult{name: name, result: result, err: err}
-		}(a.name, a.fn)
+			result, err := o.runSpecialist(ctx, agent, ticker, fn)
+			collected[i] = agentResult{result: result, err: err}
+		}(i, a.agent, a.fn)
 	}
+	wg.Wait()
 
-	// Close channel once all agents complete
-	go func() {
-		wg.Wait()
-		close(ch)
-	}()
-
-	// Collect results
+	// Collect results in canonical order, regardless of completion order
 	results := make(map[string]*AgentResult)
 	var errors []string
-	for ar := range ch {
+	var ran []*AgentResult // every agent that ran, for run metadata
+	for i, ar := range collected {
+		name := agents[i].name
+		ran = append(ran, ar.result)
 		if ar.err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", ar.name, ar.err))
+			errors = append(errors, fmt.Sprintf("%s: %v", name, ar.err))
 			continue
 		}
-		results[ar.name] = ar.result
+		ar.result.ID = agentResultID(ticker, name)
+		results[name] = ar.result
 	}
 
 	// Phase 2: CIO synthesis
 	synthesisTask := buildSynthesisPrompt(ticker, query, results, errors)
 	cioResult, err := o.cio.Process(ctx, synthesisTask)
+	ran = append(ran, cioResult)
 	if err != nil {
 		// If CIO fails, try to compile results manually
+		o.recordRun(ctx, ModeMulti, start, ran...)
 		return compileFallbackResult(ticker, results, errors, start), nil
 	}
 
-	// Phase 3: Generate report
-	allResults := []*AgentResult{}
-	for _, r := range results {
-		allResults = append(allResults, r)
+	// Phase 2b: Debate round when specialists disagree
+	var debate *DebateRound
+	var verdict *AgentResult
+	if o.debateThreshold > 0 {
+		if d := detectDebate(results, o.debateThreshold); d != nil {
+			v, err := o.cio.Process(ctx, buildDebatePrompt(ticker, d, results, cioResult.Content))
+			if err == nil && v != nil {
+				d.Verdict = v.Content
+				debate, verdict = d, v
+			}
+		}
 	}
+
+	// Phase 3: Generate report
+	allResults := orderedResults(results)
 	if cioResult != nil {
 		allResults = append(allResults, cioResult)
 	}
+	if verdict != nil {
+		allResults = append(allResults, verdict)
+	}
 
 	reportResult, reportErr := o.reporter.GenerateReport(ctx, ticker, allResults)
+	o.recordRun(ctx, ModeMulti, start, append(ran, verdict, reportResult)...)
 
 	// Build final orchestrator result
 	final := &AgentResult{
-		AgentName: "orchestrator",
-		Role:      "Multi-Agent Orchestrator",
-		Duration:  time.Since(start),
+		ID:           agentResultID(ticker, "orchestrator"),
+		AgentName:    "orchestrator",
+		Role:         "Multi-Agent Orchestrator",
+		Duration:     time.Since(start),
+		AgentResults: orderedResults(results),
 	}
 
 	if reportErr == nil && reportResult != nil {
@@ -297,6 +485,9 @@ func (o *Orchestrator) processMulti(ctx context.Context, query string) (*AgentRe
 	} else {
 		final.Content = cioResult.Content
 		final.Tokens = cioResult.Tokens
+		if verdict != nil {
+			final.Content += "\n\n## CIO Debate Verdict\n\n" + verdict.Content
+		}
 	}
 
 	// Count total tool calls across all agents
@@ -304,18 +495,125 @@ func (o *Orchestrator) processMulti(ctx context.Context, query string) (*AgentRe
 		final.ToolCalls += r.ToolCalls
 	}
 	final.ToolCalls += cioResult.ToolCalls
+	if verdict != nil {
+		final.ToolCalls += verdict.ToolCalls
+		final.Debate = debate
+	}
 
 	// Attach composite analysis
-	final.Analysis = &models.AnalysisResult{
-		Ticker:    ticker,
-		Type:      models.AnalysisComposite,
-		AgentName: "orchestrator",
-		Timestamp: time.Now(),
-	}
+	final.Analysis = buildCompositeAnalysis(ticker, results)
 
 	return final, nil
 }
 
+// runSpecialist runs one specialist of a multi-agent analysis, followed by
+// its retryIfWeak re-prompt. With an agent timeout set, the timeout bounds
+// both: an agent that overruns it before answering is cancelled and
+// abandoned, and a placeholder result saying it timed out is returned in
+// its place; a re-prompt that overruns it is abandoned and the first
+// answer kept.
+func (o *Orchestrator) runSpecialist(ctx context.Context, agent *BaseAgent, ticker string, fn func(context.Context, string) (*AgentResult, error)) (*AgentResult, error) {
+	if o.agentTimeout <= 0 {
+		result, err := fn(ctx, ticker)
+		if err != nil {
+			return result, err
+		}
+		return o.retryIfWeak(ctx, agent, result), nil
+	}
+
+	actx, cancel := context.WithTimeout(ctx, o.agentTimeout)
+	defer cancel()
+
+	type outcome struct {
+		result *AgentResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		result, err := fn(actx, ticker)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		if out.err == nil {
+			return o.retryWithin(actx, agent, out.result), nil
+		}
+		if actx.Err() == nil || ctx.Err() != nil {
+			return out.result, out.err
+		}
+		// Failed because the timeout cancelled it.
+	case <-actx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return &AgentResult{
+		AgentName: agent.Name(),
+		Role:      agent.Role(),
+		Content:   fmt.Sprintf("[timed out] This agent did not finish within %s; no analysis is available from it.", o.agentTimeout),
+		Duration:  time.Since(start),
+		Error:     "timed out",
+	}, nil
+}
+
+// retryWithin runs retryIfWeak until actx is done, keeping result if the
+// re-prompt has not finished by then.
+func (o *Orchestrator) retryWithin(actx context.Context, agent *BaseAgent, result *AgentResult) *AgentResult {
+	retried := make(chan *AgentResult, 1)
+	go func() { retried <- o.retryIfWeak(actx, agent, result) }()
+	select {
+	case r := <-retried:
+		return r
+	case <-actx.Done():
+		return result
+	}
+}
+
+// decisivePrompt re-prompts a specialist whose answer was empty or fell
+// below the confidence floor.
+const decisivePrompt = "Your previous answer was empty or not confident enough to act on. " +
+	"Using the data you have already gathered, commit to a clear view: state a recommendation, " +
+	"a confidence between 0 and 1, and the key signals behind it, in the same JSON format."
+
+// retryIfWeak re-prompts a specialist once, with its conversation so far,
+// when MinConfidence is set and its result is empty or below the floor.
+// The second answer replaces the first; if the retry fails, the first is
+// kept. Timed-out placeholders are never retried.
+func (o *Orchestrator) retryIfWeak(ctx context.Context, agent *BaseAgent, result *AgentResult) *AgentResult {
+	if o.minConfidence <= 0 || result == nil || result.Error != "" || !o.isWeak(result) {
+		return result
+	}
+
+	history := append([]llm.Message(nil), result.Messages[min(1, len(result.Messages)):]...) // drop the system prompt
+	history = append(history, llm.AssistantMessage(result.Content))
+	retry, err := agent.ProcessWithMessages(ctx, decisivePrompt, history)
+	if err != nil || retry == nil {
+		return result
+	}
+
+	defaults := models.AnalysisResult{AgentName: agent.Name()}
+	if result.Analysis != nil {
+		defaults.Ticker, defaults.Type = result.Analysis.Ticker, result.Analysis.Type
+	}
+	retry.Analysis = ParseAnalysisResult(retry.Content, defaults)
+	retry.Tokens += result.Tokens
+	retry.ToolCalls += result.ToolCalls
+	retry.Duration += result.Duration
+	retry.Retried = true
+	return retry
+}
+
+// isWeak reports whether a specialist result is empty or less confident
+// than MinConfidence.
+func (o *Orchestrator) isWeak(r *AgentResult) bool {
+	if strings.TrimSpace(r.Content) == "" {
+		return true
+	}
+	return r.Analysis == nil || float64(r.Analysis.Confidence) < o.minConfidence
+}
+
 // buildSynthesisPrompt creates the CIO synthesis task from agent results.
 func buildSynthesisPrompt(ticker, originalQuery string, results map[string]*AgentResult, errors []string) string {
 	var sb strings.Builder
@@ -325,7 +623,11 @@ func buildSynthesisPrompt(ticker, originalQuery string, results map[string]*Agen
 	sb.WriteString(prompts.CoTSynthesis(ticker))
 	sb.WriteString("\n\nHere are the analysis results from your team:\n\n")
 
-	for name, r := range results {
+	for _, name := range specialistOrder {
+		r, ok := results[name]
+		if !ok {
+			continue
+		}
 		sb.WriteString(fmt.Sprintf("### %s Agent (%s)\n", strings.Title(name), r.Role))
 		sb.WriteString(r.Content)
 		sb.WriteString("\n\n---\n\n")
@@ -349,14 +651,44 @@ func buildSynthesisPrompt(ticker, originalQuery string, results map[string]*Agen
 	return sb.String()
 }
 
+// buildDebatePrompt asks the CIO to adjudicate between the most bullish and
+// most bearish specialists, given its own synthesis.
+func buildDebatePrompt(ticker string, d *DebateRound, results map[string]*AgentResult, synthesis string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Your analysts disagree on %s and you must adjudicate.\n\n", ticker))
+	sb.WriteString(fmt.Sprintf("The %s agent is bullish (signal score %+.2f) and the %s agent is bearish (signal score %+.2f).\n\n",
+		strings.Title(d.Bullish), d.BullishScore, strings.Title(d.Bearish), d.BearishScore))
+
+	for _, name := range []string{d.Bullish, d.Bearish} {
+		sb.WriteString(fmt.Sprintf("### %s Agent (%s)\n", strings.Title(name), results[name].Role))
+		sb.WriteString(results[name].Content)
+		sb.WriteString("\n\n---\n\n")
+	}
+
+	sb.WriteString("### Your Initial Synthesis\n")
+	sb.WriteString(synthesis)
+	sb.WriteString("\n\n---\n\n")
+
+	sb.WriteString("Reconcile the two views explicitly:\n" +
+		"1. The strongest argument on each side\n" +
+		"2. Which view is better supported by the evidence, and why\n" +
+		"3. Whether your overall recommendation stands or changes\n" +
+		"4. What would have to happen for the losing view to prevail\n")
+
+	return sb.String()
+}
+
 // compileFallbackResult creates a summary when the CIO agent fails.
 func compileFallbackResult(ticker string, results map[string]*AgentResult, errors []string, start time.Time) *AgentResult {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("# Multi-Agent Analysis: %s\n\n", ticker))
 	sb.WriteString("*Note: CIO synthesis unavailable. Presenting raw agent outputs.*\n\n")
 
-	for name, r := range results {
-		sb.WriteString(fmt.Sprintf("## %s Agent\n%s\n\n", strings.Title(name), r.Content))
+	for _, name := range specialistOrder {
+		if r, ok := results[name]; ok {
+			sb.WriteString(fmt.Sprintf("## %s Agent\n%s\n\n", strings.Title(name), r.Content))
+		}
 	}
 
 	if len(errors) > 0 {
@@ -372,20 +704,40 @@ func compileFallbackResult(ticker string, results map[string]*AgentResult, error
 	}
 
 	return &AgentResult{
-		AgentName: "orchestrator",
-		Role:      "Multi-Agent Orchestrator (fallback)",
-		Content:   sb.String(),
-		ToolCalls: totalTools,
-		Duration:  time.Since(start),
-		Analysis: &models.AnalysisResult{
-			Ticker:    ticker,
-			Type:      models.AnalysisComposite,
-			AgentName: "orchestrator",
-			Timestamp: time.Now(),
-		},
+		ID:           agentResultID(ticker, "orchestrator"),
+		AgentName:    "orchestrator",
+		Role:         "Multi-Agent Orchestrator (fallback)",
+		Content:      sb.String(),
+		ToolCalls:    totalTools,
+		Duration:     time.Since(start),
+		Analysis:     buildCompositeAnalysis(ticker, results),
+		Degraded:     true,
+		AgentResults: orderedResults(results),
 	}
 }
 
+// specialistOrder is the canonical order of specialist agents in
+// multi-agent results, prompts, and reports.
+var specialistOrder = []string{"fundamental", "technical", "sentiment", "fno", "risk"}
+
+// orderedResults returns the specialist results in specialistOrder,
+// skipping agents that produced none.
+func orderedResults(results map[string]*AgentResult) []*AgentResult {
+	out := make([]*AgentResult, 0, len(results))
+	for _, name := range specialistOrder {
+		if r, ok := results[name]; ok {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// agentResultID returns the stable ID of an agent's result for ticker,
+// e.g. "TCS/fundamental".
+func agentResultID(ticker, agent string) string {
+	return ticker + "/" + agent
+}
+
 // extractTicker attempts to extract an NSE ticker from a query string.
 // It looks for known patterns and uppercase words that look like tickers.
 func extractTicker(query string) string {
@@ -446,6 +798,13 @@ func (o *Orchestrator) ExecutorAgent() *ExecutorAgent { return o.executor }
 // ReporterAgent returns the report generator agent.
 func (o *Orchestrator) ReporterAgent() *ReporterAgent { return o.reporter }
 
+// Aggregator returns the market data source the agents read.
+func (o *Orchestrator) Aggregator() *datasource.Aggregator { return o.aggregator }
+
+// Tools returns the de-duplicated tools of all specialized agents, as
+// available to the single agent.
+func (o *Orchestrator) Tools() []llm.Tool { return o.singleAgent.Tools() }
+
 // SetMode sets the default orchestration mode.
 func (o *Orchestrator) SetMode(mode OrchestratorMode) {
 	o.mu.Lock()
@@ -453,6 +812,22 @@ func (o *Orchestrator) SetMode(mode OrchestratorMode) {
 	o.defaultMode = mode
 }
 
+// SetInstructions layers extra system instructions, such as a persona from
+// prompts.Persona, over the default system prompts of the single agent and
+// the CIO, which together produce every user-facing answer. An empty string
+// restores the defaults. Call it before the orchestrator is used.
+func (o *Orchestrator) SetInstructions(extra string) {
+	o.singleAgent.systemPrompt = withInstructions(buildSingleAgentPrompt(), extra)
+	o.cio.systemPrompt = withInstructions(prompts.CIOSystemPrompt+prompts.IndianMarketPromptSuffix(), extra)
+}
+
+func withInstructions(base, extra string) string {
+	if strings.TrimSpace(extra) == "" {
+		return base
+	}
+	return base + "\n\n## Additional Instructions\n" + strings.TrimSpace(extra) + "\n"
+}
+
 // Mode returns the current default mode.
 func (o *Orchestrator) Mode() OrchestratorMode {
 	o.mu.RLock()
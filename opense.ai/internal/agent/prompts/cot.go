@@ -101,7 +101,8 @@ Think step-by-step:
 **Step 7 — Trade Setup**
 - Entry price, target price, stop-loss (ATR-based)
 - Risk-reward ratio
-- BUY/SELL/NEUTRAL signal with confidence`, ticker)
+- BUY/SELL/NEUTRAL signal with confidence
+- End with a JSON block whose "details" give "entry_price", "target_price", "stop_loss", and "atr" as numbers`, ticker)
 }
 
 // CoTDerivatives is a chain-of-thought template for F&O analysis.
@@ -177,7 +178,8 @@ Think step-by-step:
 
 **Step 6 — Decision**
 - Approve/Reject/Modify the trade with specific conditions
-- If approved: exact quantity, entry range, stop-loss, targets`, ticker, capitalINR, capitalINR*0.05)
+- If approved: exact quantity, entry range, stop-loss, targets
+- End with a JSON block whose "details" give "target_price", "stop_loss", and "atr" as numbers`, ticker, capitalINR, capitalINR*0.05)
 }
 
 // CoTSynthesis is a chain-of-thought template for the CIO synthesizing all analyses.
@@ -0,0 +1,41 @@
+package prompts
+
+// ── Analysis Focus ──
+
+// focuses holds the emphasis instructions for a focused analysis, layered
+// over an agent's default system prompt.
+var focuses = map[string]string{
+	"valuation": `## Analysis Focus: Valuation
+- Lead with valuation: P/E, P/B, EV/EBITDA, and dividend yield against the stock's own 5-year range and its sector peers
+- Judge whether earnings growth and return ratios (ROE, ROCE) justify the current multiples
+- State a fair-value range and the margin of safety at the current price
+- Cover technicals, sentiment, and risk only briefly, as context for the valuation call`,
+
+	"momentum": `## Analysis Focus: Momentum
+- Lead with price momentum: trend direction, moving-average alignment, RSI, MACD, and relative strength against the Nifty
+- Confirm moves with volume and note breakouts, breakdowns, and divergences
+- Give entry, stop-loss, and target levels for the prevailing trend
+- Cover fundamentals and valuation only briefly, as context for the momentum call`,
+
+	"sentiment": `## Analysis Focus: Sentiment
+- Lead with sentiment: recent news flow, analyst actions, FII/DII activity, and promoter buying or pledging
+- Use F&O positioning (PCR, open interest build-up) as a read on trader sentiment where available
+- Separate durable narrative shifts from one-day noise
+- Cover valuation and technicals only briefly, as context for the sentiment call`,
+
+	"risk": `## Analysis Focus: Risk
+- Lead with what can go wrong: volatility, drawdown history, leverage, governance, and concentration risks
+- Quantify downside with ATR-based stops, VaR, and position sizing for a given capital
+- Flag event risk — results dates, F&O expiry, regulatory actions — in the holding period
+- Cover upside drivers only briefly, as context for the risk call`,
+}
+
+// Focus returns the emphasis instructions for the named analysis focus.
+func Focus(name string) (string, error) {
+	return lookupPrompt("focus", focuses, name)
+}
+
+// FocusNames returns the names of the analysis focuses, sorted.
+func FocusNames() []string {
+	return promptNames(focuses)
+}
@@ -0,0 +1,64 @@
+package prompts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ── Chat Personas ──
+
+// personas holds the bundled persona prompts, layered over an agent's
+// default system prompt to set its investing style.
+var personas = map[string]string{
+	"conservative": `## Persona: Conservative Long-Term Investor
+- Favour quality businesses with durable moats, low leverage, and consistent cash flows
+- Think in years, not weeks: weigh valuation and fundamentals above short-term price action
+- Prefer large caps and diversified positions; flag small caps, high beta, and F&O as high risk
+- Recommend staggered entries (SIP-style accumulation) and capital protection over maximising return
+- Be explicit about downside scenarios and when to stay in cash`,
+
+	"aggressive": `## Persona: Aggressive Trader
+- Focus on momentum, breakouts, volume surges, and short-term catalysts
+- Holding periods of days to a few weeks; intraday and F&O strategies are in scope
+- Always give precise entry, stop-loss, and target levels with the risk–reward ratio
+- Size positions by the stop distance, and state the maximum loss per trade in ₹
+- Call out event risk (results, expiry, policy announcements) that could gap the price`,
+
+	"quant": `## Persona: Quantitative Analyst
+- Ground every view in numbers: indicator values, z-scores, percentiles, volatility, and correlations
+- State the lookback window and data source for each statistic you cite
+- Prefer rules that could be backtested; describe signals as explicit conditions
+- Quantify uncertainty with ranges or probabilities rather than adjectives
+- Avoid narrative reasoning that the data does not support`,
+}
+
+// Persona returns the bundled prompt for the named persona.
+func Persona(name string) (string, error) {
+	return lookupPrompt("persona", personas, name)
+}
+
+// PersonaNames returns the names of the bundled personas, sorted.
+func PersonaNames() []string {
+	return promptNames(personas)
+}
+
+// lookupPrompt returns the named entry of a prompt set, matching the name
+// case-insensitively. kind names the set in the error.
+func lookupPrompt(kind string, set map[string]string, name string) (string, error) {
+	p, ok := set[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("unknown %s %q (available: %s)", kind, name, strings.Join(promptNames(set), ", "))
+	}
+	return p, nil
+}
+
+// promptNames returns the names in a prompt set, sorted.
+func promptNames(set map[string]string) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/seenimoa/openseai/internal/agent/prompts"
+	"github.com/seenimoa/openseai/internal/analysis/technical"
 	"github.com/seenimoa/openseai/internal/datasource"
 	"github.com/seenimoa/openseai/internal/llm"
 	"github.com/seenimoa/openseai/pkg/models"
@@ -219,12 +220,7 @@ func (a *RiskAgent) handleComputeVaR(ctx context.Context, args json.RawMessage)
 	}
 
 	// Compute daily returns
-	returns := make([]float64, len(candles)-1)
-	for i := 1; i < len(candles); i++ {
-		if candles[i-1].Close > 0 {
-			returns[i-1] = (candles[i].Close - candles[i-1].Close) / candles[i-1].Close
-		}
-	}
+	returns := technical.DailyReturns(candles)
 
 	// Sort returns for percentile-based VaR
 	sortedReturns := make([]float64, len(returns))
@@ -243,16 +239,9 @@ func (a *RiskAgent) handleComputeVaR(ctx context.Context, args json.RawMessage)
 	holdingVaRPct := dailyVaRPct * math.Sqrt(float64(params.HoldingDays))
 	varAmount := params.PositionSize * holdingVaRPct
 
-	// Compute volatility
-	var sum, sumSq float64
-	for _, r := range returns {
-		sum += r
-		sumSq += r * r
-	}
-	mean := sum / float64(len(returns))
-	variance := sumSq/float64(len(returns)) - mean*mean
-	dailyVol := math.Sqrt(variance)
-	annualVol := dailyVol * math.Sqrt(252)
+	// Compute volatility (EWMA, so recent moves weigh more)
+	dailyVol := technical.ComputeEWMAVol(returns, technical.DefaultEWMALambda)
+	annualVol := technical.AnnualizeVol(dailyVol)
 
 	result := map[string]any{
 		"ticker":            params.Ticker,
@@ -301,18 +290,15 @@ func (a *RiskAgent) handleSuggestStopLoss(ctx context.Context, args json.RawMess
 		return fmt.Sprintf("Insufficient data for %s ATR-based stop-loss (need ≥14 days)", params.Ticker), nil
 	}
 
-	// Compute 14-day ATR manually
-	atr := computeATR(candles, 14)
+	atr := computeATR(candles, StopATRPeriod)
 
-	var sl1, sl2, sl3 float64
-	if params.Direction == "long" {
-		sl1 = params.EntryPrice - 1.5*atr // tight
-		sl2 = params.EntryPrice - 2.0*atr // moderate
-		sl3 = params.EntryPrice - 3.0*atr // wide
-	} else {
-		sl1 = params.EntryPrice + 1.5*atr
-		sl2 = params.EntryPrice + 2.0*atr
-		sl3 = params.EntryPrice + 3.0*atr
+	stopLevels := make(map[string]any)
+	for _, l := range SuggestStops(params.EntryPrice, atr, params.Direction == "long") {
+		stopLevels[l.Name] = map[string]any{
+			"price":      l.Price,
+			"multiplier": fmt.Sprintf("%.1fx ATR", l.Multiplier),
+			"risk_pct":   fmt.Sprintf("%.2f%%", l.RiskPct),
+		}
 	}
 
 	result := map[string]any{
@@ -320,11 +306,7 @@ func (a *RiskAgent) handleSuggestStopLoss(ctx context.Context, args json.RawMess
 		"entry_price": params.EntryPrice,
 		"direction":   params.Direction,
 		"atr_14":      fmt.Sprintf("%.2f", atr),
-		"stop_levels": map[string]any{
-			"tight":    map[string]any{"price": math.Round(sl1*100) / 100, "multiplier": "1.5x ATR", "risk_pct": fmt.Sprintf("%.2f%%", math.Abs(sl1-params.EntryPrice)/params.EntryPrice*100)},
-			"moderate": map[string]any{"price": math.Round(sl2*100) / 100, "multiplier": "2.0x ATR", "risk_pct": fmt.Sprintf("%.2f%%", math.Abs(sl2-params.EntryPrice)/params.EntryPrice*100)},
-			"wide":     map[string]any{"price": math.Round(sl3*100) / 100, "multiplier": "3.0x ATR", "risk_pct": fmt.Sprintf("%.2f%%", math.Abs(sl3-params.EntryPrice)/params.EntryPrice*100)},
-		},
+		"stop_levels": stopLevels,
 		"recommendation": "Use 2.0x ATR for swing trades, 1.5x for intraday, 3.0x for positional",
 	}
 
@@ -508,6 +490,7 @@ func (a *RiskAgent) AnalyzeWithTimestamp(ctx context.Context, ticker string, cap
 		AgentName: a.Name(),
 		Timestamp: time.Now(),
 	})
+	setComputedATR(ctx, result.Analysis, a.dataSources)
 
 	return result, nil
 }
@@ -83,8 +83,10 @@ func (a *SentimentAgent) buildTools() []llm.Tool {
 			Description: "Run full sentiment analysis: score all articles, aggregate sentiment, detect catalysts, and produce an overall sentiment rating",
 			Parameters: llm.ObjectSchema("Sentiment analysis parameters",
 				map[string]*llm.JSONSchema{
-					"ticker": llm.StringProp("NSE ticker symbol"),
-					"limit":  llm.IntProp("Number of articles to analyze (default: 20)"),
+					"ticker":          llm.StringProp("NSE ticker symbol"),
+					"limit":           llm.IntProp("Number of articles to analyze (default: 20)"),
+					"lookback_days":   llm.IntProp("Ignore articles older than this many days (default: all)"),
+					"half_life_hours": llm.NumberProp("Recency decay: an article's weight halves every this many hours (default: 24)"),
 				},
 				"ticker",
 			),
@@ -185,8 +187,10 @@ func (a *SentimentAgent) handleGetSectorNews(ctx context.Context, args json.RawM
 
 func (a *SentimentAgent) handleAnalyzeSentiment(ctx context.Context, args json.RawMessage) (string, error) {
 	var params struct {
-		Ticker string `json:"ticker"`
-		Limit  int    `json:"limit"`
+		Ticker        string  `json:"ticker"`
+		Limit         int     `json:"limit"`
+		LookbackDays  int     `json:"lookback_days"`
+		HalfLifeHours float64 `json:"half_life_hours"`
 	}
 	if err := json.Unmarshal(args, &params); err != nil {
 		return "", fmt.Errorf("parse args: %w", err)
@@ -203,7 +207,10 @@ func (a *SentimentAgent) handleAnalyzeSentiment(ctx context.Context, args json.R
 		return fmt.Sprintf("No news articles found for %s", params.Ticker), nil
 	}
 
-	analysisResult := sentiment.FullSentimentAnalysis(params.Ticker, articles)
+	analysisResult := sentiment.FullSentimentAnalysisDecayed(params.Ticker, articles, sentiment.DecayOptions{
+		LookbackDays: params.LookbackDays,
+		HalfLife:     time.Duration(params.HalfLifeHours * float64(time.Hour)),
+	})
 	data, _ := json.MarshalIndent(analysisResult, "", "  ")
 	return string(data), nil
 }
@@ -0,0 +1,232 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/seenimoa/openseai/internal/datasource"
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// SignalConflict flags a driver (signal source) that produced both a
+// bullish and a bearish signal.
+type SignalConflict struct {
+	Source  string        `json:"source"`
+	Bullish models.Signal `json:"bullish"`
+	Bearish models.Signal `json:"bearish"`
+}
+
+// signalKey normalizes a signal source for comparison.
+func signalKey(source string) string {
+	return strings.ToUpper(strings.TrimSpace(source))
+}
+
+// MergeSignals collapses signals with the same source and type into the one
+// with the highest confidence, preserving first-seen order. Sources that end
+// up with both a BUY and a SELL signal are reported as conflicts; both
+// signals are kept in merged.
+func MergeSignals(signals []models.Signal) (merged []models.Signal, conflicts []SignalConflict) {
+	type dedupKey struct {
+		source string
+		typ    models.SignalType
+	}
+	index := make(map[dedupKey]int)
+	for _, sig := range signals {
+		k := dedupKey{signalKey(sig.Source), sig.Type}
+		if i, ok := index[k]; ok {
+			if sig.Confidence > merged[i].Confidence {
+				merged[i] = sig
+			}
+			continue
+		}
+		index[k] = len(merged)
+		merged = append(merged, sig)
+	}
+
+	seen := make(map[string]bool)
+	for _, sig := range merged {
+		src := signalKey(sig.Source)
+		if seen[src] {
+			continue
+		}
+		buy, okBuy := index[dedupKey{src, models.SignalBuy}]
+		sell, okSell := index[dedupKey{src, models.SignalSell}]
+		if okBuy && okSell {
+			seen[src] = true
+			conflicts = append(conflicts, SignalConflict{
+				Source:  sig.Source,
+				Bullish: merged[buy],
+				Bearish: merged[sell],
+			})
+		}
+	}
+	return merged, conflicts
+}
+
+// buildCompositeAnalysis combines the specialist agents' signals into the
+// orchestrator's composite analysis. Conflicting signals are recorded under
+// Details["signal_conflicts"], and price scenarios are built over the
+// CompositeTimeframe horizon.
+func buildCompositeAnalysis(ticker string, results map[string]*AgentResult) *models.AnalysisResult {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var signals []models.Signal
+	for _, name := range names {
+		if r := results[name]; r != nil && r.Analysis != nil {
+			signals = append(signals, r.Analysis.Signals...)
+		}
+	}
+
+	merged, conflicts := MergeSignals(signals)
+	composite := &models.AnalysisResult{
+		Ticker:    ticker,
+		Type:      models.AnalysisComposite,
+		AgentName: "orchestrator",
+		Signals:   merged,
+		Score:     models.ScoreSignals(merged),
+		Timestamp: time.Now(),
+	}
+	if len(conflicts) > 0 {
+		composite.Details = map[string]any{"signal_conflicts": conflicts}
+	}
+	composite.Scenarios = BuildScenarios(orderedResults(results), HorizonDays(CompositeTimeframe))
+	return composite
+}
+
+// CompositeTimeframe is the horizon of the orchestrator's composite view.
+const CompositeTimeframe = "medium-term"
+
+// HorizonDays returns the trading days a "short-term", "medium-term", or
+// "long-term" view spans. Anything else is read as medium-term.
+func HorizonDays(timeframe string) int {
+	switch strings.ToLower(timeframe) {
+	case "short-term":
+		return 10
+	case "long-term":
+		return 250
+	default:
+		return 63
+	}
+}
+
+// BuildScenarios derives bear, base, and bull case targets from the
+// technical and risk analyses among results, which report "target_price"
+// and "atr" in their details. The base case is the technical target (else
+// the risk agent's). The daily ATR, taken from the risk agent first, is
+// scaled by the square root of horizonDays; the band is one scaled ATR
+// either side of the base and the bear and bull cases two. It returns nil
+// when no target or ATR was reported.
+func BuildScenarios(results []*AgentResult, horizonDays int) *models.PriceScenarios {
+	var technical, risk map[string]any
+	for _, r := range results {
+		if r == nil || r.Analysis == nil {
+			continue
+		}
+		switch r.Analysis.Type {
+		case models.AnalysisTechnical:
+			technical = r.Analysis.Details
+		case models.AnalysisRisk:
+			risk = r.Analysis.Details
+		}
+	}
+
+	base := firstPositive(detailFloat(technical, "target_price"), detailFloat(risk, "target_price"))
+	atr := firstPositive(detailFloat(risk, "atr"), detailFloat(technical, "atr"))
+	if base <= 0 || atr <= 0 {
+		return nil
+	}
+	move := atr * math.Sqrt(float64(max(horizonDays, 1)))
+	return &models.PriceScenarios{
+		Bear:     math.Max(base-2*move, 0),
+		Base:     base,
+		Bull:     base + 2*move,
+		BandLow:  math.Max(base-move, 0),
+		BandHigh: base + move,
+	}
+}
+
+// setComputedATR records in the analysis details the StopATRPeriod-day
+// ATR computed from the first source with enough daily history, in place
+// of any ATR the model reported. The details are left as they are when
+// no source has the history.
+func setComputedATR(ctx context.Context, analysis *models.AnalysisResult, sources []datasource.DataSource) {
+	for _, src := range sources {
+		atr, err := HistoryATR(src.GetHistoricalData)(ctx, analysis.Ticker)
+		if err != nil || atr <= 0 {
+			continue
+		}
+		if analysis.Details == nil {
+			analysis.Details = map[string]any{}
+		}
+		analysis.Details["atr"] = atr
+		return
+	}
+}
+
+// detailFloat returns the numeric detail key, or 0.
+func detailFloat(details map[string]any, key string) float64 {
+	v, _ := details[key].(float64)
+	return v
+}
+
+func firstPositive(values ...float64) float64 {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// DebateRound records a CIO adjudication between the most bullish and the
+// most bearish specialist agents.
+type DebateRound struct {
+	Bullish      string  `json:"bullish"` // agent with the highest signal score
+	BullishScore float64 `json:"bullish_score"`
+	Bearish      string  `json:"bearish"` // agent with the lowest signal score
+	BearishScore float64 `json:"bearish_score"`
+	Verdict      string  `json:"verdict"` // the CIO's adjudication
+}
+
+// detectDebate returns the most bullish and most bearish agents when one
+// scores above zero, the other below, and their scores are at least
+// threshold apart. Agents without signals are ignored. It returns nil when
+// there is nothing to debate.
+func detectDebate(results map[string]*AgentResult, threshold float64) *DebateRound {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var d *DebateRound
+	for _, name := range names {
+		r := results[name]
+		if r == nil || r.Analysis == nil || len(r.Analysis.Signals) == 0 {
+			continue
+		}
+		score := models.ScoreSignals(r.Analysis.Signals)
+		if d == nil {
+			d = &DebateRound{Bullish: name, BullishScore: score, Bearish: name, BearishScore: score}
+			continue
+		}
+		if score > d.BullishScore {
+			d.Bullish, d.BullishScore = name, score
+		}
+		if score < d.BearishScore {
+			d.Bearish, d.BearishScore = name, score
+		}
+	}
+
+	if d == nil || d.BullishScore <= 0 || d.BearishScore >= 0 || d.BullishScore-d.BearishScore < threshold {
+		return nil
+	}
+	return d
+}
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// StopATRPeriod is the ATR lookback, in daily bars, behind stop suggestions.
+const StopATRPeriod = 14
+
+// stopMultipliers are the ATR multiples of the tight, moderate, and wide
+// stops, in that order.
+var stopMultipliers = []struct {
+	name string
+	mult float64
+}{
+	{"tight", 1.5},
+	{"moderate", 2.0},
+	{"wide", 3.0},
+}
+
+// StopLevel is one ATR-based stop-loss suggestion.
+type StopLevel struct {
+	Name       string  `json:"name"`       // tight, moderate, or wide
+	Multiplier float64 `json:"multiplier"` // ATR multiple
+	Price      float64 `json:"price"`
+	RiskPct    float64 `json:"risk_pct"`           // distance from entry, % of entry
+	Risk       float64 `json:"risk_inr,omitempty"` // ₹ lost if hit, for a sized position
+}
+
+// SuggestStops returns the tight, moderate, and wide stops for a position
+// entered at entry, placed 1.5, 2, and 3 ATRs away on the losing side.
+func SuggestStops(entry, atr float64, long bool) []StopLevel {
+	levels := make([]StopLevel, 0, len(stopMultipliers))
+	for _, m := range stopMultipliers {
+		price := entry - m.mult*atr
+		if !long {
+			price = entry + m.mult*atr
+		}
+		price = math.Round(price*100) / 100
+		var riskPct float64
+		if entry > 0 {
+			riskPct = math.Abs(price-entry) / entry * 100
+		}
+		levels = append(levels, StopLevel{Name: m.name, Multiplier: m.mult, Price: price, RiskPct: riskPct})
+	}
+	return levels
+}
+
+// ATRFunc returns the current StopATRPeriod-day ATR of ticker.
+type ATRFunc func(ctx context.Context, ticker string) (float64, error)
+
+// HistoryATR returns an ATRFunc that computes ATR from the last three
+// months of daily bars returned by fetch, such as
+// Aggregator.FetchHistoricalData.
+func HistoryATR(fetch func(ctx context.Context, ticker string, from, to time.Time, tf models.Timeframe) ([]models.OHLCV, error)) ATRFunc {
+	return func(ctx context.Context, ticker string) (float64, error) {
+		to := time.Now()
+		candles, err := fetch(ctx, ticker, to.AddDate(0, -3, 0), to, models.Timeframe1Day)
+		if err != nil {
+			return 0, err
+		}
+		if len(candles) <= StopATRPeriod {
+			return 0, fmt.Errorf("insufficient data for %s ATR (need ≥%d days, got %d)", ticker, StopATRPeriod+1, len(candles))
+		}
+		return computeATR(candles, StopATRPeriod), nil
+	}
+}
+
+// HoldingStop holds the stop suggestions for one holding, each with the
+// rupee risk of the whole position.
+type HoldingStop struct {
+	Ticker   string      `json:"ticker"`
+	Quantity int         `json:"quantity"`
+	Price    float64     `json:"price"` // last traded price, or average price when unknown
+	ATR      float64     `json:"atr"`
+	Levels   []StopLevel `json:"levels,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// SuggestHoldingStops suggests long stops below the current price of each
+// holding. A holding whose ATR cannot be fetched is returned with Error
+// set; the rest are still sized.
+func SuggestHoldingStops(ctx context.Context, holdings []models.Holding, atr ATRFunc) []HoldingStop {
+	out := make([]HoldingStop, 0, len(holdings))
+	for _, h := range holdings {
+		hs := HoldingStop{Ticker: h.Ticker, Quantity: h.Quantity, Price: h.LTP}
+		if hs.Price <= 0 {
+			hs.Price = h.AvgPrice
+		}
+		a, err := atr(ctx, h.Ticker)
+		if err != nil {
+			hs.Error = err.Error()
+			out = append(out, hs)
+			continue
+		}
+		hs.ATR = a
+		hs.Levels = SuggestStops(hs.Price, a, true)
+		for i := range hs.Levels {
+			hs.Levels[i].Risk = (hs.Price - hs.Levels[i].Price) * float64(h.Quantity)
+		}
+		out = append(out, hs)
+	}
+	return out
+}
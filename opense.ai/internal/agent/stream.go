@@ -0,0 +1,320 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seenimoa/openseai/internal/llm"
+)
+
+// AgentStreamEvent is one event of a streamed analysis: a content delta
+// from the named agent, the end of an agent's tool-calling round, or, on
+// the last event, the finished result. An event with none of these marks
+// the agent starting a task.
+type AgentStreamEvent struct {
+	Agent     string       `json:"agent"`                // e.g. prompts.AgentTechnical; "orchestrator" on the last event
+	Delta     string       `json:"delta,omitempty"`      // content streamed by Agent since its previous event
+	ToolCalls bool         `json:"tool_calls,omitempty"` // Agent's text since its previous round led to tool calls, not an answer
+	Result    *AgentResult `json:"result,omitempty"`     // set on the last event
+	Err       error        `json:"-"`                    // set on the last event if the analysis failed
+}
+
+// AnalyzeStream runs a multi-agent analysis of ticker like FullAnalysis,
+// streaming each agent's output as it is generated. Specialists stream
+// concurrently, so their events interleave; the CIO synthesis and the
+// report follow. The last event carries the final result (or error), after
+// which the channel is closed.
+//
+// Deltas stream a line at a time with banned phrases redacted. A
+// post-processor set with OrchestratorConfig.PostProcess may rewrite any
+// of the text, so then no deltas are sent and only the result carries it.
+//
+// Result.Usage counts the tokens each provider reports at the end of its
+// stream; if one reports none, Usage.Incomplete is set. Callers must
+// drain the channel or cancel ctx.
+func (o *Orchestrator) AnalyzeStream(ctx context.Context, ticker string) (<-chan AgentStreamEvent, error) {
+	ticker = strings.ToUpper(strings.TrimSpace(ticker))
+	if ticker == "" {
+		return nil, fmt.Errorf("ticker is required")
+	}
+
+	mux := newStreamMux()
+	sink := mux.emit
+	if o.customPost {
+		sink = func(ctx context.Context, agent string, ev AgentStreamEvent) {
+			if ev.Delta == "" {
+				mux.emit(ctx, agent, ev)
+			}
+		}
+	}
+	go func() {
+		result, err := o.FullAnalysis(withStreamSink(ctx, sink), ticker)
+		mux.emit(ctx, "orchestrator", AgentStreamEvent{Result: result, Err: err})
+		mux.close()
+	}()
+	return mux.ch, nil
+}
+
+// ChatStream is Chat with the reply streamed as it is generated. The last
+// chunk has Done set and, if the run failed, Err; the channel is then
+// closed. Callers must drain the channel or cancel ctx.
+//
+// The reply streams from the provider a line at a time, with banned phrases
+// redacted; whatever post-processing appends, such as the disclaimer,
+// arrives in the last chunk. Text the agent writes before calling tools is
+// streamed too, followed by a chunk with FinishReason llm.FinishToolCalls:
+// the reply is what streams after the last such chunk. A post-processor
+// set with OrchestratorConfig.PostProcess may rewrite any of the reply, so
+// then nothing is streamed and the last chunk holds the whole reply.
+//
+// In multi-agent mode a message naming a ticker is answered like Process:
+// a "→ <agent> running" line streams as each agent starts, then a chunk
+// with FinishReason llm.FinishToolCalls, and the last chunk holds the
+// whole answer. Other messages, such as follow-ups, are answered in
+// single-agent mode so that history is kept.
+func (o *Orchestrator) ChatStream(ctx context.Context, message string, history []llm.Message) (<-chan llm.StreamChunk, error) {
+	if strings.TrimSpace(message) == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+
+	multi := o.Mode() == ModeMulti && extractTicker(message) != ""
+	live := !o.customPost && !multi
+	mux := newStreamMux()
+	go func() {
+		sctx := withStreamSink(ctx, mux.emit)
+		var result *AgentResult
+		var err error
+		if multi {
+			result, err = o.Process(sctx, message)
+		} else {
+			result, err = o.Chat(sctx, message, history)
+		}
+		mux.emit(ctx, "orchestrator", AgentStreamEvent{Result: result, Err: err})
+		mux.close()
+	}()
+
+	out := make(chan llm.StreamChunk, 64)
+	go func() {
+		defer close(out)
+		send := func(c llm.StreamChunk) {
+			select {
+			case out <- c:
+			case <-ctx.Done():
+			}
+		}
+
+		var lines lineRedactor
+		final := llm.StreamChunk{FinishReason: llm.FinishStop, Done: true}
+		finished, progressed := false, false
+		for ev := range mux.ch {
+			switch {
+			case ev.Agent == "orchestrator":
+				finished = true
+				final.Err = ev.Err
+				if progressed {
+					send(llm.StreamChunk{FinishReason: llm.FinishToolCalls})
+				}
+				if ev.Result != nil && !live {
+					final.Content = ev.Result.Content
+					break
+				}
+				if rest := lines.flush(); rest != "" {
+					send(llm.StreamChunk{Content: rest})
+				}
+				if ev.Result != nil {
+					final.Content = lines.tail(ev.Result.Content)
+				}
+			case multi:
+				if ev.Delta == "" && !ev.ToolCalls {
+					progressed = true
+					send(llm.StreamChunk{Content: fmt.Sprintf("→ %s running\n", ev.Agent)})
+				}
+			case !live:
+			case ev.ToolCalls:
+				if rest := lines.flush(); rest != "" {
+					send(llm.StreamChunk{Content: rest})
+				}
+				lines = lineRedactor{}
+				send(llm.StreamChunk{FinishReason: llm.FinishToolCalls})
+			default:
+				if text := lines.write(ev.Delta); text != "" {
+					send(llm.StreamChunk{Content: text})
+				}
+			}
+		}
+		if !finished {
+			final.Err = ctx.Err()
+		}
+		send(final)
+	}()
+	return out, nil
+}
+
+// lineRedactor releases streamed text a line at a time with banned phrases
+// redacted, remembering what it released.
+type lineRedactor struct {
+	pending string
+	sent    strings.Builder
+}
+
+// write buffers delta and returns the complete lines it finishes.
+func (r *lineRedactor) write(delta string) string {
+	r.pending += delta
+	i := strings.LastIndexByte(r.pending, '\n')
+	if i < 0 {
+		return ""
+	}
+	text := bannedPhrases.ReplaceAllString(r.pending[:i+1], redactedPhrase)
+	r.pending = r.pending[i+1:]
+	r.sent.WriteString(text)
+	return text
+}
+
+// flush returns the buffered partial line.
+func (r *lineRedactor) flush() string {
+	text := bannedPhrases.ReplaceAllString(r.pending, redactedPhrase)
+	r.pending = ""
+	r.sent.WriteString(text)
+	return text
+}
+
+// tail returns the part of the final content that was not streamed: what
+// post-processing appended. Trailing newlines trimmed by post-processing
+// are matched too, so the streamed text and the tail reproduce content.
+func (r *lineRedactor) tail(content string) string {
+	sent := r.sent.String()
+	base := strings.TrimRight(sent, "\n")
+	if !strings.HasPrefix(content, base) {
+		return ""
+	}
+	return strings.TrimPrefix(content[len(base):], sent[len(base):])
+}
+
+// streamMux multiplexes events from concurrently streaming agents onto one
+// channel. Emits after close are dropped, so agents abandoned by a timeout
+// cannot send on the closed channel.
+type streamMux struct {
+	mu     sync.Mutex
+	ch     chan AgentStreamEvent
+	closed bool
+}
+
+func newStreamMux() *streamMux {
+	return &streamMux{ch: make(chan AgentStreamEvent, 64)}
+}
+
+// emit sends ev tagged with agent, giving up if ctx ends first.
+func (m *streamMux) emit(ctx context.Context, agent string, ev AgentStreamEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
+	ev.Agent = agent
+	select {
+	case m.ch <- ev:
+	case <-ctx.Done():
+	}
+}
+
+func (m *streamMux) close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	close(m.ch)
+}
+
+// streamSink receives the streamed output of the agents of a run.
+type streamSink func(ctx context.Context, agent string, ev AgentStreamEvent)
+
+type streamKey struct{}
+
+// withStreamSink returns a context whose agent calls stream into sink.
+func withStreamSink(ctx context.Context, sink streamSink) context.Context {
+	return context.WithValue(ctx, streamKey{}, sink)
+}
+
+// streamSinkFrom returns the sink carried by ctx, or nil.
+func streamSinkFrom(ctx context.Context) streamSink {
+	s, _ := ctx.Value(streamKey{}).(streamSink)
+	return s
+}
+
+// streamingProvider serves Chat from ChatStream, passing the content to
+// emit a line at a time with banned phrases redacted, and a ToolCalls
+// event after a response that calls tools, so the tool loop runs
+// unchanged while the agent's text streams out.
+type streamingProvider struct {
+	llm.LLMProvider
+	emit func(ctx context.Context, ev AgentStreamEvent)
+}
+
+// Chat streams the response and assembles it. Tool call fragments without
+// an ID or name continue the previous call's arguments.
+func (p *streamingProvider) Chat(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+	start := time.Now()
+	ch, err := p.LLMProvider.ChatStream(ctx, messages, tools, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &llm.Response{Provider: p.LLMProvider.Name()}
+	if opts != nil {
+		resp.Model = opts.Model
+	}
+	var content strings.Builder
+	var lines lineRedactor
+	for chunk := range ch {
+		if chunk.Err != nil {
+			return nil, chunk.Err
+		}
+		if chunk.Content != "" {
+			content.WriteString(chunk.Content)
+			if text := lines.write(chunk.Content); text != "" {
+				p.emit(ctx, AgentStreamEvent{Delta: text})
+			}
+		}
+		for _, tc := range chunk.ToolCalls {
+			if n := len(resp.ToolCalls); n > 0 && tc.ID == "" && tc.Name == "" {
+				resp.ToolCalls[n-1].Arguments = append(resp.ToolCalls[n-1].Arguments, tc.Arguments...)
+				continue
+			}
+			tc.Arguments = append(json.RawMessage(nil), tc.Arguments...)
+			resp.ToolCalls = append(resp.ToolCalls, tc)
+		}
+		if chunk.FinishReason != "" {
+			resp.FinishReason = chunk.FinishReason
+		}
+		if u := chunk.Usage; u != nil {
+			resp.Usage.PromptTokens += u.PromptTokens
+			resp.Usage.CompletionTokens += u.CompletionTokens
+			resp.Usage.TotalTokens += u.TotalTokens
+		}
+		if chunk.Done {
+			break
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if rest := lines.flush(); rest != "" {
+		p.emit(ctx, AgentStreamEvent{Delta: rest})
+	}
+
+	resp.Content = content.String()
+	if resp.FinishReason == "" {
+		resp.FinishReason = llm.FinishStop
+		if resp.HasToolCalls() {
+			resp.FinishReason = llm.FinishToolCalls
+		}
+	}
+	if resp.HasToolCalls() {
+		p.emit(ctx, AgentStreamEvent{ToolCalls: true})
+	}
+	resp.Latency = time.Since(start)
+	return resp, nil
+}
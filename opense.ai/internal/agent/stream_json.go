@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/seenimoa/openseai/internal/llm"
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// AnalysisStream extracts the structured recommendation from an analysis
+// while it is still streaming. It scans each chunk once, tracking brace
+// depth outside of JSON strings, and tries to decode every top-level
+// object as soon as its closing brace arrives.
+type AnalysisStream struct {
+	defaults models.AnalysisResult
+	buf      strings.Builder
+	scanned  int  // bytes of buf already scanned
+	depth    int  // brace depth of the current candidate object
+	start    int  // offset in buf of the candidate's opening brace
+	inString bool // inside a JSON string of the candidate
+	escaped  bool // previous byte was a backslash inside a string
+	ready    *models.AnalysisResult
+}
+
+// NewAnalysisStream returns an AnalysisStream that fills fields missing
+// from the parsed object from defaults, as ParseAnalysisResult does.
+func NewAnalysisStream(defaults models.AnalysisResult) *AnalysisStream {
+	return &AnalysisStream{defaults: defaults}
+}
+
+// Write appends a chunk of streamed text. It returns the parsed result the
+// first time a complete object carrying a recommendation has arrived, and
+// nil otherwise.
+func (s *AnalysisStream) Write(chunk string) *models.AnalysisResult {
+	s.buf.WriteString(chunk)
+	if s.ready != nil {
+		return nil
+	}
+	text := s.buf.String()
+	for i := s.scanned; i < len(text); i++ {
+		c := text[i]
+		if s.depth == 0 {
+			if c == '{' {
+				s.depth, s.start = 1, i
+			}
+			continue
+		}
+		switch {
+		case s.escaped:
+			s.escaped = false
+		case s.inString:
+			switch c {
+			case '\\':
+				s.escaped = true
+			case '"':
+				s.inString = false
+			}
+		case c == '"':
+			s.inString = true
+		case c == '{':
+			s.depth++
+		case c == '}':
+			s.depth--
+			if s.depth == 0 {
+				if r := s.parse(text[s.start : i+1]); r != nil {
+					s.scanned = len(text)
+					s.ready = r
+					return r
+				}
+			}
+		}
+	}
+	s.scanned = len(text)
+	return nil
+}
+
+// parse decodes obj and returns it merged over the defaults, or nil when
+// it is not an analysis result with a recommendation.
+func (s *AnalysisStream) parse(obj string) *models.AnalysisResult {
+	var parsed models.AnalysisResult
+	if err := json.Unmarshal([]byte(obj), &parsed); err != nil || parsed.Recommendation == "" {
+		return nil
+	}
+	return ParseAnalysisResult(obj, s.defaults)
+}
+
+// Ready returns the result parsed during streaming, or nil if none was.
+func (s *AnalysisStream) Ready() *models.AnalysisResult { return s.ready }
+
+// Content returns all text written so far.
+func (s *AnalysisStream) Content() string { return s.buf.String() }
+
+// ReadAnalysisStream drains a streamed analysis, calling onReady as soon as
+// its recommendation object is complete — usually well before the prose
+// around it ends. It returns the full text and the structured result: the
+// early one when there was one, else whatever ParseAnalysisResult makes of
+// the full text, in which case onReady is never called.
+func ReadAnalysisStream(ch <-chan llm.StreamChunk, defaults models.AnalysisResult, onReady func(*models.AnalysisResult)) (string, *models.AnalysisResult, error) {
+	s := NewAnalysisStream(defaults)
+	for chunk := range ch {
+		if chunk.Err != nil {
+			return s.Content(), nil, chunk.Err
+		}
+		if r := s.Write(chunk.Content); r != nil && onReady != nil {
+			onReady(r)
+		}
+		if chunk.Done {
+			break
+		}
+	}
+	if r := s.Ready(); r != nil {
+		return s.Content(), r, nil
+	}
+	content := s.Content()
+	return content, ParseAnalysisResult(content, defaults), nil
+}
@@ -59,12 +59,13 @@ func (a *TechnicalAgent) buildTools() []llm.Tool {
 		},
 		{
 			Name:        "compute_indicators",
-			Description: "Compute all technical indicators (RSI, MACD, Bollinger Bands, SuperTrend, ATR, Pivot Points) from OHLCV data",
+			Description: "Compute all technical indicators (RSI, MACD, SMA/EMA/WMA/HMA, Bollinger Bands, SuperTrend, ATR, Pivot Points) from OHLCV data",
 			Parameters: llm.ObjectSchema("Indicator parameters",
 				map[string]*llm.JSONSchema{
-					"ticker":    llm.StringProp("NSE ticker symbol"),
-					"days":      llm.IntProp("Number of trading days (default: 200)"),
-					"timeframe": llm.StringProp("Candle timeframe (default: 1d)"),
+					"ticker":         llm.StringProp("NSE ticker symbol"),
+					"days":           llm.IntProp("Number of trading days (default: 200)"),
+					"timeframe":      llm.StringProp("Candle timeframe (default: 1d)"),
+					"clean_outliers": llm.BoolProp("Cap spurious price spikes (data errors) before analysis (default: false)"),
 				},
 				"ticker",
 			),
@@ -75,9 +76,10 @@ func (a *TechnicalAgent) buildTools() []llm.Tool {
 			Description: "Generate BUY/SELL/HOLD trading signals from technical analysis of price data",
 			Parameters: llm.ObjectSchema("Signal generation parameters",
 				map[string]*llm.JSONSchema{
-					"ticker":    llm.StringProp("NSE ticker symbol"),
-					"days":      llm.IntProp("Number of trading days (default: 200)"),
-					"timeframe": llm.StringProp("Candle timeframe (default: 1d)"),
+					"ticker":         llm.StringProp("NSE ticker symbol"),
+					"days":           llm.IntProp("Number of trading days (default: 200)"),
+					"timeframe":      llm.StringProp("Candle timeframe (default: 1d)"),
+					"clean_outliers": llm.BoolProp("Cap spurious price spikes (data errors) before analysis (default: false)"),
 				},
 				"ticker",
 			),
@@ -88,14 +90,30 @@ func (a *TechnicalAgent) buildTools() []llm.Tool {
 			Description: "Run a comprehensive technical analysis combining all indicators, signals, support/resistance, and trend assessment",
 			Parameters: llm.ObjectSchema("Full analysis parameters",
 				map[string]*llm.JSONSchema{
-					"ticker":    llm.StringProp("NSE ticker symbol"),
-					"days":      llm.IntProp("Number of trading days (default: 200)"),
-					"timeframe": llm.StringProp("Candle timeframe (default: 1d)"),
+					"ticker":         llm.StringProp("NSE ticker symbol"),
+					"days":           llm.IntProp("Number of trading days (default: 200)"),
+					"timeframe":      llm.StringProp("Candle timeframe (default: 1d)"),
+					"clean_outliers": llm.BoolProp("Cap spurious price spikes (data errors) before analysis (default: false)"),
 				},
 				"ticker",
 			),
 			Handler: a.handleFullAnalysis,
 		},
+		{
+			Name:        "rsi_divergence",
+			Description: "Detect bullish (price lower low, RSI higher low) and bearish (price higher high, RSI lower high) RSI divergences between recent swing points, with their dates",
+			Parameters: llm.ObjectSchema("RSI divergence parameters",
+				map[string]*llm.JSONSchema{
+					"ticker":     llm.StringProp("NSE ticker symbol"),
+					"days":       llm.IntProp("Number of trading days (default: 200)"),
+					"timeframe":  llm.StringProp("Candle timeframe (default: 1d)"),
+					"rsi_period": llm.IntProp("RSI period (default: 14)"),
+					"lookback":   llm.IntProp("Only report divergences confirmed in the last N candles (default: 60)"),
+				},
+				"ticker",
+			),
+			Handler: a.handleRSIDivergence,
+		},
 		{
 			Name:        "get_quote",
 			Description: "Get latest stock quote with current price, volume, day range, 52-week range",
@@ -112,6 +130,10 @@ func (a *TechnicalAgent) buildTools() []llm.Tool {
 
 // ── Tool Handlers ──
 
+// outlierStdDevs is the band, in standard deviations, beyond which
+// clean_outliers caps a bar's prices.
+const outlierStdDevs = 4.0
+
 func (a *TechnicalAgent) fetchCandles(ctx context.Context, ticker string, days int, timeframe string) ([]models.OHLCV, error) {
 	if days <= 0 {
 		days = 200
@@ -189,9 +211,10 @@ func (a *TechnicalAgent) handleGetHistorical(ctx context.Context, args json.RawM
 
 func (a *TechnicalAgent) handleComputeIndicators(ctx context.Context, args json.RawMessage) (string, error) {
 	var params struct {
-		Ticker    string `json:"ticker"`
-		Days      int    `json:"days"`
-		Timeframe string `json:"timeframe"`
+		Ticker        string `json:"ticker"`
+		Days          int    `json:"days"`
+		Timeframe     string `json:"timeframe"`
+		CleanOutliers bool   `json:"clean_outliers"`
 	}
 	if err := json.Unmarshal(args, &params); err != nil {
 		return "", fmt.Errorf("parse args: %w", err)
@@ -201,6 +224,9 @@ func (a *TechnicalAgent) handleComputeIndicators(ctx context.Context, args json.
 	if err != nil {
 		return err.Error(), nil
 	}
+	if params.CleanOutliers {
+		candles = datasource.WinsorizeBars(candles, outlierStdDevs)
+	}
 
 	indicators := technical.ComputeAll(params.Ticker, candles)
 	data, _ := json.MarshalIndent(indicators, "", "  ")
@@ -209,9 +235,10 @@ func (a *TechnicalAgent) handleComputeIndicators(ctx context.Context, args json.
 
 func (a *TechnicalAgent) handleGenerateSignals(ctx context.Context, args json.RawMessage) (string, error) {
 	var params struct {
-		Ticker    string `json:"ticker"`
-		Days      int    `json:"days"`
-		Timeframe string `json:"timeframe"`
+		Ticker        string `json:"ticker"`
+		Days          int    `json:"days"`
+		Timeframe     string `json:"timeframe"`
+		CleanOutliers bool   `json:"clean_outliers"`
 	}
 	if err := json.Unmarshal(args, &params); err != nil {
 		return "", fmt.Errorf("parse args: %w", err)
@@ -221,6 +248,9 @@ func (a *TechnicalAgent) handleGenerateSignals(ctx context.Context, args json.Ra
 	if err != nil {
 		return err.Error(), nil
 	}
+	if params.CleanOutliers {
+		candles = datasource.WinsorizeBars(candles, outlierStdDevs)
+	}
 
 	signals := technical.GenerateSignals(candles)
 	result := map[string]any{
@@ -241,21 +271,71 @@ func (a *TechnicalAgent) handleGenerateSignals(ctx context.Context, args json.Ra
 }
 
 func (a *TechnicalAgent) handleFullAnalysis(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Ticker        string `json:"ticker"`
+		Days          int    `json:"days"`
+		Timeframe     string `json:"timeframe"`
+		CleanOutliers bool   `json:"clean_outliers"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("parse args: %w", err)
+	}
+
+	candles, err := a.fetchCandles(ctx, params.Ticker, params.Days, params.Timeframe)
+	if err != nil {
+		return err.Error(), nil
+	}
+	if params.CleanOutliers {
+		candles = datasource.WinsorizeBars(candles, outlierStdDevs)
+	}
+
+	result := technical.FullTechnicalAnalysis(params.Ticker, candles)
+	data, _ := json.MarshalIndent(result, "", "  ")
+	return string(data), nil
+}
+
+func (a *TechnicalAgent) handleRSIDivergence(ctx context.Context, args json.RawMessage) (string, error) {
 	var params struct {
 		Ticker    string `json:"ticker"`
 		Days      int    `json:"days"`
 		Timeframe string `json:"timeframe"`
+		RSIPeriod int    `json:"rsi_period"`
+		Lookback  int    `json:"lookback"`
 	}
 	if err := json.Unmarshal(args, &params); err != nil {
 		return "", fmt.Errorf("parse args: %w", err)
 	}
+	if params.RSIPeriod <= 0 {
+		params.RSIPeriod = 14
+	}
+	if params.Lookback <= 0 {
+		params.Lookback = 60
+	}
 
 	candles, err := a.fetchCandles(ctx, params.Ticker, params.Days, params.Timeframe)
 	if err != nil {
 		return err.Error(), nil
 	}
 
-	result := technical.FullTechnicalAnalysis(params.Ticker, candles)
+	recent := []technical.Divergence{}
+	for _, d := range technical.DetectRSIDivergence(candles, params.RSIPeriod) {
+		if d.Index >= len(candles)-params.Lookback {
+			recent = append(recent, d)
+		}
+	}
+	result := map[string]any{
+		"ticker":      params.Ticker,
+		"rsi_period":  params.RSIPeriod,
+		"candles":     len(candles),
+		"lookback":    params.Lookback,
+		"divergences": recent,
+	}
+	if len(recent) > 0 {
+		latest := recent[len(recent)-1]
+		result["latest"] = latest.Type
+		result["bars_since_latest"] = len(candles) - 1 - latest.Index
+	}
+
 	data, _ := json.MarshalIndent(result, "", "  ")
 	return string(data), nil
 }
@@ -298,6 +378,7 @@ func (a *TechnicalAgent) AnalyzeWithTimestamp(ctx context.Context, ticker string
 		AgentName: a.Name(),
 		Timestamp: time.Now(),
 	})
+	setComputedATR(ctx, result.Analysis, a.dataSources)
 
 	return result, nil
 }
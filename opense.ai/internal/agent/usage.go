@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"context"
+	"sync"
+
+	"github.com/seenimoa/openseai/internal/llm"
+)
+
+// UsageTotals is the token usage and estimated cost summed over every LLM
+// call of one orchestrator run, including each tool-loop round trip.
+type UsageTotals struct {
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+	PricingKnown     bool    `json:"pricing_known"`        // false if any call used a model missing from the pricing table
+	Incomplete       bool    `json:"incomplete,omitempty"` // true if any call reported no token counts
+}
+
+// UsageAccumulator sums LLM usage across the agents of a run. It is safe
+// for concurrent use, so specialists running in parallel can share one.
+type UsageAccumulator struct {
+	mu     sync.Mutex
+	model  string // fallback when a response does not name its model
+	totals UsageTotals
+}
+
+// NewUsageAccumulator returns an empty accumulator that prices responses
+// without a model name as model.
+func NewUsageAccumulator(model string) *UsageAccumulator {
+	return &UsageAccumulator{model: model, totals: UsageTotals{PricingKnown: true}}
+}
+
+// Add records the usage of one LLM call made with model.
+func (u *UsageAccumulator) Add(model string, usage llm.Usage) {
+	if model == "" {
+		model = u.model
+	}
+	total := usage.TotalTokens
+	if total == 0 {
+		total = usage.PromptTokens + usage.CompletionTokens
+	}
+	unreported := total == 0
+	pricing, ok := llm.LookupPricing(model)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.totals.Calls++
+	u.totals.PromptTokens += usage.PromptTokens
+	u.totals.CompletionTokens += usage.CompletionTokens
+	u.totals.TotalTokens += total
+	u.totals.Incomplete = u.totals.Incomplete || unreported
+	if ok {
+		u.totals.CostUSD += pricing.Cost(usage.PromptTokens, usage.CompletionTokens)
+	} else {
+		u.totals.PricingKnown = false
+	}
+}
+
+// Totals returns the usage recorded so far.
+func (u *UsageAccumulator) Totals() UsageTotals {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.totals
+}
+
+type usageKey struct{}
+
+// withUsage returns a context whose agent calls are recorded in u.
+func withUsage(ctx context.Context, u *UsageAccumulator) context.Context {
+	return context.WithValue(ctx, usageKey{}, u)
+}
+
+// usageFrom returns the accumulator carried by ctx, or nil.
+func usageFrom(ctx context.Context) *UsageAccumulator {
+	u, _ := ctx.Value(usageKey{}).(*UsageAccumulator)
+	return u
+}
+
+// meteredProvider records the usage of every Chat call in an accumulator.
+type meteredProvider struct {
+	llm.LLMProvider
+	usage *UsageAccumulator
+}
+
+// Chat forwards to the wrapped provider and records the response's usage.
+func (p *meteredProvider) Chat(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts *llm.ChatOptions) (*llm.Response, error) {
+	resp, err := p.LLMProvider.Chat(ctx, messages, tools, opts)
+	if resp != nil {
+		model := resp.Model
+		if model == "" && opts != nil {
+			model = opts.Model
+		}
+		p.usage.Add(model, resp.Usage)
+	}
+	return resp, err
+}
@@ -51,6 +51,53 @@ func TestAnalyzeOptionChainNil(t *testing.T) {
 	}
 }
 
+func TestKeyLevels(t *testing.T) {
+	oc := &models.OptionChain{
+		Ticker:    "NIFTY",
+		SpotPrice: 25100,
+		Contracts: []models.OptionContract{
+			{StrikePrice: 24700, OptionType: "PE", OI: 90000},
+			{StrikePrice: 24900, OptionType: "PE", OI: 250000},
+			{StrikePrice: 25000, OptionType: "PE", OI: 140000},
+			{StrikePrice: 25300, OptionType: "PE", OI: 5000},
+			{StrikePrice: 24900, OptionType: "CE", OI: 4000},
+			{StrikePrice: 25200, OptionType: "CE", OI: 120000},
+			{StrikePrice: 25500, OptionType: "CE", OI: 310000},
+			{StrikePrice: 25800, OptionType: "CE", OI: 80000},
+		},
+	}
+
+	support, resistance := KeyLevels(oc)
+	if len(support) == 0 || support[0] != 24900 {
+		t.Errorf("expected highest put OI strike 24900 as primary support, got %v", support)
+	}
+	if len(resistance) == 0 || resistance[0] != 25500 {
+		t.Errorf("expected highest call OI strike 25500 as primary resistance, got %v", resistance)
+	}
+	for _, s := range support {
+		if s > oc.SpotPrice {
+			t.Errorf("support %.0f above spot", s)
+		}
+	}
+	for _, r := range resistance {
+		if r < oc.SpotPrice {
+			t.Errorf("resistance %.0f below spot", r)
+		}
+	}
+
+	a := AnalyzeOptionChain(oc)
+	if len(a.Support) != len(support) || len(a.Resistance) != len(resistance) {
+		t.Errorf("analysis should expose key levels, got support=%v resistance=%v", a.Support, a.Resistance)
+	}
+}
+
+func TestKeyLevelsNil(t *testing.T) {
+	support, resistance := KeyLevels(nil)
+	if support != nil || resistance != nil {
+		t.Error("expected nil levels for nil chain")
+	}
+}
+
 func TestComputeMaxPain(t *testing.T) {
 	oc := sampleOptionChain()
 	mp := ComputeMaxPain(oc.Contracts)
@@ -17,6 +17,8 @@ type OptionChainAnalysis struct {
 	ATMStrike    float64      `json:"atm_strike"`
 	ATMIV        float64      `json:"atm_iv"`        // average ATM IV
 	OISRLevels   OISupportRes `json:"oi_sr_levels"`
+	Support      []float64    `json:"support"`       // option-implied support, strongest first
+	Resistance   []float64    `json:"resistance"`    // option-implied resistance, strongest first
 	Sentiment    string       `json:"sentiment"`     // "bullish", "bearish", "neutral"
 }
 
@@ -62,6 +64,7 @@ func AnalyzeOptionChain(oc *models.OptionChain) OptionChainAnalysis {
 
 	// OI-based support/resistance.
 	a.OISRLevels = computeOISR(oc.Contracts)
+	a.Support, a.Resistance = KeyLevels(oc)
 
 	// Sentiment from PCR.
 	switch {
@@ -76,6 +79,73 @@ func AnalyzeOptionChain(oc *models.OptionChain) OptionChainAnalysis {
 	return a
 }
 
+// keyLevelMinShare is the minimum share of one side's total OI a strike must
+// hold to count as an option-implied key level.
+const keyLevelMinShare = 0.10
+
+// maxKeyLevels caps the number of support and resistance levels returned.
+const maxKeyLevels = 3
+
+// KeyLevels returns option-implied support and resistance strikes, strongest first.
+// Support comes from strikes with a concentration of put OI at or below spot
+// (put writers defending the level); resistance from call OI concentration at
+// or above spot. A strike qualifies when it holds at least 10% of its side's
+// total OI. When the spot price is unknown, strikes are not filtered by side.
+func KeyLevels(oc *models.OptionChain) (support []float64, resistance []float64) {
+	if oc == nil || len(oc.Contracts) == 0 {
+		return nil, nil
+	}
+
+	ceMap := map[float64]int64{}
+	peMap := map[float64]int64{}
+	for _, c := range oc.Contracts {
+		if c.OptionType == "CE" {
+			ceMap[c.StrikePrice] += c.OI
+		} else {
+			peMap[c.StrikePrice] += c.OI
+		}
+	}
+
+	support = concentratedStrikes(peMap, func(strike float64) bool {
+		return oc.SpotPrice <= 0 || strike <= oc.SpotPrice
+	})
+	resistance = concentratedStrikes(ceMap, func(strike float64) bool {
+		return oc.SpotPrice <= 0 || strike >= oc.SpotPrice
+	})
+	return support, resistance
+}
+
+// concentratedStrikes returns up to maxKeyLevels strikes accepted by keep whose
+// OI share of the side total is at least keyLevelMinShare, ordered by OI descending.
+func concentratedStrikes(oiByStrike map[float64]int64, keep func(strike float64) bool) []float64 {
+	var total int64
+	for _, oi := range oiByStrike {
+		total += oi
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	var entries []oiEntry
+	for s, oi := range oiByStrike {
+		if keep(s) && float64(oi)/float64(total) >= keyLevelMinShare {
+			entries = append(entries, oiEntry{s, oi})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].oi != entries[j].oi {
+			return entries[i].oi > entries[j].oi
+		}
+		return entries[i].strike < entries[j].strike
+	})
+
+	var levels []float64
+	for i := 0; i < maxKeyLevels && i < len(entries); i++ {
+		levels = append(levels, entries[i].strike)
+	}
+	return levels
+}
+
 // ComputeMaxPain calculates the max pain strike from option contracts.
 func ComputeMaxPain(contracts []models.OptionContract) float64 {
 	if len(contracts) == 0 {
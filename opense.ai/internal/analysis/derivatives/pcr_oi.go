@@ -363,6 +363,7 @@ func FullDerivativesAnalysis(ticker string, oc *models.OptionChain, fut *models.
 		Signals:        signals,
 		Recommendation: rec,
 		Confidence:     conf,
+		Score:          models.ScoreSignals(signals),
 		Summary:        summary,
 		Details:        details,
 		Timestamp:      time.Now(),
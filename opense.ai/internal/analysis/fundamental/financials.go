@@ -293,6 +293,9 @@ func FormatFinancialSummary(ratios models.FinancialRatios, growth models.GrowthR
 	b.WriteString(fmt.Sprintf("ROE: %.1f%% | ROCE: %.1f%% | D/E: %.2f\n", ratios.ROE, ratios.ROCE, ratios.DebtEquity))
 	b.WriteString(fmt.Sprintf("Revenue Growth YoY: %.1f%% | Profit Growth YoY: %.1f%%\n", growth.RevenueGrowthYoY, growth.ProfitGrowthYoY))
 
+	if ratios.DividendPerShare > 0 {
+		b.WriteString(fmt.Sprintf("Dividend: ₹%.2f/share | Yield: %.2f%% | Payout: %.1f%%\n", ratios.DividendPerShare, ratios.DividendYield, ratios.PayoutRatio))
+	}
 	if ratios.GrahamNumber > 0 {
 		b.WriteString(fmt.Sprintf("Graham Number: ₹%.2f\n", ratios.GrahamNumber))
 	}
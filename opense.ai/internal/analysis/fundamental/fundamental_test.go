@@ -1,6 +1,7 @@
 package fundamental
 
 import (
+	"math"
 	"testing"
 
 	"github.com/seenimoa/openseai/pkg/models"
@@ -68,6 +69,53 @@ func TestComputeRatiosNilData(t *testing.T) {
 	}
 }
 
+func TestComputeRatiosDividends(t *testing.T) {
+	price := 1250.0
+	dps := 10.0
+	wantYield := dps / price * 100 // 0.8%
+	wantPayout := dps / 50 * 100   // 20% of EPS
+
+	reported := sampleFinancialData()
+	reported.AnnualIncome[0].DividendPerShare = dps
+
+	fromCashFlow := sampleFinancialData()
+	fromCashFlow.AnnualCashFlow[0].DividendsPaid = -5600 // ₹10 on 560 shares
+
+	tests := []struct {
+		name   string
+		fin    *models.FinancialData
+		shares float64
+	}{
+		{"reported per share", reported, 560},
+		{"cash flow with shares", fromCashFlow, 560},
+		{"cash flow with shares from PAT/EPS", fromCashFlow, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DividendPerShare(tt.fin, tt.shares)
+			if math.Abs(got-dps) > 1e-9 {
+				t.Fatalf("DividendPerShare = %.4f, want %.4f", got, dps)
+			}
+			yield, payout := DividendMetrics(got, price, 50)
+			if math.Abs(yield-wantYield) > 1e-9 || math.Abs(payout-wantPayout) > 1e-9 {
+				t.Errorf("yield, payout = %.4f, %.4f; want %.4f, %.4f", yield, payout, wantYield, wantPayout)
+			}
+		})
+	}
+
+	ratios := ComputeRatios(reported, price, 560)
+	if math.Abs(ratios.DividendYield-wantYield) > 1e-9 {
+		t.Errorf("DividendYield = %.4f, want %.4f", ratios.DividendYield, wantYield)
+	}
+	if math.Abs(ratios.PayoutRatio-wantPayout) > 1e-9 {
+		t.Errorf("PayoutRatio = %.4f, want %.4f", ratios.PayoutRatio, wantPayout)
+	}
+
+	if ratios := ComputeRatios(sampleFinancialData(), price, 560); ratios.DividendYield != 0 || ratios.PayoutRatio != 0 {
+		t.Errorf("expected no dividend metrics without dividend data, got %+v", ratios)
+	}
+}
+
 func TestComputeGrowth(t *testing.T) {
 	fin := sampleFinancialData()
 	g := ComputeGrowth(fin)
@@ -96,9 +96,55 @@ func ComputeRatios(fin *models.FinancialData, price float64, sharesOutstanding f
 		ratios.PEGRatio = ratios.PE / growth.EPSGrowthYoY
 	}
 
+	// Dividend Yield = DPS / Price, Payout = DPS / EPS
+	ratios.DividendPerShare = DividendPerShare(fin, sharesOutstanding)
+	ratios.DividendYield, ratios.PayoutRatio = DividendMetrics(ratios.DividendPerShare, price, ratios.EPS)
+
 	return ratios
 }
 
+// DividendPerShare returns the latest annual dividend per share. It uses the
+// per-share figure of the latest annual income statement when reported, and
+// otherwise the dividends paid in the latest annual cash flow spread over
+// sharesOutstanding. When sharesOutstanding is not positive, the share count
+// is derived from the latest annual PAT and EPS.
+func DividendPerShare(fin *models.FinancialData, sharesOutstanding float64) float64 {
+	if fin == nil {
+		return 0
+	}
+	if len(fin.AnnualIncome) > 0 && fin.AnnualIncome[0].DividendPerShare > 0 {
+		return fin.AnnualIncome[0].DividendPerShare
+	}
+	if len(fin.AnnualCashFlow) == 0 || fin.AnnualCashFlow[0].DividendsPaid == 0 {
+		return 0
+	}
+	shares := sharesOutstanding
+	if shares <= 0 && len(fin.AnnualIncome) > 0 && fin.AnnualIncome[0].EPS > 0 {
+		shares = fin.AnnualIncome[0].PAT / fin.AnnualIncome[0].EPS
+	}
+	if shares <= 0 {
+		return 0
+	}
+	// Cash flow statements report dividends paid as an outflow.
+	return math.Abs(fin.AnnualCashFlow[0].DividendsPaid) / shares
+}
+
+// DividendMetrics returns the dividend yield (DPS / price) and payout ratio
+// (DPS / EPS), both in percent. Either is zero when its denominator is not
+// positive.
+func DividendMetrics(dps, price, eps float64) (yield, payout float64) {
+	if dps <= 0 {
+		return 0, 0
+	}
+	if price > 0 {
+		yield = dps / price * 100
+	}
+	if eps > 0 {
+		payout = dps / eps * 100
+	}
+	return yield, payout
+}
+
 // ComputeGrowth calculates growth rates from financial data.
 func ComputeGrowth(fin *models.FinancialData) models.GrowthRates {
 	g := models.GrowthRates{}
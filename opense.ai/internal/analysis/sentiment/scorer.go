@@ -93,28 +93,65 @@ func ScoreArticle(article models.NewsArticle) models.SentimentScore {
 	}
 }
 
-// AggregateSentiment computes a time-weighted aggregate sentiment from multiple scores.
+// DefaultHalfLife is the age at which an article's weight in the
+// aggregate sentiment has halved.
+const DefaultHalfLife = 24 * time.Hour
+
+// DecayOptions controls how article age affects the aggregate sentiment.
+type DecayOptions struct {
+	LookbackDays int           // drop articles older than this many days; 0 keeps all
+	HalfLife     time.Duration // weight halves every HalfLife (default: DefaultHalfLife)
+	Now          time.Time     // reference time for ages (default: time.Now())
+}
+
+// AggregateSentiment computes a time-weighted aggregate sentiment from
+// multiple scores, using the default decay options.
 func AggregateSentiment(ticker string, scores []models.SentimentScore) models.AggregatedSentiment {
+	return AggregateSentimentDecayed(ticker, scores, DecayOptions{})
+}
+
+// AggregateSentimentDecayed computes the aggregate sentiment with each
+// score weighted by its confidence and an exponential decay in its age,
+// after dropping scores outside the lookback window.
+func AggregateSentimentDecayed(ticker string, scores []models.SentimentScore, opts DecayOptions) models.AggregatedSentiment {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	halfLife := opts.HalfLife
+	if halfLife <= 0 {
+		halfLife = DefaultHalfLife
+	}
+	if opts.LookbackDays > 0 {
+		cutoff := now.AddDate(0, 0, -opts.LookbackDays)
+		var recent []models.SentimentScore
+		for _, s := range scores {
+			if !s.PublishedAt.Before(cutoff) {
+				recent = append(recent, s)
+			}
+		}
+		scores = recent
+	}
+
 	if len(scores) == 0 {
 		return models.AggregatedSentiment{
 			Ticker:    ticker,
 			Label:     "Neutral",
-			Timestamp: time.Now(),
+			Timestamp: now,
 		}
 	}
 
-	now := time.Now()
 	weightedSum := 0.0
 	totalWeight := 0.0
 	confSum := 0.0
 
 	for _, s := range scores {
-		// Time decay: halve weight every 24 hours.
-		age := now.Sub(s.PublishedAt).Hours()
+		// Time decay: halve weight every halfLife.
+		age := now.Sub(s.PublishedAt)
 		if age < 0 {
 			age = 0
 		}
-		timeWeight := math.Exp(-0.693 * age / 24) // ln(2)/1 * t/24
+		timeWeight := math.Exp(-math.Ln2 * age.Hours() / halfLife.Hours())
 		w := timeWeight * float64(s.Confidence)
 
 		weightedSum += s.Score * w
@@ -154,6 +191,12 @@ func AggregateSentiment(ticker string, scores []models.SentimentScore) models.Ag
 
 // FullSentimentAnalysis runs end-to-end sentiment analysis on a set of news articles.
 func FullSentimentAnalysis(ticker string, articles []models.NewsArticle) *models.AnalysisResult {
+	return FullSentimentAnalysisDecayed(ticker, articles, DecayOptions{})
+}
+
+// FullSentimentAnalysisDecayed runs end-to-end sentiment analysis with the
+// given lookback window and recency decay.
+func FullSentimentAnalysisDecayed(ticker string, articles []models.NewsArticle, opts DecayOptions) *models.AnalysisResult {
 	if len(articles) == 0 {
 		return nil
 	}
@@ -163,7 +206,8 @@ func FullSentimentAnalysis(ticker string, articles []models.NewsArticle) *models
 		scores = append(scores, ScoreArticle(a))
 	}
 
-	agg := AggregateSentiment(ticker, scores)
+	agg := AggregateSentimentDecayed(ticker, scores, opts)
+	n := agg.ArticleCount // articles inside the lookback window
 
 	// Convert to signal.
 	var sigType models.SignalType
@@ -180,10 +224,10 @@ func FullSentimentAnalysis(ticker string, articles []models.NewsArticle) *models
 		Source:     "Sentiment",
 		Type:       sigType,
 		Confidence: agg.Confidence,
-		Reason:     agg.Label + " sentiment across " + string(rune('0'+len(articles))) + " articles",
+		Reason:     agg.Label + " sentiment across " + string(rune('0'+n)) + " articles",
 	}
-	if len(articles) >= 10 {
-		sig.Reason = agg.Label + " sentiment across " + itoa(len(articles)) + " articles"
+	if n >= 10 {
+		sig.Reason = agg.Label + " sentiment across " + itoa(n) + " articles"
 	}
 
 	var rec models.Recommendation
@@ -202,7 +246,7 @@ func FullSentimentAnalysis(ticker string, articles []models.NewsArticle) *models
 
 	details := map[string]any{
 		"aggregated_sentiment": agg,
-		"article_count":        len(articles),
+		"article_count":        n,
 	}
 
 	return &models.AnalysisResult{
@@ -212,6 +256,7 @@ func FullSentimentAnalysis(ticker string, articles []models.NewsArticle) *models
 		Signals:        []models.Signal{sig},
 		Recommendation: rec,
 		Confidence:     agg.Confidence,
+		Score:          models.ScoreSignals([]models.Signal{sig}),
 		Summary:        agg.Label + " overall sentiment for " + ticker,
 		Details:        details,
 		Timestamp:      time.Now(),
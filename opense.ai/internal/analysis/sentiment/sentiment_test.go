@@ -76,6 +76,34 @@ func TestAggregateSentiment(t *testing.T) {
 	}
 }
 
+func TestAggregateSentimentDecayed(t *testing.T) {
+	now := time.Date(2025, 9, 15, 12, 0, 0, 0, time.UTC)
+	scores := []models.SentimentScore{
+		{Source: "MC", Score: 0.9, Confidence: 0.8, PublishedAt: now.AddDate(0, 0, -5)},    // old, bullish
+		{Source: "ET", Score: -0.6, Confidence: 0.5, PublishedAt: now.Add(-2 * time.Hour)}, // fresh, bearish
+	}
+
+	agg := AggregateSentimentDecayed("TCS", scores, DecayOptions{Now: now})
+	if agg.Score >= 0 {
+		t.Errorf("expected the fresh bearish article to dominate, got %.4f", agg.Score)
+	}
+	if agg.ArticleCount != 2 {
+		t.Errorf("expected 2 articles, got %d", agg.ArticleCount)
+	}
+
+	// A very slow decay lets the older, more confident article win.
+	agg = AggregateSentimentDecayed("TCS", scores, DecayOptions{Now: now, HalfLife: 365 * 24 * time.Hour})
+	if agg.Score <= 0 {
+		t.Errorf("expected a positive score with slow decay, got %.4f", agg.Score)
+	}
+
+	// The lookback window drops the old article entirely.
+	agg = AggregateSentimentDecayed("TCS", scores, DecayOptions{Now: now, LookbackDays: 3})
+	if agg.ArticleCount != 1 || agg.Score != -0.6 {
+		t.Errorf("expected only the fresh article in a 3-day window, got %d articles scoring %.4f", agg.ArticleCount, agg.Score)
+	}
+}
+
 func TestAggregateSentimentEmpty(t *testing.T) {
 	agg := AggregateSentiment("RELIANCE", nil)
 	if agg.Label != "Neutral" {
@@ -0,0 +1,93 @@
+package technical
+
+import (
+	"time"
+
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// Swing-point parameters of DetectRSIDivergence.
+const (
+	// divergenceSwing is how many bars on each side a swing low (high)
+	// must undercut (exceed).
+	divergenceSwing = 3
+	// divergenceMaxGap is the widest spacing, in bars, of two swings
+	// compared for divergence.
+	divergenceMaxGap = 60
+)
+
+// Divergence is an RSI/price divergence between two consecutive swing
+// points. Bullish: price makes a lower low while RSI makes a higher low.
+// Bearish: price makes a higher high while RSI makes a lower high.
+type Divergence struct {
+	Type      string    `json:"type"`  // "bullish" or "bearish"
+	Index     int       `json:"index"` // candle index of the second swing, where it is confirmed
+	Date      time.Time `json:"date"`
+	PrevIndex int       `json:"prev_index"` // candle index of the first swing
+	PrevDate  time.Time `json:"prev_date"`
+	Price     float64   `json:"price"` // low (bullish) or high (bearish) of the second swing
+	PrevPrice float64   `json:"prev_price"`
+	RSI       float64   `json:"rsi"`
+	PrevRSI   float64   `json:"prev_rsi"`
+}
+
+// DetectRSIDivergence scans the swing lows and highs of candles, oldest
+// first, and returns every divergence between consecutive swings of the
+// same kind no more than divergenceMaxGap bars apart, in order of the
+// second swing. Swings before the first RSI value are ignored, as are the
+// last divergenceSwing bars, which cannot be confirmed as swings yet.
+func DetectRSIDivergence(candles []models.OHLCV, rsiPeriod int) []Divergence {
+	if rsiPeriod <= 0 {
+		rsiPeriod = 14
+	}
+	rsi := RSI(candles, rsiPeriod)
+	if rsi == nil {
+		return nil
+	}
+
+	var out []Divergence
+	prevLow, prevHigh := -1, -1
+	for i := max(rsiPeriod, divergenceSwing); i < len(candles)-divergenceSwing; i++ {
+		if isSwing(candles, i, func(c models.OHLCV) float64 { return -c.Low }) {
+			if prevLow >= 0 && i-prevLow <= divergenceMaxGap &&
+				candles[i].Low < candles[prevLow].Low && rsi[i] > rsi[prevLow] {
+				out = append(out, newDivergence("bullish", candles, rsi, prevLow, i, candles[prevLow].Low, candles[i].Low))
+			}
+			prevLow = i
+		}
+		if isSwing(candles, i, func(c models.OHLCV) float64 { return c.High }) {
+			if prevHigh >= 0 && i-prevHigh <= divergenceMaxGap &&
+				candles[i].High > candles[prevHigh].High && rsi[i] < rsi[prevHigh] {
+				out = append(out, newDivergence("bearish", candles, rsi, prevHigh, i, candles[prevHigh].High, candles[i].High))
+			}
+			prevHigh = i
+		}
+	}
+	return out
+}
+
+// isSwing reports whether value(candles[i]) is strictly above that of the
+// divergenceSwing bars on either side.
+func isSwing(candles []models.OHLCV, i int, value func(models.OHLCV) float64) bool {
+	v := value(candles[i])
+	for k := 1; k <= divergenceSwing; k++ {
+		if value(candles[i-k]) >= v || value(candles[i+k]) >= v {
+			return false
+		}
+	}
+	return true
+}
+
+func newDivergence(kind string, candles []models.OHLCV, rsi []float64, prev, cur int, prevPrice, price float64) Divergence {
+	return Divergence{
+		Type:      kind,
+		Index:     cur,
+		Date:      candles[cur].Timestamp,
+		PrevIndex: prev,
+		PrevDate:  candles[prev].Timestamp,
+		Price:     price,
+		PrevPrice: prevPrice,
+		RSI:       rsi[cur],
+		PrevRSI:   rsi[prev],
+	}
+}
@@ -299,6 +299,124 @@ func SuperTrendLatest(candles []models.OHLCV, period int, mult float64) models.S
 	return vals[len(vals)-1]
 }
 
+// ParabolicSARPoint is one bar of the Parabolic SAR: the stop level and
+// the trend it trails ("UP" with the stop below price, "DOWN" above).
+type ParabolicSARPoint struct {
+	Value float64
+	Trend string
+}
+
+// ParabolicSAR calculates Wilder's Parabolic Stop-And-Reverse. The
+// acceleration factor starts at step, grows by step with each new extreme
+// point, and is capped at maxStep. When price crosses the stop the trend
+// reverses and the stop restarts at the prior extreme.
+// Default: step=0.02, maxStep=0.2.
+func ParabolicSAR(candles []models.OHLCV, step, maxStep float64) []ParabolicSARPoint {
+	if step <= 0 {
+		step = 0.02
+	}
+	if maxStep <= 0 {
+		maxStep = 0.2
+	}
+
+	n := len(candles)
+	if n < 2 {
+		return nil
+	}
+
+	up := candles[1].Close >= candles[0].Close
+	sar, ep := candles[0].High, candles[0].Low
+	if up {
+		sar, ep = candles[0].Low, candles[0].High
+	}
+	af := step
+
+	result := make([]ParabolicSARPoint, n)
+	result[0] = ParabolicSARPoint{Value: sar, Trend: trendName(up)}
+
+	for i := 1; i < n; i++ {
+		sar += af * (ep - sar)
+		c := candles[i]
+		if up {
+			// The stop may not rise into the prior two bars' range.
+			sar = math.Min(sar, candles[i-1].Low)
+			if i >= 2 {
+				sar = math.Min(sar, candles[i-2].Low)
+			}
+			if c.Low < sar {
+				up, sar, ep, af = false, ep, c.Low, step
+			} else if c.High > ep {
+				ep, af = c.High, math.Min(af+step, maxStep)
+			}
+		} else {
+			sar = math.Max(sar, candles[i-1].High)
+			if i >= 2 {
+				sar = math.Max(sar, candles[i-2].High)
+			}
+			if c.High > sar {
+				up, sar, ep, af = true, ep, c.High, step
+			} else if c.Low < ep {
+				ep, af = c.Low, math.Min(af+step, maxStep)
+			}
+		}
+		result[i] = ParabolicSARPoint{Value: sar, Trend: trendName(up)}
+	}
+
+	return result
+}
+
+func trendName(up bool) string {
+	if up {
+		return "UP"
+	}
+	return "DOWN"
+}
+
+// KeltnerChannels calculates Keltner Channels: an EMA of closes with bands
+// mult × ATR above and below. Default: period=20, multiplier=1.5.
+func KeltnerChannels(candles []models.OHLCV, period int, mult float64) []models.KeltnerData {
+	if period <= 0 {
+		period = 20
+	}
+	if mult <= 0 {
+		mult = 1.5
+	}
+
+	n := len(candles)
+	if n < period {
+		return nil
+	}
+
+	ema := emaCalc(extractCloses(candles), period)
+	atr := ATR(candles, period)
+
+	result := make([]models.KeltnerData, n)
+	for i := period - 1; i < n; i++ {
+		result[i] = models.KeltnerData{
+			Upper:  ema[i] + mult*atr[i],
+			Middle: ema[i],
+			Lower:  ema[i] - mult*atr[i],
+		}
+	}
+
+	return result
+}
+
+// KeltnerLatest returns the most recent Keltner Channel values.
+func KeltnerLatest(candles []models.OHLCV, period int, mult float64) models.KeltnerData {
+	vals := KeltnerChannels(candles, period, mult)
+	if len(vals) == 0 {
+		return models.KeltnerData{}
+	}
+	return vals[len(vals)-1]
+}
+
+// InSqueeze reports whether the Bollinger Bands lie entirely inside the
+// Keltner Channels — the volatility contraction that often precedes a breakout.
+func InSqueeze(bb models.BollingerData, kc models.KeltnerData) bool {
+	return bb.Upper < kc.Upper && bb.Lower > kc.Lower
+}
+
 // ComputeAll calculates all major indicators and returns a TechnicalIndicators struct.
 func ComputeAll(ticker string, candles []models.OHLCV) *models.TechnicalIndicators {
 	if len(candles) == 0 {
@@ -311,6 +429,8 @@ func ComputeAll(ticker string, candles []models.OHLCV) *models.TechnicalIndicato
 		MACD:   MACDLatest(candles, 12, 26, 9),
 		SMA:    make(map[int]float64),
 		EMA:    make(map[int]float64),
+		WMA:    make(map[int]float64),
+		HMA:    make(map[int]float64),
 		Bollinger:  BollingerLatest(candles, 20, 2),
 		SuperTrend: SuperTrendLatest(candles, 7, 3),
 		ATR:    ATRLatest(candles, 14),
@@ -324,6 +444,12 @@ func ComputeAll(ticker string, candles []models.OHLCV) *models.TechnicalIndicato
 		if ema := EMALatest(closes, p); ema > 0 {
 			ti.EMA[p] = ema
 		}
+		if wma := WMALatest(closes, p); wma > 0 {
+			ti.WMA[p] = wma
+		}
+		if hma := HMALatest(closes, p); hma > 0 {
+			ti.HMA[p] = hma
+		}
 	}
 
 	ti.VWAP = VWAPLatest(candles)
@@ -1,6 +1,8 @@
 package technical
 
 import (
+	"math"
+
 	"github.com/seenimoa/openseai/pkg/models"
 )
 
@@ -81,6 +83,52 @@ func WMALatest(data []float64, period int) float64 {
 	return vals[len(vals)-1]
 }
 
+// HMA calculates the Hull Moving Average for the given period:
+// WMA(2*WMA(n/2) - WMA(n), sqrt(n)). It tracks price with far less lag than
+// an SMA or EMA of the same period.
+func HMA(data []float64, period int) []float64 {
+	n := len(data)
+	if n < period || period <= 1 {
+		return nil
+	}
+
+	half := period / 2
+	sqrtPeriod := int(math.Round(math.Sqrt(float64(period))))
+	if sqrtPeriod < 1 {
+		sqrtPeriod = 1
+	}
+
+	wmaHalf := WMA(data, half)
+	wmaFull := WMA(data, period)
+
+	// The raw Hull series is only defined once the full-period WMA is.
+	start := period - 1
+	raw := make([]float64, n-start)
+	for i := start; i < n; i++ {
+		raw[i-start] = 2*wmaHalf[i] - wmaFull[i]
+	}
+
+	smoothed := WMA(raw, sqrtPeriod)
+	if smoothed == nil {
+		return nil
+	}
+
+	result := make([]float64, n)
+	for i := sqrtPeriod - 1; i < len(smoothed); i++ {
+		result[start+i] = smoothed[i]
+	}
+	return result
+}
+
+// HMALatest returns the most recent HMA value.
+func HMALatest(data []float64, period int) float64 {
+	vals := HMA(data, period)
+	if len(vals) == 0 {
+		return 0
+	}
+	return vals[len(vals)-1]
+}
+
 // VWAP calculates Volume Weighted Average Price for the candle series.
 // Typically computed intraday — resets daily. This computes a running VWAP
 // across the entire series.
@@ -139,5 +187,27 @@ func MultiEMA(data []float64, periods []int) map[int]float64 {
 	return result
 }
 
+// MultiWMA computes WMA for multiple periods at once.
+func MultiWMA(data []float64, periods []int) map[int]float64 {
+	result := make(map[int]float64, len(periods))
+	for _, p := range periods {
+		if v := WMALatest(data, p); v > 0 {
+			result[p] = v
+		}
+	}
+	return result
+}
+
+// MultiHMA computes HMA for multiple periods at once.
+func MultiHMA(data []float64, periods []int) map[int]float64 {
+	result := make(map[int]float64, len(periods))
+	for _, p := range periods {
+		if v := HMALatest(data, p); v > 0 {
+			result[p] = v
+		}
+	}
+	return result
+}
+
 // StandardPeriods are the commonly used MA periods for Indian market analysis.
 var StandardPeriods = []int{5, 10, 20, 50, 100, 200}
@@ -305,6 +305,7 @@ func FullTechnicalAnalysis(ticker string, candles []models.OHLCV) *models.Analys
 		Signals:        signals,
 		Recommendation: rec,
 		Confidence:     conf,
+		Score:          models.ScoreSignals(signals),
 		Summary:        summary,
 		Details:        details,
 		Timestamp:      time.Now(),
@@ -1,6 +1,7 @@
 package technical
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -111,6 +112,51 @@ func TestATR(t *testing.T) {
 	}
 }
 
+func TestKeltnerChannels(t *testing.T) {
+	candles := makeCandles(50, 100, 0.3)
+	kc := KeltnerLatest(candles, 20, 1.5)
+	if kc.Upper <= kc.Middle || kc.Middle <= kc.Lower {
+		t.Errorf("invalid Keltner channels: upper=%.2f, middle=%.2f, lower=%.2f",
+			kc.Upper, kc.Middle, kc.Lower)
+	}
+	if KeltnerChannels(candles[:10], 20, 1.5) != nil {
+		t.Error("expected nil with insufficient data")
+	}
+}
+
+func TestSqueeze_LowVolatility(t *testing.T) {
+	// Closes oscillate within ±0.2 while each bar spans ±2: tiny close
+	// dispersion (narrow Bollinger) against a wide true range (wide Keltner).
+	candles := make([]models.OHLCV, 60)
+	for i := range candles {
+		c := 100.0
+		if i%2 == 1 {
+			c = 100.2
+		}
+		candles[i] = models.OHLCV{Open: c, High: c + 2, Low: c - 2, Close: c}
+	}
+	bb := BollingerLatest(candles, 20, 2)
+	kc := KeltnerLatest(candles, 20, 1.5)
+	if !InSqueeze(bb, kc) {
+		t.Errorf("expected squeeze: bb=%+v kc=%+v", bb, kc)
+	}
+}
+
+func TestSqueeze_HighVolatility(t *testing.T) {
+	// A steep trend with narrow bars: closes disperse far more than the
+	// true range, so Bollinger Bands expand beyond the Keltner Channels.
+	candles := make([]models.OHLCV, 60)
+	for i := range candles {
+		c := 100 + 3*float64(i)
+		candles[i] = models.OHLCV{Open: c - 0.5, High: c + 0.5, Low: c - 0.5, Close: c}
+	}
+	bb := BollingerLatest(candles, 20, 2)
+	kc := KeltnerLatest(candles, 20, 1.5)
+	if InSqueeze(bb, kc) {
+		t.Errorf("expected no squeeze: bb=%+v kc=%+v", bb, kc)
+	}
+}
+
 func TestSuperTrend(t *testing.T) {
 	candles := makeCandles(50, 100, 1)
 	results := SuperTrend(candles, 7, 3)
@@ -126,6 +172,24 @@ func TestSuperTrend(t *testing.T) {
 	}
 }
 
+func TestParabolicSAR(t *testing.T) {
+	candles := append(makeCandles(30, 100, 2), makeCandles(30, 160, -2)...)
+	sar := ParabolicSAR(candles, 0.02, 0.2)
+	if len(sar) != len(candles) {
+		t.Fatalf("expected %d points, got %d", len(candles), len(sar))
+	}
+	if sar[20].Trend != "UP" || sar[20].Value >= candles[20].Low {
+		t.Errorf("uptrend: want UP stop below the low, got %+v (low %.2f)", sar[20], candles[20].Low)
+	}
+	last := sar[len(sar)-1]
+	if last.Trend != "DOWN" || last.Value <= candles[len(candles)-1].High {
+		t.Errorf("downtrend: want DOWN stop above the high, got %+v", last)
+	}
+	if ParabolicSAR(candles[:1], 0, 0) != nil {
+		t.Error("expected nil for a single candle")
+	}
+}
+
 func TestComputeAll(t *testing.T) {
 	candles := makeCandles(250, 100, 0.2)
 	ti := ComputeAll("RELIANCE", candles)
@@ -188,6 +252,57 @@ func TestWMA(t *testing.T) {
 	}
 }
 
+func TestWMAWeights(t *testing.T) {
+	data := []float64{1, 2, 3, 4, 5}
+	vals := WMA(data, 4)
+	if vals == nil {
+		t.Fatal("WMA returned nil")
+	}
+	// Weights 1..4, denominator 10.
+	// WMA[3] = (1*1 + 2*2 + 3*3 + 4*4) / 10 = 3.0
+	// WMA[4] = (2*1 + 3*2 + 4*3 + 5*4) / 10 = 4.0
+	if vals[3] != 3.0 {
+		t.Errorf("expected WMA[3]=3.0, got %.4f", vals[3])
+	}
+	if vals[4] != 4.0 {
+		t.Errorf("expected WMA[4]=4.0, got %.4f", vals[4])
+	}
+	if vals[2] != 0 {
+		t.Errorf("expected WMA[2]=0 before warm-up, got %.4f", vals[2])
+	}
+}
+
+func TestHMALessLagThanSMA(t *testing.T) {
+	// Linear ramp: an unbiased average should track the latest value.
+	data := make([]float64, 60)
+	for i := range data {
+		data[i] = float64(100 + i)
+	}
+	latest := data[len(data)-1]
+
+	hma := HMALatest(data, 16)
+	sma := SMALatest(data, 16)
+	if hma == 0 || sma == 0 {
+		t.Fatalf("expected non-zero averages, got HMA=%.4f SMA=%.4f", hma, sma)
+	}
+
+	hmaLag := latest - hma
+	smaLag := latest - sma
+	if hmaLag >= smaLag {
+		t.Errorf("expected HMA lag (%.4f) < SMA lag (%.4f)", hmaLag, smaLag)
+	}
+	// On a pure ramp the Hull MA lags by under one bar (SMA(16) lags by 7.5).
+	if hmaLag > 1 || hmaLag < -1 {
+		t.Errorf("expected HMA close to latest value %.2f, got %.4f", latest, hma)
+	}
+}
+
+func TestHMAInsufficientData(t *testing.T) {
+	if vals := HMA([]float64{1, 2, 3}, 9); vals != nil {
+		t.Error("HMA should return nil for insufficient data")
+	}
+}
+
 func TestVWAP(t *testing.T) {
 	candles := makeCandles(10, 100, 1)
 	vals := VWAP(candles)
@@ -372,3 +487,110 @@ func TestFullTechnicalAnalysis(t *testing.T) {
 		t.Error("expected non-empty summary")
 	}
 }
+
+func TestComputeEWMAVol(t *testing.T) {
+	calm := make([]float64, 60)
+	wild := make([]float64, 60)
+	for i := range calm {
+		sign := 1.0
+		if i%2 == 1 {
+			sign = -1
+		}
+		calm[i] = sign * 0.005
+		wild[i] = sign * 0.02
+	}
+
+	// Constant-magnitude returns have an EWMA volatility of that magnitude.
+	daily := ComputeEWMAVol(calm, DefaultEWMALambda)
+	if math.Abs(daily-0.005) > 1e-12 {
+		t.Errorf("calm daily vol = %f, want 0.005", daily)
+	}
+	if got, want := AnnualizeVol(daily), 0.005*math.Sqrt(252); math.Abs(got-want) > 1e-12 {
+		t.Errorf("annualized vol = %f, want %f", got, want)
+	}
+	if hi := ComputeEWMAVol(wild, DefaultEWMALambda); hi <= daily {
+		t.Errorf("higher-variance returns gave vol %f, want above %f", hi, daily)
+	}
+
+	// Recent shocks weigh more than old ones.
+	early := append([]float64{0.05}, calm[1:]...)
+	late := append(append([]float64{}, calm[:59]...), 0.05)
+	if ComputeEWMAVol(late, 0.94) <= ComputeEWMAVol(early, 0.94) {
+		t.Error("a recent shock should raise EWMA volatility more than an old one")
+	}
+	if ComputeEWMAVol(nil, 0.94) != 0 {
+		t.Error("empty returns should have zero volatility")
+	}
+}
+
+// divergenceSeries builds a bullish RSI divergence: a sharp sell-off to a
+// low at index 27, a rally, then a slow, choppy drift to a lower low at
+// index 47 on which RSI holds higher.
+func divergenceSeries() []models.OHLCV {
+	var closes []float64
+	price := 100.0
+	for i := 0; i < 20; i++ { // range-bound warm-up
+		price += []float64{1, -0.8}[i%2]
+		closes = append(closes, price)
+	}
+	for i := 0; i < 8; i++ { // sharp sell-off
+		price -= 3
+		closes = append(closes, price)
+	}
+	for i := 0; i < 8; i++ { // relief rally
+		price += 2
+		closes = append(closes, price)
+	}
+	for i := 0; i < 12; i++ { // choppy drift lower
+		price += []float64{-3, 1}[i%2]
+		closes = append(closes, price)
+	}
+	price -= 5 // the final leg undercuts the sell-off low
+	closes[len(closes)-1] = price
+	for i := 0; i < 6; i++ { // recovery
+		price += 2
+		closes = append(closes, price)
+	}
+
+	candles := make([]models.OHLCV, len(closes))
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, c := range closes {
+		candles[i] = models.OHLCV{
+			Timestamp: start.AddDate(0, 0, i),
+			Open:      c, High: c + 0.5, Low: c - 0.5, Close: c, Volume: 100000,
+		}
+	}
+	return candles
+}
+
+func TestDetectRSIDivergence(t *testing.T) {
+	candles := divergenceSeries()
+	divs := DetectRSIDivergence(candles, 14)
+	if len(divs) != 1 {
+		t.Fatalf("expected 1 divergence, got %+v", divs)
+	}
+	d := divs[0]
+	if d.Type != "bullish" || d.Index != 47 || d.PrevIndex != 27 {
+		t.Errorf("expected bullish divergence at 47 (from 27), got %s at %d (from %d)", d.Type, d.Index, d.PrevIndex)
+	}
+	if !(d.Price < d.PrevPrice && d.RSI > d.PrevRSI) {
+		t.Errorf("expected lower low with higher RSI, got price %.2f→%.2f RSI %.1f→%.1f", d.PrevPrice, d.Price, d.PrevRSI, d.RSI)
+	}
+	if !d.Date.Equal(candles[47].Timestamp) {
+		t.Errorf("date = %v, want %v", d.Date, candles[47].Timestamp)
+	}
+
+	// Mirroring the series turns it into a bearish divergence.
+	mirrored := make([]models.OHLCV, len(candles))
+	for i, c := range candles {
+		mirrored[i] = models.OHLCV{Timestamp: c.Timestamp, Open: 200 - c.Open, High: 200 - c.Low, Low: 200 - c.High, Close: 200 - c.Close}
+	}
+	divs = DetectRSIDivergence(mirrored, 14)
+	if len(divs) != 1 || divs[0].Type != "bearish" || divs[0].Index != 47 {
+		t.Errorf("expected bearish divergence at 47, got %+v", divs)
+	}
+
+	if divs := DetectRSIDivergence(candles[:10], 14); divs != nil {
+		t.Errorf("expected no divergence without enough data, got %+v", divs)
+	}
+}
@@ -0,0 +1,51 @@
+package technical
+
+import (
+	"math"
+
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// TradingDaysPerYear annualizes daily statistics on NSE.
+const TradingDaysPerYear = 252
+
+// DefaultEWMALambda is the RiskMetrics decay factor for daily returns.
+const DefaultEWMALambda = 0.94
+
+// DailyReturns returns the simple close-to-close returns of candles.
+// A return after a zero close is reported as 0.
+func DailyReturns(candles []models.OHLCV) []float64 {
+	if len(candles) < 2 {
+		return nil
+	}
+	returns := make([]float64, len(candles)-1)
+	for i := 1; i < len(candles); i++ {
+		if candles[i-1].Close > 0 {
+			returns[i-1] = (candles[i].Close - candles[i-1].Close) / candles[i-1].Close
+		}
+	}
+	return returns
+}
+
+// ComputeEWMAVol returns the exponentially-weighted daily volatility of
+// returns, oldest first: σ²ₜ = λσ²ₜ₋₁ + (1−λ)r²ₜ, seeded with the first
+// squared return and assuming a zero mean. A lambda outside (0, 1) uses
+// DefaultEWMALambda.
+func ComputeEWMAVol(returns []float64, lambda float64) float64 {
+	if len(returns) == 0 {
+		return 0
+	}
+	if lambda <= 0 || lambda >= 1 {
+		lambda = DefaultEWMALambda
+	}
+	variance := returns[0] * returns[0]
+	for _, r := range returns[1:] {
+		variance = lambda*variance + (1-lambda)*r*r
+	}
+	return math.Sqrt(variance)
+}
+
+// AnnualizeVol scales a daily volatility to a yearly one (×√252).
+func AnnualizeVol(daily float64) float64 {
+	return daily * math.Sqrt(TradingDaysPerYear)
+}
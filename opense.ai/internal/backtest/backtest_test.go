@@ -1,11 +1,14 @@
 package backtest
 
 import (
+	"fmt"
 	"math"
 	"testing"
 	"time"
 
+	"github.com/seenimoa/openseai/internal/analysis/technical"
 	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
 )
 
 // ════════════════════════════════════════════════════════════════════
@@ -78,11 +81,14 @@ func steadyDowntrend(n int, startPrice float64) []models.OHLCV {
 // simpleTestStrategy is a minimal strategy for testing the engine.
 type simpleTestStrategy struct {
 	name    string
+	warmup  int
 	onBar   func(ctx *StrategyContext, bar models.OHLCV)
 }
 
 func (s *simpleTestStrategy) Name() string                                { return s.name }
 func (s *simpleTestStrategy) Init(_ *StrategyContext)                     {}
+func (s *simpleTestStrategy) Params() []StrategyParam                     { return nil }
+func (s *simpleTestStrategy) WarmupBars() int                             { return s.warmup }
 func (s *simpleTestStrategy) OnBar(ctx *StrategyContext, bar models.OHLCV) {
 	if s.onBar != nil {
 		s.onBar(ctx, bar)
@@ -204,6 +210,85 @@ func TestEngine_BuyAndHold(t *testing.T) {
 	}
 }
 
+func TestEngine_WarmupSkipsEarlyBars(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SlippagePct = 0
+	e := NewEngine(cfg)
+
+	bars := steadyUptrend(80, 100)
+
+	// Buys on every bar it sees; only warm-up should hold it back.
+	firstSeen := -1
+	s := &simpleTestStrategy{
+		name:   "BuyAlways",
+		warmup: 50,
+		onBar: func(ctx *StrategyContext, bar models.OHLCV) {
+			if firstSeen < 0 {
+				firstSeen = ctx.CurrentBar
+			}
+			if ctx.Position == 0 {
+				ctx.Buy(1, "always")
+			}
+		},
+	}
+
+	result, err := e.Run(s, "TCS", bars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if firstSeen != 50 {
+		t.Errorf("expected first OnBar at bar 50, got %d", firstSeen)
+	}
+	if result.WarmupBars != 50 {
+		t.Errorf("expected WarmupBars=50, got %d", result.WarmupBars)
+	}
+	if result.TotalTrades < 1 {
+		t.Fatal("expected a trade after warm-up")
+	}
+	for _, tr := range result.Trades {
+		if tr.EntryDate.Before(bars[50].Timestamp) {
+			t.Errorf("trade entered at %v, before warm-up ended at %v", tr.EntryDate, bars[50].Timestamp)
+		}
+	}
+}
+
+func TestEngine_RunWithProgress(t *testing.T) {
+	e := NewEngine(DefaultConfig())
+	bars := generateBars(250, 100)
+
+	progress := make(chan Progress)
+	var got []Progress
+	collected := make(chan struct{})
+	go func() {
+		for p := range progress {
+			got = append(got, p)
+		}
+		close(collected)
+	}()
+
+	result, err := e.RunWithProgress(&simpleTestStrategy{name: "Idle"}, "TCS", bars, progress)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	<-collected
+
+	if len(got) == 0 {
+		t.Fatal("expected progress updates")
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Percent <= got[i-1].Percent {
+			t.Errorf("progress not increasing: %.2f after %.2f", got[i].Percent, got[i-1].Percent)
+		}
+	}
+	last := got[len(got)-1]
+	if last.Percent != 100 || last.Bar != len(bars) {
+		t.Errorf("expected final update at 100%% (bar %d), got %.2f%% (bar %d)", len(bars), last.Percent, last.Bar)
+	}
+	if last.Equity != result.EquityCurve[len(result.EquityCurve)-1].Value {
+		t.Errorf("final progress equity %.2f != last equity point %.2f", last.Equity, result.EquityCurve[len(result.EquityCurve)-1].Value)
+	}
+}
+
 func TestEngine_SortsBars(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.SlippagePct = 0
@@ -420,6 +505,119 @@ func TestEngine_CancelPending(t *testing.T) {
 	}
 }
 
+func TestEngine_SizingModes(t *testing.T) {
+	bars := generateBars(20, 100)
+	// Same signals in every mode: buy with 90% of cash at bar 2, close at bar 6.
+	signals := func() Strategy {
+		return &simpleTestStrategy{
+			name: "Signals",
+			onBar: func(ctx *StrategyContext, bar models.OHLCV) {
+				if ctx.CurrentBar == 2 && ctx.Position == 0 {
+					ctx.Buy(maxShares(ctx.Cash*0.9, bar.Close), "entry")
+				}
+				if ctx.CurrentBar == 6 {
+					ctx.ClosePosition("exit")
+				}
+			},
+		}
+	}
+	run := func(mode SizingMode, tweak func(*Config)) models.BacktestTrade {
+		t.Helper()
+		cfg := DefaultConfig()
+		cfg.SlippagePct = 0
+		cfg.SizingMode = mode
+		if tweak != nil {
+			tweak(&cfg)
+		}
+		result, err := NewEngine(cfg).Run(signals(), "TEST", bars)
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if len(result.Trades) != 1 {
+			t.Fatalf("%s: expected 1 trade, got %d", mode, len(result.Trades))
+		}
+		return result.Trades[0]
+	}
+
+	requested := run(SizeStrategy, nil)
+	fixedQty := run(SizeFixedQty, func(c *Config) { c.FixedQty = 25 })
+	fractional := run(SizeFixedFractional, func(c *Config) {
+		c.RiskPct = 0.02
+		c.StopLossPct = 0.05
+	})
+	kelly := run(SizeKelly, func(c *Config) { c.KellyCap = 0.3 })
+
+	if fixedQty.Quantity != 25 {
+		t.Errorf("fixed-qty: quantity = %d, want 25", fixedQty.Quantity)
+	}
+
+	// Risking 2% of ₹10L with a 5% stop: (qty × entry × 5%) ≈ ₹20,000.
+	risk := float64(fractional.Quantity) * fractional.EntryPrice * 0.05
+	if want := 0.02 * DefaultConfig().InitialCapital; risk > want || want-risk > fractional.EntryPrice*0.05 {
+		t.Errorf("fixed-fractional: risk = %.2f, want just under %.2f", risk, want)
+	}
+
+	// No closed trades yet, so Kelly commits half its cap: 15% of equity.
+	if value := float64(kelly.Quantity) * kelly.EntryPrice; math.Abs(value-0.15*DefaultConfig().InitialCapital) > kelly.EntryPrice {
+		t.Errorf("kelly: position value = %.2f, want ~%.2f", value, 0.15*DefaultConfig().InitialCapital)
+	}
+
+	if !(fixedQty.Quantity < kelly.Quantity && kelly.Quantity < fractional.Quantity && fractional.Quantity < requested.Quantity) {
+		t.Errorf("unexpected size ordering: fixed-qty %d, kelly %d, fixed-fractional %d, strategy %d",
+			fixedQty.Quantity, kelly.Quantity, fractional.Quantity, requested.Quantity)
+	}
+}
+
+func TestKellyFraction(t *testing.T) {
+	trades := make([]models.BacktestTrade, 0, 10)
+	for i := 0; i < 6; i++ {
+		trades = append(trades, models.BacktestTrade{PnL: 200})
+	}
+	for i := 0; i < 4; i++ {
+		trades = append(trades, models.BacktestTrade{PnL: -100})
+	}
+	// W = 0.6, R = 2: 0.6 − 0.4/2 = 0.4.
+	if f := kellyFraction(trades, 1); math.Abs(f-0.4) > 1e-9 {
+		t.Errorf("kellyFraction = %v, want 0.4", f)
+	}
+	if f := kellyFraction(trades, 0.25); f != 0.25 {
+		t.Errorf("capped kellyFraction = %v, want 0.25", f)
+	}
+	if f := kellyFraction(append(trades[6:], trades[6]), 0.25); f != 0 {
+		t.Errorf("kellyFraction with no wins = %v, want 0", f)
+	}
+}
+
+func TestEngine_RunSplit(t *testing.T) {
+	bars := generateBars(100, 100)
+	engine := NewEngine(DefaultConfig())
+
+	split, err := engine.RunSplit(NewSMACrossover(5, 10), "TEST", bars, 0.7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	is, oos := split.InSample, split.OutOfSample
+	if !is.From.Equal(bars[0].Timestamp) || !is.To.Equal(bars[69].Timestamp) {
+		t.Errorf("in-sample range %s–%s, want %s–%s", is.From, is.To, bars[0].Timestamp, bars[69].Timestamp)
+	}
+	if !oos.From.Equal(bars[70].Timestamp) || !oos.To.Equal(bars[99].Timestamp) {
+		t.Errorf("out-of-sample range %s–%s, want %s–%s", oos.From, oos.To, bars[70].Timestamp, bars[99].Timestamp)
+	}
+	if len(is.EquityCurve) != 70 || len(oos.EquityCurve) != 30 {
+		t.Errorf("expected 70/30 equity points, got %d/%d", len(is.EquityCurve), len(oos.EquityCurve))
+	}
+	if oos.InitialCapital != is.InitialCapital {
+		t.Error("both halves should start from the configured capital")
+	}
+
+	for _, ratio := range []float64{0, 1, 1.5, 0.001} {
+		if _, err := engine.RunSplit(NewSMACrossover(5, 10), "TEST", bars, ratio); err == nil {
+			t.Errorf("ratio %g: expected error", ratio)
+		}
+	}
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Strategy Context Tests
 // ════════════════════════════════════════════════════════════════════
@@ -934,6 +1132,94 @@ func TestSMACrossover_Run(t *testing.T) {
 	}
 }
 
+func TestSMACrossover_Params(t *testing.T) {
+	s := NewSMACrossover(20, 50)
+	params := s.Params()
+	got := map[string]float64{}
+	for _, p := range params {
+		if p.Type != ParamInt {
+			t.Errorf("%s: expected int type, got %s", p.Name, p.Type)
+		}
+		got[p.Name] = p.Default
+	}
+	if got["fast"] != 20 || got["slow"] != 50 || len(got) != 2 {
+		t.Fatalf("expected fast=20 slow=50, got %v", got)
+	}
+}
+
+func TestSMACrossover_ParamOverrideChangesSignals(t *testing.T) {
+	// Oscillating prices so both parameter sets see crossovers.
+	bars := make([]models.OHLCV, 300)
+	base := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	for i := range bars {
+		price := 100 + 10*math.Sin(float64(i)/8)
+		bars[i] = models.OHLCV{Timestamp: base.AddDate(0, 0, i), Open: price, High: price, Low: price, Close: price, Volume: 1000}
+	}
+
+	// signalBars records the bars on which the strategy queued an order.
+	signalBars := func(s Strategy) []int {
+		var got []int
+		rec := &simpleTestStrategy{name: s.Name(), onBar: func(ctx *StrategyContext, bar models.OHLCV) {
+			before := len(ctx.orders)
+			s.OnBar(ctx, bar)
+			if len(ctx.orders) > before {
+				got = append(got, ctx.CurrentBar)
+			}
+		}}
+		if _, err := NewEngine(DefaultConfig()).Run(rec, "TEST", bars); err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		return got
+	}
+
+	defaults := signalBars(NewSMACrossover(20, 50))
+
+	tuned := NewSMACrossover(20, 50)
+	overrides, err := ParseParams([]string{"fast=3", "slow=10"})
+	if err != nil {
+		t.Fatalf("ParseParams: %v", err)
+	}
+	if err := ApplyParams(tuned, overrides); err != nil {
+		t.Fatalf("ApplyParams: %v", err)
+	}
+	if tuned.FastPeriod != 3 || tuned.SlowPeriod != 10 {
+		t.Fatalf("override not applied: %+v", tuned)
+	}
+
+	got := signalBars(tuned)
+	if len(got) == 0 {
+		t.Fatal("expected tuned strategy to signal on oscillating data")
+	}
+	if fmt.Sprint(got) == fmt.Sprint(defaults) {
+		t.Errorf("expected overriding fast/slow to change signals, both signalled on bars %v", got)
+	}
+}
+
+func TestApplyParams_Invalid(t *testing.T) {
+	s := NewSMACrossover(20, 50)
+	cases := map[string]map[string]float64{
+		"unknown":      {"period": 5},
+		"non-integer":  {"fast": 2.5},
+		"out of range": {"fast": 1},
+		"fast >= slow": {"fast": 60},
+		"fast == slow": {"fast": 30, "slow": 30},
+	}
+	for name, overrides := range cases {
+		if err := ApplyParams(s, overrides); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if s.FastPeriod != 20 || s.SlowPeriod != 50 {
+		t.Errorf("invalid overrides must not change params, got %+v", s)
+	}
+	if err := ApplyParams(NewMACDCrossover(12, 26, 9), map[string]float64{"slow": 10}); err == nil {
+		t.Error("expected MACD slow below fast to be rejected")
+	}
+	if _, err := ParseParams([]string{"fast"}); err == nil {
+		t.Error("expected error for param without value")
+	}
+}
+
 func TestRSIMeanReversion_Name(t *testing.T) {
 	s := NewRSIMeanReversion(14, 30, 70)
 	if s.Name() != "RSI Mean Reversion" {
@@ -1031,17 +1317,112 @@ func TestMACDCrossover_Run(t *testing.T) {
 	}
 }
 
+// positionRecorder wraps a strategy and records the position seen at each bar.
+type positionRecorder struct {
+	Strategy
+	positions map[int]int
+}
+
+func (r *positionRecorder) OnBar(ctx *StrategyContext, bar models.OHLCV) {
+	r.positions[ctx.CurrentBar] = ctx.Position
+	r.Strategy.OnBar(ctx, bar)
+}
+
+func TestParabolicSAR_ReversesLongToShort(t *testing.T) {
+	bars := generateBars(80, 100)
+	sar := technical.ParabolicSAR(bars, 0.02, 0.2)
+	flip := -1
+	for i := 10; i < len(sar); i++ {
+		if sar[i-1].Trend == "UP" && sar[i].Trend == "DOWN" {
+			flip = i
+			break
+		}
+	}
+	if flip < 0 || flip+1 >= len(bars) {
+		t.Fatalf("expected the SAR to flip down in the series, got %v", sar)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Product = models.NRML
+	cfg.SlippagePct = 0
+	rec := &positionRecorder{Strategy: NewParabolicSARStrategy(0.02, 0.2), positions: map[int]int{}}
+	result, err := NewEngine(cfg).Run(rec, "TEST", bars)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The reversal is signalled on the flip bar and fills at the next open.
+	if rec.positions[flip] <= 0 {
+		t.Errorf("position at flip bar %d = %d, want long", flip, rec.positions[flip])
+	}
+	if rec.positions[flip+1] >= 0 {
+		t.Errorf("position after flip bar %d = %d, want short", flip+1, rec.positions[flip+1])
+	}
+
+	var closedLong bool
+	for _, tr := range result.Trades {
+		if tr.Side == models.Buy && tr.ExitDate.Equal(bars[flip+1].Timestamp) {
+			closedLong = true
+		}
+	}
+	if !closedLong {
+		t.Errorf("expected the long to close on the reversal bar, trades: %+v", result.Trades)
+	}
+}
+
+func TestReverse_UsesSizingMode(t *testing.T) {
+	bars := generateBars(80, 100)
+	cfg := DefaultConfig()
+	cfg.Product = models.NRML
+	cfg.SlippagePct = 0
+	cfg.SizingMode = SizeFixedQty
+	cfg.FixedQty = 7
+	rec := &positionRecorder{Strategy: NewParabolicSARStrategy(0.02, 0.2), positions: map[int]int{}}
+	if _, err := NewEngine(cfg).Run(rec, "TEST", bars); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var long, short bool
+	for i, pos := range rec.positions {
+		switch pos {
+		case 7:
+			long = true
+		case -7:
+			short = true
+		case 0:
+		default:
+			t.Fatalf("position at bar %d = %d, want ±7", i, pos)
+		}
+	}
+	if !long || !short {
+		t.Errorf("expected the fixed quantity on both sides, got %v", rec.positions)
+	}
+}
+
+func TestReverse_CNCOnlyExits(t *testing.T) {
+	bars := generateBars(80, 100)
+	cfg := DefaultConfig()
+	rec := &positionRecorder{Strategy: NewParabolicSARStrategy(0.02, 0.2), positions: map[int]int{}}
+	if _, err := NewEngine(cfg).Run(rec, "TEST", bars); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, pos := range rec.positions {
+		if pos < 0 {
+			t.Fatalf("CNC backtest went short at bar %d", i)
+		}
+	}
+}
+
 func TestBuiltinStrategies(t *testing.T) {
 	strategies := BuiltinStrategies()
-	if len(strategies) != 5 {
-		t.Errorf("expected 5 built-in strategies, got %d", len(strategies))
+	if len(strategies) != 6 {
+		t.Errorf("expected 6 built-in strategies, got %d", len(strategies))
 	}
 
 	names := make(map[string]bool)
 	for _, s := range strategies {
 		names[s.Name()] = true
 	}
-	expected := []string{"SMA Crossover", "RSI Mean Reversion", "SuperTrend", "VWAP Breakout", "MACD Crossover"}
+	expected := []string{"SMA Crossover", "RSI Mean Reversion", "SuperTrend", "VWAP Breakout", "MACD Crossover", "Parabolic SAR"}
 	for _, n := range expected {
 		if !names[n] {
 			t.Errorf("missing built-in strategy: %s", n)
@@ -1130,3 +1511,62 @@ func TestIntegration_UptrendProfit(t *testing.T) {
 		t.Errorf("expected near-positive returns in uptrend, got %f%%", result.TotalReturnPct)
 	}
 }
+
+func TestTradingRange(t *testing.T) {
+	// Sat 2025-08-16 moves forward to Mon 2025-08-18; Fri 2025-08-29 is a trading day.
+	from, to, err := TradingRange(
+		time.Date(2025, 8, 16, 0, 0, 0, 0, utils.IST),
+		time.Date(2025, 8, 29, 0, 0, 0, 0, utils.IST),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := utils.FormatDateIST(from); got != "2025-08-18" {
+		t.Errorf("from = %s, want 2025-08-18", got)
+	}
+	if got := utils.FormatDateIST(to); got != "2025-08-29" {
+		t.Errorf("to = %s, want 2025-08-29", got)
+	}
+
+	// A range covering only a holiday and a weekend has no trading days.
+	_, _, err = TradingRange(
+		time.Date(2025, 8, 15, 0, 0, 0, 0, utils.IST),
+		time.Date(2025, 8, 17, 0, 0, 0, 0, utils.IST),
+	)
+	if err == nil {
+		t.Error("expected error for a range with no trading days")
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	a := models.BacktestResult{
+		StrategyName: "SMA Crossover", Ticker: "TCS",
+		TotalReturnPct: 12, SharpeRatio: 0.8, MaxDrawdownPct: 10, WinRate: 50, TotalTrades: 20,
+	}
+	b := a
+	b.SharpeRatio = 1.2
+	b.MaxDrawdownPct = 15
+	b.TotalTrades = 24
+
+	diff := DiffResults(a, b)
+	want := map[string]Change{
+		"Sharpe Ratio":   Improved,
+		"Max Drawdown %": Regressed,
+		"Total Return %": Unchanged,
+		"Total Trades":   Changed,
+	}
+	for _, m := range diff.Metrics {
+		if w, ok := want[m.Metric]; ok && m.Change != w {
+			t.Errorf("%s: change = %s, want %s", m.Metric, m.Change, w)
+		}
+		if m.Metric == "Sharpe Ratio" && math.Abs(m.Delta-0.4) > 1e-9 {
+			t.Errorf("Sharpe delta = %f, want 0.4", m.Delta)
+		}
+	}
+	if n := len(diff.Improved()); n != 1 {
+		t.Errorf("improved = %d, want 1", n)
+	}
+	if n := len(diff.Regressed()); n != 1 {
+		t.Errorf("regressed = %d, want 1", n)
+	}
+}
@@ -0,0 +1,100 @@
+package backtest
+
+import (
+	"math"
+
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// ════════════════════════════════════════════════════════════════════
+// Result Comparison
+// ════════════════════════════════════════════════════════════════════
+
+// Change labels how a metric moved from one run to the next.
+type Change string
+
+const (
+	Improved  Change = "improved"
+	Regressed Change = "regressed"
+	Unchanged Change = "unchanged"
+	Changed   Change = "changed" // moved, but neither direction is better
+)
+
+// MetricDelta is one metric of two backtest runs and how it changed.
+type MetricDelta struct {
+	Metric string  `json:"metric"`
+	A      float64 `json:"a"`
+	B      float64 `json:"b"`
+	Delta  float64 `json:"delta"` // B - A
+	Change Change  `json:"change"`
+}
+
+// ResultComparison holds the per-metric deltas from run A to run B.
+type ResultComparison struct {
+	A       string        `json:"a"` // "strategy on ticker" for run A
+	B       string        `json:"b"`
+	Metrics []MetricDelta `json:"metrics"`
+}
+
+// Improved returns the metrics that got better from A to B.
+func (c ResultComparison) Improved() []MetricDelta { return c.filter(Improved) }
+
+// Regressed returns the metrics that got worse from A to B.
+func (c ResultComparison) Regressed() []MetricDelta { return c.filter(Regressed) }
+
+func (c ResultComparison) filter(ch Change) []MetricDelta {
+	var out []MetricDelta
+	for _, m := range c.Metrics {
+		if m.Change == ch {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Metric directions for DiffResults.
+const (
+	higherIsBetter = 1
+	lowerIsBetter  = -1
+	neutral        = 0
+)
+
+// DiffResults compares run b against baseline a. Returns, ratios, and win
+// rate improve when they rise; max drawdown improves when it falls; trade
+// count is reported as changed without a direction.
+func DiffResults(a, b models.BacktestResult) ResultComparison {
+	metrics := []struct {
+		name   string
+		a, b   float64
+		better int
+	}{
+		{"Total Return %", a.TotalReturnPct, b.TotalReturnPct, higherIsBetter},
+		{"CAGR %", a.CAGR, b.CAGR, higherIsBetter},
+		{"Sharpe Ratio", a.SharpeRatio, b.SharpeRatio, higherIsBetter},
+		{"Sortino Ratio", a.SortinoRatio, b.SortinoRatio, higherIsBetter},
+		{"Max Drawdown %", a.MaxDrawdownPct, b.MaxDrawdownPct, lowerIsBetter},
+		{"Win Rate %", a.WinRate, b.WinRate, higherIsBetter},
+		{"Profit Factor", a.ProfitFactor, b.ProfitFactor, higherIsBetter},
+		{"Total Trades", float64(a.TotalTrades), float64(b.TotalTrades), neutral},
+	}
+
+	c := ResultComparison{
+		A: a.StrategyName + " on " + a.Ticker,
+		B: b.StrategyName + " on " + b.Ticker,
+	}
+	for _, m := range metrics {
+		d := MetricDelta{Metric: m.name, A: m.a, B: m.b, Delta: m.b - m.a}
+		switch {
+		case math.Abs(d.Delta) < 1e-9:
+			d.Change = Unchanged
+		case m.better == neutral:
+			d.Change = Changed
+		case (d.Delta > 0) == (m.better == higherIsBetter):
+			d.Change = Improved
+		default:
+			d.Change = Regressed
+		}
+		c.Metrics = append(c.Metrics, d)
+	}
+	return c
+}
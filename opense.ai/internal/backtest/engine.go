@@ -12,6 +12,7 @@ import (
 
 	"github.com/seenimoa/openseai/internal/broker"
 	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
 )
 
 // ════════════════════════════════════════════════════════════════════
@@ -26,6 +27,13 @@ type Config struct {
 	Benchmark      []models.OHLCV  // optional benchmark data (e.g., Nifty 50) for comparison
 	BenchmarkName  string           // benchmark name (default: "NIFTY 50")
 	RiskFreeRate   float64          // annual risk-free rate for Sharpe (default: 0.065 = 6.5% India)
+
+	// Position sizing applied when a position is opened; see SizingMode.
+	SizingMode  SizingMode
+	RiskPct     float64 // SizeFixedFractional: equity fraction risked per trade (default: 0.01)
+	StopLossPct float64 // SizeFixedFractional: assumed loss per share as a fraction of entry (default: 0.05)
+	FixedQty    int     // SizeFixedQty: shares per trade
+	KellyCap    float64 // SizeKelly: maximum equity fraction committed (default: 0.25)
 }
 
 // DefaultConfig returns sensible defaults for Indian markets.
@@ -39,6 +47,23 @@ func DefaultConfig() Config {
 	}
 }
 
+// TradingRange snaps a backtest date range to NSE trading days: from moves
+// forward and to moves back past weekends and market holidays. It returns an
+// error if no trading day falls within the range.
+func TradingRange(from, to time.Time) (time.Time, time.Time, error) {
+	if !utils.IsTradingDay(from) {
+		from = utils.NextTradingDay(from)
+	}
+	if !utils.IsTradingDay(to) {
+		to = utils.PrevTradingDay(to)
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("no trading days between %s and %s",
+			utils.FormatDateIST(from), utils.FormatDateIST(to))
+	}
+	return from, to, nil
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Engine — Event-Driven Backtesting
 // ════════════════════════════════════════════════════════════════════
@@ -63,12 +88,42 @@ func NewEngine(cfg Config) *Engine {
 	if cfg.RiskFreeRate <= 0 {
 		cfg.RiskFreeRate = 0.065
 	}
+	if cfg.RiskPct <= 0 {
+		cfg.RiskPct = defaultRiskPct
+	}
+	if cfg.StopLossPct <= 0 {
+		cfg.StopLossPct = defaultStopLossPct
+	}
+	if cfg.KellyCap <= 0 {
+		cfg.KellyCap = defaultKellyCap
+	}
 	return &Engine{cfg: cfg}
 }
 
+// Progress reports how far a backtest has got. Sent by RunWithProgress.
+type Progress struct {
+	Bar       int       `json:"bar"` // bars processed so far
+	TotalBars int       `json:"total_bars"`
+	Percent   float64   `json:"percent"` // 0–100
+	Date      time.Time `json:"date"`    // timestamp of the latest processed bar
+	Equity    float64   `json:"equity"`  // equity at the latest processed bar
+}
+
 // Run executes the strategy against the provided OHLCV bars and returns
 // a BacktestResult with full trade log, equity curve, and performance metrics.
 func (e *Engine) Run(strategy Strategy, ticker string, bars []models.OHLCV) (*models.BacktestResult, error) {
+	return e.RunWithProgress(strategy, ticker, bars, nil)
+}
+
+// RunWithProgress is like Run but sends a Progress update on the channel each
+// time another whole percent of the bars has been processed, ending with 100%.
+// Sends block, so the caller must drain the channel. The channel is closed
+// when the run returns. A nil channel disables progress reporting.
+func (e *Engine) RunWithProgress(strategy Strategy, ticker string, bars []models.OHLCV, progress chan<- Progress) (*models.BacktestResult, error) {
+	if progress != nil {
+		defer close(progress)
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -104,6 +159,8 @@ func (e *Engine) Run(strategy Strategy, ticker string, bars []models.OHLCV) (*mo
 
 	// Let strategy initialize
 	strategy.Init(ctx)
+	warmup := strategy.WarmupBars()
+	lastPct := -1
 
 	// Process bars one by one
 	for i := 0; i < len(sorted); i++ {
@@ -113,8 +170,10 @@ func (e *Engine) Run(strategy Strategy, ticker string, bars []models.OHLCV) (*mo
 		// Process pending orders at current bar's open
 		e.processPendingOrders(ctx, sorted[i])
 
-		// Call strategy
-		strategy.OnBar(ctx, sorted[i])
+		// Call strategy once its indicators have warmed up
+		if i >= warmup {
+			strategy.OnBar(ctx, sorted[i])
+		}
 
 		// Record equity
 		equity := ctx.Cash
@@ -127,6 +186,20 @@ func (e *Engine) Run(strategy Strategy, ticker string, bars []models.OHLCV) (*mo
 			Date:  sorted[i].Timestamp,
 			Value: equity,
 		})
+
+		if progress != nil {
+			pct := (i + 1) * 100 / len(sorted)
+			if pct > lastPct {
+				lastPct = pct
+				progress <- Progress{
+					Bar:       i + 1,
+					TotalBars: len(sorted),
+					Percent:   float64(i+1) / float64(len(sorted)) * 100,
+					Date:      sorted[i].Timestamp,
+					Equity:    equity,
+				}
+			}
+		}
 	}
 
 	// Close any open position at last bar's close
@@ -151,6 +224,7 @@ type pendingOrder struct {
 	Price        float64 // limit price
 	TriggerPrice float64 // SL trigger
 	Reason       string
+	Reverse      bool // stop-and-reverse: close the opposite position, then open on Side
 }
 
 func (e *Engine) processPendingOrders(ctx *StrategyContext, bar models.OHLCV) {
@@ -165,7 +239,11 @@ func (e *Engine) processPendingOrders(ctx *StrategyContext, bar models.OHLCV) {
 			} else {
 				fillPrice *= (1 - ctx.slippage)
 			}
-			e.executeFill(ctx, o, fillPrice, bar.Timestamp)
+			if o.Reverse {
+				e.executeReverse(ctx, o, fillPrice, bar.Timestamp)
+			} else {
+				e.executeFill(ctx, o, fillPrice, bar.Timestamp)
+			}
 		} else {
 			remaining = append(remaining, o)
 		}
@@ -204,7 +282,13 @@ func (e *Engine) tryFill(o pendingOrder, bar models.OHLCV) (bool, float64) {
 
 func (e *Engine) executeFill(ctx *StrategyContext, o pendingOrder, fillPrice float64, ts time.Time) {
 	qty := o.Quantity
+	if ctx.Position == 0 {
+		qty = e.positionSize(ctx, o.Side, qty, fillPrice)
+	}
 	if qty <= 0 {
+		if ctx.Position == 0 && e.cfg.SizingMode != SizeStrategy {
+			return // sized to nothing
+		}
 		qty = 1
 	}
 
@@ -303,6 +387,43 @@ func (e *Engine) executeFill(ctx *StrategyContext, o pendingOrder, fillPrice flo
 	}
 }
 
+// executeReverse fills a stop-and-reverse order: it closes a position on
+// the other side of o, then opens one on o's side at the same price, sized
+// by positionSize like any new position. If the close does not fill, or
+// the position is already on o's side, no new position is opened.
+func (e *Engine) executeReverse(ctx *StrategyContext, o pendingOrder, fillPrice float64, ts time.Time) {
+	opposite := (o.Side == models.Buy && ctx.Position < 0) || (o.Side == models.Sell && ctx.Position > 0)
+	if opposite {
+		closeQty := ctx.Position
+		if closeQty < 0 {
+			closeQty = -closeQty
+		}
+		e.executeFill(ctx, pendingOrder{Side: o.Side, OrderType: o.OrderType, Quantity: closeQty, Reason: o.Reason}, fillPrice, ts)
+	}
+	if ctx.Position != 0 {
+		return
+	}
+
+	qty := e.positionSize(ctx, o.Side, o.Quantity, fillPrice)
+	if qty <= 0 && e.cfg.SizingMode == SizeStrategy {
+		qty = affordableQty(ctx, fillPrice) // the strategy left the size to the cash
+	}
+	if qty <= 0 {
+		return
+	}
+	e.executeFill(ctx, pendingOrder{Side: o.Side, OrderType: o.OrderType, Quantity: qty, Reason: o.Reason}, fillPrice, ts)
+}
+
+// affordableQty returns the most shares the cash balance pays for at
+// price, charges included.
+func affordableQty(ctx *StrategyContext, price float64) int {
+	qty := maxShares(ctx.Cash, price)
+	for qty > 0 && price*float64(qty)+broker.CalculateBrokerage(price, price, qty, ctx.product).Total > ctx.Cash {
+		qty--
+	}
+	return qty
+}
+
 func (e *Engine) forceClose(ctx *StrategyContext, bar models.OHLCV) {
 	if ctx.Position > 0 {
 		o := pendingOrder{
@@ -338,6 +459,7 @@ func (e *Engine) buildResult(strategy Strategy, ticker string, bars []models.OHL
 		FinalCapital:   finalEquity,
 		TotalReturn:    finalEquity - e.cfg.InitialCapital,
 		TotalReturnPct: ((finalEquity - e.cfg.InitialCapital) / e.cfg.InitialCapital) * 100,
+		WarmupBars:     strategy.WarmupBars(),
 		Trades:         ctx.trades,
 		EquityCurve:    ctx.equity,
 	}
@@ -0,0 +1,134 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ════════════════════════════════════════════════════════════════════
+// Strategy Parameters
+// ════════════════════════════════════════════════════════════════════
+
+// Parameter types reported in StrategyParam.Type.
+const (
+	ParamInt   = "int"
+	ParamFloat = "float"
+)
+
+// StrategyParam describes one tunable strategy parameter.
+type StrategyParam struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"` // ParamInt or ParamFloat
+	Default     float64 `json:"default"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	Description string  `json:"description,omitempty"`
+}
+
+// ParamSetter is implemented by strategies whose parameters can be overridden.
+type ParamSetter interface {
+	// SetParam sets the named parameter. The value has already been
+	// validated against the strategy's Params.
+	SetParam(name string, value float64) error
+}
+
+// ParamValidator is implemented by strategies whose parameters constrain
+// each other, such as a fast period that must stay below the slow one.
+type ParamValidator interface {
+	// ValidateParams checks the full set of values the strategy would run
+	// with, keyed by parameter name.
+	ValidateParams(values map[string]float64) error
+}
+
+// ParseParams parses "name=value" pairs (as given by repeated --param flags).
+func ParseParams(pairs []string) (map[string]float64, error) {
+	params := make(map[string]float64, len(pairs))
+	for _, p := range pairs {
+		name, raw, ok := strings.Cut(p, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid param %q; use name=value", p)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for param %q: %w", name, err)
+		}
+		params[name] = v
+	}
+	return params, nil
+}
+
+// ApplyParams validates overrides against s.Params() and applies them.
+// Unknown names, out-of-range values, non-integer values for int
+// parameters, and combinations a ParamValidator rejects are refused
+// before any parameter is changed.
+func ApplyParams(s Strategy, overrides map[string]float64) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+	setter, ok := s.(ParamSetter)
+	if !ok {
+		return fmt.Errorf("strategy %q does not accept parameters", s.Name())
+	}
+
+	schema := make(map[string]StrategyParam)
+	for _, p := range s.Params() {
+		schema[p.Name] = p
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p, ok := schema[name]
+		if !ok {
+			return fmt.Errorf("strategy %q has no parameter %q", s.Name(), name)
+		}
+		v := overrides[name]
+		if p.Type == ParamInt && v != math.Trunc(v) {
+			return fmt.Errorf("parameter %q must be an integer, got %v", name, v)
+		}
+		if v < p.Min || v > p.Max {
+			return fmt.Errorf("parameter %q out of range [%v, %v]: %v", name, p.Min, p.Max, v)
+		}
+	}
+
+	if pv, ok := s.(ParamValidator); ok {
+		values := make(map[string]float64, len(schema))
+		for name, p := range schema {
+			values[name] = p.Default
+		}
+		for name, v := range overrides {
+			values[name] = v
+		}
+		if err := pv.ValidateParams(values); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		if err := setter.SetParam(name, overrides[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateFastSlow rejects a fast period that is not below the slow one.
+func validateFastSlow(values map[string]float64) error {
+	if values["fast"] >= values["slow"] {
+		return fmt.Errorf("parameter \"fast\" (%v) must be less than \"slow\" (%v)", values["fast"], values["slow"])
+	}
+	return nil
+}
+
+// errUnknownParam is returned by SetParam for names not in the strategy's schema.
+func errUnknownParam(s Strategy, name string) error {
+	return fmt.Errorf("strategy %q has no parameter %q", s.Name(), name)
+}
@@ -0,0 +1,113 @@
+package backtest
+
+import (
+	"math"
+
+	"github.com/seenimoa/openseai/internal/broker"
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// ════════════════════════════════════════════════════════════════════
+// Position Sizing
+// ════════════════════════════════════════════════════════════════════
+
+// SizingMode selects how the engine sizes a position when it opens one.
+// Orders that add to or close a position keep the strategy's quantity.
+type SizingMode int
+
+const (
+	// SizeStrategy uses the quantity the strategy asked for (default).
+	SizeStrategy SizingMode = iota
+	// SizeFixedFractional risks Config.RiskPct of equity per trade, taking
+	// Config.StopLossPct of the entry price as the loss per share.
+	SizeFixedFractional
+	// SizeFixedQty trades Config.FixedQty shares every time.
+	SizeFixedQty
+	// SizeKelly commits the Kelly fraction of equity implied by the win rate
+	// and payoff of the trades closed so far, capped at Config.KellyCap.
+	SizeKelly
+)
+
+// String returns the sizing mode name.
+func (m SizingMode) String() string {
+	switch m {
+	case SizeFixedFractional:
+		return "fixed-fractional"
+	case SizeFixedQty:
+		return "fixed-qty"
+	case SizeKelly:
+		return "kelly"
+	default:
+		return "strategy"
+	}
+}
+
+// Sizing defaults applied by NewEngine when the mode needs them.
+const (
+	defaultRiskPct     = 0.01 // 1% of equity per trade
+	defaultStopLossPct = 0.05 // 5% below entry
+	defaultKellyCap    = 0.25
+
+	// kellyMinTrades is how many closed trades SizeKelly needs before it
+	// trusts their statistics. Until then it commits half of KellyCap.
+	kellyMinTrades = 5
+)
+
+// positionSize returns the quantity for an order that opens a position at
+// price, or requested when the mode leaves sizing to the strategy. Longs
+// are capped at what the cash balance can pay for, charges included.
+func (e *Engine) positionSize(ctx *StrategyContext, side models.OrderSide, requested int, price float64) int {
+	if price <= 0 {
+		return requested
+	}
+	equity := ctx.Cash // flat, so cash is the whole account
+
+	var qty int
+	switch e.cfg.SizingMode {
+	case SizeFixedFractional:
+		qty = int(equity * e.cfg.RiskPct / (price * e.cfg.StopLossPct))
+	case SizeFixedQty:
+		qty = e.cfg.FixedQty
+	case SizeKelly:
+		qty = int(equity * kellyFraction(ctx.trades, e.cfg.KellyCap) / price)
+	default:
+		return requested
+	}
+
+	if side == models.Buy {
+		qty = min(qty, int(ctx.Cash/price))
+		for qty > 0 && price*float64(qty)+broker.CalculateBrokerage(price, price, qty, ctx.product).Total > ctx.Cash {
+			qty--
+		}
+	}
+	return max(qty, 0)
+}
+
+// kellyFraction returns the Kelly fraction W − (1−W)/R for trades, where W
+// is the win rate and R the average win over the average loss, clamped to
+// [0, limit]. With fewer than kellyMinTrades trades it returns limit/2.
+func kellyFraction(trades []models.BacktestTrade, limit float64) float64 {
+	if len(trades) < kellyMinTrades {
+		return limit / 2
+	}
+	var wins, losses int
+	var winSum, lossSum float64
+	for _, t := range trades {
+		if t.PnL > 0 {
+			wins++
+			winSum += t.PnL
+		} else {
+			losses++
+			lossSum -= t.PnL
+		}
+	}
+	if wins == 0 {
+		return 0
+	}
+	if losses == 0 || lossSum == 0 {
+		return limit
+	}
+	w := float64(wins) / float64(len(trades))
+	payoff := (winSum / float64(wins)) / (lossSum / float64(losses))
+	return math.Max(0, math.Min(w-(1-w)/payoff, limit))
+}
@@ -0,0 +1,63 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// ════════════════════════════════════════════════════════════════════
+// In-Sample / Out-of-Sample Split
+// ════════════════════════════════════════════════════════════════════
+
+// sharpeDegradationRatio is the fraction of in-sample Sharpe below which
+// the out-of-sample Sharpe is flagged as degraded.
+const sharpeDegradationRatio = 0.5
+
+// SplitResult holds the in-sample and out-of-sample runs of a strategy.
+type SplitResult struct {
+	Ratio       float64                `json:"ratio"` // fraction of bars in-sample
+	InSample    *models.BacktestResult `json:"in_sample"`
+	OutOfSample *models.BacktestResult `json:"out_of_sample"`
+	Degraded    bool                   `json:"degraded"` // OOS Sharpe much worse than in-sample
+}
+
+// RunSplit runs the strategy separately on the first ratio of the bars
+// (in-sample) and on the remainder (out-of-sample). Each run starts from
+// the configured initial capital and re-warms the strategy's indicators.
+// Degraded is set when the out-of-sample Sharpe ratio falls below half of a
+// positive in-sample Sharpe ratio.
+func (e *Engine) RunSplit(strategy Strategy, ticker string, bars []models.OHLCV, ratio float64) (*SplitResult, error) {
+	if ratio <= 0 || ratio >= 1 {
+		return nil, fmt.Errorf("split ratio must be between 0 and 1, got %g", ratio)
+	}
+
+	sorted := make([]models.OHLCV, len(bars))
+	copy(sorted, bars)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	cut := int(float64(len(sorted)) * ratio)
+	if cut < 2 || len(sorted)-cut < 2 {
+		return nil, fmt.Errorf("insufficient data: %d bars cannot be split %g/%g with at least 2 bars each",
+			len(sorted), ratio, 1-ratio)
+	}
+
+	is, err := e.Run(strategy, ticker, sorted[:cut])
+	if err != nil {
+		return nil, fmt.Errorf("in-sample: %w", err)
+	}
+	oos, err := e.Run(strategy, ticker, sorted[cut:])
+	if err != nil {
+		return nil, fmt.Errorf("out-of-sample: %w", err)
+	}
+
+	return &SplitResult{
+		Ratio:       ratio,
+		InSample:    is,
+		OutOfSample: oos,
+		Degraded:    is.SharpeRatio > 0 && oos.SharpeRatio < is.SharpeRatio*sharpeDegradationRatio,
+	}, nil
+}
@@ -17,6 +17,7 @@ func BuiltinStrategies() []Strategy {
 		NewSuperTrendStrategy(7, 3.0),
 		NewVWAPBreakout(20),
 		NewMACDCrossover(12, 26, 9),
+		NewParabolicSARStrategy(0.02, 0.2),
 	}
 }
 
@@ -37,10 +38,42 @@ func NewSMACrossover(fast, slow int) *SMACrossover {
 }
 
 func (s *SMACrossover) Name() string { return "SMA Crossover" }
+func (s *SMACrossover) Description() string {
+	return "Buys when the fast SMA crosses above the slow SMA, sells when it crosses below"
+}
 func (s *SMACrossover) Init(_ *StrategyContext) {}
 
+// Params returns the fast and slow SMA periods.
+func (s *SMACrossover) Params() []StrategyParam {
+	return []StrategyParam{
+		{Name: "fast", Type: ParamInt, Default: float64(s.FastPeriod), Min: 2, Max: 200, Description: "fast SMA period"},
+		{Name: "slow", Type: ParamInt, Default: float64(s.SlowPeriod), Min: 3, Max: 400, Description: "slow SMA period"},
+	}
+}
+
+// SetParam sets the fast or slow SMA period.
+func (s *SMACrossover) SetParam(name string, value float64) error {
+	switch name {
+	case "fast":
+		s.FastPeriod = int(value)
+	case "slow":
+		s.SlowPeriod = int(value)
+	default:
+		return errUnknownParam(s, name)
+	}
+	return nil
+}
+
+// ValidateParams requires the fast SMA period to be below the slow one.
+func (s *SMACrossover) ValidateParams(values map[string]float64) error {
+	return validateFastSlow(values)
+}
+
+// WarmupBars returns the bars needed before the slow SMA is valid.
+func (s *SMACrossover) WarmupBars() int { return s.SlowPeriod + 1 }
+
 func (s *SMACrossover) OnBar(ctx *StrategyContext, bar models.OHLCV) {
-	if ctx.CurrentBar < s.SlowPeriod+1 {
+	if ctx.CurrentBar < s.WarmupBars() {
 		return
 	}
 
@@ -97,10 +130,40 @@ func NewRSIMeanReversion(period int, oversold, overbought float64) *RSIMeanRever
 }
 
 func (s *RSIMeanReversion) Name() string { return "RSI Mean Reversion" }
+func (s *RSIMeanReversion) Description() string {
+	return "Buys when RSI recovers above the oversold level, sells when it crosses above overbought"
+}
 func (s *RSIMeanReversion) Init(_ *StrategyContext) {}
 
+// Params returns the RSI period and oversold/overbought thresholds.
+func (s *RSIMeanReversion) Params() []StrategyParam {
+	return []StrategyParam{
+		{Name: "period", Type: ParamInt, Default: float64(s.Period), Min: 2, Max: 100, Description: "RSI period"},
+		{Name: "oversold", Type: ParamFloat, Default: s.Oversold, Min: 1, Max: 50, Description: "RSI level to enter long"},
+		{Name: "overbought", Type: ParamFloat, Default: s.Overbought, Min: 50, Max: 99, Description: "RSI level to exit long"},
+	}
+}
+
+// SetParam sets the RSI period or a threshold.
+func (s *RSIMeanReversion) SetParam(name string, value float64) error {
+	switch name {
+	case "period":
+		s.Period = int(value)
+	case "oversold":
+		s.Oversold = value
+	case "overbought":
+		s.Overbought = value
+	default:
+		return errUnknownParam(s, name)
+	}
+	return nil
+}
+
+// WarmupBars returns the bars needed before the RSI is valid.
+func (s *RSIMeanReversion) WarmupBars() int { return s.Period + 2 }
+
 func (s *RSIMeanReversion) OnBar(ctx *StrategyContext, bar models.OHLCV) {
-	if ctx.CurrentBar < s.Period+2 {
+	if ctx.CurrentBar < s.WarmupBars() {
 		return
 	}
 
@@ -146,10 +209,37 @@ func NewSuperTrendStrategy(period int, mult float64) *SuperTrendStrategy {
 }
 
 func (s *SuperTrendStrategy) Name() string { return "SuperTrend" }
+func (s *SuperTrendStrategy) Description() string {
+	return "Goes long when SuperTrend flips up, exits when it flips down"
+}
 func (s *SuperTrendStrategy) Init(_ *StrategyContext) {}
 
+// Params returns the ATR period and multiplier.
+func (s *SuperTrendStrategy) Params() []StrategyParam {
+	return []StrategyParam{
+		{Name: "period", Type: ParamInt, Default: float64(s.Period), Min: 2, Max: 100, Description: "ATR period"},
+		{Name: "multiplier", Type: ParamFloat, Default: s.Multiplier, Min: 0.5, Max: 10, Description: "ATR band multiplier"},
+	}
+}
+
+// SetParam sets the ATR period or multiplier.
+func (s *SuperTrendStrategy) SetParam(name string, value float64) error {
+	switch name {
+	case "period":
+		s.Period = int(value)
+	case "multiplier":
+		s.Multiplier = value
+	default:
+		return errUnknownParam(s, name)
+	}
+	return nil
+}
+
+// WarmupBars returns the bars needed before the ATR is valid.
+func (s *SuperTrendStrategy) WarmupBars() int { return s.Period + 1 }
+
 func (s *SuperTrendStrategy) OnBar(ctx *StrategyContext, bar models.OHLCV) {
-	if ctx.CurrentBar < s.Period+1 {
+	if ctx.CurrentBar < s.WarmupBars() {
 		return
 	}
 
@@ -198,10 +288,32 @@ func NewVWAPBreakout(smaPeriod int) *VWAPBreakout {
 }
 
 func (s *VWAPBreakout) Name() string { return "VWAP Breakout" }
+func (s *VWAPBreakout) Description() string {
+	return "Buys a close above VWAP in an SMA-confirmed uptrend, exits on a close back below VWAP"
+}
 func (s *VWAPBreakout) Init(_ *StrategyContext) {}
 
+// Params returns the trend-confirmation SMA period.
+func (s *VWAPBreakout) Params() []StrategyParam {
+	return []StrategyParam{
+		{Name: "sma_period", Type: ParamInt, Default: float64(s.SMAPeriod), Min: 2, Max: 200, Description: "SMA period for trend confirmation"},
+	}
+}
+
+// SetParam sets the trend-confirmation SMA period.
+func (s *VWAPBreakout) SetParam(name string, value float64) error {
+	if name != "sma_period" {
+		return errUnknownParam(s, name)
+	}
+	s.SMAPeriod = int(value)
+	return nil
+}
+
+// WarmupBars returns the bars needed before the trend SMA is valid.
+func (s *VWAPBreakout) WarmupBars() int { return s.SMAPeriod + 1 }
+
 func (s *VWAPBreakout) OnBar(ctx *StrategyContext, bar models.OHLCV) {
-	if ctx.CurrentBar < s.SMAPeriod+1 {
+	if ctx.CurrentBar < s.WarmupBars() {
 		return
 	}
 
@@ -257,10 +369,45 @@ func NewMACDCrossover(fast, slow, signal int) *MACDCrossover {
 }
 
 func (s *MACDCrossover) Name() string { return "MACD Crossover" }
+func (s *MACDCrossover) Description() string {
+	return "Buys when the MACD line crosses above its signal line, sells when it crosses below"
+}
 func (s *MACDCrossover) Init(_ *StrategyContext) {}
 
+// Params returns the MACD fast, slow, and signal periods.
+func (s *MACDCrossover) Params() []StrategyParam {
+	return []StrategyParam{
+		{Name: "fast", Type: ParamInt, Default: float64(s.FastPeriod), Min: 2, Max: 100, Description: "fast EMA period"},
+		{Name: "slow", Type: ParamInt, Default: float64(s.SlowPeriod), Min: 3, Max: 200, Description: "slow EMA period"},
+		{Name: "signal", Type: ParamInt, Default: float64(s.SignalPeriod), Min: 2, Max: 50, Description: "signal line EMA period"},
+	}
+}
+
+// SetParam sets a MACD period.
+func (s *MACDCrossover) SetParam(name string, value float64) error {
+	switch name {
+	case "fast":
+		s.FastPeriod = int(value)
+	case "slow":
+		s.SlowPeriod = int(value)
+	case "signal":
+		s.SignalPeriod = int(value)
+	default:
+		return errUnknownParam(s, name)
+	}
+	return nil
+}
+
+// ValidateParams requires the fast EMA period to be below the slow one.
+func (s *MACDCrossover) ValidateParams(values map[string]float64) error {
+	return validateFastSlow(values)
+}
+
+// WarmupBars returns the bars needed before the MACD signal line is valid.
+func (s *MACDCrossover) WarmupBars() int { return s.SlowPeriod + s.SignalPeriod + 1 }
+
 func (s *MACDCrossover) OnBar(ctx *StrategyContext, bar models.OHLCV) {
-	if ctx.CurrentBar < s.SlowPeriod+s.SignalPeriod+1 {
+	if ctx.CurrentBar < s.WarmupBars() {
 		return
 	}
 
@@ -294,6 +441,72 @@ func (s *MACDCrossover) OnBar(ctx *StrategyContext, bar models.OHLCV) {
 	}
 }
 
+// ────────────────────────────────────────────────────────────────────
+// 6. Parabolic SAR Strategy
+// ────────────────────────────────────────────────────────────────────
+
+// ParabolicSARStrategy is a stop-and-reverse system that is always in the
+// market on the side of the Parabolic SAR, flipping from long to short in
+// one order when the SAR reverses. Under CNC, where shorts are not allowed,
+// a flip down just exits the long.
+type ParabolicSARStrategy struct {
+	Step    float64
+	MaxStep float64
+}
+
+// NewParabolicSARStrategy creates a new Parabolic SAR strategy.
+func NewParabolicSARStrategy(step, maxStep float64) *ParabolicSARStrategy {
+	return &ParabolicSARStrategy{Step: step, MaxStep: maxStep}
+}
+
+func (s *ParabolicSARStrategy) Name() string { return "Parabolic SAR" }
+func (s *ParabolicSARStrategy) Description() string {
+	return "Stays on the side of the Parabolic SAR, reversing long to short in one order when it flips"
+}
+func (s *ParabolicSARStrategy) Init(_ *StrategyContext) {}
+
+// Params returns the acceleration step and its cap.
+func (s *ParabolicSARStrategy) Params() []StrategyParam {
+	return []StrategyParam{
+		{Name: "step", Type: ParamFloat, Default: s.Step, Min: 0.001, Max: 0.2, Description: "acceleration factor step"},
+		{Name: "max_step", Type: ParamFloat, Default: s.MaxStep, Min: 0.01, Max: 1, Description: "maximum acceleration factor"},
+	}
+}
+
+// SetParam sets the acceleration step or its cap.
+func (s *ParabolicSARStrategy) SetParam(name string, value float64) error {
+	switch name {
+	case "step":
+		s.Step = value
+	case "max_step":
+		s.MaxStep = value
+	default:
+		return errUnknownParam(s, name)
+	}
+	return nil
+}
+
+// WarmupBars returns the bars needed to seed the SAR's first trend.
+func (s *ParabolicSARStrategy) WarmupBars() int { return 2 }
+
+func (s *ParabolicSARStrategy) OnBar(ctx *StrategyContext, bar models.OHLCV) {
+	sar := technical.ParabolicSAR(ctx.HistoricalBars(), s.Step, s.MaxStep)
+	if len(sar) < ctx.CurrentBar+1 {
+		return
+	}
+
+	switch sar[ctx.CurrentBar].Trend {
+	case "UP":
+		if ctx.Position <= 0 {
+			ctx.Reverse(models.Buy, 0, "Parabolic SAR flipped up")
+		}
+	case "DOWN":
+		if ctx.Position >= 0 {
+			ctx.Reverse(models.Sell, 0, "Parabolic SAR flipped down")
+		}
+	}
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Helpers
 // ════════════════════════════════════════════════════════════════════
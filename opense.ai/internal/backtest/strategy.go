@@ -15,6 +15,13 @@ type Strategy interface {
 	// Name returns the human-readable strategy name.
 	Name() string
 
+	// Params describes the strategy's tunable parameters with their current values.
+	Params() []StrategyParam
+
+	// WarmupBars returns how many leading bars the strategy's indicators need
+	// before its signals are meaningful. The engine does not call OnBar for them.
+	WarmupBars() int
+
 	// Init is called once before the first bar. Use it to set up state.
 	Init(ctx *StrategyContext)
 
@@ -23,6 +30,12 @@ type Strategy interface {
 	OnBar(ctx *StrategyContext, bar models.OHLCV)
 }
 
+// Describer is implemented by strategies that can explain their rules in
+// one line, for listings such as the strategies API.
+type Describer interface {
+	Description() string
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Strategy Context — The strategy's view of the world
 // ════════════════════════════════════════════════════════════════════
@@ -127,6 +140,22 @@ func (ctx *StrategyContext) ClosePosition(reason string) {
 	}
 }
 
+// Reverse places a stop-and-reverse market order. When it fills, any
+// position on the other side is closed and a position on side is opened at
+// the same price, as one operation. The new position is sized by the
+// engine's SizingMode like any other; under SizeStrategy a qty of zero or
+// less sizes it to the cash available after the close. It does nothing if
+// the position is already on side.
+func (ctx *StrategyContext) Reverse(side models.OrderSide, qty int, reason string) {
+	ctx.orders = append(ctx.orders, pendingOrder{
+		Side:      side,
+		OrderType: models.Market,
+		Quantity:  qty,
+		Reason:    reason,
+		Reverse:   true,
+	})
+}
+
 // CancelPending removes all pending (unfilled) orders.
 func (ctx *StrategyContext) CancelPending() {
 	ctx.orders = ctx.orders[:0]
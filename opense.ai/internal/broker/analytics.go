@@ -0,0 +1,426 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// ════════════════════════════════════════════════════════════════════
+// Portfolio Analytics
+// ════════════════════════════════════════════════════════════════════
+
+// CashFlow is an external deposit (positive) or withdrawal (negative)
+// recorded against the paper account.
+type CashFlow struct {
+	Amount      float64   `json:"amount"`
+	Date        time.Time `json:"date"`
+	ValueBefore float64   `json:"value_before"` // account value just before the flow
+}
+
+// PortfolioAnalytics summarises paper account performance in the presence
+// of deposits and withdrawals. All returns are percentages.
+type PortfolioAnalytics struct {
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	StartValue     float64   `json:"start_value"`
+	EndValue       float64   `json:"end_value"`
+	NetCashFlow    float64   `json:"net_cash_flow"`
+	NaiveReturnPct float64   `json:"naive_return_pct"` // P&L over total capital contributed
+	TWRPct         float64   `json:"twr_pct"`          // time-weighted, for the whole period
+	MWRPct         float64   `json:"mwr_pct"`          // money-weighted (IRR), annualised
+}
+
+// ErrIRRNotFound is returned when the cash-flow series has no IRR.
+var ErrIRRNotFound = errors.New("no IRR solution for cash flows")
+
+// AddCashFlow records a deposit (amount > 0) or withdrawal (amount < 0)
+// made at when. Flows must be added in chronological order and cannot
+// precede the account start date.
+func (pb *PaperBroker) AddCashFlow(amount float64, when time.Time) error {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	if amount == 0 {
+		return fmt.Errorf("cash flow amount must be non-zero")
+	}
+	last := pb.startedAt
+	if n := len(pb.cashFlows); n > 0 {
+		last = pb.cashFlows[n-1].Date
+	}
+	if when.Before(last) {
+		return fmt.Errorf("cash flow dated %s precedes %s", when.Format("2006-01-02"), last.Format("2006-01-02"))
+	}
+	if amount < 0 && -amount > pb.cash-pb.usedMargin {
+		return fmt.Errorf("%w: withdrawal of ₹%.2f exceeds available ₹%.2f",
+			ErrInsufficientMargin, -amount, pb.cash-pb.usedMargin)
+	}
+
+	pb.cashFlows = append(pb.cashFlows, CashFlow{
+		Amount:      amount,
+		Date:        when,
+		ValueBefore: pb.accountValue(),
+	})
+	pb.cash += amount
+	return nil
+}
+
+// CashFlows returns the recorded deposits and withdrawals.
+func (pb *PaperBroker) CashFlows() []CashFlow {
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+
+	out := make([]CashFlow, len(pb.cashFlows))
+	copy(out, pb.cashFlows)
+	return out
+}
+
+// Analytics computes naive, time-weighted, and money-weighted returns for
+// the account from its start date to asOf, valuing open holdings and
+// positions at their last set price.
+//
+// TWR chains the sub-period returns between cash flows, so it measures
+// trading performance independent of when money was added. MWR is the
+// annualised internal rate of return of the initial capital, the recorded
+// cash flows, and the ending value.
+func (pb *PaperBroker) Analytics(asOf time.Time) (*PortfolioAnalytics, error) {
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+
+	end := pb.accountValue()
+	a := &PortfolioAnalytics{
+		StartDate:  pb.startedAt,
+		EndDate:    asOf,
+		StartValue: pb.initialCapital,
+		EndValue:   end,
+	}
+
+	growth := 1.0
+	base := pb.initialCapital
+	dates := []time.Time{pb.startedAt}
+	amounts := []float64{-pb.initialCapital}
+	for _, cf := range pb.cashFlows {
+		if base > 0 {
+			growth *= cf.ValueBefore / base
+		}
+		base = cf.ValueBefore + cf.Amount
+		a.NetCashFlow += cf.Amount
+		dates = append(dates, cf.Date)
+		amounts = append(amounts, -cf.Amount)
+	}
+	if base > 0 {
+		growth *= end / base
+	}
+	a.TWRPct = (growth - 1) * 100
+
+	if invested := pb.initialCapital + a.NetCashFlow; invested > 0 {
+		a.NaiveReturnPct = (end - invested) / invested * 100
+	}
+
+	if asOf.After(pb.startedAt) {
+		dates = append(dates, asOf)
+		amounts = append(amounts, end)
+		irr, err := xirr(amounts, dates)
+		if err != nil {
+			return nil, err
+		}
+		a.MWRPct = irr * 100
+	}
+	return a, nil
+}
+
+// accountValue returns cash plus locked margin, unrealised position P&L,
+// and the market value of holdings. Caller must hold pb.mu.
+func (pb *PaperBroker) accountValue() float64 {
+	value := pb.cash + pb.usedMargin
+	for _, p := range pb.positions {
+		value += p.PnL
+	}
+	for _, h := range pb.holdings {
+		value += h.CurrentValue
+	}
+	return value
+}
+
+// xnpv discounts dated cash flows to the first date at annual rate.
+func xnpv(rate float64, amounts []float64, dates []time.Time) float64 {
+	var npv float64
+	for i, amt := range amounts {
+		years := dates[i].Sub(dates[0]).Hours() / 24 / 365
+		npv += amt / math.Pow(1+rate, years)
+	}
+	return npv
+}
+
+// xirr finds the annual rate at which the dated cash flows have zero net
+// present value, by bisection.
+func xirr(amounts []float64, dates []time.Time) (float64, error) {
+	lo, hi := -0.9999, 1.0
+	fLo := xnpv(lo, amounts, dates)
+	fHi := xnpv(hi, amounts, dates)
+	for fLo*fHi > 0 {
+		if hi > 1e6 {
+			return 0, ErrIRRNotFound
+		}
+		hi *= 10
+		fHi = xnpv(hi, amounts, dates)
+	}
+
+	for i := 0; i < 200; i++ {
+		mid := (lo + hi) / 2
+		fMid := xnpv(mid, amounts, dates)
+		if math.Abs(fMid) < 1e-7 || hi-lo < 1e-12 {
+			return mid, nil
+		}
+		if fLo*fMid < 0 {
+			hi = mid
+		} else {
+			lo, fLo = mid, fMid
+		}
+	}
+	return (lo + hi) / 2, nil
+}
+
+// ════════════════════════════════════════════════════════════════════
+// P&L Attribution
+// ════════════════════════════════════════════════════════════════════
+
+// TickerAttribution is one ticker's contribution to paper account P&L.
+// P&L is before charges; a trade is a fill that closes all or part of a
+// holding or position, and it wins when it realises a profit.
+type TickerAttribution struct {
+	Ticker        string  `json:"ticker"`
+	RealizedPnL   float64 `json:"realized_pnl"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	TotalPnL      float64 `json:"total_pnl"`
+	Trades        int     `json:"trades"`
+	Wins          int     `json:"wins"`
+	WinRatePct    float64 `json:"win_rate_pct"`
+}
+
+// closedTrades accumulates the realised P&L of closing fills for a ticker.
+type closedTrades struct {
+	pnl    float64
+	trades int
+	wins   int
+}
+
+// recordClose records a closing fill of ticker that realised pnl. Caller
+// must hold pb.mu.
+func (pb *PaperBroker) recordClose(ticker string, pnl float64) {
+	c, ok := pb.closed[ticker]
+	if !ok {
+		c = &closedTrades{}
+		pb.closed[ticker] = c
+	}
+	c.pnl += pnl
+	c.trades++
+	if pnl > 0 {
+		c.wins++
+	}
+}
+
+// Attribution summarises realised and unrealised P&L, trade count, and win
+// rate per ticker, sorted by total P&L with the largest contributor first.
+// Open holdings and positions are valued at their last set price.
+func (pb *PaperBroker) Attribution() []TickerAttribution {
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+
+	byTicker := make(map[string]*TickerAttribution)
+	get := func(ticker string) *TickerAttribution {
+		a, ok := byTicker[ticker]
+		if !ok {
+			a = &TickerAttribution{Ticker: ticker}
+			byTicker[ticker] = a
+		}
+		return a
+	}
+
+	for ticker, c := range pb.closed {
+		a := get(ticker)
+		a.RealizedPnL = c.pnl
+		a.Trades = c.trades
+		a.Wins = c.wins
+	}
+	for _, p := range pb.positions {
+		get(p.Ticker).UnrealizedPnL += (p.LTP - p.AvgPrice) * float64(p.Quantity)
+	}
+	for _, h := range pb.holdings {
+		get(h.Ticker).UnrealizedPnL += (h.LTP - h.AvgPrice) * float64(h.Quantity)
+	}
+
+	return sortedAttribution(byTicker)
+}
+
+// JournalAttribution rebuilds per-ticker P&L attribution from the fills in
+// a trade journal, so it covers trades made by earlier sessions. Fills
+// are replayed in order into one book per ticker and product; an order
+// journaled by both the broker and the risk manager is counted once.
+// Quantity still open is valued at its ticker's last fill price.
+func JournalAttribution(logs []models.TradeLog) []TickerAttribution {
+	byTicker := make(map[string]*TickerAttribution)
+	get := func(ticker string) *TickerAttribution {
+		a, ok := byTicker[ticker]
+		if !ok {
+			a = &TickerAttribution{Ticker: ticker}
+			byTicker[ticker] = a
+		}
+		return a
+	}
+
+	r := replayJournal(logs, func(ticker string, pnl float64) {
+		a := get(ticker)
+		a.RealizedPnL += pnl
+		a.Trades++
+		if pnl > 0 {
+			a.Wins++
+		}
+	})
+	for key, b := range r.books {
+		ticker := key[:strings.LastIndex(key, "|")]
+		get(ticker).UnrealizedPnL += (r.last[ticker] - b.avg) * float64(b.qty)
+	}
+	return sortedAttribution(byTicker)
+}
+
+// JournalHoldings rebuilds the delivery (CNC) holdings left open by the
+// fills in a trade journal, replayed the same way as JournalAttribution.
+// Each holding's LTP is its ticker's last fill price; callers with live
+// quotes should refresh it.
+func JournalHoldings(logs []models.TradeLog) []models.Holding {
+	r := replayJournal(logs, nil)
+	holdings := make([]models.Holding, 0, len(r.books))
+	for key, b := range r.books {
+		i := strings.LastIndex(key, "|")
+		if models.OrderProduct(key[i+1:]) != models.CNC || b.qty <= 0 {
+			continue
+		}
+		ticker := key[:i]
+		h := models.Holding{
+			Ticker:        ticker,
+			Exchange:      b.exchange,
+			Quantity:      b.qty,
+			AvgPrice:      b.avg,
+			LTP:           r.last[ticker],
+			InvestedValue: b.avg * float64(b.qty),
+		}
+		h.CurrentValue = h.LTP * float64(h.Quantity)
+		h.PnL = h.CurrentValue - h.InvestedValue
+		if h.InvestedValue > 0 {
+			h.PnLPct = h.PnL / h.InvestedValue * 100
+		}
+		holdings = append(holdings, h)
+	}
+	sort.Slice(holdings, func(i, j int) bool { return holdings[i].Ticker < holdings[j].Ticker })
+	return holdings
+}
+
+// journalBook is the open quantity of one ticker and product while a
+// trade journal is replayed.
+type journalBook struct {
+	qty      int // signed: negative for a short
+	avg      float64
+	exchange string
+}
+
+// journalReplay is the state left after replaying a trade journal: the
+// books still open, keyed by "ticker|product", and each ticker's last
+// fill price.
+type journalReplay struct {
+	books map[string]*journalBook
+	last  map[string]float64
+}
+
+// replayJournal replays the fills in logs in order, skipping an order
+// seen twice, and calls onClose (if non-nil) with the realized P&L of
+// every fill that reduces an open book. Flat books are dropped.
+func replayJournal(logs []models.TradeLog, onClose func(ticker string, pnl float64)) journalReplay {
+	r := journalReplay{
+		books: make(map[string]*journalBook),
+		last:  make(map[string]float64),
+	}
+	seen := make(map[string]bool)
+
+	for _, l := range logs {
+		if l.FilledQty <= 0 || l.FillPrice <= 0 {
+			continue
+		}
+		if resp := l.OrderResponse; resp != nil && resp.OrderID != "" {
+			if seen[resp.OrderID] {
+				continue
+			}
+			seen[resp.OrderID] = true
+		}
+		req := l.OrderRequest
+		product := req.Product
+		if product == "" {
+			product = models.CNC
+		}
+		key := req.Ticker + "|" + string(product)
+		b, ok := r.books[key]
+		if !ok {
+			b = &journalBook{}
+			r.books[key] = b
+		}
+		if req.Exchange != "" {
+			b.exchange = req.Exchange
+		}
+		r.last[req.Ticker] = l.FillPrice
+
+		qty := l.FilledQty
+		sign := 1
+		if req.Side == models.Sell {
+			sign = -1
+		}
+		// A fill against the book's direction closes first.
+		if b.qty*sign < 0 {
+			closed := absInt(b.qty)
+			if qty < closed {
+				closed = qty
+			}
+			if onClose != nil {
+				onClose(req.Ticker, (l.FillPrice-b.avg)*float64(closed)*float64(-sign))
+			}
+			b.qty += sign * closed
+			qty -= closed
+		}
+		if qty > 0 {
+			open := absInt(b.qty)
+			b.avg = (b.avg*float64(open) + l.FillPrice*float64(qty)) / float64(open+qty)
+			b.qty += sign * qty
+		}
+	}
+
+	for key, b := range r.books {
+		if b.qty == 0 {
+			delete(r.books, key)
+		}
+	}
+	return r
+}
+
+// sortedAttribution totals each ticker's P&L and win rate and sorts them
+// with the largest contributor first.
+func sortedAttribution(byTicker map[string]*TickerAttribution) []TickerAttribution {
+	out := make([]TickerAttribution, 0, len(byTicker))
+	for _, a := range byTicker {
+		a.TotalPnL = a.RealizedPnL + a.UnrealizedPnL
+		if a.Trades > 0 {
+			a.WinRatePct = float64(a.Wins) / float64(a.Trades) * 100
+		}
+		out = append(out, *a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TotalPnL != out[j].TotalPnL {
+			return out[i].TotalPnL > out[j].TotalPnL
+		}
+		return out[i].Ticker < out[j].Ticker
+	})
+	return out
+}
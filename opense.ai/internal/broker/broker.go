@@ -4,8 +4,17 @@
 package broker
 
 import (
+	"bufio"
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
 	"sync"
 	"time"
 
@@ -67,6 +76,7 @@ type Broker interface {
 type TradeLogger struct {
 	mu   sync.Mutex
 	logs []models.TradeLog
+	path string // journal file appended to by Log; empty keeps logs in memory
 }
 
 // NewTradeLogger creates a new trade logger.
@@ -76,17 +86,79 @@ func NewTradeLogger() *TradeLogger {
 	}
 }
 
-// Log records a trade event.
-func (tl *TradeLogger) Log(log models.TradeLog) {
+// OpenTradeLogger returns a logger backed by a journal file with one JSON
+// trade event per line. Events already in the file are loaded, and every
+// event logged afterwards is appended to it, so the log outlives the
+// process. A missing file is created on open.
+func OpenTradeLogger(path string) (*TradeLogger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create trade journal directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open trade journal: %w", err)
+	}
+	defer f.Close()
+
+	tl := NewTradeLogger()
+	tl.path = path
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var log models.TradeLog
+		if err := json.Unmarshal(sc.Bytes(), &log); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		tl.logs = append(tl.logs, log)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read trade journal: %w", err)
+	}
+	return tl, nil
+}
+
+// Log records a trade event. For a journal-backed logger the event is also
+// appended to the journal; a failed write is reported to the standard
+// logger and leaves the event in memory only.
+func (tl *TradeLogger) Log(entry models.TradeLog) {
 	tl.mu.Lock()
 	defer tl.mu.Unlock()
-	if log.Timestamp.IsZero() {
-		log.Timestamp = time.Now()
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("TL-%d", len(tl.logs)+1)
+	}
+	tl.logs = append(tl.logs, entry)
+	if tl.path != "" {
+		if err := appendJournal(tl.path, entry); err != nil {
+			log.Printf("trade journal %s: cannot record %s: %v", tl.path, entry.ID, err)
+		}
+	}
+}
+
+// fillLogger is implemented by brokers that log each fill as it happens,
+// such as PaperBroker, so that fills made after placement are journaled.
+type fillLogger interface {
+	Logger() *TradeLogger
+	SetLogger(tl *TradeLogger)
+}
+
+// appendJournal appends one event to a trade journal file.
+func appendJournal(path string, log models.TradeLog) error {
+	line, err := json.Marshal(log)
+	if err != nil {
+		return err
 	}
-	if log.ID == "" {
-		log.ID = fmt.Sprintf("TL-%d", len(tl.logs)+1)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
 	}
-	tl.logs = append(tl.logs, log)
+	_, err = f.Write(append(line, '\n'))
+	return errors.Join(err, f.Close())
 }
 
 // Logs returns all logged trade events.
@@ -134,6 +206,60 @@ func (tl *TradeLogger) DayLogs(date time.Time) []models.TradeLog {
 	return out
 }
 
+// tradeLogCSVHeader is the header row written by ExportCSV.
+var tradeLogCSVHeader = []string{
+	"id", "timestamp", "agent", "ticker", "exchange", "side", "order_type", "product",
+	"quantity", "price", "trigger_price", "tag", "order_id", "status",
+	"filled_qty", "fill_price", "charges", "approved", "reason",
+}
+
+// ExportCSV writes every logged event to w as CSV, one row per event,
+// under tradeLogCSVHeader. Timestamps are RFC 3339 and prices are left
+// unrounded for reconciliation.
+func (tl *TradeLogger) ExportCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(tradeLogCSVHeader); err != nil {
+		return err
+	}
+	num := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	for _, log := range tl.Logs() {
+		req := log.OrderRequest
+		var orderID, status string
+		if resp := log.OrderResponse; resp != nil {
+			orderID, status = resp.OrderID, resp.Status
+		}
+		row := []string{
+			log.ID, log.Timestamp.Format(time.RFC3339), log.AgentName,
+			req.Ticker, req.Exchange, string(req.Side), string(req.OrderType), string(req.Product),
+			strconv.Itoa(req.Quantity), num(req.Price), num(req.TriggerPrice), req.Tag,
+			orderID, status,
+			strconv.Itoa(log.FilledQty), num(log.FillPrice), num(log.Charges),
+			strconv.FormatBool(log.Approved), log.Reason,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportJSON writes every logged event to w as an indented JSON array.
+func (tl *TradeLogger) ExportJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(tl.Logs())
+}
+
+// legCharges returns the charges on one side of a trade: the buy leg of a
+// BUY fill or the sell leg of a SELL fill.
+func legCharges(side models.OrderSide, price float64, qty int, product models.OrderProduct) float64 {
+	if side == models.Sell {
+		return CalculateBrokerage(0, price, qty, product).Total
+	}
+	return CalculateBrokerage(price, 0, qty, product).Total
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Common Errors
 // ════════════════════════════════════════════════════════════════════
@@ -163,6 +289,9 @@ var (
 	// ErrApprovalTimeout is returned when human approval times out.
 	ErrApprovalTimeout = fmt.Errorf("trade approval timed out")
 
+	// ErrApprovalNotFound is returned when a pending approval ID doesn't exist.
+	ErrApprovalNotFound = fmt.Errorf("pending approval not found")
+
 	// ErrNotSupported is returned for unimplemented broker features.
 	ErrNotSupported = fmt.Errorf("operation not supported by this broker")
 )
@@ -1,10 +1,15 @@
 package broker
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -51,6 +56,156 @@ func TestTradeLogger_Log(t *testing.T) {
 	}
 }
 
+func TestTradeLogger_Export(t *testing.T) {
+	logger := NewTradeLogger()
+	ts := time.Date(2026, 3, 2, 10, 15, 0, 0, time.UTC)
+	logger.Log(models.TradeLog{
+		Timestamp: ts,
+		OrderRequest: models.OrderRequest{
+			Ticker: "RELIANCE", Exchange: "NSE", Side: models.Buy, OrderType: models.Limit,
+			Product: models.CNC, Quantity: 10, Price: 2500, Tag: "executor",
+		},
+		OrderResponse: &models.OrderResponse{OrderID: "PAPER-1", Status: "COMPLETE"},
+		Approved:      true,
+		AgentName:     "risk-paper",
+		FilledQty:     10,
+		FillPrice:     2502.5,
+		Charges:       28.47,
+	})
+	logger.Log(models.TradeLog{
+		Timestamp:    ts.Add(time.Minute),
+		OrderRequest: models.OrderRequest{Ticker: "TCS", Side: models.Sell, Quantity: 5},
+		AgentName:    "risk-paper",
+		Reason:       "approval denied: too large",
+	})
+
+	var buf bytes.Buffer
+	if err := logger.ExportCSV(&buf); err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected header + 2 rows, got %d", len(rows))
+	}
+	wantHeader := "id,timestamp,agent,ticker,exchange,side,order_type,product,quantity,price,trigger_price,tag,order_id,status,filled_qty,fill_price,charges,approved,reason"
+	if got := strings.Join(rows[0], ","); got != wantHeader {
+		t.Errorf("header = %s", got)
+	}
+	wantRow := "TL-1,2026-03-02T10:15:00Z,risk-paper,RELIANCE,NSE,BUY,LIMIT,CNC,10,2500,0,executor,PAPER-1,COMPLETE,10,2502.5,28.47,true,"
+	if got := strings.Join(rows[1], ","); got != wantRow {
+		t.Errorf("row 1 = %s\nwant    %s", got, wantRow)
+	}
+	if rows[2][3] != "TCS" || rows[2][13] != "" || rows[2][17] != "false" || rows[2][18] != "approval denied: too large" {
+		t.Errorf("row 2 = %v", rows[2])
+	}
+
+	buf.Reset()
+	if err := logger.ExportJSON(&buf); err != nil {
+		t.Fatalf("ExportJSON: %v", err)
+	}
+	var logs []models.TradeLog
+	if err := json.Unmarshal(buf.Bytes(), &logs); err != nil {
+		t.Fatalf("parse JSON: %v", err)
+	}
+	if len(logs) != 2 || logs[0].FillPrice != 2502.5 || logs[0].Charges != 28.47 {
+		t.Errorf("JSON export = %+v", logs)
+	}
+}
+
+func TestOpenTradeLogger_Journal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal", "trades.jsonl")
+	logger, err := OpenTradeLogger(path)
+	if err != nil {
+		t.Fatalf("OpenTradeLogger: %v", err)
+	}
+	logger.Log(models.TradeLog{OrderRequest: models.OrderRequest{Ticker: "INFY"}, AgentName: "a"})
+	logger.Log(models.TradeLog{OrderRequest: models.OrderRequest{Ticker: "TCS"}, AgentName: "b"})
+
+	reopened, err := OpenTradeLogger(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	logs := reopened.Logs()
+	if len(logs) != 2 || logs[0].OrderRequest.Ticker != "INFY" || logs[1].AgentName != "b" {
+		t.Fatalf("reloaded logs = %+v", logs)
+	}
+	reopened.Log(models.TradeLog{OrderRequest: models.OrderRequest{Ticker: "ITC"}})
+	if id := reopened.Logs()[2].ID; id != "TL-3" {
+		t.Errorf("new event after reload got ID %s, want TL-3", id)
+	}
+}
+
+func TestRiskManager_LogsFill(t *testing.T) {
+	pb := NewPaperBroker(&PaperBrokerConfig{InitialCapital: 1_000_000})
+	rm := NewRiskManager(pb, RiskConfig{MaxPositionPct: 10, MaxOrderValuePct: 20, InitialCapital: 1_000_000})
+	ctx := context.Background()
+	resp, err := rm.PlaceOrder(ctx, models.OrderRequest{
+		Ticker: "RELIANCE", Exchange: "NSE", Side: models.Buy, OrderType: models.Limit,
+		Product: models.CNC, Quantity: 10, Price: 2500,
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	order, err := pb.GetOrderByID(ctx, resp.OrderID)
+	if err != nil {
+		t.Fatalf("GetOrderByID: %v", err)
+	}
+
+	logs := rm.Logger().Logs()
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 log, got %d", len(logs))
+	}
+	log := logs[0]
+	if log.FilledQty != 10 || log.FillPrice != order.AvgPrice {
+		t.Errorf("logged fill %d @ %.2f, want 10 @ %.2f", log.FilledQty, log.FillPrice, order.AvgPrice)
+	}
+	if want := CalculateBrokerage(order.AvgPrice, 0, 10, models.CNC).Total; math.Abs(log.Charges-want) > 1e-9 || want <= 0 {
+		t.Errorf("charges = %.4f, want %.4f", log.Charges, want)
+	}
+}
+
+func TestRiskManager_JournalsDelayedFill(t *testing.T) {
+	pb := NewPaperBroker(&PaperBrokerConfig{InitialCapital: 1_000_000, FillLatency: 20 * time.Millisecond})
+	rm := NewRiskManager(pb, RiskConfig{MaxPositionPct: 10, MaxOrderValuePct: 20, InitialCapital: 1_000_000})
+	path := filepath.Join(t.TempDir(), "trades.jsonl")
+	journal, err := OpenTradeLogger(path)
+	if err != nil {
+		t.Fatalf("OpenTradeLogger: %v", err)
+	}
+	rm.SetLogger(journal)
+
+	ctx := context.Background()
+	resp, err := rm.PlaceOrder(ctx, models.OrderRequest{
+		Ticker: "RELIANCE", Exchange: "NSE", Side: models.Buy, OrderType: models.Limit,
+		Product: models.CNC, Quantity: 10, Price: 2500,
+	})
+	if err != nil || resp.Status != string(models.OrderOpen) {
+		t.Fatalf("PlaceOrder: %+v, %v", resp, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	reopened, err := OpenTradeLogger(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	order, _ := pb.GetOrderByID(ctx, resp.OrderID)
+	var fills int
+	for _, log := range reopened.Logs() {
+		if log.FilledQty > 0 {
+			fills++
+			if log.FilledQty != 10 || log.FillPrice != order.AvgPrice || log.Charges <= 0 {
+				t.Errorf("journaled fill %d @ %.2f (charges %.2f), want 10 @ %.2f", log.FilledQty, log.FillPrice, log.Charges, order.AvgPrice)
+			}
+		}
+	}
+	if fills != 1 {
+		t.Errorf("expected the delayed fill journaled once, got %d fill events", fills)
+	}
+}
+
 func TestTradeLogger_RecentLogs(t *testing.T) {
 	logger := NewTradeLogger()
 
@@ -287,7 +442,7 @@ func TestValidateOrder_NRMLOnNFO(t *testing.T) {
 		Side:         models.Buy,
 		OrderType:    models.Market,
 		Product:      models.NRML,
-		Quantity:     50,
+		Quantity:     65,
 		TriggerPrice: 200,
 	}
 	result := ValidateOrder(req)
@@ -296,6 +451,75 @@ func TestValidateOrder_NRMLOnNFO(t *testing.T) {
 	}
 }
 
+func TestValidateOrder_NFOLotMultiple(t *testing.T) {
+	req := models.OrderRequest{
+		Ticker:    "BANKNIFTY24DEC52000CE",
+		Exchange:  "NFO",
+		Side:      models.Buy,
+		OrderType: models.Market,
+		Product:   models.NRML,
+		Quantity:  90,
+	}
+	if result := ValidateOrder(req); !result.IsValid() {
+		t.Errorf("expected 3 BANKNIFTY lots to be valid, got: %s", result.ErrorString())
+	}
+}
+
+func TestValidateOrder_NFONotLotMultiple(t *testing.T) {
+	req := models.OrderRequest{
+		Ticker:    "NIFTY24DEC24000CE",
+		Exchange:  "NFO",
+		Side:      models.Buy,
+		OrderType: models.Market,
+		Product:   models.NRML,
+		Quantity:  100,
+	}
+	result := ValidateOrder(req)
+	if result.IsValid() {
+		t.Fatal("expected invalid for 100 NIFTY (lot size 65)")
+	}
+	if msg := result.ErrorString(); !strings.Contains(msg, "lot size 65") || !strings.Contains(msg, "try 65 or 130") {
+		t.Errorf("unexpected message: %s", msg)
+	}
+}
+
+func TestLotSize(t *testing.T) {
+	if n, ok := LotSize("NIFTY24DECFUT"); !ok || n != 65 {
+		t.Errorf("NIFTY lot size = %d, %v", n, ok)
+	}
+	if _, ok := LotSize("UNKNOWNCO24DECFUT"); ok {
+		t.Error("expected no lot size for unknown underlying")
+	}
+	if got := Underlying("M&M24DEC3000CE"); got != "M&M" {
+		t.Errorf("Underlying = %q, want M&M", got)
+	}
+}
+
+func TestSetLotSizes(t *testing.T) {
+	t.Cleanup(func() {
+		lotSizesMu.Lock()
+		delete(lotSizes, "RELIANCE")
+		lotSizes["SENSEX"] = 20
+		lotSizesMu.Unlock()
+	})
+	// Config keys arrive lower-cased.
+	SetLotSizes(map[string]int{"reliance": 500, "sensex": 25, "infy": 0})
+	if n, ok := LotSize("RELIANCE24DECFUT"); !ok || n != 500 {
+		t.Errorf("RELIANCE lot size = %d, %v", n, ok)
+	}
+	if _, ok := LotSize("INFY24DECFUT"); ok {
+		t.Error("expected a zero size to be ignored")
+	}
+
+	req := models.OrderRequest{
+		Ticker: "SENSEX24DEC80000CE", Exchange: "BFO", Side: models.Buy,
+		OrderType: models.Market, Product: models.NRML, Quantity: 20,
+	}
+	if msg := ValidateOrder(req).ErrorString(); msg != "quantity: quantity 20 is not a multiple of the SENSEX lot size 25 (try 25)" {
+		t.Errorf("expected only a BFO lot size error, got %q", msg)
+	}
+}
+
 func TestValidateStopLoss_Buy(t *testing.T) {
 	// Buy: stop loss must be below entry
 	if err := ValidateStopLoss(models.Buy, 100, 95); err != nil {
@@ -674,6 +898,53 @@ func TestPaperBroker_SellHolding(t *testing.T) {
 	}
 }
 
+func TestPaperBroker_AddToHoldingAveragesPrice(t *testing.T) {
+	pb := NewPaperBroker(&PaperBrokerConfig{
+		InitialCapital: 1_000_000,
+		SlippagePct:    0.001,
+	})
+	ctx := context.Background()
+
+	var fills []float64
+	for _, price := range []float64{1000, 1200} {
+		resp, err := pb.PlaceOrder(ctx, models.OrderRequest{
+			Ticker:    "TCS",
+			Exchange:  "NSE",
+			Side:      models.Buy,
+			OrderType: models.Limit,
+			Product:   models.CNC,
+			Quantity:  100,
+			Price:     price,
+		})
+		if err != nil {
+			t.Fatalf("buy @%.0f: %v", price, err)
+		}
+		order, _ := pb.GetOrderByID(ctx, resp.OrderID)
+		fills = append(fills, order.AvgPrice)
+	}
+
+	holdings, _ := pb.GetHoldings(ctx)
+	if len(holdings) != 1 {
+		t.Fatalf("expected 1 holding after adding to it, got %d", len(holdings))
+	}
+	h := holdings[0]
+	if h.Quantity != 200 {
+		t.Errorf("expected quantity 200, got %d", h.Quantity)
+	}
+	// Fills carry a little random slippage, so compare against the exact
+	// volume-weighted fill price and loosely against 1100.
+	vwap := (fills[0] + fills[1]) / 2
+	if math.Abs(h.AvgPrice-vwap) > 1e-9 || math.Abs(h.AvgPrice-1100) > 0.1 {
+		t.Errorf("expected avg price %.4f (~1100), got %.4f", vwap, h.AvgPrice)
+	}
+	if math.Abs(h.InvestedValue-vwap*200) > 1e-6 {
+		t.Errorf("expected invested value %.2f, got %.2f", vwap*200, h.InvestedValue)
+	}
+	if h.LTP != fills[1] || math.Abs(h.PnL-(fills[1]-vwap)*200) > 1e-6 {
+		t.Errorf("expected holding marked to the last fill, got LTP %.2f PnL %.2f", h.LTP, h.PnL)
+	}
+}
+
 func TestPaperBroker_ClosePosition_MIS(t *testing.T) {
 	pb := NewPaperBroker(&PaperBrokerConfig{
 		InitialCapital: 1_000_000,
@@ -740,6 +1011,161 @@ func TestPaperBroker_SetPrice(t *testing.T) {
 	}
 }
 
+func TestPaperBroker_FillModelMidpoint(t *testing.T) {
+	pb := NewPaperBroker(&PaperBrokerConfig{
+		InitialCapital: 1_000_000,
+		SlippagePct:    0.001,
+		FillModel:      FillMidpoint,
+	})
+	ctx := context.Background()
+
+	// A buy limit above the market is marketable and should share the
+	// ₹20 improvement rather than filling at the limit.
+	pb.SetPrice("INFY", 1480)
+	resp, err := pb.PlaceOrder(ctx, models.OrderRequest{
+		Ticker:    "INFY",
+		Exchange:  "NSE",
+		Side:      models.Buy,
+		OrderType: models.Limit,
+		Product:   models.CNC,
+		Quantity:  10,
+		Price:     1500,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	order, _ := pb.GetOrderByID(ctx, resp.OrderID)
+	if order.AvgPrice >= 1500 {
+		t.Errorf("expected fill below the ₹1500 limit, got %.2f", order.AvgPrice)
+	}
+	if math.Abs(order.AvgPrice-1490) > 0.1 {
+		t.Errorf("expected fill near the ₹1490 midpoint, got %.2f", order.AvgPrice)
+	}
+}
+
+func TestPaperBroker_FillModelWorstRespectsLimit(t *testing.T) {
+	pb := NewPaperBroker(&PaperBrokerConfig{
+		InitialCapital: 1_000_000,
+		SlippagePct:    0.5,
+		FillModel:      FillWorst,
+	})
+	ctx := context.Background()
+	pb.SetPrice("INFY", 1500)
+
+	for _, tc := range []struct {
+		side  models.OrderSide
+		limit float64
+	}{
+		{models.Buy, 1520},
+		{models.Sell, 1480},
+	} {
+		resp, err := pb.PlaceOrder(ctx, models.OrderRequest{
+			Ticker:    "INFY",
+			Exchange:  "NSE",
+			Side:      tc.side,
+			OrderType: models.Limit,
+			Product:   models.MIS,
+			Quantity:  10,
+			Price:     tc.limit,
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.side, err)
+		}
+		order, _ := pb.GetOrderByID(ctx, resp.OrderID)
+		if (tc.side == models.Buy && order.AvgPrice > tc.limit) || (tc.side == models.Sell && order.AvgPrice < tc.limit) {
+			t.Errorf("%s: expected fill within the ₹%.0f limit, got %.2f", tc.side, tc.limit, order.AvgPrice)
+		}
+	}
+}
+
+func TestPaperBroker_FillModelWorstBelowMidpoint(t *testing.T) {
+	ctx := context.Background()
+	fill := func(model FillModel, side models.OrderSide, limit float64) float64 {
+		pb := NewPaperBroker(&PaperBrokerConfig{
+			InitialCapital: 1_000_000,
+			SlippagePct:    0.5,
+			FillModel:      model,
+		})
+		pb.SetPrice("INFY", 1500)
+		resp, err := pb.PlaceOrder(ctx, models.OrderRequest{
+			Ticker:    "INFY",
+			Exchange:  "NSE",
+			Side:      side,
+			OrderType: models.Limit,
+			Product:   models.MIS,
+			Quantity:  10,
+			Price:     limit,
+		})
+		if err != nil {
+			t.Fatalf("%s %s: unexpected error: %v", model, side, err)
+		}
+		order, _ := pb.GetOrderByID(ctx, resp.OrderID)
+		return order.AvgPrice
+	}
+
+	// Midpoint ₹1540 on a buy, ₹1460 on a sell; full 0.5% slippage puts
+	// the worst fill at ₹1547.70 and ₹1452.70.
+	buyMid, buyWorst := fill(FillMidpoint, models.Buy, 1580), fill(FillWorst, models.Buy, 1580)
+	if math.Abs(buyWorst-1547.70) > 0.01 || buyWorst <= buyMid {
+		t.Errorf("buy: expected worst fill ₹1547.70 above midpoint fill %.2f, got %.2f", buyMid, buyWorst)
+	}
+	sellMid, sellWorst := fill(FillMidpoint, models.Sell, 1420), fill(FillWorst, models.Sell, 1420)
+	if math.Abs(sellWorst-1452.70) > 0.01 || sellWorst >= sellMid {
+		t.Errorf("sell: expected worst fill ₹1452.70 below midpoint fill %.2f, got %.2f", sellMid, sellWorst)
+	}
+}
+
+func TestPaperBroker_FillLatency(t *testing.T) {
+	pb := NewPaperBroker(&PaperBrokerConfig{FillLatency: 30 * time.Millisecond})
+	ctx := context.Background()
+
+	req := models.OrderRequest{
+		Ticker:    "INFY",
+		Exchange:  "NSE",
+		Side:      models.Buy,
+		OrderType: models.Limit,
+		Product:   models.CNC,
+		Quantity:  10,
+		Price:     1500,
+	}
+	resp, err := pb.PlaceOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != string(models.OrderOpen) {
+		t.Errorf("response status: got %s, want OPEN", resp.Status)
+	}
+	order, _ := pb.GetOrderByID(ctx, resp.OrderID)
+	if order.Status != models.OrderOpen || order.PendingQty != 10 {
+		t.Errorf("order before latency: status %s, pending %d", order.Status, order.PendingQty)
+	}
+	if holdings, _ := pb.GetHoldings(ctx); len(holdings) != 0 {
+		t.Errorf("holdings before fill: %d", len(holdings))
+	}
+
+	cancelled, _ := pb.PlaceOrder(ctx, req)
+	if err := pb.CancelOrder(ctx, cancelled.OrderID); err != nil {
+		t.Fatalf("cancel open order: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for order.Status == models.OrderOpen && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		order, _ = pb.GetOrderByID(ctx, resp.OrderID)
+	}
+	if order.Status != models.OrderComplete || order.FilledQty != 10 || order.AvgPrice <= 0 {
+		t.Fatalf("order after latency: status %s, filled %d @ %.2f", order.Status, order.FilledQty, order.AvgPrice)
+	}
+	holdings, _ := pb.GetHoldings(ctx)
+	if len(holdings) != 1 || holdings[0].Quantity != 10 {
+		t.Errorf("holdings after fill: %+v", holdings)
+	}
+
+	if o, _ := pb.GetOrderByID(ctx, cancelled.OrderID); o.Status != models.OrderCancelled {
+		t.Errorf("cancelled order: got %s", o.Status)
+	}
+}
+
 func TestPaperBroker_TotalPnL(t *testing.T) {
 	pb := NewPaperBroker(&PaperBrokerConfig{
 		InitialCapital: 1_000_000,
@@ -869,6 +1295,369 @@ func TestPaperBroker_ModifyOrder(t *testing.T) {
 	}
 }
 
+func TestPaperBroker_Analytics_CashFlows(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pb := NewPaperBroker(&PaperBrokerConfig{
+		InitialCapital: 1_000_000,
+		SlippagePct:    0.001,
+		StartDate:      start,
+	})
+	ctx := context.Background()
+
+	buy := func(price float64) {
+		t.Helper()
+		_, err := pb.PlaceOrder(ctx, models.OrderRequest{
+			Ticker:    "INFY",
+			Exchange:  "NSE",
+			Side:      models.Buy,
+			OrderType: models.Limit,
+			Product:   models.CNC,
+			Quantity:  100,
+			Price:     price,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Gain 10% on the first lot, then deposit ₹10L and lose on both lots.
+	buy(1000)
+	pb.SetPrice("INFY", 1100)
+	if err := pb.AddCashFlow(1_000_000, start.AddDate(0, 3, 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buy(1100)
+	pb.SetPrice("INFY", 1000)
+
+	asOf := start.AddDate(0, 6, 0)
+	a, err := pb.Analytics(asOf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if a.NetCashFlow != 1_000_000 {
+		t.Errorf("expected net cash flow ₹10L, got %f", a.NetCashFlow)
+	}
+	// End value ≈ ₹19.9L: naive ≈ -0.5%, TWR ≈ 1.01 × (19.9/20.1) - 1 ≈ -0.005%.
+	if math.Abs(a.NaiveReturnPct-(-0.5)) > 0.01 {
+		t.Errorf("expected naive return ≈ -0.5%%, got %f", a.NaiveReturnPct)
+	}
+	if math.Abs(a.TWRPct-(-0.005)) > 0.01 {
+		t.Errorf("expected TWR ≈ -0.005%%, got %f", a.TWRPct)
+	}
+	if math.Abs(a.TWRPct-a.NaiveReturnPct) < 0.1 {
+		t.Errorf("expected TWR to differ from naive return, both ≈ %f", a.TWRPct)
+	}
+
+	// The IRR must zero the NPV of the cash-flow series.
+	rate := a.MWRPct / 100
+	years := func(d time.Time) float64 { return d.Sub(start).Hours() / 24 / 365 }
+	npv := -1_000_000 -
+		1_000_000/math.Pow(1+rate, years(start.AddDate(0, 3, 0))) +
+		a.EndValue/math.Pow(1+rate, years(asOf))
+	if math.Abs(npv) > 1e-3 {
+		t.Errorf("expected NPV ≈ 0 at IRR %f%%, got %f", a.MWRPct, npv)
+	}
+	if a.MWRPct >= 0 {
+		t.Errorf("expected negative IRR for a losing period, got %f", a.MWRPct)
+	}
+}
+
+func TestPaperBroker_Attribution(t *testing.T) {
+	pb := NewPaperBroker(&PaperBrokerConfig{InitialCapital: 1_000_000, SlippagePct: 1e-9})
+	ctx := context.Background()
+
+	trade := func(ticker string, side models.OrderSide, product models.OrderProduct, qty int, price float64) {
+		t.Helper()
+		_, err := pb.PlaceOrder(ctx, models.OrderRequest{
+			Ticker: ticker, Exchange: "NSE", Side: side,
+			OrderType: models.Limit, Product: product, Quantity: qty, Price: price,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// RELIANCE: one winning and one losing partial exit, fully closed.
+	trade("RELIANCE", models.Buy, models.CNC, 10, 100)
+	trade("RELIANCE", models.Sell, models.CNC, 5, 110)
+	trade("RELIANCE", models.Sell, models.CNC, 5, 95)
+
+	// TCS: one winning round trip, then a new lot marked down by ₹10.
+	trade("TCS", models.Buy, models.MIS, 10, 200)
+	trade("TCS", models.Sell, models.MIS, 10, 220)
+	trade("TCS", models.Buy, models.MIS, 4, 200)
+	pb.SetPrice("TCS", 190)
+
+	got := pb.Attribution()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 tickers, got %d", len(got))
+	}
+	tcs, rel := got[0], got[1]
+	if tcs.Ticker != "TCS" || rel.Ticker != "RELIANCE" {
+		t.Fatalf("expected TCS before RELIANCE, got %s, %s", tcs.Ticker, rel.Ticker)
+	}
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 0.01 }
+	if !near(tcs.RealizedPnL, 200) || !near(tcs.UnrealizedPnL, -40) || !near(tcs.TotalPnL, 160) {
+		t.Errorf("TCS P&L = %+v", tcs)
+	}
+	if tcs.Trades != 1 || tcs.WinRatePct != 100 {
+		t.Errorf("TCS trades = %d, win rate = %f", tcs.Trades, tcs.WinRatePct)
+	}
+	if !near(rel.RealizedPnL, 25) || rel.UnrealizedPnL != 0 || !near(rel.TotalPnL, 25) {
+		t.Errorf("RELIANCE P&L = %+v", rel)
+	}
+	if rel.Trades != 2 || rel.Wins != 1 || rel.WinRatePct != 50 {
+		t.Errorf("RELIANCE trades = %d, wins = %d, win rate = %f", rel.Trades, rel.Wins, rel.WinRatePct)
+	}
+
+	pb.Reset()
+	if n := len(pb.Attribution()); n != 0 {
+		t.Errorf("expected no attribution after Reset, got %d tickers", n)
+	}
+}
+
+func TestJournalAttribution(t *testing.T) {
+	pb := NewPaperBroker(&PaperBrokerConfig{InitialCapital: 1_000_000, SlippagePct: 1e-9})
+	journal := NewTradeLogger()
+	cfg := DefaultRiskConfig()
+	cfg.InitialCapital = 1_000_000
+	rm := NewRiskManager(pb, cfg)
+	rm.SetLogger(journal)
+	ctx := context.Background()
+
+	trade := func(ticker string, side models.OrderSide, product models.OrderProduct, qty int, price float64) {
+		t.Helper()
+		_, err := rm.PlaceOrder(ctx, models.OrderRequest{
+			Ticker: ticker, Exchange: "NSE", Side: side,
+			OrderType: models.Limit, Product: product, Quantity: qty, Price: price,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	trade("RELIANCE", models.Buy, models.CNC, 10, 100)
+	trade("RELIANCE", models.Sell, models.CNC, 5, 110)
+	trade("RELIANCE", models.Sell, models.CNC, 5, 95)
+	trade("TCS", models.Buy, models.MIS, 10, 200)
+	trade("TCS", models.Sell, models.MIS, 10, 220)
+	trade("TCS", models.Buy, models.MIS, 4, 210)
+	// A risk manager entry for an order the broker already journaled.
+	logs := journal.Logs()
+	dup := logs[len(logs)-1]
+	dup.AgentName = "risk-manager"
+	logs = append(logs, dup)
+
+	got := JournalAttribution(logs)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 tickers, got %d", len(got))
+	}
+	tcs, rel := got[0], got[1]
+	near := func(a, b float64) bool { return math.Abs(a-b) < 0.01 }
+	if tcs.Ticker != "TCS" || !near(tcs.RealizedPnL, 200) || !near(tcs.UnrealizedPnL, 0) || tcs.Trades != 1 {
+		t.Errorf("TCS = %+v", tcs)
+	}
+	if rel.Ticker != "RELIANCE" || !near(rel.RealizedPnL, 25) || rel.Trades != 2 || rel.Wins != 1 {
+		t.Errorf("RELIANCE = %+v", rel)
+	}
+}
+
+func TestJournalHoldings(t *testing.T) {
+	fill := func(id, ticker string, side models.OrderSide, product models.OrderProduct, qty int, price float64) models.TradeLog {
+		return models.TradeLog{
+			OrderRequest: models.OrderRequest{
+				Ticker: ticker, Exchange: "NSE", Side: side, Product: product, Quantity: qty,
+			},
+			OrderResponse: &models.OrderResponse{OrderID: id},
+			FilledQty:     qty,
+			FillPrice:     price,
+		}
+	}
+	logs := []models.TradeLog{
+		fill("1", "RELIANCE", models.Buy, models.CNC, 10, 100),
+		fill("2", "RELIANCE", models.Buy, "", 10, 120),
+		fill("3", "RELIANCE", models.Sell, models.CNC, 5, 130),
+		fill("4", "INFY", models.Buy, models.CNC, 5, 1500),
+		fill("5", "INFY", models.Sell, models.CNC, 5, 1550),
+		fill("6", "TCS", models.Buy, models.MIS, 10, 200),
+	}
+	logs = append(logs, logs[0]) // journaled twice
+
+	got := JournalHoldings(logs)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 holding, got %+v", got)
+	}
+	h := got[0]
+	near := func(a, b float64) bool { return math.Abs(a-b) < 0.01 }
+	if h.Ticker != "RELIANCE" || h.Exchange != "NSE" || h.Quantity != 15 || !near(h.AvgPrice, 110) {
+		t.Errorf("holding = %+v", h)
+	}
+	if !near(h.LTP, 130) || !near(h.PnL, 300) || !near(h.CurrentValue, 1950) {
+		t.Errorf("holding value = %+v", h)
+	}
+}
+
+func TestPaperBroker_AddCashFlow_Validation(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pb := NewPaperBroker(&PaperBrokerConfig{InitialCapital: 100_000, StartDate: start})
+
+	if err := pb.AddCashFlow(0, start); err == nil {
+		t.Error("expected error for zero amount")
+	}
+	if err := pb.AddCashFlow(50_000, start.AddDate(0, 0, -1)); err == nil {
+		t.Error("expected error for flow before start date")
+	}
+	if err := pb.AddCashFlow(-200_000, start.AddDate(0, 0, 1)); !errors.Is(err, ErrInsufficientMargin) {
+		t.Errorf("expected ErrInsufficientMargin, got %v", err)
+	}
+	if err := pb.AddCashFlow(-40_000, start.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	margins, _ := pb.GetMargins(context.Background())
+	if margins.AvailableCash != 60_000 {
+		t.Errorf("expected ₹60,000 after withdrawal, got %f", margins.AvailableCash)
+	}
+	if flows := pb.CashFlows(); len(flows) != 1 || flows[0].ValueBefore != 100_000 {
+		t.Errorf("unexpected cash flows: %+v", flows)
+	}
+}
+
+// ════════════════════════════════════════════════════════════════════
+// Margin Calculator Tests
+// ════════════════════════════════════════════════════════════════════
+
+func TestRequiredMargin_EquityMIS(t *testing.T) {
+	m, err := RequiredMargin(models.OrderRequest{
+		Ticker:   "RELIANCE",
+		Exchange: "NSE",
+		Side:     models.Buy,
+		Product:  models.MIS,
+		Quantity: 100,
+	}, 2500)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// ₹2,50,000 at 5x leverage
+	if m != 50_000 {
+		t.Errorf("expected ₹50,000 MIS margin, got %f", m)
+	}
+}
+
+func TestRequiredMargin_NFOOptionBuy(t *testing.T) {
+	m, err := RequiredMargin(models.OrderRequest{
+		Ticker:   "NIFTY24DEC24000CE",
+		Exchange: "NFO",
+		Side:     models.Buy,
+		Product:  models.NRML,
+		Quantity: 50,
+	}, 120)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Full premium: 50 × ₹120
+	if m != 6_000 {
+		t.Errorf("expected ₹6,000 premium, got %f", m)
+	}
+}
+
+func TestRequiredMargin_NFOWritesAndFutures(t *testing.T) {
+	fut, err := RequiredMargin(models.OrderRequest{
+		Ticker: "NIFTY24DECFUT", Exchange: "NFO", Side: models.Sell, Product: models.NRML, Quantity: 50,
+	}, 24000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(fut-180_000) > 1e-6 { // 15% of ₹12L notional
+		t.Errorf("expected ₹1,80,000 futures margin, got %f", fut)
+	}
+
+	write, err := RequiredMargin(models.OrderRequest{
+		Ticker: "NIFTY24DEC24000PE", Exchange: "NFO", Side: models.Sell, Product: models.NRML, Quantity: 50,
+	}, 120)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(write-180_000) > 1e-6 { // 15% of strike notional
+		t.Errorf("expected ₹1,80,000 option write margin, got %f", write)
+	}
+
+	// Weekly expiries, and underlyings ending in digits, parse too.
+	for _, ticker := range []string{"NIFTY2412624000CE", "NIFTY24D1224000PE", "NIFTYNXT5024DEC24000CE"} {
+		weekly, err := RequiredMargin(models.OrderRequest{
+			Ticker: ticker, Exchange: "NFO", Side: models.Sell, Product: models.NRML, Quantity: 50,
+		}, 120)
+		if err != nil || math.Abs(weekly-180_000) > 1e-6 {
+			t.Errorf("%s: got ₹%f, %v; want ₹1,80,000", ticker, weekly, err)
+		}
+	}
+}
+
+func TestRequiredMargin_EquityProducts(t *testing.T) {
+	// An unset product is delivery: the full order value
+	m, err := RequiredMargin(models.OrderRequest{
+		Ticker: "RELIANCE", Exchange: "NSE", Side: models.Buy, Quantity: 100,
+	}, 2500)
+	if err != nil || m != 250_000 {
+		t.Errorf("unset product: got ₹%f, %v; want ₹2,50,000", m, err)
+	}
+
+	m, err = RequiredMargin(models.OrderRequest{
+		Ticker: "RELIANCE", Exchange: "NSE", Side: models.Buy, Product: models.NRML, Quantity: 100,
+	}, 2500)
+	if err != nil || math.Abs(m-37_500) > 1e-6 { // 15% of ₹2.5L
+		t.Errorf("NRML: got ₹%f, %v; want ₹37,500", m, err)
+	}
+}
+
+func TestPaperBroker_ClosingReleasesBlockedMargin(t *testing.T) {
+	pb := NewPaperBroker(&PaperBrokerConfig{InitialCapital: 100_000, SlippagePct: 0.001})
+	ctx := context.Background()
+	option := models.OrderRequest{
+		Ticker: "NIFTY24DEC24000CE", Exchange: "NFO", Side: models.Buy,
+		OrderType: models.Limit, Product: models.NRML, Quantity: 65, Price: 120,
+	}
+	if _, err := pb.PlaceOrder(ctx, option); err != nil {
+		t.Fatalf("buy: %v", err)
+	}
+	option.Side, option.Price = models.Sell, 150
+	if _, err := pb.PlaceOrder(ctx, option); err != nil {
+		t.Fatalf("sell: %v", err)
+	}
+
+	m, _ := pb.GetMargins(ctx)
+	if math.Abs(m.UsedMargin) > 1 {
+		t.Errorf("expected no margin blocked after closing, got ₹%.2f", m.UsedMargin)
+	}
+	if math.Abs(m.AvailableCash-101_950) > 1 { // ₹30 gain on 65 units
+		t.Errorf("expected ₹1,01,950 cash after closing, got ₹%.2f", m.AvailableCash)
+	}
+}
+
+func TestPaperBroker_PlaceOrder_UsesRequiredMargin(t *testing.T) {
+	pb := NewPaperBroker(&PaperBrokerConfig{InitialCapital: 10_000, SlippagePct: 0.001})
+	ctx := context.Background()
+
+	// ₹7,800 premium fits within ₹10,000
+	_, err := pb.PlaceOrder(ctx, models.OrderRequest{
+		Ticker: "NIFTY24DEC24000CE", Exchange: "NFO", Side: models.Buy,
+		OrderType: models.Limit, Product: models.NRML, Quantity: 65, Price: 120,
+	})
+	if err != nil {
+		t.Fatalf("expected option buy to fill, got %v", err)
+	}
+
+	// ₹24,000 premium does not
+	_, err = pb.PlaceOrder(ctx, models.OrderRequest{
+		Ticker: "BANKNIFTY24DEC52000CE", Exchange: "NFO", Side: models.Buy,
+		OrderType: models.Limit, Product: models.NRML, Quantity: 30, Price: 800,
+	})
+	if err != ErrInsufficientMargin {
+		t.Errorf("expected ErrInsufficientMargin, got %v", err)
+	}
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Zerodha Broker Tests
 // ════════════════════════════════════════════════════════════════════
@@ -1391,6 +2180,42 @@ func TestRiskManager_Approval_Denied(t *testing.T) {
 	}
 }
 
+func TestRiskManager_ResolveApprovalRechecksRisk(t *testing.T) {
+	pb := NewPaperBroker(&PaperBrokerConfig{InitialCapital: 1_000_000})
+	cfg := RiskConfig{
+		MaxPositionPct:    10.0,
+		MaxOrderValuePct:  20.0,
+		DailyLossLimitPct: 5.0,
+		MaxOpenPositions:  10,
+		RequireApproval:   true,
+		ParkApprovals:     true,
+		InitialCapital:    1_000_000,
+	}
+	rm := NewRiskManager(pb, cfg)
+
+	ctx := context.Background()
+	resp, err := rm.PlaceOrder(ctx, models.OrderRequest{
+		Ticker: "HCLTECH", Exchange: "NSE", Side: models.Buy, OrderType: models.Limit,
+		Product: models.CNC, Quantity: 5, Price: 1500,
+	})
+	if err != nil || resp.Status != StatusPendingApproval {
+		t.Fatalf("expected a parked order, got %+v, %v", resp, err)
+	}
+
+	// The limits tighten while the order waits
+	cfg.MaxPositionPct = 0.1
+	cfg.MaxOrderValuePct = 0.1
+	rm.UpdateConfig(cfg)
+
+	resp, err = rm.ResolveApproval(ctx, resp.ApprovalID, true, "")
+	if !errors.Is(err, ErrTradeBlocked) || resp.Status != "REJECTED" {
+		t.Fatalf("expected the approved order to fail the risk re-check, got %+v, %v", resp, err)
+	}
+	if orders, _ := pb.GetOrders(ctx); len(orders) != 0 {
+		t.Errorf("blocked order reached the broker: %+v", orders)
+	}
+}
+
 func TestRiskManager_Approval_Approved(t *testing.T) {
 	pb := NewPaperBroker(&PaperBrokerConfig{
 		InitialCapital: 1_000_000,
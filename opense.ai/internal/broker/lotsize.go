@@ -0,0 +1,67 @@
+package broker
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ════════════════════════════════════════════════════════════════════
+// F&O Lot Sizes
+// ════════════════════════════════════════════════════════════════════
+
+// lotSizes maps F&O underlyings to their contract lot size. The bundled
+// entries are the index lots from the NSE and BSE revision of January
+// 2026. Stock lots are revised too often to bundle: set them, and any
+// later index revision, with SetLotSizes from the lot_sizes config.
+var (
+	lotSizesMu sync.RWMutex
+	lotSizes   = map[string]int{
+		// NSE indices
+		"NIFTY":      65,
+		"BANKNIFTY":  30,
+		"FINNIFTY":   60,
+		"MIDCPNIFTY": 120,
+		"NIFTYNXT50": 25,
+
+		// BSE indices
+		"SENSEX": 20,
+		"BANKEX": 30,
+	}
+)
+
+// LotSize returns the contract lot size for an F&O symbol such as
+// NIFTY24DEC24000CE or BANKNIFTY24DECFUT, keyed by its underlying.
+func LotSize(ticker string) (int, bool) {
+	lotSizesMu.RLock()
+	defer lotSizesMu.RUnlock()
+	size, ok := lotSizes[Underlying(ticker)]
+	return size, ok
+}
+
+// SetLotSize sets the lot size of an underlying, replacing the bundled value.
+func SetLotSize(underlying string, size int) {
+	lotSizesMu.Lock()
+	defer lotSizesMu.Unlock()
+	lotSizes[strings.ToUpper(underlying)] = size
+}
+
+// SetLotSizes sets the lot size of each underlying in sizes, such as the
+// top-level lot_sizes config. Entries that are not positive are ignored.
+func SetLotSizes(sizes map[string]int) {
+	for underlying, size := range sizes {
+		if size > 0 {
+			SetLotSize(underlying, size)
+		}
+	}
+}
+
+// Underlying returns the underlying of an F&O symbol: everything before the
+// expiry digits, e.g. "NIFTY" for NIFTY24DEC24000CE.
+func Underlying(ticker string) string {
+	ticker = strings.ToUpper(strings.TrimSpace(ticker))
+	if i := strings.IndexFunc(ticker, unicode.IsDigit); i > 0 {
+		return ticker[:i]
+	}
+	return ticker
+}
@@ -0,0 +1,89 @@
+package broker
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// ════════════════════════════════════════════════════════════════════
+// Margin Calculator
+// ════════════════════════════════════════════════════════════════════
+
+// Broker-neutral margin approximations. Actual SPAN margins are published
+// daily by the clearing corporation and vary by instrument and volatility.
+const (
+	misLeverage    = 5    // intraday equity leverage (20% margin)
+	nfoSpanPct     = 0.12 // approximate SPAN margin on F&O notional
+	nfoExposurePct = 0.03 // approximate exposure margin on F&O notional
+)
+
+// optionSymbolRe matches F&O option symbols and captures the strike:
+// monthly expiries such as NIFTY24DEC24000CE (year, month) and weekly ones
+// such as NIFTY24D1224000CE (year, month code 1-9/O/N/D, day).
+var optionSymbolRe = regexp.MustCompile(`^[A-Z][A-Z0-9&-]*?\d{2}(?:[A-Z]{3}|[1-9OND]\d{2})(\d+(?:\.\d+)?)(?:CE|PE)$`)
+
+// optionStrike returns the strike of an option symbol, reporting false
+// for anything else, such as a futures symbol.
+func optionStrike(symbol string) (float64, bool) {
+	m := optionSymbolRe.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(symbol)))
+	if m == nil {
+		return 0, false
+	}
+	strike, err := strconv.ParseFloat(m[1], 64)
+	return strike, err == nil && strike > 0
+}
+
+// RequiredMargin estimates the margin needed to place req at price ltp
+// (falling back to req.Price when ltp is zero).
+//
+//   - NSE/BSE CNC buys need the full order value; CNC sells of held shares
+//     need none. An order without a product is treated as CNC.
+//   - NSE/BSE MIS orders need the order value divided by misLeverage.
+//   - NSE/BSE NRML orders need SPAN + exposure margin on the order value.
+//   - NFO/BFO futures, and option writes, need SPAN + exposure margin on the
+//     notional (option notional uses the strike parsed from a monthly or
+//     weekly symbol).
+//   - NFO/BFO option buys need the full premium.
+func RequiredMargin(req models.OrderRequest, ltp float64) (float64, error) {
+	price := ltp
+	if price <= 0 {
+		price = req.Price
+	}
+	if price <= 0 {
+		return 0, fmt.Errorf("margin: no price for %s", req.Ticker)
+	}
+	if req.Quantity <= 0 {
+		return 0, fmt.Errorf("margin: quantity must be positive")
+	}
+	qty := float64(req.Quantity)
+	value := price * qty
+
+	if ex := strings.ToUpper(req.Exchange); ex != "NFO" && ex != "BFO" {
+		switch req.Product {
+		case models.CNC, "":
+			if req.Side == models.Sell {
+				return 0, nil
+			}
+			return value, nil
+		case models.MIS:
+			return value / misLeverage, nil
+		case models.NRML:
+			return value * (nfoSpanPct + nfoExposurePct), nil
+		default:
+			return 0, fmt.Errorf("margin: product %s not supported on %s", req.Product, req.Exchange)
+		}
+	}
+
+	strike, isOption := optionStrike(req.Ticker)
+	if !isOption {
+		return value * (nfoSpanPct + nfoExposurePct), nil
+	}
+	if req.Side == models.Buy {
+		return value, nil
+	}
+	return strike * qty * (nfoSpanPct + nfoExposurePct), nil
+}
@@ -53,10 +53,10 @@ func ValidateOrder(req models.OrderRequest) *ValidationResult {
 		result.addError("ticker", "ticker is required")
 	}
 
-	// Exchange must be NSE, BSE, or NFO
+	// Exchange must be NSE, BSE, NFO, or BFO
 	exchange := strings.ToUpper(req.Exchange)
-	if exchange != "NSE" && exchange != "BSE" && exchange != "NFO" {
-		result.addError("exchange", fmt.Sprintf("invalid exchange %q, must be NSE, BSE, or NFO", req.Exchange))
+	if exchange != "NSE" && exchange != "BSE" && exchange != "NFO" && exchange != "BFO" {
+		result.addError("exchange", fmt.Sprintf("invalid exchange %q, must be NSE, BSE, NFO, or BFO", req.Exchange))
 	}
 
 	// Side must be BUY or SELL
@@ -105,9 +105,24 @@ func ValidateOrder(req models.OrderRequest) *ValidationResult {
 		result.addError("price", "price cannot be negative")
 	}
 
-	// F&O product check — NRML only on NFO exchange
-	if req.Product == models.NRML && exchange != "NFO" {
-		result.addError("product", "NRML product is only valid on NFO exchange")
+	// F&O product check — NRML only on the NFO and BFO exchanges
+	derivatives := exchange == "NFO" || exchange == "BFO"
+	if req.Product == models.NRML && !derivatives {
+		result.addError("product", "NRML product is only valid on NFO and BFO exchanges")
+	}
+
+	// F&O quantities must be whole lots
+	if derivatives && req.Quantity > 0 {
+		if lot, ok := LotSize(req.Ticker); ok && req.Quantity%lot != 0 {
+			lower := req.Quantity / lot * lot
+			msg := fmt.Sprintf("quantity %d is not a multiple of the %s lot size %d", req.Quantity, Underlying(req.Ticker), lot)
+			if lower > 0 {
+				msg += fmt.Sprintf(" (try %d or %d)", lower, lower+lot)
+			} else {
+				msg += fmt.Sprintf(" (try %d)", lot)
+			}
+			result.addError("quantity", msg)
+		}
 	}
 
 	return result
@@ -2,7 +2,9 @@ package broker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"sync"
 	"time"
@@ -37,9 +39,48 @@ type PaperBroker struct {
 	// Configuration
 	slippagePct float64 // simulated slippage (default 0.05%)
 	fillDelay   time.Duration
+	fillLatency time.Duration
+	fillModel   FillModel
+
+	// Market prices set via SetPrice, used to fill marketable limit orders
+	prices map[string]float64
 
 	// Trade log
 	logger *TradeLogger
+
+	// Performance tracking
+	startedAt time.Time
+	cashFlows []CashFlow
+	closed    map[string]*closedTrades // key: "TICKER"
+}
+
+// FillModel controls the price at which the paper broker fills a limit
+// order that is marketable, i.e. a buy limit at or above the market price
+// or a sell limit at or below it.
+type FillModel int
+
+const (
+	// FillLimitPrice fills at the limit price with random slippage (default).
+	FillLimitPrice FillModel = iota
+	// FillMidpoint fills halfway between the limit and market price, sharing
+	// the price improvement. The fill never crosses the limit.
+	FillMidpoint
+	// FillWorst fills at the midpoint moved against the trader by the full
+	// slippage percentage, capped at the limit: the least favourable price
+	// the midpoint model can produce.
+	FillWorst
+)
+
+// String returns the fill model name.
+func (m FillModel) String() string {
+	switch m {
+	case FillMidpoint:
+		return "midpoint"
+	case FillWorst:
+		return "worst"
+	default:
+		return "limit"
+	}
 }
 
 // PaperBrokerConfig holds configuration for the paper broker.
@@ -47,6 +88,12 @@ type PaperBrokerConfig struct {
 	InitialCapital float64       // starting capital in INR (default: ₹10,00,000)
 	SlippagePct    float64       // simulated slippage percentage (default: 0.05%)
 	FillDelay      time.Duration // simulated order fill delay (default: 100ms)
+	StartDate      time.Time     // date the initial capital was funded (default: now)
+	FillModel      FillModel     // fill price for marketable limit orders (default: FillLimitPrice)
+
+	// FillLatency is how long an accepted order stays OPEN before it fills,
+	// as on a live exchange. Zero fills immediately (default).
+	FillLatency time.Duration
 }
 
 // NewPaperBroker creates a new paper trading simulator.
@@ -70,6 +117,11 @@ func NewPaperBroker(cfg *PaperBrokerConfig) *PaperBroker {
 		fillDelay = 100 * time.Millisecond
 	}
 
+	startedAt := cfg.StartDate
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+
 	return &PaperBroker{
 		initialCapital: capital,
 		cash:           capital,
@@ -78,7 +130,12 @@ func NewPaperBroker(cfg *PaperBrokerConfig) *PaperBroker {
 		holdings:       make(map[string]*models.Holding),
 		slippagePct:    slippage,
 		fillDelay:      fillDelay,
+		fillLatency:    cfg.FillLatency,
+		fillModel:      cfg.FillModel,
+		prices:         make(map[string]float64),
 		logger:         NewTradeLogger(),
+		startedAt:      startedAt,
+		closed:         make(map[string]*closedTrades),
 	}
 }
 
@@ -159,7 +216,9 @@ func (pb *PaperBroker) GetOrderByID(_ context.Context, orderID string) (*models.
 	return &out, nil
 }
 
-// PlaceOrder simulates placing an order with the exchange.
+// PlaceOrder simulates placing an order with the exchange. With a
+// FillLatency configured, an accepted order is returned OPEN and fills in
+// the background once the latency has passed, unless it is cancelled first.
 func (pb *PaperBroker) PlaceOrder(ctx context.Context, req models.OrderRequest) (*models.OrderResponse, error) {
 	// Validate the order
 	validation := ValidateOrder(req)
@@ -194,64 +253,136 @@ func (pb *PaperBroker) PlaceOrder(ctx context.Context, req models.OrderRequest)
 		Tag:          req.Tag,
 	}
 
+	pb.orders[orderID] = order
+
 	// Compute fill price with slippage
 	fillPrice := pb.computeFillPrice(req)
+	if err := pb.checkMargin(req, fillPrice); err != nil {
+		return pb.reject(order, req, err)
+	}
+
+	if pb.fillLatency > 0 {
+		order.Status = models.OrderOpen
+		order.PendingQty = req.Quantity
+		time.AfterFunc(pb.fillLatency, func() { pb.fillOpenOrder(orderID) })
+		return &models.OrderResponse{
+			OrderID: orderID,
+			Status:  string(models.OrderOpen),
+			Message: fmt.Sprintf("fill expected in %s", pb.fillLatency),
+		}, nil
+	}
+
+	pb.fill(order, req, fillPrice)
+	return &models.OrderResponse{
+		OrderID: orderID,
+		Status:  "COMPLETE",
+		Message: fmt.Sprintf("filled at ₹%.2f", fillPrice),
+	}, nil
+}
 
-	// Check margin
-	requiredMargin := pb.computeRequiredMargin(req, fillPrice)
+// checkMargin returns why an order filling at fillPrice cannot be
+// afforded, or nil. Orders that only reduce an open position need no
+// margin. Caller must hold pb.mu.
+func (pb *PaperBroker) checkMargin(req models.OrderRequest, fillPrice float64) error {
+	if pb.reducesPosition(req) {
+		return nil
+	}
+	requiredMargin, err := RequiredMargin(req, fillPrice)
+	if err != nil {
+		return err
+	}
 	available := pb.cash - pb.usedMargin
 	if requiredMargin > available {
-		order.Status = models.OrderRejected
-		order.StatusMessage = fmt.Sprintf("insufficient margin: need ₹%.2f, available ₹%.2f", requiredMargin, available)
-		pb.orders[orderID] = order
-
-		pb.logger.Log(models.TradeLog{
-			OrderRequest: req,
-			OrderResponse: &models.OrderResponse{
-				OrderID: orderID,
-				Status:  "REJECTED",
-				Message: order.StatusMessage,
-			},
-			Approved:  false,
-			AgentName: "paper-broker",
-			Reason:    order.StatusMessage,
-		})
+		return fmt.Errorf("%w: need ₹%.2f, available ₹%.2f", ErrInsufficientMargin, requiredMargin, available)
+	}
+	return nil
+}
 
-		return &models.OrderResponse{
-			OrderID: orderID,
+// reject marks order rejected for cause and logs it, returning the
+// response and error PlaceOrder reports. Caller must hold pb.mu.
+func (pb *PaperBroker) reject(order *models.Order, req models.OrderRequest, cause error) (*models.OrderResponse, error) {
+	order.Status = models.OrderRejected
+	order.StatusMessage = cause.Error()
+	order.UpdatedAt = time.Now()
+
+	pb.logger.Log(models.TradeLog{
+		OrderRequest: req,
+		OrderResponse: &models.OrderResponse{
+			OrderID: order.OrderID,
 			Status:  "REJECTED",
 			Message: order.StatusMessage,
-		}, ErrInsufficientMargin
+		},
+		Approved:  false,
+		AgentName: "paper-broker",
+		Reason:    order.StatusMessage,
+	})
+
+	if errors.Is(cause, ErrInsufficientMargin) {
+		cause = ErrInsufficientMargin
+	} else {
+		cause = fmt.Errorf("%w: %v", ErrOrderRejected, cause)
 	}
+	return &models.OrderResponse{
+		OrderID: order.OrderID,
+		Status:  "REJECTED",
+		Message: order.StatusMessage,
+	}, cause
+}
 
-	// Simulate fill
+// fill completes order at fillPrice, updates positions and holdings, and
+// logs the fill. Caller must hold pb.mu.
+func (pb *PaperBroker) fill(order *models.Order, req models.OrderRequest, fillPrice float64) {
 	order.Status = models.OrderComplete
 	order.AvgPrice = fillPrice
 	order.FilledQty = req.Quantity
 	order.PendingQty = 0
-	order.UpdatedAt = now
-
-	pb.orders[orderID] = order
+	order.UpdatedAt = time.Now()
 
-	// Update positions/holdings
 	pb.updatePositions(order)
 
-	// Log the trade
 	pb.logger.Log(models.TradeLog{
 		OrderRequest: req,
 		OrderResponse: &models.OrderResponse{
-			OrderID: orderID,
+			OrderID: order.OrderID,
 			Status:  "COMPLETE",
 		},
 		Approved:  true,
 		AgentName: "paper-broker",
+		FilledQty: order.FilledQty,
+		FillPrice: fillPrice,
+		Charges:   legCharges(req.Side, fillPrice, order.FilledQty, req.Product),
 	})
+}
 
-	return &models.OrderResponse{
-		OrderID: orderID,
-		Status:  "COMPLETE",
-		Message: fmt.Sprintf("filled at ₹%.2f", fillPrice),
-	}, nil
+// fillOpenOrder fills an order left OPEN by PlaceOrder once its latency has
+// passed. The order is priced and margin-checked again as it stands now, so
+// modifications and SetPrice updates made while it was open apply. Orders
+// cancelled in the meantime, or cleared by Reset, are left alone.
+func (pb *PaperBroker) fillOpenOrder(orderID string) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	order, ok := pb.orders[orderID]
+	if !ok || order.Status != models.OrderOpen {
+		return
+	}
+	req := models.OrderRequest{
+		Ticker:       order.Ticker,
+		Exchange:     order.Exchange,
+		Side:         order.Side,
+		OrderType:    order.OrderType,
+		Product:      order.Product,
+		Quantity:     order.Quantity,
+		Price:        order.Price,
+		TriggerPrice: order.TriggerPrice,
+		Tag:          order.Tag,
+	}
+	fillPrice := pb.computeFillPrice(req)
+	if err := pb.checkMargin(req, fillPrice); err != nil {
+		pb.reject(order, req, err)
+		return
+	}
+	pb.fill(order, req, fillPrice)
 }
 
 // ModifyOrder simulates modifying an existing order.
@@ -320,9 +451,19 @@ func (pb *PaperBroker) SubscribeQuotes(_ context.Context, _ []string) (<-chan mo
 
 // Logger returns the trade logger.
 func (pb *PaperBroker) Logger() *TradeLogger {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
 	return pb.logger
 }
 
+// SetLogger replaces the trade logger that fills and rejections are logged
+// to, for example with a journal-backed one from OpenTradeLogger.
+func (pb *PaperBroker) SetLogger(tl *TradeLogger) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.logger = tl
+}
+
 // Reset resets the paper broker to initial state.
 func (pb *PaperBroker) Reset() {
 	pb.mu.Lock()
@@ -333,16 +474,25 @@ func (pb *PaperBroker) Reset() {
 	pb.orders = make(map[string]*models.Order)
 	pb.positions = make(map[string]*models.Position)
 	pb.holdings = make(map[string]*models.Holding)
+	pb.prices = make(map[string]float64)
 	pb.orderCounter = 0
-	pb.logger = NewTradeLogger()
+	if pb.logger.path == "" { // a journal outlives resets
+		pb.logger = NewTradeLogger()
+	}
+	pb.startedAt = time.Now()
+	pb.cashFlows = nil
+	pb.closed = make(map[string]*closedTrades)
 }
 
 // SetPrice simulates updating the LTP (last traded price) for a ticker.
-// This is used for P&L calculation in paper mode.
+// This is used for P&L calculation in paper mode, and as the market price
+// when filling marketable limit orders under the configured FillModel.
 func (pb *PaperBroker) SetPrice(ticker string, price float64) {
 	pb.mu.Lock()
 	defer pb.mu.Unlock()
 
+	pb.prices[ticker] = price
+
 	// Update positions
 	for key, pos := range pb.positions {
 		if pos.Ticker == ticker {
@@ -411,6 +561,12 @@ func (pb *PaperBroker) computeFillPrice(req models.OrderRequest) float64 {
 		}
 	}
 
+	if req.OrderType == models.Limit && pb.fillModel != FillLimitPrice {
+		if price, ok := pb.marketableLimitFill(req); ok {
+			return price
+		}
+	}
+
 	// Apply random slippage
 	slippage := basePrice * (pb.slippagePct / 100) * (rand.Float64()*2 - 1) // ±slippage%
 	if req.Side == models.Buy {
@@ -421,20 +577,63 @@ func (pb *PaperBroker) computeFillPrice(req models.OrderRequest) float64 {
 	return basePrice - absFloat(slippage)
 }
 
-// computeRequiredMargin calculates the margin needed for an order.
-func (pb *PaperBroker) computeRequiredMargin(req models.OrderRequest, fillPrice float64) float64 {
-	value := fillPrice * float64(req.Quantity)
+// marketableLimitFill prices a marketable limit order under the FillMidpoint
+// or FillWorst model. It reports false when the ticker has no market price
+// or the order is not marketable, leaving the default fill. Caller must
+// hold pb.mu.
+func (pb *PaperBroker) marketableLimitFill(req models.OrderRequest) (float64, bool) {
+	limit := req.Price
+	market, ok := pb.prices[req.Ticker]
+	if !ok || market <= 0 || limit <= 0 {
+		return 0, false
+	}
+	buy := req.Side == models.Buy
+	if (buy && limit < market) || (!buy && limit > market) {
+		return 0, false
+	}
 
-	switch req.Product {
-	case models.CNC:
-		return value // full value for delivery
-	case models.MIS:
-		return value * 0.20 // 5x leverage for intraday
-	case models.NRML:
-		return value * 0.15 // ~6.7x for F&O
-	default:
-		return value
+	mid := (limit + market) / 2
+	slippage := mid * (pb.slippagePct / 100)
+	if pb.fillModel != FillWorst {
+		slippage = absFloat(slippage * (rand.Float64()*2 - 1))
+	}
+	if buy {
+		return math.Min(mid+slippage, limit), true
+	}
+	return math.Max(mid-slippage, limit), true
+}
+
+// reducesPosition reports whether req only closes (part of) an existing
+// intraday/F&O position. Caller must hold pb.mu.
+func (pb *PaperBroker) reducesPosition(req models.OrderRequest) bool {
+	if req.Product == models.CNC {
+		return false
+	}
+	pos, ok := pb.positions[fmt.Sprintf("%s:%s", req.Ticker, req.Product)]
+	if !ok {
+		return false
 	}
+	if req.Side == models.Sell {
+		return pos.Quantity >= req.Quantity
+	}
+	return -pos.Quantity >= req.Quantity
+}
+
+// positionMargin returns the margin blocked by qty units of order's
+// instrument held on side at price, as RequiredMargin estimates it. An
+// instrument it cannot price blocks its full value.
+func positionMargin(order *models.Order, side models.OrderSide, qty int, price float64) float64 {
+	margin, err := RequiredMargin(models.OrderRequest{
+		Ticker:   order.Ticker,
+		Exchange: order.Exchange,
+		Side:     side,
+		Product:  order.Product,
+		Quantity: qty,
+	}, price)
+	if err != nil {
+		return price * float64(qty)
+	}
+	return margin
 }
 
 // updatePositions updates positions/holdings based on a filled order.
@@ -457,13 +656,7 @@ func (pb *PaperBroker) updateHoldings(order *models.Order) {
 		pb.cash -= cost
 
 		if exists {
-			// Average up/down
-			totalQty := existing.Quantity + order.FilledQty
-			totalInvested := existing.InvestedValue + cost
-			existing.Quantity = totalQty
-			existing.AvgPrice = totalInvested / float64(totalQty)
-			existing.InvestedValue = totalInvested
-			existing.CurrentValue = existing.LTP * float64(totalQty)
+			addToHolding(existing, order.FilledQty, order.AvgPrice)
 		} else {
 			pb.holdings[key] = &models.Holding{
 				Ticker:        order.Ticker,
@@ -482,6 +675,7 @@ func (pb *PaperBroker) updateHoldings(order *models.Order) {
 		}
 		proceeds := order.AvgPrice * float64(order.FilledQty)
 		pb.cash += proceeds
+		pb.recordClose(order.Ticker, (order.AvgPrice-existing.AvgPrice)*float64(order.FilledQty))
 
 		existing.Quantity -= order.FilledQty
 		existing.InvestedValue = existing.AvgPrice * float64(existing.Quantity)
@@ -493,6 +687,24 @@ func (pb *PaperBroker) updateHoldings(order *models.Order) {
 	}
 }
 
+// addToHolding folds a buy of qty shares at price into h: the quantity
+// grows and the average price becomes the volume-weighted average of the
+// old and new lots. The fill is the latest known price, so the holding is
+// marked to it.
+func addToHolding(h *models.Holding, qty int, price float64) {
+	totalQty := h.Quantity + qty
+	h.InvestedValue = h.AvgPrice*float64(h.Quantity) + price*float64(qty)
+	h.Quantity = totalQty
+	h.AvgPrice = h.InvestedValue / float64(totalQty)
+	h.LTP = price
+	h.CurrentValue = price * float64(totalQty)
+	h.PnL = h.CurrentValue - h.InvestedValue
+	h.PnLPct = 0
+	if h.InvestedValue > 0 {
+		h.PnLPct = (h.PnL / h.InvestedValue) * 100
+	}
+}
+
 // updateTradePositions updates intraday/F&O positions (MIS/NRML).
 func (pb *PaperBroker) updateTradePositions(order *models.Order) {
 	key := fmt.Sprintf("%s:%s", order.Ticker, order.Product)
@@ -504,10 +716,7 @@ func (pb *PaperBroker) updateTradePositions(order *models.Order) {
 		qty = -qty // negative for shorts
 	}
 
-	margin := pb.computeRequiredMargin(models.OrderRequest{
-		Product:  order.Product,
-		Quantity: order.FilledQty,
-	}, order.AvgPrice)
+	margin := positionMargin(order, order.Side, order.FilledQty, order.AvgPrice)
 
 	if !exists {
 		pb.positions[key] = &models.Position{
@@ -524,9 +733,14 @@ func (pb *PaperBroker) updateTradePositions(order *models.Order) {
 		return
 	}
 
-	// Position exists — adjust
+	// Position exists — adjust. Closing releases the margin blocked when
+	// the position was opened.
 	oldQty := existing.Quantity
 	newQty := oldQty + qty
+	held := models.Buy
+	if oldQty < 0 {
+		held = models.Sell
+	}
 
 	if newQty == 0 {
 		// Position closed
@@ -535,8 +749,10 @@ func (pb *PaperBroker) updateTradePositions(order *models.Order) {
 		} else {
 			existing.PnL = (existing.AvgPrice - order.AvgPrice) * float64(-oldQty)
 		}
-		pb.cash += margin + existing.PnL
-		pb.usedMargin -= margin
+		released := positionMargin(order, held, absInt(oldQty), existing.AvgPrice)
+		pb.cash += released + existing.PnL
+		pb.usedMargin -= released
+		pb.recordClose(order.Ticker, existing.PnL)
 		delete(pb.positions, key)
 		return
 	}
@@ -554,14 +770,15 @@ func (pb *PaperBroker) updateTradePositions(order *models.Order) {
 		if closedQty > absInt(oldQty) {
 			closedQty = absInt(oldQty)
 		}
-		if oldQty > 0 {
-			existing.PnL += (order.AvgPrice - existing.AvgPrice) * float64(closedQty)
-		} else {
-			existing.PnL += (existing.AvgPrice - order.AvgPrice) * float64(closedQty)
+		realized := (order.AvgPrice - existing.AvgPrice) * float64(closedQty)
+		if oldQty < 0 {
+			realized = -realized
 		}
-		partialMargin := margin * (float64(closedQty) / float64(absInt(qty)))
-		pb.cash += partialMargin + existing.PnL
-		pb.usedMargin -= partialMargin
+		existing.PnL += realized
+		pb.recordClose(order.Ticker, realized)
+		released := positionMargin(order, held, closedQty, existing.AvgPrice)
+		pb.cash += released + existing.PnL
+		pb.usedMargin -= released
 	}
 
 	existing.Quantity = newQty
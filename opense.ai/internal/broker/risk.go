@@ -3,6 +3,7 @@ package broker
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -31,6 +32,10 @@ type RiskManager struct {
 	// HITL approval channel
 	approvalCh chan ApprovalRequest
 
+	// Orders parked for approval when ParkApprovals is set
+	pending     map[string]PendingApproval
+	approvalSeq int
+
 	logger *TradeLogger
 }
 
@@ -42,6 +47,7 @@ type RiskConfig struct {
 	MaxOrderValuePct  float64 // max single order value as % of capital (default: 10.0)
 	RequireApproval   bool    // require HITL approval for live orders
 	ApprovalTimeout   time.Duration // timeout for HITL approval (default: 60s)
+	ParkApprovals     bool    // park orders needing approval (see ResolveApproval) instead of blocking
 	InitialCapital    float64 // capital base for % calculations
 }
 
@@ -107,6 +113,7 @@ func NewRiskManager(broker Broker, cfg RiskConfig) *RiskManager {
 		broker:     broker,
 		config:     cfg,
 		approvalCh: make(chan ApprovalRequest, 10),
+		pending:    make(map[string]PendingApproval),
 		logger:     NewTradeLogger(),
 	}
 }
@@ -157,19 +164,13 @@ func (rm *RiskManager) PlaceOrder(ctx context.Context, req models.OrderRequest)
 	}
 
 	if !report.Passed {
-		rm.logger.Log(models.TradeLog{
-			OrderRequest: req,
-			Approved:     false,
-			AgentName:    rm.Name(),
-			Reason:       fmt.Sprintf("risk check failed: %v", report.Violations),
-		})
-		return &models.OrderResponse{
-			Status:  "REJECTED",
-			Message: fmt.Sprintf("risk check failed: %v", report.Violations),
-		}, ErrTradeBlocked
+		return rm.blocked(req, report), ErrTradeBlocked
 	}
 
 	// HITL approval if required
+	if rm.config.RequireApproval && rm.config.ParkApprovals {
+		return rm.park(req, *report), nil
+	}
 	if rm.config.RequireApproval {
 		approved, reason, err := rm.requestApproval(ctx, req, *report)
 		if err != nil {
@@ -189,18 +190,52 @@ func (rm *RiskManager) PlaceOrder(ctx context.Context, req models.OrderRequest)
 		}
 	}
 
-	// Delegate to underlying broker
+	return rm.execute(ctx, req)
+}
+
+// blocked logs req as failing the risk checks in report and returns the
+// REJECTED response.
+func (rm *RiskManager) blocked(req models.OrderRequest, report *RiskReport) *models.OrderResponse {
+	rm.logger.Log(models.TradeLog{
+		OrderRequest: req,
+		Approved:     false,
+		AgentName:    rm.Name(),
+		Reason:       fmt.Sprintf("risk check failed: %v", report.Violations),
+	})
+	return &models.OrderResponse{
+		Status:  "REJECTED",
+		Message: fmt.Sprintf("risk check failed: %v", report.Violations),
+	}
+}
+
+// execute places an approved order with the underlying broker and logs it.
+// When the broker logs its own fills to the same logger (see SetLogger)
+// they are logged as they happen; otherwise a fill already made at
+// placement is logged with the order.
+func (rm *RiskManager) execute(ctx context.Context, req models.OrderRequest) (*models.OrderResponse, error) {
 	resp, err := rm.broker.PlaceOrder(ctx, req)
 
 	// Log the trade
+	logsFills := false
+	if fl, ok := rm.broker.(fillLogger); ok {
+		logsFills = fl.Logger() == rm.logger
+	}
 	now := time.Now()
-	rm.logger.Log(models.TradeLog{
+	log := models.TradeLog{
 		OrderRequest:  req,
 		OrderResponse: resp,
 		Approved:      true,
 		ApprovedAt:    &now,
 		AgentName:     rm.Name(),
-	})
+	}
+	if !logsFills && resp != nil && resp.OrderID != "" {
+		if order, err := rm.broker.GetOrderByID(ctx, resp.OrderID); err == nil && order.FilledQty > 0 {
+			log.FilledQty = order.FilledQty
+			log.FillPrice = order.AvgPrice
+			log.Charges = legCharges(req.Side, order.AvgPrice, order.FilledQty, req.Product)
+		}
+	}
+	rm.logger.Log(log)
 
 	// Update day tracking
 	rm.mu.Lock()
@@ -383,6 +418,97 @@ func (rm *RiskManager) requestApproval(ctx context.Context, req models.OrderRequ
 	}
 }
 
+// StatusPendingApproval is the OrderResponse status of a parked order.
+const StatusPendingApproval = "PENDING_APPROVAL"
+
+// PendingApproval is an order parked by the risk manager until a human
+// approves or denies it.
+type PendingApproval struct {
+	ID         string              `json:"id"`
+	Order      models.OrderRequest `json:"order"`
+	RiskReport RiskReport          `json:"risk_report"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// park records req as awaiting approval and returns a pending response
+// carrying the approval ID.
+func (rm *RiskManager) park(req models.OrderRequest, report RiskReport) *models.OrderResponse {
+	rm.mu.Lock()
+	rm.approvalSeq++
+	id := fmt.Sprintf("APR-%d-%d", time.Now().UnixMilli(), rm.approvalSeq)
+	rm.pending[id] = PendingApproval{
+		ID:         id,
+		Order:      req,
+		RiskReport: report,
+		CreatedAt:  time.Now(),
+	}
+	rm.mu.Unlock()
+
+	return &models.OrderResponse{
+		Status:     StatusPendingApproval,
+		Message:    "order awaiting human approval",
+		ApprovalID: id,
+	}
+}
+
+// PendingApprovals returns the parked orders, oldest first.
+func (rm *RiskManager) PendingApprovals() []PendingApproval {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	out := make([]PendingApproval, 0, len(rm.pending))
+	for _, p := range rm.pending {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out
+}
+
+// ResolveApproval approves or denies a parked order. An approved order is
+// risk-checked again, since positions and P&L may have moved while it was
+// parked, then placed with the underlying broker; a denied one is logged
+// and returned as REJECTED with ErrApprovalDenied. Returns
+// ErrApprovalNotFound if id is not pending.
+func (rm *RiskManager) ResolveApproval(ctx context.Context, id string, approve bool, reason string) (*models.OrderResponse, error) {
+	rm.mu.Lock()
+	p, ok := rm.pending[id]
+	delete(rm.pending, id)
+	rm.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrApprovalNotFound, id)
+	}
+
+	if !approve {
+		rm.logger.Log(models.TradeLog{
+			OrderRequest: p.Order,
+			Approved:     false,
+			AgentName:    rm.Name(),
+			Reason:       fmt.Sprintf("approval denied: %s", reason),
+		})
+		return &models.OrderResponse{
+			Status:     "REJECTED",
+			Message:    fmt.Sprintf("human approval denied: %s", reason),
+			ApprovalID: id,
+		}, ErrApprovalDenied
+	}
+
+	report, err := rm.Assess(ctx, p.Order)
+	if err != nil {
+		return nil, fmt.Errorf("risk assessment failed: %w", err)
+	}
+	if !report.Passed {
+		resp := rm.blocked(p.Order, report)
+		resp.ApprovalID = id
+		return resp, ErrTradeBlocked
+	}
+	return rm.execute(ctx, p.Order)
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Accessors
 // ════════════════════════════════════════════════════════════════════
@@ -392,6 +518,17 @@ func (rm *RiskManager) Logger() *TradeLogger {
 	return rm.logger
 }
 
+// SetLogger replaces the risk manager's trade logger, for example with a
+// journal-backed one from OpenTradeLogger. A broker that logs its own
+// fills is switched to tl too, so fills made after placement, such as
+// resting limit orders, are logged when they happen.
+func (rm *RiskManager) SetLogger(tl *TradeLogger) {
+	rm.logger = tl
+	if fl, ok := rm.broker.(fillLogger); ok {
+		fl.SetLogger(tl)
+	}
+}
+
 // Config returns the current risk configuration.
 func (rm *RiskManager) Config() RiskConfig {
 	return rm.config
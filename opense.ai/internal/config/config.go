@@ -19,22 +19,51 @@ type Config struct {
 	Trading    TradingConfig    `mapstructure:"trading"    yaml:"trading"    json:"trading"`
 	Analysis   AnalysisConfig   `mapstructure:"analysis"   yaml:"analysis"   json:"analysis"`
 	FinanceQL  FinanceQLConfig  `mapstructure:"financeql"  yaml:"financeql"  json:"financeql"`
+	DataSource DataSourceConfig `mapstructure:"datasource" yaml:"datasource" json:"datasource"`
 	API        APIConfig        `mapstructure:"api"        yaml:"api"        json:"api"`
 	Web        WebConfig        `mapstructure:"web"        yaml:"web"        json:"web"`
 	Logging    LoggingConfig    `mapstructure:"logging"    yaml:"logging"    json:"logging"`
+
+	// Universes are named ticker lists, selectable with --universe and
+	// FinanceQL's universe("name").
+	Universes map[string][]string `mapstructure:"universes" yaml:"universes" json:"universes"`
+
+	// LotSizes are F&O contract lots per underlying, applied over the
+	// bundled index lots when orders are validated.
+	LotSizes map[string]int `mapstructure:"lot_sizes" yaml:"lot_sizes" json:"lot_sizes"`
 }
 
 // LLMConfig holds LLM provider configuration.
 type LLMConfig struct {
-	Primary      string  `mapstructure:"primary"       yaml:"primary"       json:"primary"`       // "openai", "ollama", "gemini", "anthropic"
+	Primary      string  `mapstructure:"primary"       yaml:"primary"       json:"primary"`       // "openai", "azure", "ollama", "gemini", "anthropic", "grok"
 	OpenAIKey    string  `mapstructure:"openai_key"     yaml:"openai_key"     json:"-"`             // excluded from JSON — use /config/keys
 	OllamaURL    string  `mapstructure:"ollama_url"     yaml:"ollama_url"     json:"ollama_url"`
+	OllamaPromptTools bool `mapstructure:"ollama_prompt_tools" yaml:"ollama_prompt_tools" json:"ollama_prompt_tools"` // describe tools in the prompt instead of native tool calling
 	GeminiKey    string  `mapstructure:"gemini_key"     yaml:"gemini_key"     json:"-"`
 	AnthropicKey string  `mapstructure:"anthropic_key"  yaml:"anthropic_key"  json:"-"`
+	GrokKey      string  `mapstructure:"grok_key"       yaml:"grok_key"       json:"-"`
+	Azure        AzureOpenAIConfig `mapstructure:"azure" yaml:"azure" json:"azure"`
 	Model        string  `mapstructure:"model"          yaml:"model"          json:"model"`
 	FallbackModel string `mapstructure:"fallback_model" yaml:"fallback_model" json:"fallback_model"`
 	Temperature  float64 `mapstructure:"temperature"   yaml:"temperature"   json:"temperature"`
 	MaxTokens    int     `mapstructure:"max_tokens"     yaml:"max_tokens"     json:"max_tokens"`
+	HTTP         LLMHTTPConfig `mapstructure:"http" yaml:"http" json:"http"`
+}
+
+// LLMHTTPConfig tunes the connection pool shared by the LLM provider clients.
+type LLMHTTPConfig struct {
+	MaxIdleConns        int  `mapstructure:"max_idle_conns"          yaml:"max_idle_conns"          json:"max_idle_conns"`
+	MaxIdleConnsPerHost int  `mapstructure:"max_idle_conns_per_host" yaml:"max_idle_conns_per_host" json:"max_idle_conns_per_host"`
+	IdleConnTimeoutSec  int  `mapstructure:"idle_conn_timeout_sec"   yaml:"idle_conn_timeout_sec"   json:"idle_conn_timeout_sec"`
+	DisableHTTP2        bool `mapstructure:"disable_http2"           yaml:"disable_http2"           json:"disable_http2"`
+}
+
+// AzureOpenAIConfig holds Azure OpenAI deployment settings.
+type AzureOpenAIConfig struct {
+	Endpoint   string `mapstructure:"endpoint"    yaml:"endpoint"    json:"endpoint"` // e.g. https://myresource.openai.azure.com
+	Deployment string `mapstructure:"deployment"  yaml:"deployment"  json:"deployment"`
+	APIKey     string `mapstructure:"api_key"     yaml:"api_key"     json:"-"`
+	APIVersion string `mapstructure:"api_version" yaml:"api_version" json:"api_version"`
 }
 
 // BrokerConfig holds broker integration configuration.
@@ -63,14 +92,20 @@ type TradingConfig struct {
 	DailyLossLimitPct   float64 `mapstructure:"daily_loss_limit_pct"  yaml:"daily_loss_limit_pct"  json:"daily_loss_limit_pct"`
 	MaxOpenPositions    int     `mapstructure:"max_open_positions"    yaml:"max_open_positions"    json:"max_open_positions"`
 	RequireConfirmation bool    `mapstructure:"require_confirmation"  yaml:"require_confirmation"  json:"require_confirmation"`
+	ApprovalQueue       bool    `mapstructure:"approval_queue"        yaml:"approval_queue"        json:"approval_queue"`
 	ConfirmTimeoutSec   int     `mapstructure:"confirm_timeout_sec"   yaml:"confirm_timeout_sec"   json:"confirm_timeout_sec"`
 	InitialCapital      float64 `mapstructure:"initial_capital"       yaml:"initial_capital"       json:"initial_capital"`
+	MinRiskReward       float64 `mapstructure:"min_risk_reward"       yaml:"min_risk_reward"       json:"min_risk_reward"`       // smallest reward:risk the executor accepts, e.g. 2 for 1:2; 0 = no floor
+	MinTradeConfidence  float64 `mapstructure:"min_trade_confidence"  yaml:"min_trade_confidence"  json:"min_trade_confidence"`  // smallest analysis confidence (0–1) the executor accepts; 0 = no floor
 }
 
 // AnalysisConfig holds analysis engine settings.
 type AnalysisConfig struct {
 	CacheTTL         int `mapstructure:"cache_ttl"          yaml:"cache_ttl"          json:"cache_ttl"`
 	ConcurrentFetches int `mapstructure:"concurrent_fetches" yaml:"concurrent_fetches" json:"concurrent_fetches"`
+	AgentTimeoutSec  int `mapstructure:"agent_timeout_sec"  yaml:"agent_timeout_sec"  json:"agent_timeout_sec"` // per specialist in multi-agent analysis; 0 = no limit
+	MinConfidence    float64 `mapstructure:"min_confidence" yaml:"min_confidence"     json:"min_confidence"` // re-prompt specialists below this confidence (0–1); 0 = never
+	DebateThreshold  float64 `mapstructure:"debate_threshold" yaml:"debate_threshold" json:"debate_threshold"` // CIO debate when opposing specialist scores are this far apart (0–2); 0 = off
 }
 
 // FinanceQLConfig holds FinanceQL query language settings.
@@ -81,11 +116,39 @@ type FinanceQLConfig struct {
 	REPLHistoryFile     string `mapstructure:"repl_history_file"      yaml:"repl_history_file"      json:"repl_history_file"`
 }
 
+// DataSourceConfig chooses the market data source for each data category,
+// by name: yfinance, nse, nse_derivatives, or screener. Empty keeps the
+// default (Yahoo Finance with NSE fallback for quotes, Screener.in for
+// fundamentals, NSE derivatives for options).
+type DataSourceConfig struct {
+	Quotes       string `mapstructure:"quotes"       yaml:"quotes"       json:"quotes"`
+	Fundamentals string `mapstructure:"fundamentals" yaml:"fundamentals" json:"fundamentals"`
+	Options      string `mapstructure:"options"      yaml:"options"      json:"options"`
+}
+
 // APIConfig holds HTTP/gRPC API server settings.
 type APIConfig struct {
-	Host        string   `mapstructure:"host"         yaml:"host"         json:"host"`
-	Port        int      `mapstructure:"port"         yaml:"port"         json:"port"`
-	CORSOrigins []string `mapstructure:"cors_origins"  yaml:"cors_origins"  json:"cors_origins"`
+	Host          string            `mapstructure:"host"           yaml:"host"           json:"host"`
+	Port          int               `mapstructure:"port"           yaml:"port"           json:"port"`
+	CORSOrigins   []string          `mapstructure:"cors_origins"   yaml:"cors_origins"   json:"cors_origins"`
+	WebhookSecret string            `mapstructure:"webhook_secret" yaml:"webhook_secret" json:"-"` // HMAC key for alert webhooks
+	TradingView   TradingViewConfig `mapstructure:"tradingview"    yaml:"tradingview"    json:"tradingview"`
+
+	RefreshTickers     []string `mapstructure:"refresh_tickers"      yaml:"refresh_tickers"      json:"refresh_tickers"`      // quotes kept warm by the server
+	RefreshIntervalSec int      `mapstructure:"refresh_interval_sec" yaml:"refresh_interval_sec" json:"refresh_interval_sec"` // seconds between refreshes
+
+	AllowedOrigins []string `mapstructure:"allowed_origins" yaml:"allowed_origins" json:"allowed_origins"` // browser origins allowed to open /ws; "*" allows any
+	WSToken        string   `mapstructure:"ws_token"        yaml:"ws_token"        json:"-"`               // required on /ws when set
+}
+
+// TradingViewConfig maps a TradingView alert payload to a paper order.
+// Field names may be dotted paths into nested objects, e.g. "strategy.order.action".
+type TradingViewConfig struct {
+	TickerField   string `mapstructure:"ticker_field"   yaml:"ticker_field"   json:"ticker_field"`
+	ActionField   string `mapstructure:"action_field"   yaml:"action_field"   json:"action_field"`   // "buy" or "sell"
+	QuantityField string `mapstructure:"quantity_field" yaml:"quantity_field" json:"quantity_field"`
+	Exchange      string `mapstructure:"exchange"       yaml:"exchange"       json:"exchange"`
+	Product       string `mapstructure:"product"        yaml:"product"        json:"product"` // "CNC" or "MIS"
 }
 
 // WebConfig holds Next.js frontend configuration.
@@ -117,7 +180,7 @@ func Load() (*Config, error) {
 	v.SetConfigName("config")
 	v.SetConfigType("yaml")
 	v.AddConfigPath("./config")
-	v.AddConfigPath(filepath.Join(homeDir(), ".openseai"))
+	v.AddConfigPath(Dir())
 	v.AddConfigPath("/etc/openseai")
 
 	// Environment variable settings
@@ -172,9 +235,15 @@ func setDefaults(v *viper.Viper) {
 	// LLM defaults
 	v.SetDefault("llm.primary", "openai")
 	v.SetDefault("llm.ollama_url", "http://localhost:11434")
+	v.SetDefault("llm.ollama_prompt_tools", false)
 	v.SetDefault("llm.model", "gpt-4o")
 	v.SetDefault("llm.temperature", 0.1)
 	v.SetDefault("llm.max_tokens", 4096)
+	v.SetDefault("llm.azure.api_version", "2024-06-01")
+	v.SetDefault("llm.http.max_idle_conns", 100)
+	v.SetDefault("llm.http.max_idle_conns_per_host", 16)
+	v.SetDefault("llm.http.idle_conn_timeout_sec", 90)
+	v.SetDefault("llm.http.disable_http2", false)
 
 	// Broker defaults
 	v.SetDefault("broker.provider", "paper")
@@ -187,12 +256,18 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("trading.daily_loss_limit_pct", 2.0)
 	v.SetDefault("trading.max_open_positions", 10)
 	v.SetDefault("trading.require_confirmation", true)
+	v.SetDefault("trading.approval_queue", false)
 	v.SetDefault("trading.confirm_timeout_sec", 60)
 	v.SetDefault("trading.initial_capital", 1000000) // ₹10 lakh default
+	v.SetDefault("trading.min_risk_reward", 0.0)
+	v.SetDefault("trading.min_trade_confidence", 0.0)
 
 	// Analysis defaults
 	v.SetDefault("analysis.cache_ttl", 300)          // 5 minutes
 	v.SetDefault("analysis.concurrent_fetches", 5)
+	v.SetDefault("analysis.agent_timeout_sec", 0)
+	v.SetDefault("analysis.min_confidence", 0.0)
+	v.SetDefault("analysis.debate_threshold", 0.0)
 
 	// FinanceQL defaults
 	v.SetDefault("financeql.cache_ttl", 60)           // 1 minute
@@ -204,6 +279,13 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("api.host", "0.0.0.0")
 	v.SetDefault("api.port", 8080)
 	v.SetDefault("api.cors_origins", []string{"http://localhost:3000"})
+	v.SetDefault("api.allowed_origins", []string{"http://localhost:3000"})
+	v.SetDefault("api.tradingview.ticker_field", "ticker")
+	v.SetDefault("api.tradingview.action_field", "action")
+	v.SetDefault("api.tradingview.quantity_field", "qty")
+	v.SetDefault("api.tradingview.exchange", "NSE")
+	v.SetDefault("api.tradingview.product", "CNC")
+	v.SetDefault("api.refresh_interval_sec", 30)
 
 	// Web defaults
 	v.SetDefault("web.url", "http://localhost:3000")
@@ -224,12 +306,24 @@ func overrideFromEnv(cfg *Config) {
 	if key := os.Getenv("OPENSEAI_LLM_ANTHROPIC_KEY"); key != "" {
 		cfg.LLM.AnthropicKey = key
 	}
+	if key := os.Getenv("OPENSEAI_LLM_GROK_KEY"); key != "" {
+		cfg.LLM.GrokKey = key
+	}
+	if key := os.Getenv("OPENSEAI_LLM_AZURE_API_KEY"); key != "" {
+		cfg.LLM.Azure.APIKey = key
+	}
 	if key := os.Getenv("OPENSEAI_BROKER_ZERODHA_API_KEY"); key != "" {
 		cfg.Broker.Zerodha.APIKey = key
 	}
 	if key := os.Getenv("OPENSEAI_BROKER_ZERODHA_API_SECRET"); key != "" {
 		cfg.Broker.Zerodha.APISecret = key
 	}
+	if key := os.Getenv("OPENSEAI_API_WEBHOOK_SECRET"); key != "" {
+		cfg.API.WebhookSecret = key
+	}
+	if key := os.Getenv("OPENSEAI_API_WS_TOKEN"); key != "" {
+		cfg.API.WSToken = key
+	}
 }
 
 // SaveToFile writes the current configuration to a YAML file.
@@ -261,7 +355,7 @@ func ConfigFilePath() string {
 	v.SetConfigName("config")
 	v.SetConfigType("yaml")
 	v.AddConfigPath("./config")
-	v.AddConfigPath(filepath.Join(homeDir(), ".openseai"))
+	v.AddConfigPath(Dir())
 	v.AddConfigPath("/etc/openseai")
 
 	if err := v.ReadInConfig(); err != nil {
@@ -270,6 +364,18 @@ func ConfigFilePath() string {
 	return v.ConfigFileUsed()
 }
 
+// Dir returns the per-user OpeNSE.ai directory, ~/.openseai, which holds
+// the user config file and on-disk caches.
+func Dir() string {
+	return filepath.Join(homeDir(), ".openseai")
+}
+
+// TradeJournalPath returns the default trade journal file, one JSON trade
+// event per line, under Dir.
+func TradeJournalPath() string {
+	return filepath.Join(Dir(), "trades.jsonl")
+}
+
 // homeDir returns the user's home directory.
 func homeDir() string {
 	home, err := os.UserHomeDir()
@@ -12,6 +12,7 @@ func TestLoadReturnsDefaults(t *testing.T) {
 	// Unset any env vars that would interfere
 	envVars := []string{
 		"OPENSEAI_LLM_OPENAI_KEY", "OPENSEAI_LLM_GEMINI_KEY", "OPENSEAI_LLM_ANTHROPIC_KEY",
+		"OPENSEAI_LLM_AZURE_API_KEY", "OPENSEAI_LLM_GROK_KEY",
 		"OPENSEAI_BROKER_ZERODHA_API_KEY", "OPENSEAI_BROKER_ZERODHA_API_SECRET",
 	}
 	for _, e := range envVars {
@@ -136,6 +137,12 @@ trading:
   mode: "paper"
   max_position_pct: 3.0
   initial_capital: 2000000
+  min_risk_reward: 2
+  min_trade_confidence: 0.65
+analysis:
+  agent_timeout_sec: 90
+  min_confidence: 0.6
+  debate_threshold: 1.2
 api:
   port: 9090
 logging:
@@ -179,6 +186,13 @@ logging:
 	if cfg.Trading.InitialCapital != 2000000 {
 		t.Errorf("Trading.InitialCapital: got %f, want 2000000", cfg.Trading.InitialCapital)
 	}
+	if cfg.Trading.MinRiskReward != 2 || cfg.Trading.MinTradeConfidence != 0.65 {
+		t.Errorf("Trading: got min risk:reward %f, min confidence %f; want 2, 0.65", cfg.Trading.MinRiskReward, cfg.Trading.MinTradeConfidence)
+	}
+	if cfg.Analysis.AgentTimeoutSec != 90 || cfg.Analysis.MinConfidence != 0.6 || cfg.Analysis.DebateThreshold != 1.2 {
+		t.Errorf("Analysis: got timeout %d, min confidence %f, debate threshold %f; want 90, 0.6, 1.2",
+			cfg.Analysis.AgentTimeoutSec, cfg.Analysis.MinConfidence, cfg.Analysis.DebateThreshold)
+	}
 	if cfg.API.Port != 9090 {
 		t.Errorf("API.Port: got %d, want 9090", cfg.API.Port)
 	}
@@ -298,6 +312,7 @@ func TestCheckAPIKeysAllEmpty(t *testing.T) {
 	// Clear env vars
 	envVars := []string{
 		"OPENSEAI_LLM_OPENAI_KEY", "OPENSEAI_LLM_GEMINI_KEY", "OPENSEAI_LLM_ANTHROPIC_KEY",
+		"OPENSEAI_LLM_AZURE_API_KEY",
 		"OPENSEAI_BROKER_ZERODHA_API_KEY", "OPENSEAI_BROKER_ZERODHA_API_SECRET",
 	}
 	for _, e := range envVars {
@@ -307,8 +322,8 @@ func TestCheckAPIKeysAllEmpty(t *testing.T) {
 	cfg := &Config{}
 	statuses := CheckAPIKeys(cfg)
 
-	if len(statuses) != 5 {
-		t.Fatalf("CheckAPIKeys: got %d statuses, want 5", len(statuses))
+	if len(statuses) != 7 {
+		t.Fatalf("CheckAPIKeys: got %d statuses, want 7", len(statuses))
 	}
 	for _, s := range statuses {
 		if s.IsSet {
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultComments documents the keys of a generated config file, by dotted
+// path. Section keys get a head comment; leaf keys a line comment.
+var defaultComments = map[string]string{
+	"llm":                              "LLM providers. Keys may also be set through the environment.",
+	"llm.primary":                      "openai | azure | ollama | gemini | anthropic | grok",
+	"llm.openai_key":                   "env: OPENSEAI_LLM_OPENAI_KEY",
+	"llm.ollama_url":                   "local Ollama server",
+	"llm.ollama_prompt_tools":          "describe tools in the prompt for Ollama models without native tool calling (detected automatically otherwise)",
+	"llm.gemini_key":                   "env: OPENSEAI_LLM_GEMINI_KEY",
+	"llm.anthropic_key":                "env: OPENSEAI_LLM_ANTHROPIC_KEY",
+	"llm.grok_key":                     "env: OPENSEAI_LLM_GROK_KEY",
+	"llm.azure":                        "Azure OpenAI deployment, used when primary is azure",
+	"llm.azure.endpoint":               "e.g. https://myresource.openai.azure.com",
+	"llm.azure.deployment":             "Azure deployment name",
+	"llm.azure.api_key":                "env: OPENSEAI_LLM_AZURE_API_KEY",
+	"llm.model":                        "or e.g. \"qwen2.5:32b\" for Ollama",
+	"llm.fallback_model":               "used when the primary model fails",
+	"llm.temperature":                  "0 = deterministic",
+	"llm.max_tokens":                   "per response",
+	"llm.http":                         "Connection pool shared by the provider HTTP clients.",
+	"llm.http.max_idle_conns_per_host": "raise for many concurrent agents on one provider",
+	"llm.http.idle_conn_timeout_sec":   "seconds an idle connection is kept open",
+	"llm.http.disable_http2":           "force HTTP/1.1",
+
+	"broker":                    "Order execution. Paper trading needs no credentials.",
+	"broker.provider":           "paper | zerodha | ibkr",
+	"broker.zerodha.api_key":    "env: OPENSEAI_BROKER_ZERODHA_API_KEY",
+	"broker.zerodha.api_secret": "env: OPENSEAI_BROKER_ZERODHA_API_SECRET",
+	"broker.ibkr.host":          "TWS / IB Gateway host",
+	"broker.ibkr.port":          "7497 = TWS paper, 7496 = TWS live",
+
+	"trading":                      "Trading limits, enforced before any order is placed.",
+	"trading.mode":                 "paper | live",
+	"trading.max_position_pct":     "max % of capital in one position",
+	"trading.daily_loss_limit_pct": "stop trading for the day after this % loss",
+	"trading.max_open_positions":   "positions held at once",
+	"trading.require_confirmation": "human-in-the-loop for live trades",
+	"trading.approval_queue":       "park API orders until approved via /api/v1/approvals",
+	"trading.confirm_timeout_sec":  "seconds to wait for a confirmation",
+	"trading.initial_capital":      "₹ of paper capital",
+	"trading.min_risk_reward":      "smallest reward:risk a trade plan may have, e.g. 2 for 1:2; 0 = no floor",
+	"trading.min_trade_confidence": "smallest analysis confidence (0–1) a trade plan may rest on; 0 = no floor",
+
+	"analysis":                    "Analysis engine.",
+	"analysis.cache_ttl":          "seconds to cache market data",
+	"analysis.concurrent_fetches": "parallel data fetches",
+	"analysis.agent_timeout_sec":  "seconds each specialist agent may run in multi-agent analysis, including its retry; 0 = no limit",
+	"analysis.min_confidence":     "re-prompt a specialist once when its confidence is below this (0–1); 0 = never",
+	"analysis.debate_threshold":   "ask the CIO to settle a bullish and a bearish specialist whose scores are at least this far apart (0–2); 0 = never",
+
+	"financeql":                      "FinanceQL query language.",
+	"financeql.cache_ttl":            "seconds to cache query results",
+	"financeql.max_range":            "longest range selector",
+	"financeql.alert_check_interval": "seconds between alert evaluations",
+
+	"datasource":              "Data source per category: yfinance | nse | nse_derivatives | screener. Empty keeps the default.",
+	"datasource.quotes":       "default: yfinance, falling back to nse",
+	"datasource.fundamentals": "default: screener",
+	"datasource.options":      "default: nse_derivatives",
+
+	"universes": "Named ticker lists for --universe and FinanceQL universe(\"name\"), e.g. it: [TCS, INFY, WIPRO]",
+	"lot_sizes": "F&O contract lot per underlying, e.g. RELIANCE: 500; overrides the bundled index lots",
+
+	"api":                          "HTTP API server (openseai serve).",
+	"api.cors_origins":             "origins allowed by CORS",
+	"api.webhook_secret":           "HMAC key for /api/v1/webhook/tradingview (env: OPENSEAI_API_WEBHOOK_SECRET); empty disables the webhook",
+	"api.tradingview":              "Maps a TradingView alert payload to a paper order; dotted paths allowed.",
+	"api.tradingview.action_field": "value must be \"buy\" or \"sell\"",
+	"api.tradingview.product":      "CNC | MIS",
+	"api.refresh_tickers":          "quotes refreshed in the background and pushed to WebSocket clients",
+	"api.refresh_interval_sec":     "seconds between refreshes",
+	"api.allowed_origins":          "browser origins allowed to open /api/v1/ws besides the server's own; \"*\" allows any",
+	"api.ws_token":                 "required on /api/v1/ws as ?token= or a \"token.<value>\" subprotocol (env: OPENSEAI_API_WS_TOKEN); empty disables",
+
+	"web":            "Next.js frontend.",
+	"logging":        "Logging.",
+	"logging.level":  "debug | info | warn | error",
+	"logging.format": "text | json",
+}
+
+// Defaults returns the configuration Load falls back to when no file or
+// environment variable sets a value.
+func Defaults() (*Config, error) {
+	v := viper.New()
+	setDefaults(v)
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("error unmarshaling defaults: %w", err)
+	}
+	return &cfg, nil
+}
+
+// DefaultYAML renders Defaults as a commented YAML config file.
+func DefaultYAML() ([]byte, error) {
+	cfg, err := Defaults()
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := doc.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to encode defaults: %w", err)
+	}
+	commentNode(&doc, "")
+	doc.HeadComment = "OpeNSE.ai configuration, generated by \"openseai config init\".\n" +
+		"Environment variables override these values: OPENSEAI_<SECTION>_<KEY>,\n" +
+		"e.g. OPENSEAI_LLM_OPENAI_KEY."
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal defaults: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// commentNode attaches defaultComments to the keys of a mapping node and
+// its nested mappings; prefix is the dotted path of node.
+func commentNode(node *yaml.Node, prefix string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, val := node.Content[i], node.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+		if val.Tag == "!!float" && strings.Contains(val.Value, "e+") {
+			// Write 1000000 rather than 1e+06.
+			if f, err := strconv.ParseFloat(val.Value, 64); err == nil && f == math.Trunc(f) {
+				val.Tag, val.Value = "!!int", strconv.FormatFloat(f, 'f', -1, 64)
+			}
+		}
+		if c := defaultComments[path]; c != "" {
+			switch {
+			case val.Kind == yaml.MappingNode:
+				key.HeadComment = c
+			case val.Kind == yaml.SequenceNode && len(val.Content) == 0:
+				val.LineComment = c // an empty flow sequence drops key comments
+			default:
+				key.LineComment = c
+			}
+		}
+		commentNode(val, path)
+	}
+}
+
+// WriteDefault writes DefaultYAML to path, creating its directory. An
+// existing file is only replaced when force is set.
+func WriteDefault(path string, force bool) error {
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("config file %s already exists (use --force to overwrite)", path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	data, err := DefaultYAML()
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("cannot create config directory %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+	return nil
+}
@@ -25,6 +25,8 @@ func CheckAPIKeys(cfg *Config) []KeyStatus {
 		checkKey("OpenAI API Key", cfg.LLM.OpenAIKey, "OPENSEAI_LLM_OPENAI_KEY"),
 		checkKey("Gemini API Key", cfg.LLM.GeminiKey, "OPENSEAI_LLM_GEMINI_KEY"),
 		checkKey("Anthropic API Key", cfg.LLM.AnthropicKey, "OPENSEAI_LLM_ANTHROPIC_KEY"),
+		checkKey("xAI Grok API Key", cfg.LLM.GrokKey, "OPENSEAI_LLM_GROK_KEY"),
+		checkKey("Azure OpenAI API Key", cfg.LLM.Azure.APIKey, "OPENSEAI_LLM_AZURE_API_KEY"),
 		checkKey("Zerodha API Key", cfg.Broker.Zerodha.APIKey, "OPENSEAI_BROKER_ZERODHA_API_KEY"),
 		checkKey("Zerodha API Secret", cfg.Broker.Zerodha.APISecret, "OPENSEAI_BROKER_ZERODHA_API_SECRET"),
 	}
@@ -21,19 +21,37 @@ type Aggregator struct {
 	screener    *Screener
 	news        *News
 	fiidii      *FIIDII
+	events      CorporateEventSource
+	history     []HistoryFetcher
+	cache       *HistoryCache
+	named       map[string]DataSource       // selectable by name, see RegisterSource
+	routes      map[DataCategory]DataSource // chosen source per category, see UseSource
+
+	noTimeframeFallback bool
 }
 
 // NewAggregator creates a new data source aggregator with all default sources.
 func NewAggregator() *Aggregator {
 	nse := NewNSE()
-	return &Aggregator{
-		yfinance:    NewYFinance(),
+	yf := NewYFinance()
+	a := &Aggregator{
+		yfinance:    yf,
 		nse:         nse,
 		derivatives: NewNSEDerivatives(nse),
 		screener:    NewScreener(),
 		news:        NewNews(),
 		fiidii:      NewFIIDII(nse),
+		events:      nse,
+		history:     []HistoryFetcher{yf, nse},
+		routes:      make(map[DataCategory]DataSource),
+	}
+	a.named = map[string]DataSource{
+		"yfinance":        a.yfinance,
+		"nse":             a.nse,
+		"nse_derivatives": a.derivatives,
+		"screener":        a.screener,
 	}
+	return a
 }
 
 // Sources returns all registered data sources.
@@ -84,13 +102,9 @@ func (a *Aggregator) FetchProfile(ctx context.Context, ticker string) (*models.S
 
 	g, gctx := errgroup.WithContext(ctx)
 
-	// 1. Quote from Yahoo Finance (primary) with NSE as fallback.
+	// 1. Quote from the quotes source (see GetQuote).
 	g.Go(func() error {
-		quote, err := a.yfinance.GetQuote(gctx, symbol)
-		if err != nil {
-			// Try NSE as fallback.
-			quote, err = a.nse.GetQuote(gctx, symbol)
-		}
+		quote, err := a.GetQuote(gctx, symbol)
 		if err != nil {
 			mu.Lock()
 			errs = append(errs, fmt.Errorf("quote: %w", err))
@@ -105,9 +119,10 @@ func (a *Aggregator) FetchProfile(ctx context.Context, ticker string) (*models.S
 		return nil
 	})
 
-	// 2. Financials from Screener.in.
+	// 2. Financials from the fundamentals source (default Screener.in).
+	fundamentals := a.fundamentalsSource()
 	g.Go(func() error {
-		fd, err := a.screener.GetFinancials(gctx, symbol)
+		fd, err := fundamentals.GetFinancials(gctx, symbol)
 		if err != nil {
 			mu.Lock()
 			errs = append(errs, fmt.Errorf("financials: %w", err))
@@ -120,9 +135,13 @@ func (a *Aggregator) FetchProfile(ctx context.Context, ticker string) (*models.S
 		return nil
 	})
 
-	// 3. Financial ratios from Screener.in.
+	// 3. Financial ratios, if the fundamentals source serves them.
 	g.Go(func() error {
-		ratios, err := a.screener.GetFinancialRatios(gctx, symbol)
+		rf, ok := fundamentals.(ratiosFetcher)
+		if !ok {
+			return nil
+		}
+		ratios, err := rf.GetFinancialRatios(gctx, symbol)
 		if err != nil {
 			mu.Lock()
 			errs = append(errs, fmt.Errorf("ratios: %w", err))
@@ -163,25 +182,21 @@ func (a *Aggregator) FetchProfile(ctx context.Context, ticker string) (*models.S
 	return profile, nil
 }
 
-// FetchHistoricalData fetches OHLCV data, trying Yahoo Finance first, then NSE.
+// FetchHistoricalData fetches OHLCV data, trying Yahoo Finance first, then
+// NSE. If tf is unavailable it may fall back to another granularity; use
+// FetchHistory to see whether it did.
 func (a *Aggregator) FetchHistoricalData(ctx context.Context, ticker string, from, to time.Time, tf models.Timeframe) ([]models.OHLCV, error) {
-	// Try YFinance first (better historical data coverage).
-	candles, err := a.yfinance.GetHistoricalData(ctx, ticker, from, to, tf)
-	if err == nil && len(candles) > 0 {
-		return candles, nil
-	}
-
-	// Fallback to NSE.
-	candles, err = a.nse.GetHistoricalData(ctx, ticker, from, to, tf)
+	data, err := a.FetchHistory(ctx, ticker, from, to, tf)
 	if err != nil {
-		return nil, fmt.Errorf("historical data unavailable for %s: %w", ticker, err)
+		return nil, err
 	}
-	return candles, nil
+	return data.Candles, nil
 }
 
-// FetchOptionChain fetches the option chain from NSE derivatives.
+// FetchOptionChain fetches the option chain from the options source
+// (default NSE derivatives).
 func (a *Aggregator) FetchOptionChain(ctx context.Context, ticker string, expiry string) (*models.OptionChain, error) {
-	return a.derivatives.GetOptionChain(ctx, ticker, expiry)
+	return a.optionsSource().GetOptionChain(ctx, ticker, expiry)
 }
 
 // FetchMarketOverview returns a market overview with indices, VIX, and FII/DII data.
@@ -248,6 +263,11 @@ func (a *Aggregator) FetchMarketOverview(ctx context.Context) (*MarketOverview,
 	return overview, nil
 }
 
+// FetchFIIDIIActivity returns the latest FII/DII cash market activity.
+func (a *Aggregator) FetchFIIDIIActivity(ctx context.Context) (*models.FIIDIIData, error) {
+	return a.fiidii.GetFIIDIIActivity(ctx)
+}
+
 // FetchStockNews returns recent news for a ticker.
 func (a *Aggregator) FetchStockNews(ctx context.Context, ticker string, limit int) ([]models.NewsArticle, error) {
 	return a.news.GetStockNews(ctx, ticker, limit)
@@ -255,9 +275,9 @@ func (a *Aggregator) FetchStockNews(ctx context.Context, ticker string, limit in
 
 // MarketOverview holds a snapshot of the overall market state.
 type MarketOverview struct {
-	Nifty50   *models.Quote     `json:"nifty50,omitempty"`
-	BankNifty *models.Quote     `json:"bank_nifty,omitempty"`
-	IndiaVIX  *models.IndiaVIX  `json:"india_vix,omitempty"`
+	Nifty50   *models.Quote      `json:"nifty50,omitempty"`
+	BankNifty *models.Quote      `json:"bank_nifty,omitempty"`
+	IndiaVIX  *models.IndiaVIX   `json:"india_vix,omitempty"`
 	FIIDII    *models.FIIDIIData `json:"fii_dii,omitempty"`
 	FetchedAt time.Time          `json:"fetched_at"`
 }
@@ -0,0 +1,106 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
+)
+
+// Bundle field names used as keys in StockBundle.Errors.
+const (
+	BundleQuote      = "quote"
+	BundleProfile    = "profile"
+	BundleFinancials = "financials"
+)
+
+// StockBundle holds the quote, profile, and latest financials for one ticker.
+// Fields that could not be fetched are nil and have an entry in Errors.
+type StockBundle struct {
+	Ticker     string                `json:"ticker"`
+	Quote      *models.Quote         `json:"quote,omitempty"`
+	Profile    *models.StockProfile  `json:"profile,omitempty"`
+	Financials *models.FinancialData `json:"financials,omitempty"`
+	Errors     map[string]error      `json:"-"` // per-field fetch errors, keyed by Bundle* name
+	FetchedAt  time.Time             `json:"fetched_at"`
+}
+
+// bundleSources selects the data source used for each bundle field.
+type bundleSources struct {
+	quote      QuoteFetcher
+	profile    DataSource
+	financials DataSource
+}
+
+// FetchBundle fetches the quote, profile, and latest financials for ticker
+// concurrently. A failing field does not fail the others; its error is
+// recorded in StockBundle.Errors. An error is returned only if every field failed.
+func (a *Aggregator) FetchBundle(ctx context.Context, ticker string) (*StockBundle, error) {
+	return fetchBundle(ctx, bundleSources{
+		quote:      a,
+		profile:    a.nse,
+		financials: a.fundamentalsSource(),
+	}, ticker)
+}
+
+func fetchBundle(ctx context.Context, src bundleSources, ticker string) (*StockBundle, error) {
+	symbol := utils.NormalizeTicker(ticker)
+	b := &StockBundle{
+		Ticker:    symbol,
+		Errors:    make(map[string]error),
+		FetchedAt: utils.NowIST(),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	record := func(field string, err error) {
+		mu.Lock()
+		b.Errors[field] = err
+		mu.Unlock()
+	}
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		q, err := src.quote.GetQuote(ctx, symbol)
+		if err != nil {
+			record(BundleQuote, err)
+			return
+		}
+		mu.Lock()
+		b.Quote = q
+		mu.Unlock()
+	}()
+	go func() {
+		defer wg.Done()
+		p, err := src.profile.GetStockProfile(ctx, symbol)
+		if err != nil {
+			record(BundleProfile, err)
+			return
+		}
+		mu.Lock()
+		b.Profile = p
+		mu.Unlock()
+	}()
+	go func() {
+		defer wg.Done()
+		fd, err := src.financials.GetFinancials(ctx, symbol)
+		if err != nil {
+			record(BundleFinancials, err)
+			return
+		}
+		mu.Lock()
+		b.Financials = fd
+		mu.Unlock()
+	}()
+	wg.Wait()
+
+	if b.Quote == nil && b.Profile == nil && b.Financials == nil {
+		return b, fmt.Errorf("all bundle fields failed for %s: quote: %v; profile: %v; financials: %v",
+			symbol, b.Errors[BundleQuote], b.Errors[BundleProfile], b.Errors[BundleFinancials])
+	}
+	return b, nil
+}
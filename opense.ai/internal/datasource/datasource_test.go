@@ -2,8 +2,15 @@ package datasource
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
 )
 
 func TestCacheSetGet(t *testing.T) {
@@ -188,3 +195,476 @@ func TestParseScreenerNumber(t *testing.T) {
 		}
 	}
 }
+
+// fakeSource implements DataSource with canned data; a non-nil err fails every call.
+type fakeSource struct {
+	err error
+}
+
+var _ DataSource = (*fakeSource)(nil)
+
+func (f *fakeSource) Name() string { return "fake" }
+
+func (f *fakeSource) GetQuote(_ context.Context, ticker string) (*models.Quote, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.Quote{Ticker: ticker, LastPrice: 100}, nil
+}
+
+func (f *fakeSource) GetHistoricalData(_ context.Context, _ string, _, _ time.Time, _ models.Timeframe) ([]models.OHLCV, error) {
+	return nil, ErrNotSupported
+}
+
+func (f *fakeSource) GetFinancials(_ context.Context, ticker string) (*models.FinancialData, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.FinancialData{Ticker: ticker}, nil
+}
+
+func (f *fakeSource) GetOptionChain(_ context.Context, _ string, _ string) (*models.OptionChain, error) {
+	return nil, ErrNotSupported
+}
+
+func (f *fakeSource) GetStockProfile(_ context.Context, ticker string) (*models.StockProfile, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.StockProfile{Stock: models.Stock{Ticker: ticker}}, nil
+}
+
+func TestFetchBundlePartialFailure(t *testing.T) {
+	errProfile := errors.New("profile down")
+	b, err := fetchBundle(context.Background(), bundleSources{
+		quote:      &fakeSource{},
+		profile:    &fakeSource{err: errProfile},
+		financials: &fakeSource{},
+	}, "reliance")
+	if err != nil {
+		t.Fatalf("partial failure should not error: %v", err)
+	}
+	if b.Ticker != "RELIANCE" {
+		t.Errorf("ticker: got %q", b.Ticker)
+	}
+	if b.Quote == nil || b.Quote.LastPrice != 100 {
+		t.Errorf("expected quote to populate, got %+v", b.Quote)
+	}
+	if b.Financials == nil {
+		t.Error("expected financials to populate")
+	}
+	if b.Profile != nil {
+		t.Error("expected profile to be nil")
+	}
+	if !errors.Is(b.Errors[BundleProfile], errProfile) {
+		t.Errorf("expected profile error recorded, got %v", b.Errors)
+	}
+	if len(b.Errors) != 1 {
+		t.Errorf("expected exactly one field error, got %v", b.Errors)
+	}
+}
+
+func TestFetchBundleAllFail(t *testing.T) {
+	down := &fakeSource{err: errors.New("down")}
+	_, err := fetchBundle(context.Background(), bundleSources{
+		quote: down, profile: down, financials: down,
+	}, "TCS")
+	if err == nil {
+		t.Fatal("expected error when every field fails")
+	}
+}
+
+func TestClassifyCorporateAction(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    models.CorporateEventType
+		ok      bool
+	}{
+		{"Interim Dividend - Rs 10 Per Share", models.EventDividend, true},
+		{"Face Value Split (Sub-Division) - From Rs 10/- Per Share To Rs 1/- Per Share", models.EventSplit, true},
+		{"Bonus 1:1", models.EventBonus, true},
+		{"Annual General Meeting", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := classifyCorporateAction(tt.subject)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("classifyCorporateAction(%q) = %q, %v; want %q, %v", tt.subject, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCheckSources(t *testing.T) {
+	results := checkSources(context.Background(), []sourceProbe{
+		{"up", func(context.Context) error { return nil }},
+		{"down", func(context.Context) error { return errors.New("timeout") }},
+	})
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Name != "up" || !results[0].OK || results[0].Error != "" {
+		t.Errorf("unexpected healthy result: %+v", results[0])
+	}
+	if results[1].Name != "down" || results[1].OK || results[1].Error != "timeout" {
+		t.Errorf("unexpected failing result: %+v", results[1])
+	}
+}
+
+// dailyOnlySource serves daily candles and rejects every other timeframe.
+type dailyOnlySource struct {
+	bars []models.OHLCV
+}
+
+func (d *dailyOnlySource) GetHistoricalData(_ context.Context, _ string, _, _ time.Time, tf models.Timeframe) ([]models.OHLCV, error) {
+	if tf != models.Timeframe1Day {
+		return nil, ErrNotSupported
+	}
+	return d.bars, nil
+}
+
+func TestFetchHistoryTimeframeFallback(t *testing.T) {
+	// Mon 2025-09-01 through Fri 2025-09-12: two trading weeks.
+	var bars []models.OHLCV
+	for d := 1; d <= 12; d++ {
+		ts := time.Date(2025, 9, d, 0, 0, 0, 0, utils.IST)
+		if ts.Weekday() == time.Saturday || ts.Weekday() == time.Sunday {
+			continue
+		}
+		p := float64(100 + d)
+		bars = append(bars, models.OHLCV{Timestamp: ts, Open: p, High: p + 1, Low: p - 1, Close: p + 0.5, Volume: 1000})
+	}
+	src := &dailyOnlySource{bars: bars}
+	agg := NewAggregator()
+	agg.SetHistorySources(src)
+	from, to := bars[0].Timestamp, bars[len(bars)-1].Timestamp
+
+	data, err := agg.FetchHistory(context.Background(), "TCS", from, to, models.Timeframe1Hour)
+	if err != nil {
+		t.Fatalf("FetchHistory 1h: %v", err)
+	}
+	if !data.Fallback || data.Warning == "" {
+		t.Errorf("expected fallback flag and warning, got %+v", data)
+	}
+	if data.Requested != models.Timeframe1Hour || data.Timeframe != models.Timeframe1Day || data.Source != models.Timeframe1Day {
+		t.Errorf("timeframes: requested %s, served %s, source %s", data.Requested, data.Timeframe, data.Source)
+	}
+	if len(data.Candles) != len(bars) {
+		t.Errorf("expected %d daily candles, got %d", len(bars), len(data.Candles))
+	}
+
+	// A weekly request is resampled from daily candles.
+	data, err = agg.FetchHistory(context.Background(), "TCS", from, to, models.Timeframe1Week)
+	if err != nil {
+		t.Fatalf("FetchHistory 1w: %v", err)
+	}
+	if !data.Fallback || data.Timeframe != models.Timeframe1Week || len(data.Candles) != 2 {
+		t.Fatalf("expected 2 weekly candles from fallback, got %+v", data)
+	}
+	week := data.Candles[0]
+	if week.Open != 101 || week.Close != 105.5 || week.High != 106 || week.Low != 100 || week.Volume != 5000 {
+		t.Errorf("unexpected first weekly candle: %+v", week)
+	}
+
+	// With fallback disabled the request fails.
+	agg.SetTimeframeFallback(false)
+	if _, err := agg.FetchHistory(context.Background(), "TCS", from, to, models.Timeframe1Hour); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported without fallback, got %v", err)
+	}
+}
+
+// countingSource serves fixed daily candles and counts its calls.
+type countingSource struct {
+	bars  []models.OHLCV
+	calls int
+}
+
+func (c *countingSource) GetHistoricalData(_ context.Context, _ string, _, _ time.Time, _ models.Timeframe) ([]models.OHLCV, error) {
+	c.calls++
+	return c.bars, nil
+}
+
+func TestFetchHistoryDiskCache(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, utils.IST)
+	to := time.Date(2024, 3, 29, 0, 0, 0, 0, utils.IST)
+	src := &countingSource{bars: []models.OHLCV{
+		{Timestamp: from, Open: 100, High: 101, Low: 99, Close: 100.5, Volume: 1000},
+		{Timestamp: to, Open: 110, High: 111, Low: 109, Close: 110.5, Volume: 2000},
+	}}
+	dir := t.TempDir()
+
+	newAgg := func(refresh bool) *Aggregator {
+		cache := NewHistoryCache(dir)
+		cache.SetRefresh(refresh)
+		agg := NewAggregator()
+		agg.SetHistorySources(src)
+		agg.SetHistoryCache(cache)
+		return agg
+	}
+
+	ctx := context.Background()
+	if _, err := newAgg(false).FetchHistoricalData(ctx, "TCS", from, to, models.Timeframe1Day); err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	// A fresh aggregator sharing the directory reads the range from disk.
+	bars, err := newAgg(false).FetchHistoricalData(ctx, "TCS", from, to, models.Timeframe1Day)
+	if err != nil {
+		t.Fatalf("second fetch: %v", err)
+	}
+	if src.calls != 1 {
+		t.Errorf("source called %d times, want 1", src.calls)
+	}
+	if len(bars) != 2 || bars[1].Close != 110.5 || bars[1].Volume != 2000 {
+		t.Errorf("unexpected cached bars: %+v", bars)
+	}
+
+	if _, err := newAgg(true).FetchHistoricalData(ctx, "TCS", from, to, models.Timeframe1Day); err != nil {
+		t.Fatalf("refresh fetch: %v", err)
+	}
+	if src.calls != 2 {
+		t.Errorf("refresh: source called %d times, want 2", src.calls)
+	}
+}
+
+// tickerCountingSource serves fixed candles and counts its calls per
+// ticker; it is safe for concurrent use.
+type tickerCountingSource struct {
+	mu    sync.Mutex
+	bars  []models.OHLCV
+	calls map[string]int
+}
+
+func (c *tickerCountingSource) GetHistoricalData(_ context.Context, ticker string, _, _ time.Time, _ models.Timeframe) ([]models.OHLCV, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[ticker]++
+	return c.bars, nil
+}
+
+func TestDownloadHistory(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, utils.IST)
+	to := time.Date(2024, 3, 29, 0, 0, 0, 0, utils.IST)
+	src := &tickerCountingSource{calls: map[string]int{}, bars: []models.OHLCV{
+		{Timestamp: from, Close: 100},
+		{Timestamp: to, Close: 110},
+	}}
+	dir := t.TempDir()
+	newAgg := func() *Aggregator {
+		agg := NewAggregator()
+		agg.SetHistorySources(src)
+		agg.SetHistoryCache(NewHistoryCache(dir))
+		return agg
+	}
+	tickers := []string{"TCS", "INFY", "RELIANCE", "HDFCBANK", "ITC"}
+
+	ctx := context.Background()
+	progress := 0
+	opts := DownloadOptions{Concurrency: 2, Progress: func(done, total int, _ DownloadResult) {
+		progress++
+		if done != progress || total != len(tickers) {
+			t.Errorf("progress(%d, %d) on call %d", done, total, progress)
+		}
+	}}
+	results, err := newAgg().DownloadHistory(ctx, tickers, from, to, models.Timeframe1Day, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress != len(tickers) {
+		t.Errorf("progress called %d times, want %d", progress, len(tickers))
+	}
+	for i, r := range results {
+		if r.Ticker != tickers[i] || r.Err != nil || r.Cached || r.Bars != 2 || r.Attempts != 1 {
+			t.Errorf("first run result %d: %+v", i, r)
+		}
+		if src.calls[tickers[i]] != 1 {
+			t.Errorf("%s fetched %d times, want 1", tickers[i], src.calls[tickers[i]])
+		}
+	}
+
+	// A rerun finds every range cached and fetches nothing.
+	opts.Progress = nil
+	results, err = newAgg().DownloadHistory(ctx, tickers, from, to, models.Timeframe1Day, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		if !r.Cached || r.Attempts != 0 || r.Bars != 2 {
+			t.Errorf("second run result %d: %+v", i, r)
+		}
+	}
+	total := 0
+	for _, n := range src.calls {
+		total += n
+	}
+	if total != len(tickers) {
+		t.Errorf("source called %d times over both runs, want %d", total, len(tickers))
+	}
+
+	if _, err := NewAggregator().DownloadHistory(ctx, tickers, from, to, models.Timeframe1Day, opts); err == nil {
+		t.Error("expected an error without a history cache")
+	}
+}
+
+func TestHistoryCacheFreshness(t *testing.T) {
+	now := time.Date(2024, 6, 14, 12, 0, 0, 0, utils.IST)
+	cache := NewHistoryCache(t.TempDir())
+	cache.now = func() time.Time { return now }
+	data := &HistoricalData{Candles: []models.OHLCV{{Close: 1}}, Requested: models.Timeframe1Day}
+
+	past := now.AddDate(0, 0, -1)
+	from := now.AddDate(0, -1, 0)
+	if err := cache.Put("TCS", from, past, models.Timeframe1Day, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put("TCS", from, now, models.Timeframe1Day, data); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, ok := cache.Get("TCS", from, past, models.Timeframe1Day); !ok {
+		t.Error("a range ending before today should never expire")
+	}
+	if _, ok := cache.Get("TCS", from, now, models.Timeframe1Day); ok {
+		t.Error("a range reaching today should expire after the TTL")
+	}
+}
+
+func TestHistoryCacheServesSubRanges(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, utils.IST) }
+	src := &countingSource{}
+	for d := 1; d <= 20; d++ {
+		src.bars = append(src.bars, models.OHLCV{Timestamp: day(d), Close: float64(d)})
+	}
+	cache := NewHistoryCache(t.TempDir())
+	agg := NewAggregator()
+	agg.SetHistorySources(src)
+	agg.SetHistoryCache(cache)
+
+	ctx := context.Background()
+	if _, err := agg.FetchHistoricalData(ctx, "TCS", day(1), day(20), models.Timeframe1Day); err != nil {
+		t.Fatal(err)
+	}
+	bars, err := agg.FetchHistoricalData(ctx, "TCS", day(5), day(9), models.Timeframe1Day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.calls != 1 {
+		t.Errorf("source called %d times, want 1", src.calls)
+	}
+	if len(bars) != 5 || bars[0].Close != 5 || bars[4].Close != 9 {
+		t.Errorf("sub-range bars = %+v, want days 5-9", bars)
+	}
+
+	// An overlapping range extends the stored coverage.
+	later := &HistoricalData{Requested: models.Timeframe1Day, Timeframe: models.Timeframe1Day, Source: models.Timeframe1Day}
+	for d := 15; d <= 25; d++ {
+		later.Candles = append(later.Candles, models.OHLCV{Timestamp: day(d), Close: float64(d)})
+	}
+	if err := cache.Put("TCS", day(15), day(25), models.Timeframe1Day, later); err != nil {
+		t.Fatal(err)
+	}
+	data, ok := cache.Get("TCS", day(1), day(25), models.Timeframe1Day)
+	if !ok {
+		t.Fatal("expected the merged range to be cached")
+	}
+	if len(data.Candles) != 25 || data.Candles[24].Close != 25 {
+		t.Errorf("merged range has %d bars, want 25", len(data.Candles))
+	}
+	if _, ok := cache.Get("TCS", day(1), day(26), models.Timeframe1Day); ok {
+		t.Error("a range past the stored coverage should miss")
+	}
+}
+
+func TestWinsorizeBars(t *testing.T) {
+	var bars []models.OHLCV
+	for i := 0; i < 40; i++ {
+		c := 100 + 2*math.Sin(float64(i)/3)
+		bars = append(bars, models.OHLCV{Open: c - 0.5, High: c + 1, Low: c - 1, Close: c, Volume: 1000})
+	}
+	bars[20] = models.OHLCV{Open: bars[20].Open, High: 1000, Low: bars[20].Low, Close: 1000, Volume: 1000}
+
+	got := WinsorizeBars(bars, 4)
+	spike := got[20]
+	if spike.Close > 120 || spike.High > 120 {
+		t.Errorf("expected spike clamped near the rolling median, got %+v", spike)
+	}
+	if spike.High < spike.Close || spike.Low > spike.Open {
+		t.Errorf("clamped bar is not a valid candle: %+v", spike)
+	}
+	for i := range bars {
+		if i != 20 && got[i] != bars[i] {
+			t.Errorf("bar %d changed: %+v → %+v", i, bars[i], got[i])
+		}
+	}
+	if bars[20].Close != 1000 {
+		t.Error("WinsorizeBars modified its input")
+	}
+}
+
+// namedFakeSource is a fakeSource that records the calls it serves.
+type namedFakeSource struct {
+	fakeSource
+	name  string
+	mu    sync.Mutex
+	calls []string
+}
+
+func (f *namedFakeSource) record(method string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, method)
+}
+
+func (f *namedFakeSource) served() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func (f *namedFakeSource) Name() string { return f.name }
+
+func (f *namedFakeSource) GetQuote(ctx context.Context, ticker string) (*models.Quote, error) {
+	f.record("quote")
+	return f.fakeSource.GetQuote(ctx, ticker)
+}
+
+func (f *namedFakeSource) GetFinancials(ctx context.Context, ticker string) (*models.FinancialData, error) {
+	f.record("financials")
+	return f.fakeSource.GetFinancials(ctx, ticker)
+}
+
+func TestAggregatorSelectSources(t *testing.T) {
+	a, b := &namedFakeSource{name: "A"}, &namedFakeSource{name: "B"}
+	agg := NewAggregator()
+	agg.RegisterSource("source_a", a)
+	agg.RegisterSource("source_b", b)
+
+	if err := agg.SelectSources(SourceSelection{Quotes: "source_a", Fundamentals: "Source_B"}); err != nil {
+		t.Fatalf("SelectSources: %v", err)
+	}
+
+	if _, err := agg.GetQuote(context.Background(), "TCS"); err != nil {
+		t.Fatalf("GetQuote: %v", err)
+	}
+	// A cancelled context keeps the NSE shareholding fetch offline; the
+	// fakes ignore it.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	profile, err := agg.FetchProfile(ctx, "TCS")
+	if err != nil {
+		t.Fatalf("FetchProfile: %v", err)
+	}
+	if profile.Quote == nil || profile.Financials == nil {
+		t.Fatalf("expected quote and financials, got %+v", profile)
+	}
+
+	if got := a.served(); len(got) != 2 || got[0] != "quote" || got[1] != "quote" {
+		t.Errorf("source A served %v, want two quotes", got)
+	}
+	if got := b.served(); len(got) != 1 || got[0] != "financials" {
+		t.Errorf("source B served %v, want financials only", got)
+	}
+
+	err = agg.UseSource(CategoryOptions, "bloomberg")
+	if err == nil || !strings.Contains(err.Error(), "source_a") {
+		t.Errorf("expected unknown source error listing sources, got %v", err)
+	}
+}
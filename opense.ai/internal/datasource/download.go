@@ -0,0 +1,122 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// Defaults for DownloadOptions fields left at zero.
+const (
+	defaultDownloadConcurrency = 4
+	defaultDownloadRetries     = 2
+	defaultDownloadRetryDelay  = 2 * time.Second
+)
+
+// DownloadOptions tunes Aggregator.DownloadHistory.
+type DownloadOptions struct {
+	Concurrency int           // tickers fetched at once; zero means 4
+	Retries     int           // further attempts after a failed fetch; zero means 2, negative means none
+	RetryDelay  time.Duration // wait before the first retry, doubled for each one after; zero means 2s
+
+	// Progress, if set, is called once per ticker as it finishes. Calls
+	// are serialized, and done counts the tickers finished so far.
+	Progress func(done, total int, r DownloadResult)
+}
+
+// DownloadResult is the outcome of downloading one ticker's history.
+type DownloadResult struct {
+	Ticker   string
+	Bars     int
+	Cached   bool // the range was already cached, so nothing was fetched
+	Attempts int  // fetches made, including retries
+	Err      error
+}
+
+// DownloadHistory fills the history cache with the [from, to] range of tf
+// for each ticker, fetching up to opts.Concurrency tickers at a time and
+// retrying failed fetches. Ranges already in the cache are skipped, so an
+// interrupted download can be rerun to fetch only what is missing. Results
+// are in the order of tickers; a ticker that still fails after its retries
+// has Err set and does not stop the others. It fails outright only when no
+// history cache is set.
+func (a *Aggregator) DownloadHistory(ctx context.Context, tickers []string, from, to time.Time, tf models.Timeframe, opts DownloadOptions) ([]DownloadResult, error) {
+	if a.cache == nil {
+		return nil, fmt.Errorf("download needs a history cache")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultDownloadConcurrency
+	}
+	if opts.Retries == 0 {
+		opts.Retries = defaultDownloadRetries
+	} else if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = defaultDownloadRetryDelay
+	}
+
+	results := make([]DownloadResult, len(tickers))
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+		sem  = make(chan struct{}, opts.Concurrency)
+	)
+	for i, ticker := range tickers {
+		wg.Add(1)
+		go func(i int, ticker string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				results[i] = a.downloadTicker(ctx, ticker, from, to, tf, opts)
+				<-sem
+			case <-ctx.Done():
+				results[i] = DownloadResult{Ticker: ticker, Err: ctx.Err()}
+			}
+			if opts.Progress != nil {
+				mu.Lock()
+				done++
+				opts.Progress(done, len(tickers), results[i])
+				mu.Unlock()
+			}
+		}(i, ticker)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// downloadTicker caches one ticker's range unless it is cached already.
+func (a *Aggregator) downloadTicker(ctx context.Context, ticker string, from, to time.Time, tf models.Timeframe, opts DownloadOptions) DownloadResult {
+	res := DownloadResult{Ticker: ticker}
+	if data, ok := a.cache.Get(ticker, from, to, tf); ok {
+		res.Cached, res.Bars = true, len(data.Candles)
+		return res
+	}
+
+	delay := opts.RetryDelay
+	for {
+		res.Attempts++
+		data, err := a.fetchHistoryFallback(ctx, ticker, from, to, tf)
+		if err == nil {
+			res.Bars = len(data.Candles)
+			if err := a.cache.Put(ticker, from, to, tf, data); err != nil {
+				res.Err = fmt.Errorf("cache %s: %w", ticker, err)
+			}
+			return res
+		}
+		res.Err = err
+		if res.Attempts > opts.Retries || ctx.Err() != nil {
+			return res
+		}
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return res
+		}
+	}
+}
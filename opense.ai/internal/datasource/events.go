@@ -0,0 +1,162 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
+)
+
+// CorporateEventSource is implemented by sources that provide corporate
+// events (results dates, dividends, splits, bonuses) for a ticker.
+type CorporateEventSource interface {
+	GetCorporateEvents(ctx context.Context, ticker string) ([]models.CorporateEvent, error)
+}
+
+// nseCorporateAction is one row of NSE's corporate actions feed.
+type nseCorporateAction struct {
+	Subject string `json:"subject"`
+	ExDate  string `json:"exDate"`
+}
+
+// nseBoardMeeting is one row of NSE's board meetings feed.
+type nseBoardMeeting struct {
+	Purpose string `json:"bm_purpose"`
+	Date    string `json:"bm_date"`
+	Desc    string `json:"bm_desc"`
+}
+
+// GetCorporateEvents returns results board meetings and dividend, split,
+// and bonus ex-dates for the given ticker.
+func (n *NSE) GetCorporateEvents(ctx context.Context, ticker string) ([]models.CorporateEvent, error) {
+	symbol := utils.NormalizeTicker(ticker)
+
+	cacheKey := "nse:events:" + symbol
+	if cached, ok := n.cache.Get(cacheKey); ok {
+		return cached.([]models.CorporateEvent), nil
+	}
+
+	if err := n.ensureCookies(ctx); err != nil {
+		return nil, err
+	}
+
+	var events []models.CorporateEvent
+
+	if err := n.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/corporates-corporateActions?index=equities&symbol=%s", nseAPIBase, symbol)
+	data, err := n.nseGet(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("NSE corporate actions %s: %w", symbol, err)
+	}
+	var actions []nseCorporateAction
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return nil, fmt.Errorf("parse NSE corporate actions: %w", err)
+	}
+	for _, a := range actions {
+		typ, ok := classifyCorporateAction(a.Subject)
+		if !ok {
+			continue
+		}
+		date, err := time.ParseInLocation("02-Jan-2006", a.ExDate, utils.IST)
+		if err != nil {
+			continue
+		}
+		events = append(events, models.CorporateEvent{
+			Ticker:      symbol,
+			Type:        typ,
+			Date:        date,
+			Description: a.Subject,
+		})
+	}
+
+	if err := n.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	url = fmt.Sprintf("%s/corporate-board-meetings?index=equities&symbol=%s", nseAPIBase, symbol)
+	data, err = n.nseGet(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("NSE board meetings %s: %w", symbol, err)
+	}
+	var meetings []nseBoardMeeting
+	if err := json.Unmarshal(data, &meetings); err != nil {
+		return nil, fmt.Errorf("parse NSE board meetings: %w", err)
+	}
+	for _, m := range meetings {
+		if !strings.Contains(strings.ToLower(m.Purpose), "financial result") {
+			continue
+		}
+		date, err := time.ParseInLocation("02-Jan-2006", m.Date, utils.IST)
+		if err != nil {
+			continue
+		}
+		events = append(events, models.CorporateEvent{
+			Ticker:      symbol,
+			Type:        models.EventEarnings,
+			Date:        date,
+			Description: m.Purpose,
+		})
+	}
+
+	n.cache.SetWithTTL(cacheKey, events, 6*time.Hour)
+	return events, nil
+}
+
+// classifyCorporateAction maps an NSE corporate action subject such as
+// "Dividend - Rs 10 Per Share" to an event type.
+func classifyCorporateAction(subject string) (models.CorporateEventType, bool) {
+	s := strings.ToLower(subject)
+	switch {
+	case strings.Contains(s, "dividend"):
+		return models.EventDividend, true
+	case strings.Contains(s, "split"), strings.Contains(s, "sub-division"):
+		return models.EventSplit, true
+	case strings.Contains(s, "bonus"):
+		return models.EventBonus, true
+	}
+	return "", false
+}
+
+// SetCorporateEventSource replaces the source used by EarningsCalendar.
+func (a *Aggregator) SetCorporateEventSource(src CorporateEventSource) {
+	a.events = src
+}
+
+// EarningsCalendar returns the corporate events (earnings, dividends,
+// splits, bonuses) for ticker, ordered by date.
+func (a *Aggregator) EarningsCalendar(ctx context.Context, ticker string) ([]models.CorporateEvent, error) {
+	events, err := a.events.GetCorporateEvents(ctx, utils.NormalizeTicker(ticker))
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]models.CorporateEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+	return sorted, nil
+}
+
+// NextEvent returns the earliest event of type typ on or after the day of now.
+func NextEvent(events []models.CorporateEvent, typ models.CorporateEventType, now time.Time) (models.CorporateEvent, bool) {
+	n := now.In(utils.IST)
+	today := time.Date(n.Year(), n.Month(), n.Day(), 0, 0, 0, 0, utils.IST)
+
+	var next models.CorporateEvent
+	found := false
+	for _, e := range events {
+		if e.Type != typ || e.Date.Before(today) {
+			continue
+		}
+		if !found || e.Date.Before(next.Date) {
+			next, found = e, true
+		}
+	}
+	return next, found
+}
@@ -0,0 +1,206 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
+)
+
+// HistoryFetcher is implemented by sources that serve OHLCV history.
+type HistoryFetcher interface {
+	GetHistoricalData(ctx context.Context, ticker string, from, to time.Time, tf models.Timeframe) ([]models.OHLCV, error)
+}
+
+// HistoricalData is OHLCV history together with how it was obtained.
+type HistoricalData struct {
+	Candles   []models.OHLCV   `json:"candles"`
+	Requested models.Timeframe `json:"requested"`
+	Timeframe models.Timeframe `json:"timeframe"`        // granularity of Candles
+	Source    models.Timeframe `json:"source_timeframe"` // granularity fetched from the source
+	Fallback  bool             `json:"fallback"`         // Requested was unavailable
+	Warning   string           `json:"warning,omitempty"`
+}
+
+// timeframeOrder lists timeframes from finest to coarsest.
+var timeframeOrder = []models.Timeframe{
+	models.Timeframe1Min,
+	models.Timeframe5Min,
+	models.Timeframe15Min,
+	models.Timeframe1Hour,
+	models.Timeframe1Day,
+	models.Timeframe1Week,
+	models.Timeframe1Mon,
+}
+
+// SetHistorySources replaces the sources FetchHistoricalData tries, in
+// order. The default is Yahoo Finance, then NSE.
+func (a *Aggregator) SetHistorySources(srcs ...HistoryFetcher) {
+	a.history = srcs
+}
+
+// SetHistoryCache makes FetchHistory consult c before the history sources
+// and store what they return in it. A nil cache disables caching, which
+// is the default.
+func (a *Aggregator) SetHistoryCache(c *HistoryCache) {
+	a.cache = c
+}
+
+// SetTimeframeFallback enables or disables falling back to another
+// granularity when no source serves the requested timeframe. It is
+// enabled by default.
+func (a *Aggregator) SetTimeframeFallback(enabled bool) {
+	a.noTimeframeFallback = !enabled
+}
+
+// FetchHistory fetches OHLCV history for ticker. When no source serves tf
+// and timeframe fallback is enabled, a weekly or monthly request is
+// resampled from daily candles, and an intraday request is served at the
+// next coarser timeframe that is available, up to daily. Either way the
+// result is flagged as a fallback with a warning. With a history cache
+// set, a fresh cached copy of the range is returned without fetching.
+func (a *Aggregator) FetchHistory(ctx context.Context, ticker string, from, to time.Time, tf models.Timeframe) (*HistoricalData, error) {
+	if a.cache == nil {
+		return a.fetchHistoryFallback(ctx, ticker, from, to, tf)
+	}
+	if data, ok := a.cache.Get(ticker, from, to, tf); ok {
+		return data, nil
+	}
+	data, err := a.fetchHistoryFallback(ctx, ticker, from, to, tf)
+	if err != nil {
+		return nil, err
+	}
+	_ = a.cache.Put(ticker, from, to, tf, data) // best-effort
+	return data, nil
+}
+
+// fetchHistoryFallback fetches tf from the history sources, falling back
+// to another granularity as described on FetchHistory.
+func (a *Aggregator) fetchHistoryFallback(ctx context.Context, ticker string, from, to time.Time, tf models.Timeframe) (*HistoricalData, error) {
+	candles, err := a.fetchHistory(ctx, ticker, from, to, tf)
+	if err == nil {
+		return &HistoricalData{Candles: candles, Requested: tf, Timeframe: tf, Source: tf}, nil
+	}
+	if a.noTimeframeFallback || ctx.Err() != nil {
+		return nil, err
+	}
+
+	for _, alt := range fallbackTimeframes(tf) {
+		bars, altErr := a.fetchHistory(ctx, ticker, from, to, alt)
+		if altErr != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		result := &HistoricalData{Requested: tf, Source: alt, Fallback: true}
+		if timeframeRank(alt) < timeframeRank(tf) {
+			result.Candles = ResampleOHLCV(bars, tf)
+			result.Timeframe = tf
+			result.Warning = fmt.Sprintf("%s candles unavailable for %s; resampled from %s", tf, ticker, alt)
+		} else {
+			result.Candles = bars
+			result.Timeframe = alt
+			result.Warning = fmt.Sprintf("%s candles unavailable for %s; serving %s candles", tf, ticker, alt)
+		}
+		return result, nil
+	}
+	return nil, err
+}
+
+// fetchHistory tries each history source in order for exactly tf.
+func (a *Aggregator) fetchHistory(ctx context.Context, ticker string, from, to time.Time, tf models.Timeframe) ([]models.OHLCV, error) {
+	var errs []error
+	for _, src := range a.history {
+		candles, err := src.GetHistoricalData(ctx, ticker, from, to, tf)
+		if err == nil && len(candles) > 0 {
+			return candles, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("no %s candles", tf)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("historical data unavailable for %s: no sources configured", ticker)
+	}
+	return nil, fmt.Errorf("historical data unavailable for %s: %w", ticker, errors.Join(errs...))
+}
+
+// fallbackTimeframes returns the timeframes to try, in order, when tf is
+// unavailable. Weekly and monthly candles can be built exactly from daily
+// ones. Intraday requests fall back to coarser timeframes up to daily,
+// since a source that lacks one intraday granularity for a range rarely
+// has a finer one.
+func fallbackTimeframes(tf models.Timeframe) []models.Timeframe {
+	rank := timeframeRank(tf)
+	daily := timeframeRank(models.Timeframe1Day)
+	switch {
+	case rank < 0:
+		return nil
+	case rank > daily:
+		return []models.Timeframe{models.Timeframe1Day}
+	case rank < daily:
+		return timeframeOrder[rank+1 : daily+1]
+	}
+	return nil
+}
+
+func timeframeRank(tf models.Timeframe) int {
+	for i, t := range timeframeOrder {
+		if t == tf {
+			return i
+		}
+	}
+	return -1
+}
+
+// ResampleOHLCV aggregates bars, sorted oldest first, into candles of the
+// coarser timeframe tf. Buckets are aligned in IST: days by calendar date,
+// weeks starting Monday, months by calendar month, and intraday buckets
+// by wall-clock time. Each candle is stamped with its first bar's time.
+func ResampleOHLCV(bars []models.OHLCV, tf models.Timeframe) []models.OHLCV {
+	var out []models.OHLCV
+	var current time.Time
+	for _, b := range bars {
+		bucket := bucketStart(b.Timestamp, tf)
+		if len(out) == 0 || !bucket.Equal(current) {
+			current = bucket
+			out = append(out, b)
+			continue
+		}
+		c := &out[len(out)-1]
+		if b.High > c.High {
+			c.High = b.High
+		}
+		if b.Low < c.Low {
+			c.Low = b.Low
+		}
+		c.Close = b.Close
+		c.Volume += b.Volume
+	}
+	return out
+}
+
+// bucketStart returns the start of the tf bucket containing t, in IST.
+func bucketStart(t time.Time, tf models.Timeframe) time.Time {
+	t = t.In(utils.IST)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, utils.IST)
+	switch tf {
+	case models.Timeframe5Min, models.Timeframe15Min:
+		step := int(tf.Duration() / time.Minute)
+		return t.Truncate(time.Minute).Add(-time.Duration(t.Minute()%step) * time.Minute)
+	case models.Timeframe1Hour:
+		return day.Add(time.Duration(t.Hour()) * time.Hour)
+	case models.Timeframe1Week:
+		return day.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	case models.Timeframe1Mon:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, utils.IST)
+	case models.Timeframe1Day:
+		return day
+	}
+	return t.Truncate(time.Minute)
+}
@@ -0,0 +1,66 @@
+package datasource
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// healthProbeTicker is the liquid ticker used to probe data sources.
+const healthProbeTicker = "RELIANCE"
+
+// SourceHealth is the result of probing one data source.
+type SourceHealth struct {
+	Name      string `json:"name"`
+	OK        bool   `json:"ok"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// sourceProbe performs a cheap request against one data source.
+type sourceProbe struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// CheckHealth probes the quote and financials sources concurrently and
+// reports whether each responded. Results are in a fixed order.
+func (a *Aggregator) CheckHealth(ctx context.Context) []SourceHealth {
+	return checkSources(ctx, []sourceProbe{
+		{a.yfinance.Name(), func(ctx context.Context) error {
+			_, err := a.yfinance.GetQuote(ctx, healthProbeTicker)
+			return err
+		}},
+		{a.nse.Name(), func(ctx context.Context) error {
+			_, err := a.nse.GetQuote(ctx, healthProbeTicker)
+			return err
+		}},
+		{a.screener.Name(), func(ctx context.Context) error {
+			_, err := a.screener.GetFinancials(ctx, healthProbeTicker)
+			return err
+		}},
+	})
+}
+
+func checkSources(ctx context.Context, probes []sourceProbe) []SourceHealth {
+	results := make([]SourceHealth, len(probes))
+	var wg sync.WaitGroup
+	for i, p := range probes {
+		wg.Add(1)
+		go func(i int, p sourceProbe) {
+			defer wg.Done()
+			start := time.Now()
+			err := p.check(ctx)
+			results[i] = SourceHealth{
+				Name:      p.name,
+				OK:        err == nil,
+				LatencyMS: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i, p)
+	}
+	wg.Wait()
+	return results
+}
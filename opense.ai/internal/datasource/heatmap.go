@@ -0,0 +1,149 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
+)
+
+// indexConstituents maps a normalized index name to its constituent tickers.
+// FinanceQL's nifty50(), niftybank() and default screener universe read
+// the same lists.
+var indexConstituents = map[string][]string{
+	"NIFTY 50": {
+		"RELIANCE", "TCS", "HDFCBANK", "INFY", "ICICIBANK",
+		"HINDUNILVR", "ITC", "SBIN", "BHARTIARTL", "KOTAKBANK",
+		"LT", "AXISBANK", "BAJFINANCE", "ASIANPAINT", "MARUTI",
+		"TITAN", "SUNPHARMA", "HCLTECH", "NTPC", "TATAMOTORS",
+		"ULTRACEMCO", "WIPRO", "POWERGRID", "NESTLEIND", "ONGC",
+		"JSWSTEEL", "ADANIENT", "ADANIPORTS", "TECHM", "TATASTEEL",
+		"M&M", "BAJAJFINSV", "HDFCLIFE", "BEL", "DRREDDY",
+		"SBILIFE", "BRITANNIA", "CIPLA", "COALINDIA", "INDUSINDBK",
+		"GRASIM", "EICHERMOT", "APOLLOHOSP", "HEROMOTOCO", "TATACONSUM",
+		"BPCL", "TRENT", "BAJAJ-AUTO", "HINDALCO", "SHRIRAMFIN",
+	},
+	"NIFTY BANK": {
+		"HDFCBANK", "ICICIBANK", "KOTAKBANK", "AXISBANK", "SBIN",
+		"INDUSINDBK", "CANBK", "FEDERALBNK", "IDFCFIRSTB", "PNB",
+		"AUBANK", "BANKBARODA",
+	},
+	"NIFTY IT": {
+		"COFORGE", "HCLTECH", "INFY", "LTIM", "LTTS",
+		"MPHASIS", "PERSISTENT", "TCS", "TECHM", "WIPRO",
+	},
+}
+
+// QuoteFetcher is implemented by anything that can return a quote for a ticker.
+type QuoteFetcher interface {
+	GetQuote(ctx context.Context, ticker string) (*models.Quote, error)
+}
+
+// HeatmapSource supplies index constituents and their quotes for a heatmap.
+type HeatmapSource interface {
+	QuoteFetcher
+
+	// IndexConstituents returns the constituent tickers of the given index.
+	IndexConstituents(ctx context.Context, index string) ([]string, error)
+}
+
+// Constituents returns the bundled constituent tickers of an index, or nil
+// if the index is not supported.
+func Constituents(index string) []string {
+	tickers := indexConstituents[utils.NormalizeTicker(index)]
+	if tickers == nil {
+		return nil
+	}
+	return append([]string(nil), tickers...)
+}
+
+// IndexConstituents returns the constituent tickers of a supported index.
+func (a *Aggregator) IndexConstituents(_ context.Context, index string) ([]string, error) {
+	tickers := Constituents(index)
+	if tickers == nil {
+		return nil, fmt.Errorf("%w: no constituents for index %q", ErrNotSupported, index)
+	}
+	return tickers, nil
+}
+
+// GetQuote returns a quote from the quotes source, or by default from
+// Yahoo Finance, falling back to NSE.
+func (a *Aggregator) GetQuote(ctx context.Context, ticker string) (*models.Quote, error) {
+	if src, ok := a.routes[CategoryQuotes]; ok {
+		return src.GetQuote(ctx, ticker)
+	}
+	quote, err := a.yfinance.GetQuote(ctx, ticker)
+	if err != nil {
+		quote, err = a.nse.GetQuote(ctx, ticker)
+	}
+	return quote, err
+}
+
+// FetchHeatmap fetches quotes for every constituent of index concurrently and
+// returns one heatmap cell per constituent, ordered by descending weight.
+// Weights are each constituent's share of the fetched total market cap.
+// Constituents whose quote fails are omitted.
+func FetchHeatmap(ctx context.Context, src HeatmapSource, index string) ([]models.HeatmapCell, error) {
+	tickers, err := src.IndexConstituents(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	return FetchHeatmapTickers(ctx, src, index, tickers)
+}
+
+// FetchHeatmapTickers is FetchHeatmap over an explicit ticker list, such as
+// a custom universe; name labels it in errors.
+func FetchHeatmapTickers(ctx context.Context, src HeatmapSource, name string, tickers []string) ([]models.HeatmapCell, error) {
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		cells = make([]models.HeatmapCell, 0, len(tickers))
+	)
+	for _, t := range tickers {
+		wg.Add(1)
+		go func(ticker string) {
+			defer wg.Done()
+			q, err := src.GetQuote(ctx, ticker)
+			if err != nil || q == nil {
+				return
+			}
+			mu.Lock()
+			cells = append(cells, models.HeatmapCell{
+				Symbol:    ticker,
+				Name:      q.Name,
+				LastPrice: q.LastPrice,
+				ChangePct: q.ChangePct,
+				GapPct:    q.GapPct(),
+				RangePct:  q.DayRangePct(),
+				MarketCap: q.MarketCap,
+			})
+			mu.Unlock()
+		}(t)
+	}
+	wg.Wait()
+
+	if len(cells) == 0 && len(tickers) > 0 {
+		return nil, fmt.Errorf("no quotes available for %s constituents", name)
+	}
+
+	var totalCap float64
+	for _, c := range cells {
+		totalCap += c.MarketCap
+	}
+	if totalCap > 0 {
+		for i := range cells {
+			cells[i].Weight = cells[i].MarketCap / totalCap * 100
+		}
+	}
+
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Weight != cells[j].Weight {
+			return cells[i].Weight > cells[j].Weight
+		}
+		return cells[i].Symbol < cells[j].Symbol
+	})
+	return cells, nil
+}
@@ -0,0 +1,182 @@
+package datasource
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
+)
+
+// historyCacheTTL is how long a cached range that reaches today stays
+// fresh. Ranges that end before today hold settled bars and never expire.
+const historyCacheTTL = 15 * time.Minute
+
+// HistoryCache stores fetched OHLCV history on disk, one JSON file per
+// (ticker, timeframe) holding the date range covered so far. Any range
+// inside the stored coverage is served from it, and overlapping writes
+// extend the coverage. It is best-effort: read and write failures are
+// treated as cache misses.
+type HistoryCache struct {
+	dir     string
+	refresh bool
+	now     func() time.Time
+}
+
+// historyCacheEntry is the on-disk form of a cached ticker/timeframe.
+// FetchedAt is when the latest end of the coverage was fetched.
+type historyCacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	From      time.Time       `json:"from"`
+	To        time.Time       `json:"to"`
+	Data      *HistoricalData `json:"data"`
+}
+
+// NewHistoryCache returns a cache that stores its files in dir, which is
+// created on the first write.
+func NewHistoryCache(dir string) *HistoryCache {
+	return &HistoryCache{dir: dir, now: time.Now}
+}
+
+// SetRefresh makes the cache ignore stored entries while still writing
+// fresh ones, so the next fetch of each range goes to the source.
+func (c *HistoryCache) SetRefresh(refresh bool) {
+	c.refresh = refresh
+}
+
+// Get returns the cached history for the range when the stored coverage
+// contains it and is fresh enough, trimmed to the requested dates.
+func (c *HistoryCache) Get(ticker string, from, to time.Time, tf models.Timeframe) (*HistoricalData, bool) {
+	if c.refresh {
+		return nil, false
+	}
+	entry, ok := c.load(ticker, tf)
+	if !ok {
+		return nil, false
+	}
+	lo, hi := cacheBound(from, tf), cacheBound(to, tf)
+	if lo.Before(cacheBound(entry.From, tf)) || hi.After(cacheBound(entry.To, tf)) {
+		return nil, false
+	}
+	if !c.settled(to) && c.now().Sub(entry.FetchedAt) > historyCacheTTL {
+		return nil, false
+	}
+	if lo.Equal(cacheBound(entry.From, tf)) && hi.Equal(cacheBound(entry.To, tf)) {
+		return entry.Data, true
+	}
+	data := *entry.Data
+	data.Candles = nil
+	for _, bar := range entry.Data.Candles {
+		if ts := cacheBound(bar.Timestamp, tf); !ts.Before(lo) && !ts.After(hi) {
+			data.Candles = append(data.Candles, bar)
+		}
+	}
+	return &data, true
+}
+
+// Put stores data as the cached history for the range. When the stored
+// coverage overlaps the range and was fetched at the same granularity,
+// the two are merged, with data replacing the stored bars inside the
+// range; otherwise data replaces the stored entry.
+func (c *HistoryCache) Put(ticker string, from, to time.Time, tf models.Timeframe, data *HistoricalData) error {
+	entry := historyCacheEntry{FetchedAt: c.now(), From: from, To: to, Data: data}
+	if old, ok := c.load(ticker, tf); ok && mergeable(old, entry, tf) {
+		entry = mergeHistory(old, entry, tf)
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("cannot create cache directory %s: %w", c.dir, err)
+	}
+	path := c.path(ticker, tf)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// load reads the stored entry for ticker and tf.
+func (c *HistoryCache) load(ticker string, tf models.Timeframe) (historyCacheEntry, bool) {
+	var entry historyCacheEntry
+	raw, err := os.ReadFile(c.path(ticker, tf))
+	if err != nil {
+		return entry, false
+	}
+	if err := json.Unmarshal(raw, &entry); err != nil || entry.Data == nil {
+		return entry, false
+	}
+	return entry, true
+}
+
+// mergeable reports whether next can extend old: the ranges overlap and
+// both were served at the same granularity.
+func mergeable(old, next historyCacheEntry, tf models.Timeframe) bool {
+	if cacheBound(next.From, tf).After(cacheBound(old.To, tf)) || cacheBound(next.To, tf).Before(cacheBound(old.From, tf)) {
+		return false
+	}
+	return old.Data.Timeframe == next.Data.Timeframe && old.Data.Source == next.Data.Source
+}
+
+// mergeHistory combines two overlapping entries: bars of next replace
+// those of old inside next's range, and the coverage is their union.
+func mergeHistory(old, next historyCacheEntry, tf models.Timeframe) historyCacheEntry {
+	lo, hi := cacheBound(next.From, tf), cacheBound(next.To, tf)
+	data := *next.Data
+	data.Candles = nil
+	for _, bar := range old.Data.Candles {
+		if ts := cacheBound(bar.Timestamp, tf); ts.Before(lo) || ts.After(hi) {
+			data.Candles = append(data.Candles, bar)
+		}
+	}
+	data.Candles = append(data.Candles, next.Data.Candles...)
+	sort.SliceStable(data.Candles, func(i, j int) bool {
+		return data.Candles[i].Timestamp.Before(data.Candles[j].Timestamp)
+	})
+
+	merged := next
+	merged.Data = &data
+	if old.From.Before(merged.From) {
+		merged.From = old.From
+	}
+	if cacheBound(old.To, tf).After(hi) {
+		merged.To, merged.FetchedAt = old.To, old.FetchedAt
+	}
+	return merged
+}
+
+// settled reports whether a range ending at to lies entirely before today
+// (IST), so its bars can no longer change.
+func (c *HistoryCache) settled(to time.Time) bool {
+	now := c.now().In(utils.IST)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, utils.IST)
+	return to.Before(today)
+}
+
+// cacheBound truncates t to the resolution ranges are compared at: the
+// IST date for daily and coarser timeframes, so a range ending "now"
+// matches the coverage all day, and the minute for intraday ones.
+func cacheBound(t time.Time, tf models.Timeframe) time.Time {
+	t = t.In(utils.IST)
+	if tf.Intraday() {
+		return t.Truncate(time.Minute)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, utils.IST)
+}
+
+// path returns the cache file for a ticker and timeframe.
+func (c *HistoryCache) path(ticker string, tf models.Timeframe) string {
+	name := string(tf)
+	if tf == models.Timeframe1Mon {
+		name = "1mo" // "1M" and "1m" collide on case-insensitive filesystems
+	}
+	return filepath.Join(c.dir, fmt.Sprintf("%s_%s.json",
+		url.PathEscape(utils.NormalizeTicker(ticker)), name))
+}
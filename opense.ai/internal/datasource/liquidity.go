@@ -0,0 +1,42 @@
+package datasource
+
+import "github.com/seenimoa/openseai/pkg/models"
+
+// Liquidity summarises how actively a stock trades over a window of bars.
+type Liquidity struct {
+	AvgVolume   float64 `json:"avg_volume"`   // shares per bar
+	AvgTurnover float64 `json:"avg_turnover"` // INR per bar (close × volume)
+	Bars        int     `json:"bars"`
+}
+
+// MeasureLiquidity returns the average volume and turnover of bars.
+func MeasureLiquidity(bars []models.OHLCV) Liquidity {
+	l := Liquidity{Bars: len(bars)}
+	if len(bars) == 0 {
+		return l
+	}
+	for _, b := range bars {
+		l.AvgVolume += float64(b.Volume)
+		l.AvgTurnover += b.Close * float64(b.Volume)
+	}
+	l.AvgVolume /= float64(len(bars))
+	l.AvgTurnover /= float64(len(bars))
+	return l
+}
+
+// LiquidityFilter excludes stocks whose average daily volume or turnover
+// falls below a floor. A zero floor is not applied.
+type LiquidityFilter struct {
+	MinVolume   float64 `json:"min_volume,omitempty"`
+	MinTurnover float64 `json:"min_turnover,omitempty"` // INR
+}
+
+// Enabled reports whether the filter has any floor set.
+func (f LiquidityFilter) Enabled() bool {
+	return f.MinVolume > 0 || f.MinTurnover > 0
+}
+
+// Allows reports whether l clears every floor set on f.
+func (f LiquidityFilter) Allows(l Liquidity) bool {
+	return l.AvgVolume >= f.MinVolume && l.AvgTurnover >= f.MinTurnover
+}
@@ -189,9 +189,12 @@ func (n *NSE) GetQuote(ctx context.Context, ticker string) (*models.Quote, error
 	return quote, nil
 }
 
-// GetHistoricalData returns historical OHLCV from NSE.
+// GetHistoricalData returns historical daily OHLCV from NSE.
 // NSE provides limited historical data; for longer history use YFinance.
-func (n *NSE) GetHistoricalData(ctx context.Context, ticker string, from, to time.Time, _ models.Timeframe) ([]models.OHLCV, error) {
+func (n *NSE) GetHistoricalData(ctx context.Context, ticker string, from, to time.Time, tf models.Timeframe) ([]models.OHLCV, error) {
+	if tf != "" && tf != models.Timeframe1Day {
+		return nil, fmt.Errorf("%w: NSE serves daily history only, not %s", ErrNotSupported, tf)
+	}
 	symbol := utils.NormalizeTicker(ticker)
 
 	cacheKey := fmt.Sprintf("nse:hist:%s:%s:%s", symbol, from.Format("2006-01-02"), to.Format("2006-01-02"))
@@ -0,0 +1,82 @@
+package datasource
+
+import (
+	"math"
+	"sort"
+
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// winsorizeRadius is the number of bars on each side of a bar that form
+// its reference window in WinsorizeBars.
+const winsorizeRadius = 10
+
+// WinsorizeBars returns a copy of bars with spurious price spikes capped.
+// Each bar is compared with up to winsorizeRadius bars on either side,
+// excluding itself: its open, high, low, and close are clamped to within k
+// standard deviations (of the neighbours' highs, lows, and closes) of the
+// neighbours' median close. Bars inside the band are returned unchanged.
+// Volume and timestamps are never modified. With fewer than three bars or
+// k <= 0 the bars are returned as is.
+func WinsorizeBars(bars []models.OHLCV, k float64) []models.OHLCV {
+	out := make([]models.OHLCV, len(bars))
+	copy(out, bars)
+	if len(bars) < 3 || k <= 0 {
+		return out
+	}
+
+	closes := make([]float64, 0, 2*winsorizeRadius)
+	prices := make([]float64, 0, 6*winsorizeRadius)
+	for i := range bars {
+		closes, prices = closes[:0], prices[:0]
+		lo, hi := max(0, i-winsorizeRadius), min(len(bars)-1, i+winsorizeRadius)
+		for j := lo; j <= hi; j++ {
+			if j != i {
+				closes = append(closes, bars[j].Close)
+				prices = append(prices, bars[j].High, bars[j].Low, bars[j].Close)
+			}
+		}
+		med, sd := median(closes), stdDev(prices)
+		lower, upper := med-k*sd, med+k*sd
+
+		b := &out[i]
+		if b.Open >= lower && b.Open <= upper && b.High >= lower && b.High <= upper &&
+			b.Low >= lower && b.Low <= upper && b.Close >= lower && b.Close <= upper {
+			continue
+		}
+		b.Open = clamp(b.Open, lower, upper)
+		b.High = clamp(b.High, lower, upper)
+		b.Low = clamp(b.Low, lower, upper)
+		b.Close = clamp(b.Close, lower, upper)
+		b.High = math.Max(b.High, math.Max(b.Open, b.Close))
+		b.Low = math.Min(b.Low, math.Min(b.Open, b.Close))
+	}
+	return out
+}
+
+// median returns the median of values, which it sorts in place.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// stdDev returns the population standard deviation of values.
+func stdDev(values []float64) float64 {
+	var mean, ss float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		ss += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(ss / float64(len(values)))
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(v, hi))
+}
@@ -0,0 +1,134 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/seenimoa/openseai/pkg/models"
+)
+
+// DataCategory is a kind of data whose source can be chosen in config.
+type DataCategory string
+
+const (
+	CategoryQuotes       DataCategory = "quotes"       // GetQuote, and the quote in profiles and bundles
+	CategoryFundamentals DataCategory = "fundamentals" // financials and ratios in profiles and bundles
+	CategoryOptions      DataCategory = "options"      // FetchOptionChain
+)
+
+// SourceSelection names the source serving each data category. An empty
+// name keeps the default routing.
+type SourceSelection struct {
+	Quotes       string
+	Fundamentals string
+	Options      string
+}
+
+// ratiosFetcher is implemented by sources that serve financial ratios.
+type ratiosFetcher interface {
+	GetFinancialRatios(ctx context.Context, ticker string) (*models.FinancialRatios, error)
+}
+
+// RegisterSource makes src selectable by name with UseSource. The built-in
+// sources are registered as "yfinance", "nse", "nse_derivatives", and
+// "screener".
+func (a *Aggregator) RegisterSource(name string, src DataSource) {
+	a.named[strings.ToLower(name)] = src
+}
+
+// SourceNames returns the names of the registered sources, sorted.
+func (a *Aggregator) SourceNames() []string {
+	names := make([]string, 0, len(a.named))
+	for name := range a.named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseSource routes every request for category to the registered source
+// called name.
+func (a *Aggregator) UseSource(category DataCategory, name string) error {
+	switch category {
+	case CategoryQuotes, CategoryFundamentals, CategoryOptions:
+	default:
+		return fmt.Errorf("unknown data category %q", category)
+	}
+	src, ok := a.named[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown %s source %q (available: %s)", category, name, strings.Join(a.SourceNames(), ", "))
+	}
+	a.routes[category] = src
+	return nil
+}
+
+// SelectSources applies sel, skipping categories it leaves empty.
+func (a *Aggregator) SelectSources(sel SourceSelection) error {
+	for _, r := range []struct {
+		category DataCategory
+		name     string
+	}{
+		{CategoryQuotes, sel.Quotes},
+		{CategoryFundamentals, sel.Fundamentals},
+		{CategoryOptions, sel.Options},
+	} {
+		if r.name == "" {
+			continue
+		}
+		if err := a.UseSource(r.category, r.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fundamentalsSource returns the source of financials and ratios.
+func (a *Aggregator) fundamentalsSource() DataSource {
+	if src, ok := a.routes[CategoryFundamentals]; ok {
+		return src
+	}
+	return a.screener
+}
+
+// optionsSource returns the source of option chains.
+func (a *Aggregator) optionsSource() DataSource {
+	if src, ok := a.routes[CategoryOptions]; ok {
+		return src
+	}
+	return a.derivatives
+}
+
+// The methods below make the Aggregator a DataSource whose every request
+// honours the configured routes, so consumers can hold it in place of the
+// individual sources.
+
+// Name returns the aggregator's name.
+func (a *Aggregator) Name() string { return "Aggregator" }
+
+// GetFinancials returns financial statements from the fundamentals source.
+func (a *Aggregator) GetFinancials(ctx context.Context, ticker string) (*models.FinancialData, error) {
+	return a.fundamentalsSource().GetFinancials(ctx, ticker)
+}
+
+// GetHistoricalData is FetchHistoricalData.
+func (a *Aggregator) GetHistoricalData(ctx context.Context, ticker string, from, to time.Time, tf models.Timeframe) ([]models.OHLCV, error) {
+	return a.FetchHistoricalData(ctx, ticker, from, to, tf)
+}
+
+// GetOptionChain is FetchOptionChain.
+func (a *Aggregator) GetOptionChain(ctx context.Context, ticker string, expiry string) (*models.OptionChain, error) {
+	return a.FetchOptionChain(ctx, ticker, expiry)
+}
+
+// GetStockProfile is FetchProfile.
+func (a *Aggregator) GetStockProfile(ctx context.Context, ticker string) (*models.StockProfile, error) {
+	return a.FetchProfile(ctx, ticker)
+}
+
+// GetCorporateEvents is EarningsCalendar.
+func (a *Aggregator) GetCorporateEvents(ctx context.Context, ticker string) ([]models.CorporateEvent, error) {
+	return a.EarningsCalendar(ctx, ticker)
+}
@@ -295,6 +295,9 @@ func (s *Screener) parseIncomeTable(doc *goquery.Document, sectionID string) []m
 				statements[idx].PAT = val
 			case strings.Contains(label, "EPS"):
 				statements[idx].EPS = val
+			case strings.Contains(label, "Dividend Payout"):
+				// Screener lists the payout %, below the EPS row.
+				statements[idx].DividendPerShare = val * statements[idx].EPS / 100
 			}
 		})
 	})
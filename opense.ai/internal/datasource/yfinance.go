@@ -129,7 +129,7 @@ type yfError struct {
 
 // GetQuote returns a real-time quote from Yahoo Finance.
 func (y *YFinance) GetQuote(ctx context.Context, ticker string) (*models.Quote, error) {
-	yfTicker := utils.ToYFinanceTicker(ticker)
+	yfTicker := utils.ToYahooSymbol(ticker, "")
 
 	// Check cache.
 	cacheKey := "quote:" + yfTicker
@@ -194,7 +194,7 @@ func (y *YFinance) GetQuote(ctx context.Context, ticker string) (*models.Quote,
 
 // GetHistoricalData returns OHLCV candles from Yahoo Finance chart API.
 func (y *YFinance) GetHistoricalData(ctx context.Context, ticker string, from, to time.Time, tf models.Timeframe) ([]models.OHLCV, error) {
-	yfTicker := utils.ToYFinanceTicker(ticker)
+	yfTicker := utils.ToYahooSymbol(ticker, "")
 
 	cacheKey := fmt.Sprintf("hist:%s:%d:%d:%s", yfTicker, from.Unix(), to.Unix(), tf)
 	if cached, ok := y.cache.Get(cacheKey); ok {
@@ -245,7 +245,7 @@ func (y *YFinance) GetHistoricalData(ctx context.Context, ticker string, from, t
 
 // GetFinancials returns financial statements from Yahoo Finance.
 func (y *YFinance) GetFinancials(ctx context.Context, ticker string) (*models.FinancialData, error) {
-	yfTicker := utils.ToYFinanceTicker(ticker)
+	yfTicker := utils.ToYahooSymbol(ticker, "")
 
 	cacheKey := "fin:" + yfTicker
 	if cached, ok := y.cache.Get(cacheKey); ok {
@@ -313,7 +313,7 @@ func (y *YFinance) GetStockProfile(ctx context.Context, ticker string) (*models.
 	profile := &models.StockProfile{
 		Stock: models.Stock{
 			Ticker:    utils.NormalizeTicker(ticker),
-			NSETicker: utils.ToYFinanceTicker(ticker),
+			NSETicker: utils.ToYahooSymbol(ticker, ""),
 			Name:      quote.Name,
 			Exchange:  "NSE",
 			MarketCap: quote.MarketCap,
@@ -0,0 +1,45 @@
+package financeql
+
+// Downsample reduces pts to at most n points for charting while keeping
+// its shape. The first and last points are always kept; the points between
+// them are split into (n-2)/2 equal buckets, and each bucket contributes
+// its lowest and highest point in time order, so every peak and trough of
+// the series survives. pts is returned unchanged when it already fits or n
+// is not positive.
+func Downsample(pts []TimePoint, n int) []TimePoint {
+	if n <= 0 || len(pts) <= n {
+		return pts
+	}
+	if n == 1 {
+		return []TimePoint{pts[len(pts)-1]}
+	}
+
+	out := make([]TimePoint, 0, n)
+	out = append(out, pts[0])
+	inner := pts[1 : len(pts)-1]
+	buckets := (n - 2) / 2
+	for b := 0; b < buckets; b++ {
+		lo, hi := b*len(inner)/buckets, (b+1)*len(inner)/buckets
+		if lo == hi {
+			continue
+		}
+		minIdx, maxIdx := lo, lo
+		for i := lo + 1; i < hi; i++ {
+			if inner[i].Value < inner[minIdx].Value {
+				minIdx = i
+			}
+			if inner[i].Value > inner[maxIdx].Value {
+				maxIdx = i
+			}
+		}
+		switch {
+		case minIdx == maxIdx:
+			out = append(out, inner[minIdx])
+		case minIdx < maxIdx:
+			out = append(out, inner[minIdx], inner[maxIdx])
+		default:
+			out = append(out, inner[maxIdx], inner[minIdx])
+		}
+	}
+	return append(out, pts[len(pts)-1])
+}
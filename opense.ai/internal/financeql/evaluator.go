@@ -2,6 +2,7 @@ package financeql
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"strings"
@@ -22,20 +23,54 @@ type BuiltinFunc func(ctx *EvalContext, args []Value) (Value, error)
 
 // EvalContext carries runtime state during expression evaluation.
 type EvalContext struct {
-	Ctx        context.Context
-	Aggregator *datasource.Aggregator // data source
-	Functions  map[string]BuiltinFunc // registered functions
-	Cache      *EvalCache             // query cache
-	PipeInput  *Value                 // upstream value from pipe (nil if none)
+	Ctx          context.Context
+	Aggregator   *datasource.Aggregator     // data source
+	Fundamentals FundamentalsSource         // quotes and profiles for per-ticker functions
+	Functions    map[string]BuiltinFunc     // registered functions
+	Cache        *EvalCache                 // query cache
+	PipeInput    *Value                     // upstream value from pipe (nil if none)
+	Universe     []string                   // tickers scanned by screener (default: Nifty 50)
+	Universes    map[string][]string        // named ticker lists for universe("name")
+	Precision    int                        // decimal places for displayed scalars; 0 picks by magnitude
+	History      datasource.HistoryFetcher  // daily candles; nil reads from Yahoo Finance
+	Liquidity    datasource.LiquidityFilter // screener skips tickers below these floors
+	Describers   map[string]DescribeFunc    // human descriptions for EvalQueryExplain
+	Resolver     TickerResolver             // validates query tickers; nil only normalizes them
+
+	memo         *tickerMemo   // per-evaluation quote/profile memo (nil outside EvalQuery)
+	trace        *explainTrace // explanations being collected (nil outside EvalQueryExplain)
+	screenTicker string        // ticker bound to * while evaluating a screener filter
+}
+
+// FundamentalsSource supplies the quote and stock profile that the price and
+// fundamental functions read from.
+type FundamentalsSource interface {
+	GetQuote(ctx context.Context, ticker string) (*models.Quote, error)
+	GetStockProfile(ctx context.Context, ticker string) (*models.StockProfile, error)
+}
+
+// aggregatorFundamentals reads quotes and profiles (ratios, promoter holding)
+// through the aggregator, honouring its configured source routes.
+type aggregatorFundamentals struct {
+	agg *datasource.Aggregator
+}
+
+func (a aggregatorFundamentals) GetQuote(ctx context.Context, ticker string) (*models.Quote, error) {
+	return a.agg.GetQuote(ctx, ticker)
+}
+
+func (a aggregatorFundamentals) GetStockProfile(ctx context.Context, ticker string) (*models.StockProfile, error) {
+	return a.agg.FetchProfile(ctx, ticker)
 }
 
 // NewEvalContext creates an evaluation context with the given aggregator and defaults.
 func NewEvalContext(ctx context.Context, agg *datasource.Aggregator) *EvalContext {
 	ec := &EvalContext{
-		Ctx:        ctx,
-		Aggregator: agg,
-		Functions:  make(map[string]BuiltinFunc),
-		Cache:      NewEvalCache(5 * time.Minute),
+		Ctx:          ctx,
+		Aggregator:   agg,
+		Fundamentals: aggregatorFundamentals{agg},
+		Functions:    make(map[string]BuiltinFunc),
+		Cache:        NewEvalCache(5 * time.Minute),
 	}
 	RegisterBuiltins(ec)
 	return ec
@@ -88,6 +123,62 @@ func (c *EvalCache) Set(key string, val Value) {
 	c.entries[key] = cacheEntry{value: val, expiresAt: time.Now().Add(c.ttl)}
 }
 
+// ════════════════════════════════════════════════════════════════════
+// Per-evaluation memo
+// ════════════════════════════════════════════════════════════════════
+
+// tickerMemo remembers each ticker's quote and profile (or fetch error) for
+// the duration of one query, so a screener filter that references several
+// metrics fetches each ticker's data once.
+type tickerMemo struct {
+	mu       sync.Mutex
+	quotes   map[string]memoEntry[*models.Quote]
+	profiles map[string]memoEntry[*models.StockProfile]
+}
+
+type memoEntry[T any] struct {
+	value T
+	err   error
+}
+
+func newTickerMemo() *tickerMemo {
+	return &tickerMemo{
+		quotes:   make(map[string]memoEntry[*models.Quote]),
+		profiles: make(map[string]memoEntry[*models.StockProfile]),
+	}
+}
+
+// fetchQuote returns the quote for ticker, fetching it at most once per evaluation.
+func (ec *EvalContext) fetchQuote(ticker string) (*models.Quote, error) {
+	if ec.memo == nil {
+		return ec.Fundamentals.GetQuote(ec.Ctx, ticker)
+	}
+	ec.memo.mu.Lock()
+	defer ec.memo.mu.Unlock()
+	if e, ok := ec.memo.quotes[ticker]; ok {
+		return e.value, e.err
+	}
+	q, err := ec.Fundamentals.GetQuote(ec.Ctx, ticker)
+	ec.memo.quotes[ticker] = memoEntry[*models.Quote]{q, err}
+	return q, err
+}
+
+// fetchProfile returns the stock profile for ticker, fetching it at most once
+// per evaluation.
+func (ec *EvalContext) fetchProfile(ticker string) (*models.StockProfile, error) {
+	if ec.memo == nil {
+		return ec.Fundamentals.GetStockProfile(ec.Ctx, ticker)
+	}
+	ec.memo.mu.Lock()
+	defer ec.memo.mu.Unlock()
+	if e, ok := ec.memo.profiles[ticker]; ok {
+		return e.value, e.err
+	}
+	p, err := ec.Fundamentals.GetStockProfile(ec.Ctx, ticker)
+	ec.memo.profiles[ticker] = memoEntry[*models.StockProfile]{p, err}
+	return p, err
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Evaluator — AST Walker
 // ════════════════════════════════════════════════════════════════════
@@ -143,6 +234,8 @@ func EvalQuery(ec *EvalContext, query string) (Value, error) {
 	if err != nil {
 		return NilValue(), err
 	}
+	ec.memo = newTickerMemo()
+	defer func() { ec.memo = nil }()
 	return Eval(ec, node)
 }
 
@@ -157,6 +250,9 @@ func evalIdentifier(ec *EvalContext, n *Identifier) (Value, error) {
 	name := n.Name
 
 	if name == "*" {
+		if ec.screenTicker != "" {
+			return StringValue(ec.screenTicker), nil
+		}
 		return StringValue("*"), nil
 	}
 
@@ -165,6 +261,14 @@ func evalIdentifier(ec *EvalContext, n *Identifier) (Value, error) {
 		return StringValue(name), nil
 	}
 
+	// Inside a screener filter, a bare function name is applied to the
+	// ticker being screened: pe < 15 is shorthand for pe(*) < 15.
+	if ec.screenTicker != "" {
+		if fn, ok := ec.Functions[strings.ToLower(name)]; ok {
+			return fn(ec, []Value{StringValue(ec.screenTicker)})
+		}
+	}
+
 	// Try treating it as a ticker — resolve price
 	if fn, ok := ec.Functions["price"]; ok {
 		return fn(ec, []Value{StringValue(name)})
@@ -186,6 +290,10 @@ func evalFunctionCall(ec *EvalContext, n *FunctionCall) (Value, error) {
 	for i, argNode := range n.Args {
 		// For function calls that take ticker names, pass identifiers as strings
 		if ident, ok := argNode.(*Identifier); ok {
+			if ident.Name == "*" && ec.screenTicker != "" {
+				args[i] = StringValue(ec.screenTicker)
+				continue
+			}
 			args[i] = StringValue(ident.Name)
 			continue
 		}
@@ -201,7 +309,21 @@ func evalFunctionCall(ec *EvalContext, n *FunctionCall) (Value, error) {
 		args = append([]Value{*ec.PipeInput}, args...)
 	}
 
-	return fn(ec, args)
+	return callFunc(ec, name, fn, args)
+}
+
+// callFunc invokes a registered function, recording an explanation of the
+// call when the evaluation is being explained.
+func callFunc(ec *EvalContext, name string, fn BuiltinFunc, args []Value) (Value, error) {
+	if ec.trace == nil {
+		return fn(ec, args)
+	}
+	from := ec.trace.fetchCount()
+	val, err := fn(ec, args)
+	if err == nil {
+		ec.trace.record(ec, name, args, from)
+	}
+	return val, err
 }
 
 func evalRangeSelector(ec *EvalContext, n *RangeSelector) (Value, error) {
@@ -225,7 +347,7 @@ func evalRangeSelector(ec *EvalContext, n *RangeSelector) (Value, error) {
 				args[i] = val
 			}
 			args = append(args, ScalarValue(float64(n.Days)))
-			return fn(ec, args)
+			return callFunc(ec, rangeName, fn, args)
 		}
 
 		// Fallback: evaluate inner as instant, and wrap
@@ -333,23 +455,86 @@ func evalPipeExpr(ec *EvalContext, n *PipeExpr) (Value, error) {
 
 	// Create a new context with pipe input set
 	pipeCtx := &EvalContext{
-		Ctx:        ec.Ctx,
-		Aggregator: ec.Aggregator,
-		Functions:  ec.Functions,
-		Cache:      ec.Cache,
-		PipeInput:  &leftVal,
+		Ctx:          ec.Ctx,
+		Aggregator:   ec.Aggregator,
+		Fundamentals: ec.Fundamentals,
+		Functions:    ec.Functions,
+		Cache:        ec.Cache,
+		PipeInput:    &leftVal,
+		Universe:     ec.Universe,
+		Universes:    ec.Universes,
+		History:      ec.History,
+		Liquidity:    ec.Liquidity,
+		Describers:   ec.Describers,
+		Resolver:     ec.Resolver,
+		memo:         ec.memo,
+		trace:        ec.trace,
+		screenTicker: ec.screenTicker,
 	}
 
 	return Eval(pipeCtx, n.Right)
 }
 
+// evalScreenerExpr evaluates the filter once per ticker in the universe, with
+// * bound to that ticker, and returns the tickers for which it holds.
+// Tickers whose data cannot be fetched, or that fail ec.Liquidity, are
+// skipped; if no ticker could be evaluated at all, the first failure is
+// returned instead of an empty table.
 func evalScreenerExpr(ec *EvalContext, n *ScreenerExpr) (Value, error) {
-	if fn, ok := ec.Functions["_screener"]; ok {
-		// Pass the filter AST as a string and the raw node
-		filterStr := n.Filter.String()
-		return fn(ec, []Value{StringValue(filterStr)})
+	universe := ec.Universe
+	if len(universe) == 0 {
+		universe = datasource.Constituents("NIFTY 50")
+	}
+	if ec.memo == nil {
+		ec.memo = newTickerMemo()
+		defer func() { ec.memo = nil }()
 	}
-	return NilValue(), fmt.Errorf("screener functionality not available")
+
+	rows := make([]map[string]interface{}, 0)
+	failed := 0
+	var firstErr error
+	fail := func(ticker string, err error) {
+		failed++
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", ticker, err)
+		}
+	}
+	for _, ticker := range universe {
+		if ec.Ctx != nil {
+			if err := ec.Ctx.Err(); err != nil {
+				return NilValue(), err
+			}
+		}
+		resolved, err := ec.resolveTicker(ticker)
+		if err != nil {
+			fail(ticker, err)
+			continue
+		}
+		tickerCtx := *ec
+		tickerCtx.screenTicker = resolved
+		if ec.Liquidity.Enabled() {
+			liq, err := tickerLiquidity(ec, tickerCtx.screenTicker, liquidityLookbackDays)
+			if err != nil {
+				fail(resolved, err)
+				continue
+			}
+			if !ec.Liquidity.Allows(liq) {
+				continue
+			}
+		}
+		val, err := Eval(&tickerCtx, n.Filter)
+		if err != nil {
+			fail(resolved, err)
+			continue
+		}
+		if toBool(val) {
+			rows = append(rows, map[string]interface{}{"ticker": tickerCtx.screenTicker})
+		}
+	}
+	if failed > 0 && failed == len(universe) {
+		return NilValue(), fmt.Errorf("screener: no ticker could be evaluated: %w", firstErr)
+	}
+	return TableValue(rows), nil
 }
 
 func evalAlertExpr(ec *EvalContext, n *AlertExpr) (Value, error) {
@@ -449,6 +634,35 @@ func ResolveTicker(ticker string) string {
 	return t
 }
 
+// ErrUnknownSymbol is returned, wrapped, when a TickerResolver does not
+// recognise a ticker used in a query.
+var ErrUnknownSymbol = errors.New("unknown symbol")
+
+// TickerResolver maps a ticker as written in a query to the symbol the data
+// sources expect, e.g. by looking it up in an exchange symbol master.
+// Resolve returns an error wrapping ErrUnknownSymbol for tickers it does not
+// know, so the query fails before any data is fetched.
+type TickerResolver interface {
+	Resolve(ctx context.Context, ticker string) (string, error)
+}
+
+// PassthroughResolver accepts every ticker, only normalizing it with
+// ResolveTicker. It is the default when EvalContext.Resolver is nil.
+type PassthroughResolver struct{}
+
+// Resolve returns ResolveTicker(ticker).
+func (PassthroughResolver) Resolve(_ context.Context, ticker string) (string, error) {
+	return ResolveTicker(ticker), nil
+}
+
+// resolveTicker normalizes ticker and validates it with ec.Resolver.
+func (ec *EvalContext) resolveTicker(ticker string) (string, error) {
+	if ec.Resolver == nil {
+		return ResolveTicker(ticker), nil
+	}
+	return ec.Resolver.Resolve(ec.Ctx, ResolveTicker(ticker))
+}
+
 // FetchHistorical fetches OHLCV data with caching.
 func FetchHistorical(ec *EvalContext, ticker string, days int) ([]models.OHLCV, error) {
 	key := fmt.Sprintf("hist:%s:%d", ticker, days)
@@ -458,12 +672,26 @@ func FetchHistorical(ec *EvalContext, ticker string, days int) ([]models.OHLCV,
 	}
 
 	to := time.Now()
-	from := to.AddDate(0, 0, -days)
+	return FetchHistoricalRange(ec, ticker, to.AddDate(0, 0, -days), to)
+}
 
-	data, err := ec.Aggregator.YFinance().GetHistoricalData(ec.Ctx, ticker, from, to, models.Timeframe1Day)
+// FetchHistoricalRange fetches daily candles for a ticker between from and to.
+func FetchHistoricalRange(ec *EvalContext, ticker string, from, to time.Time) ([]models.OHLCV, error) {
+	src := ec.History
+	if src == nil {
+		src = ec.Aggregator.YFinance()
+	}
+	data, err := src.GetHistoricalData(ec.Ctx, ticker, from, to, models.Timeframe1Day)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch historical data for %s: %w", ticker, err)
 	}
+	if ec.trace != nil {
+		r := DataRange{Ticker: ticker, Bars: len(data)}
+		if len(data) > 0 {
+			r.From, r.To = data[0].Timestamp, data[len(data)-1].Timestamp
+		}
+		ec.trace.recordFetch(r)
+	}
 	return data, nil
 }
 
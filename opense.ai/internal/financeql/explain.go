@@ -0,0 +1,246 @@
+package financeql
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ════════════════════════════════════════════════════════════════════
+// Explain mode
+// ════════════════════════════════════════════════════════════════════
+
+// DataRange is one block of daily candles a function read while evaluating.
+type DataRange struct {
+	Ticker string    `json:"ticker"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Bars   int       `json:"bars"`
+}
+
+// FuncCall is a function call as seen by a DescribeFunc: its name, its
+// evaluated arguments (pipe input first), and the candles it fetched.
+type FuncCall struct {
+	Name   string
+	Args   []Value
+	Inputs []DataRange
+}
+
+// DescribeFunc renders a plain-English description of what one call computed.
+type DescribeFunc func(call FuncCall) string
+
+// Explanation describes one function call made while evaluating a query.
+type Explanation struct {
+	Function    string      `json:"function"`
+	Description string      `json:"description"`
+	Inputs      []DataRange `json:"inputs,omitempty"`
+}
+
+// explainTrace collects explanations during EvalQueryExplain. Screener
+// filters evaluate on copies of the context, so it is shared by pointer.
+type explainTrace struct {
+	mu      sync.Mutex
+	fetches []DataRange
+	entries []Explanation
+}
+
+func (t *explainTrace) fetchCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.fetches)
+}
+
+func (t *explainTrace) recordFetch(r DataRange) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fetches = append(t.fetches, r)
+}
+
+// record adds an explanation for a call whose fetches start at index from.
+func (t *explainTrace) record(ec *EvalContext, name string, args []Value, from int) {
+	t.mu.Lock()
+	inputs := append([]DataRange(nil), t.fetches[from:]...)
+	t.mu.Unlock()
+
+	call := FuncCall{Name: name, Args: args, Inputs: inputs}
+	describe, ok := ec.Describers[name]
+	if !ok {
+		describe = describeGeneric
+	}
+	e := Explanation{Function: name, Description: describe(call), Inputs: inputs}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, e)
+}
+
+// RegisterDescription registers the human description of a function by
+// name (lower-cased), used by EvalQueryExplain.
+func (ec *EvalContext) RegisterDescription(name string, fn DescribeFunc) {
+	if ec.Describers == nil {
+		ec.Describers = make(map[string]DescribeFunc)
+	}
+	ec.Describers[strings.ToLower(name)] = fn
+}
+
+// EvalQueryExplain evaluates query like EvalQuery and also returns, in
+// evaluation order, a description of each function call and the data it
+// read, e.g. "RSI over 14 periods of RELIANCE daily closes from …".
+func EvalQueryExplain(ec *EvalContext, query string) (Value, []Explanation, error) {
+	trace := &explainTrace{}
+	ec.trace = trace
+	defer func() { ec.trace = nil }()
+	val, err := EvalQuery(ec, query)
+	return val, trace.entries, err
+}
+
+// ────────────────────────────────────────────────────────────────────
+// Built-in descriptions
+// ────────────────────────────────────────────────────────────────────
+
+// registerDescriptions registers descriptions for the built-in functions.
+// Functions without one are described generically.
+func registerDescriptions(ec *EvalContext) {
+	quote := func(what string) DescribeFunc {
+		return func(c FuncCall) string {
+			return fmt.Sprintf("%s of %s from the latest quote", what, subject(c.Args))
+		}
+	}
+	profile := func(what string) DescribeFunc {
+		return func(c FuncCall) string {
+			return fmt.Sprintf("%s of %s from its latest company profile", what, subject(c.Args))
+		}
+	}
+	indicator := func(what string, def int) DescribeFunc {
+		return func(c FuncCall) string {
+			return fmt.Sprintf("%s over %d periods of %s", what, optionalInt(c.Args, 1, def), series(c))
+		}
+	}
+	window := func(what string, def int) DescribeFunc {
+		return func(c FuncCall) string {
+			if isPiped(c.Args) {
+				return fmt.Sprintf("%s of %s", what, series(c))
+			}
+			return fmt.Sprintf("%s of %s over the last %d days", what, series(c), optionalInt(c.Args, 1, def))
+		}
+	}
+	aggregate := func(what string) DescribeFunc {
+		return func(c FuncCall) string {
+			return fmt.Sprintf("%s of %s", what, series(c))
+		}
+	}
+
+	// ── Price & Market ──
+	ec.RegisterDescription("price", quote("Last traded price"))
+	ec.RegisterDescription("open", quote("Opening price"))
+	ec.RegisterDescription("high", quote("Day high"))
+	ec.RegisterDescription("low", quote("Day low"))
+	ec.RegisterDescription("close", quote("Last traded price"))
+	ec.RegisterDescription("volume", quote("Traded volume"))
+	ec.RegisterDescription("price_range", window("Daily closing prices", 30))
+	ec.RegisterDescription("returns", window("Day-over-day returns", 252))
+	ec.RegisterDescription("change_pct", window("Percentage change from first to last close", 30))
+
+	// ── Technical ──
+	ec.RegisterDescription("sma", indicator("Simple moving average", 20))
+	ec.RegisterDescription("ema", indicator("Exponential moving average", 20))
+	ec.RegisterDescription("wma", indicator("Weighted moving average", 20))
+	ec.RegisterDescription("hma", indicator("Hull moving average", 20))
+	ec.RegisterDescription("rsi", indicator("RSI", 14))
+	ec.RegisterDescription("atr", indicator("Average true range", 14))
+	ec.RegisterDescription("macd", func(c FuncCall) string {
+		return fmt.Sprintf("MACD (fast %d, slow %d, signal %d) of %s",
+			optionalInt(c.Args, 1, 12), optionalInt(c.Args, 2, 26), optionalInt(c.Args, 3, 9), series(c))
+	})
+	ec.RegisterDescription("bollinger", func(c FuncCall) string {
+		return fmt.Sprintf("Bollinger Bands (%d periods, %.1f standard deviations) of %s",
+			optionalInt(c.Args, 1, 20), optionalFloat(c.Args, 2, 2.0), series(c))
+	})
+
+	// ── Fundamental ──
+	ec.RegisterDescription("pe", profile("Price-to-earnings ratio"))
+	ec.RegisterDescription("pb", profile("Price-to-book ratio"))
+	ec.RegisterDescription("roe", profile("Return on equity"))
+	ec.RegisterDescription("roce", profile("Return on capital employed"))
+	ec.RegisterDescription("debt_equity", profile("Debt-to-equity ratio"))
+	ec.RegisterDescription("market_cap", profile("Market capitalisation"))
+	ec.RegisterDescription("dividend_yield", profile("Dividend yield"))
+	ec.RegisterDescription("eps", profile("Earnings per share"))
+
+	// ── Aggregation ──
+	ec.RegisterDescription("avg", aggregate("Average"))
+	ec.RegisterDescription("sum", aggregate("Sum"))
+	ec.RegisterDescription("min", aggregate("Minimum"))
+	ec.RegisterDescription("max", aggregate("Maximum"))
+	ec.RegisterDescription("stddev", aggregate("Standard deviation"))
+	ec.RegisterDescription("trend", aggregate("Trend direction"))
+}
+
+// describeGeneric describes a call by name and arguments.
+func describeGeneric(c FuncCall) string {
+	parts := make([]string, 0, len(c.Args))
+	for _, a := range c.Args {
+		parts = append(parts, describeArg(a))
+	}
+	desc := fmt.Sprintf("%s(%s)", c.Name, strings.Join(parts, ", "))
+	if s := dataSpan(c.Inputs); s != "" {
+		desc += " using " + s
+	}
+	return desc
+}
+
+// series describes the data an indicator ran on: the fetched candles, or
+// the piped-in vector.
+func series(c FuncCall) string {
+	if s := dataSpan(c.Inputs); s != "" {
+		return s
+	}
+	return subject(c.Args)
+}
+
+// dataSpan describes fetched candles, e.g. "RELIANCE daily closes from
+// 2025-01-02 to 2025-03-28 (60 bars)".
+func dataSpan(inputs []DataRange) string {
+	parts := make([]string, 0, len(inputs))
+	for _, r := range inputs {
+		if r.Bars == 0 {
+			parts = append(parts, fmt.Sprintf("%s daily closes (no data)", r.Ticker))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s daily closes from %s to %s (%d bars)",
+			r.Ticker, r.From.Format("2006-01-02"), r.To.Format("2006-01-02"), r.Bars))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// subject names what a call operated on.
+func subject(args []Value) string {
+	if len(args) == 0 {
+		return "the input"
+	}
+	return describeArg(args[0])
+}
+
+func describeArg(v Value) string {
+	switch v.Type {
+	case TypeString:
+		return ResolveTicker(v.Str)
+	case TypeScalar:
+		return FormatScalar(v.Scalar, 0)
+	case TypeVector:
+		if len(v.Vector) == 0 {
+			return "an empty series"
+		}
+		return fmt.Sprintf("a %d-point series from %s to %s", len(v.Vector),
+			v.Vector[0].Time.Format("2006-01-02"), v.Vector[len(v.Vector)-1].Time.Format("2006-01-02"))
+	case TypeTable:
+		return fmt.Sprintf("a %d-row table", len(v.Table))
+	default:
+		return v.Type.String()
+	}
+}
+
+func isPiped(args []Value) bool {
+	return len(args) > 0 && args[0].Type == TypeVector
+}
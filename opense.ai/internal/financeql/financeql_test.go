@@ -2,12 +2,17 @@ package financeql
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"math"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/seenimoa/openseai/internal/datasource"
 	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
 )
 
 // ════════════════════════════════════════════════════════════════════
@@ -848,6 +853,35 @@ func TestBuiltin_Abs(t *testing.T) {
 	assertFloat(t, 42, v.Scalar)
 }
 
+func TestBuiltin_Round(t *testing.T) {
+	ec := newTestEvalContext()
+
+	v, err := ec.Functions["round"](ec, []Value{ScalarValue(2912.4567), ScalarValue(2)})
+	assertNoErr(t, err)
+	assertFloat(t, 2912.46, v.Scalar)
+
+	v, err = ec.Functions["round"](ec, []Value{ScalarValue(-2.5)})
+	assertNoErr(t, err)
+	assertFloat(t, -3, v.Scalar) // halves round away from zero
+
+	pts := []TimePoint{{Value: 1.234}, {Value: 5.678}}
+	v, err = ec.Functions["round"](ec, []Value{VectorValue(pts), ScalarValue(1)})
+	assertNoErr(t, err)
+	assertEqual(t, TypeVector, v.Type)
+	assertFloat(t, 1.2, v.Vector[0].Value)
+	assertFloat(t, 5.7, v.Vector[1].Value)
+	assertFloat(t, 1.234, pts[0].Value) // input not modified
+
+	_, err = ec.Functions["round"](ec, []Value{ScalarValue(1), ScalarValue(-1)})
+	assertTrue(t, err != nil)
+	_, err = ec.Functions["round"](ec, nil)
+	assertTrue(t, err != nil)
+
+	val, err := EvalQuery(ec, "round(10 / 3, 2)")
+	assertNoErr(t, err)
+	assertFloat(t, 3.33, val.Scalar)
+}
+
 func TestBuiltin_Correlation(t *testing.T) {
 	ec := newTestEvalContext()
 	a := []TimePoint{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}, {Value: 5}}
@@ -1027,6 +1061,31 @@ func TestBuiltin_EMA_VectorInput(t *testing.T) {
 	}
 }
 
+func TestBuiltin_WMA_VectorInput(t *testing.T) {
+	ec := newTestEvalContext()
+	pts := []TimePoint{{Value: 10}, {Value: 20}, {Value: 30}}
+	v, err := ec.Functions["wma"](ec, []Value{VectorValue(pts), ScalarValue(3)})
+	assertNoErr(t, err)
+	assertEqual(t, TypeScalar, v.Type)
+	// (10*1 + 20*2 + 30*3) / 6
+	assertFloat(t, 140.0/6.0, v.Scalar)
+}
+
+func TestBuiltin_HMA_VectorInput(t *testing.T) {
+	ec := newTestEvalContext()
+	pts := make([]TimePoint, 50)
+	for i := range pts {
+		pts[i] = TimePoint{Value: float64(i + 1)}
+	}
+	v, err := ec.Functions["hma"](ec, []Value{VectorValue(pts), ScalarValue(16)})
+	assertNoErr(t, err)
+	assertEqual(t, TypeScalar, v.Type)
+	// HMA on a ramp tracks the latest value (50) far closer than SMA (42.5).
+	if v.Scalar < 49 || v.Scalar > 51 {
+		t.Errorf("unexpected HMA value: %f", v.Scalar)
+	}
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Evaluator Pipe Tests
 // ════════════════════════════════════════════════════════════════════
@@ -1261,7 +1320,42 @@ func TestFormatResult_Scalar(t *testing.T) {
 	repl := NewREPLWithIO(nil, in, &out)
 
 	repl.formatResult(ScalarValue(42.5))
-	assertTrue(t, strings.Contains(out.String(), "42.5000"))
+	assertTrue(t, strings.Contains(out.String(), "→ 42.50\n"))
+}
+
+func TestFormatScalar(t *testing.T) {
+	tests := []struct {
+		v         float64
+		precision int
+		want      string
+	}{
+		{2912.456, 0, "2912.46"}, // price: 2 decimals
+		{24567.8, 0, "24567.80"},
+		{-15.5, 0, "-15.50"},
+		{10, 0, "10.00"},
+		{0.123456, 0, "0.1235"}, // ratio: 4 decimals
+		{1.5, 0, "1.5000"},
+		{-0.04321, 0, "-0.0432"},
+		{0, 0, "0.0000"},
+		{2912.456, 1, "2912.5"},
+		{0.123456, 6, "0.123456"},
+	}
+	for _, tt := range tests {
+		assertEqual(t, tt.want, FormatScalar(tt.v, tt.precision))
+	}
+
+	ec := newTestEvalContext()
+	ec.Precision = 3
+	assertEqual(t, "2912.456", ec.FormatScalar(2912.456))
+}
+
+func TestREPL_Precision(t *testing.T) {
+	var out bytes.Buffer
+	repl := NewREPLWithIO(nil, strings.NewReader(".precision 1\n10 / 4\n.precision auto\n10 / 4\n.quit\n"), &out)
+	repl.Run()
+	output := out.String()
+	assertTrue(t, strings.Contains(output, "→ 2.5\n"))
+	assertTrue(t, strings.Contains(output, "→ 2.5000\n"))
 }
 
 func TestFormatResult_Vector(t *testing.T) {
@@ -1357,6 +1451,29 @@ func TestEval_ScreenerExpr(t *testing.T) {
 	assertEqual(t, TypeTable, v.Type)
 }
 
+// failingFundamentals fails every fetch.
+type failingFundamentals struct{}
+
+func (failingFundamentals) GetQuote(context.Context, string) (*models.Quote, error) {
+	return nil, errors.New("quote service down")
+}
+
+func (failingFundamentals) GetStockProfile(context.Context, string) (*models.StockProfile, error) {
+	return nil, errors.New("quote service down")
+}
+
+func TestEval_ScreenerAllFetchesFail(t *testing.T) {
+	ec := newTestEvalContext()
+	ec.Ctx = context.Background()
+	ec.Fundamentals = failingFundamentals{}
+	ec.Universe = []string{"TCS", "INFY"}
+
+	_, err := EvalQuery(ec, `screener(pe < 30)`)
+	if err == nil || !strings.Contains(err.Error(), "quote service down") {
+		t.Fatalf("expected the fetch error, got %v", err)
+	}
+}
+
 func TestEval_AlertExpr_Triggered(t *testing.T) {
 	ec := newTestEvalContext()
 	v, err := EvalQuery(ec, `alert(5 > 3, "high!")`)
@@ -1443,6 +1560,36 @@ func TestResolveTicker(t *testing.T) {
 	assertEqual(t, "INFY", ResolveTicker(" infy "))
 }
 
+// rejectingResolver knows every symbol except those in unknown.
+type rejectingResolver struct {
+	unknown map[string]bool
+}
+
+func (r rejectingResolver) Resolve(_ context.Context, ticker string) (string, error) {
+	if r.unknown[ticker] {
+		return "", fmt.Errorf("%w %q", ErrUnknownSymbol, ticker)
+	}
+	return ticker, nil
+}
+
+func TestEval_ResolverRejectsUnknownTicker(t *testing.T) {
+	fake := newCountingFundamentals()
+	ec := newTestEvalContext()
+	ec.Ctx = context.Background()
+	ec.Fundamentals = fake
+	ec.Resolver = rejectingResolver{unknown: map[string]bool{"FAKE123": true}}
+
+	_, err := EvalQuery(ec, `price(FAKE123)`)
+	if !errors.Is(err, ErrUnknownSymbol) {
+		t.Fatalf("expected ErrUnknownSymbol, got %v", err)
+	}
+	assertEqual(t, 0, fake.quotes["FAKE123"])
+
+	_, err = EvalQuery(ec, `price(tcs)`)
+	assertNoErr(t, err)
+	assertEqual(t, 1, fake.quotes["TCS"])
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Helper Utilities Tests
 // ════════════════════════════════════════════════════════════════════
@@ -1547,6 +1694,343 @@ func TestParseError_Error(t *testing.T) {
 	assertTrue(t, strings.Contains(s, "col 5"))
 }
 
+// ════════════════════════════════════════════════════════════════════
+// Corporate Events
+// ════════════════════════════════════════════════════════════════════
+
+type fakeEventSource struct {
+	events []models.CorporateEvent
+}
+
+func (f *fakeEventSource) GetCorporateEvents(_ context.Context, _ string) ([]models.CorporateEvent, error) {
+	return f.events, nil
+}
+
+func TestEval_NextEarnings(t *testing.T) {
+	now := utils.NowIST()
+	later := now.AddDate(0, 0, 40)
+	sooner := now.AddDate(0, 0, 5)
+
+	agg := datasource.NewAggregator()
+	agg.SetCorporateEventSource(&fakeEventSource{events: []models.CorporateEvent{
+		{Ticker: "TCS", Type: models.EventEarnings, Date: later},
+		{Ticker: "TCS", Type: models.EventEarnings, Date: sooner},
+	}})
+	ec := NewEvalContext(context.Background(), agg)
+
+	v, err := EvalQuery(ec, `next_earnings(TCS)`)
+	assertNoErr(t, err)
+	assertEqual(t, TypeString, v.Type)
+	assertEqual(t, utils.FormatDateIST(sooner), v.Str)
+}
+
+func TestEval_NextEarningsNoneScheduled(t *testing.T) {
+	agg := datasource.NewAggregator()
+	agg.SetCorporateEventSource(&fakeEventSource{events: []models.CorporateEvent{
+		{Ticker: "TCS", Type: models.EventEarnings, Date: utils.NowIST().AddDate(0, -2, 0)},
+		{Ticker: "TCS", Type: models.EventDividend, Date: utils.NowIST().AddDate(0, 0, 3)},
+	}})
+	ec := NewEvalContext(context.Background(), agg)
+
+	v, err := EvalQuery(ec, `next_earnings("TCS")`)
+	assertNoErr(t, err)
+	assertEqual(t, TypeNil, v.Type)
+}
+
+// countingFundamentals serves fixed quotes and ratios and counts fetches per ticker.
+type countingFundamentals struct {
+	pe       map[string]float64
+	roe      map[string]float64
+	quotes   map[string]int
+	profiles map[string]int
+}
+
+func newCountingFundamentals() *countingFundamentals {
+	return &countingFundamentals{
+		pe:       map[string]float64{"TCS": 12, "INFY": 25, "ITC": 10},
+		roe:      map[string]float64{"TCS": 40, "INFY": 30, "ITC": 15},
+		quotes:   make(map[string]int),
+		profiles: make(map[string]int),
+	}
+}
+
+func (f *countingFundamentals) GetQuote(_ context.Context, ticker string) (*models.Quote, error) {
+	f.quotes[ticker]++
+	return &models.Quote{Ticker: ticker, PE: f.pe[ticker]}, nil
+}
+
+func (f *countingFundamentals) GetStockProfile(_ context.Context, ticker string) (*models.StockProfile, error) {
+	f.profiles[ticker]++
+	return &models.StockProfile{Ratios: &models.FinancialRatios{ROE: f.roe[ticker]}}, nil
+}
+
+func TestEval_ScreenerFetchesOncePerTicker(t *testing.T) {
+	fake := newCountingFundamentals()
+	ec := newTestEvalContext()
+	ec.Ctx = context.Background()
+	ec.Fundamentals = fake
+	ec.Universe = []string{"TCS", "INFY", "ITC"}
+
+	v, err := EvalQuery(ec, `screener(pe < 15 AND roe > 20 AND pe(*) > 0 AND roe(*) > 0)`)
+	assertNoErr(t, err)
+	assertEqual(t, TypeTable, v.Type)
+	assertEqual(t, 1, len(v.Table))
+	assertEqual(t, "TCS", v.Table[0]["ticker"].(string))
+
+	for _, ticker := range ec.Universe {
+		assertEqual(t, 1, fake.quotes[ticker])
+		assertEqual(t, 1, fake.profiles[ticker])
+	}
+
+	// The memo lasts for one evaluation only.
+	_, err = EvalQuery(ec, `pe(TCS)`)
+	assertNoErr(t, err)
+	assertEqual(t, 2, fake.quotes["TCS"])
+}
+
+// dividendFundamentals serves a ₹500 quote and financials with a ₹25 EPS
+// and ₹10 of dividends per share, reported either per share or only as
+// total dividends paid.
+type dividendFundamentals struct {
+	perShare bool
+}
+
+func (f dividendFundamentals) GetQuote(_ context.Context, ticker string) (*models.Quote, error) {
+	return &models.Quote{Ticker: ticker, LastPrice: 500, DividendYield: 1.1}, nil
+}
+
+func (f dividendFundamentals) GetStockProfile(_ context.Context, ticker string) (*models.StockProfile, error) {
+	fin := &models.FinancialData{
+		AnnualIncome:   []models.IncomeStatement{{PAT: 2500, EPS: 25}},
+		AnnualCashFlow: []models.CashFlow{{DividendsPaid: -1000}},
+	}
+	if f.perShare {
+		fin.AnnualIncome[0].DividendPerShare = 10
+		fin.AnnualCashFlow = nil
+	}
+	return &models.StockProfile{Financials: fin}, nil
+}
+
+func TestEval_DividendMetrics(t *testing.T) {
+	for _, perShare := range []bool{true, false} {
+		ec := newTestEvalContext()
+		ec.Ctx = context.Background()
+		ec.Fundamentals = dividendFundamentals{perShare: perShare}
+
+		v, err := EvalQuery(ec, `dividend_yield(ITC)`)
+		assertNoErr(t, err)
+		assertFloat(t, 10.0/500*100, v.Scalar)
+
+		v, err = EvalQuery(ec, `payout_ratio(ITC)`)
+		assertNoErr(t, err)
+		assertFloat(t, 10.0/25*100, v.Scalar)
+	}
+
+	// Without dividend data the quoted yield is used.
+	ec := newTestEvalContext()
+	ec.Ctx = context.Background()
+	ec.Fundamentals = quoteOnlyFundamentals{}
+	v, err := EvalQuery(ec, `dividend_yield(ITC)`)
+	assertNoErr(t, err)
+	assertFloat(t, 1.1, v.Scalar)
+	v, err = EvalQuery(ec, `payout_ratio(ITC)`)
+	assertNoErr(t, err)
+	assertFloat(t, 0, v.Scalar)
+}
+
+// quoteOnlyFundamentals serves dividendFundamentals quotes with empty profiles.
+type quoteOnlyFundamentals struct{ dividendFundamentals }
+
+func (quoteOnlyFundamentals) GetStockProfile(_ context.Context, _ string) (*models.StockProfile, error) {
+	return &models.StockProfile{}, nil
+}
+
+// routedQuoteSource is a DataSource that serves only quotes, with a fixed PE.
+type routedQuoteSource struct{}
+
+func (routedQuoteSource) Name() string { return "routed" }
+func (routedQuoteSource) GetQuote(_ context.Context, ticker string) (*models.Quote, error) {
+	return &models.Quote{Ticker: ticker, PE: 7}, nil
+}
+func (routedQuoteSource) GetHistoricalData(context.Context, string, time.Time, time.Time, models.Timeframe) ([]models.OHLCV, error) {
+	return nil, datasource.ErrNotSupported
+}
+func (routedQuoteSource) GetFinancials(context.Context, string) (*models.FinancialData, error) {
+	return nil, datasource.ErrNotSupported
+}
+func (routedQuoteSource) GetOptionChain(context.Context, string, string) (*models.OptionChain, error) {
+	return nil, datasource.ErrNotSupported
+}
+func (routedQuoteSource) GetStockProfile(context.Context, string) (*models.StockProfile, error) {
+	return nil, datasource.ErrNotSupported
+}
+
+func TestEval_QuotesFollowSourceRoute(t *testing.T) {
+	agg := datasource.NewAggregator()
+	agg.RegisterSource("routed", routedQuoteSource{})
+	assertNoErr(t, agg.UseSource(datasource.CategoryQuotes, "routed"))
+	ec := NewEvalContext(context.Background(), agg)
+
+	v, err := EvalQuery(ec, `pe(TCS)`)
+	assertNoErr(t, err)
+	assertFloat(t, 7, v.Scalar)
+}
+
+func TestDownsample(t *testing.T) {
+	start := time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)
+	pts := make([]TimePoint, 1000)
+	for i := range pts {
+		pts[i] = TimePoint{Time: start.AddDate(0, 0, i), Value: 100 + 10*math.Sin(float64(i)/25)}
+	}
+	pts[437].Value = 250 // spike
+	pts[812].Value = -40 // crash
+
+	got := Downsample(pts, 200)
+	if len(got) > 200 || len(got) < 190 {
+		t.Fatalf("got %d points, want ~200", len(got))
+	}
+	assertEqual(t, pts[0], got[0])
+	assertEqual(t, pts[999], got[len(got)-1])
+
+	var sawSpike, sawCrash bool
+	for i, p := range got {
+		if i > 0 && !p.Time.After(got[i-1].Time) {
+			t.Fatalf("points out of order at %d", i)
+		}
+		sawSpike = sawSpike || p == pts[437]
+		sawCrash = sawCrash || p == pts[812]
+	}
+	assertTrue(t, sawSpike)
+	assertTrue(t, sawCrash)
+
+	// Series that already fit are returned as is.
+	assertEqual(t, 1000, len(Downsample(pts, 1000)))
+	assertEqual(t, 1000, len(Downsample(pts, 0)))
+}
+
+// fakeHistory serves a constant daily volume per ticker at a close of 100.
+type fakeHistory map[string]int64
+
+func (f fakeHistory) GetHistoricalData(_ context.Context, ticker string, from, to time.Time, _ models.Timeframe) ([]models.OHLCV, error) {
+	var bars []models.OHLCV
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		bars = append(bars, models.OHLCV{Timestamp: d, Close: 100, Volume: f[ticker]})
+	}
+	return bars, nil
+}
+
+func TestEval_ScreenerLiquidityFilter(t *testing.T) {
+	ec := newTestEvalContext()
+	ec.Ctx = context.Background()
+	ec.Fundamentals = newCountingFundamentals()
+	ec.History = fakeHistory{"TCS": 2_000_000, "INFY": 1_500_000, "ITC": 500}
+	ec.Universe = []string{"TCS", "INFY", "ITC"}
+
+	v, err := EvalQuery(ec, `screener(pe < 30)`)
+	assertNoErr(t, err)
+	assertEqual(t, 3, len(v.Table))
+
+	// ITC trades ₹50,000 a day, below the ₹1 crore floor.
+	ec.Liquidity = datasource.LiquidityFilter{MinTurnover: 1e7}
+	v, err = EvalQuery(ec, `screener(pe < 30)`)
+	assertNoErr(t, err)
+	assertEqual(t, 2, len(v.Table))
+	for _, row := range v.Table {
+		if row["ticker"] == "ITC" {
+			t.Errorf("illiquid ITC should be filtered out, got %v", v.Table)
+		}
+	}
+
+	v, err = EvalQuery(ec, `liquidity(ITC)`)
+	assertNoErr(t, err)
+	assertFloat(t, 50_000, v.Scalar)
+}
+
+// seriesHistory serves fixed bars per ticker, whatever the range.
+type seriesHistory map[string][]models.OHLCV
+
+func (s seriesHistory) GetHistoricalData(_ context.Context, ticker string, _, _ time.Time, _ models.Timeframe) ([]models.OHLCV, error) {
+	return s[ticker], nil
+}
+
+func TestEval_PairsSpreadAndRatio(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, utils.IST) }
+	bars := func(days []int, closes []float64) []models.OHLCV {
+		out := make([]models.OHLCV, len(days))
+		for i := range days {
+			out[i] = models.OHLCV{Timestamp: day(days[i]), Close: closes[i]}
+		}
+		return out
+	}
+	ec := newTestEvalContext()
+	ec.Ctx = context.Background()
+	// HDFCBANK has no bar on the 5th; ICICIBANK none on the 6th.
+	ec.History = seriesHistory{
+		"HDFCBANK":  bars([]int{3, 4, 6, 7}, []float64{1600, 1640, 1680, 1720}),
+		"ICICIBANK": bars([]int{3, 4, 5, 7}, []float64{1000, 1000, 1010, 1050}),
+	}
+	wantDays := []int{3, 4, 7}
+
+	v, err := EvalQuery(ec, `ratio(HDFCBANK, ICICIBANK)`)
+	assertNoErr(t, err)
+	assertEqual(t, TypeVector, v.Type)
+	assertEqual(t, len(wantDays), len(v.Vector))
+	for i, want := range []float64{1.6, 1.64, 1720.0 / 1050} {
+		assertTrue(t, v.Vector[i].Time.Equal(day(wantDays[i])))
+		assertFloat(t, want, v.Vector[i].Value)
+	}
+
+	// Rebased to 100: HDFCBANK 100, 102.5, 107.5; ICICIBANK 100, 100, 105.
+	v, err = EvalQuery(ec, `spread(HDFCBANK, ICICIBANK)`)
+	assertNoErr(t, err)
+	assertEqual(t, len(wantDays), len(v.Vector))
+	for i, want := range []float64{0, 2.5, 2.5} {
+		assertTrue(t, v.Vector[i].Time.Equal(day(wantDays[i])))
+		assertFloat(t, want, v.Vector[i].Value)
+	}
+
+	_, err = EvalQuery(ec, `ratio(HDFCBANK)`)
+	assertTrue(t, err != nil)
+	v, err = EvalQuery(ec, `spread(HDFCBANK)`)
+	assertTrue(t, err != nil)
+	assertEqual(t, TypeNil, v.Type)
+}
+
+func TestEval_Volatility(t *testing.T) {
+	// Closes alternate ±1% and ±3%, so every daily return has that magnitude.
+	bars := func(move float64) []models.OHLCV {
+		out := make([]models.OHLCV, 80)
+		price := 100.0
+		for i := range out {
+			out[i] = models.OHLCV{Timestamp: time.Date(2025, 1, 1+i, 0, 0, 0, 0, utils.IST), Close: price}
+			if i%2 == 0 {
+				price *= 1 + move
+			} else {
+				price *= 1 - move
+			}
+		}
+		return out
+	}
+	ec := newTestEvalContext()
+	ec.Ctx = context.Background()
+	ec.History = seriesHistory{"TCS": bars(0.01), "ADANIENT": bars(0.03)}
+
+	calm, err := EvalQuery(ec, `volatility(TCS, 20)`)
+	assertNoErr(t, err)
+	assertEqual(t, TypeScalar, calm.Type)
+	assertFloat(t, 0.01*math.Sqrt(252), calm.Scalar)
+
+	wild, err := EvalQuery(ec, `volatility(ADANIENT)`)
+	assertNoErr(t, err)
+	assertFloat(t, 0.03*math.Sqrt(252), wild.Scalar)
+	assertTrue(t, wild.Scalar > calm.Scalar)
+
+	for _, q := range []string{`volatility(TCS, 1)`, `volatility(TCS, 0)`, `volatility(TCS, 2000)`, `volatility(TCS, 2.5)`} {
+		_, err := EvalQuery(ec, q)
+		assertTrue(t, err != nil)
+	}
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Test Helpers
 // ════════════════════════════════════════════════════════════════════
@@ -1578,3 +2062,163 @@ func assertNoErr(t *testing.T, err error) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestEval_Shift(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, utils.IST) }
+	ec := newTestEvalContext()
+	ec.Ctx = context.Background()
+	ec.History = seriesHistory{"TCS": {
+		{Timestamp: day(3), Close: 100},
+		{Timestamp: day(4), Close: 101},
+		{Timestamp: day(5), Close: 103},
+		{Timestamp: day(6), Close: 106},
+	}}
+
+	v, err := EvalQuery(ec, `price_range(TCS, 10) | shift(1)`)
+	assertNoErr(t, err)
+	assertEqual(t, TypeVector, v.Type)
+	assertEqual(t, 3, len(v.Vector))
+	for i, want := range []float64{100, 101, 103} {
+		assertTrue(t, v.Vector[i].Time.Equal(day(i+4)))
+		assertFloat(t, want, v.Vector[i].Value)
+	}
+
+	// One-day change as the series minus its own lag.
+	v, err = EvalQuery(ec, `last(price_range(TCS, 10)) - last(shift(price_range(TCS, 10), 1))`)
+	assertNoErr(t, err)
+	assertFloat(t, 3, v.Scalar)
+
+	v, err = EvalQuery(ec, `shift(price_range(TCS, 10), 10)`)
+	assertNoErr(t, err)
+	assertEqual(t, TypeVector, v.Type)
+	assertEqual(t, 0, len(v.Vector))
+
+	_, err = EvalQuery(ec, `shift(price_range(TCS, 10), -1)`)
+	assertTrue(t, err != nil)
+	_, err = EvalQuery(ec, `shift(42, 1)`)
+	assertTrue(t, err != nil)
+}
+
+func TestEval_AsOf(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, utils.IST) }
+	ec := newTestEvalContext()
+	ec.Ctx = context.Background()
+	ec.History = seriesHistory{"INFY": {
+		{Timestamp: day(1, 1), Close: 1500},
+		{Timestamp: day(1, 2), Close: 1520},
+		{Timestamp: day(1, 5), Close: 1490}, // Friday
+		{Timestamp: day(1, 8), Close: 1530}, // Monday
+	}}
+
+	v, err := EvalQuery(ec, `asof(INFY, "2024-01-02")`)
+	assertNoErr(t, err)
+	assertFloat(t, 1520, v.Scalar)
+
+	// A weekend date takes Friday's close.
+	v, err = EvalQuery(ec, `asof(INFY, "2024-01-07")`)
+	assertNoErr(t, err)
+	assertFloat(t, 1490, v.Scalar)
+
+	_, err = EvalQuery(ec, `asof(INFY, "2023-12-01")`)
+	assertTrue(t, err != nil)
+	_, err = EvalQuery(ec, `asof(INFY, "2024-13-01")`)
+	assertTrue(t, err != nil)
+	_, err = EvalQuery(ec, `asof(INFY, "2999-01-01")`)
+	assertTrue(t, err != nil)
+}
+
+func TestEval_RSRank(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, utils.IST) }
+	series := func(closes ...float64) []models.OHLCV {
+		out := make([]models.OHLCV, len(closes))
+		for i, c := range closes {
+			out[i] = models.OHLCV{Timestamp: day(i + 3), Close: c}
+		}
+		return out
+	}
+	ec := newTestEvalContext()
+	ec.Ctx = context.Background()
+	ec.History = seriesHistory{
+		"TCS":   series(100, 102, 105), // +5%
+		"INFY":  series(200, 190, 180), // -10%
+		"WIPRO": series(50, 55, 60),    // +20%
+		"HCL":   series(80),            // too short to rank
+	}
+	ec.Universes = map[string][]string{"it": {"TCS", "INFY", "WIPRO", "HCL"}}
+
+	for _, query := range []string{`rs_rank("it", 30)`, `rs_rank(universe("it"), "30d")`} {
+		v, err := EvalQuery(ec, query)
+		assertNoErr(t, err)
+		assertEqual(t, TypeTable, v.Type)
+		assertEqual(t, 3, len(v.Table))
+
+		want := []struct {
+			ticker      string
+			ret, pctile float64
+		}{
+			{"WIPRO", 20, 100},
+			{"TCS", 5, 50},
+			{"INFY", -10, 0},
+		}
+		for i, w := range want {
+			row := v.Table[i]
+			assertEqual(t, w.ticker, row["ticker"].(string))
+			assertEqual(t, i+1, row["rank"].(int))
+			assertFloat(t, w.ret, row["return_pct"].(float64))
+			assertFloat(t, w.pctile, row["percentile"].(float64))
+		}
+	}
+
+	_, err := EvalQuery(ec, `rs_rank("it", 0)`)
+	assertTrue(t, err != nil)
+
+	// No ticker with enough history is an error, not an empty ranking.
+	ec.Universes["short"] = []string{"HCL", "TECHM"}
+	_, err = EvalQuery(ec, `rs_rank("short")`)
+	if err == nil || !strings.Contains(err.Error(), "no ticker could be ranked") {
+		t.Fatalf("expected an error when nothing is ranked, got %v", err)
+	}
+}
+
+func TestEval_UniverseFunction(t *testing.T) {
+	ec := newTestEvalContext()
+	ec.Universes = map[string][]string{"it": {"TCS", "INFY.NS", "WIPRO"}}
+
+	v, err := EvalQuery(ec, `universe("IT")`)
+	assertNoErr(t, err)
+	assertEqual(t, TypeTable, v.Type)
+	assertEqual(t, 3, len(v.Table))
+	assertEqual(t, "INFY", v.Table[1]["ticker"].(string))
+
+	_, err = EvalQuery(ec, `universe("pharma")`)
+	assertTrue(t, err != nil && strings.Contains(err.Error(), "available: it"))
+}
+
+func TestEvalQueryExplain_RSI(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, 1, 0, 0, 0, 0, utils.IST).AddDate(0, 0, d) }
+	var bars []models.OHLCV
+	for i := 0; i < 70; i++ {
+		bars = append(bars, models.OHLCV{Timestamp: day(i), Close: 100 + float64(i%7)})
+	}
+	ec := newTestEvalContext()
+	ec.Ctx = context.Background()
+	ec.History = seriesHistory{"RELIANCE": bars}
+
+	v, explanations, err := EvalQueryExplain(ec, `rsi(RELIANCE, 14)`)
+	assertNoErr(t, err)
+	assertEqual(t, TypeScalar, v.Type)
+	assertEqual(t, 1, len(explanations))
+
+	e := explanations[0]
+	assertEqual(t, "rsi", e.Function)
+	for _, want := range []string{"14 periods", "RELIANCE", "2025-03-01", "2025-05-09"} {
+		if !strings.Contains(e.Description, want) {
+			t.Errorf("description %q should mention %q", e.Description, want)
+		}
+	}
+	assertEqual(t, 1, len(e.Inputs))
+	assertEqual(t, 70, e.Inputs[0].Bars)
+
+	// Explaining is off for plain evaluations.
+	assertTrue(t, ec.trace == nil)
+}
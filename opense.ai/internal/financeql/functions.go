@@ -5,9 +5,13 @@ import (
 	"math"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/seenimoa/openseai/internal/analysis/fundamental"
 	"github.com/seenimoa/openseai/internal/analysis/technical"
+	"github.com/seenimoa/openseai/internal/datasource"
 	"github.com/seenimoa/openseai/pkg/models"
+	"github.com/seenimoa/openseai/pkg/utils"
 )
 
 // ════════════════════════════════════════════════════════════════════
@@ -25,19 +29,28 @@ func RegisterBuiltins(ec *EvalContext) {
 	ec.RegisterFunc("close", fnClose)
 	ec.RegisterFunc("volume", fnVolume)
 	ec.RegisterFunc("volume_range", fnVolumeRange)
+	ec.RegisterFunc("liquidity", fnLiquidity)
 	ec.RegisterFunc("returns", fnReturns)
 	ec.RegisterFunc("change_pct", fnChangePct)
 	ec.RegisterFunc("vix", fnVIX)
+	ec.RegisterFunc("spread", fnSpread)
+	ec.RegisterFunc("ratio", fnRatio)
+	ec.RegisterFunc("asof", fnAsOf)
 
 	// ── Technical Indicator Functions ────────────────────────────
 	ec.RegisterFunc("sma", fnSMA)
 	ec.RegisterFunc("ema", fnEMA)
+	ec.RegisterFunc("wma", fnWMA)
+	ec.RegisterFunc("hma", fnHMA)
 	ec.RegisterFunc("rsi", fnRSI)
 	ec.RegisterFunc("rsi_range", fnRSIRange)
 	ec.RegisterFunc("macd", fnMACD)
 	ec.RegisterFunc("bollinger", fnBollinger)
+	ec.RegisterFunc("keltner", fnKeltner)
+	ec.RegisterFunc("squeeze", fnSqueeze)
 	ec.RegisterFunc("supertrend", fnSuperTrend)
 	ec.RegisterFunc("atr", fnATR)
+	ec.RegisterFunc("volatility", fnVolatility)
 	ec.RegisterFunc("vwap", fnVWAP)
 	ec.RegisterFunc("crossover", fnCrossover)
 	ec.RegisterFunc("crossunder", fnCrossunder)
@@ -50,10 +63,12 @@ func RegisterBuiltins(ec *EvalContext) {
 	ec.RegisterFunc("debt_equity", fnDebtEquity)
 	ec.RegisterFunc("market_cap", fnMarketCap)
 	ec.RegisterFunc("dividend_yield", fnDividendYield)
+	ec.RegisterFunc("payout_ratio", fnPayoutRatio)
 	ec.RegisterFunc("promoter_holding", fnPromoterHolding)
 	ec.RegisterFunc("eve_ebitda", fnEVEBITDA)
 	ec.RegisterFunc("eps", fnEPS)
 	ec.RegisterFunc("book_value", fnBookValue)
+	ec.RegisterFunc("next_earnings", fnNextEarnings)
 
 	// ── Aggregation & Math Functions ─────────────────────────────
 	ec.RegisterFunc("avg", fnAvg)
@@ -64,10 +79,13 @@ func RegisterBuiltins(ec *EvalContext) {
 	ec.RegisterFunc("percentile", fnPercentile)
 	ec.RegisterFunc("correlation", fnCorrelation)
 	ec.RegisterFunc("abs", fnAbs)
+	ec.RegisterFunc("round", fnRound)
 
 	// ── Screening & Filtering ────────────────────────────────────
 	ec.RegisterFunc("nifty50", fnNifty50)
 	ec.RegisterFunc("niftybank", fnNiftyBank)
+	ec.RegisterFunc("universe", fnUniverse)
+	ec.RegisterFunc("rs_rank", fnRSRank)
 	ec.RegisterFunc("sector", fnSector)
 	ec.RegisterFunc("sort", fnSort)
 	ec.RegisterFunc("top", fnTop)
@@ -79,22 +97,22 @@ func RegisterBuiltins(ec *EvalContext) {
 	ec.RegisterFunc("count", fnCount)
 	ec.RegisterFunc("last", fnLast)
 	ec.RegisterFunc("first", fnFirst)
+	ec.RegisterFunc("shift", fnShift)
 
-	// ── Screener internal ────────────────────────────────────────
-	ec.RegisterFunc("_screener", fnScreenerInternal)
+	registerDescriptions(ec)
 }
 
 // ════════════════════════════════════════════════════════════════════
 // Argument helpers
 // ════════════════════════════════════════════════════════════════════
 
-func requireTicker(args []Value, pos int) (string, error) {
+func requireTicker(ec *EvalContext, args []Value, pos int) (string, error) {
 	if pos >= len(args) {
 		return "", fmt.Errorf("missing ticker argument at position %d", pos)
 	}
 	v := args[pos]
 	if v.Type == TypeString {
-		return ResolveTicker(v.Str), nil
+		return ec.resolveTicker(v.Str)
 	}
 	return "", fmt.Errorf("expected ticker string at position %d, got %s", pos, v.Type)
 }
@@ -143,7 +161,7 @@ func fetchCandles(ec *EvalContext, ticker string, days int) ([]models.OHLCV, err
 
 // price(TICKER) → latest closing price
 func fnPrice(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -153,7 +171,7 @@ func fnPrice(ec *EvalContext, args []Value) (Value, error) {
 		return v, nil
 	}
 
-	quote, err := ec.Aggregator.YFinance().GetQuote(ec.Ctx, ticker)
+	quote, err := ec.fetchQuote(ticker)
 	if err != nil {
 		return NilValue(), fmt.Errorf("failed to get quote for %s: %w", ticker, err)
 	}
@@ -164,7 +182,7 @@ func fnPrice(ec *EvalContext, args []Value) (Value, error) {
 
 // price_range(TICKER, days) → price time-series
 func fnPriceRange(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -178,11 +196,11 @@ func fnPriceRange(ec *EvalContext, args []Value) (Value, error) {
 }
 
 func fnOpen(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
-	quote, err := ec.Aggregator.YFinance().GetQuote(ec.Ctx, ticker)
+	quote, err := ec.fetchQuote(ticker)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -190,11 +208,11 @@ func fnOpen(ec *EvalContext, args []Value) (Value, error) {
 }
 
 func fnHigh(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
-	quote, err := ec.Aggregator.YFinance().GetQuote(ec.Ctx, ticker)
+	quote, err := ec.fetchQuote(ticker)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -202,11 +220,11 @@ func fnHigh(ec *EvalContext, args []Value) (Value, error) {
 }
 
 func fnLow(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
-	quote, err := ec.Aggregator.YFinance().GetQuote(ec.Ctx, ticker)
+	quote, err := ec.fetchQuote(ticker)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -218,11 +236,11 @@ func fnClose(ec *EvalContext, args []Value) (Value, error) {
 }
 
 func fnVolume(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
-	quote, err := ec.Aggregator.YFinance().GetQuote(ec.Ctx, ticker)
+	quote, err := ec.fetchQuote(ticker)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -230,7 +248,7 @@ func fnVolume(ec *EvalContext, args []Value) (Value, error) {
 }
 
 func fnVolumeRange(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -246,6 +264,36 @@ func fnVolumeRange(ec *EvalContext, args []Value) (Value, error) {
 	return VectorValue(pts), nil
 }
 
+// liquidityLookbackDays is the calendar-day window over which liquidity()
+// and the screener's liquidity filter average volume and turnover.
+const liquidityLookbackDays = 30
+
+// liquidity(TICKER, days=30) → average daily turnover in INR
+func fnLiquidity(ec *EvalContext, args []Value) (Value, error) {
+	ticker, err := requireTicker(ec, args, 0)
+	if err != nil {
+		return NilValue(), err
+	}
+	liq, err := tickerLiquidity(ec, ticker, optionalInt(args, 1, liquidityLookbackDays))
+	if err != nil {
+		return NilValue(), err
+	}
+	return ScalarValue(liq.AvgTurnover), nil
+}
+
+// tickerLiquidity measures ticker's average daily volume and turnover over
+// the last days calendar days.
+func tickerLiquidity(ec *EvalContext, ticker string, days int) (datasource.Liquidity, error) {
+	data, err := fetchCandles(ec, ticker, days)
+	if err != nil {
+		return datasource.Liquidity{}, err
+	}
+	if len(data) == 0 {
+		return datasource.Liquidity{}, fmt.Errorf("no trading history for %s", ticker)
+	}
+	return datasource.MeasureLiquidity(data), nil
+}
+
 func fnReturns(ec *EvalContext, args []Value) (Value, error) {
 	// If pipe input is a vector, compute returns
 	if len(args) > 0 && args[0].Type == TypeVector {
@@ -265,7 +313,7 @@ func fnReturns(ec *EvalContext, args []Value) (Value, error) {
 		return VectorValue(ret), nil
 	}
 
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -303,7 +351,7 @@ func fnChangePct(ec *EvalContext, args []Value) (Value, error) {
 		}
 		return ScalarValue(0), nil
 	}
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -335,6 +383,87 @@ func fnVIX(ec *EvalContext, args []Value) (Value, error) {
 	return ScalarValue(quote.LastPrice), nil
 }
 
+// pairLookbackDays is the default calendar-day window of spread() and ratio().
+const pairLookbackDays = 90
+
+// spread(A, B, days=90) → A − B, each rebased to 100 on the first common date
+func fnSpread(ec *EvalContext, args []Value) (Value, error) {
+	times, a, b, err := alignedPair(ec, "spread", args)
+	if err != nil {
+		return NilValue(), err
+	}
+	if len(times) == 0 {
+		return VectorValue(nil), nil
+	}
+	if a[0] == 0 || b[0] == 0 {
+		return NilValue(), fmt.Errorf("spread: series start at zero and cannot be rebased")
+	}
+	pts := make([]TimePoint, len(times))
+	for i, t := range times {
+		pts[i] = TimePoint{Time: t, Value: 100*a[i]/a[0] - 100*b[i]/b[0]}
+	}
+	return VectorValue(pts), nil
+}
+
+// ratio(A, B, days=90) → A / B at each common date
+func fnRatio(ec *EvalContext, args []Value) (Value, error) {
+	times, a, b, err := alignedPair(ec, "ratio", args)
+	if err != nil {
+		return NilValue(), err
+	}
+	pts := make([]TimePoint, 0, len(times))
+	for i, t := range times {
+		if b[i] != 0 {
+			pts = append(pts, TimePoint{Time: t, Value: a[i] / b[i]})
+		}
+	}
+	return VectorValue(pts), nil
+}
+
+// alignedPair resolves the two series of a pairs function — two tickers,
+// whose closes over the last days are fetched, or two vectors — and keeps
+// only the dates (in IST) present in both, in the first series' order.
+func alignedPair(ec *EvalContext, name string, args []Value) ([]time.Time, []float64, []float64, error) {
+	if len(args) < 2 {
+		return nil, nil, nil, fmt.Errorf("%s: expected two tickers or two vectors", name)
+	}
+	series := make([][]TimePoint, 2)
+	for i, arg := range args[:2] {
+		switch arg.Type {
+		case TypeVector:
+			series[i] = arg.Vector
+		case TypeString:
+			ticker, err := ec.resolveTicker(arg.Str)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			data, err := fetchCandles(ec, ticker, optionalInt(args, 2, pairLookbackDays))
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			series[i] = OHLCVToVector(data)
+		default:
+			return nil, nil, nil, fmt.Errorf("%s: expected ticker or vector at position %d, got %s", name, i, arg.Type)
+		}
+	}
+
+	dateKey := func(t time.Time) string { return t.In(utils.IST).Format("2006-01-02") }
+	other := make(map[string]float64, len(series[1]))
+	for _, p := range series[1] {
+		other[dateKey(p.Time)] = p.Value
+	}
+	var times []time.Time
+	var a, b []float64
+	for _, p := range series[0] {
+		if v, ok := other[dateKey(p.Time)]; ok {
+			times = append(times, p.Time)
+			a = append(a, p.Value)
+			b = append(b, v)
+		}
+	}
+	return times, a, b, nil
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Technical Indicator Functions
 // ════════════════════════════════════════════════════════════════════
@@ -352,7 +481,7 @@ func fnSMA(ec *EvalContext, args []Value) (Value, error) {
 	}
 
 	// sma(TICKER, period)
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -377,7 +506,7 @@ func fnEMA(ec *EvalContext, args []Value) (Value, error) {
 		return ScalarValue(result[len(result)-1]), nil
 	}
 
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -391,8 +520,58 @@ func fnEMA(ec *EvalContext, args []Value) (Value, error) {
 	return ScalarValue(val), nil
 }
 
+func fnWMA(ec *EvalContext, args []Value) (Value, error) {
+	if len(args) > 0 && args[0].Type == TypeVector {
+		data := vectorToFloat64(args[0].Vector)
+		period := optionalInt(args, 1, 20)
+		result := technical.WMA(data, period)
+		if result == nil {
+			return NilValue(), nil
+		}
+		return ScalarValue(result[len(result)-1]), nil
+	}
+
+	ticker, err := requireTicker(ec, args, 0)
+	if err != nil {
+		return NilValue(), err
+	}
+	period := optionalInt(args, 1, 20)
+	candles, err := fetchCandles(ec, ticker, period*3)
+	if err != nil {
+		return NilValue(), err
+	}
+	closes := ohlcvCloses(candles)
+	val := technical.WMALatest(closes, period)
+	return ScalarValue(val), nil
+}
+
+func fnHMA(ec *EvalContext, args []Value) (Value, error) {
+	if len(args) > 0 && args[0].Type == TypeVector {
+		data := vectorToFloat64(args[0].Vector)
+		period := optionalInt(args, 1, 20)
+		result := technical.HMA(data, period)
+		if result == nil {
+			return NilValue(), nil
+		}
+		return ScalarValue(result[len(result)-1]), nil
+	}
+
+	ticker, err := requireTicker(ec, args, 0)
+	if err != nil {
+		return NilValue(), err
+	}
+	period := optionalInt(args, 1, 20)
+	candles, err := fetchCandles(ec, ticker, period*3)
+	if err != nil {
+		return NilValue(), err
+	}
+	closes := ohlcvCloses(candles)
+	val := technical.HMALatest(closes, period)
+	return ScalarValue(val), nil
+}
+
 func fnRSI(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -406,7 +585,7 @@ func fnRSI(ec *EvalContext, args []Value) (Value, error) {
 }
 
 func fnRSIRange(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -430,7 +609,7 @@ func fnRSIRange(ec *EvalContext, args []Value) (Value, error) {
 }
 
 func fnMACD(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -453,7 +632,7 @@ func fnMACD(ec *EvalContext, args []Value) (Value, error) {
 }
 
 func fnBollinger(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -473,8 +652,51 @@ func fnBollinger(ec *EvalContext, args []Value) (Value, error) {
 	return TableValue([]map[string]interface{}{row}), nil
 }
 
+func fnKeltner(ec *EvalContext, args []Value) (Value, error) {
+	ticker, err := requireTicker(ec, args, 0)
+	if err != nil {
+		return NilValue(), err
+	}
+	period := optionalInt(args, 1, 20)
+	mult := optionalFloat(args, 2, 1.5)
+
+	candles, err := fetchCandles(ec, ticker, period*5)
+	if err != nil {
+		return NilValue(), err
+	}
+	kc := technical.KeltnerLatest(candles, period, mult)
+	row := map[string]interface{}{
+		"upper":  kc.Upper,
+		"middle": kc.Middle,
+		"lower":  kc.Lower,
+	}
+	return TableValue([]map[string]interface{}{row}), nil
+}
+
+// squeeze(TICKER, period) → true when Bollinger(period, 2) lies inside Keltner(period, 1.5)
+func fnSqueeze(ec *EvalContext, args []Value) (Value, error) {
+	ticker, err := requireTicker(ec, args, 0)
+	if err != nil {
+		return NilValue(), err
+	}
+	period := ScalarValue(float64(optionalInt(args, 1, 20)))
+
+	bb, err := fnBollinger(ec, []Value{StringValue(ticker), period, ScalarValue(2)})
+	if err != nil {
+		return NilValue(), err
+	}
+	kc, err := fnKeltner(ec, []Value{StringValue(ticker), period, ScalarValue(1.5)})
+	if err != nil {
+		return NilValue(), err
+	}
+	return BoolValue(technical.InSqueeze(
+		models.BollingerData{Upper: bb.Table[0]["upper"].(float64), Lower: bb.Table[0]["lower"].(float64)},
+		models.KeltnerData{Upper: kc.Table[0]["upper"].(float64), Lower: kc.Table[0]["lower"].(float64)},
+	)), nil
+}
+
 func fnSuperTrend(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -494,7 +716,7 @@ func fnSuperTrend(ec *EvalContext, args []Value) (Value, error) {
 }
 
 func fnATR(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -508,8 +730,45 @@ func fnATR(ec *EvalContext, args []Value) (Value, error) {
 	return ScalarValue(val), nil
 }
 
+// volatilityWindow is the default number of daily returns behind volatility().
+const volatilityWindow = 60
+
+// volatility(TICKER, window=60) → annualized EWMA volatility of the last
+// window daily returns, as a fraction (0.25 = 25%)
+func fnVolatility(ec *EvalContext, args []Value) (Value, error) {
+	ticker, err := requireTicker(ec, args, 0)
+	if err != nil {
+		return NilValue(), err
+	}
+	window := volatilityWindow
+	if len(args) > 1 {
+		if args[1].Type != TypeScalar || args[1].Scalar != math.Trunc(args[1].Scalar) {
+			return NilValue(), fmt.Errorf("volatility: window must be a whole number of days")
+		}
+		window = int(args[1].Scalar)
+	}
+	if window < 2 || window > 5*technical.TradingDaysPerYear {
+		return NilValue(), fmt.Errorf("volatility: window must be between 2 and %d days, got %d", 5*technical.TradingDaysPerYear, window)
+	}
+
+	// ~1.5 calendar days per trading day, plus room for holidays.
+	candles, err := fetchCandles(ec, ticker, window*3/2+15)
+	if err != nil {
+		return NilValue(), err
+	}
+	returns := technical.DailyReturns(candles)
+	if len(returns) < 2 {
+		return NilValue(), fmt.Errorf("volatility: insufficient history for %s", ticker)
+	}
+	if len(returns) > window {
+		returns = returns[len(returns)-window:]
+	}
+	daily := technical.ComputeEWMAVol(returns, technical.DefaultEWMALambda)
+	return ScalarValue(technical.AnnualizeVol(daily)), nil
+}
+
 func fnVWAP(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -546,23 +805,41 @@ func fnCrossunder(ec *EvalContext, args []Value) (Value, error) {
 // ════════════════════════════════════════════════════════════════════
 
 func fnPE(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
-	quote, err := ec.Aggregator.YFinance().GetQuote(ec.Ctx, ticker)
+	quote, err := ec.fetchQuote(ticker)
 	if err != nil {
 		return NilValue(), err
 	}
 	return ScalarValue(quote.PE), nil
 }
 
+// fnNextEarnings returns the date (YYYY-MM-DD) of the ticker's next
+// results board meeting, or nil if none is scheduled.
+func fnNextEarnings(ec *EvalContext, args []Value) (Value, error) {
+	ticker, err := requireTicker(ec, args, 0)
+	if err != nil {
+		return NilValue(), err
+	}
+	events, err := ec.Aggregator.EarningsCalendar(ec.Ctx, ticker)
+	if err != nil {
+		return NilValue(), err
+	}
+	next, ok := datasource.NextEvent(events, models.EventEarnings, utils.NowIST())
+	if !ok {
+		return NilValue(), nil
+	}
+	return StringValue(utils.FormatDateIST(next.Date)), nil
+}
+
 func fnPB(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
-	quote, err := ec.Aggregator.YFinance().GetQuote(ec.Ctx, ticker)
+	quote, err := ec.fetchQuote(ticker)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -582,35 +859,75 @@ func fnDebtEquity(ec *EvalContext, args []Value) (Value, error) {
 }
 
 func fnMarketCap(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
-	quote, err := ec.Aggregator.YFinance().GetQuote(ec.Ctx, ticker)
+	quote, err := ec.fetchQuote(ticker)
 	if err != nil {
 		return NilValue(), err
 	}
 	return ScalarValue(quote.MarketCap), nil
 }
 
+// fnDividendYield returns the latest annual dividend per share as a % of
+// the current price, falling back to the quoted yield when the financials
+// carry no dividend data.
 func fnDividendYield(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
-	quote, err := ec.Aggregator.YFinance().GetQuote(ec.Ctx, ticker)
+	quote, err := ec.fetchQuote(ticker)
 	if err != nil {
 		return NilValue(), err
 	}
+	if dps, eps, err := fetchDividend(ec, ticker); err == nil && dps > 0 {
+		if yield, _ := fundamental.DividendMetrics(dps, quote.LastPrice, eps); yield > 0 {
+			return ScalarValue(yield), nil
+		}
+	}
 	return ScalarValue(quote.DividendYield), nil
 }
 
+// fnPayoutRatio returns the latest annual dividend per share as a % of EPS.
+func fnPayoutRatio(ec *EvalContext, args []Value) (Value, error) {
+	ticker, err := requireTicker(ec, args, 0)
+	if err != nil {
+		return NilValue(), err
+	}
+	dps, eps, err := fetchDividend(ec, ticker)
+	if err != nil {
+		return NilValue(), err
+	}
+	_, payout := fundamental.DividendMetrics(dps, 0, eps)
+	return ScalarValue(payout), nil
+}
+
+// fetchDividend returns the latest annual dividend per share and EPS from
+// the stock profile's financials, preferring the statement EPS over the
+// profile ratio.
+func fetchDividend(ec *EvalContext, ticker string) (dps, eps float64, err error) {
+	profile, err := ec.fetchProfile(ticker)
+	if err != nil {
+		return 0, 0, err
+	}
+	fin := profile.Financials
+	if fin != nil && len(fin.AnnualIncome) > 0 {
+		eps = fin.AnnualIncome[0].EPS
+	}
+	if eps <= 0 && profile.Ratios != nil {
+		eps = profile.Ratios.EPS
+	}
+	return fundamental.DividendPerShare(fin, 0), eps, nil
+}
+
 func fnPromoterHolding(ec *EvalContext, args []Value) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
-	profile, err := ec.Aggregator.Screener().GetStockProfile(ec.Ctx, ticker)
+	profile, err := ec.fetchProfile(ticker)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -634,11 +951,11 @@ func fnBookValue(ec *EvalContext, args []Value) (Value, error) {
 
 // fetchRatioField fetches a stock profile and extracts a ratio field.
 func fetchRatioField(ec *EvalContext, args []Value, extract func(*models.FinancialRatios) float64) (Value, error) {
-	ticker, err := requireTicker(args, 0)
+	ticker, err := requireTicker(ec, args, 0)
 	if err != nil {
 		return NilValue(), err
 	}
-	profile, err := ec.Aggregator.Screener().GetStockProfile(ec.Ctx, ticker)
+	profile, err := ec.fetchProfile(ticker)
 	if err != nil {
 		return NilValue(), err
 	}
@@ -753,11 +1070,19 @@ func fnCorrelation(ec *EvalContext, args []Value) (Value, error) {
 	// Ticker-based
 	if len(args) >= 2 && args[0].Type == TypeString && args[1].Type == TypeString {
 		days := optionalInt(args, 2, 90)
-		dataA, err := fetchCandles(ec, ResolveTicker(args[0].Str), days)
+		tickerA, err := requireTicker(ec, args, 0)
+		if err != nil {
+			return NilValue(), err
+		}
+		tickerB, err := requireTicker(ec, args, 1)
 		if err != nil {
 			return NilValue(), err
 		}
-		dataB, err := fetchCandles(ec, ResolveTicker(args[1].Str), days)
+		dataA, err := fetchCandles(ec, tickerA, days)
+		if err != nil {
+			return NilValue(), err
+		}
+		dataB, err := fetchCandles(ec, tickerB, days)
 		if err != nil {
 			return NilValue(), err
 		}
@@ -775,46 +1100,191 @@ func fnAbs(ec *EvalContext, args []Value) (Value, error) {
 	return ScalarValue(0), nil
 }
 
-// ════════════════════════════════════════════════════════════════════
-// Screening & Filtering Functions
-// ════════════════════════════════════════════════════════════════════
+// maxRoundDecimals bounds the decimals argument of round.
+const maxRoundDecimals = 10
 
-// Nifty 50 constituent symbols (representative subset).
-var nifty50Symbols = []string{
-	"RELIANCE", "TCS", "HDFCBANK", "INFY", "ICICIBANK",
-	"HINDUNILVR", "ITC", "SBIN", "BHARTIARTL", "KOTAKBANK",
-	"LT", "AXISBANK", "BAJFINANCE", "ASIANPAINT", "MARUTI",
-	"TITAN", "SUNPHARMA", "HCLTECH", "NTPC", "TATAMOTORS",
-	"ULTRACEMCO", "WIPRO", "POWERGRID", "NESTLEIND", "ONGC",
-	"JSWSTEEL", "ADANIENT", "ADANIPORTS", "TECHM", "TATASTEEL",
-	"M_M", "BAJAJFINSV", "HDFCLIFE", "DIVISLAB", "DRREDDY",
-	"SBILIFE", "BRITANNIA", "CIPLA", "COALINDIA", "INDUSINDBK",
-	"GRASIM", "EICHERMOT", "APOLLOHOSP", "HEROMOTOCO", "TATACONSUM",
-	"BPCL", "UPL", "BAJAJ_AUTO", "HINDALCO", "LTIM",
-}
+// fnRound rounds a scalar, or each point of a vector, to n decimal places
+// (default 0), with halves rounded away from zero.
+func fnRound(ec *EvalContext, args []Value) (Value, error) {
+	if len(args) == 0 {
+		return NilValue(), fmt.Errorf("round requires a value argument")
+	}
+	n := 0
+	if len(args) > 1 {
+		if args[1].Type != TypeScalar {
+			return NilValue(), fmt.Errorf("round: decimals must be a number")
+		}
+		n = int(args[1].Scalar)
+	}
+	if n < 0 || n > maxRoundDecimals {
+		return NilValue(), fmt.Errorf("round: decimals must be between 0 and %d, got %d", maxRoundDecimals, n)
+	}
+	scale := math.Pow(10, float64(n))
+	round := func(x float64) float64 { return math.Round(x*scale) / scale }
 
-var niftyBankSymbols = []string{
-	"HDFCBANK", "ICICIBANK", "KOTAKBANK", "AXISBANK", "SBIN",
-	"INDUSINDBK", "BANDHANBNK", "FEDERALBNK", "IDFCFIRSTB", "PNB",
-	"AUBANK", "BANKBARODA",
+	switch args[0].Type {
+	case TypeScalar:
+		return ScalarValue(round(args[0].Scalar)), nil
+	case TypeVector:
+		out := make([]TimePoint, len(args[0].Vector))
+		for i, p := range args[0].Vector {
+			out[i] = TimePoint{Time: p.Time, Value: round(p.Value)}
+		}
+		return VectorValue(out), nil
+	default:
+		return NilValue(), fmt.Errorf("round: expected scalar or vector, got %s", args[0].Type)
+	}
 }
 
+// ════════════════════════════════════════════════════════════════════
+// Screening & Filtering Functions
+// ════════════════════════════════════════════════════════════════════
+
 func fnNifty50(_ *EvalContext, _ []Value) (Value, error) {
-	rows := make([]map[string]interface{}, len(nifty50Symbols))
-	for i, s := range nifty50Symbols {
+	symbols := datasource.Constituents("NIFTY 50")
+	rows := make([]map[string]interface{}, len(symbols))
+	for i, s := range symbols {
 		rows[i] = map[string]interface{}{"ticker": s, "index": "NIFTY 50"}
 	}
 	return TableValue(rows), nil
 }
 
 func fnNiftyBank(_ *EvalContext, _ []Value) (Value, error) {
-	rows := make([]map[string]interface{}, len(niftyBankSymbols))
-	for i, s := range niftyBankSymbols {
+	symbols := datasource.Constituents("NIFTY BANK")
+	rows := make([]map[string]interface{}, len(symbols))
+	for i, s := range symbols {
 		rows[i] = map[string]interface{}{"ticker": s, "index": "NIFTY BANK"}
 	}
 	return TableValue(rows), nil
 }
 
+// universe("name") → the tickers of a named universe from the config
+func fnUniverse(ec *EvalContext, args []Value) (Value, error) {
+	if len(args) == 0 || args[0].Type != TypeString {
+		return NilValue(), fmt.Errorf("universe: expected a universe name")
+	}
+	name := strings.ToLower(args[0].Str)
+	tickers, ok := ec.Universes[name]
+	if !ok {
+		names := make([]string, 0, len(ec.Universes))
+		for n := range ec.Universes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return NilValue(), fmt.Errorf("universe: unknown universe %q (none configured)", args[0].Str)
+		}
+		return NilValue(), fmt.Errorf("universe: unknown universe %q (available: %s)", args[0].Str, strings.Join(names, ", "))
+	}
+	rows := make([]map[string]interface{}, len(tickers))
+	for i, s := range tickers {
+		rows[i] = map[string]interface{}{"ticker": ResolveTicker(s), "universe": name}
+	}
+	return TableValue(rows), nil
+}
+
+// rsRankLookbackDays is the default rs_rank() return window, in calendar days.
+const rsRankLookbackDays = 90
+
+// rs_rank(universe, lookback=90d) → tickers ranked by return over lookback
+//
+// universe is a table with a ticker column, such as nifty50() or
+// universe("it"), or the name of a configured universe; without one the
+// screener universe is ranked. Each row holds the ticker, its return_pct,
+// its rank (1 = strongest), and its percentile: the share of the other
+// tickers it outperformed, from 100 for the leader to 0 for the laggard.
+// Tickers without enough history, or whose history cannot be fetched, are
+// left out; if that leaves none, the first failure is returned instead of
+// an empty table.
+func fnRSRank(ec *EvalContext, args []Value) (Value, error) {
+	var tickers []string
+	switch {
+	case len(args) == 0:
+		tickers = ec.Universe
+		if len(tickers) == 0 {
+			tickers = datasource.Constituents("NIFTY 50")
+		}
+	case args[0].Type == TypeTable:
+		for _, row := range args[0].Table {
+			if t, ok := row["ticker"].(string); ok {
+				tickers = append(tickers, t)
+			}
+		}
+	case args[0].Type == TypeString:
+		u, err := fnUniverse(ec, args[:1])
+		if err != nil {
+			return NilValue(), fmt.Errorf("rs_rank: %w", err)
+		}
+		for _, row := range u.Table {
+			tickers = append(tickers, row["ticker"].(string))
+		}
+	default:
+		return NilValue(), fmt.Errorf("rs_rank: expected a universe table or name, got %s", args[0].Type)
+	}
+
+	days := rsRankLookbackDays
+	if len(args) > 1 {
+		switch args[1].Type {
+		case TypeScalar:
+			days = int(args[1].Scalar)
+		case TypeString:
+			days = parseDuration(args[1].Str)
+		}
+		if days <= 0 {
+			return NilValue(), fmt.Errorf("rs_rank: lookback must be a positive number of days")
+		}
+	}
+
+	type ranked struct {
+		ticker string
+		ret    float64
+	}
+	var rs []ranked
+	var firstErr error
+	for _, t := range tickers {
+		if ec.Ctx != nil {
+			if err := ec.Ctx.Err(); err != nil {
+				return NilValue(), err
+			}
+		}
+		ticker, err := ec.resolveTicker(t)
+		if err == nil {
+			var data []models.OHLCV
+			data, err = fetchCandles(ec, ticker, days)
+			if err == nil && (len(data) < 2 || data[0].Close == 0) {
+				err = fmt.Errorf("not enough history over %dd", days)
+			}
+			if err == nil {
+				first, last := data[0].Close, data[len(data)-1].Close
+				rs = append(rs, ranked{ticker, (last - first) / first * 100})
+				continue
+			}
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", t, err)
+		}
+	}
+	if len(rs) == 0 && firstErr != nil {
+		return NilValue(), fmt.Errorf("rs_rank: no ticker could be ranked: %w", firstErr)
+	}
+	sort.SliceStable(rs, func(i, j int) bool { return rs[i].ret > rs[j].ret })
+
+	rows := make([]map[string]interface{}, len(rs))
+	for i, r := range rs {
+		pct := 100.0
+		if len(rs) > 1 {
+			pct = float64(len(rs)-1-i) / float64(len(rs)-1) * 100
+		}
+		rows[i] = map[string]interface{}{
+			"ticker":     r.ticker,
+			"return_pct": r.ret,
+			"rank":       i + 1,
+			"percentile": pct,
+		}
+	}
+	return TableValue(rows), nil
+}
+
 func fnSector(_ *EvalContext, args []Value) (Value, error) {
 	sector := ""
 	if len(args) > 0 && args[0].Type == TypeString {
@@ -896,20 +1366,6 @@ func fnWhere(_ *EvalContext, args []Value) (Value, error) {
 	return NilValue(), nil
 }
 
-func fnScreenerInternal(_ *EvalContext, args []Value) (Value, error) {
-	// Placeholder — actual implementation would evaluate filter against stock universe
-	filter := ""
-	if len(args) > 0 && args[0].Type == TypeString {
-		filter = args[0].Str
-	}
-	row := map[string]interface{}{
-		"filter": filter,
-		"status": "screener_pending",
-		"note":   "screener requires live data connection",
-	}
-	return TableValue([]map[string]interface{}{row}), nil
-}
-
 // ════════════════════════════════════════════════════════════════════
 // Utility / Display Functions
 // ════════════════════════════════════════════════════════════════════
@@ -964,6 +1420,63 @@ func fnFirst(_ *EvalContext, args []Value) (Value, error) {
 	return ScalarValue(0), nil
 }
 
+// shift(vector, n=1) → vector lagged by n bars: each point takes the value
+// from n bars earlier, and the first n points, having none, are dropped.
+func fnShift(_ *EvalContext, args []Value) (Value, error) {
+	if len(args) == 0 || args[0].Type != TypeVector {
+		return NilValue(), fmt.Errorf("shift: expected a vector as the first argument")
+	}
+	n := 1
+	if len(args) > 1 {
+		if args[1].Type != TypeScalar || args[1].Scalar != math.Trunc(args[1].Scalar) || args[1].Scalar < 0 {
+			return NilValue(), fmt.Errorf("shift: n must be a non-negative whole number of bars")
+		}
+		n = int(math.Min(args[1].Scalar, float64(len(args[0].Vector))))
+	}
+
+	vec := args[0].Vector
+	out := make([]TimePoint, 0, len(vec)-n)
+	for i := n; i < len(vec); i++ {
+		out = append(out, TimePoint{Time: vec[i].Time, Value: vec[i-n].Value})
+	}
+	return VectorValue(out), nil
+}
+
+// asofLookbackDays is how many calendar days before the requested date
+// asof() searches for the last close, enough to span long market holidays.
+const asofLookbackDays = 14
+
+// asof(TICKER, "YYYY-MM-DD") → close on the date, or on the last trading
+// day before it
+func fnAsOf(ec *EvalContext, args []Value) (Value, error) {
+	ticker, err := requireTicker(ec, args, 0)
+	if err != nil {
+		return NilValue(), err
+	}
+	if len(args) < 2 || args[1].Type != TypeString {
+		return NilValue(), fmt.Errorf("asof: expected a date string (YYYY-MM-DD) as the second argument")
+	}
+	date, err := time.ParseInLocation("2006-01-02", args[1].Str, utils.IST)
+	if err != nil {
+		return NilValue(), fmt.Errorf("asof: invalid date %q, use YYYY-MM-DD", args[1].Str)
+	}
+	if date.After(time.Now()) {
+		return NilValue(), fmt.Errorf("asof: %s is in the future", args[1].Str)
+	}
+	end := date.AddDate(0, 0, 1) // bars up to the end of the day
+
+	candles, err := FetchHistoricalRange(ec, ticker, date.AddDate(0, 0, -asofLookbackDays), end)
+	if err != nil {
+		return NilValue(), err
+	}
+	for i := len(candles) - 1; i >= 0; i-- {
+		if candles[i].Timestamp.Before(end) {
+			return ScalarValue(candles[i].Close), nil
+		}
+	}
+	return NilValue(), fmt.Errorf("asof: no close for %s on or in the %d days before %s", ticker, asofLookbackDays, args[1].Str)
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Internal Helpers
 // ════════════════════════════════════════════════════════════════════
@@ -5,8 +5,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -54,6 +56,12 @@ func NewREPLWithIO(agg *datasource.Aggregator, in io.Reader, out io.Writer) *REP
 	}
 }
 
+// EvalContext returns the context queries are evaluated in, so callers can
+// set the screener universe or liquidity floors before Run.
+func (r *REPL) EvalContext() *EvalContext {
+	return r.ec
+}
+
 // Run starts the interactive loop. Blocks until EOF or .quit.
 func (r *REPL) Run() {
 	fmt.Fprint(r.out, replBanner)
@@ -103,12 +111,40 @@ func (r *REPL) handleCommand(cmd string) bool {
 		r.history = nil
 		fmt.Fprintln(r.out, "History cleared.")
 
+	case ".precision":
+		r.setPrecision(strings.Fields(cmd)[1:])
+
 	default:
 		fmt.Fprintf(r.out, "Unknown command: %s  (type .help for help)\n", cmd)
 	}
 	return false
 }
 
+// setPrecision handles ".precision [N|auto]", which sets or shows the
+// decimal places used for scalar output.
+func (r *REPL) setPrecision(args []string) {
+	if len(args) == 0 {
+		if r.ec.Precision == 0 {
+			fmt.Fprintln(r.out, "Precision: auto")
+		} else {
+			fmt.Fprintf(r.out, "Precision: %d\n", r.ec.Precision)
+		}
+		return
+	}
+	if strings.EqualFold(args[0], "auto") {
+		r.ec.Precision = 0
+		fmt.Fprintln(r.out, "Precision: auto")
+		return
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > maxRoundDecimals {
+		fmt.Fprintf(r.out, "Usage: .precision [1-%d|auto]\n", maxRoundDecimals)
+		return
+	}
+	r.ec.Precision = n
+	fmt.Fprintf(r.out, "Precision: %d\n", n)
+}
+
 func (r *REPL) printHelp() {
 	help := `
 FinanceQL Quick Reference
@@ -127,6 +163,7 @@ Dot-Commands:
   .functions   List all built-in functions
   .history     Show query history
   .clear       Clear history
+  .precision   Show or set scalar decimals (.precision 2, .precision auto)
   .quit        Exit REPL
 
 Number Suffixes: 1cr = 10M, 1l = 100K
@@ -154,11 +191,11 @@ func (r *REPL) printFunctions() {
 		"Utility":     {},
 	}
 
-	priceSet := map[string]bool{"price": true, "open": true, "high": true, "low": true, "close": true, "volume": true, "returns": true, "change_pct": true, "vix": true, "price_range": true, "volume_range": true}
-	techSet := map[string]bool{"sma": true, "ema": true, "rsi": true, "rsi_range": true, "macd": true, "bollinger": true, "supertrend": true, "atr": true, "vwap": true, "crossover": true, "crossunder": true}
-	fundSet := map[string]bool{"pe": true, "pb": true, "roe": true, "roce": true, "debt_equity": true, "market_cap": true, "dividend_yield": true, "promoter_holding": true, "eve_ebitda": true, "eps": true, "book_value": true}
-	aggSet := map[string]bool{"avg": true, "sum": true, "min": true, "max": true, "stddev": true, "percentile": true, "correlation": true, "abs": true}
-	screenSet := map[string]bool{"nifty50": true, "niftybank": true, "sector": true, "sort": true, "top": true, "bottom": true, "where": true}
+	priceSet := map[string]bool{"price": true, "open": true, "high": true, "low": true, "close": true, "volume": true, "returns": true, "change_pct": true, "vix": true, "price_range": true, "volume_range": true, "liquidity": true, "spread": true, "ratio": true, "asof": true}
+	techSet := map[string]bool{"sma": true, "ema": true, "wma": true, "hma": true, "rsi": true, "rsi_range": true, "macd": true, "bollinger": true, "keltner": true, "squeeze": true, "supertrend": true, "atr": true, "volatility": true, "vwap": true, "crossover": true, "crossunder": true}
+	fundSet := map[string]bool{"pe": true, "pb": true, "roe": true, "roce": true, "debt_equity": true, "market_cap": true, "dividend_yield": true, "payout_ratio": true, "promoter_holding": true, "eve_ebitda": true, "eps": true, "book_value": true, "next_earnings": true}
+	aggSet := map[string]bool{"avg": true, "sum": true, "min": true, "max": true, "stddev": true, "percentile": true, "correlation": true, "abs": true, "round": true}
+	screenSet := map[string]bool{"nifty50": true, "niftybank": true, "universe": true, "rs_rank": true, "sector": true, "sort": true, "top": true, "bottom": true, "where": true}
 
 	for _, name := range names {
 		switch {
@@ -214,7 +251,7 @@ func (r *REPL) execute(query string) {
 func (r *REPL) formatResult(v Value) {
 	switch v.Type {
 	case TypeScalar:
-		fmt.Fprintf(r.out, "→ %.4f\n", v.Scalar)
+		fmt.Fprintf(r.out, "→ %s\n", r.ec.FormatScalar(v.Scalar))
 
 	case TypeString:
 		fmt.Fprintf(r.out, "→ %s\n", v.Str)
@@ -257,11 +294,11 @@ func (r *REPL) formatVector(pts []TimePoint) {
 	last := pts[len(pts)-1]
 
 	if !first.Time.IsZero() {
-		fmt.Fprintf(r.out, "  First: %.4f (%s)\n", first.Value, first.Time.Format("2006-01-02"))
-		fmt.Fprintf(r.out, "  Last:  %.4f (%s)\n", last.Value, last.Time.Format("2006-01-02"))
+		fmt.Fprintf(r.out, "  First: %s (%s)\n", r.ec.FormatScalar(first.Value), first.Time.Format("2006-01-02"))
+		fmt.Fprintf(r.out, "  Last:  %s (%s)\n", r.ec.FormatScalar(last.Value), last.Time.Format("2006-01-02"))
 	} else {
-		fmt.Fprintf(r.out, "  First: %.4f\n", first.Value)
-		fmt.Fprintf(r.out, "  Last:  %.4f\n", last.Value)
+		fmt.Fprintf(r.out, "  First: %s\n", r.ec.FormatScalar(first.Value))
+		fmt.Fprintf(r.out, "  Last:  %s\n", r.ec.FormatScalar(last.Value))
 	}
 
 	// Compute stats
@@ -276,7 +313,8 @@ func (r *REPL) formatVector(pts []TimePoint) {
 		}
 	}
 	avg := sum / float64(len(pts))
-	fmt.Fprintf(r.out, "  Min:   %.4f  Max: %.4f  Avg: %.4f\n", mn, mx, avg)
+	fmt.Fprintf(r.out, "  Min:   %s  Max: %s  Avg: %s\n",
+		r.ec.FormatScalar(mn), r.ec.FormatScalar(mx), r.ec.FormatScalar(avg))
 
 	// Sparkline
 	if len(pts) > 1 {
@@ -356,6 +394,24 @@ func (r *REPL) formatTable(rows []map[string]interface{}) {
 // Formatting Helpers
 // ════════════════════════════════════════════════════════════════════
 
+// FormatScalar formats v with precision decimal places. A precision of 0
+// picks one from the magnitude: 2 decimals at 10 and above (prices, index
+// levels), 4 below (ratios, returns).
+func FormatScalar(v float64, precision int) string {
+	if precision <= 0 {
+		precision = 4
+		if math.Abs(v) >= 10 {
+			precision = 2
+		}
+	}
+	return strconv.FormatFloat(v, 'f', precision, 64)
+}
+
+// FormatScalar formats v using the context's Precision.
+func (ec *EvalContext) FormatScalar(v float64) string {
+	return FormatScalar(v, ec.Precision)
+}
+
 func padRight(s string, width int) string {
 	if len(s) >= width {
 		return s
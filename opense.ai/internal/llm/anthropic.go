@@ -57,7 +57,7 @@ func NewAnthropicProvider(apiKey string, opts ...AnthropicOption) (*AnthropicPro
 		apiKey:  apiKey,
 		baseURL: "https://api.anthropic.com/v1",
 		model:   "claude-sonnet-4-20250514",
-		client:  &http.Client{Timeout: 120 * time.Second},
+		client:  defaultHTTPClient(120 * time.Second),
 	}
 	for _, opt := range opts {
 		opt(p)
@@ -356,6 +356,8 @@ func (p *AnthropicProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk
 	// Track current tool call being built
 	var currentToolID, currentToolName string
 	var toolArgsBuilder strings.Builder
+	// Input tokens come with message_start, output tokens with message_delta.
+	var usage *Usage
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -374,6 +376,11 @@ func (p *AnthropicProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk
 		}
 
 		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				usage = &Usage{PromptTokens: event.Message.Usage.InputTokens}
+			}
+
 		case "content_block_start":
 			if event.ContentBlock != nil && event.ContentBlock.Type == "tool_use" {
 				currentToolID = event.ContentBlock.ID
@@ -407,16 +414,24 @@ func (p *AnthropicProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk
 			}
 
 		case "message_delta":
+			if event.Usage != nil {
+				if usage == nil {
+					usage = &Usage{}
+				}
+				usage.CompletionTokens = event.Usage.OutputTokens
+				usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+			}
 			if event.Delta != nil && event.Delta.StopReason != "" {
 				ch <- StreamChunk{
 					FinishReason: mapAnthropicStopReason(event.Delta.StopReason),
 					Done:         true,
+					Usage:        usage,
 				}
 				return
 			}
 
 		case "message_stop":
-			ch <- StreamChunk{Done: true}
+			ch <- StreamChunk{Done: true, Usage: usage}
 			return
 		}
 	}
@@ -51,7 +51,7 @@ func NewGeminiProvider(apiKey string, opts ...GeminiOption) (*GeminiProvider, er
 		apiKey:  apiKey,
 		baseURL: "https://generativelanguage.googleapis.com/v1beta",
 		model:   "gemini-2.0-flash",
-		client:  &http.Client{Timeout: 120 * time.Second},
+		client:  defaultHTTPClient(120 * time.Second),
 	}
 	for _, opt := range opts {
 		opt(p)
@@ -413,9 +413,17 @@ func (p *GeminiProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk) {
 					})
 				}
 			}
-			if candidate.FinishReason == "STOP" {
-				sc.FinishReason = FinishStop
+			if candidate.FinishReason != "" {
+				sc.FinishReason = mapGeminiFinishReason(candidate.FinishReason)
 				sc.Done = true
+				// Every chunk carries the running totals; the last has them all.
+				if u := chunk.UsageMetadata; u.TotalTokenCount > 0 {
+					sc.Usage = &Usage{
+						PromptTokens:     u.PromptTokenCount,
+						CompletionTokens: u.CandidatesTokenCount,
+						TotalTokens:      u.TotalTokenCount,
+					}
+				}
 			}
 		}
 		ch <- sc
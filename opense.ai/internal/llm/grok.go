@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// grokModels lists commonly available xAI Grok models.
+var grokModels = []string{
+	"grok-3",
+	"grok-3-mini",
+	"grok-2-1212",
+	"grok-2-vision-1212",
+}
+
+// GrokProvider implements LLMProvider for xAI's Grok models. The xAI API is
+// OpenAI-compatible, so requests, tool calls, and streaming reuse the
+// OpenAI provider.
+type GrokProvider struct {
+	*OpenAIProvider
+}
+
+// GrokOption configures the Grok provider.
+type GrokOption func(*GrokProvider)
+
+// WithGrokBaseURL sets a custom base URL (e.g., for proxies).
+func WithGrokBaseURL(url string) GrokOption {
+	return func(p *GrokProvider) { p.baseURL = strings.TrimRight(url, "/") }
+}
+
+// WithGrokModel sets the default model.
+func WithGrokModel(model string) GrokOption {
+	return func(p *GrokProvider) { p.model = model }
+}
+
+// WithGrokHTTPClient sets a custom HTTP client.
+func WithGrokHTTPClient(client *http.Client) GrokOption {
+	return func(p *GrokProvider) { p.client = client }
+}
+
+// NewGrokProvider creates an xAI Grok provider.
+func NewGrokProvider(apiKey string, opts ...GrokOption) (*GrokProvider, error) {
+	if apiKey == "" {
+		return nil, ErrNoAPIKey
+	}
+	p := &GrokProvider{&OpenAIProvider{
+		apiKey:  apiKey,
+		baseURL: "https://api.x.ai/v1",
+		model:   "grok-3",
+		client:  defaultHTTPClient(120 * time.Second),
+		name:    ProviderGrok,
+	}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+func (p *GrokProvider) Models() []string { return grokModels }
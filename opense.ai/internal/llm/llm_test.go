@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/seenimoa/openseai/internal/config"
 )
 
 // ════════════════════════════════════════════════════════════════════
@@ -44,6 +47,46 @@ func TestMessageConstructors(t *testing.T) {
 	}
 }
 
+func TestDefaultHTTPClientTransport(t *testing.T) {
+	p, err := NewOpenAIProvider("sk-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, ok := p.client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("transport: got %T, want *http.Transport", p.client.Transport)
+	}
+	want := DefaultTransportConfig()
+	if tr.MaxIdleConnsPerHost != want.MaxIdleConnsPerHost || tr.MaxIdleConns != want.MaxIdleConns {
+		t.Errorf("idle conns: got %d/%d per host, want %d/%d",
+			tr.MaxIdleConns, tr.MaxIdleConnsPerHost, want.MaxIdleConns, want.MaxIdleConnsPerHost)
+	}
+	if tr.IdleConnTimeout != want.IdleConnTimeout {
+		t.Errorf("IdleConnTimeout: got %v, want %v", tr.IdleConnTimeout, want.IdleConnTimeout)
+	}
+	if !tr.ForceAttemptHTTP2 {
+		t.Error("expected HTTP/2 to be attempted")
+	}
+	if p.client.Timeout != 120*time.Second {
+		t.Errorf("timeout: got %v", p.client.Timeout)
+	}
+
+	custom := &http.Client{Timeout: time.Second}
+	p, _ = NewOpenAIProvider("sk-test", WithOpenAIHTTPClient(custom))
+	if p.client != custom {
+		t.Error("WithOpenAIHTTPClient did not override the default client")
+	}
+
+	h1 := NewTransport(TransportConfig{MaxIdleConnsPerHost: 4, DisableHTTP2: true})
+	if h1.MaxIdleConnsPerHost != 4 || h1.ForceAttemptHTTP2 || h1.TLSNextProto == nil {
+		t.Errorf("NewTransport: got per-host %d, h2 %v, TLSNextProto %v",
+			h1.MaxIdleConnsPerHost, h1.ForceAttemptHTTP2, h1.TLSNextProto)
+	}
+	if h1.IdleConnTimeout != want.IdleConnTimeout {
+		t.Errorf("zero IdleConnTimeout should take the default, got %v", h1.IdleConnTimeout)
+	}
+}
+
 func TestResponseHasToolCalls(t *testing.T) {
 	r := &Response{Content: "hello"}
 	if r.HasToolCalls() {
@@ -189,7 +232,7 @@ func TestToolRegistryExecuteAll(t *testing.T) {
 		{ID: "1", Name: "slow"},
 		{ID: "2", Name: "fast"},
 	}
-	results := reg.ExecuteAll(context.Background(), calls)
+	results := reg.ExecuteAll(context.Background(), calls, 0)
 	if len(results) != 2 {
 		t.Fatalf("expected 2 results, got %d", len(results))
 	}
@@ -198,6 +241,46 @@ func TestToolRegistryExecuteAll(t *testing.T) {
 	}
 }
 
+func TestToolRegistryExecuteAllMaxParallel(t *testing.T) {
+	const maxParallel = 3
+	var mu sync.Mutex
+	running, peak := 0, 0
+
+	reg := NewToolRegistry()
+	reg.Register(Tool{
+		Name: "slow",
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			mu.Lock()
+			running++
+			peak = max(peak, running)
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return string(args), nil
+		},
+	})
+
+	calls := make([]ToolCall, 20)
+	for i := range calls {
+		calls[i] = ToolCall{ID: fmt.Sprint(i), Name: "slow", Arguments: json.RawMessage(fmt.Sprint(i))}
+	}
+	results := reg.ExecuteAll(context.Background(), calls, maxParallel)
+
+	if peak > maxParallel {
+		t.Errorf("%d tools ran concurrently, cap is %d", peak, maxParallel)
+	}
+	if peak < 2 {
+		t.Errorf("expected tools to run in parallel, peak concurrency %d", peak)
+	}
+	for i, r := range results {
+		if r.ToolCallID != fmt.Sprint(i) || r.Content != fmt.Sprint(i) {
+			t.Errorf("result %d out of order: %+v", i, r)
+		}
+	}
+}
+
 func TestToolResultToMessage(t *testing.T) {
 	// Success case
 	tr := ToolResult{ToolCallID: "c1", Name: "fn", Content: "result"}
@@ -490,6 +573,122 @@ func TestOpenAIPing(t *testing.T) {
 	}
 }
 
+func TestAzureOpenAIChat(t *testing.T) {
+	server := newMockOpenAIServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/gpt4o-prod/chat/completions" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if v := r.URL.Query().Get("api-version"); v != "2024-06-01" {
+			t.Fatalf("unexpected api-version: %q", v)
+		}
+		if r.Header.Get("api-key") != "az-key" {
+			t.Fatal("missing api-key header")
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Fatal("Azure requests must not send a bearer token")
+		}
+
+		resp := openAIChatResponse{
+			Choices: []openAIChoice{{
+				Message:      openAIMessage{Role: "assistant", Content: "ok"},
+				FinishReason: "stop",
+			}},
+			Model: "gpt-4o",
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	defer server.Close()
+
+	if _, err := NewAzureOpenAIProvider(server.URL, "gpt4o-prod", "", ""); err != ErrNoAPIKey {
+		t.Fatalf("expected ErrNoAPIKey, got: %v", err)
+	}
+
+	p, err := NewAzureOpenAIProvider(server.URL+"/", "gpt4o-prod", "az-key", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name() != ProviderAzure {
+		t.Fatalf("expected provider name %q, got %q", ProviderAzure, p.Name())
+	}
+	resp, err := p.Chat(context.Background(), []Message{UserMessage("hi")}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "ok" || resp.Provider != ProviderAzure {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestGrokChat(t *testing.T) {
+	server := newMockOpenAIServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer xai-test" {
+			t.Fatal("missing auth header")
+		}
+
+		var req openAIChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "grok-3-mini" {
+			t.Fatalf("unexpected model: %s", req.Model)
+		}
+
+		resp := openAIChatResponse{
+			ID: "grok-123",
+			Choices: []openAIChoice{{
+				Message:      openAIMessage{Role: "assistant", Content: "NIFTY is range-bound"},
+				FinishReason: "length",
+			}},
+			Usage: openAIUsage{PromptTokens: 12, CompletionTokens: 8, TotalTokens: 20},
+			Model: "grok-3-mini",
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	defer server.Close()
+
+	if _, err := NewGrokProvider(""); err != ErrNoAPIKey {
+		t.Fatalf("expected ErrNoAPIKey, got: %v", err)
+	}
+
+	p, err := NewGrokProvider("xai-test",
+		WithGrokBaseURL(server.URL+"/"),
+		WithGrokModel("grok-3-mini"),
+		WithGrokHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name() != ProviderGrok || len(p.Models()) == 0 || p.Models()[0] != "grok-3" {
+		t.Fatalf("unexpected provider: %s %v", p.Name(), p.Models())
+	}
+	resp, err := p.Chat(context.Background(), []Message{UserMessage("Outlook for NIFTY?")}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "NIFTY is range-bound" || resp.Provider != ProviderGrok || resp.Usage.TotalTokens != 20 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp.FinishReason != FinishLength {
+		t.Fatalf("expected length, got %s", resp.FinishReason)
+	}
+
+	cfg := &config.Config{}
+	cfg.LLM.Primary = ProviderGrok
+	cfg.LLM.GrokKey = "xai-test"
+	router, err := NewRouterFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary, err := router.Primary()
+	if err != nil || primary.Name() != ProviderGrok {
+		t.Fatalf("expected grok primary, got %v (%v)", primary, err)
+	}
+	if router.modelMap[TaskSimple] != "grok-3-mini" {
+		t.Fatalf("unexpected simple model: %q", router.modelMap[TaskSimple])
+	}
+}
+
 func TestOpenAIChatStream(t *testing.T) {
 	server := newMockOpenAIServer(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/event-stream")
@@ -529,6 +728,49 @@ func TestOpenAIChatStream(t *testing.T) {
 	}
 }
 
+func TestOpenAIChatStreamUsage(t *testing.T) {
+	server := newMockOpenAIServer(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Error("expected stream_options.include_usage")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`data: {"choices":[{"delta":{"content":"Hi"},"index":0}]}`,
+			`data: {"choices":[{"delta":{},"finish_reason":"stop","index":0}]}`,
+			`data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`,
+			`data: [DONE]`,
+		} {
+			fmt.Fprintln(w, chunk)
+		}
+	})
+	defer server.Close()
+
+	p, _ := NewOpenAIProvider("sk-test", WithOpenAIBaseURL(server.URL))
+	ch, err := p.ChatStream(context.Background(), []Message{UserMessage("hi")}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var last StreamChunk
+	for chunk := range ch {
+		if chunk.Err != nil {
+			t.Fatal(chunk.Err)
+		}
+		if last.Done {
+			t.Fatalf("chunk after Done: %+v", chunk)
+		}
+		last = chunk
+	}
+	if !last.Done || last.FinishReason != FinishStop {
+		t.Fatalf("unexpected last chunk: %+v", last)
+	}
+	if last.Usage == nil || *last.Usage != (Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15}) {
+		t.Fatalf("unexpected usage: %+v", last.Usage)
+	}
+}
+
 // ════════════════════════════════════════════════════════════════════
 // ollama.go — Ollama Provider with mock server
 // ════════════════════════════════════════════════════════════════════
@@ -625,6 +867,134 @@ func TestOllamaChatWithTools(t *testing.T) {
 	}
 }
 
+func TestOllamaPromptToolFallback(t *testing.T) {
+	var requests []ollamaChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		if len(req.Tools) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"error":"registry.ollama.ai/library/gemma2:27b does not support tools"}`)
+			return
+		}
+		json.NewEncoder(w).Encode(ollamaChatResponse{
+			Model: "gemma2:27b",
+			Message: ollamaMessage{
+				Role:    "assistant",
+				Content: "Let me check the RSI.\n```json\n{\"tool_calls\": [{\"name\": \"get_rsi\", \"arguments\": {\"ticker\": \"TCS\", \"period\": 14}}]}\n```",
+			},
+			Done: true,
+		})
+	}))
+	defer server.Close()
+
+	p, _ := NewOllamaProvider(server.URL, WithOllamaModel("gemma2:27b"))
+	tools := []Tool{{Name: "get_rsi", Description: "Get RSI", Parameters: ObjectSchema("RSI", map[string]*JSONSchema{"ticker": StringProp("ticker")}, "ticker")}}
+	resp, err := p.Chat(context.Background(),
+		[]Message{SystemMessage("You are an analyst."), UserMessage("RSI of TCS")}, tools, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.HasToolCalls() || resp.FinishReason != FinishToolCalls {
+		t.Fatalf("expected a parsed tool call, got %+v", resp)
+	}
+	tc := resp.ToolCalls[0]
+	if tc.Name != "get_rsi" || !strings.HasPrefix(tc.ID, "call_") || string(tc.Arguments) != `{"ticker": "TCS", "period": 14}` {
+		t.Errorf("unexpected tool call: %+v (args %s)", tc, tc.Arguments)
+	}
+	if resp.Content != "Let me check the RSI." {
+		t.Errorf("expected the tool block stripped from content, got %q", resp.Content)
+	}
+
+	// The retry described the tools in the system prompt instead.
+	if len(requests) != 2 {
+		t.Fatalf("expected a native attempt and a prompt-based retry, got %d requests", len(requests))
+	}
+	sys := requests[1].Messages[0]
+	if sys.Role != "system" || !strings.HasPrefix(sys.Content, "You are an analyst.") || !strings.Contains(sys.Content, "get_rsi: Get RSI") {
+		t.Errorf("expected tool prompt in system message, got %+v", sys)
+	}
+
+	// The model is remembered, and tool turns are replayed as text.
+	_, err = p.Chat(context.Background(), []Message{
+		UserMessage("RSI of TCS"),
+		{Role: RoleAssistant, ToolCalls: resp.ToolCalls},
+		ToolResultMessage(tc.ID, "get_rsi", `{"rsi": 58.2}`),
+	}, tools, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 3 || len(requests[2].Tools) != 0 {
+		t.Fatalf("expected a single prompt-based request, got %d requests", len(requests))
+	}
+	msgs := requests[2].Messages
+	if msgs[0].Role != "system" || !strings.Contains(msgs[2].Content, `"tool_calls"`) ||
+		msgs[3].Role != "user" || !strings.Contains(msgs[3].Content, `{"rsi": 58.2}`) {
+		t.Errorf("unexpected replayed messages: %+v", msgs)
+	}
+
+	// Unknown tool names and plain JSON are not tool calls.
+	if calls, _ := parsePromptToolCalls(`{"name": "delete_all", "arguments": {}}`, tools); calls != nil {
+		t.Errorf("expected unknown tool to be ignored, got %+v", calls)
+	}
+	if calls, _ := parsePromptToolCalls(`Verdict: {"recommendation": "BUY"}`, tools); calls != nil {
+		t.Errorf("expected no calls in an analysis, got %+v", calls)
+	}
+	calls, _ := parsePromptToolCalls(`{"name": "get_rsi", "arguments": {"ticker": "INFY"}}`, tools)
+	if len(calls) != 1 {
+		t.Fatalf("expected the single-call form to parse, got %+v", calls)
+	}
+	// IDs stay distinct across turns.
+	if calls[0].ID == tc.ID {
+		t.Errorf("expected a fresh call ID, got %q twice", tc.ID)
+	}
+}
+
+func TestOllamaPromptToolStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, _ := w.(http.Flusher)
+		chunks := []ollamaChatResponse{
+			{Message: ollamaMessage{Content: "Let me check "}},
+			{Message: ollamaMessage{Content: "the RSI.\n```json\n{\"tool_calls\": [{\"name\": "}},
+			{Message: ollamaMessage{Content: "\"get_rsi\", \"arguments\": {\"ticker\": \"TCS\"}}]}\n```"}},
+			{Done: true},
+		}
+		for _, c := range chunks {
+			data, _ := json.Marshal(c)
+			fmt.Fprintln(w, string(data))
+			flusher.Flush()
+		}
+	}))
+	defer server.Close()
+
+	p, _ := NewOllamaProvider(server.URL, WithOllamaPromptTools(true))
+	tools := []Tool{{Name: "get_rsi", Description: "Get RSI"}}
+	ch, err := p.ChatStream(context.Background(), []Message{UserMessage("RSI of TCS")}, tools, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var content strings.Builder
+	var last StreamChunk
+	for chunk := range ch {
+		if chunk.Err != nil {
+			t.Fatal(chunk.Err)
+		}
+		content.WriteString(chunk.Content)
+		last = chunk
+	}
+	if strings.Contains(content.String(), "tool_calls") || strings.Contains(content.String(), "```") {
+		t.Errorf("expected the tool block kept out of the stream, got %q", content.String())
+	}
+	if !strings.HasPrefix(content.String(), "Let me check the RSI.") {
+		t.Errorf("expected the text before the block streamed, got %q", content.String())
+	}
+	if last.FinishReason != FinishToolCalls || len(last.ToolCalls) != 1 || last.ToolCalls[0].Name != "get_rsi" {
+		t.Errorf("expected the call on the final chunk, got %+v", last)
+	}
+}
+
 func TestOllamaPing(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/api/tags" {
@@ -959,10 +1329,12 @@ func TestAnthropicStream(t *testing.T) {
 		flusher, _ := w.(http.Flusher)
 
 		events := []string{
+			`data: {"type":"message_start","message":{"usage":{"input_tokens":25,"output_tokens":1}}}`,
 			`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
 			`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Bullish "}}`,
 			`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"on TCS"}}`,
 			`data: {"type":"content_block_stop","index":0}`,
+			`data: {"type":"message_delta","delta":{},"usage":{"output_tokens":4}}`,
 			`data: {"type":"message_stop"}`,
 		}
 		for _, e := range events {
@@ -979,15 +1351,22 @@ func TestAnthropicStream(t *testing.T) {
 	}
 
 	var content strings.Builder
+	var usage *Usage
 	for chunk := range ch {
 		if chunk.Err != nil {
 			t.Fatal(chunk.Err)
 		}
 		content.WriteString(chunk.Content)
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
 	}
 	if content.String() != "Bullish on TCS" {
 		t.Fatalf("unexpected stream: %q", content.String())
 	}
+	if usage == nil || *usage != (Usage{PromptTokens: 25, CompletionTokens: 4, TotalTokens: 29}) {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
 }
 
 func TestAnthropicPing(t *testing.T) {
@@ -1138,6 +1517,55 @@ func TestRouterNoProviders(t *testing.T) {
 	}
 }
 
+func TestRouterResponseCache(t *testing.T) {
+	calls := 0
+	r := NewRouter("main", WithResponseCache(time.Minute))
+	r.RegisterProvider(&mockProvider{
+		name: "main",
+		chatFunc: func(ctx context.Context, messages []Message, tools []Tool, opts *ChatOptions) (*Response, error) {
+			calls++
+			return &Response{Content: fmt.Sprintf("answer %d", calls), Provider: "main"}, nil
+		},
+	})
+
+	msgs := []Message{UserMessage("what is the PE of TCS?")}
+	opts := &ChatOptions{Model: "mock-model", Temperature: 0.2}
+	first, err := r.Chat(context.Background(), msgs, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := r.Chat(context.Background(), msgs, nil, &ChatOptions{Model: "mock-model", Temperature: 0.2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 provider call for identical requests, got %d", calls)
+	}
+	if second.Content != first.Content {
+		t.Fatalf("cached content = %q, want %q", second.Content, first.Content)
+	}
+
+	// A different prompt or option is a cache miss.
+	if _, err := r.Chat(context.Background(), []Message{UserMessage("other")}, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Chat(context.Background(), msgs, nil, &ChatOptions{Model: "mock-model", Temperature: 0.7}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 provider calls after two distinct requests, got %d", calls)
+	}
+
+	// Entries expire after the TTL.
+	r.cache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := r.Chat(context.Background(), msgs, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 4 {
+		t.Fatalf("expected expired entry to call provider, got %d calls", calls)
+	}
+}
+
 func TestRouterChatWithComplexity(t *testing.T) {
 	r := NewRouter("main",
 		WithModelMap(map[TaskComplexity]string{
@@ -1257,7 +1685,7 @@ func TestRunToolLoop(t *testing.T) {
 	msgs := []Message{UserMessage("Price of TCS?")}
 	tools := []Tool{{Name: "get_price", Description: "Get stock price"}}
 
-	resp, finalMsgs, err := RunToolLoop(context.Background(), provider, registry, msgs, tools, nil, 5)
+	resp, finalMsgs, err := RunToolLoop(context.Background(), provider, registry, msgs, tools, nil, 5, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1292,12 +1720,79 @@ func TestRunToolLoopMaxIterations(t *testing.T) {
 	})
 
 	_, _, err := RunToolLoop(context.Background(), provider, registry,
-		[]Message{UserMessage("test")}, []Tool{{Name: "fn"}}, nil, 3)
+		[]Message{UserMessage("test")}, []Tool{{Name: "fn"}}, nil, 3, 0)
 	if err == nil || !strings.Contains(err.Error(), "exceeded") {
 		t.Fatalf("expected max iterations error, got: %v", err)
 	}
 }
 
+func TestRunToolLoopParallelToolCalls(t *testing.T) {
+	const n = 5
+	var calls []ToolCall
+	for i := 0; i < n; i++ {
+		calls = append(calls, ToolCall{ID: fmt.Sprintf("call_%d", i), Name: "slow", Arguments: json.RawMessage(fmt.Sprintf(`{"i":%d}`, i))})
+	}
+
+	callNum := 0
+	provider := &mockProvider{
+		name: "test",
+		chatFunc: func(ctx context.Context, messages []Message, tools []Tool, opts *ChatOptions) (*Response, error) {
+			callNum++
+			if callNum == 1 {
+				return &Response{ToolCalls: calls, FinishReason: FinishToolCalls}, nil
+			}
+			return &Response{Content: "done", FinishReason: FinishStop}, nil
+		},
+	}
+
+	// Every handler waits until all of them have started, so the loop only
+	// finishes if the calls really run at once. Later calls return first.
+	var started sync.WaitGroup
+	started.Add(n)
+	registry := NewToolRegistry()
+	registry.Register(Tool{
+		Name: "slow",
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var a struct{ I int }
+			json.Unmarshal(args, &a)
+			started.Done()
+			started.Wait()
+			time.Sleep(time.Duration(n-a.I) * 5 * time.Millisecond)
+			return fmt.Sprintf("result %d", a.I), nil
+		},
+	})
+
+	done := make(chan struct{})
+	var msgs []Message
+	var err error
+	go func() {
+		defer close(done)
+		_, msgs, err = RunToolLoop(context.Background(), provider, registry,
+			[]Message{UserMessage("go")}, []Tool{{Name: "slow"}}, nil, 2, 0)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool calls did not run concurrently")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// user + assistant(tool_calls) + n tool results
+	if len(msgs) != 2+n {
+		t.Fatalf("expected %d messages, got %d", 2+n, len(msgs))
+	}
+	for i, m := range msgs[2:] {
+		if m.Role != RoleTool || m.ToolCallID != calls[i].ID {
+			t.Errorf("message %d: role=%s id=%s, want tool result for %s", i, m.Role, m.ToolCallID, calls[i].ID)
+		}
+		if want := fmt.Sprintf("result %d", i); m.Content != want {
+			t.Errorf("message %d: content=%q, want %q", i, m.Content, want)
+		}
+	}
+}
+
 func TestRunToolLoopNoToolCalls(t *testing.T) {
 	provider := &mockProvider{
 		name: "test",
@@ -1307,7 +1802,7 @@ func TestRunToolLoopNoToolCalls(t *testing.T) {
 	}
 
 	resp, msgs, err := RunToolLoop(context.Background(), provider, NewToolRegistry(),
-		[]Message{UserMessage("hello")}, nil, nil, 5)
+		[]Message{UserMessage("hello")}, nil, nil, 5, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1319,6 +1814,55 @@ func TestRunToolLoopNoToolCalls(t *testing.T) {
 	}
 }
 
+func TestRunToolLoopRetriesTransientToolError(t *testing.T) {
+	defer func(d time.Duration) { toolRetryDelay = d }(toolRetryDelay)
+	toolRetryDelay = time.Millisecond
+
+	var toolResult string
+	provider := &mockProvider{
+		name: "test",
+		chatFunc: func(ctx context.Context, messages []Message, tools []Tool, opts *ChatOptions) (*Response, error) {
+			last := messages[len(messages)-1]
+			if last.Role != RoleTool {
+				return &Response{
+					ToolCalls:    []ToolCall{{ID: "call_1", Name: "get_price", Arguments: json.RawMessage(`{}`)}},
+					FinishReason: FinishToolCalls,
+				}, nil
+			}
+			toolResult = last.Content
+			return &Response{Content: "done", FinishReason: FinishStop}, nil
+		},
+	}
+
+	calls := 0
+	registry := NewToolRegistry()
+	registry.Register(Tool{
+		Name: "get_price",
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			calls++
+			if calls == 1 {
+				return "", Transient(errors.New("stale quote"))
+			}
+			return "₹4,200.00", nil
+		},
+	})
+
+	resp, _, err := RunToolLoop(context.Background(), provider, registry,
+		[]Message{UserMessage("Price of TCS?")}, []Tool{{Name: "get_price"}}, nil, 5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "done" {
+		t.Fatalf("unexpected content: %s", resp.Content)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 tool executions, got %d", calls)
+	}
+	if toolResult != "₹4,200.00" {
+		t.Fatalf("expected model to see the retried result, got %q", toolResult)
+	}
+}
+
 // ════════════════════════════════════════════════════════════════════
 // gemini.go — quoteIfNeeded helper
 // ════════════════════════════════════════════════════════════════════
@@ -1634,6 +2178,35 @@ func TestRouterModelsEmpty(t *testing.T) {
 	}
 }
 
+func TestLookupPricing(t *testing.T) {
+	tests := []struct {
+		model string
+		want  ModelPricing
+		ok    bool
+	}{
+		{"gpt-4o", ModelPricing{2.50, 10.00}, true},
+		{"gpt-4o-mini", ModelPricing{0.15, 0.60}, true},
+		{"gpt-4o-mini-2024-07-18", ModelPricing{0.15, 0.60}, true},
+		{"claude-sonnet-4-20250514", ModelPricing{3.00, 15.00}, true},
+		{"qwen2.5:7b", ModelPricing{}, true},
+		{"unknown-model", ModelPricing{}, false},
+		{"", ModelPricing{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := LookupPricing(tt.model)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("LookupPricing(%q) = %+v, %v; want %+v, %v", tt.model, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestModelPricingCost(t *testing.T) {
+	p := ModelPricing{InputPerMillion: 2.50, OutputPerMillion: 10.00}
+	if got := p.Cost(1_000_000, 100_000); got != 3.50 {
+		t.Errorf("Cost() = %f, want 3.50", got)
+	}
+}
+
 // distinctModelProvider is a mock with configurable model lists.
 type distinctModelProvider struct {
 	name   string
@@ -1651,4 +2224,80 @@ func (d *distinctModelProvider) ChatStream(ctx context.Context, messages []Messa
 	ch <- StreamChunk{Content: "ok", Done: true}
 	close(ch)
 	return ch, nil
-}
\ No newline at end of file
+}
+// droppingStreamProvider closes its first drops streams partway through a
+// response and serves the rest when asked to continue.
+type droppingStreamProvider struct {
+	mockProvider
+	drops    int
+	mu       sync.Mutex
+	requests [][]Message
+}
+
+func (d *droppingStreamProvider) ChatStream(ctx context.Context, messages []Message, tools []Tool, opts *ChatOptions) (<-chan StreamChunk, error) {
+	d.mu.Lock()
+	d.requests = append(d.requests, messages)
+	attempt := len(d.requests)
+	d.mu.Unlock()
+
+	ch := make(chan StreamChunk, 4)
+	if attempt <= d.drops {
+		ch <- StreamChunk{Content: "TCS is trading "}
+		ch <- StreamChunk{Content: "near its 52-week"}
+	} else {
+		ch <- StreamChunk{Content: " high; hold."}
+		ch <- StreamChunk{FinishReason: FinishStop, Done: true}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestRouterStreamResumesDroppedStream(t *testing.T) {
+	p := &droppingStreamProvider{mockProvider: mockProvider{name: "main"}, drops: 1}
+	r := NewRouter("main")
+	r.RegisterProvider(p)
+
+	ch, err := r.ChatStream(context.Background(), []Message{UserMessage("view on TCS")}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content strings.Builder
+	for chunk := range ch {
+		if chunk.Err != nil {
+			t.Fatalf("unexpected stream error: %v", chunk.Err)
+		}
+		content.WriteString(chunk.Content)
+	}
+
+	if want := "TCS is trading near its 52-week high; hold."; content.String() != want {
+		t.Errorf("content = %q, want %q", content.String(), want)
+	}
+	if len(p.requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(p.requests))
+	}
+	cont := p.requests[1]
+	if len(cont) != 3 || cont[1].Role != RoleAssistant || cont[1].Content != "TCS is trading near its 52-week" || cont[2].Role != RoleUser {
+		t.Errorf("continuation request = %+v", cont)
+	}
+}
+
+func TestRouterStreamResumeLimit(t *testing.T) {
+	p := &droppingStreamProvider{mockProvider: mockProvider{name: "main"}, drops: 10}
+	r := NewRouter("main", WithStreamResumes(2))
+	r.RegisterProvider(p)
+
+	ch, err := r.ChatStream(context.Background(), []Message{UserMessage("view on TCS")}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last StreamChunk
+	for chunk := range ch {
+		last = chunk
+	}
+	if len(p.requests) != 3 {
+		t.Errorf("requests = %d, want 3 (initial + 2 resumes)", len(p.requests))
+	}
+	if !errors.Is(last.Err, ErrStreamClosed) {
+		t.Errorf("last chunk error = %v, want ErrStreamClosed", last.Err)
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -28,10 +30,20 @@ var ollamaModels = []string{
 }
 
 // OllamaProvider implements LLMProvider for local Ollama instances.
+//
+// Models without native tool calling are driven through a prompt-based
+// protocol instead: tool descriptions go into the system prompt and the
+// model answers with a JSON tool_calls block, parsed back into ToolCalls.
+// A model is switched over the first time Ollama rejects its tools, or
+// from the start with WithOllamaPromptTools.
 type OllamaProvider struct {
-	baseURL string
-	model   string
-	client  *http.Client
+	baseURL     string
+	model       string
+	client      *http.Client
+	promptTools bool // always use the prompt-based tool protocol
+
+	mu      sync.Mutex
+	noTools map[string]bool // models Ollama reported as lacking tool support
 }
 
 // OllamaOption configures the Ollama provider.
@@ -47,6 +59,12 @@ func WithOllamaHTTPClient(client *http.Client) OllamaOption {
 	return func(p *OllamaProvider) { p.client = client }
 }
 
+// WithOllamaPromptTools makes every model use the prompt-based tool
+// protocol rather than Ollama's native tool calling.
+func WithOllamaPromptTools(enabled bool) OllamaOption {
+	return func(p *OllamaProvider) { p.promptTools = enabled }
+}
+
 // NewOllamaProvider creates an Ollama provider.
 // baseURL is the Ollama server URL (e.g., "http://localhost:11434").
 func NewOllamaProvider(baseURL string, opts ...OllamaOption) (*OllamaProvider, error) {
@@ -56,7 +74,8 @@ func NewOllamaProvider(baseURL string, opts ...OllamaOption) (*OllamaProvider, e
 	p := &OllamaProvider{
 		baseURL: strings.TrimRight(baseURL, "/"),
 		model:   "qwen2.5:7b",
-		client:  &http.Client{Timeout: 300 * time.Second}, // longer timeout for local models
+		client:  defaultHTTPClient(300 * time.Second), // longer timeout for local models
+		noTools: make(map[string]bool),
 	}
 	for _, opt := range opts {
 		opt(p)
@@ -89,42 +108,59 @@ func (p *OllamaProvider) Chat(ctx context.Context, messages []Message, tools []T
 	start := time.Now()
 	model := p.resolveModel(opts)
 
-	body := p.buildRequest(messages, tools, model, opts, false)
-	data, err := json.Marshal(body)
-	if err != nil {
-		return nil, fmt.Errorf("ollama: marshal request: %w", err)
+	promptTools := len(tools) > 0 && p.usePromptTools(model)
+	resp, err := p.post(ctx, p.buildRequest(messages, tools, model, opts, false, promptTools))
+	if err != nil && len(tools) > 0 && !promptTools && toolsUnsupported(err) {
+		p.markNoTools(model)
+		promptTools = true
+		resp, err = p.post(ctx, p.buildRequest(messages, tools, model, opts, false, promptTools))
 	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/chat", bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrProviderDown, err)
-	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return nil, fmt.Errorf("ollama: HTTP %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
 	var result ollamaChatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("ollama: decode response: %w", err)
 	}
 
-	return p.parseResponse(&result, model, start), nil
+	r := p.parseResponse(&result, model, start)
+	if promptTools {
+		if calls, rest := parsePromptToolCalls(r.Content, tools); len(calls) > 0 {
+			r.ToolCalls, r.Content, r.FinishReason = calls, rest, FinishToolCalls
+		}
+	}
+	return r, nil
 }
 
 // ChatStream sends a streaming chat request to Ollama.
 func (p *OllamaProvider) ChatStream(ctx context.Context, messages []Message, tools []Tool, opts *ChatOptions) (<-chan StreamChunk, error) {
 	model := p.resolveModel(opts)
 
-	body := p.buildRequest(messages, tools, model, opts, true)
+	promptTools := len(tools) > 0 && p.usePromptTools(model)
+	resp, err := p.post(ctx, p.buildRequest(messages, tools, model, opts, true, promptTools))
+	if err != nil && len(tools) > 0 && !promptTools && toolsUnsupported(err) {
+		p.markNoTools(model)
+		promptTools = true
+		resp, err = p.post(ctx, p.buildRequest(messages, tools, model, opts, true, promptTools))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var promptToolset []Tool
+	if promptTools {
+		promptToolset = tools
+	}
+	ch := make(chan StreamChunk, 64)
+	go p.readStream(resp.Body, ch, promptToolset)
+	return ch, nil
+}
+
+// post sends a chat request and returns the response, whose body the
+// caller closes, or an *ollamaHTTPError for a non-200 status.
+func (p *OllamaProvider) post(ctx context.Context, body ollamaChatRequest) (*http.Response, error) {
 	data, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("ollama: marshal request: %w", err)
@@ -144,12 +180,44 @@ func (p *OllamaProvider) ChatStream(ctx context.Context, messages []Message, too
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
 		resp.Body.Close()
-		return nil, fmt.Errorf("ollama: HTTP %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, &ollamaHTTPError{status: resp.StatusCode, body: string(bodyBytes)}
 	}
+	return resp, nil
+}
 
-	ch := make(chan StreamChunk, 64)
-	go p.readStream(resp.Body, ch)
-	return ch, nil
+// ollamaHTTPError is a non-200 response from the Ollama server.
+type ollamaHTTPError struct {
+	status int
+	body   string
+}
+
+func (e *ollamaHTTPError) Error() string {
+	return fmt.Sprintf("ollama: HTTP %d: %s", e.status, e.body)
+}
+
+// toolsUnsupported reports whether err is Ollama rejecting a request
+// because the model has no native tool calling.
+func toolsUnsupported(err error) bool {
+	var he *ollamaHTTPError
+	return errors.As(err, &he) && he.status == http.StatusBadRequest &&
+		strings.Contains(he.body, "does not support tools")
+}
+
+// usePromptTools reports whether model is driven through the prompt-based
+// tool protocol.
+func (p *OllamaProvider) usePromptTools(model string) bool {
+	if p.promptTools {
+		return true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.noTools[model]
+}
+
+func (p *OllamaProvider) markNoTools(model string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.noTools[model] = true
 }
 
 // ── Internal Types ──
@@ -213,14 +281,19 @@ func (p *OllamaProvider) resolveModel(opts *ChatOptions) string {
 	return p.model
 }
 
-func (p *OllamaProvider) buildRequest(messages []Message, tools []Tool, model string, opts *ChatOptions, stream bool) ollamaChatRequest {
+func (p *OllamaProvider) buildRequest(messages []Message, tools []Tool, model string, opts *ChatOptions, stream, promptTools bool) ollamaChatRequest {
 	r := ollamaChatRequest{
-		Model:    model,
-		Messages: convertToOllamaMessages(messages),
-		Stream:   stream,
+		Model:  model,
+		Stream: stream,
 	}
-	if len(tools) > 0 {
+	switch {
+	case promptTools:
+		r.Messages = convertToOllamaPromptMessages(messages, tools)
+	case len(tools) > 0:
+		r.Messages = convertToOllamaMessages(messages)
 		r.Tools = convertToOllamaTools(tools)
+	default:
+		r.Messages = convertToOllamaMessages(messages)
 	}
 	if opts != nil {
 		o := &ollamaOptions{}
@@ -274,10 +347,16 @@ func (p *OllamaProvider) parseResponse(raw *ollamaChatResponse, model string, st
 	return r
 }
 
-func (p *OllamaProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk) {
+// readStream forwards a streamed response. With promptTools set, content
+// from the first possible tool_calls block onward is held back; any calls
+// in it are returned as ToolCalls on the final chunk, and only the text
+// around them is sent.
+func (p *OllamaProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk, promptTools []Tool) {
 	defer close(ch)
 	defer body.Close()
 
+	var content strings.Builder
+	held := -1
 	scanner := bufio.NewScanner(body)
 	// Ollama may return large lines; increase buffer
 	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
@@ -293,6 +372,19 @@ func (p *OllamaProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk) {
 			Content: chunk.Message.Content,
 			Done:    chunk.Done,
 		}
+		if promptTools != nil {
+			// Text is forwarded until something that may open a tool_calls
+			// block appears; from there it is held back and parsed at the end.
+			content.WriteString(chunk.Message.Content)
+			if held < 0 {
+				if i := strings.IndexAny(content.String(), "{`"); i >= 0 {
+					held = i
+					sc.Content = chunk.Message.Content[:len(chunk.Message.Content)-(content.Len()-i)]
+				}
+			} else {
+				sc.Content = ""
+			}
+		}
 		for i, tc := range chunk.Message.ToolCalls {
 			sc.ToolCalls = append(sc.ToolCalls, ToolCall{
 				ID:        fmt.Sprintf("call_%d", i),
@@ -302,6 +394,18 @@ func (p *OllamaProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk) {
 		}
 		if chunk.Done {
 			sc.FinishReason = FinishStop
+			sc.Usage = &Usage{
+				PromptTokens:     chunk.PromptEvalCount,
+				CompletionTokens: chunk.EvalCount,
+				TotalTokens:      chunk.PromptEvalCount + chunk.EvalCount,
+			}
+			if held >= 0 {
+				rest := content.String()[held:]
+				if calls, stripped := parsePromptToolCalls(rest, promptTools); len(calls) > 0 {
+					sc.ToolCalls, sc.FinishReason, rest = calls, FinishToolCalls, stripped
+				}
+				sc.Content += rest
+			}
 		}
 		ch <- sc
 		if chunk.Done {
@@ -353,3 +457,29 @@ func convertToOllamaTools(tools []Tool) []ollamaTool {
 	}
 	return out
 }
+
+// convertToOllamaPromptMessages converts messages for the prompt-based
+// tool protocol: the tool prompt is appended to the system message (or
+// becomes one), earlier tool calls are written back as tool_calls blocks,
+// and tool results are passed as user messages.
+func convertToOllamaPromptMessages(messages []Message, tools []Tool) []ollamaMessage {
+	prompt := toolPrompt(tools)
+	out := make([]ollamaMessage, 0, len(messages)+1)
+	if len(messages) == 0 || messages[0].Role != RoleSystem {
+		out = append(out, ollamaMessage{Role: string(RoleSystem), Content: prompt})
+	}
+	for i, m := range messages {
+		msg := ollamaMessage{Role: string(m.Role), Content: m.Content}
+		switch {
+		case i == 0 && m.Role == RoleSystem:
+			msg.Content = strings.TrimSpace(m.Content + "\n\n" + prompt)
+		case m.Role == RoleTool:
+			msg.Role = string(RoleUser)
+			msg.Content = fmt.Sprintf("Result of tool %s (call %s):\n%s", m.Name, m.ToolCallID, m.Content)
+		case len(m.ToolCalls) > 0:
+			msg.Content = strings.TrimSpace(m.Content + "\n" + formatPromptToolCalls(m.ToolCalls))
+		}
+		out = append(out, msg)
+	}
+	return out
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -30,6 +31,13 @@ type OpenAIProvider struct {
 	baseURL string
 	model   string
 	client  *http.Client
+
+	// Azure OpenAI routing; deployment is empty for api.openai.com.
+	deployment string
+	apiVersion string
+
+	// name overrides the provider name for other OpenAI-compatible APIs.
+	name string
 }
 
 // OpenAIOption configures the OpenAI provider.
@@ -50,6 +58,9 @@ func WithOpenAIHTTPClient(client *http.Client) OpenAIOption {
 	return func(p *OpenAIProvider) { p.client = client }
 }
 
+// defaultAzureAPIVersion is used when NewAzureOpenAIProvider gets no api-version.
+const defaultAzureAPIVersion = "2024-06-01"
+
 // NewOpenAIProvider creates an OpenAI provider.
 func NewOpenAIProvider(apiKey string, opts ...OpenAIOption) (*OpenAIProvider, error) {
 	if apiKey == "" {
@@ -59,7 +70,7 @@ func NewOpenAIProvider(apiKey string, opts ...OpenAIOption) (*OpenAIProvider, er
 		apiKey:  apiKey,
 		baseURL: "https://api.openai.com/v1",
 		model:   "gpt-4o",
-		client:  &http.Client{Timeout: 120 * time.Second},
+		client:  defaultHTTPClient(120 * time.Second),
 	}
 	for _, opt := range opts {
 		opt(p)
@@ -67,16 +78,53 @@ func NewOpenAIProvider(apiKey string, opts ...OpenAIOption) (*OpenAIProvider, er
 	return p, nil
 }
 
-func (p *OpenAIProvider) Name() string      { return ProviderOpenAI }
-func (p *OpenAIProvider) Models() []string   { return openAIModels }
+// NewAzureOpenAIProvider creates a provider for an Azure OpenAI deployment,
+// e.g. endpoint "https://myresource.openai.azure.com" and deployment
+// "gpt-4o-prod". The deployment name doubles as the default model for
+// cost estimates; override it with WithOpenAIModel.
+func NewAzureOpenAIProvider(endpoint, deployment, apiKey, apiVersion string, opts ...OpenAIOption) (*OpenAIProvider, error) {
+	if apiKey == "" {
+		return nil, ErrNoAPIKey
+	}
+	if endpoint == "" || deployment == "" {
+		return nil, fmt.Errorf("azure: endpoint and deployment are required")
+	}
+	if apiVersion == "" {
+		apiVersion = defaultAzureAPIVersion
+	}
+	p := &OpenAIProvider{
+		apiKey:     apiKey,
+		baseURL:    strings.TrimRight(endpoint, "/"),
+		model:      deployment,
+		client:     defaultHTTPClient(120 * time.Second),
+		deployment: deployment,
+		apiVersion: apiVersion,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+func (p *OpenAIProvider) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	if p.deployment != "" {
+		return ProviderAzure
+	}
+	return ProviderOpenAI
+}
+
+func (p *OpenAIProvider) Models() []string { return openAIModels }
 
 // Ping verifies the API key by listing models.
 func (p *OpenAIProvider) Ping(ctx context.Context) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/models", nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.modelsURL(), nil)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	p.setAuth(req)
 	resp, err := p.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrProviderDown, err)
@@ -102,7 +150,7 @@ func (p *OpenAIProvider) Chat(ctx context.Context, messages []Message, tools []T
 		return nil, fmt.Errorf("openai: marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.chatURL(), bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
@@ -136,7 +184,7 @@ func (p *OpenAIProvider) ChatStream(ctx context.Context, messages []Message, too
 		return nil, fmt.Errorf("openai: marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.chatURL(), bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
@@ -164,12 +212,18 @@ type openAIChatRequest struct {
 	Messages    []openAIMessage   `json:"messages"`
 	Tools       []openAITool      `json:"tools,omitempty"`
 	Stream      bool              `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
 	Temperature *float64          `json:"temperature,omitempty"`
 	MaxTokens   *int              `json:"max_tokens,omitempty"`
 	TopP        *float64          `json:"top_p,omitempty"`
 	Stop        []string          `json:"stop,omitempty"`
 }
 
+// openAIStreamOptions asks for a final chunk carrying the token usage.
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
 type openAIMessage struct {
 	Role       string            `json:"role"`
 	Content    string            `json:"content,omitempty"`
@@ -239,15 +293,45 @@ func (p *OpenAIProvider) resolveModel(opts *ChatOptions) string {
 
 func (p *OpenAIProvider) setHeaders(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
+	p.setAuth(req)
+}
+
+// setAuth sets the bearer token, or the api-key header for Azure.
+func (p *OpenAIProvider) setAuth(req *http.Request) {
+	if p.deployment != "" {
+		req.Header.Set("api-key", p.apiKey)
+		return
+	}
 	req.Header.Set("Authorization", "Bearer "+p.apiKey)
 }
 
+// chatURL returns the chat completions endpoint. Azure routes requests to
+// a named deployment and requires an api-version query parameter.
+func (p *OpenAIProvider) chatURL() string {
+	if p.deployment != "" {
+		return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+			p.baseURL, url.PathEscape(p.deployment), url.QueryEscape(p.apiVersion))
+	}
+	return p.baseURL + "/chat/completions"
+}
+
+// modelsURL returns the model listing endpoint used by Ping.
+func (p *OpenAIProvider) modelsURL() string {
+	if p.deployment != "" {
+		return fmt.Sprintf("%s/openai/models?api-version=%s", p.baseURL, url.QueryEscape(p.apiVersion))
+	}
+	return p.baseURL + "/models"
+}
+
 func (p *OpenAIProvider) buildRequest(messages []Message, tools []Tool, model string, opts *ChatOptions, stream bool) openAIChatRequest {
 	r := openAIChatRequest{
 		Model:    model,
 		Messages: convertToOpenAIMessages(messages),
 		Stream:   stream,
 	}
+	if stream {
+		r.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
 	if len(tools) > 0 {
 		r.Tools = convertToOpenAITools(tools)
 	}
@@ -294,7 +378,7 @@ func (p *OpenAIProvider) checkError(resp *http.Response) error {
 func (p *OpenAIProvider) parseResponse(raw *openAIChatResponse, model string, start time.Time) *Response {
 	r := &Response{
 		Model:    raw.Model,
-		Provider: ProviderOpenAI,
+		Provider: p.Name(),
 		Latency:  time.Since(start),
 		Usage: Usage{
 			PromptTokens:     raw.Usage.PromptTokens,
@@ -321,6 +405,20 @@ func (p *OpenAIProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk) {
 	defer close(ch)
 	defer body.Close()
 
+	// The usage arrives in a chunk of its own after the finish reason, so
+	// the finishing chunk is held back and sent with it.
+	var final *StreamChunk
+	var usage *Usage
+	finish := func(done bool) {
+		sc := StreamChunk{}
+		if final != nil {
+			sc = *final
+		}
+		sc.Done = sc.Done || done
+		sc.Usage = usage
+		ch <- sc
+	}
+
 	scanner := bufio.NewScanner(body)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -329,7 +427,7 @@ func (p *OpenAIProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk) {
 		}
 		data := strings.TrimPrefix(line, "data: ")
 		if data == "[DONE]" {
-			ch <- StreamChunk{Done: true}
+			finish(true)
 			return
 		}
 
@@ -338,6 +436,13 @@ func (p *OpenAIProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk) {
 			ch <- StreamChunk{Err: fmt.Errorf("openai: stream parse: %w", err)}
 			return
 		}
+		if u := chunk.Usage; u.PromptTokens > 0 || u.CompletionTokens > 0 {
+			usage = &Usage{
+				PromptTokens:     u.PromptTokens,
+				CompletionTokens: u.CompletionTokens,
+				TotalTokens:      u.TotalTokens,
+			}
+		}
 		if len(chunk.Choices) == 0 {
 			continue
 		}
@@ -356,11 +461,17 @@ func (p *OpenAIProvider) readStream(body io.ReadCloser, ch chan<- StreamChunk) {
 			if fr == "stop" {
 				sc.Done = true
 			}
+			final = &sc
+			continue
 		}
 		ch <- sc
 	}
 	if err := scanner.Err(); err != nil {
 		ch <- StreamChunk{Err: fmt.Errorf("openai: stream read: %w", err)}
+		return
+	}
+	if final != nil || usage != nil {
+		finish(false)
 	}
 }
 
@@ -0,0 +1,78 @@
+package llm
+
+import "strings"
+
+// ModelPricing is the list price of a model in USD per million tokens.
+type ModelPricing struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// Cost returns the USD cost of a request with the given token counts.
+func (p ModelPricing) Cost(promptTokens, completionTokens int) float64 {
+	return float64(promptTokens)*p.InputPerMillion/1_000_000 +
+		float64(completionTokens)*p.OutputPerMillion/1_000_000
+}
+
+// modelPricing holds published list prices for the hosted models we support.
+// Prices change; treat estimates derived from this table as approximate.
+var modelPricing = map[string]ModelPricing{
+	// OpenAI
+	"gpt-4o":        {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+	"gpt-4o-mini":   {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+	"gpt-4-turbo":   {InputPerMillion: 10.00, OutputPerMillion: 30.00},
+	"gpt-4":         {InputPerMillion: 30.00, OutputPerMillion: 60.00},
+	"gpt-3.5-turbo": {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+	"o1":            {InputPerMillion: 15.00, OutputPerMillion: 60.00},
+	"o1-mini":       {InputPerMillion: 1.10, OutputPerMillion: 4.40},
+	"o3-mini":       {InputPerMillion: 1.10, OutputPerMillion: 4.40},
+
+	// Anthropic
+	"claude-opus-4":     {InputPerMillion: 15.00, OutputPerMillion: 75.00},
+	"claude-sonnet-4":   {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"claude-3-7-sonnet": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"claude-3-5-sonnet": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"claude-3-5-haiku":  {InputPerMillion: 0.80, OutputPerMillion: 4.00},
+	"claude-3-haiku":    {InputPerMillion: 0.25, OutputPerMillion: 1.25},
+
+	// Gemini
+	"gemini-2.0-flash":      {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	"gemini-2.0-flash-lite": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+	"gemini-1.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 5.00},
+	"gemini-1.5-flash":      {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+	"gemini-1.5-flash-8b":   {InputPerMillion: 0.0375, OutputPerMillion: 0.15},
+
+	// xAI Grok
+	"grok-3":      {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"grok-3-mini": {InputPerMillion: 0.30, OutputPerMillion: 0.50},
+	"grok-2":      {InputPerMillion: 2.00, OutputPerMillion: 10.00},
+}
+
+// LookupPricing returns the pricing for a model. Dated model IDs such as
+// "claude-sonnet-4-20250514" resolve to their family entry by longest prefix.
+// Local Ollama models are free. ok is false when the model is unknown.
+func LookupPricing(model string) (ModelPricing, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return ModelPricing{}, false
+	}
+	if p, ok := modelPricing[model]; ok {
+		return p, true
+	}
+	for _, m := range ollamaModels {
+		if m == model {
+			return ModelPricing{}, true
+		}
+	}
+
+	best := ""
+	for name := range modelPricing {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPricing{}, false
+	}
+	return modelPricing[best], true
+}
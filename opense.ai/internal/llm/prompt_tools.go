@@ -0,0 +1,122 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// ── Prompt-based Tool Protocol ──
+//
+// For models without native tool calling, tools are described in the
+// system prompt and the model requests calls by replying with a block
+// such as:
+//
+//	```json
+//	{"tool_calls": [{"name": "get_quote", "arguments": {"ticker": "TCS"}}]}
+//	```
+
+// promptCallSeq numbers parsed calls. Models replying in text have no call
+// IDs of their own, so IDs are drawn from one sequence to keep them
+// distinct across the turns of a conversation.
+var promptCallSeq atomic.Uint64
+
+// promptToolCall is one call in a tool_calls block.
+type promptToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// toolPrompt describes tools and the tool_calls reply format.
+func toolPrompt(tools []Tool) string {
+	var sb strings.Builder
+	sb.WriteString("## Tools\n")
+	sb.WriteString("You can call the following tools. Arguments are JSON objects matching each tool's parameter schema.\n\n")
+	for _, t := range tools {
+		fmt.Fprintf(&sb, "- %s: %s\n", t.Name, t.Description)
+		if t.Parameters != nil {
+			if schema, err := json.Marshal(t.Parameters); err == nil {
+				fmt.Fprintf(&sb, "  Parameters: %s\n", schema)
+			}
+		}
+	}
+	sb.WriteString("\nTo call tools, reply with only a JSON block in this exact form:\n")
+	sb.WriteString("```json\n{\"tool_calls\": [{\"name\": \"<tool name>\", \"arguments\": {}}]}\n```\n")
+	sb.WriteString("Tool results are returned in the next message. Once you have what you need, answer normally without a tool_calls block.")
+	return sb.String()
+}
+
+// formatPromptToolCalls writes calls back as a tool_calls block, so that a
+// conversation replayed to the model shows the calls it made.
+func formatPromptToolCalls(calls []ToolCall) string {
+	block := struct {
+		ToolCalls []promptToolCall `json:"tool_calls"`
+	}{}
+	for _, tc := range calls {
+		args := tc.Arguments
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		block.ToolCalls = append(block.ToolCalls, promptToolCall{Name: tc.Name, Arguments: args})
+	}
+	data, _ := json.Marshal(block)
+	return "```json\n" + string(data) + "\n```"
+}
+
+// parsePromptToolCalls finds the first JSON object in content that
+// requests tools — a {"tool_calls": [...]} block, or a single
+// {"name": ..., "arguments": ...} call — and returns its calls along with
+// the content around it. Calls to tools not in tools are ignored, so an
+// analysis that happens to contain a "name" field is not mistaken for one.
+func parsePromptToolCalls(content string, tools []Tool) ([]ToolCall, string) {
+	known := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		known[t.Name] = true
+	}
+
+	for i := strings.IndexByte(content, '{'); i >= 0; {
+		dec := json.NewDecoder(strings.NewReader(content[i:]))
+		var block struct {
+			ToolCalls []promptToolCall `json:"tool_calls"`
+			promptToolCall
+		}
+		if err := dec.Decode(&block); err == nil {
+			if len(block.ToolCalls) == 0 && block.Name != "" {
+				block.ToolCalls = []promptToolCall{block.promptToolCall}
+			}
+			var calls []ToolCall
+			for _, c := range block.ToolCalls {
+				if !known[c.Name] {
+					continue
+				}
+				args := c.Arguments
+				if len(args) == 0 || string(args) == "null" {
+					args = json.RawMessage("{}")
+				}
+				calls = append(calls, ToolCall{ID: fmt.Sprintf("call_%d", promptCallSeq.Add(1)), Name: c.Name, Arguments: args})
+			}
+			if len(calls) > 0 {
+				end := i + int(dec.InputOffset())
+				return calls, stripToolBlock(content[:i], content[end:])
+			}
+		}
+		next := strings.IndexByte(content[i+1:], '{')
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+	return nil, content
+}
+
+// stripToolBlock joins the text before and after a tool_calls object,
+// dropping the code fence around it.
+func stripToolBlock(before, after string) string {
+	before = strings.TrimRight(before, " \t\n")
+	before = strings.TrimSuffix(before, "```json")
+	before = strings.TrimSuffix(before, "```")
+	after = strings.TrimLeft(after, " \t\n")
+	after = strings.TrimPrefix(after, "```")
+	return strings.TrimSpace(strings.TrimSpace(before) + "\n" + strings.TrimSpace(after))
+}
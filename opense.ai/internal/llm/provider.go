@@ -1,5 +1,5 @@
 // Package llm provides a unified interface for multiple LLM providers
-// (OpenAI, Ollama, Gemini, Anthropic) with tool/function calling support,
+// (OpenAI, Azure OpenAI, Ollama, Gemini, Anthropic) with tool/function calling support,
 // streaming, and model routing with fallback.
 package llm
 
@@ -17,6 +17,8 @@ const (
 	ProviderOllama    = "ollama"
 	ProviderGemini    = "gemini"
 	ProviderAnthropic = "anthropic"
+	ProviderAzure     = "azure"
+	ProviderGrok      = "grok"
 )
 
 // Common errors returned by LLM providers.
@@ -91,6 +93,7 @@ type StreamChunk struct {
 	ToolCalls    []ToolCall   `json:"tool_calls,omitempty"`
 	FinishReason FinishReason `json:"finish_reason,omitempty"`
 	Done         bool         `json:"done"`
+	Usage        *Usage       `json:"usage,omitempty"` // set on the last chunk when the provider reports token counts
 	Err          error        `json:"-"`
 }
 
@@ -2,6 +2,9 @@ package llm
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -30,6 +33,9 @@ type Router struct {
 	modelMap    map[TaskComplexity]string // complexity → model override
 	maxRetries  int
 	retryDelay  time.Duration
+	cache       *responseCache // nil unless WithResponseCache is set
+
+	streamResumes int // continuation attempts after a stream drops
 }
 
 // RouterOption configures the router.
@@ -55,6 +61,27 @@ func WithRetryDelay(d time.Duration) RouterOption {
 	return func(r *Router) { r.retryDelay = d }
 }
 
+// WithStreamResumes sets how many times ChatStream asks the provider to
+// continue a response whose stream closed before finishing. Zero disables
+// resuming.
+func WithStreamResumes(n int) RouterOption {
+	return func(r *Router) { r.streamResumes = n }
+}
+
+// WithResponseCache caches successful Chat responses for ttl, keyed by a
+// hash of the model, messages, tools, and options, so an identical request
+// repeated within ttl (such as a retry after a partial failure) is answered
+// without calling a provider again. A ttl of zero or less disables caching.
+func WithResponseCache(ttl time.Duration) RouterOption {
+	return func(r *Router) {
+		if ttl > 0 {
+			r.cache = newResponseCache(ttl)
+		} else {
+			r.cache = nil
+		}
+	}
+}
+
 // NewRouter creates a new LLM router with the given primary provider.
 func NewRouter(primary string, opts ...RouterOption) *Router {
 	r := &Router{
@@ -63,6 +90,8 @@ func NewRouter(primary string, opts ...RouterOption) *Router {
 		modelMap:   make(map[TaskComplexity]string),
 		maxRetries: 2,
 		retryDelay: 1 * time.Second,
+
+		streamResumes: 2,
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -104,6 +133,14 @@ func (r *Router) Chat(ctx context.Context, messages []Message, tools []Tool, opt
 		return nil, ErrNoProviders
 	}
 
+	var key string
+	if r.cache != nil {
+		key = r.cacheKey(messages, tools, opts)
+		if resp, ok := r.cache.get(key); ok {
+			return resp, nil
+		}
+	}
+
 	var lastErr error
 	for _, providerName := range chain {
 		provider, ok := r.GetProvider(providerName)
@@ -113,6 +150,9 @@ func (r *Router) Chat(ctx context.Context, messages []Message, tools []Tool, opt
 
 		resp, err := r.chatWithRetry(ctx, provider, messages, tools, opts)
 		if err == nil {
+			if r.cache != nil {
+				r.cache.put(key, resp)
+			}
 			return resp, nil
 		}
 
@@ -134,6 +174,10 @@ func (r *Router) Chat(ctx context.Context, messages []Message, tools []Tool, opt
 }
 
 // ChatStream routes a streaming request using the same fallback chain.
+// If the stream closes or fails before a finish reason after text has
+// arrived, the same provider is asked to continue from the partial
+// response, up to the WithStreamResumes limit. The continuation is
+// forwarded on the same channel, so the consumer sees one stitched stream.
 func (r *Router) ChatStream(ctx context.Context, messages []Message, tools []Tool, opts *ChatOptions) (<-chan StreamChunk, error) {
 	chain := r.providerChain()
 	if len(chain) == 0 {
@@ -149,7 +193,12 @@ func (r *Router) ChatStream(ctx context.Context, messages []Message, tools []Too
 
 		ch, err := provider.ChatStream(ctx, messages, tools, opts)
 		if err == nil {
-			return ch, nil
+			if r.streamResumes <= 0 {
+				return ch, nil
+			}
+			out := make(chan StreamChunk, 16)
+			go r.resumeStream(ctx, provider, messages, tools, opts, ch, out)
+			return out, nil
 		}
 
 		lastErr = err
@@ -166,6 +215,67 @@ func (r *Router) ChatStream(ctx context.Context, messages []Message, tools []Too
 	return nil, fmt.Errorf("llm/router: all stream providers failed, last error: %w", lastErr)
 }
 
+// streamContinuationPrompt asks the model to carry on from a response that
+// was cut off in transit.
+const streamContinuationPrompt = "Your previous response was cut off. Continue it exactly where it stopped, " +
+	"without repeating any text already written and without any preamble."
+
+// resumeStream forwards chunks from ch to out. When ch ends without a finish
+// reason, it re-requests a continuation of the partial content and forwards
+// that too. A dropped stream that carried tool calls, or no text at all, is
+// not resumed, since there is nothing meaningful to continue from.
+func (r *Router) resumeStream(ctx context.Context, provider LLMProvider,
+	messages []Message, tools []Tool, opts *ChatOptions, ch <-chan StreamChunk, out chan<- StreamChunk) {
+	defer close(out)
+	send := func(c StreamChunk) bool {
+		select {
+		case out <- c:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var partial strings.Builder
+	for resumes := 0; ; resumes++ {
+		finished, sawTools := false, false
+		var streamErr error
+		for chunk := range ch {
+			if chunk.Err != nil {
+				streamErr = chunk.Err
+				continue
+			}
+			partial.WriteString(chunk.Content)
+			sawTools = sawTools || len(chunk.ToolCalls) > 0
+			finished = finished || chunk.FinishReason != "" || chunk.Done
+			if !send(chunk) {
+				return
+			}
+		}
+		if finished && streamErr == nil {
+			return
+		}
+		if streamErr == nil {
+			streamErr = ErrStreamClosed
+		}
+		if finished || sawTools || partial.Len() == 0 || resumes >= r.streamResumes || ctx.Err() != nil {
+			send(StreamChunk{Err: streamErr})
+			return
+		}
+
+		log.Printf("llm/router: stream from %s dropped after %d bytes (%v), resuming", provider.Name(), partial.Len(), streamErr)
+		cont := make([]Message, 0, len(messages)+2)
+		cont = append(cont, messages...)
+		cont = append(cont, AssistantMessage(partial.String()), UserMessage(streamContinuationPrompt))
+		next, err := provider.ChatStream(ctx, cont, tools, opts)
+		if err != nil {
+			send(StreamChunk{Err: fmt.Errorf("llm/router: resume stream: %w", err)})
+			return
+		}
+		ch = next
+	}
+}
+
 // ChatWithComplexity routes based on task complexity, selecting the appropriate model.
 func (r *Router) ChatWithComplexity(ctx context.Context, complexity TaskComplexity,
 	messages []Message, tools []Tool, opts *ChatOptions) (*Response, error) {
@@ -297,6 +407,69 @@ func (r *Router) chatWithRetry(ctx context.Context, provider LLMProvider,
 	return nil, lastErr
 }
 
+// cacheKey hashes everything that determines a Chat response. Tool
+// handlers are excluded by Tool's JSON encoding.
+func (r *Router) cacheKey(messages []Message, tools []Tool, opts *ChatOptions) string {
+	r.mu.RLock()
+	primary := r.primary
+	r.mu.RUnlock()
+
+	b, _ := json.Marshal(struct {
+		Primary  string       `json:"primary"`
+		Messages []Message    `json:"messages"`
+		Tools    []Tool       `json:"tools"`
+		Options  *ChatOptions `json:"options"`
+	}{primary, messages, tools, opts})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// responseCache is a TTL cache of Chat responses.
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	resp    Response
+	expires time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, entries: make(map[string]cacheEntry), now: time.Now}
+}
+
+// get returns a copy of the cached response for key if it has not expired.
+func (c *responseCache) get(key string) (*Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	resp := e.resp
+	return &resp, true
+}
+
+// put stores a copy of resp under key and evicts expired entries.
+func (c *responseCache) put(key string, resp *Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{resp: *resp, expires: now.Add(c.ttl)}
+}
+
 func isNonRetryable(err error) bool {
 	if err == nil {
 		return false
@@ -310,8 +483,16 @@ func isNonRetryable(err error) bool {
 }
 
 // NewRouterFromConfig creates a fully configured Router from the application config.
-// It instantiates the appropriate providers based on available API keys.
+// It instantiates the appropriate providers based on available API keys,
+// after applying cfg.LLM.HTTP to the transport they share.
 func NewRouterFromConfig(cfg *config.Config) (*Router, error) {
+	ConfigureTransport(TransportConfig{
+		MaxIdleConns:        cfg.LLM.HTTP.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.LLM.HTTP.MaxIdleConnsPerHost,
+		IdleConnTimeout:     time.Duration(cfg.LLM.HTTP.IdleConnTimeoutSec) * time.Second,
+		DisableHTTP2:        cfg.LLM.HTTP.DisableHTTP2,
+	})
+
 	router := NewRouter(cfg.LLM.Primary,
 		WithMaxRetries(2),
 		WithRetryDelay(time.Second),
@@ -341,6 +522,18 @@ func NewRouterFromConfig(cfg *config.Config) (*Router, error) {
 		}
 	}
 
+	// Register Azure OpenAI if a deployment is configured
+	if az := cfg.LLM.Azure; az.APIKey != "" && az.Endpoint != "" && az.Deployment != "" {
+		p, err := NewAzureOpenAIProvider(az.Endpoint, az.Deployment, az.APIKey, az.APIVersion)
+		if err == nil {
+			router.RegisterProvider(p)
+			registered++
+			if cfg.LLM.Primary != ProviderAzure {
+				fallbacks = append(fallbacks, ProviderAzure)
+			}
+		}
+	}
+
 	// Register Ollama (no key needed, just URL)
 	if cfg.LLM.OllamaURL != "" {
 		model := cfg.LLM.Model
@@ -349,6 +542,7 @@ func NewRouterFromConfig(cfg *config.Config) (*Router, error) {
 		}
 		p, err := NewOllamaProvider(cfg.LLM.OllamaURL,
 			WithOllamaModel(model),
+			WithOllamaPromptTools(cfg.LLM.OllamaPromptTools),
 		)
 		if err == nil {
 			router.RegisterProvider(p)
@@ -387,6 +581,20 @@ func NewRouterFromConfig(cfg *config.Config) (*Router, error) {
 		}
 	}
 
+	// Register xAI Grok if key is available
+	if cfg.LLM.GrokKey != "" {
+		p, err := NewGrokProvider(cfg.LLM.GrokKey,
+			WithGrokModel(defaultGrokModel(cfg.LLM.Model)),
+		)
+		if err == nil {
+			router.RegisterProvider(p)
+			registered++
+			if cfg.LLM.Primary != ProviderGrok {
+				fallbacks = append(fallbacks, ProviderGrok)
+			}
+		}
+	}
+
 	if registered == 0 {
 		return nil, ErrNoProviders
 	}
@@ -404,6 +612,8 @@ func selectSimpleModel(provider string) string {
 		return "gemini-2.0-flash-lite"
 	case ProviderAnthropic:
 		return "claude-3-5-haiku-20241022"
+	case ProviderGrok:
+		return "grok-3-mini"
 	default:
 		return "" // use default
 	}
@@ -422,3 +632,10 @@ func defaultAnthropicModel(model string) string {
 	}
 	return "claude-sonnet-4-20250514"
 }
+
+func defaultGrokModel(model string) string {
+	if strings.HasPrefix(model, "grok") {
+		return model
+	}
+	return "grok-3"
+}
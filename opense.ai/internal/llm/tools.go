@@ -3,8 +3,10 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 )
 
 // Tool represents a function/tool that can be called by the LLM.
@@ -18,6 +20,33 @@ type Tool struct {
 // ToolHandler is a function that executes a tool call and returns a string result.
 type ToolHandler func(ctx context.Context, args json.RawMessage) (string, error)
 
+// TransientError marks a tool failure that may succeed if retried, such as a
+// data source timeout or a stale/invalid upstream response. RunToolLoop
+// retries such calls once before reporting the error to the model.
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string { return "transient: " + e.Err.Error() }
+func (e *TransientError) Unwrap() error { return e.Err }
+
+// Transient wraps err as a TransientError. Returns nil if err is nil.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TransientError{Err: err}
+}
+
+// IsTransient reports whether err is or wraps a TransientError.
+func IsTransient(err error) bool {
+	var te *TransientError
+	return errors.As(err, &te)
+}
+
+// toolRetryDelay is the backoff before retrying transiently failed tool calls.
+var toolRetryDelay = 500 * time.Millisecond
+
 // JSONSchema represents a JSON Schema definition for tool parameters.
 type JSONSchema struct {
 	Type        string                 `json:"type"`
@@ -148,14 +177,22 @@ func (r *ToolRegistry) Execute(ctx context.Context, call ToolCall) (string, erro
 	return tool.Handler(ctx, call.Arguments)
 }
 
-// ExecuteAll runs all tool calls concurrently and returns results in order.
-func (r *ToolRegistry) ExecuteAll(ctx context.Context, calls []ToolCall) []ToolResult {
+// ExecuteAll runs all tool calls concurrently, at most maxParallel at a
+// time, and returns results in call order. A maxParallel of zero or less
+// runs every call at once.
+func (r *ToolRegistry) ExecuteAll(ctx context.Context, calls []ToolCall, maxParallel int) []ToolResult {
+	if maxParallel <= 0 || maxParallel > len(calls) {
+		maxParallel = len(calls)
+	}
+	sem := make(chan struct{}, maxParallel)
 	results := make([]ToolResult, len(calls))
 	var wg sync.WaitGroup
 	for i, call := range calls {
 		wg.Add(1)
 		go func(idx int, c ToolCall) {
 			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 			output, err := r.Execute(ctx, c)
 			results[idx] = ToolResult{
 				ToolCallID: c.ID,
@@ -188,11 +225,13 @@ func (tr ToolResult) ToMessage() Message {
 
 // RunToolLoop executes the LLM tool-calling loop:
 // 1. Send messages to LLM
-// 2. If LLM returns tool calls, execute them
-// 3. Append tool results to messages
+// 2. If LLM returns tool calls, execute them concurrently
+// 3. Append tool results to messages in call order
 // 4. Repeat until LLM returns a text response or maxIterations is reached
+//
+// At most maxParallel tool calls run at once; zero means no limit.
 func RunToolLoop(ctx context.Context, provider LLMProvider, registry *ToolRegistry,
-	messages []Message, tools []Tool, opts *ChatOptions, maxIterations int) (*Response, []Message, error) {
+	messages []Message, tools []Tool, opts *ChatOptions, maxIterations, maxParallel int) (*Response, []Message, error) {
 
 	if maxIterations <= 0 {
 		maxIterations = 10
@@ -216,8 +255,11 @@ func RunToolLoop(ctx context.Context, provider LLMProvider, registry *ToolRegist
 		// Append the assistant message with tool calls
 		msgs = append(msgs, AssistantToolCallMessage(resp.ToolCalls))
 
-		// Execute all tool calls
-		results := registry.ExecuteAll(ctx, resp.ToolCalls)
+		// Execute all tool calls, retrying transient failures once
+		results := registry.ExecuteAll(ctx, resp.ToolCalls, maxParallel)
+		if err := retryTransient(ctx, registry, resp.ToolCalls, results, maxParallel); err != nil {
+			return nil, msgs, err
+		}
 
 		// Append tool results as messages
 		for _, result := range results {
@@ -227,3 +269,31 @@ func RunToolLoop(ctx context.Context, provider LLMProvider, registry *ToolRegist
 
 	return nil, msgs, fmt.Errorf("llm: tool loop exceeded %d iterations", maxIterations)
 }
+
+// retryTransient re-executes, after toolRetryDelay, the calls whose results
+// failed with a TransientError, replacing those results in place. Only
+// context cancellation during the backoff is returned as an error.
+func retryTransient(ctx context.Context, registry *ToolRegistry, calls []ToolCall, results []ToolResult, maxParallel int) error {
+	var idx []int
+	var retry []ToolCall
+	for i, r := range results {
+		if IsTransient(r.Err) {
+			idx = append(idx, i)
+			retry = append(retry, calls[i])
+		}
+	}
+	if len(retry) == 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(toolRetryDelay):
+	}
+
+	for j, r := range registry.ExecuteAll(ctx, retry, maxParallel) {
+		results[idx[j]] = r
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TransportConfig tunes the connection pool shared by the providers'
+// default HTTP clients. Zero fields take the DefaultTransportConfig value.
+type TransportConfig struct {
+	MaxIdleConns        int           // idle connections kept across all hosts
+	MaxIdleConnsPerHost int           // idle connections kept per provider host
+	IdleConnTimeout     time.Duration // how long an idle connection is kept
+	DisableHTTP2        bool          // use HTTP/1.1 only
+}
+
+// DefaultTransportConfig returns the pool settings used until
+// ConfigureTransport is called. net/http keeps only two idle connections
+// per host, so concurrent agents calling one provider would otherwise
+// keep opening and closing connections.
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 16,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+var (
+	transportMu     sync.Mutex
+	sharedTransport = NewTransport(DefaultTransportConfig())
+)
+
+// NewTransport returns an http.Transport with the proxy, dial, and TLS
+// settings of http.DefaultTransport and the pool settings of cfg.
+func NewTransport(cfg TransportConfig) *http.Transport {
+	def := DefaultTransportConfig()
+	if cfg.MaxIdleConns <= 0 {
+		cfg.MaxIdleConns = def.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = def.MaxIdleConnsPerHost
+	}
+	if cfg.IdleConnTimeout <= 0 {
+		cfg.IdleConnTimeout = def.IdleConnTimeout
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = cfg.MaxIdleConns
+	t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	t.IdleConnTimeout = cfg.IdleConnTimeout
+	t.ForceAttemptHTTP2 = !cfg.DisableHTTP2
+	if cfg.DisableHTTP2 {
+		// A non-nil empty map stops net/http from negotiating h2.
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return t
+}
+
+// ConfigureTransport replaces the shared transport used by providers
+// created afterwards without a custom HTTP client. Providers that already
+// exist keep the transport they were created with.
+func ConfigureTransport(cfg TransportConfig) {
+	t := NewTransport(cfg)
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	sharedTransport = t
+}
+
+// defaultHTTPClient returns a client on the shared transport with the
+// given overall request timeout.
+func defaultHTTPClient(timeout time.Duration) *http.Client {
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	return &http.Client{Timeout: timeout, Transport: sharedTransport}
+}
@@ -0,0 +1,210 @@
+// Package mcp serves llm.Tool handlers to external agents over the Model
+// Context Protocol: newline-delimited JSON-RPC 2.0 on stdio.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/seenimoa/openseai/internal/llm"
+)
+
+// ProtocolVersion is the MCP revision this server implements.
+const ProtocolVersion = "2024-11-05"
+
+// JSON-RPC 2.0 error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// maxMessageSize bounds a single JSON-RPC message read from the client.
+const maxMessageSize = 4 << 20
+
+// Server dispatches MCP requests to a tool registry.
+type Server struct {
+	name     string
+	version  string
+	registry *llm.ToolRegistry
+}
+
+// NewServer creates a server advertising the given tools. name and version
+// identify the server in the initialize handshake.
+func NewServer(name, version string, tools []llm.Tool) *Server {
+	reg := llm.NewToolRegistry()
+	for _, t := range tools {
+		reg.Register(t)
+	}
+	return &Server{name: name, version: version, registry: reg}
+}
+
+// request is an incoming JSON-RPC message. A missing ID marks a notification.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// toolInfo is a tool as advertised by tools/list.
+type toolInfo struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema *llm.JSONSchema `json:"inputSchema"`
+}
+
+type callParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content []content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+// Serve reads requests from r and writes responses to w until r is
+// exhausted or ctx is cancelled. Tool calls run concurrently, so their
+// responses may be written out of order; clients match them by ID.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	enc := json.NewEncoder(w)
+	write := func(resp *response) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(resp)
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for sc.Scan() {
+		if ctx.Err() != nil {
+			break
+		}
+		line := sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			write(errorResponse(json.RawMessage("null"), codeParseError, "parse error: "+err.Error()))
+			continue
+		}
+
+		if req.Method != "tools/call" {
+			if resp := s.handle(ctx, req); resp != nil {
+				write(resp)
+			}
+			continue
+		}
+		wg.Add(1)
+		go func(req request) {
+			defer wg.Done()
+			if resp := s.handle(ctx, req); resp != nil {
+				write(resp)
+			}
+		}(req)
+	}
+	wg.Wait()
+	return sc.Err()
+}
+
+// handle dispatches one request. It returns nil for notifications.
+func (s *Server) handle(ctx context.Context, req request) *response {
+	if len(req.ID) == 0 {
+		return nil
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "invalid JSON-RPC 2.0 request")
+	}
+
+	switch req.Method {
+	case "initialize":
+		return result(req.ID, map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": s.name, "version": s.version},
+		})
+	case "ping":
+		return result(req.ID, map[string]any{})
+	case "tools/list":
+		return result(req.ID, map[string]any{"tools": s.listTools()})
+	case "tools/call":
+		var p callParams
+		if err := json.Unmarshal(req.Params, &p); err != nil || p.Name == "" {
+			return errorResponse(req.ID, codeInvalidParams, "tools/call requires a tool name")
+		}
+		if _, ok := s.registry.Get(p.Name); !ok {
+			return errorResponse(req.ID, codeInvalidParams, fmt.Sprintf("unknown tool %q", p.Name))
+		}
+		return result(req.ID, s.callTool(ctx, p))
+	default:
+		return errorResponse(req.ID, codeMethodNotFound, fmt.Sprintf("method %q not found", req.Method))
+	}
+}
+
+// listTools returns the registered tools sorted by name.
+func (s *Server) listTools() []toolInfo {
+	tools := s.registry.List()
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+
+	out := make([]toolInfo, len(tools))
+	for i, t := range tools {
+		schema := t.Parameters
+		if schema == nil {
+			schema = &llm.JSONSchema{Type: "object"}
+		}
+		out[i] = toolInfo{Name: t.Name, Description: t.Description, InputSchema: schema}
+	}
+	return out
+}
+
+// callTool runs a tool. Handler errors are reported to the client as a
+// tool result with isError set, per MCP, rather than as a JSON-RPC error.
+func (s *Server) callTool(ctx context.Context, p callParams) callResult {
+	args := p.Arguments
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	out, err := s.registry.Execute(ctx, llm.ToolCall{Name: p.Name, Arguments: args})
+	if err != nil {
+		return callResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}
+	}
+	return callResult{Content: []content{{Type: "text", Text: out}}}
+}
+
+func result(id json.RawMessage, v any) *response {
+	return &response{JSONRPC: "2.0", ID: id, Result: v}
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) *response {
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: msg}}
+}
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/seenimoa/openseai/internal/llm"
+)
+
+func testTools() []llm.Tool {
+	return []llm.Tool{
+		{
+			Name:        "get_quote",
+			Description: "Get latest stock quote",
+			Parameters: llm.ObjectSchema("Quote parameters",
+				map[string]*llm.JSONSchema{"ticker": llm.StringProp("NSE ticker symbol")},
+				"ticker",
+			),
+			Handler: func(_ context.Context, args json.RawMessage) (string, error) {
+				var p struct{ Ticker string }
+				if err := json.Unmarshal(args, &p); err != nil {
+					return "", err
+				}
+				return `{"ticker":"` + p.Ticker + `","last_price":2850.5}`, nil
+			},
+		},
+		{
+			Name:        "compute_indicators",
+			Description: "Compute technical indicators",
+			Handler: func(context.Context, json.RawMessage) (string, error) {
+				return "", errors.New("no data")
+			},
+		},
+	}
+}
+
+// serve runs the server over the given request lines and returns the
+// responses keyed by request ID.
+func serve(t *testing.T, lines ...string) map[string]response {
+	t.Helper()
+	var out strings.Builder
+	srv := NewServer("openseai", "test", testTools())
+	if err := srv.Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")), &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+
+	resps := make(map[string]response)
+	sc := bufio.NewScanner(strings.NewReader(out.String()))
+	for sc.Scan() {
+		var r struct {
+			response
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("bad response %q: %v", sc.Text(), err)
+		}
+		r.response.Result = r.Result
+		resps[string(r.ID)] = r.response
+	}
+	return resps
+}
+
+func TestServer_ToolsList(t *testing.T) {
+	resps := serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+	)
+	if len(resps) != 2 {
+		t.Fatalf("expected 2 responses (notification gets none), got %d", len(resps))
+	}
+
+	var list struct {
+		Tools []toolInfo `json:"tools"`
+	}
+	if err := json.Unmarshal(resps["2"].Result.(json.RawMessage), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Tools) != 2 || list.Tools[0].Name != "compute_indicators" || list.Tools[1].Name != "get_quote" {
+		t.Fatalf("unexpected tools: %+v", list.Tools)
+	}
+	if list.Tools[1].InputSchema == nil || list.Tools[1].InputSchema.Required[0] != "ticker" {
+		t.Errorf("expected get_quote input schema to require ticker, got %+v", list.Tools[1].InputSchema)
+	}
+	if list.Tools[0].InputSchema == nil || list.Tools[0].InputSchema.Type != "object" {
+		t.Error("tools without parameters should advertise an empty object schema")
+	}
+}
+
+func TestServer_ToolsCall(t *testing.T) {
+	resps := serve(t,
+		`{"jsonrpc":"2.0","id":"q","method":"tools/call","params":{"name":"get_quote","arguments":{"ticker":"RELIANCE"}}}`,
+		`{"jsonrpc":"2.0","id":"e","method":"tools/call","params":{"name":"compute_indicators","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":"u","method":"tools/call","params":{"name":"place_order"}}`,
+		`{"jsonrpc":"2.0","id":"m","method":"resources/list"}`,
+	)
+
+	var quote callResult
+	if err := json.Unmarshal(resps[`"q"`].Result.(json.RawMessage), &quote); err != nil {
+		t.Fatal(err)
+	}
+	if quote.IsError || len(quote.Content) != 1 || !strings.Contains(quote.Content[0].Text, `"last_price":2850.5`) {
+		t.Errorf("unexpected get_quote result: %+v", quote)
+	}
+
+	var failed callResult
+	if err := json.Unmarshal(resps[`"e"`].Result.(json.RawMessage), &failed); err != nil {
+		t.Fatal(err)
+	}
+	if !failed.IsError || failed.Content[0].Text != "no data" {
+		t.Errorf("expected handler error as isError result, got %+v", failed)
+	}
+
+	if e := resps[`"u"`].Error; e == nil || e.Code != codeInvalidParams {
+		t.Errorf("expected invalid params for unknown tool, got %+v", e)
+	}
+	if e := resps[`"m"`].Error; e == nil || e.Code != codeMethodNotFound {
+		t.Errorf("expected method not found, got %+v", e)
+	}
+}
@@ -120,7 +120,7 @@ func toYFTicker(symbol string) string {
 	if strings.Contains(symbol, ".") || strings.HasPrefix(symbol, "^") {
 		return symbol
 	}
-	return utils.ToYFinanceTicker(symbol)
+	return utils.ToYahooSymbol(symbol, "")
 }
 
 // fromYFTicker converts a Yahoo Finance ticker back to canonical form.
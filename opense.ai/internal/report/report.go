@@ -24,11 +24,12 @@ const (
 	FormatText ReportFormat = "text"
 )
 
-// ReportSection identifies a section to include/exclude.
+// ReportSection identifies a section to include/exclude. Sections render in
+// the order they appear in ReportConfig.Sections.
 type ReportSection string
 
 const (
-	SectionSummary      ReportSection = "summary"
+	SectionSummary      ReportSection = "summary" // quote bar and price chart
 	SectionFundamental  ReportSection = "fundamental"
 	SectionTechnical    ReportSection = "technical"
 	SectionDerivatives  ReportSection = "derivatives"
@@ -37,19 +38,50 @@ const (
 	SectionRecommend    ReportSection = "recommendation"
 )
 
-// AllSections returns all report sections in display order.
+// AllSections returns all report sections in default display order.
 func AllSections() []ReportSection {
 	return []ReportSection{
 		SectionSummary,
+		SectionRecommend,
 		SectionFundamental,
 		SectionTechnical,
 		SectionDerivatives,
 		SectionSentiment,
 		SectionRisk,
-		SectionRecommend,
 	}
 }
 
+// ParseSections parses a comma-separated list of section identifiers such as
+// "technical,fundamental,risk", preserving the given order. An empty list
+// selects AllSections. Unknown or repeated names are an error.
+func ParseSections(list string) ([]ReportSection, error) {
+	if strings.TrimSpace(list) == "" {
+		return AllSections(), nil
+	}
+
+	valid := make(map[ReportSection]bool)
+	names := make([]string, 0, len(AllSections()))
+	for _, s := range AllSections() {
+		valid[s] = true
+		names = append(names, string(s))
+	}
+
+	var sections []ReportSection
+	seen := make(map[ReportSection]bool)
+	for _, part := range strings.Split(list, ",") {
+		sec := ReportSection(strings.ToLower(strings.TrimSpace(part)))
+		if !valid[sec] {
+			return nil, fmt.Errorf("unknown report section %q (valid: %s)", part, strings.Join(names, ", "))
+		}
+		if seen[sec] {
+			return nil, fmt.Errorf("report section %q listed more than once", sec)
+		}
+		seen[sec] = true
+		sections = append(sections, sec)
+	}
+	return sections, nil
+}
+
 // ReportConfig controls report generation behaviour.
 type ReportConfig struct {
 	Format   ReportFormat    // output format (default: HTML)
@@ -122,6 +154,10 @@ type ReportData struct {
 	StopLoss           string
 	RiskReward         string
 	Timeframe          string
+	BearTarget         string
+	BaseTarget         string
+	BullTarget         string
+	TargetBand         string
 
 	// Analysis sections
 	TechnicalSummary    string
@@ -148,6 +184,8 @@ type ReportData struct {
 	BalanceSheetItems  []FinancialRow
 
 	// Section visibility flags
+	Sections        []string // visible section identifiers, in render order
+	ShowSummary     bool
 	ShowFundamental bool
 	ShowTechnical   bool
 	ShowDerivatives bool
@@ -199,6 +237,9 @@ func GenerateHTML(analysis *models.CompositeAnalysis, cfg ReportConfig) (string,
 	if err != nil {
 		return "", fmt.Errorf("parsing template: %w", err)
 	}
+	if _, err := tmpl.Parse(sectionTemplates); err != nil {
+		return "", fmt.Errorf("parsing section templates: %w", err)
+	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
@@ -218,6 +259,96 @@ func GenerateText(analysis *models.CompositeAnalysis, cfg ReportConfig) (string,
 	return renderTextReport(data), nil
 }
 
+// ComparisonData is the template model for ComparisonTemplate.
+type ComparisonData struct {
+	Title       string
+	Author      string
+	GeneratedAt string
+	Rows        []ComparisonRow
+	Stocks      []ReportData
+}
+
+// ComparisonRow is one stock's row in the comparison matrix.
+type ComparisonRow struct {
+	Ticker              string
+	Sector              string
+	LastPrice           string
+	ChangePct           string
+	MarketCap           string
+	PE                  string
+	PB                  string
+	DividendYield       string
+	Recommendation      string
+	RecommendationClass string
+	Confidence          string
+	TargetPrice         string
+	Upside              string // target vs last price
+}
+
+// GenerateComparisonHTML generates a single HTML report comparing several
+// stocks: a comparison matrix, then a subsection per stock with the
+// sections selected in cfg.
+func GenerateComparisonHTML(analyses []*models.CompositeAnalysis, cfg ReportConfig) (string, error) {
+	if len(analyses) == 0 {
+		return "", fmt.Errorf("no analyses to compare")
+	}
+
+	data := ComparisonData{
+		Title:       cfg.Title,
+		Author:      cfg.Author,
+		GeneratedAt: utils.NowIST().Format("02 Jan 2006, 03:04 PM IST"),
+	}
+	tickers := make([]string, 0, len(analyses))
+	for _, a := range analyses {
+		if a == nil {
+			return "", fmt.Errorf("analysis is nil")
+		}
+		stock := buildReportData(a, cfg)
+		data.Stocks = append(data.Stocks, stock)
+		data.Rows = append(data.Rows, comparisonRow(a, stock))
+		tickers = append(tickers, a.Ticker)
+	}
+	if data.Title == "" {
+		data.Title = fmt.Sprintf("%s — Comparison Report", strings.Join(tickers, " vs "))
+	}
+
+	tmpl, err := template.New("comparison").Parse(ComparisonTemplate)
+	if err != nil {
+		return "", fmt.Errorf("parsing template: %w", err)
+	}
+	if _, err := tmpl.Parse(sectionTemplates); err != nil {
+		return "", fmt.Errorf("parsing section templates: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("executing template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// comparisonRow builds a's comparison matrix row from its report data.
+func comparisonRow(a *models.CompositeAnalysis, d ReportData) ComparisonRow {
+	row := ComparisonRow{
+		Ticker:              d.Ticker,
+		Sector:              d.Sector,
+		LastPrice:           d.LastPrice,
+		ChangePct:           d.ChangePct,
+		MarketCap:           d.MarketCap,
+		PE:                  d.PE,
+		PB:                  d.PB,
+		DividendYield:       d.DividendYield,
+		Recommendation:      d.Recommendation,
+		RecommendationClass: d.RecommendationClass,
+		Confidence:          d.Confidence,
+		TargetPrice:         d.TargetPrice,
+	}
+	if q := a.StockProfile.Quote; q != nil && q.LastPrice > 0 && a.TargetPrice > 0 {
+		row.Upside = utils.FormatPct((a.TargetPrice - q.LastPrice) / q.LastPrice * 100)
+	}
+	return row
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Internal — Build template data
 // ════════════════════════════════════════════════════════════════════
@@ -246,6 +377,7 @@ func buildReportData(a *models.CompositeAnalysis, cfg ReportConfig) ReportData {
 		Timeframe:           a.Timeframe,
 
 		// Section visibility
+		ShowSummary:     cfg.hasSection(SectionSummary),
 		ShowFundamental: cfg.hasSection(SectionFundamental) && a.Fundamental != nil,
 		ShowTechnical:   cfg.hasSection(SectionTechnical) && a.Technical != nil,
 		ShowDerivatives: cfg.hasSection(SectionDerivatives) && a.Derivatives != nil,
@@ -258,6 +390,12 @@ func buildReportData(a *models.CompositeAnalysis, cfg ReportConfig) ReportData {
 		data.Title = fmt.Sprintf("%s — Research Report", a.Ticker)
 	}
 
+	for _, sec := range cfg.Sections {
+		if data.showSection(sec) {
+			data.Sections = append(data.Sections, string(sec))
+		}
+	}
+
 	// Quote info
 	if profile.Quote != nil {
 		q := profile.Quote
@@ -288,6 +426,12 @@ func buildReportData(a *models.CompositeAnalysis, cfg ReportConfig) ReportData {
 	if a.RiskRewardRatio > 0 {
 		data.RiskReward = fmt.Sprintf("1:%.1f", a.RiskRewardRatio)
 	}
+	if sc := a.Scenarios; sc != nil {
+		data.BearTarget = utils.FormatINR(sc.Bear)
+		data.BaseTarget = utils.FormatINR(sc.Base)
+		data.BullTarget = utils.FormatINR(sc.Bull)
+		data.TargetBand = utils.FormatINR(sc.BandLow) + " – " + utils.FormatINR(sc.BandHigh)
+	}
 
 	// Analysis sections
 	if a.Technical != nil {
@@ -351,6 +495,28 @@ func buildReportData(a *models.CompositeAnalysis, cfg ReportConfig) ReportData {
 	return data
 }
 
+// showSection reports whether a section is selected and has data to render.
+func (d ReportData) showSection(s ReportSection) bool {
+	switch s {
+	case SectionSummary:
+		return d.ShowSummary
+	case SectionRecommend:
+		return d.ShowRecommend
+	case SectionFundamental:
+		return d.ShowFundamental
+	case SectionTechnical:
+		return d.ShowTechnical
+	case SectionDerivatives:
+		return d.ShowDerivatives
+	case SectionSentiment:
+		return d.ShowSentiment
+	case SectionRisk:
+		return d.ShowRisk
+	default:
+		return false
+	}
+}
+
 func flattenSignals(signals []models.Signal) []SignalRow {
 	rows := make([]SignalRow, len(signals))
 	for i, s := range signals {
@@ -470,33 +636,8 @@ func renderTextReport(d ReportData) string {
 	sb.WriteString(fmt.Sprintf("  Sector: %s | Industry: %s\n", d.Sector, d.Industry))
 	sb.WriteString(thinLine + "\n")
 
-	// Quote
-	if d.LastPrice != "" {
-		sb.WriteString(fmt.Sprintf("  Price: %s (%s, %s)\n", d.LastPrice, d.Change, d.ChangePct))
-		sb.WriteString(fmt.Sprintf("  Day: %s — %s | 52W: %s — %s\n", d.DayLow, d.DayHigh, d.WeekLow52, d.WeekHigh52))
-		sb.WriteString(fmt.Sprintf("  Volume: %s | Market Cap: %s\n", d.Volume, d.MarketCap))
-		sb.WriteString(thinLine + "\n")
-	}
-
-	// Recommendation
-	if d.ShowRecommend {
-		sb.WriteString("\n  ★ RECOMMENDATION\n")
-		sb.WriteString(fmt.Sprintf("  %s (Confidence: %s)\n", d.Recommendation, d.Confidence))
-		if d.EntryPrice != "" {
-			sb.WriteString(fmt.Sprintf("  Entry: %s | Target: %s | Stop Loss: %s\n", d.EntryPrice, d.TargetPrice, d.StopLoss))
-		}
-		if d.RiskReward != "" {
-			sb.WriteString(fmt.Sprintf("  Risk/Reward: %s | Timeframe: %s\n", d.RiskReward, d.Timeframe))
-		}
-		sb.WriteString(fmt.Sprintf("\n  %s\n", d.Summary))
-		sb.WriteString(thinLine + "\n")
-	}
-
 	// Analysis sections
-	writeSection := func(title string, show bool, summary string, signals []SignalRow) {
-		if !show {
-			return
-		}
+	writeSignals := func(title, summary string, signals []SignalRow) {
 		sb.WriteString(fmt.Sprintf("\n  ■ %s\n", title))
 		sb.WriteString(fmt.Sprintf("  %s\n", summary))
 		for _, s := range signals {
@@ -505,27 +646,59 @@ func renderTextReport(d ReportData) string {
 		sb.WriteString(thinLine + "\n")
 	}
 
-	writeSection("FUNDAMENTAL ANALYSIS", d.ShowFundamental, d.FundamentalSummary, d.FundamentalSignals)
-	writeSection("TECHNICAL ANALYSIS", d.ShowTechnical, d.TechnicalSummary, d.TechnicalSignals)
-	writeSection("DERIVATIVES VIEW", d.ShowDerivatives, d.DerivativesSummary, d.DerivativesSignals)
-	writeSection("SENTIMENT ANALYSIS", d.ShowSentiment, d.SentimentSummary, d.SentimentSignals)
-	writeSection("RISK ASSESSMENT", d.ShowRisk, d.RiskSummary, d.RiskSignals)
+	for _, sec := range d.Sections {
+		switch ReportSection(sec) {
+		case SectionSummary:
+			if d.LastPrice != "" {
+				sb.WriteString(fmt.Sprintf("  Price: %s (%s, %s)\n", d.LastPrice, d.Change, d.ChangePct))
+				sb.WriteString(fmt.Sprintf("  Day: %s — %s | 52W: %s — %s\n", d.DayLow, d.DayHigh, d.WeekLow52, d.WeekHigh52))
+				sb.WriteString(fmt.Sprintf("  Volume: %s | Market Cap: %s\n", d.Volume, d.MarketCap))
+				sb.WriteString(thinLine + "\n")
+			}
 
-	// Key ratios
-	if len(d.FinancialRatios) > 0 {
-		sb.WriteString("\n  ■ KEY FINANCIAL RATIOS\n")
-		for _, r := range d.FinancialRatios {
-			sb.WriteString(fmt.Sprintf("    %-20s %s\n", r.Label, r.Value))
-		}
-		sb.WriteString(thinLine + "\n")
-	}
+		case SectionRecommend:
+			sb.WriteString("\n  ★ RECOMMENDATION\n")
+			sb.WriteString(fmt.Sprintf("  %s (Confidence: %s)\n", d.Recommendation, d.Confidence))
+			if d.EntryPrice != "" {
+				sb.WriteString(fmt.Sprintf("  Entry: %s | Target: %s | Stop Loss: %s\n", d.EntryPrice, d.TargetPrice, d.StopLoss))
+			}
+			if d.RiskReward != "" {
+				sb.WriteString(fmt.Sprintf("  Risk/Reward: %s | Timeframe: %s\n", d.RiskReward, d.Timeframe))
+			}
+			if d.BaseTarget != "" {
+				sb.WriteString(fmt.Sprintf("  Scenarios: Bear %s | Base %s | Bull %s (band %s)\n", d.BearTarget, d.BaseTarget, d.BullTarget, d.TargetBand))
+			}
+			sb.WriteString(fmt.Sprintf("\n  %s\n", d.Summary))
+			sb.WriteString(thinLine + "\n")
+
+		case SectionFundamental:
+			writeSignals("FUNDAMENTAL ANALYSIS", d.FundamentalSummary, d.FundamentalSignals)
+			if len(d.FinancialRatios) > 0 {
+				sb.WriteString("\n  ■ KEY FINANCIAL RATIOS\n")
+				for _, r := range d.FinancialRatios {
+					sb.WriteString(fmt.Sprintf("    %-20s %s\n", r.Label, r.Value))
+				}
+				sb.WriteString(thinLine + "\n")
+			}
 
-	// Option strategy
-	if d.OptionStrategy != "" {
-		sb.WriteString(fmt.Sprintf("\n  ■ OPTION STRATEGY: %s\n", d.OptionStrategy))
-		sb.WriteString(fmt.Sprintf("    Max Profit: %s | Max Loss: %s\n", d.MaxProfit, d.MaxLoss))
-		sb.WriteString(fmt.Sprintf("    Breakevens: %s\n", d.Breakevens))
-		sb.WriteString(thinLine + "\n")
+		case SectionTechnical:
+			writeSignals("TECHNICAL ANALYSIS", d.TechnicalSummary, d.TechnicalSignals)
+
+		case SectionDerivatives:
+			writeSignals("DERIVATIVES VIEW", d.DerivativesSummary, d.DerivativesSignals)
+			if d.OptionStrategy != "" {
+				sb.WriteString(fmt.Sprintf("\n  ■ OPTION STRATEGY: %s\n", d.OptionStrategy))
+				sb.WriteString(fmt.Sprintf("    Max Profit: %s | Max Loss: %s\n", d.MaxProfit, d.MaxLoss))
+				sb.WriteString(fmt.Sprintf("    Breakevens: %s\n", d.Breakevens))
+				sb.WriteString(thinLine + "\n")
+			}
+
+		case SectionSentiment:
+			writeSignals("SENTIMENT ANALYSIS", d.SentimentSummary, d.SentimentSignals)
+
+		case SectionRisk:
+			writeSignals("RISK ASSESSMENT", d.RiskSummary, d.RiskSignals)
+		}
 	}
 
 	sb.WriteString("\n" + line + "\n")
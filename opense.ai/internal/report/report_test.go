@@ -485,6 +485,49 @@ func TestGenerateHTML_SelectedSections(t *testing.T) {
 	}
 }
 
+func TestGenerateHTML_SectionOrder(t *testing.T) {
+	sections, err := ParseSections("technical, fundamental,RISK")
+	if err != nil {
+		t.Fatalf("ParseSections failed: %v", err)
+	}
+	cfg := DefaultReportConfig()
+	cfg.Sections = sections
+
+	html, err := GenerateHTML(sampleAnalysis(), cfg)
+	if err != nil {
+		t.Fatalf("GenerateHTML failed: %v", err)
+	}
+
+	tech := strings.Index(html, "<h2>Technical Analysis</h2>")
+	fund := strings.Index(html, "<h2>Fundamental Analysis</h2>")
+	risk := strings.Index(html, "<h2>Risk Assessment</h2>")
+	if tech < 0 || fund < 0 || risk < 0 {
+		t.Fatalf("expected technical, fundamental and risk sections (got %d, %d, %d)", tech, fund, risk)
+	}
+	if !(tech < fund && fund < risk) {
+		t.Errorf("expected order technical < fundamental < risk, got %d, %d, %d", tech, fund, risk)
+	}
+
+	for _, h := range []string{"<h2>Recommendation</h2>", "<h2>Price Chart</h2>", "Derivatives &amp; F&amp;O", "<h2>Sentiment Analysis</h2>", `class="quote-bar"`} {
+		if strings.Contains(html, h) {
+			t.Errorf("did not expect %q in output", h)
+		}
+	}
+}
+
+func TestParseSections(t *testing.T) {
+	all, err := ParseSections("")
+	if err != nil || len(all) != len(AllSections()) {
+		t.Errorf("expected all sections for empty list, got %v (err %v)", all, err)
+	}
+	if _, err := ParseSections("technical,valuation"); err == nil || !strings.Contains(err.Error(), "valuation") {
+		t.Errorf("expected unknown section error, got %v", err)
+	}
+	if _, err := ParseSections("risk,risk"); err == nil {
+		t.Error("expected error for repeated section")
+	}
+}
+
 func TestGenerateHTML_CustomTitle(t *testing.T) {
 	analysis := sampleAnalysis()
 	cfg := DefaultReportConfig()
@@ -543,6 +586,51 @@ func TestGenerateHTML_WithOptionStrategy(t *testing.T) {
 	}
 }
 
+func TestGenerateComparisonHTML(t *testing.T) {
+	reliance := sampleAnalysis()
+	tcs := &models.CompositeAnalysis{
+		Ticker: "TCS",
+		StockProfile: models.StockProfile{
+			Stock: models.Stock{Ticker: "TCS", Name: "Tata Consultancy Services", Sector: "IT"},
+			Quote: &models.Quote{Ticker: "TCS", LastPrice: 4000, PE: 31.2},
+		},
+		Recommendation: models.Hold,
+		Confidence:     0.55,
+		TargetPrice:    4400,
+		Summary:        "Steady compounder, fairly valued.",
+	}
+
+	html, err := GenerateComparisonHTML([]*models.CompositeAnalysis{reliance, tcs}, DefaultReportConfig())
+	if err != nil {
+		t.Fatalf("GenerateComparisonHTML failed: %v", err)
+	}
+	for _, want := range []string{
+		`<table class="comparison-matrix">`,
+		"RELIANCE vs TCS — Comparison Report",
+		`<span class="ticker-badge">RELIANCE</span> Reliance Industries Ltd`,
+		`<span class="ticker-badge">TCS</span> Tata Consultancy Services`,
+		"31.20",
+		"10.00%", // TCS upside to target
+		"Steady compounder, fairly valued.",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected %q in comparison report", want)
+		}
+	}
+	// The matrix lists RELIANCE before TCS, and both come before the
+	// per-stock sections.
+	matrix := strings.Index(html, "comparison-matrix\">")
+	if matrix < 0 || !(matrix < strings.Index(html, "<strong>RELIANCE</strong>") &&
+		strings.Index(html, "<strong>RELIANCE</strong>") < strings.Index(html, "<strong>TCS</strong>") &&
+		strings.Index(html, "<strong>TCS</strong>") < strings.Index(html, `<div class="stock-section">`)) {
+		t.Error("expected comparison matrix rows in order before the per-stock sections")
+	}
+
+	if _, err := GenerateComparisonHTML(nil, DefaultReportConfig()); err == nil {
+		t.Error("expected error for no analyses")
+	}
+}
+
 func TestGenerateText_Basic(t *testing.T) {
 	analysis := sampleAnalysis()
 	cfg := DefaultReportConfig()
@@ -598,6 +686,29 @@ func TestGenerateText_MinimalAnalysis(t *testing.T) {
 	}
 }
 
+func TestGenerateText_Scenarios(t *testing.T) {
+	analysis := &models.CompositeAnalysis{
+		Ticker:         "TCS",
+		Recommendation: models.ModerateBuy,
+		Scenarios:      &models.PriceScenarios{Bear: 3900, Base: 4200, Bull: 4500, BandLow: 4050, BandHigh: 4350},
+	}
+
+	text, err := GenerateText(analysis, DefaultReportConfig())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if !strings.Contains(text, "Bear ₹3,900.00 | Base ₹4,200.00 | Bull ₹4,500.00") {
+		t.Errorf("expected scenario targets, got:\n%s", text)
+	}
+	html, err := GenerateHTML(analysis, DefaultReportConfig())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if !strings.Contains(html, "Bull Case") || !strings.Contains(html, "₹4,050.00 – ₹4,350.00") {
+		t.Error("expected scenarios in HTML report")
+	}
+}
+
 // ════════════════════════════════════════════════════════════════════
 // Report Config Tests
 // ════════════════════════════════════════════════════════════════════
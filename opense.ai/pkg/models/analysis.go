@@ -56,8 +56,10 @@ type AnalysisResult struct {
 	Signals        []Signal       `json:"signals"`
 	Recommendation Recommendation `json:"recommendation"`
 	Confidence     Confidence     `json:"confidence"`
+	Score          float64        `json:"score"`         // ScoreSignals of Signals, −1 to +1
 	Summary        string         `json:"summary"`       // LLM-generated summary
 	Details        map[string]any `json:"details"`       // agent-specific details
+	Scenarios      *PriceScenarios `json:"scenarios,omitempty"` // composite analyses only
 	Timestamp      time.Time      `json:"timestamp"`
 }
 
@@ -78,10 +80,21 @@ type CompositeAnalysis struct {
 	StopLoss        float64          `json:"stop_loss,omitempty"`
 	PositionSize    int              `json:"position_size,omitempty"`
 	RiskRewardRatio float64          `json:"risk_reward_ratio,omitempty"`
+	Scenarios       *PriceScenarios  `json:"scenarios,omitempty"`
 	Timeframe       string           `json:"timeframe"`  // e.g., "short-term", "medium-term"
 	Timestamp       time.Time        `json:"timestamp"`
 }
 
+// PriceScenarios are bear, base, and bull case target prices, with the
+// band the base case is expected to land in.
+type PriceScenarios struct {
+	Bear     float64 `json:"bear"`
+	Base     float64 `json:"base"`
+	Bull     float64 `json:"bull"`
+	BandLow  float64 `json:"band_low"`
+	BandHigh float64 `json:"band_high"`
+}
+
 // SentimentScore represents sentiment analysis output for a single source.
 type SentimentScore struct {
 	Source     string    `json:"source"`      // e.g., "Moneycontrol", "Economic Times"
@@ -110,6 +123,8 @@ type TechnicalIndicators struct {
 	MACD      MACDData  `json:"macd"`
 	SMA       map[int]float64 `json:"sma"`       // period → value (e.g., 20 → 2845.5)
 	EMA       map[int]float64 `json:"ema"`
+	WMA       map[int]float64 `json:"wma,omitempty"`
+	HMA       map[int]float64 `json:"hma,omitempty"`
 	Bollinger BollingerData   `json:"bollinger"`
 	SuperTrend SuperTrendData  `json:"supertrend"`
 	ATR       float64   `json:"atr"`
@@ -131,6 +146,13 @@ type BollingerData struct {
 	Lower  float64 `json:"lower"`
 }
 
+// KeltnerData contains Keltner Channel values.
+type KeltnerData struct {
+	Upper  float64 `json:"upper"`
+	Middle float64 `json:"middle"`
+	Lower  float64 `json:"lower"`
+}
+
 // SuperTrendData contains SuperTrend indicator values.
 type SuperTrendData struct {
 	Value    float64 `json:"value"`
@@ -174,6 +196,7 @@ type BacktestResult struct {
 	LosingTrades    int       `json:"losing_trades"`
 	AvgWin          float64   `json:"avg_win"`
 	AvgLoss         float64   `json:"avg_loss"`
+	WarmupBars      int       `json:"warmup_bars"` // leading bars skipped for indicator warm-up
 	EquityCurve     []EquityPoint `json:"equity_curve"`
 	Trades          []BacktestTrade `json:"trades"`
 	BenchmarkReturn float64   `json:"benchmark_return,omitempty"`
@@ -156,6 +156,25 @@ type DividendCalendarEntry struct {
 	Frequency     string    `json:"frequency,omitempty"` // "quarterly", "annual", etc.
 }
 
+// CorporateEventType identifies the kind of corporate event.
+type CorporateEventType string
+
+const (
+	EventEarnings CorporateEventType = "earnings"
+	EventDividend CorporateEventType = "dividend"
+	EventSplit    CorporateEventType = "split"
+	EventBonus    CorporateEventType = "bonus"
+)
+
+// CorporateEvent is a scheduled or past corporate event for a stock:
+// a results board meeting, or a dividend, split, or bonus ex-date.
+type CorporateEvent struct {
+	Ticker      string             `json:"ticker"`
+	Type        CorporateEventType `json:"type"`
+	Date        time.Time          `json:"date"` // board meeting date or ex-date
+	Description string             `json:"description,omitempty"`
+}
+
 // IPOCalendarEntry represents an upcoming IPO.
 type IPOCalendarEntry struct {
 	Symbol        string    `json:"symbol,omitempty"`
@@ -19,6 +19,7 @@ type IncomeStatement struct {
 	Tax              float64 `json:"tax"`
 	PAT              float64 `json:"pat"`              // Profit After Tax
 	EPS              float64 `json:"eps"`
+	DividendPerShare float64 `json:"dividend_per_share,omitempty"`
 	OPMPct           float64 `json:"opm_pct"`          // Operating Profit Margin %
 	NPMPct           float64 `json:"npm_pct"`          // Net Profit Margin %
 }
@@ -46,6 +46,18 @@ type IndexSectorPerformance struct {
 	Weight    float64 `json:"weight,omitempty"`
 }
 
+// HeatmapCell is one constituent's entry in an index heatmap.
+type HeatmapCell struct {
+	Symbol    string  `json:"symbol"`
+	Name      string  `json:"name,omitempty"`
+	LastPrice float64 `json:"last_price"`
+	ChangePct float64 `json:"change_pct"`
+	GapPct    float64 `json:"gap_pct"`       // open vs previous close, in percent
+	RangePct  float64 `json:"range_pct"`     // high-low range as a percentage of previous close
+	MarketCap float64 `json:"market_cap,omitempty"`
+	Weight    float64 `json:"weight"` // percentage of the index's total market cap
+}
+
 // SP500Multiple represents S&P 500 valuation multiples over time.
 type SP500Multiple struct {
 	Date        time.Time `json:"date"`
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
 	"time"
 )
@@ -93,6 +94,37 @@ func TestQuoteFields(t *testing.T) {
 	}
 }
 
+func TestQuoteDerivedFields(t *testing.T) {
+	q := Quote{
+		LastPrice: 4200.0,
+		Open:      4180.0,
+		High:      4220.0,
+		Low:       4170.0,
+		PrevClose: 4000.0,
+		Volume:    1_000,
+	}
+	approx := func(name string, got, want float64) {
+		t.Helper()
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+
+	approx("DayRangePct", q.DayRangePct(), 1.25) // 50 / 4000
+	approx("GapPct", q.GapPct(), 4.5)            // 180 / 4000
+	approx("DistanceFromVWAP", q.DistanceFromVWAP(4150), 50.0/4150*100)
+	approx("DistanceFromVWAP below", q.DistanceFromVWAP(4375), -4)
+
+	gapDown := q
+	gapDown.Open = 3900
+	approx("GapPct down", gapDown.GapPct(), -2.5)
+
+	var empty Quote
+	approx("DayRangePct no prev close", empty.DayRangePct(), 0)
+	approx("GapPct no prev close", empty.GapPct(), 0)
+	approx("DistanceFromVWAP zero vwap", q.DistanceFromVWAP(0), 0)
+}
+
 func TestTimeframeConstants(t *testing.T) {
 	timeframes := map[Timeframe]string{
 		Timeframe1Min:  "1m",
@@ -110,6 +142,43 @@ func TestTimeframeConstants(t *testing.T) {
 	}
 }
 
+func TestParseTimeframe(t *testing.T) {
+	cases := map[string]Timeframe{
+		"1m": Timeframe1Min, "5m": Timeframe5Min, "15m": Timeframe15Min,
+		"1h": Timeframe1Hour, "1d": Timeframe1Day, "1w": Timeframe1Week, "1M": Timeframe1Mon,
+		" 1D ": Timeframe1Day, "daily": Timeframe1Day, "Weekly": Timeframe1Week, "1mo": Timeframe1Mon, "60m": Timeframe1Hour,
+	}
+	for in, want := range cases {
+		got, err := ParseTimeframe(in)
+		if err != nil || got != want {
+			t.Errorf("ParseTimeframe(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "2h", "3d", "1y", "minute"} {
+		if tf, err := ParseTimeframe(in); err == nil {
+			t.Errorf("ParseTimeframe(%q) = %q, want error", in, tf)
+		}
+	}
+
+	durations := map[Timeframe]time.Duration{
+		Timeframe1Min: time.Minute, Timeframe5Min: 5 * time.Minute, Timeframe15Min: 15 * time.Minute,
+		Timeframe1Hour: time.Hour, Timeframe1Day: 24 * time.Hour, Timeframe1Week: 7 * 24 * time.Hour,
+	}
+	for tf, want := range durations {
+		back, err := ParseTimeframe(tf.String())
+		if err != nil || back != tf {
+			t.Errorf("round trip of %q gave %q, %v", tf.String(), back, err)
+		}
+		if tf.Duration() != want {
+			t.Errorf("%s.Duration() = %s, want %s", tf, tf.Duration(), want)
+		}
+	}
+	if !Timeframe15Min.Intraday() || Timeframe1Day.Intraday() || Timeframe("2h").Duration() != 0 {
+		t.Error("unexpected Intraday/Duration for edge timeframes")
+	}
+}
+
 // ── Order Tests ──
 
 func TestOrderSideConstants(t *testing.T) {
@@ -448,6 +517,46 @@ func TestAnalysisResultJSON(t *testing.T) {
 	}
 }
 
+func TestScoreSignals(t *testing.T) {
+	bullish := []Signal{
+		{Source: "RSI", Type: SignalBuy, Confidence: 0.95},
+		{Source: "MACD", Type: SignalBuy, Confidence: 0.9},
+		{Source: "Fundamental", Type: SignalBuy, Confidence: 1.0},
+	}
+	if got := ScoreSignals(bullish); got < 0.9 || got > 1 {
+		t.Errorf("all-bullish high-confidence: got %.3f, want near +1", got)
+	}
+
+	bearish := []Signal{
+		{Source: "RSI", Type: SignalSell, Confidence: 0.95},
+		{Source: "MACD", Type: SignalSell, Confidence: 0.9},
+	}
+	if got := ScoreSignals(bearish); got > -0.9 || got < -1 {
+		t.Errorf("all-bearish high-confidence: got %.3f, want near -1", got)
+	}
+
+	mixed := []Signal{
+		{Source: "RSI", Type: SignalBuy, Confidence: 0.8},
+		{Source: "MACD", Type: SignalSell, Confidence: 0.8},
+		{Source: "PCR", Type: SignalNeutral, Confidence: 0.6},
+		{Source: "News", Type: SignalBuy, Confidence: 0.5},
+		{Source: "SuperTrend", Type: SignalSell, Confidence: 0.55},
+	}
+	if got := ScoreSignals(mixed); got < -0.1 || got > 0.1 {
+		t.Errorf("mixed signals: got %.3f, want near 0", got)
+	}
+
+	// Neutral signals dilute a directional score without flipping it.
+	diluted := append([]Signal{{Type: SignalNeutral, Confidence: 1}}, bullish...)
+	if got := ScoreSignals(diluted); got <= 0 || got >= ScoreSignals(bullish) {
+		t.Errorf("neutral signal should dilute toward 0: got %.3f", got)
+	}
+
+	if got := ScoreSignals(nil); got != 0 {
+		t.Errorf("no signals: got %.3f, want 0", got)
+	}
+}
+
 func TestCompositeAnalysisJSON(t *testing.T) {
 	comp := CompositeAnalysis{
 		Ticker:          "TCS",
@@ -57,9 +57,10 @@ type OrderRequest struct {
 
 // OrderResponse represents the broker's response to an order placement.
 type OrderResponse struct {
-	OrderID  string `json:"order_id"`
-	Status   string `json:"status"`
-	Message  string `json:"message,omitempty"`
+	OrderID    string `json:"order_id"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	ApprovalID string `json:"approval_id,omitempty"` // set when the order awaits human approval
 }
 
 // Order represents a placed/historical order.
@@ -133,4 +134,7 @@ type TradeLog struct {
 	ApprovedAt    *time.Time  `json:"approved_at,omitempty"`
 	Reason        string      `json:"reason,omitempty"` // reason for trade / rejection
 	AgentName     string      `json:"agent_name"`       // which agent proposed the trade
+	FilledQty     int         `json:"filled_qty,omitempty"`
+	FillPrice     float64     `json:"fill_price,omitempty"` // average fill price
+	Charges       float64     `json:"charges,omitempty"`    // brokerage, taxes, and fees on the fill, in ₹
 }
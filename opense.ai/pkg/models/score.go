@@ -0,0 +1,42 @@
+package models
+
+// signalTypeWeights sets how much each signal type counts toward the
+// composite score. Neutral signals carry no direction but dilute the
+// score toward zero at half the weight of a directional signal.
+var signalTypeWeights = map[SignalType]float64{
+	SignalBuy:     1.0,
+	SignalSell:    1.0,
+	SignalNeutral: 0.5,
+}
+
+// ScoreSignals combines signals into a directional score from −1 (all
+// bearish) to +1 (all bullish). Each signal contributes its direction
+// scaled by its confidence, weighted by its type; signals of unknown type
+// are ignored. Returns 0 when there are no scorable signals.
+func ScoreSignals(signals []Signal) float64 {
+	var sum, total float64
+	for _, s := range signals {
+		w, ok := signalTypeWeights[s.Type]
+		if !ok {
+			continue
+		}
+		c := float64(s.Confidence)
+		if c < 0 {
+			c = 0
+		} else if c > 1 {
+			c = 1
+		}
+
+		switch s.Type {
+		case SignalBuy:
+			sum += w * c
+		case SignalSell:
+			sum -= w * c
+		}
+		total += w
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
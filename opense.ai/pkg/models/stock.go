@@ -1,7 +1,11 @@
 // Package models defines the core data structures used throughout OpeNSE.ai.
 package models
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Stock represents basic stock information.
 type Stock struct {
@@ -55,6 +59,33 @@ type Quote struct {
 	Timestamp      time.Time `json:"timestamp"`
 }
 
+// DayRangePct returns the day's high-low range as a percentage of the
+// previous close, or 0 without a previous close.
+func (q Quote) DayRangePct() float64 {
+	if q.PrevClose <= 0 {
+		return 0
+	}
+	return (q.High - q.Low) / q.PrevClose * 100
+}
+
+// GapPct returns the opening gap, the open versus the previous close, in
+// percent. Positive is a gap up; 0 without a previous close.
+func (q Quote) GapPct() float64 {
+	if q.PrevClose <= 0 {
+		return 0
+	}
+	return (q.Open - q.PrevClose) / q.PrevClose * 100
+}
+
+// DistanceFromVWAP returns how far the last price is above (positive) or
+// below (negative) vwap, in percent, or 0 for a non-positive vwap.
+func (q Quote) DistanceFromVWAP(vwap float64) float64 {
+	if vwap <= 0 {
+		return 0
+	}
+	return (q.LastPrice - vwap) / vwap * 100
+}
+
 // Timeframe represents chart timeframe for OHLCV data.
 type Timeframe string
 
@@ -68,6 +99,70 @@ const (
 	Timeframe1Mon  Timeframe = "1M"
 )
 
+// timeframeDurations is the canonical table of timeframes, finest first,
+// with the length of one bar. A month has no fixed length; it is listed
+// as 30 days.
+var timeframeDurations = []struct {
+	tf  Timeframe
+	dur time.Duration
+}{
+	{Timeframe1Min, time.Minute},
+	{Timeframe5Min, 5 * time.Minute},
+	{Timeframe15Min, 15 * time.Minute},
+	{Timeframe1Hour, time.Hour},
+	{Timeframe1Day, 24 * time.Hour},
+	{Timeframe1Week, 7 * 24 * time.Hour},
+	{Timeframe1Mon, 30 * 24 * time.Hour},
+}
+
+// timeframeAliases maps lower-case spellings accepted by ParseTimeframe to
+// their timeframe. "1M" itself is matched case-sensitively, since "1m" is
+// one minute.
+var timeframeAliases = map[string]Timeframe{
+	"1min": Timeframe1Min, "5min": Timeframe5Min, "15min": Timeframe15Min,
+	"60m": Timeframe1Hour, "1hr": Timeframe1Hour, "hourly": Timeframe1Hour,
+	"day": Timeframe1Day, "daily": Timeframe1Day, "1day": Timeframe1Day,
+	"1wk": Timeframe1Week, "week": Timeframe1Week, "weekly": Timeframe1Week,
+	"1mo": Timeframe1Mon, "month": Timeframe1Mon, "monthly": Timeframe1Mon,
+}
+
+// ParseTimeframe parses a canonical timeframe string ("1m", "5m", "15m",
+// "1h", "1d", "1w", "1M") or a common alias such as "daily" or "1mo".
+func ParseTimeframe(s string) (Timeframe, error) {
+	s = strings.TrimSpace(s)
+	for _, t := range timeframeDurations {
+		if string(t.tf) == s {
+			return t.tf, nil
+		}
+	}
+	if tf, ok := timeframeAliases[strings.ToLower(s)]; ok {
+		return tf, nil
+	}
+	if tf := Timeframe(strings.ToLower(s)); tf.Duration() > 0 && tf != Timeframe1Min {
+		return tf, nil // e.g. "1D", "1H"
+	}
+	return "", fmt.Errorf("unknown timeframe %q (supported: 1m, 5m, 15m, 1h, 1d, 1w, 1M)", s)
+}
+
+// String returns the canonical form of the timeframe, e.g. "15m".
+func (tf Timeframe) String() string { return string(tf) }
+
+// Duration returns the length of one bar, or 0 for an unknown timeframe.
+func (tf Timeframe) Duration() time.Duration {
+	for _, t := range timeframeDurations {
+		if t.tf == tf {
+			return t.dur
+		}
+	}
+	return 0
+}
+
+// Intraday reports whether bars of the timeframe are shorter than a day.
+func (tf Timeframe) Intraday() bool {
+	d := tf.Duration()
+	return d > 0 && d < 24*time.Hour
+}
+
 // StockProfile aggregates data from multiple sources for a single stock.
 type StockProfile struct {
 	Stock       Stock           `json:"stock"`
@@ -108,6 +203,8 @@ type FinancialRatios struct {
 	CurrentRatio     float64 `json:"current_ratio"`
 	InterestCoverage float64 `json:"interest_coverage"`
 	DividendYield    float64 `json:"dividend_yield"`
+	DividendPerShare float64 `json:"dividend_per_share,omitempty"`
+	PayoutRatio      float64 `json:"payout_ratio,omitempty"` // dividend as % of EPS
 	EPS              float64 `json:"eps"`
 	BookValue        float64 `json:"book_value"`
 	PEGRatio         float64 `json:"peg_ratio"`
@@ -80,28 +80,76 @@ func FromCrores(crores float64) float64 {
 	return crores * 1e7
 }
 
+// Trend is a color hint for a signed change: up (green), down (red), or
+// flat (neutral).
+type Trend int
+
+const (
+	TrendFlat Trend = iota
+	TrendUp
+	TrendDown
+)
+
+// String returns "up", "down", or "flat".
+func (t Trend) String() string {
+	switch t {
+	case TrendUp:
+		return "up"
+	case TrendDown:
+		return "down"
+	default:
+		return "flat"
+	}
+}
+
+// TrendOf returns the trend of a change as it displays at 2 decimal places,
+// so values that round to zero are flat.
+func TrendOf(change float64) Trend {
+	switch {
+	case change >= 0.005:
+		return TrendUp
+	case change <= -0.005:
+		return TrendDown
+	default:
+		return TrendFlat
+	}
+}
+
 // FormatPct formats a percentage value with sign and suffix.
 // e.g., 2.45 → "+2.45%", -1.23 → "-1.23%"
 func FormatPct(pct float64) string {
-	if pct >= 0 {
-		return fmt.Sprintf("+%.2f%%", pct)
+	s, _ := FormatPctTrend(pct, true)
+	return s
+}
+
+// FormatPctTrend formats a percentage value and returns its trend as a
+// color hint. When signed is set, non-negative values get a leading "+".
+// e.g., (2.45, true) → "+2.45%", TrendUp; (2.45, false) → "2.45%", TrendUp
+func FormatPctTrend(pct float64, signed bool) (string, Trend) {
+	trend := TrendOf(pct)
+	if trend == TrendFlat {
+		pct = 0 // avoid "-0.00%"
 	}
-	return fmt.Sprintf("%.2f%%", pct)
+	if signed && pct >= 0 {
+		return fmt.Sprintf("+%.2f%%", pct), trend
+	}
+	return fmt.Sprintf("%.2f%%", pct), trend
 }
 
-// FormatVolume formats volume in human-readable Indian format.
-// e.g., 1500000 → "15.00 L", 25000000 → "2.50 Cr"
-func FormatVolume(volume int64) string {
-	v := float64(volume)
+// FormatVolume formats a traded volume compactly in Indian units with at
+// most one decimal place.
+// e.g., 3400 → "3.4K", 4500000 → "45L", 12000000 → "1.2Cr"
+func FormatVolume(v float64) string {
+	abs := math.Abs(v)
 	switch {
-	case v >= 1e7:
-		return fmt.Sprintf("%.2f Cr", v/1e7)
-	case v >= 1e5:
-		return fmt.Sprintf("%.2f L", v/1e5)
-	case v >= 1e3:
-		return fmt.Sprintf("%.2f K", v/1e3)
+	case abs >= 1e7:
+		return formatOneDecimal(v/1e7) + "Cr"
+	case abs >= 1e5:
+		return formatOneDecimal(v/1e5) + "L"
+	case abs >= 1e3:
+		return formatOneDecimal(v/1e3) + "K"
 	default:
-		return fmt.Sprintf("%d", volume)
+		return fmt.Sprintf("%.0f", v)
 	}
 }
 
@@ -140,3 +188,11 @@ func formatWithDecimals(n float64) string {
 	s = strings.TrimRight(s, ".")
 	return s
 }
+
+// formatOneDecimal formats a number with up to 1 decimal place,
+// removing a trailing zero.
+func formatOneDecimal(n float64) string {
+	s := fmt.Sprintf("%.1f", n)
+	s = strings.TrimSuffix(s, ".0")
+	return s
+}
@@ -91,21 +91,57 @@ func TestFormatPct(t *testing.T) {
 
 func TestFormatVolume(t *testing.T) {
 	tests := []struct {
-		input    int64
+		input    float64
 		expected string
 	}{
+		{0, "0"},
 		{500, "500"},
-		{1500, "1.50 K"},
-		{150000, "1.50 L"},
-		{15000000, "1.50 Cr"},
+		{999, "999"},
+		{1000, "1K"},
+		{3400, "3.4K"},
+		{99999, "100K"},
+		{100000, "1L"},
+		{150000, "1.5L"},
+		{4500000, "45L"},
+		{10000000, "1Cr"},
+		{12000000, "1.2Cr"},
+		{2500000000, "250Cr"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.expected, func(t *testing.T) {
 			result := FormatVolume(tt.input)
 			if result != tt.expected {
-				t.Errorf("FormatVolume(%d) = %s, want %s", tt.input, result, tt.expected)
+				t.Errorf("FormatVolume(%.0f) = %s, want %s", tt.input, result, tt.expected)
 			}
 		})
 	}
 }
+
+func TestFormatPctTrend(t *testing.T) {
+	tests := []struct {
+		input    float64
+		signed   bool
+		expected string
+		trend    Trend
+	}{
+		{2.45, true, "+2.45%", TrendUp},
+		{2.45, false, "2.45%", TrendUp},
+		{-1.23, true, "-1.23%", TrendDown},
+		{-1.23, false, "-1.23%", TrendDown},
+		{0, true, "+0.00%", TrendFlat},
+		{0, false, "0.00%", TrendFlat},
+		{0.004, true, "+0.00%", TrendFlat},
+		{-0.004, true, "+0.00%", TrendFlat},
+		{-0.004, false, "0.00%", TrendFlat},
+		{0.005, true, "+0.01%", TrendUp},
+	}
+
+	for _, tt := range tests {
+		result, trend := FormatPctTrend(tt.input, tt.signed)
+		if result != tt.expected || trend != tt.trend {
+			t.Errorf("FormatPctTrend(%g, %v) = %s, %s; want %s, %s",
+				tt.input, tt.signed, result, trend, tt.expected, tt.trend)
+		}
+	}
+}
@@ -101,38 +101,83 @@ func NormalizeTicker(ticker string) string {
 	return ticker
 }
 
-// ToYFinanceTicker converts an NSE ticker to Yahoo Finance format by appending .NS.
-// Index tickers are converted to their Yahoo Finance format (^NSEI, ^NSEBANK, etc.).
-func ToYFinanceTicker(ticker string) string {
-	ticker = NormalizeTicker(ticker)
+// Exchanges accepted by ToYahooSymbol and reported by FromYahooSymbol.
+const (
+	ExchangeNSE = "NSE"
+	ExchangeBSE = "BSE"
+)
 
-	// Handle index tickers
-	switch ticker {
-	case "NIFTY 50":
-		return "^NSEI"
-	case "NIFTY BANK":
-		return "^NSEBANK"
-	case "SENSEX":
-		return "^BSESN"
-	case "NIFTY IT":
-		return "^CNXIT"
-	case "NIFTY FIN SERVICE":
-		return "^CNXFIN"
+// Yahoo Finance symbols for the canonical index names.
+var yahooIndexSymbols = map[string]string{
+	"NIFTY 50":          "^NSEI",
+	"NIFTY BANK":        "^NSEBANK",
+	"SENSEX":            "^BSESN",
+	"NIFTY IT":          "^CNXIT",
+	"NIFTY FIN SERVICE": "^CNXFIN",
+}
+
+// yahooSuffixes maps each exchange to its Yahoo Finance symbol suffix.
+var yahooSuffixes = map[string]string{
+	ExchangeNSE: ".NS",
+	ExchangeBSE: ".BO",
+}
+
+// ToYahooSymbol converts a ticker to its Yahoo Finance symbol on exchange
+// ("NSE" or "BSE"; empty means NSE): RELIANCE becomes RELIANCE.NS or
+// RELIANCE.BO, and indices become their ^-prefixed symbols. An already
+// qualified symbol keeps its exchange unless exchange says otherwise, so
+// applying ToYahooSymbol twice gives the same result.
+func ToYahooSymbol(ticker, exchange string) string {
+	base, ex := FromYahooSymbol(ticker)
+	if sym, ok := yahooIndexSymbols[base]; ok {
+		return sym
+	}
+	if exchange != "" {
+		ex = strings.ToUpper(exchange)
+	}
+	suffix, ok := yahooSuffixes[ex]
+	if !ok {
+		suffix = yahooSuffixes[ExchangeNSE]
 	}
+	return base + suffix
+}
 
-	// Already has .NS suffix
-	if strings.HasSuffix(ticker, ".NS") || strings.HasSuffix(ticker, ".BO") {
-		return ticker
+// FromYahooSymbol splits a Yahoo Finance symbol into the canonical ticker
+// and its exchange: RELIANCE.NS gives ("RELIANCE", "NSE"), 500325.BO gives
+// ("500325", "BSE"), and ^NSEI gives ("NIFTY 50", "NSE"). A symbol with no
+// suffix is taken to be an NSE ticker.
+func FromYahooSymbol(symbol string) (ticker, exchange string) {
+	symbol = strings.TrimSpace(strings.ToUpper(symbol))
+	for name, sym := range yahooIndexSymbols {
+		if sym == symbol {
+			if name == "SENSEX" {
+				return name, ExchangeBSE
+			}
+			return name, ExchangeNSE
+		}
+	}
+	for ex, suffix := range yahooSuffixes {
+		if base, ok := strings.CutSuffix(symbol, suffix); ok {
+			return NormalizeTicker(base), ex
+		}
+	}
+	ticker = NormalizeTicker(symbol)
+	if ticker == "SENSEX" {
+		return ticker, ExchangeBSE
 	}
+	return ticker, ExchangeNSE
+}
 
-	return ticker + ".NS"
+// ToYFinanceTicker converts an NSE ticker to Yahoo Finance format by appending .NS.
+// Index tickers are converted to their Yahoo Finance format (^NSEI, ^NSEBANK, etc.).
+func ToYFinanceTicker(ticker string) string {
+	return ToYahooSymbol(ticker, "")
 }
 
 // FromYFinanceTicker strips the .NS or .BO suffix to get the NSE/BSE ticker.
 func FromYFinanceTicker(yfTicker string) string {
-	yfTicker = strings.TrimSuffix(yfTicker, ".NS")
-	yfTicker = strings.TrimSuffix(yfTicker, ".BO")
-	return yfTicker
+	ticker, _ := FromYahooSymbol(yfTicker)
+	return ticker
 }
 
 // IsIndex checks if the ticker is an index (not a stock).
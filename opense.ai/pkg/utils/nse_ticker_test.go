@@ -76,6 +76,46 @@ func TestFromYFinanceTicker(t *testing.T) {
 	}
 }
 
+func TestYahooSymbolRoundTrip(t *testing.T) {
+	tests := []struct {
+		ticker   string
+		exchange string
+		symbol   string
+		wantTick string
+		wantExch string
+	}{
+		{"RELIANCE", "NSE", "RELIANCE.NS", "RELIANCE", ExchangeNSE},
+		{"reliance", "", "RELIANCE.NS", "RELIANCE", ExchangeNSE},
+		{"RELIANCE", "BSE", "RELIANCE.BO", "RELIANCE", ExchangeBSE},
+		{"500325", "bse", "500325.BO", "500325", ExchangeBSE},
+		{"RIL", "NSE", "RELIANCE.NS", "RELIANCE", ExchangeNSE},
+		{"NIFTY", "NSE", "^NSEI", "NIFTY 50", ExchangeNSE},
+		{"SENSEX", "BSE", "^BSESN", "SENSEX", ExchangeBSE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ticker+"/"+tt.exchange, func(t *testing.T) {
+			sym := ToYahooSymbol(tt.ticker, tt.exchange)
+			if sym != tt.symbol {
+				t.Fatalf("ToYahooSymbol(%q, %q) = %q, want %q", tt.ticker, tt.exchange, sym, tt.symbol)
+			}
+			if again := ToYahooSymbol(sym, tt.exchange); again != sym {
+				t.Errorf("ToYahooSymbol is not idempotent: %q -> %q", sym, again)
+			}
+			if again := ToYahooSymbol(sym, ""); again != sym {
+				t.Errorf("ToYahooSymbol(%q, \"\") = %q, want the exchange kept", sym, again)
+			}
+			ticker, exchange := FromYahooSymbol(sym)
+			if ticker != tt.wantTick || exchange != tt.wantExch {
+				t.Errorf("FromYahooSymbol(%q) = (%q, %q), want (%q, %q)", sym, ticker, exchange, tt.wantTick, tt.wantExch)
+			}
+			if back := ToYahooSymbol(ticker, exchange); back != sym {
+				t.Errorf("round trip %q -> %q", sym, back)
+			}
+		})
+	}
+}
+
 func TestIsIndex(t *testing.T) {
 	tests := []struct {
 		input    string
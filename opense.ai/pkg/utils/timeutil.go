@@ -114,18 +114,57 @@ func TradingDaysBetween(start, end time.Time) int {
 }
 
 // IsTradingHoliday checks if the given date is an NSE trading holiday.
-// This list should be updated annually.
 func IsTradingHoliday(t time.Time) bool {
-	t = t.In(IST)
-	dateStr := t.Format("2006-01-02")
-
-	_, isHoliday := nseHolidays2026[dateStr]
+	_, isHoliday := HolidayName(t)
 	return isHoliday
 }
 
-// NSE Trading Holidays for 2026 (update annually).
-// Source: NSE India circular.
-var nseHolidays2026 = map[string]string{
+// HolidayName returns the name of the NSE trading holiday on the given date.
+func HolidayName(t time.Time) (string, bool) {
+	name, ok := MarketHolidays[t.In(IST).Format("2006-01-02")]
+	return name, ok
+}
+
+// MarketHolidays is the bundled NSE equity trading holiday calendar, keyed by
+// date ("2006-01-02"). Weekend holidays are omitted. Extend it annually from
+// the NSE holiday circular.
+var MarketHolidays = map[string]string{
+	// 2024
+	"2024-01-22": "Special Holiday",
+	"2024-01-26": "Republic Day",
+	"2024-03-08": "Mahashivratri",
+	"2024-03-25": "Holi",
+	"2024-03-29": "Good Friday",
+	"2024-04-11": "Id-ul-Fitr (Ramadan)",
+	"2024-04-17": "Ram Navami",
+	"2024-05-01": "Maharashtra Day",
+	"2024-05-20": "General Elections",
+	"2024-06-17": "Id-ul-Zuha (Bakri Id)",
+	"2024-07-17": "Muharram",
+	"2024-08-15": "Independence Day",
+	"2024-10-02": "Mahatma Gandhi Jayanti",
+	"2024-11-01": "Diwali (Laxmi Pujan)",
+	"2024-11-15": "Guru Nanak Jayanti",
+	"2024-11-20": "Maharashtra Assembly Elections",
+	"2024-12-25": "Christmas",
+
+	// 2025
+	"2025-02-26": "Mahashivratri",
+	"2025-03-14": "Holi",
+	"2025-03-31": "Id-ul-Fitr (Ramadan)",
+	"2025-04-10": "Mahavir Jayanti",
+	"2025-04-14": "Dr. Ambedkar Jayanti",
+	"2025-04-18": "Good Friday",
+	"2025-05-01": "Maharashtra Day",
+	"2025-08-15": "Independence Day",
+	"2025-08-27": "Ganesh Chaturthi",
+	"2025-10-02": "Mahatma Gandhi Jayanti / Dussehra",
+	"2025-10-21": "Diwali (Laxmi Pujan)",
+	"2025-10-22": "Diwali (Balipratipada)",
+	"2025-11-05": "Guru Nanak Jayanti",
+	"2025-12-25": "Christmas",
+
+	// 2026
 	"2026-01-26": "Republic Day",
 	"2026-02-17": "Mahashivratri",
 	"2026-03-10": "Holi",
@@ -150,7 +189,14 @@ var nseHolidays2026 = map[string]string{
 
 // GetTradingHolidays returns all trading holidays for the current year.
 func GetTradingHolidays() map[string]string {
-	return nseHolidays2026
+	year := NowIST().Format("2006")
+	holidays := make(map[string]string)
+	for date, name := range MarketHolidays {
+		if date[:4] == year {
+			holidays[date] = name
+		}
+	}
+	return holidays
 }
 
 // ParseDateIST parses a date string in "2006-01-02" format and returns it in IST.
@@ -170,27 +216,31 @@ func FormatDateTimeIST(t time.Time) string {
 
 // MarketStatus returns the current market status string.
 func MarketStatus() string {
-	now := NowIST()
+	return MarketStatusAt(NowIST())
+}
 
-	if now.Weekday() == time.Saturday || now.Weekday() == time.Sunday {
+// MarketStatusAt returns the market status string at the given time.
+func MarketStatusAt(t time.Time) string {
+	t = t.In(IST)
+
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
 		return "CLOSED (Weekend)"
 	}
 
-	if IsTradingHoliday(now) {
-		holiday := nseHolidays2026[now.Format("2006-01-02")]
+	if holiday, ok := HolidayName(t); ok {
 		return "CLOSED (" + holiday + ")"
 	}
 
-	open := MarketOpenTime(now)
-	close := MarketCloseTime(now)
-	preOpen := PreOpenStart(now)
+	open := MarketOpenTime(t)
+	close := MarketCloseTime(t)
+	preOpen := PreOpenStart(t)
 
 	switch {
-	case now.Before(preOpen):
+	case t.Before(preOpen):
 		return "PRE-MARKET"
-	case now.Before(open):
+	case t.Before(open):
 		return "PRE-OPEN SESSION"
-	case !now.After(close):
+	case !t.After(close):
 		return "OPEN"
 	default:
 		return "CLOSED"
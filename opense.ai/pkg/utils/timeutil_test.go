@@ -117,6 +117,27 @@ func TestFormatDateIST(t *testing.T) {
 	}
 }
 
+func TestMarketStatusAt_Holiday(t *testing.T) {
+	// 2025-10-21 (Tuesday) is Diwali Laxmi Pujan — closed during market hours
+	status := MarketStatusAt(time.Date(2025, 10, 21, 11, 0, 0, 0, IST))
+	if status != "CLOSED (Diwali (Laxmi Pujan))" {
+		t.Errorf("MarketStatusAt(Diwali 2025) = %q, want CLOSED (Diwali (Laxmi Pujan))", status)
+	}
+
+	// Regular trading day during market hours
+	if status := MarketStatusAt(time.Date(2025, 10, 20, 11, 0, 0, 0, IST)); status != "OPEN" {
+		t.Errorf("MarketStatusAt(Oct 20 2025 11:00) = %q, want OPEN", status)
+	}
+}
+
+func TestNextTradingDay_SkipsHoliday(t *testing.T) {
+	// 2025-08-14 (Thu) → 2025-08-15 is Independence Day (Fri) → next is Mon 2025-08-18
+	next := NextTradingDay(time.Date(2025, 8, 14, 0, 0, 0, 0, IST))
+	if next.Format("2006-01-02") != "2025-08-18" {
+		t.Errorf("NextTradingDay(Aug 14 2025) = %s, want 2025-08-18", next.Format("2006-01-02"))
+	}
+}
+
 func TestMarketStatus(t *testing.T) {
 	// Just verify it doesn't panic and returns a non-empty string
 	status := MarketStatus()
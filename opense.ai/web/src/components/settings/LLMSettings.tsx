@@ -20,7 +20,8 @@ export function LLMSettings({ config, keys, onChange }: LLMSettingsProps) {
     (k) =>
       k.name.includes("OpenAI") ||
       k.name.includes("Gemini") ||
-      k.name.includes("Anthropic"),
+      k.name.includes("Anthropic") ||
+      k.name.includes("Grok"),
   );
 
   return (
@@ -39,12 +40,13 @@ export function LLMSettings({ config, keys, onChange }: LLMSettingsProps) {
             { value: "ollama", label: "Ollama (Local)" },
             { value: "gemini", label: "Google Gemini" },
             { value: "anthropic", label: "Anthropic Claude" },
+            { value: "grok", label: "xAI Grok" },
           ]}
         />
 
         <TextField
           label="Model"
-          description="Model name (e.g., gpt-4o, qwen2.5:32b, gemini-pro)."
+          description="Model name (e.g., gpt-4o, qwen2.5:32b, gemini-pro, grok-3)."
           value={config.model}
           onChange={(v) => onChange({ model: v })}
           placeholder="gpt-4o"
@@ -13,7 +13,7 @@ export const financeqlLanguage: languages.IMonarchLanguage = {
   keywords: ["AND", "OR", "NOT", "WHERE", "BY", "GROUP", "ORDER", "ASC", "DESC", "LIMIT", "OFFSET"],
 
   functions: [
-    "price", "sma", "ema", "rsi", "macd", "bbands", "supertrend",
+    "price", "sma", "ema", "wma", "hma", "rsi", "macd", "bbands", "supertrend",
     "volume", "atr", "adx", "obv", "vwap",
     "pe", "pb", "eps", "roe", "debt_to_equity", "market_cap", "dividend_yield",
     "screener", "alert", "rank", "compare",
@@ -97,7 +97,7 @@ export const financeqlLanguage: languages.IMonarchLanguage = {
 
 export const financeqlCompletionItems = [
   // Functions
-  ...["price", "sma", "ema", "rsi", "macd", "bbands", "supertrend", "volume", "atr", "adx", "obv", "vwap",
+  ...["price", "sma", "ema", "wma", "hma", "rsi", "macd", "bbands", "supertrend", "volume", "atr", "adx", "obv", "vwap",
     "pe", "pb", "eps", "roe", "debt_to_equity", "market_cap", "dividend_yield",
     "screener", "alert", "rank", "compare",
     "avg", "sum", "min", "max", "count", "stddev",